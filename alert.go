@@ -11,14 +11,18 @@ import (
 	text "text/template"
 	"time"
 
+	"github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/kapacitor/alert"
 	"github.com/influxdata/kapacitor/edge"
 	"github.com/influxdata/kapacitor/expvar"
 	"github.com/influxdata/kapacitor/models"
 	"github.com/influxdata/kapacitor/pipeline"
 	alertservice "github.com/influxdata/kapacitor/services/alert"
+	"github.com/influxdata/kapacitor/services/alertmanager"
 	"github.com/influxdata/kapacitor/services/hipchat"
 	"github.com/influxdata/kapacitor/services/httppost"
+	"github.com/influxdata/kapacitor/services/jira"
+	"github.com/influxdata/kapacitor/services/kafka"
 	"github.com/influxdata/kapacitor/services/mqtt"
 	"github.com/influxdata/kapacitor/services/opsgenie"
 	"github.com/influxdata/kapacitor/services/pagerduty"
@@ -27,6 +31,8 @@ import (
 	"github.com/influxdata/kapacitor/services/slack"
 	"github.com/influxdata/kapacitor/services/smtp"
 	"github.com/influxdata/kapacitor/services/snmptrap"
+	"github.com/influxdata/kapacitor/services/syslog"
+	"github.com/influxdata/kapacitor/services/teams"
 	"github.com/influxdata/kapacitor/services/telegram"
 	"github.com/influxdata/kapacitor/services/victorops"
 	"github.com/influxdata/kapacitor/tick/ast"
@@ -72,13 +78,36 @@ type AlertNode struct {
 
 	levelResets  []stateful.Expression
 	lrScopePools []stateful.ScopePool
+
+	// mu protects the states so that they can be snapshotted while the node is running.
+	mu     sync.Mutex
+	states map[models.GroupID]*alertState
+	// Restored alert states for groups that have not yet been seen.
+	restored map[models.GroupID]alertStateSnapshot
+}
+
+// alertSnapshot is the snapshot state of an AlertNode.
+type alertSnapshot struct {
+	States map[models.GroupID]alertStateSnapshot
+}
+
+type alertStateSnapshot struct {
+	History        []alert.Level
+	Idx            int
+	Flapping       bool
+	Changed        bool
+	FirstTriggered time.Time
+	LastTriggered  time.Time
+	Expired        bool
 }
 
 // Create a new  AlertNode which caches the most recent item and exposes it over the HTTP API.
 func newAlertNode(et *ExecutingTask, n *pipeline.AlertNode, l *log.Logger) (an *AlertNode, err error) {
 	an = &AlertNode{
-		node: node{Node: n, et: et, logger: l},
-		a:    n,
+		node:     node{Node: n, et: et, logger: l},
+		a:        n,
+		states:   make(map[models.GroupID]*alertState),
+		restored: make(map[models.GroupID]alertStateSnapshot),
 	}
 	an.node.runF = an.runAlert
 
@@ -132,7 +161,8 @@ func newAlertNode(et *ExecutingTask, n *pipeline.AlertNode, l *log.Logger) (an *
 
 	for _, email := range n.EmailHandlers {
 		c := smtp.HandlerConfig{
-			To: email.ToList,
+			To:                 email.ToList,
+			NotifyAcknowledged: email.IsNotifyAcknowledged,
 		}
 		h := et.tm.SMTPService.Handler(c, l)
 		an.handlers = append(an.handlers, h)
@@ -165,6 +195,14 @@ func newAlertNode(et *ExecutingTask, n *pipeline.AlertNode, l *log.Logger) (an *
 		if log.Mode != 0 {
 			c.Mode = os.FileMode(log.Mode)
 		}
+		if log.Format != "" {
+			c.Format = log.Format
+		}
+		c.Template = log.Template
+		c.MaxSize = toml.Size(log.MaxSize)
+		c.RotateEvery = toml.Duration(log.RotateEvery)
+		c.MaxBackups = int(log.MaxBackups)
+		c.Compress = log.IsCompress
 		h, err := alertservice.NewLogHandler(c, l)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create log alert handler")
@@ -212,9 +250,10 @@ func newAlertNode(et *ExecutingTask, n *pipeline.AlertNode, l *log.Logger) (an *
 
 	for _, s := range n.SlackHandlers {
 		c := slack.HandlerConfig{
-			Channel:   s.Channel,
-			Username:  s.Username,
-			IconEmoji: s.IconEmoji,
+			Channel:            s.Channel,
+			Username:           s.Username,
+			IconEmoji:          s.IconEmoji,
+			NotifyAcknowledged: s.IsNotifyAcknowledged,
 		}
 		h := et.tm.SlackService.Handler(c, l)
 		an.handlers = append(an.handlers, h)
@@ -261,6 +300,19 @@ func newAlertNode(et *ExecutingTask, n *pipeline.AlertNode, l *log.Logger) (an *
 		an.handlers = append(an.handlers, h)
 	}
 
+	for _, s := range n.SyslogHandlers {
+		c := syslog.HandlerConfig{
+			Facility: s.Facility,
+			AppName:  s.AppName,
+			MsgID:    s.MsgID,
+		}
+		h, err := et.tm.SyslogService.Handler(c, l)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create syslog handler")
+		}
+		an.handlers = append(an.handlers, h)
+	}
+
 	if len(n.TelegramHandlers) == 0 && (et.tm.TelegramService != nil && et.tm.TelegramService.Global()) {
 		c := telegram.HandlerConfig{}
 		h := et.tm.TelegramService.Handler(c, l)
@@ -326,6 +378,31 @@ func newAlertNode(et *ExecutingTask, n *pipeline.AlertNode, l *log.Logger) (an *
 		an.handlers = append(an.handlers, h)
 	}
 
+	for _, a := range n.AlertmanagerHandlers {
+		c := alertmanager.HandlerConfig{
+			Labels: a.Labels,
+		}
+		h := et.tm.AlertmanagerService.Handler(c, an.anonTopic, et.tm.AlertService, l)
+		an.handlers = append(an.handlers, h)
+	}
+
+	for _, j := range n.JiraHandlers {
+		c := jira.HandlerConfig{
+			Project:            j.Project,
+			IssueType:          j.IssueType,
+			Summary:            j.Summary,
+			Description:        j.Description,
+			Labels:             j.LabelsList,
+			RecoveryTransition: j.RecoveryTransition,
+			RecoveryComment:    j.RecoveryComment,
+		}
+		h, err := et.tm.JiraService.Handler(c, et.tm.AlertService, l)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create Jira handler")
+		}
+		an.handlers = append(an.handlers, h)
+	}
+
 	for _, p := range n.PushoverHandlers {
 		c := pushover.HandlerConfig{}
 		if p.Device != "" {
@@ -349,11 +426,15 @@ func newAlertNode(et *ExecutingTask, n *pipeline.AlertNode, l *log.Logger) (an *
 
 	for _, p := range n.HTTPPostHandlers {
 		c := httppost.HandlerConfig{
-			URL:      p.URL,
-			Endpoint: p.Endpoint,
-			Headers:  p.Headers,
+			URL:           p.URL,
+			Endpoint:      p.Endpoint,
+			Headers:       p.Headers,
+			AlertTemplate: p.AlertTemplate,
+		}
+		h, err := et.tm.HTTPPostService.Handler(c, l)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create post handler")
 		}
-		h := et.tm.HTTPPostService.Handler(c, l)
 		an.handlers = append(an.handlers, h)
 	}
 
@@ -376,6 +457,24 @@ func newAlertNode(et *ExecutingTask, n *pipeline.AlertNode, l *log.Logger) (an *
 		an.handlers = append(an.handlers, h)
 	}
 
+	for _, t := range n.TeamsHandlers {
+		c := teams.HandlerConfig{
+			ChannelURL: t.ChannelURL,
+		}
+		h := et.tm.TeamsService.Handler(c, l)
+		an.handlers = append(an.handlers, h)
+	}
+	if len(n.TeamsHandlers) == 0 && (et.tm.TeamsService != nil && et.tm.TeamsService.Global()) {
+		h := et.tm.TeamsService.Handler(teams.HandlerConfig{}, l)
+		an.handlers = append(an.handlers, h)
+	}
+	// If teams has been configured with state changes only set it.
+	if et.tm.TeamsService != nil &&
+		et.tm.TeamsService.Global() &&
+		et.tm.TeamsService.StateChangesOnly() {
+		n.IsStateChangesOnly = true
+	}
+
 	for _, m := range n.MQTTHandlers {
 		c := mqtt.HandlerConfig{
 			BrokerName: m.BrokerName,
@@ -386,6 +485,15 @@ func newAlertNode(et *ExecutingTask, n *pipeline.AlertNode, l *log.Logger) (an *
 		h := et.tm.MQTTService.Handler(c, l)
 		an.handlers = append(an.handlers, h)
 	}
+
+	for _, k := range n.KafkaHandlers {
+		c := kafka.HandlerConfig{
+			Cluster: k.Cluster,
+			Topic:   k.KafkaTopic,
+		}
+		h := et.tm.KafkaService.Handler(c, l)
+		an.handlers = append(an.handlers, h)
+	}
 	// Parse level expressions
 	an.levels = make([]stateful.Expression, alert.Critical+1)
 	an.scopePools = make([]stateful.ScopePool, alert.Critical+1)
@@ -460,7 +568,12 @@ func newAlertNode(et *ExecutingTask, n *pipeline.AlertNode, l *log.Logger) (an *
 	return
 }
 
-func (n *AlertNode) runAlert([]byte) error {
+func (n *AlertNode) runAlert(snapshot []byte) error {
+	if snapshot != nil {
+		if err := n.restore(snapshot); err != nil {
+			return errors.Wrap(err, "failed to restore alert snapshot")
+		}
+	}
 	// Register delete hook
 	if n.hasAnonTopic() {
 		n.et.tm.registerDeleteHookForTask(n.et.Task.ID, deleteAlertHook(n.anonTopic))
@@ -506,9 +619,14 @@ func (n *AlertNode) runAlert([]byte) error {
 	// Close the anonymous topic.
 	n.et.tm.AlertService.CloseTopic(n.anonTopic)
 
-	// Deregister Handlers on topic
+	// Deregister Handlers on topic and close them
 	for _, h := range n.handlers {
 		n.et.tm.AlertService.DeregisterAnonHandler(n.anonTopic, h)
+		if c, ok := h.(interface {
+			Close()
+		}); ok {
+			c.Close()
+		}
 	}
 	return nil
 }
@@ -520,17 +638,59 @@ func (n *AlertNode) NewGroup(group edge.GroupInfo, first edge.PointMeta) (edge.R
 	}
 	t := first.Time()
 
-	state := n.restoreEventState(id, t)
+	n.mu.Lock()
+	var state *alertState
+	if s, ok := n.restored[group.ID]; ok {
+		state = n.newAlertState()
+		state.restore(s)
+		delete(n.restored, group.ID)
+	} else {
+		state = n.restoreEventState(id, t)
+	}
+	state.group = group.ID
+	state.id = id
+	state.name = first.Name()
+	state.tags = group.Tags
+	n.states[group.ID] = state
+	n.mu.Unlock()
 
 	return edge.NewReceiverFromForwardReceiverWithStats(
 		n.outs,
 		edge.NewTimedForwardReceiver(
 			n.timer,
-			state,
+			edge.NewLockedForwardReceiver(&n.mu, state),
 		),
 	), nil
 }
 
+func (n *AlertNode) snapshot() ([]byte, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	s := alertSnapshot{
+		States: make(map[models.GroupID]alertStateSnapshot, len(n.states)+len(n.restored)),
+	}
+	for id, state := range n.restored {
+		s.States[id] = state
+	}
+	for id, state := range n.states {
+		s.States[id] = state.snapshot()
+	}
+	return encodeSnapshot(s)
+}
+
+func (n *AlertNode) restore(data []byte) error {
+	var s alertSnapshot
+	if err := decodeSnapshot(data, &s); err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for id, state := range s.States {
+		n.restored[id] = state
+	}
+	return nil
+}
+
 func (n *AlertNode) restoreEventState(id string, t time.Time) *alertState {
 	state := n.newAlertState()
 	currentLevel, triggered := n.restoreEvent(id)
@@ -720,7 +880,8 @@ func (n *AlertNode) event(
 }
 
 type alertState struct {
-	n *AlertNode
+	n     *AlertNode
+	group models.GroupID
 
 	buffer *edge.BatchBuffer
 
@@ -736,6 +897,16 @@ type alertState struct {
 	// Note: Alerts are not triggered for every event.
 	lastTriggered time.Time
 	expired       bool
+
+	// Identity and most recent data of the alert,
+	// used to send a recovery event when the group is deleted.
+	id         string
+	name       string
+	tags       models.Tags
+	lastFields models.Fields
+	lastResult models.Result
+	// Time of the most recent barrier for the group.
+	barrierTime time.Time
 }
 
 func (a *alertState) BeginBatch(begin edge.BeginBatchMessage) (edge.Message, error) {
@@ -816,6 +987,7 @@ func (a *alertState) BufferedBatch(b edge.BufferedBatchMessage) (edge.Message, e
 	}
 
 	a.n.handleEvent(event)
+	a.recordEvent(event)
 
 	// Update tags or fields with event state
 	if a.n.a.LevelTag != "" ||
@@ -879,6 +1051,7 @@ func (a *alertState) Point(p edge.PointMessage) (edge.Message, error) {
 		}
 
 		a.n.handleEvent(event)
+		a.recordEvent(event)
 
 		// Prepare an augmented point to return
 		p = p.ShallowCopy()
@@ -921,13 +1094,91 @@ func (a *alertState) augmentFieldsWithEventState(p edge.FieldSetter, eventState
 	}
 }
 
+// Barrier records the barrier time and recovers an active alert if the group is idle,
+// so that the alert of a group that stopped receiving data does not stay active.
 func (a *alertState) Barrier(b edge.BarrierMessage) (edge.Message, error) {
+	if b.Time().After(a.barrierTime) {
+		a.barrierTime = b.Time()
+	}
+	if b.Idle() && a.currentLevel() != alert.OK {
+		a.recover()
+		a.addEvent(a.barrierTime, alert.OK)
+		a.triggered(a.barrierTime)
+	}
 	return b, nil
 }
+
+// DeleteGroup removes the state of the group.
+// Since the group will receive no more data, an active alert is recovered so that it does not stay active forever.
 func (a *alertState) DeleteGroup(d edge.DeleteGroupMessage) (edge.Message, error) {
+	delete(a.n.states, a.group)
+	a.recover()
 	return d, nil
 }
 
+// recordEvent records the most recent event sent for the group.
+func (a *alertState) recordEvent(event alert.Event) {
+	a.id = event.State.ID
+	a.tags = event.Data.Tags
+	a.lastFields = event.Data.Fields
+	a.lastResult = event.Data.Result
+}
+
+// recover sends an OK event for the group if its alert is active.
+func (a *alertState) recover() {
+	if a.currentLevel() == alert.OK || a.n.a.NoRecoveriesFlag {
+		return
+	}
+	t := a.barrierTime
+	if t.Before(a.lastTriggered) {
+		t = a.lastTriggered
+	}
+	result := a.lastResult
+	if len(result.Series) == 0 {
+		// The state was restored and no event has been sent since.
+		result = models.Result{Series: models.Rows{{Name: a.name, Tags: a.tags}}}
+	}
+	event, err := a.n.event(a.id, a.name, a.group, a.tags, a.lastFields, alert.OK, t, t.Sub(a.firstTriggered), result)
+	if err != nil {
+		a.n.incrementErrorCount()
+		a.n.logger.Println("E! failed to recover alert for deleted group:", err)
+		return
+	}
+	a.n.handleEvent(event)
+}
+
+func (a *alertState) snapshot() alertStateSnapshot {
+	history := make([]alert.Level, len(a.history))
+	copy(history, a.history)
+	return alertStateSnapshot{
+		History:        history,
+		Idx:            a.idx,
+		Flapping:       a.flapping,
+		Changed:        a.changed,
+		FirstTriggered: a.firstTriggered,
+		LastTriggered:  a.lastTriggered,
+		Expired:        a.expired,
+	}
+}
+
+// restore the state from a snapshot.
+// The history is replayed into the current history,
+// so that changes to the configured history size are respected.
+func (a *alertState) restore(s alertStateSnapshot) {
+	l := len(s.History)
+	if l > 0 {
+		for i := 1; i <= l; i++ {
+			a.idx = (a.idx + 1) % len(a.history)
+			a.history[a.idx] = s.History[(s.Idx+i)%l]
+		}
+	}
+	a.flapping = s.Flapping
+	a.changed = s.Changed
+	a.firstTriggered = s.FirstTriggered
+	a.lastTriggered = s.LastTriggered
+	a.expired = s.Expired
+}
+
 // Return the duration of the current alert state.
 func (a *alertState) duration() time.Duration {
 	return a.lastTriggered.Sub(a.firstTriggered)
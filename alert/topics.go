@@ -6,6 +6,7 @@ import (
 	"path"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/influxdata/kapacitor/expvar"
 	"github.com/influxdata/kapacitor/server/vars"
@@ -21,6 +22,11 @@ type Topics struct {
 
 	topics map[string]*Topic
 
+	// Silencer is consulted before dispatching events to handlers, may be nil.
+	Silencer Silencer
+	// Inhibitor observes all events and is consulted before dispatching events to handlers, may be nil.
+	Inhibitor Inhibitor
+
 	logger *log.Logger
 }
 
@@ -36,12 +42,26 @@ func (s *Topics) Open() error {
 	return nil
 }
 
+// Close closes all topics, waiting for their handlers to handle the buffered events.
+// The topics are closed without holding the lock, since handlers may annotate the events they handle.
 func (s *Topics) Close() error {
+	s.mu.RLock()
+	topics := make([]*Topic, 0, len(s.topics))
+	for _, t := range s.topics {
+		topics = append(topics, t)
+	}
+	s.mu.RUnlock()
+
+	for _, t := range topics {
+		t.close()
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	for topic, t := range s.topics {
-		t.close()
-		delete(s.topics, topic)
+	for _, t := range topics {
+		if s.topics[t.id] == t {
+			delete(s.topics, t.id)
+		}
 	}
 	return nil
 }
@@ -58,7 +78,7 @@ func (s *Topics) RestoreTopic(id string, eventStates map[string]EventState) {
 	defer s.mu.Unlock()
 	t, ok := s.topics[id]
 	if !ok {
-		t = newTopic(id)
+		t = newTopic(id, s.logger)
 		s.topics[id] = t
 	}
 	t.restoreEventStates(eventStates)
@@ -69,12 +89,46 @@ func (s *Topics) UpdateEvent(id string, event EventState) {
 	defer s.mu.Unlock()
 	t, ok := s.topics[id]
 	if !ok {
-		t = newTopic(id)
+		t = newTopic(id, s.logger)
 		s.topics[id] = t
 	}
 	t.updateEvent(event)
 }
 
+// AckEvent acknowledges the event, replacing any existing acknowledgement.
+func (s *Topics) AckEvent(topic, event string, ack Ack) error {
+	t, ok := s.Topic(topic)
+	if !ok {
+		return fmt.Errorf("unknown topic %q", topic)
+	}
+	return t.ackEvent(event, &ack)
+}
+
+// UnackEvent removes the acknowledgement of the event.
+func (s *Topics) UnackEvent(topic, event string) error {
+	t, ok := s.Topic(topic)
+	if !ok {
+		return fmt.Errorf("unknown topic %q", topic)
+	}
+	return t.ackEvent(event, nil)
+}
+
+// AnnotateEvent sets an annotation of the event.
+func (s *Topics) AnnotateEvent(topic, event, key, value string) error {
+	t, ok := s.Topic(topic)
+	if !ok {
+		return fmt.Errorf("unknown topic %q", topic)
+	}
+	return t.annotateEvent(event, key, value)
+}
+
+// SuppressEvent counts an event of the topic that a handler suppressed, i.e. because it was over its rate limit.
+func (s *Topics) SuppressEvent(topic string) {
+	if t, ok := s.Topic(topic); ok {
+		t.suppressed.Add(1)
+	}
+}
+
 func (s *Topics) EventState(topic, event string) (EventState, bool) {
 	s.mu.RLock()
 	t, ok := s.topics[topic]
@@ -97,13 +151,16 @@ func (s *Topics) Collect(event Event) error {
 		// Check again if the topic was created, now that we have the write lock
 		topic = s.topics[event.Topic]
 		if topic == nil {
-			topic = newTopic(event.Topic)
+			topic = newTopic(event.Topic, s.logger)
 			s.topics[event.Topic] = topic
 		}
 		s.mu.Unlock()
 	}
 
-	return topic.collect(event)
+	event.State.Tags = event.Data.Tags
+	silenced := s.Silencer != nil && s.Silencer.Silenced(event)
+	event.State.Inhibited = s.Inhibitor != nil && s.Inhibitor.Inhibited(event)
+	return topic.collect(event, silenced)
 }
 
 func (s *Topics) DeleteTopic(topic string) {
@@ -126,7 +183,7 @@ func (s *Topics) RegisterHandler(topic string, h Handler) {
 
 	t, ok := s.topics[topic]
 	if !ok {
-		t = newTopic(topic)
+		t = newTopic(topic, s.logger)
 		s.topics[topic] = t
 	}
 	t.addHandler(h)
@@ -137,26 +194,21 @@ func (s *Topics) DeregisterHandler(topic string, h Handler) {
 		return
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if t, ok := s.topics[topic]; ok {
+	if t, ok := s.Topic(topic); ok {
 		t.removeHandler(h)
 	}
 }
 
 func (s *Topics) ReplaceHandler(topic string, oldH, newH Handler) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	t, ok := s.topics[topic]
 	if !ok {
-		t = newTopic(topic)
+		t = newTopic(topic, s.logger)
 		s.topics[topic] = t
 	}
+	s.mu.Unlock()
 
-	t.removeHandler(oldH)
-	t.addHandler(newH)
+	t.replaceHandler(oldH, newH)
 }
 
 // TopicState returns the max alert level for each topic matching 'pattern', not returning
@@ -168,12 +220,9 @@ func (s *Topics) TopicState(pattern string, minLevel Level) map[string]TopicStat
 		if !PatternMatch(pattern, topic.ID()) {
 			continue
 		}
-		level := topic.MaxLevel()
-		if level >= minLevel {
-			res[topic.ID()] = TopicState{
-				Level:     level,
-				Collected: topic.Collected(),
-			}
+		state := topic.State()
+		if state.Level >= minLevel {
+			res[topic.ID()] = state
 		}
 	}
 	s.mu.RUnlock()
@@ -196,22 +245,40 @@ type Topic struct {
 	events map[string]*EventState
 	sorted []*EventState
 
-	collected *expvar.Int
-	statsKey  string
+	collected  *expvar.Int
+	silenced   *expvar.Int
+	inhibited  *expvar.Int
+	suppressed *expvar.Int
+	retried    *expvar.Int
+	failed     *expvar.Int
+	statsKey   string
 
 	handlers []*bufHandler
+
+	logger *log.Logger
 }
 
-func newTopic(id string) *Topic {
+func newTopic(id string, l *log.Logger) *Topic {
 	t := &Topic{
-		id:        id,
-		events:    make(map[string]*EventState),
-		collected: new(expvar.Int),
+		id:         id,
+		events:     make(map[string]*EventState),
+		collected:  new(expvar.Int),
+		silenced:   new(expvar.Int),
+		inhibited:  new(expvar.Int),
+		suppressed: new(expvar.Int),
+		retried:    new(expvar.Int),
+		failed:     new(expvar.Int),
+		logger:     l,
 	}
 	statsKey, statsMap := vars.NewStatistic("topics", map[string]string{
 		"id": id,
 	})
 	statsMap.Set("collected", t.collected)
+	statsMap.Set("silenced", t.silenced)
+	statsMap.Set("inhibited", t.inhibited)
+	statsMap.Set("suppressed", t.suppressed)
+	statsMap.Set("retried", t.retried)
+	statsMap.Set("failed", t.failed)
 	t.statsKey = statsKey
 	return t
 }
@@ -222,8 +289,13 @@ func (t *Topic) ID() string {
 
 func (t *Topic) State() TopicState {
 	return TopicState{
-		Level:     t.MaxLevel(),
-		Collected: t.Collected(),
+		Level:      t.MaxLevel(),
+		Collected:  t.Collected(),
+		Silenced:   t.Silenced(),
+		Inhibited:  t.Inhibited(),
+		Suppressed: t.Suppressed(),
+		Retried:    t.Retried(),
+		Failed:     t.Failed(),
 	}
 }
 func (t *Topic) MaxLevel() Level {
@@ -239,29 +311,60 @@ func (t *Topic) MaxLevel() Level {
 func (t *Topic) addHandler(h Handler) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	t.addHandlerLocked(h)
+}
+
+// addHandlerLocked adds the handler unless the topic already has it.
+// Caller must have the write lock.
+func (t *Topic) addHandlerLocked(h Handler) {
+	if h == nil {
+		return
+	}
 	for _, cur := range t.handlers {
 		if cur.Equal(h) {
 			return
 		}
 	}
-	hdlr := newHandler(h)
+	hdlr := newHandler(h, t)
 	t.handlers = append(t.handlers, hdlr)
 }
 
+// removeHandler removes the handler and closes it once the lock is released,
+// since the handler may annotate the events of the topic while it handles its buffered events.
 func (t *Topic) removeHandler(h Handler) {
 	t.mu.Lock()
-	defer t.mu.Unlock()
+	removed := t.takeHandler(h)
+	t.mu.Unlock()
+	if removed != nil {
+		removed.Close()
+	}
+}
+
+// replaceHandler replaces the old handler with the new handler and closes the old handler once the lock is released.
+func (t *Topic) replaceHandler(oldH, newH Handler) {
+	t.mu.Lock()
+	removed := t.takeHandler(oldH)
+	t.addHandlerLocked(newH)
+	t.mu.Unlock()
+	if removed != nil {
+		removed.Close()
+	}
+}
+
+// takeHandler removes the handler from the topic and returns it, or nil if the topic does not have the handler.
+// Caller must have the write lock.
+func (t *Topic) takeHandler(h Handler) *bufHandler {
 	for i := 0; i < len(t.handlers); i++ {
 		if t.handlers[i].Equal(h) {
-			// Close handler
-			t.handlers[i].Close()
+			removed := t.handlers[i]
 			if i < len(t.handlers)-1 {
 				t.handlers[i] = t.handlers[len(t.handlers)-1]
 			}
 			t.handlers = t.handlers[:len(t.handlers)-1]
-			break
+			return removed
 		}
 	}
+	return nil
 }
 
 func (t *Topic) restoreEventStates(eventStates map[string]EventState) {
@@ -301,24 +404,63 @@ func (t *Topic) EventState(event string) (EventState, bool) {
 	return EventState{}, false
 }
 
-func (t *Topic) close() {
+func (t *Topic) ackEvent(event string, ack *Ack) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	// Close all handlers
-	for _, h := range t.handlers {
-		h.Close()
+	state, ok := t.events[event]
+	if !ok {
+		return fmt.Errorf("unknown event %q in topic %q", event, t.id)
+	}
+	state.Ack = ack
+	return nil
+}
+
+func (t *Topic) annotateEvent(event, key, value string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.events[event]
+	if !ok {
+		return fmt.Errorf("unknown event %q in topic %q", event, t.id)
+	}
+	// Copy the annotations since handlers may be reading the previous ones.
+	annotations := make(map[string]string, len(state.Annotations)+1)
+	for k, v := range state.Annotations {
+		annotations[k] = v
 	}
+	annotations[key] = value
+	state.Annotations = annotations
+	return nil
+}
+
+// close closes all handlers once the lock is released, since they may annotate the events of the topic.
+func (t *Topic) close() {
+	t.mu.Lock()
+	handlers := t.handlers
 	t.handlers = nil
+	t.mu.Unlock()
+	for _, h := range handlers {
+		h.Close()
+	}
 	vars.DeleteStatistic(t.statsKey)
 }
 
-func (t *Topic) collect(event Event) error {
-	prev, ok := t.updateEvent(event.State)
+// collect updates the state of the event and handles the event unless it is silenced or inhibited.
+func (t *Topic) collect(event Event, silenced bool) error {
+	cur, prev, ok := t.updateEvent(event.State)
+	event.State = cur
 	if ok {
 		event.previousState = prev
 	}
 
 	t.collected.Add(1)
+	if silenced {
+		t.silenced.Add(1)
+		return nil
+	}
+	if event.State.Inhibited {
+		t.inhibited.Add(1)
+		return nil
+	}
 	return t.handleEvent(event)
 }
 
@@ -345,8 +487,32 @@ func (t *Topic) Collected() int64 {
 	return t.collected.IntValue()
 }
 
+func (t *Topic) Silenced() int64 {
+	return t.silenced.IntValue()
+}
+
+func (t *Topic) Inhibited() int64 {
+	return t.inhibited.IntValue()
+}
+
+// Suppressed returns the number of events handlers suppressed.
+func (t *Topic) Suppressed() int64 {
+	return t.suppressed.IntValue()
+}
+
+// Retried returns the number of times handlers retried failed events.
+func (t *Topic) Retried() int64 {
+	return t.retried.IntValue()
+}
+
+// Failed returns the number of events handlers failed to handle, including all retries.
+func (t *Topic) Failed() int64 {
+	return t.failed.IntValue()
+}
+
 // updateEvent will store the latest state for the given ID.
-func (t *Topic) updateEvent(state EventState) (EventState, bool) {
+// Returns the stored state and the previous state if it exists.
+func (t *Topic) updateEvent(state EventState) (EventState, EventState, bool) {
 	var hasPrev, needSort bool
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -362,12 +528,23 @@ func (t *Topic) updateEvent(state EventState) (EventState, bool) {
 	needSort = needSort || cur.Level != state.Level
 
 	prev := *cur
+	// Acknowledgements last until the level changes or they expire.
+	if hasPrev && prev.Ack != nil && prev.Level == state.Level && !prev.Ack.Expired(time.Now()) {
+		state.Ack = prev.Ack
+	}
+	// Annotations last until the event recovers.
+	if hasPrev && prev.Level != OK && state.Annotations == nil {
+		state.Annotations = prev.Annotations
+	}
+	if hasPrev && state.Tags == nil {
+		state.Tags = prev.Tags
+	}
 	*cur = state
 
 	if needSort {
 		sort.Sort(sortedStates(t.sorted))
 	}
-	return prev, hasPrev
+	return state, prev, hasPrev
 }
 
 type sortedStates []*EventState
@@ -382,19 +559,56 @@ func (e sortedStates) Less(i int, j int) bool {
 }
 
 // bufHandler wraps a Handler implementation in order to provide buffering and non-blocking event handling.
+// Failed events are retried if the handler is a Retrier, unless the error is permanent.
+// Retries are scheduled on timers, so that other events are handled while a failed event waits to be retried.
+// If the handler is a Digester its digests are handled the same as buffered events.
 type bufHandler struct {
 	h        Handler
+	retrier  Retrier
+	digester Digester
+	topic    *Topic
 	events   chan Event
 	aborting chan struct{}
 	wg       sync.WaitGroup
-}
 
-func newHandler(h Handler) *bufHandler {
+	// seq and latest track the most recent event of each event ID that is being handled,
+	// so that outdated events are not retried. They are only accessed by the run goroutine.
+	seq    uint64
+	latest map[string]uint64
+
+	// mu protects the retries that are waiting for their timers and the retries that are due.
+	mu      sync.Mutex
+	pending map[*retry]struct{}
+	due     []*retry
+	stopped bool
+	// wake notifies the run goroutine that retries are due.
+	wake chan struct{}
+}
+
+// retry is an event that is handled again after it failed.
+type retry struct {
+	event   Event
+	seq     uint64
+	attempt int
+	backoff time.Duration
+	err     error
+	timer   *time.Timer
+	// redeliver is set once the event must not be suppressed by the handler again.
+	redeliver bool
+}
+
+func newHandler(h Handler, t *Topic) *bufHandler {
 	hdlr := &bufHandler{
 		h:        h,
+		topic:    t,
 		events:   make(chan Event, eventBufferSize),
 		aborting: make(chan struct{}),
+		latest:   make(map[string]uint64),
+		pending:  make(map[*retry]struct{}),
+		wake:     make(chan struct{}, 1),
 	}
+	hdlr.retrier, _ = h.(Retrier)
+	hdlr.digester, _ = h.(Digester)
 	hdlr.wg.Add(1)
 	go func() {
 		defer hdlr.wg.Done()
@@ -415,6 +629,8 @@ func (h *bufHandler) Equal(o Handler) (b bool) {
 	return
 }
 
+// Close handles the buffered events and waits until they are handled.
+// Events that are waiting to be retried are not retried again.
 func (h *bufHandler) Close() {
 	close(h.events)
 	h.wg.Wait()
@@ -425,26 +641,168 @@ func (h *bufHandler) Abort() {
 	h.wg.Wait()
 }
 
+// Handle buffers the event, events are failed if the buffer is full.
 func (h *bufHandler) Handle(event Event) error {
 	select {
 	case h.events <- event:
 		return nil
 	default:
-		return fmt.Errorf("failed to deliver event %q to handler", event.State.ID)
+		err := fmt.Errorf("failed to deliver event %q to handler, the buffer is full", event.State.ID)
+		h.fail(event, err)
+		return err
 	}
 }
 
 func (h *bufHandler) run() {
+	var digests <-chan time.Time
+	if h.digester != nil {
+		ticker := time.NewTicker(h.digester.DigestInterval())
+		defer ticker.Stop()
+		digests = ticker.C
+	}
 	for {
 		select {
 		case event, ok := <-h.events:
 			if !ok {
+				if h.digester != nil {
+					h.digest()
+				}
+				h.stopRetries(true)
 				return
 			}
-			h.h.Handle(event)
+			h.handle(h.newRetry(event))
+		case <-digests:
+			h.digest()
+		case <-h.wake:
+			h.mu.Lock()
+			due := h.due
+			h.due = nil
+			h.mu.Unlock()
+			for _, r := range due {
+				if h.latest[r.event.State.ID] != r.seq {
+					// A newer event has been handled since the event failed.
+					h.topic.logger.Printf("D! not retrying event %q of topic %q, it has been replaced by a newer event", r.event.State.ID, h.topic.id)
+					continue
+				}
+				h.handle(r)
+			}
 		case <-h.aborting:
+			h.stopRetries(false)
+			return
+		}
+	}
+}
+
+// newRetry tracks the event as the most recent event of its ID.
+func (h *bufHandler) newRetry(event Event) *retry {
+	h.seq++
+	h.latest[event.State.ID] = h.seq
+	r := &retry{
+		event: event,
+		seq:   h.seq,
+	}
+	if h.retrier != nil {
+		r.backoff = h.retrier.RetryPolicy().Backoff
+	}
+	return r
+}
+
+// digest handles the digest of the events the handler suppressed, if any.
+func (h *bufHandler) digest() {
+	event, ok := h.digester.Digest()
+	if !ok {
+		return
+	}
+	r := h.newRetry(event)
+	r.redeliver = true
+	h.handle(r)
+}
+
+// handle passes the event to the handler and schedules a retry according to the retry policy of the handler.
+func (h *bufHandler) handle(r *retry) {
+	var err error
+	if r.redeliver && h.digester != nil {
+		err = h.digester.Redeliver(r.event)
+	} else {
+		err = h.h.Handle(r.event)
+	}
+	if err == nil {
+		h.done(r)
+		return
+	}
+	if h.retrier != nil && !IsPermanent(err) && r.attempt < h.retrier.RetryPolicy().Attempts {
+		h.topic.retried.Add(1)
+		h.topic.logger.Printf("D! retrying event %q of topic %q in %v: %v", r.event.State.ID, h.topic.id, r.backoff, err)
+		r.err = err
+		// The handler accepted the event, so its retries must not be suppressed.
+		r.redeliver = true
+		h.schedule(r)
+		return
+	}
+	h.fail(r.event, err)
+	h.done(r)
+}
+
+// schedule starts the timer of the next retry of the event.
+func (h *bufHandler) schedule(r *retry) {
+	delay := r.backoff
+	r.attempt++
+	r.backoff = h.retrier.RetryPolicy().next(r.backoff)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pending[r] = struct{}{}
+	r.timer = time.AfterFunc(delay, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.stopped {
 			return
 		}
+		delete(h.pending, r)
+		h.due = append(h.due, r)
+		select {
+		case h.wake <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// stopRetries stops all retries, and fails the events that are waiting to be retried if deadLetter is true.
+func (h *bufHandler) stopRetries(deadLetter bool) {
+	h.mu.Lock()
+	h.stopped = true
+	retries := h.due
+	for r := range h.pending {
+		r.timer.Stop()
+		retries = append(retries, r)
+	}
+	h.pending = nil
+	h.due = nil
+	h.mu.Unlock()
+
+	if !deadLetter {
+		return
+	}
+	for _, r := range retries {
+		if h.latest[r.event.State.ID] == r.seq {
+			h.fail(r.event, r.err)
+		}
+	}
+}
+
+// done forgets the event ID once its most recent event has been handled.
+func (h *bufHandler) done(r *retry) {
+	if h.latest[r.event.State.ID] == r.seq {
+		delete(h.latest, r.event.State.ID)
+	}
+}
+
+// fail counts the event as failed and passes it to the dead-letter function of the handler.
+func (h *bufHandler) fail(event Event, err error) {
+	h.topic.failed.Add(1)
+	h.topic.logger.Printf("E! failed to handle event %q of topic %q: %v", event.State.ID, h.topic.id, err)
+	if h.retrier != nil {
+		h.retrier.DeadLetter(event, err)
 	}
 }
 
@@ -0,0 +1,116 @@
+package alert_test
+
+import (
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// annotatingHandler annotates the events it handles, as the Jira handler does.
+type annotatingHandler struct {
+	topics *alert.Topics
+	// block delays handling until it is closed, so that events are still buffered when the topics close.
+	block chan struct{}
+}
+
+func (h *annotatingHandler) Handle(event alert.Event) error {
+	<-h.block
+	return h.topics.AnnotateEvent(event.Topic, event.State.ID, "key", "value")
+}
+
+func TestTopics_CloseWhileHandlerAnnotates(t *testing.T) {
+	testCases := []struct {
+		name  string
+		close func(topics *alert.Topics, h alert.Handler)
+	}{
+		{
+			name: "close",
+			close: func(topics *alert.Topics, h alert.Handler) {
+				topics.Close()
+			},
+		},
+		{
+			name: "deregister",
+			close: func(topics *alert.Topics, h alert.Handler) {
+				topics.DeregisterHandler("topic", h)
+			},
+		},
+		{
+			name: "replace",
+			close: func(topics *alert.Topics, h alert.Handler) {
+				topics.ReplaceHandler("topic", h, &annotatingHandler{topics: topics})
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			topics := alert.NewTopics(log.New(os.Stderr, "[alert] ", log.LstdFlags))
+			h := &annotatingHandler{
+				topics: topics,
+				block:  make(chan struct{}),
+			}
+			topics.RegisterHandler("topic", h)
+			if err := topics.Collect(alert.Event{
+				Topic: "topic",
+				State: alert.EventState{
+					ID:    "id",
+					Level: alert.Critical,
+				},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			closed := make(chan struct{})
+			go func() {
+				defer close(closed)
+				tc.close(topics, h)
+			}()
+			// Let the handler annotate the event while it is being closed.
+			time.Sleep(10 * time.Millisecond)
+			close(h.block)
+			select {
+			case <-closed:
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out closing a handler that annotates its events")
+			}
+		})
+	}
+}
+
+func TestTopics_TopicState(t *testing.T) {
+	topics := alert.NewTopics(log.New(os.Stderr, "[alert] ", log.LstdFlags))
+	defer topics.Close()
+	unblocked := make(chan struct{})
+	close(unblocked)
+	topics.RegisterHandler("topic", &annotatingHandler{topics: topics, block: unblocked})
+	if err := topics.Collect(alert.Event{
+		Topic: "topic",
+		State: alert.EventState{
+			ID:    "id",
+			Level: alert.Critical,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	topics.SuppressEvent("topic")
+
+	topic, ok := topics.Topic("topic")
+	if !ok {
+		t.Fatal("expected topic to exist")
+	}
+	exp := alert.TopicState{
+		Level:      alert.Critical,
+		Collected:  1,
+		Suppressed: 1,
+	}
+	if got := topic.State(); got != exp {
+		t.Errorf("unexpected topic state got %+v exp %+v", got, exp)
+	}
+	// Listing topics reports the same state.
+	if got := topics.TopicState("", alert.OK)["topic"]; got != exp {
+		t.Errorf("unexpected listed topic state got %+v exp %+v", got, exp)
+	}
+}
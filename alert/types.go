@@ -45,12 +45,105 @@ func (e Event) TemplateData() TemplateData {
 		Group:    e.Data.Group,
 		Tags:     e.Data.Tags,
 		Fields:   e.Data.Fields,
+
+		Acknowledged: e.State.Acknowledged(),
 	}
 }
 
 type Handler interface {
 	// Handle is responsible for taking action on the event.
-	Handle(event Event)
+	// An error is returned if the action failed and may succeed when retried,
+	// errors that fail the same way on every attempt are marked with Permanent.
+	Handle(event Event) error
+}
+
+// permanentError is an error that is not resolved by retrying the event.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Permanent marks the error of a handler as permanent, so that the event is not retried.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether the error, or any error it wraps, was marked as permanent.
+func IsPermanent(err error) bool {
+	type causer interface {
+		Cause() error
+	}
+	for err != nil {
+		if _, ok := err.(permanentError); ok {
+			return true
+		}
+		c, ok := err.(causer)
+		if !ok {
+			return false
+		}
+		err = c.Cause()
+	}
+	return false
+}
+
+// RetryPolicy defines how often and when a failed event is retried.
+type RetryPolicy struct {
+	// Number of retries after the first attempt failed.
+	Attempts int
+	// Delay before the first retry, doubled for each following retry.
+	Backoff time.Duration
+	// Maximum delay between retries, zero does not limit the delay.
+	MaxBackoff time.Duration
+}
+
+// next returns the delay before the retry following a retry after the backoff.
+func (p RetryPolicy) next(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
+
+// Retrier is implemented by handlers that retry failed events.
+type Retrier interface {
+	// RetryPolicy returns the policy for retrying failed events.
+	RetryPolicy() RetryPolicy
+	// DeadLetter receives events that failed all retries, with the error of the last attempt.
+	// DeadLetter must not block.
+	DeadLetter(event Event, err error)
+}
+
+// Digester is implemented by handlers that suppress events and summarize them in a digest event each interval.
+// Digests are handled the same as other events of the topic, so they are buffered and retried.
+type Digester interface {
+	// DigestInterval returns how often a digest is taken.
+	DigestInterval() time.Duration
+	// Digest starts a new interval and returns the digest of the events suppressed in the last interval,
+	// ok is false if no events were suppressed.
+	Digest() (event Event, ok bool)
+	// Redeliver handles an event without suppressing it.
+	// It is used for digests and for retries of events the handler did not suppress the first time.
+	Redeliver(event Event) error
+}
+
+// Silencer determines whether events are silenced.
+type Silencer interface {
+	// Silenced reports whether the event must not be sent to handlers.
+	Silenced(event Event) bool
+}
+
+// Inhibitor determines whether events are inhibited by other events.
+type Inhibitor interface {
+	// Inhibited observes the event and reports whether it is inhibited by another event.
+	// Inhibited events must not be sent to handlers.
+	Inhibited(event Event) bool
 }
 
 type EventState struct {
@@ -60,6 +153,36 @@ type EventState struct {
 	Time     time.Time
 	Duration time.Duration
 	Level    Level
+	// Acknowledgement of the event, nil if the event is not acknowledged.
+	Ack *Ack
+	// Whether the event is inhibited by an event of another topic.
+	Inhibited bool
+	// Annotations set by handlers, i.e. the key of an issue opened for the event.
+	// Annotations are kept until the event recovers, the annotations of the recovery are the last ones kept.
+	// The map must not be modified, annotations are replaced with a new map.
+	Annotations map[string]string
+	// Tags of the most recent event, kept so that inhibitions can be rebuilt from the state.
+	Tags map[string]string
+}
+
+// Acknowledged reports whether the event has been acknowledged.
+func (e EventState) Acknowledged() bool {
+	return e.Ack != nil
+}
+
+// Ack is the acknowledgement of an event by a user.
+// An acknowledgement lasts until the level of the event changes or it expires.
+type Ack struct {
+	By      string
+	Comment string
+	Time    time.Time
+	// Time the acknowledgement expires, zero if it does not expire.
+	Expires time.Time
+}
+
+// Expired reports whether the acknowledgement has expired.
+func (a Ack) Expired(now time.Time) bool {
+	return !a.Expires.IsZero() && !now.Before(a.Expires)
 }
 
 type EventData struct {
@@ -114,6 +237,9 @@ type TemplateData struct {
 
 	// Fields of alerting data point.
 	Fields map[string]interface{}
+
+	// Whether the event has been acknowledged.
+	Acknowledged bool
 }
 
 type Level int
@@ -163,8 +289,13 @@ func ParseLevel(s string) (l Level, err error) {
 }
 
 type TopicState struct {
-	Level     Level
-	Collected int64
+	Level      Level
+	Collected  int64
+	Silenced   int64
+	Inhibited  int64
+	Suppressed int64
+	Retried    int64
+	Failed     int64
 }
 
 // Data is a structure that contains relevant data about an alert event.
@@ -14,6 +14,10 @@ type Interface interface {
 	GrantSubscriptionAccess(token, db, rp string) error
 	ListSubscriptionTokens() ([]string, error)
 	RevokeSubscriptionAccess(token string) error
+	// APITokenUser returns the user that owns a long-lived API token.
+	APITokenUser(token string) (User, error)
+	// TokenRevoked reports whether a token issued by Kapacitor has been revoked.
+	TokenRevoked(id string) (bool, error)
 }
 type Privilege uint
 
@@ -54,6 +58,24 @@ func (p Privilege) String() string {
 	}
 }
 
+// ParsePrivilege returns the privilege with the given name.
+func ParsePrivilege(s string) (Privilege, error) {
+	switch s {
+	case "none":
+		return NoPrivileges, nil
+	case "read":
+		return ReadPrivilege, nil
+	case "write":
+		return WritePrivilege, nil
+	case "delete":
+		return DeletePrivilege, nil
+	case "all":
+		return AllPrivileges, nil
+	default:
+		return NoPrivileges, fmt.Errorf("unknown privilege %q", s)
+	}
+}
+
 type Action struct {
 	Resource  string
 	Privilege Privilege
@@ -68,11 +90,14 @@ type User struct {
 	hash  []byte
 	// Map of resource -> Bitmask of Privileges
 	privileges map[string]Privilege
+	// Resources of privileges that are patterns
+	patterns []string
 }
 
 // Create a user with the given privileges.
 func NewUser(name string, hash []byte, admin bool, privileges map[string][]Privilege) User {
 	ps := make(map[string]Privilege, len(privileges))
+	var patterns []string
 	// Clean resources and convert to bitmask
 	for resource, privileges := range privileges {
 		clean := path.Clean(resource)
@@ -81,6 +106,9 @@ func NewUser(name string, hash []byte, admin bool, privileges map[string][]Privi
 			mask |= p
 		}
 		ps[clean] = mask
+		if IsPattern(clean) {
+			patterns = append(patterns, clean)
+		}
 	}
 	// Make our own copy of the hash
 	h := make([]byte, len(hash))
@@ -90,6 +118,7 @@ func NewUser(name string, hash []byte, admin bool, privileges map[string][]Privi
 		admin:      admin,
 		hash:       h,
 		privileges: ps,
+		patterns:   patterns,
 	}
 }
 
@@ -142,7 +171,7 @@ func (u User) AuthorizeAction(action Action) error {
 		// Clean path to prevent path traversal like /a/b/../d when user has access to /a/b
 		resource := path.Clean(action.Resource)
 		for {
-			if p, ok := u.privileges[resource]; ok {
+			if p, ok := u.resourcePrivileges(resource); ok {
 				// Found matching resource
 				authorized := p&action.Privilege != 0 || p == AllPrivileges
 				if authorized {
@@ -164,6 +193,62 @@ func (u User) AuthorizeAction(action Action) error {
 	}
 }
 
+// Determine wether the user is authorized to take the action on the resource or on any resource below it.
+// This is used by endpoints that list resources, which then only return the resources the user is authorized for.
+func (u User) AuthorizeAnyAction(action Action) error {
+	err := u.AuthorizeAction(action)
+	if err == nil || action.Privilege == NoPrivileges {
+		return err
+	}
+	if _, ok := err.(authError); !ok {
+		return err
+	}
+	resource := path.Clean(action.Resource)
+	for r, p := range u.privileges {
+		if (p&action.Privilege != 0 || p == AllPrivileges) && matchesDescendant(r, resource) {
+			return nil
+		}
+	}
+	return err
+}
+
+// resourcePrivileges returns the privileges for the resource,
+// combining the privileges of the resource itself and of all patterns that match the resource.
+func (u User) resourcePrivileges(resource string) (Privilege, bool) {
+	p, found := u.privileges[resource]
+	for _, pattern := range u.patterns {
+		if match, _ := path.Match(pattern, resource); match {
+			p |= u.privileges[pattern]
+			found = true
+		}
+	}
+	return p, found
+}
+
+// IsPattern reports whether the resource is a pattern.
+// Patterns use shell/glob matching for each element of the resource, see https://golang.org/pkg/path/#Match
+func IsPattern(resource string) bool {
+	return strings.ContainsAny(resource, `*?[\`)
+}
+
+// matchesDescendant reports whether the resource or pattern r may match a resource below the parent resource.
+func matchesDescendant(r, parent string) bool {
+	if parent == "/" {
+		return true
+	}
+	rs := strings.Split(r, "/")
+	ps := strings.Split(parent, "/")
+	if len(rs) <= len(ps) {
+		return false
+	}
+	for i := range ps {
+		if match, _ := path.Match(rs[i], ps[i]); !match {
+			return false
+		}
+	}
+	return true
+}
+
 // All auth errors are of this type.
 type authError struct {
 	username string
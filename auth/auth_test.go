@@ -45,6 +45,21 @@ func Test_Privilege_String(t *testing.T) {
 	}
 }
 
+func Test_ParsePrivilege(t *testing.T) {
+	for _, p := range auth.PrivilegeList {
+		got, err := auth.ParsePrivilege(p.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != p {
+			t.Errorf("unexpected privilege: got %v exp %v", got, p)
+		}
+	}
+	if _, err := auth.ParsePrivilege("unknown"); err == nil {
+		t.Error("expected error parsing unknown privilege")
+	}
+}
+
 func Test_NewUser(t *testing.T) {
 	privs := map[string][]auth.Privilege{
 		"/simple/path/":               []auth.Privilege{auth.ReadPrivilege, auth.WritePrivilege},
@@ -460,6 +475,92 @@ func Test_User_AuthorizeAction(t *testing.T) {
 			authorized: true,
 			err:        nil,
 		},
+		{
+			username: "teamabob",
+			privileges: map[string][]auth.Privilege{
+				"/api/tasks/teama_*": []auth.Privilege{auth.WritePrivilege},
+			},
+			action: auth.Action{
+				Resource:  "/api/tasks/teama_cpu",
+				Privilege: auth.WritePrivilege,
+			},
+			authorized: true,
+			err:        nil,
+		},
+		{
+			username: "teamajim",
+			privileges: map[string][]auth.Privilege{
+				"/api/tasks/teama_*": []auth.Privilege{auth.WritePrivilege},
+			},
+			action: auth.Action{
+				Resource:  "/api/tasks/teama_cpu/some/path",
+				Privilege: auth.WritePrivilege,
+			},
+			authorized: true,
+			err:        nil,
+		},
+		{
+			username: "teamasue",
+			privileges: map[string][]auth.Privilege{
+				"/api/tasks/teama_*": []auth.Privilege{auth.WritePrivilege},
+			},
+			action: auth.Action{
+				Resource:  "/api/tasks/teamb_cpu",
+				Privilege: auth.WritePrivilege,
+			},
+			authorized: false,
+			err:        errors.New(`user teamasue does not have "write" privilege for resource "/api/tasks/teamb_cpu"`),
+		},
+		{
+			username: "teamasally",
+			privileges: map[string][]auth.Privilege{
+				"/api/tasks/teama_*": []auth.Privilege{auth.WritePrivilege},
+			},
+			action: auth.Action{
+				Resource:  "/api/tasks",
+				Privilege: auth.WritePrivilege,
+			},
+			authorized: false,
+			err:        errors.New(`user teamasally does not have "write" privilege for resource "/api/tasks"`),
+		},
+		{
+			username: "teamafred",
+			privileges: map[string][]auth.Privilege{
+				"/api/tasks":         []auth.Privilege{auth.ReadPrivilege},
+				"/api/tasks/teama_*": []auth.Privilege{auth.WritePrivilege},
+			},
+			action: auth.Action{
+				Resource:  "/api/tasks/teama_cpu",
+				Privilege: auth.ReadPrivilege,
+			},
+			authorized: false,
+			err:        errors.New(`user teamafred does not have "read" privilege for resource "/api/tasks/teama_cpu"`),
+		},
+		{
+			username: "teamaamy",
+			privileges: map[string][]auth.Privilege{
+				"/api/tasks/teama_*": []auth.Privilege{auth.WritePrivilege},
+				"/api/tasks/*_cpu":   []auth.Privilege{auth.ReadPrivilege},
+			},
+			action: auth.Action{
+				Resource:  "/api/tasks/teama_cpu",
+				Privilege: auth.ReadPrivilege,
+			},
+			authorized: true,
+			err:        nil,
+		},
+		{
+			username: "hackerteama",
+			privileges: map[string][]auth.Privilege{
+				"/api/tasks/teama_*": []auth.Privilege{auth.WritePrivilege},
+			},
+			action: auth.Action{
+				Resource:  "/api/tasks/teama_cpu/../teamb_cpu",
+				Privilege: auth.WritePrivilege,
+			},
+			authorized: false,
+			err:        errors.New(`user hackerteama does not have "write" privilege for resource "/api/tasks/teama_cpu/../teamb_cpu"`),
+		},
 	}
 	for _, tc := range testCases {
 		u := auth.NewUser(tc.username, nil, tc.admin, tc.privileges)
@@ -477,3 +578,56 @@ func Test_User_AuthorizeAction(t *testing.T) {
 		}
 	}
 }
+
+func Test_User_AuthorizeAnyAction(t *testing.T) {
+	u := auth.NewUser("bob", nil, false, map[string][]auth.Privilege{
+		"/api/tasks/teama_*":                 []auth.Privilege{auth.ReadPrivilege, auth.WritePrivilege},
+		"/api/preview/alerts/topics/teama_*": []auth.Privilege{auth.ReadPrivilege},
+		"/api/*/shared":                      []auth.Privilege{auth.DeletePrivilege},
+	})
+	testCases := []struct {
+		action     auth.Action
+		authorized bool
+	}{
+		{
+			action:     auth.Action{Resource: "/api/tasks", Privilege: auth.ReadPrivilege},
+			authorized: true,
+		},
+		{
+			action:     auth.Action{Resource: "/api/tasks", Privilege: auth.WritePrivilege},
+			authorized: true,
+		},
+		{
+			action:     auth.Action{Resource: "/api/preview/alerts/topics", Privilege: auth.ReadPrivilege},
+			authorized: true,
+		},
+		{
+			action:     auth.Action{Resource: "/api/preview/alerts/topics", Privilege: auth.WritePrivilege},
+			authorized: false,
+		},
+		{
+			action:     auth.Action{Resource: "/api/templates", Privilege: auth.ReadPrivilege},
+			authorized: false,
+		},
+		{
+			action:     auth.Action{Resource: "/api/templates", Privilege: auth.DeletePrivilege},
+			authorized: true,
+		},
+		{
+			action:     auth.Action{Resource: "/api/tasks/teama_cpu", Privilege: auth.ReadPrivilege},
+			authorized: true,
+		},
+		{
+			action:     auth.Action{Resource: "/api/tasks/teamb_cpu", Privilege: auth.ReadPrivilege},
+			authorized: false,
+		},
+	}
+	for _, tc := range testCases {
+		err := u.AuthorizeAnyAction(tc.action)
+		if tc.authorized && err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.action, err)
+		} else if !tc.authorized && err == nil {
+			t.Errorf("%v: expected authorization error", tc.action)
+		}
+	}
+}
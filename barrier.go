@@ -0,0 +1,222 @@
+package kapacitor
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/influxdata/kapacitor/edge"
+	"github.com/influxdata/kapacitor/expvar"
+	"github.com/influxdata/kapacitor/models"
+	"github.com/influxdata/kapacitor/pipeline"
+)
+
+const (
+	statsBarrierDropped = "dropped"
+)
+
+type BarrierNode struct {
+	node
+	b *pipeline.BarrierNode
+
+	dropped *expvar.Int
+
+	mu      sync.Mutex
+	groups  map[models.GroupID]*barrierGroup
+	stopped bool
+}
+
+// Create a new BarrierNode, which emits barriers for groups based on the system clock.
+func newBarrierNode(et *ExecutingTask, n *pipeline.BarrierNode, l *log.Logger) (*BarrierNode, error) {
+	bn := &BarrierNode{
+		node:    node{Node: n, et: et, logger: l},
+		b:       n,
+		dropped: new(expvar.Int),
+		groups:  make(map[models.GroupID]*barrierGroup),
+	}
+	bn.node.runF = bn.runBarrier
+	bn.node.stopF = bn.stopBarrier
+	return bn, nil
+}
+
+func (n *BarrierNode) runBarrier([]byte) error {
+	// No barriers may be emitted once the child edges are closed.
+	defer n.stopBarrier()
+
+	n.statMap.Set(statsBarrierDropped, n.dropped)
+	consumer := edge.NewGroupedConsumer(n.ins[0], n)
+	n.statMap.Set(statCardinalityGauge, consumer.CardinalityVar())
+	return consumer.Consume()
+}
+
+func (n *BarrierNode) stopBarrier() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.stopped = true
+	for _, g := range n.groups {
+		g.stop()
+	}
+}
+
+func (n *BarrierNode) NewGroup(group edge.GroupInfo, first edge.PointMeta) (edge.Receiver, error) {
+	g := &barrierGroup{
+		n:     n,
+		group: group,
+	}
+	n.mu.Lock()
+	g.stopped = n.stopped
+	n.groups[group.ID] = g
+	n.mu.Unlock()
+	return edge.NewReceiverFromForwardReceiverWithStats(
+		n.outs,
+		edge.NewTimedForwardReceiver(n.timer, g),
+	), nil
+}
+
+func (n *BarrierNode) deleteGroup(g *barrierGroup) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	g.stop()
+	delete(n.groups, g.group.ID)
+}
+
+type barrierGroup struct {
+	n     *BarrierNode
+	group edge.GroupInfo
+
+	batch edge.BatchBuffer
+
+	// mu serializes forwarding data with emitting barriers from the timer.
+	mu sync.Mutex
+	// Time of the most recent data.
+	lastTime time.Time
+	// System time the most recent data arrived.
+	lastArrival time.Time
+	// Time of the most recent barrier.
+	barrierTime time.Time
+
+	timer   *time.Timer
+	armed   bool
+	stopped bool
+}
+
+func (g *barrierGroup) BeginBatch(begin edge.BeginBatchMessage) (edge.Message, error) {
+	return nil, g.batch.BeginBatch(begin)
+}
+
+func (g *barrierGroup) BatchPoint(bp edge.BatchPointMessage) (edge.Message, error) {
+	return nil, g.batch.BatchPoint(bp)
+}
+
+func (g *barrierGroup) EndBatch(end edge.EndBatchMessage) (edge.Message, error) {
+	return nil, g.forward(g.batch.BufferedBatchMessage(end))
+}
+
+func (g *barrierGroup) BufferedBatch(batch edge.BufferedBatchMessage) (edge.Message, error) {
+	return nil, g.forward(batch)
+}
+
+func (g *barrierGroup) Point(p edge.PointMessage) (edge.Message, error) {
+	return nil, g.forward(p)
+}
+
+func (g *barrierGroup) Barrier(b edge.BarrierMessage) (edge.Message, error) {
+	return b, nil
+}
+
+func (g *barrierGroup) DeleteGroup(d edge.DeleteGroupMessage) (edge.Message, error) {
+	g.n.deleteGroup(g)
+	return d, nil
+}
+
+// forward sends the message on to the children unless it is older than the most recent barrier.
+func (g *barrierGroup) forward(m timeMessage) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t := m.Time()
+	if t.Before(g.barrierTime) {
+		g.n.dropped.Add(1)
+		return nil
+	}
+	if t.After(g.lastTime) {
+		g.lastTime = t
+	}
+	g.lastArrival = time.Now()
+	g.arm()
+	return edge.Forward(g.n.outs, m)
+}
+
+// arm starts the timer for the next barrier.
+// Idle timers are restarted for each message, periodic timers are only started if they are not already running.
+// The group lock must be held when calling this method.
+func (g *barrierGroup) arm() {
+	if g.stopped {
+		return
+	}
+	d := g.interval()
+	if g.armed && g.n.b.Period != 0 {
+		return
+	}
+	if g.timer == nil {
+		g.timer = time.AfterFunc(d, g.emitBarrier)
+	} else {
+		g.timer.Reset(d)
+	}
+	g.armed = true
+}
+
+func (g *barrierGroup) interval() time.Duration {
+	if g.n.b.Idle != 0 {
+		return g.n.b.Idle
+	}
+	return g.n.b.Period
+}
+
+// emitBarrier is called by the timer to emit a barrier for the group.
+func (g *barrierGroup) emitBarrier() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopped {
+		return
+	}
+	d := g.interval()
+	elapsed := time.Since(g.lastArrival)
+	if g.n.b.Idle != 0 && elapsed < d {
+		// Data arrived while the timer fired, wait for the rest of the idle duration.
+		g.timer.Reset(d - elapsed)
+		return
+	}
+	g.armed = false
+
+	t := g.lastTime.Add(elapsed)
+	g.barrierTime = t
+	barrier := edge.NewBarrierMessage(g.group, t)
+	if elapsed >= d {
+		// No data arrived for the entire interval.
+		barrier = edge.NewIdleBarrierMessage(g.group, t)
+	}
+	if err := edge.Forward(g.n.outs, barrier); err != nil {
+		g.n.incrementErrorCount()
+		g.n.logger.Println("E! failed to emit barrier:", err)
+		return
+	}
+	if g.n.b.Delete {
+		if err := edge.Forward(g.n.outs, edge.NewDeleteGroupMessage(g.group.ID)); err != nil {
+			g.n.incrementErrorCount()
+			g.n.logger.Println("E! failed to delete group:", err)
+		}
+		// Do not emit any more barriers until the group receives data again.
+		return
+	}
+	g.arm()
+}
+
+// stop prevents the group from emitting any more barriers.
+func (g *barrierGroup) stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stopped = true
+	if g.timer != nil {
+		g.timer.Stop()
+	}
+}
@@ -0,0 +1,183 @@
+package kapacitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/edge"
+	"github.com/influxdata/kapacitor/expvar"
+	"github.com/influxdata/kapacitor/models"
+	"github.com/influxdata/kapacitor/pipeline"
+	"github.com/influxdata/kapacitor/timer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBarrierGroup_IdleDelete(t *testing.T) {
+	out := edge.NewStatsEdge(edge.NewChannelEdge(pipeline.StreamEdge, 10))
+	n := &BarrierNode{
+		node: node{
+			outs:       []edge.StatsEdge{out},
+			logger:     logger,
+			timer:      timer.NewNoOp(),
+			nodeErrors: new(expvar.Int),
+		},
+		b: &pipeline.BarrierNode{
+			Idle:   10 * time.Millisecond,
+			Delete: true,
+		},
+		dropped: new(expvar.Int),
+		groups:  make(map[models.GroupID]*barrierGroup),
+	}
+	defer n.stopBarrier()
+
+	group := edge.GroupInfo{ID: "host=serverA", Tags: models.Tags{"host": "serverA"}}
+	point := func(t time.Time) edge.PointMessage {
+		return edge.NewPointMessage(
+			"cpu", "db", "rp",
+			models.Dimensions{TagNames: []string{"host"}},
+			models.Fields{"value": 1.0},
+			group.Tags,
+			t,
+		)
+	}
+	r, err := n.NewGroup(group, point(time.Unix(0, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Unix(10, 0).UTC()
+	if err := r.Point(point(start)); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, _ := out.Emit()
+	assert.Equal(t, edge.Point, msg.Type())
+
+	msg, _ = out.Emit()
+	if b, ok := msg.(edge.BarrierMessage); assert.True(t, ok, "unexpected message %T", msg) {
+		assert.Equal(t, group.ID, b.GroupID())
+		assert.False(t, b.Time().Before(start.Add(10*time.Millisecond)), "barrier time %v too early", b.Time())
+		assert.True(t, b.Idle(), "expected idle barrier")
+	}
+
+	msg, _ = out.Emit()
+	if d, ok := msg.(edge.DeleteGroupMessage); assert.True(t, ok, "unexpected message %T", msg) {
+		assert.Equal(t, group.ID, d.GroupID())
+	}
+
+	// Points older than the barrier are dropped.
+	if err := r.Point(point(start)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(1), n.dropped.IntValue())
+}
+
+func TestStateTrackingGroup_IdleBarrier(t *testing.T) {
+	testCases := []struct {
+		name   string
+		script string
+		newF   func(pipeline.Node) (*StateTrackingNode, error)
+		field  string
+		exp    []interface{}
+	}{
+		{
+			name:   "stateDuration",
+			script: `stream|from().measurement('cpu')|stateDuration(lambda: "value" > 50)`,
+			newF: func(n pipeline.Node) (*StateTrackingNode, error) {
+				return newStateDurationNode(nil, n.(*pipeline.StateDurationNode), logger)
+			},
+			field: "state_duration",
+			exp:   []interface{}{0.0, 1.0, 2.0, 0.0},
+		},
+		{
+			name:   "stateCount",
+			script: `stream|from().measurement('cpu')|stateCount(lambda: "value" > 50)`,
+			newF: func(n pipeline.Node) (*StateTrackingNode, error) {
+				return newStateCountNode(nil, n.(*pipeline.StateCountNode), logger)
+			},
+			field: "state_count",
+			exp:   []interface{}{int64(1), int64(2), int64(3), int64(1)},
+		},
+	}
+	group := edge.GroupInfo{ID: "group"}
+	point := func(i int) edge.PointMessage {
+		return edge.NewPointMessage(
+			"cpu", "db", "rp",
+			models.Dimensions{},
+			models.Fields{"value": 60.0},
+			nil,
+			time.Unix(int64(i), 0).UTC(),
+		)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := tc.newF(lastPipelineNode(t, tc.script))
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := initTestNode(&n.node, 1)
+			r, err := n.NewGroup(group, point(0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			steps := []edge.Message{
+				point(0),
+				// A barrier while the group is receiving data keeps the state.
+				edge.NewBarrierMessage(group, time.Unix(1, 0).UTC()),
+				point(1),
+				point(2),
+				// An idle barrier resets the state.
+				edge.NewIdleBarrierMessage(group, time.Unix(3, 0).UTC()),
+				point(4),
+			}
+			for _, m := range steps {
+				var err error
+				switch m := m.(type) {
+				case edge.PointMessage:
+					err = r.Point(m)
+				case edge.BarrierMessage:
+					err = r.Barrier(m)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			var got []interface{}
+			for _, m := range emitted(out) {
+				if p, ok := m.(edge.PointMessage); ok {
+					got = append(got, p.Fields()[tc.field])
+				}
+			}
+			assert.Equal(t, tc.exp, got)
+		})
+	}
+}
+
+func TestAlertState_IdleBarrier(t *testing.T) {
+	n := &AlertNode{
+		a:      &pipeline.AlertNode{History: 2, NoRecoveriesFlag: true},
+		states: make(map[models.GroupID]*alertState),
+	}
+	group := edge.GroupInfo{ID: "group"}
+	state := n.newAlertState()
+	state.addEvent(time.Unix(1, 0).UTC(), alert.Critical)
+	state.triggered(time.Unix(1, 0).UTC())
+
+	// A barrier while the group is receiving data keeps the alert active.
+	if _, err := state.Barrier(edge.NewBarrierMessage(group, time.Unix(2, 0).UTC())); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, alert.Critical, state.currentLevel())
+
+	// An idle barrier recovers the alert.
+	if _, err := state.Barrier(edge.NewIdleBarrierMessage(group, time.Unix(3, 0).UTC())); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, alert.OK, state.currentLevel())
+
+	// The next event starts a new alert.
+	state.addEvent(time.Unix(4, 0).UTC(), alert.Critical)
+	state.triggered(time.Unix(4, 0).UTC())
+	assert.Equal(t, time.Unix(4, 0).UTC(), state.firstTriggered)
+}
@@ -0,0 +1,178 @@
+package kapacitor
+
+import (
+	"log"
+	"sync"
+
+	"github.com/influxdata/kapacitor/edge"
+	"github.com/influxdata/kapacitor/models"
+	"github.com/influxdata/kapacitor/pipeline"
+	"github.com/pkg/errors"
+)
+
+const previousFieldPrefix = "previous_"
+
+type ChangeDetectNode struct {
+	node
+	d *pipeline.ChangeDetectNode
+
+	// mu protects the groups so that they can be snapshotted while the node is running.
+	mu     sync.Mutex
+	groups map[models.GroupID]*changeDetectGroup
+	// Restored previous values for groups that have not yet been seen.
+	restored map[models.GroupID]models.Fields
+}
+
+// changeDetectSnapshot is the snapshot state of a ChangeDetectNode.
+type changeDetectSnapshot struct {
+	// The previous values of the watched fields of each group.
+	Previous map[models.GroupID]models.Fields
+}
+
+// Create a new changeDetect node.
+func newChangeDetectNode(et *ExecutingTask, n *pipeline.ChangeDetectNode, l *log.Logger) (*ChangeDetectNode, error) {
+	cn := &ChangeDetectNode{
+		node:     node{Node: n, et: et, logger: l},
+		d:        n,
+		groups:   make(map[models.GroupID]*changeDetectGroup),
+		restored: make(map[models.GroupID]models.Fields),
+	}
+	cn.node.runF = cn.runChangeDetect
+	return cn, nil
+}
+
+func (n *ChangeDetectNode) runChangeDetect(snapshot []byte) error {
+	if snapshot != nil {
+		if err := n.restore(snapshot); err != nil {
+			return errors.Wrap(err, "failed to restore changeDetect snapshot")
+		}
+	}
+	consumer := edge.NewGroupedConsumer(
+		n.ins[0],
+		n,
+	)
+	n.statMap.Set(statCardinalityGauge, consumer.CardinalityVar())
+	return consumer.Consume()
+}
+
+func (n *ChangeDetectNode) snapshot() ([]byte, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	s := changeDetectSnapshot{
+		Previous: make(map[models.GroupID]models.Fields, len(n.groups)+len(n.restored)),
+	}
+	for id, previous := range n.restored {
+		s.Previous[id] = previous
+	}
+	for id, g := range n.groups {
+		if len(g.previous) > 0 {
+			s.Previous[id] = g.previous
+		}
+	}
+	return encodeSnapshot(s)
+}
+
+func (n *ChangeDetectNode) restore(data []byte) error {
+	var s changeDetectSnapshot
+	if err := decodeSnapshot(data, &s); err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for id, previous := range s.Previous {
+		n.restored[id] = previous
+	}
+	return nil
+}
+
+func (n *ChangeDetectNode) NewGroup(group edge.GroupInfo, first edge.PointMeta) (edge.Receiver, error) {
+	g := &changeDetectGroup{
+		n:        n,
+		id:       group.ID,
+		previous: make(models.Fields, len(n.d.Fields)),
+	}
+	n.mu.Lock()
+	if previous, ok := n.restored[group.ID]; ok {
+		g.previous = previous
+		delete(n.restored, group.ID)
+	}
+	n.groups[group.ID] = g
+	n.mu.Unlock()
+	return edge.NewReceiverFromForwardReceiverWithStats(
+		n.outs,
+		edge.NewTimedForwardReceiver(n.timer, edge.NewLockedForwardReceiver(&n.mu, g)),
+	), nil
+}
+
+type changeDetectGroup struct {
+	n  *ChangeDetectNode
+	id models.GroupID
+	// The most recent value of each watched field.
+	previous models.Fields
+}
+
+func (g *changeDetectGroup) BeginBatch(begin edge.BeginBatchMessage) (edge.Message, error) {
+	return begin, nil
+}
+
+func (g *changeDetectGroup) BatchPoint(bp edge.BatchPointMessage) (edge.Message, error) {
+	fields, changed := g.doChangeDetect(bp.Fields())
+	if !changed {
+		return nil, nil
+	}
+	bp = bp.ShallowCopy()
+	bp.SetFields(fields)
+	return bp, nil
+}
+
+func (g *changeDetectGroup) EndBatch(end edge.EndBatchMessage) (edge.Message, error) {
+	return end, nil
+}
+
+func (g *changeDetectGroup) Point(p edge.PointMessage) (edge.Message, error) {
+	fields, changed := g.doChangeDetect(p.Fields())
+	if !changed {
+		return nil, nil
+	}
+	p = p.ShallowCopy()
+	p.SetFields(fields)
+	return p, nil
+}
+
+// doChangeDetect compares the watched fields with their previous values.
+// If any field changed the fields are returned with the previous values added.
+// The first point of a group always changes and has no previous values.
+func (g *changeDetectGroup) doChangeDetect(fields models.Fields) (models.Fields, bool) {
+	changed := false
+	for _, f := range g.n.d.Fields {
+		v, ok := fields[f]
+		if !ok {
+			continue
+		}
+		if prev, ok := g.previous[f]; !ok || prev != v {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil, false
+	}
+
+	newFields := fields.Copy()
+	for _, f := range g.n.d.Fields {
+		if prev, ok := g.previous[f]; ok {
+			newFields[previousFieldPrefix+f] = prev
+		}
+		if v, ok := fields[f]; ok {
+			g.previous[f] = v
+		}
+	}
+	return newFields, true
+}
+
+func (g *changeDetectGroup) Barrier(b edge.BarrierMessage) (edge.Message, error) {
+	return b, nil
+}
+func (g *changeDetectGroup) DeleteGroup(d edge.DeleteGroupMessage) (edge.Message, error) {
+	delete(g.n.groups, g.id)
+	return d, nil
+}
@@ -27,29 +27,40 @@ const DefaultUserAgent = "KapacitorClient"
 // then use the appropriate *Link methods.
 
 const (
-	basePath          = "/kapacitor/v1"
-	basePreviewPath   = "/kapacitor/v1preview"
-	pingPath          = basePath + "/ping"
-	logLevelPath      = basePath + "/loglevel"
-	debugVarsPath     = basePath + "/debug/vars"
-	tasksPath         = basePath + "/tasks"
-	templatesPath     = basePath + "/templates"
-	recordingsPath    = basePath + "/recordings"
-	recordStreamPath  = basePath + "/recordings/stream"
-	recordBatchPath   = basePath + "/recordings/batch"
-	recordQueryPath   = basePath + "/recordings/query"
-	replaysPath       = basePath + "/replays"
-	replayBatchPath   = basePath + "/replays/batch"
-	replayQueryPath   = basePath + "/replays/query"
-	configPath        = basePath + "/config"
-	serviceTestsPath  = basePath + "/service-tests"
-	alertsPath        = basePreviewPath + "/alerts"
-	topicsPath        = alertsPath + "/topics"
-	topicEventsPath   = "events"
-	topicHandlersPath = "handlers"
-	storagePath       = basePath + "/storage"
-	storesPath        = storagePath + "/stores"
-	backupPath        = storagePath + "/backup"
+	basePath           = "/kapacitor/v1"
+	basePreviewPath    = "/kapacitor/v1preview"
+	pingPath           = basePath + "/ping"
+	logLevelPath       = basePath + "/loglevel"
+	debugVarsPath      = basePath + "/debug/vars"
+	tasksPath          = basePath + "/tasks"
+	templatesPath      = basePath + "/templates"
+	recordingsPath     = basePath + "/recordings"
+	recordStreamPath   = basePath + "/recordings/stream"
+	recordBatchPath    = basePath + "/recordings/batch"
+	recordQueryPath    = basePath + "/recordings/query"
+	replaysPath        = basePath + "/replays"
+	replayBatchPath    = basePath + "/replays/batch"
+	replayQueryPath    = basePath + "/replays/query"
+	configPath         = basePath + "/config"
+	serviceTestsPath   = basePath + "/service-tests"
+	alertsPath         = basePreviewPath + "/alerts"
+	topicsPath         = alertsPath + "/topics"
+	topicEventsPath    = "events"
+	topicEventAckPath  = "ack"
+	topicHandlersPath  = "handlers"
+	topicHistoryPath   = "history"
+	silencesPath       = alertsPath + "/silences"
+	inhibitionsPath    = alertsPath + "/inhibitions"
+	storagePath        = basePath + "/storage"
+	storesPath         = storagePath + "/stores"
+	backupPath         = storagePath + "/backup"
+	blobsPath          = basePath + "/blobs"
+	blobTagsPath       = blobsPath + "/tags"
+	sideloadReloadPath = basePath + "/sideload/reload"
+	usersPath          = basePath + "/users"
+	tokensPath         = basePath + "/tokens"
+	auditPath          = basePath + "/audit"
+	blobTagHistory     = "history"
 )
 
 // HTTP configuration for connecting to Kapacitor
@@ -720,10 +731,41 @@ func (c *Client) TopicHandlersLink(topic string) Link {
 func (c *Client) TopicHandlerLink(topic, id string) Link {
 	return Link{Relation: Self, Href: path.Join(topicsPath, topic, topicHandlersPath, id)}
 }
+func (c *Client) TopicHistoryLink(topic string) Link {
+	return Link{Relation: Self, Href: path.Join(topicsPath, topic, topicHistoryPath)}
+}
+
+func (c *Client) SilenceLink(id string) Link {
+	return Link{Relation: Self, Href: path.Join(silencesPath, id)}
+}
+
+func (c *Client) InhibitionLink(id string) Link {
+	return Link{Relation: Self, Href: path.Join(inhibitionsPath, id)}
+}
+
 func (c *Client) StorageLink(name string) Link {
 	return Link{Relation: Self, Href: path.Join(storesPath, name)}
 }
 
+func (c *Client) BlobLink(id string) Link {
+	return Link{Relation: Self, Href: path.Join(blobsPath, id)}
+}
+
+func (c *Client) BlobTagLink(name string) Link {
+	return Link{Relation: Self, Href: path.Join(blobTagsPath, name)}
+}
+func (c *Client) BlobTagHistoryLink(name string) Link {
+	return Link{Relation: Self, Href: path.Join(blobTagsPath, name, blobTagHistory)}
+}
+
+func (c *Client) UserLink(name string) Link {
+	return Link{Relation: Self, Href: path.Join(usersPath, name)}
+}
+
+func (c *Client) TokenLink(id string) Link {
+	return Link{Relation: Self, Href: path.Join(tokensPath, id)}
+}
+
 type CreateTaskOptions struct {
 	ID         string     `json:"id,omitempty"`
 	TemplateID string     `json:"template-id,omitempty"`
@@ -1787,8 +1829,14 @@ type Topic struct {
 	ID           string `json:"id"`
 	Level        string `json:"level"`
 	Collected    int64  `json:"collected"`
+	Silenced     int64  `json:"silenced"`
+	Inhibited    int64  `json:"inhibited"`
+	Suppressed   int64  `json:"suppressed"`
+	Retried      int64  `json:"retried"`
+	Failed       int64  `json:"failed"`
 	EventsLink   Link   `json:"events-link"`
 	HandlersLink Link   `json:"handlers-link"`
+	HistoryLink  Link   `json:"history-link"`
 }
 
 func (c *Client) ListTopics(opt *ListTopicsOptions) (Topics, error) {
@@ -1865,6 +1913,29 @@ type EventState struct {
 	Time     time.Time `json:"time"`
 	Duration Duration  `json:"duration"`
 	Level    string    `json:"level"`
+	// Acknowledgement of the event, nil if the event is not acknowledged.
+	Ack *EventAck `json:"ack,omitempty"`
+	// Whether the event is inhibited by an event of another topic.
+	Inhibited bool `json:"inhibited,omitempty"`
+	// Annotations set by handlers, i.e. the key of an issue opened for the event.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type EventAck struct {
+	Link    Link      `json:"link"`
+	By      string    `json:"by"`
+	Comment string    `json:"comment"`
+	Time    time.Time `json:"time"`
+	// Expiration of the acknowledgement, nil if it does not expire.
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// EventAckOptions define the acknowledgement of an event.
+// The acknowledgement lasts until the level of the event changes or the timeout expires.
+type EventAckOptions struct {
+	Comment string `json:"comment,omitempty"`
+	// How long until the acknowledgement expires, zero means it does not expire.
+	Timeout Duration `json:"timeout,omitempty"`
 }
 
 // TopicEvent retrieves details for a single event of a topic
@@ -1887,6 +1958,49 @@ func (c *Client) TopicEvent(link Link) (TopicEvent, error) {
 	return e, err
 }
 
+// AckTopicEvent acknowledges the event, replacing any existing acknowledgement.
+func (c *Client) AckTopicEvent(link Link, opt EventAckOptions) (EventAck, error) {
+	a := EventAck{}
+	if link.Href == "" {
+		return a, fmt.Errorf("invalid link %v", link)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	err := enc.Encode(opt)
+	if err != nil {
+		return a, err
+	}
+
+	u := *c.url
+	u.Path = path.Join(link.Href, topicEventAckPath)
+
+	req, err := http.NewRequest("POST", u.String(), &buf)
+	if err != nil {
+		return a, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = c.Do(req, &a, http.StatusOK)
+	return a, err
+}
+
+// UnackTopicEvent removes the acknowledgement of the event.
+func (c *Client) UnackTopicEvent(link Link) error {
+	if link.Href == "" {
+		return fmt.Errorf("invalid link %v", link)
+	}
+	u := *c.url
+	u.Path = path.Join(link.Href, topicEventAckPath)
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Do(req, nil, http.StatusNoContent)
+	return err
+}
+
 type ListTopicEventsOptions struct {
 	MinLevel string
 }
@@ -1928,6 +2042,91 @@ func (c *Client) ListTopicEvents(link Link, opt *ListTopicEventsOptions) (TopicE
 	return t, err
 }
 
+type TopicHistory struct {
+	Link    Link            `json:"link"`
+	Topic   string          `json:"topic"`
+	Records []HistoryRecord `json:"records"`
+}
+
+// HistoryRecord is a transition of the level of an event.
+type HistoryRecord struct {
+	ID       string            `json:"id"`
+	EventID  string            `json:"event-id"`
+	Message  string            `json:"message"`
+	Time     time.Time         `json:"time"`
+	Duration Duration          `json:"duration"`
+	Level    string            `json:"level"`
+	Previous string            `json:"previous"`
+	Tags     map[string]string `json:"tags"`
+}
+
+type ListTopicHistoryOptions struct {
+	// Glob pattern matched against the event ID.
+	EventID  string
+	MinLevel string
+	// Glob patterns matched against the tags of the event.
+	Tags map[string]string
+	// Only records in the time range [Since, Until) are returned, zero values are unbounded.
+	Since  time.Time
+	Until  time.Time
+	Offset int
+	Limit  int
+}
+
+func (o *ListTopicHistoryOptions) Default() {
+	if o.MinLevel == "" {
+		o.MinLevel = "OK"
+	}
+	if o.Limit == 0 {
+		o.Limit = 100
+	}
+}
+
+func (o *ListTopicHistoryOptions) Values() *url.Values {
+	v := &url.Values{}
+	if o.EventID != "" {
+		v.Set("event", o.EventID)
+	}
+	v.Set("min-level", o.MinLevel)
+	for tag, pattern := range o.Tags {
+		v.Add("tag", tag+"="+pattern)
+	}
+	if !o.Since.IsZero() {
+		v.Set("since", o.Since.Format(time.RFC3339Nano))
+	}
+	if !o.Until.IsZero() {
+		v.Set("until", o.Until.Format(time.RFC3339Nano))
+	}
+	v.Set("offset", strconv.FormatInt(int64(o.Offset), 10))
+	v.Set("limit", strconv.FormatInt(int64(o.Limit), 10))
+	return v
+}
+
+// ListTopicHistory returns the level transitions of events within a topic, newest first.
+func (c *Client) ListTopicHistory(link Link, opt *ListTopicHistoryOptions) (TopicHistory, error) {
+	t := TopicHistory{}
+	if link.Href == "" {
+		return t, fmt.Errorf("invalid link %v", link)
+	}
+
+	if opt == nil {
+		opt = new(ListTopicHistoryOptions)
+	}
+	opt.Default()
+
+	u := *c.url
+	u.Path = link.Href
+	u.RawQuery = opt.Values().Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return t, err
+	}
+
+	_, err = c.Do(req, &t, http.StatusOK)
+	return t, err
+}
+
 type TopicHandlers struct {
 	Link     Link           `json:"link"`
 	Topic    string         `json:"topic"`
@@ -1935,11 +2134,37 @@ type TopicHandlers struct {
 }
 
 type TopicHandler struct {
-	Link    Link                   `json:"link"`
-	ID      string                 `json:"id"`
-	Kind    string                 `json:"kind"`
-	Options map[string]interface{} `json:"options"`
-	Match   string                 `json:"match"`
+	Link      Link                   `json:"link"`
+	ID        string                 `json:"id"`
+	Kind      string                 `json:"kind"`
+	Options   map[string]interface{} `json:"options"`
+	Match     string                 `json:"match"`
+	Retry     *HandlerRetry          `json:"retry,omitempty"`
+	RateLimit *HandlerRateLimit      `json:"rate-limit,omitempty"`
+}
+
+// HandlerRetry defines how a handler retries events it failed to handle.
+type HandlerRetry struct {
+	// Number of retries after the first attempt failed.
+	Attempts int `json:"attempts" yaml:"attempts"`
+	// Delay before the first retry, doubled for each following retry.
+	Backoff Duration `json:"backoff" yaml:"backoff"`
+	// Maximum delay between retries, zero does not limit the delay.
+	MaxBackoff Duration `json:"max-backoff" yaml:"max-backoff"`
+	// Topic that receives events which failed all retries.
+	// Empty uses the default dead-letter topic of the server.
+	DeadLetterTopic string `json:"dead-letter-topic" yaml:"dead-letter-topic"`
+}
+
+// HandlerRateLimit limits the number of events a handler handles per interval.
+// Events over the limit are suppressed and handled as a single digest event at the end of the interval.
+type HandlerRateLimit struct {
+	// Number of events handled per interval.
+	Count    int      `json:"count" yaml:"count"`
+	Interval Duration `json:"interval" yaml:"interval"`
+	// Template of the message of the digest event, with the number of suppressed events as .Count and the .Interval.
+	// Empty uses the default message of the server.
+	Message string `json:"message" yaml:"message"`
 }
 
 // TopicHandler retrieves an alert handler.
@@ -1963,10 +2188,12 @@ func (c *Client) TopicHandler(link Link) (TopicHandler, error) {
 }
 
 type TopicHandlerOptions struct {
-	ID      string                 `json:"id" yaml:"id"`
-	Kind    string                 `json:"kind" yaml:"kind"`
-	Options map[string]interface{} `json:"options" yaml:"options"`
-	Match   string                 `json:"match" yaml:"match"`
+	ID        string                 `json:"id" yaml:"id"`
+	Kind      string                 `json:"kind" yaml:"kind"`
+	Options   map[string]interface{} `json:"options" yaml:"options"`
+	Match     string                 `json:"match" yaml:"match"`
+	Retry     *HandlerRetry          `json:"retry,omitempty" yaml:"retry,omitempty"`
+	RateLimit *HandlerRateLimit      `json:"rate-limit,omitempty" yaml:"rate-limit,omitempty"`
 }
 
 // CreateTopicHandler creates a new alert handler.
@@ -2097,114 +2324,986 @@ func (c *Client) ListTopicHandlers(link Link, opt *ListTopicHandlersOptions) (To
 	return handlers, nil
 }
 
-type StorageList struct {
-	Link    Link      `json:"link"`
-	Storage []Storage `json:"storage"`
+type Silences struct {
+	Link     Link      `json:"link"`
+	Silences []Silence `json:"silences"`
+}
+
+type Silence struct {
+	Link     Link              `json:"link"`
+	ID       string            `json:"id"`
+	Topic    string            `json:"topic"`
+	EventID  string            `json:"event-id"`
+	Tags     map[string]string `json:"tags"`
+	Start    time.Time         `json:"start"`
+	End      time.Time         `json:"end"`
+	Schedule string            `json:"schedule"`
+	Duration Duration          `json:"duration"`
+	Comment  string            `json:"comment"`
+	// Whether the silence is currently silencing matching events.
+	Active bool `json:"active"`
+}
+
+// SilenceOptions define a silence.
+// Events matching all of the non-empty matchers Topic, EventID and Tags are not sent to handlers
+// while the silence is active. Their state is still updated.
+type SilenceOptions struct {
+	// ID of the silence, a random ID is generated if empty.
+	ID string `json:"id,omitempty"`
+	// Pattern matching the topic of events.
+	Topic string `json:"topic,omitempty"`
+	// Pattern matching the ID of events.
+	EventID string `json:"event-id,omitempty"`
+	// Map of tag to a pattern matching the tag value of events.
+	Tags map[string]string `json:"tags,omitempty"`
+	// The silence is active between start and end, zero values are unbounded.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Cron expression of a recurring schedule, the silence is active
+	// for Duration after each time in the schedule.
+	Schedule string   `json:"schedule,omitempty"`
+	Duration Duration `json:"duration,omitempty"`
+	Comment  string   `json:"comment,omitempty"`
 }
 
-type Storage struct {
-	Link Link   `json:"link"`
-	Name string `json:"name"`
+// CreateSilence creates a new silence.
+// Errors if the silence already exists.
+func (c *Client) CreateSilence(opt SilenceOptions) (Silence, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	err := enc.Encode(opt)
+	if err != nil {
+		return Silence{}, err
+	}
+
+	u := *c.url
+	u.Path = silencesPath
+
+	req, err := http.NewRequest("POST", u.String(), &buf)
+	if err != nil {
+		return Silence{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	s := Silence{}
+	_, err = c.Do(req, &s, http.StatusOK)
+	return s, err
 }
 
-type StorageAction int
+// ReplaceSilence replaces an existing silence, with the new definition.
+func (c *Client) ReplaceSilence(link Link, opt SilenceOptions) (Silence, error) {
+	s := Silence{}
+	if link.Href == "" {
+		return s, fmt.Errorf("invalid link %v", link)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	err := enc.Encode(opt)
+	if err != nil {
+		return s, err
+	}
 
-const (
-	_ StorageAction = iota
-	StorageRebuild
-)
+	u := *c.url
+	u.Path = link.Href
 
-func (sa StorageAction) MarshalText() ([]byte, error) {
-	switch sa {
-	case StorageRebuild:
-		return []byte("rebuild"), nil
-	default:
-		return nil, fmt.Errorf("unknown StorageAction %d", sa)
+	req, err := http.NewRequest("PUT", u.String(), &buf)
+	if err != nil {
+		return s, err
 	}
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = c.Do(req, &s, http.StatusOK)
+	return s, err
 }
 
-func (sa *StorageAction) UnmarshalText(text []byte) error {
-	switch s := string(text); s {
-	case "rebuild":
-		*sa = StorageRebuild
-	default:
-		return fmt.Errorf("unknown StorageAction %s", s)
+// Silence retrieves a silence.
+// Errors if no silence exists.
+func (c *Client) Silence(link Link) (Silence, error) {
+	s := Silence{}
+	if link.Href == "" {
+		return s, fmt.Errorf("invalid link %v", link)
 	}
-	return nil
+
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return s, err
+	}
+
+	_, err = c.Do(req, &s, http.StatusOK)
+	return s, err
 }
 
-func (sa StorageAction) String() string {
-	s, err := sa.MarshalText()
+// DeleteSilence deletes a silence.
+func (c *Client) DeleteSilence(link Link) error {
+	if link.Href == "" {
+		return fmt.Errorf("invalid link %v", link)
+	}
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
 	if err != nil {
-		return err.Error()
+		return err
 	}
-	return string(s)
+
+	_, err = c.Do(req, nil, http.StatusNoContent)
+	return err
 }
 
-type StorageActionOptions struct {
-	Action StorageAction `json:"action"`
+type ListSilencesOptions struct {
+	Pattern string
 }
 
-func (c *Client) ListStorage() (StorageList, error) {
-	list := StorageList{}
+func (o *ListSilencesOptions) Default() {}
+
+func (o *ListSilencesOptions) Values() *url.Values {
+	v := &url.Values{}
+	v.Set("pattern", o.Pattern)
+	return v
+}
+
+func (c *Client) ListSilences(opt *ListSilencesOptions) (Silences, error) {
+	silences := Silences{}
+	if opt == nil {
+		opt = new(ListSilencesOptions)
+	}
+	opt.Default()
+
 	u := *c.url
-	u.Path = storesPath
+	u.Path = silencesPath
+	u.RawQuery = opt.Values().Encode()
 
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
-		return list, err
+		return silences, err
 	}
 
-	_, err = c.Do(req, &list, http.StatusOK)
-	if err != nil {
-		return list, err
-	}
-	return list, nil
+	_, err = c.Do(req, &silences, http.StatusOK)
+	return silences, err
 }
 
-func (c *Client) DoStorageAction(l Link, opt StorageActionOptions) error {
-	u := *c.url
-	u.Path = l.Href
+type Inhibitions struct {
+	Link        Link         `json:"link"`
+	Inhibitions []Inhibition `json:"inhibitions"`
+}
+
+type Inhibition struct {
+	Link         Link              `json:"link"`
+	ID           string            `json:"id"`
+	SourceTopic  string            `json:"source-topic"`
+	SourceTags   map[string]string `json:"source-tags"`
+	Level        string            `json:"level"`
+	TargetTopics []string          `json:"target-topics"`
+	Equal        []string          `json:"equal"`
+}
+
+// InhibitionOptions define an inhibition rule.
+// While an event of the source topic matching the source tags is at or above the level,
+// events of the target topics with the same values for the equal tags are not sent to handlers.
+// Their state is still updated and marked as inhibited.
+type InhibitionOptions struct {
+	ID string `json:"id" yaml:"id"`
+	// Pattern matching the topic of source events.
+	SourceTopic string `json:"source-topic" yaml:"source-topic"`
+	// Map of tag to a pattern matching the tag value of source events.
+	SourceTags map[string]string `json:"source-tags,omitempty" yaml:"source-tags"`
+	// Minimum level of source events, defaults to CRITICAL.
+	Level string `json:"level,omitempty" yaml:"level"`
+	// Patterns matching the topics of inhibited events.
+	TargetTopics []string `json:"target-topics" yaml:"target-topics"`
+	// Tags that must have the same value on the source and the inhibited events.
+	Equal []string `json:"equal,omitempty" yaml:"equal"`
+}
 
+// CreateInhibition creates a new inhibition rule.
+// Errors if the inhibition already exists.
+func (c *Client) CreateInhibition(opt InhibitionOptions) (Inhibition, error) {
 	var buf bytes.Buffer
 	enc := json.NewEncoder(&buf)
 	err := enc.Encode(opt)
 	if err != nil {
-		return err
+		return Inhibition{}, err
 	}
+
+	u := *c.url
+	u.Path = inhibitionsPath
+
 	req, err := http.NewRequest("POST", u.String(), &buf)
 	if err != nil {
-		return err
+		return Inhibition{}, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	_, err = c.Do(req, nil, http.StatusNoContent)
+	i := Inhibition{}
+	_, err = c.Do(req, &i, http.StatusOK)
+	return i, err
+}
+
+// ReplaceInhibition replaces an existing inhibition rule, with the new definition.
+func (c *Client) ReplaceInhibition(link Link, opt InhibitionOptions) (Inhibition, error) {
+	i := Inhibition{}
+	if link.Href == "" {
+		return i, fmt.Errorf("invalid link %v", link)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	err := enc.Encode(opt)
 	if err != nil {
-		return err
+		return i, err
 	}
-	return nil
+
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("PUT", u.String(), &buf)
+	if err != nil {
+		return i, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = c.Do(req, &i, http.StatusOK)
+	return i, err
 }
 
-// Backup requests a backup of all storage from Kapacitor.
-// A short read is possible, to verify that the backup was successful
-// check that the number of bytes read matches the returned size.
-func (c *Client) Backup() (int64, io.ReadCloser, error) {
+// Inhibition retrieves an inhibition rule.
+// Errors if no inhibition exists.
+func (c *Client) Inhibition(link Link) (Inhibition, error) {
+	i := Inhibition{}
+	if link.Href == "" {
+		return i, fmt.Errorf("invalid link %v", link)
+	}
+
 	u := *c.url
-	u.Path = backupPath
+	u.Path = link.Href
 
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
-		return 0, nil, err
+		return i, err
 	}
-	err = c.prepRequest(req)
-	if err != nil {
-		return 0, nil, err
+
+	_, err = c.Do(req, &i, http.StatusOK)
+	return i, err
+}
+
+// DeleteInhibition deletes an inhibition rule.
+func (c *Client) DeleteInhibition(link Link) error {
+	if link.Href == "" {
+		return fmt.Errorf("invalid link %v", link)
 	}
-	resp, err := c.httpClient.Do(req)
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
 	if err != nil {
-		return 0, nil, err
+		return err
 	}
-	return resp.ContentLength, resp.Body, nil
+
+	_, err = c.Do(req, nil, http.StatusNoContent)
+	return err
+}
+
+type ListInhibitionsOptions struct {
+	Pattern string
+}
+
+func (o *ListInhibitionsOptions) Default() {}
+
+func (o *ListInhibitionsOptions) Values() *url.Values {
+	v := &url.Values{}
+	v.Set("pattern", o.Pattern)
+	return v
+}
+
+func (c *Client) ListInhibitions(opt *ListInhibitionsOptions) (Inhibitions, error) {
+	inhibitions := Inhibitions{}
+	if opt == nil {
+		opt = new(ListInhibitionsOptions)
+	}
+	opt.Default()
+
+	u := *c.url
+	u.Path = inhibitionsPath
+	u.RawQuery = opt.Values().Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return inhibitions, err
+	}
+
+	_, err = c.Do(req, &inhibitions, http.StatusOK)
+	return inhibitions, err
+}
+
+type StorageList struct {
+	Link    Link      `json:"link"`
+	Storage []Storage `json:"storage"`
+}
+
+type Storage struct {
+	Link Link   `json:"link"`
+	Name string `json:"name"`
+}
+
+type StorageAction int
+
+const (
+	_ StorageAction = iota
+	StorageRebuild
+)
+
+func (sa StorageAction) MarshalText() ([]byte, error) {
+	switch sa {
+	case StorageRebuild:
+		return []byte("rebuild"), nil
+	default:
+		return nil, fmt.Errorf("unknown StorageAction %d", sa)
+	}
+}
+
+func (sa *StorageAction) UnmarshalText(text []byte) error {
+	switch s := string(text); s {
+	case "rebuild":
+		*sa = StorageRebuild
+	default:
+		return fmt.Errorf("unknown StorageAction %s", s)
+	}
+	return nil
+}
+
+func (sa StorageAction) String() string {
+	s, err := sa.MarshalText()
+	if err != nil {
+		return err.Error()
+	}
+	return string(s)
+}
+
+type StorageActionOptions struct {
+	Action StorageAction `json:"action"`
+}
+
+func (c *Client) ListStorage() (StorageList, error) {
+	list := StorageList{}
+	u := *c.url
+	u.Path = storesPath
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return list, err
+	}
+
+	_, err = c.Do(req, &list, http.StatusOK)
+	if err != nil {
+		return list, err
+	}
+	return list, nil
+}
+
+func (c *Client) DoStorageAction(l Link, opt StorageActionOptions) error {
+	u := *c.url
+	u.Path = l.Href
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	err := enc.Encode(opt)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", u.String(), &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = c.Do(req, nil, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Backup requests a backup of all storage from Kapacitor.
+// A short read is possible, to verify that the backup was successful
+// check that the number of bytes read matches the returned size.
+func (c *Client) Backup() (int64, io.ReadCloser, error) {
+	u := *c.url
+	u.Path = backupPath
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	err = c.prepRequest(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.ContentLength, resp.Body, nil
+}
+
+type Blob struct {
+	Link    Link      `json:"link"`
+	ID      string    `json:"id"`
+	Size    int64     `json:"size"`
+	Created time.Time `json:"created"`
+}
+
+type BlobTag struct {
+	Link     Link      `json:"link"`
+	Name     string    `json:"name"`
+	Blob     Link      `json:"blob"`
+	BlobID   string    `json:"blob-id"`
+	Modified time.Time `json:"modified"`
+}
+
+type BlobTagHistory struct {
+	Link    Link           `json:"link"`
+	Name    string         `json:"name"`
+	History []BlobTagEntry `json:"history"`
+}
+
+type BlobTagEntry struct {
+	Blob   Link      `json:"blob"`
+	BlobID string    `json:"blob-id"`
+	Time   time.Time `json:"time"`
+}
+
+// Create a new blob from the content of r.
+// Blobs are content addressed so creating a blob with existing content returns the existing blob.
+func (c *Client) CreateBlob(r io.Reader) (Blob, error) {
+	u := *c.url
+	u.Path = blobsPath
+
+	req, err := http.NewRequest("POST", u.String(), r)
+	if err != nil {
+		return Blob{}, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	b := Blob{}
+	_, err = c.Do(req, &b, http.StatusOK)
+	return b, err
+}
+
+// Blob returns the content of a blob.
+// The link may be either a blob link or a blob tag link,
+// in which case the content of the blob most recently tagged is returned.
+// The returned reader must be closed.
+func (c *Client) Blob(link Link) (io.ReadCloser, error) {
+	if link.Href == "" {
+		return nil, fmt.Errorf("invalid link %v", link)
+	}
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	err = c.prepRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, c.decodeError(resp)
+	}
+	return resp.Body, nil
+}
+
+// Delete a blob.
+func (c *Client) DeleteBlob(link Link) error {
+	if link.Href == "" {
+		return fmt.Errorf("invalid link %v", link)
+	}
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Do(req, nil, http.StatusNoContent)
+	return err
+}
+
+type TagBlobOptions struct {
+	Blob string `json:"blob"`
+}
+
+// Tag a blob, the tag history records any previous blobs the tag referenced.
+func (c *Client) TagBlob(link Link, opt TagBlobOptions) (BlobTag, error) {
+	t := BlobTag{}
+	if link.Href == "" {
+		return t, fmt.Errorf("invalid link %v", link)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	err := enc.Encode(opt)
+	if err != nil {
+		return t, err
+	}
+
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("PUT", u.String(), &buf)
+	if err != nil {
+		return t, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = c.Do(req, &t, http.StatusOK)
+	return t, err
+}
+
+// Delete a blob tag, the blobs the tag referenced are not deleted.
+func (c *Client) DeleteBlobTag(link Link) error {
+	if link.Href == "" {
+		return fmt.Errorf("invalid link %v", link)
+	}
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Do(req, nil, http.StatusNoContent)
+	return err
+}
+
+// ListTagHistory returns the history of blobs for a tag, most recent first.
+func (c *Client) ListTagHistory(link Link) (BlobTagHistory, error) {
+	h := BlobTagHistory{}
+	if link.Href == "" {
+		return h, fmt.Errorf("invalid link %v", link)
+	}
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return h, err
+	}
+
+	_, err = c.Do(req, &h, http.StatusOK)
+	return h, err
+}
+
+// SideloadReload rereads the files of all sideload sources.
+func (c *Client) SideloadReload() error {
+	u := *c.url
+	u.Path = sideloadReloadPath
+
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Do(req, nil, http.StatusNoContent)
+	return err
+}
+
+type User struct {
+	Link     Link   `json:"link"`
+	Username string `json:"username"`
+	Admin    bool   `json:"admin"`
+	// Map of resource to the privileges the user has for the resource.
+	// Privileges are one of "read", "write", "delete" or "all".
+	Privileges map[string][]string `json:"privileges"`
+}
+
+type CreateUserOptions struct {
+	Username   string              `json:"username"`
+	Password   string              `json:"password"`
+	Admin      bool                `json:"admin"`
+	Privileges map[string][]string `json:"privileges,omitempty"`
+}
+
+// CreateUser creates a new user.
+// Errors if the user already exists.
+func (c *Client) CreateUser(opt CreateUserOptions) (User, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	err := enc.Encode(opt)
+	if err != nil {
+		return User{}, err
+	}
+
+	u := *c.url
+	u.Path = usersPath
+
+	req, err := http.NewRequest("POST", u.String(), &buf)
+	if err != nil {
+		return User{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	user := User{}
+	_, err = c.Do(req, &user, http.StatusOK)
+	return user, err
+}
+
+// UpdateUserOptions are the changes to make to a user.
+// Empty values leave the existing value unchanged.
+type UpdateUserOptions struct {
+	Password   string              `json:"password,omitempty"`
+	Admin      *bool               `json:"admin,omitempty"`
+	Privileges map[string][]string `json:"privileges,omitempty"`
+}
+
+// UpdateUser updates the password, admin status or privileges of an existing user.
+// The privileges replace all existing privileges of the user.
+func (c *Client) UpdateUser(link Link, opt UpdateUserOptions) (User, error) {
+	user := User{}
+	if link.Href == "" {
+		return user, fmt.Errorf("invalid link %v", link)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	err := enc.Encode(opt)
+	if err != nil {
+		return user, err
+	}
+
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("PATCH", u.String(), &buf)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = c.Do(req, &user, http.StatusOK)
+	return user, err
+}
+
+// User retrieves a user.
+// Errors if no user exists.
+func (c *Client) User(link Link) (User, error) {
+	user := User{}
+	if link.Href == "" {
+		return user, fmt.Errorf("invalid link %v", link)
+	}
+
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return user, err
+	}
+
+	_, err = c.Do(req, &user, http.StatusOK)
+	return user, err
+}
+
+// DeleteUser deletes a user.
+func (c *Client) DeleteUser(link Link) error {
+	if link.Href == "" {
+		return fmt.Errorf("invalid link %v", link)
+	}
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Do(req, nil, http.StatusNoContent)
+	return err
+}
+
+type ListUsersOptions struct {
+	Pattern string
+	Offset  int
+	Limit   int
+}
+
+func (o *ListUsersOptions) Default() {
+	if o.Limit == 0 {
+		o.Limit = 100
+	}
+}
+
+func (o *ListUsersOptions) Values() *url.Values {
+	v := &url.Values{}
+	v.Set("pattern", o.Pattern)
+	v.Set("offset", strconv.FormatInt(int64(o.Offset), 10))
+	v.Set("limit", strconv.FormatInt(int64(o.Limit), 10))
+	return v
+}
+
+// ListUsers returns the users matching the pattern.
+func (c *Client) ListUsers(opt *ListUsersOptions) ([]User, error) {
+	if opt == nil {
+		opt = new(ListUsersOptions)
+	}
+	opt.Default()
+
+	u := *c.url
+	u.Path = usersPath
+	u.RawQuery = opt.Values().Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Response type
+	type response struct {
+		Users []User `json:"users"`
+	}
+
+	r := &response{}
+
+	_, err = c.Do(req, r, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return r.Users, nil
+}
+
+type Token struct {
+	Link        Link       `json:"link"`
+	ID          string     `json:"id"`
+	Type        string     `json:"type"`
+	Username    string     `json:"username"`
+	Description string     `json:"description"`
+	Created     time.Time  `json:"created"`
+	Expires     *time.Time `json:"expires,omitempty"`
+	// The privileges the token is restricted to, empty means all the privileges of the user.
+	Privileges map[string][]string `json:"privileges,omitempty"`
+	// The value to use for bearer authentication, only returned when the token is created.
+	Token string `json:"token,omitempty"`
+}
+
+type CreateTokenOptions struct {
+	// Type of the token, either "api" for a long-lived token or "jwt" for a short-lived JWT.
+	// Defaults to "api".
+	Type string `json:"type,omitempty"`
+	// Username of the owner of the token, defaults to the authenticated user.
+	// Only admin users can create tokens for other users.
+	Username    string `json:"username,omitempty"`
+	Description string `json:"description,omitempty"`
+	// How long until the token expires.
+	// API tokens do not expire by default, JWTs expire after an hour by default.
+	ExpiresIn Duration `json:"expires-in,omitempty"`
+	// Restrict the token to a subset of the privileges of the user.
+	Privileges map[string][]string `json:"privileges,omitempty"`
+}
+
+// CreateToken issues a new token.
+// The returned token contains the value to use for bearer authentication, it cannot be retrieved again.
+func (c *Client) CreateToken(opt CreateTokenOptions) (Token, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	err := enc.Encode(opt)
+	if err != nil {
+		return Token{}, err
+	}
+
+	u := *c.url
+	u.Path = tokensPath
+
+	req, err := http.NewRequest("POST", u.String(), &buf)
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	t := Token{}
+	_, err = c.Do(req, &t, http.StatusOK)
+	return t, err
+}
+
+// Token retrieves the details of a token.
+// Errors if no token exists.
+func (c *Client) Token(link Link) (Token, error) {
+	t := Token{}
+	if link.Href == "" {
+		return t, fmt.Errorf("invalid link %v", link)
+	}
+
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return t, err
+	}
+
+	_, err = c.Do(req, &t, http.StatusOK)
+	return t, err
+}
+
+// RevokeToken revokes a token so it can no longer be used to authenticate.
+func (c *Client) RevokeToken(link Link) error {
+	if link.Href == "" {
+		return fmt.Errorf("invalid link %v", link)
+	}
+	u := *c.url
+	u.Path = link.Href
+
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Do(req, nil, http.StatusNoContent)
+	return err
+}
+
+type ListTokensOptions struct {
+	Pattern string
+	Offset  int
+	Limit   int
+}
+
+func (o *ListTokensOptions) Default() {
+	if o.Limit == 0 {
+		o.Limit = 100
+	}
+}
+
+func (o *ListTokensOptions) Values() *url.Values {
+	v := &url.Values{}
+	v.Set("pattern", o.Pattern)
+	v.Set("offset", strconv.FormatInt(int64(o.Offset), 10))
+	v.Set("limit", strconv.FormatInt(int64(o.Limit), 10))
+	return v
+}
+
+// ListTokens returns the tokens whose IDs match the pattern.
+// Only admin users can see the tokens of other users.
+func (c *Client) ListTokens(opt *ListTokensOptions) ([]Token, error) {
+	if opt == nil {
+		opt = new(ListTokensOptions)
+	}
+	opt.Default()
+
+	u := *c.url
+	u.Path = tokensPath
+	u.RawQuery = opt.Values().Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Response type
+	type response struct {
+		Tokens []Token `json:"tokens"`
+	}
+
+	r := &response{}
+
+	_, err = c.Do(req, r, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return r.Tokens, nil
+}
+
+// AuditRecord is an entry of the audit log describing a change made via the API.
+type AuditRecord struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Username string    `json:"username"`
+	// Remote address of the request
+	Source   string `json:"source"`
+	Method   string `json:"method"`
+	Resource string `json:"resource"`
+	// One of create, update, delete, enable or disable
+	Action string `json:"action"`
+	// JSON representation of the resource before and after the change.
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+	// JSON merge patch from the before to the after representation.
+	Diff json.RawMessage `json:"diff,omitempty"`
+}
+
+type ListAuditRecordsOptions struct {
+	User string
+	// Glob pattern matched against the path of the resource,
+	// either the full path or the path relative to the API base path, i.e. tasks/cpu_*.
+	Resource string
+	Action   string
+	// Only records in the time range [Since, Until) are returned, zero values are unbounded.
+	Since  time.Time
+	Until  time.Time
+	Offset int
+	Limit  int
+}
+
+func (o *ListAuditRecordsOptions) Default() {
+	if o.Limit == 0 {
+		o.Limit = 100
+	}
+}
+
+func (o *ListAuditRecordsOptions) Values() *url.Values {
+	v := &url.Values{}
+	if o.User != "" {
+		v.Set("user", o.User)
+	}
+	if o.Resource != "" {
+		v.Set("resource", o.Resource)
+	}
+	if o.Action != "" {
+		v.Set("action", o.Action)
+	}
+	if !o.Since.IsZero() {
+		v.Set("since", o.Since.Format(time.RFC3339Nano))
+	}
+	if !o.Until.IsZero() {
+		v.Set("until", o.Until.Format(time.RFC3339Nano))
+	}
+	v.Set("offset", strconv.FormatInt(int64(o.Offset), 10))
+	v.Set("limit", strconv.FormatInt(int64(o.Limit), 10))
+	return v
+}
+
+// ListAuditRecords returns the audit records that match the options, newest first.
+func (c *Client) ListAuditRecords(opt *ListAuditRecordsOptions) ([]AuditRecord, error) {
+	if opt == nil {
+		opt = new(ListAuditRecordsOptions)
+	}
+	opt.Default()
+
+	u := *c.url
+	u.Path = auditPath
+	u.RawQuery = opt.Values().Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := struct {
+		Records []AuditRecord `json:"records"`
+	}{}
+	_, err = c.Do(req, &r, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return r.Records, nil
 }
 
 type LogLevelOptions struct {
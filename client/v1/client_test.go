@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -327,6 +328,34 @@ func Test_ReportsErrors(t *testing.T) {
 				return err
 			},
 		},
+		{
+			name: "CreateBlob",
+			fnc: func(c *client.Client) error {
+				_, err := c.CreateBlob(strings.NewReader("data"))
+				return err
+			},
+		},
+		{
+			name: "Blob",
+			fnc: func(c *client.Client) error {
+				_, err := c.Blob(c.BlobLink("id"))
+				return err
+			},
+		},
+		{
+			name: "TagBlob",
+			fnc: func(c *client.Client) error {
+				_, err := c.TagBlob(c.BlobTagLink("tag"), client.TagBlobOptions{})
+				return err
+			},
+		},
+		{
+			name: "ListTagHistory",
+			fnc: func(c *client.Client) error {
+				_, err := c.ListTagHistory(c.BlobTagHistoryLink("tag"))
+				return err
+			},
+		},
 		{
 			name: "LogLevel",
 			fnc: func(c *client.Client) error {
@@ -3006,6 +3035,174 @@ func Test_DeleteTopicHandler(t *testing.T) {
 	}
 }
 
+func Test_CreateBlob(t *testing.T) {
+	s, c, err := newClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		if r.URL.String() == "/kapacitor/v1/blobs" &&
+			r.Method == "POST" &&
+			string(data) == "model data" {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{
+	"link":{"rel":"self","href":"/kapacitor/v1/blobs/abc"},
+	"id": "abc",
+	"size": 10,
+	"created": "2017-07-01T00:00:00Z"
+}`)
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "request: %v", r)
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	b, err := c.CreateBlob(strings.NewReader("model data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := client.Blob{
+		Link:    client.Link{Relation: client.Self, Href: "/kapacitor/v1/blobs/abc"},
+		ID:      "abc",
+		Size:    10,
+		Created: time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(exp, b) {
+		t.Errorf("unexpected create blob result:\ngot:\n%v\nexp:\n%v", b, exp)
+	}
+}
+
+func Test_Blob(t *testing.T) {
+	s, c, err := newClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (r.URL.String() == "/kapacitor/v1/blobs/abc" || r.URL.String() == "/kapacitor/v1/blobs/tags/model") &&
+			r.Method == "GET" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, "model data")
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "request: %v", r)
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, l := range []client.Link{c.BlobLink("abc"), c.BlobTagLink("model")} {
+		r, err := c.Blob(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, exp := string(data), "model data"; got != exp {
+			t.Errorf("unexpected blob data for %s: got %q exp %q", l.Href, got, exp)
+		}
+	}
+}
+
+func Test_TagBlob(t *testing.T) {
+	s, c, err := newClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		options := client.TagBlobOptions{}
+		json.NewDecoder(r.Body).Decode(&options)
+		if r.URL.String() == "/kapacitor/v1/blobs/tags/model" &&
+			r.Method == "PUT" &&
+			options.Blob == "abc" {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{
+	"link":{"rel":"self","href":"/kapacitor/v1/blobs/tags/model"},
+	"name": "model",
+	"blob": {"rel":"self","href":"/kapacitor/v1/blobs/abc"},
+	"blob-id": "abc",
+	"modified": "2017-07-01T00:00:00Z"
+}`)
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "request: %v", r)
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	tag, err := c.TagBlob(c.BlobTagLink("model"), client.TagBlobOptions{Blob: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := client.BlobTag{
+		Link:     client.Link{Relation: client.Self, Href: "/kapacitor/v1/blobs/tags/model"},
+		Name:     "model",
+		Blob:     client.Link{Relation: client.Self, Href: "/kapacitor/v1/blobs/abc"},
+		BlobID:   "abc",
+		Modified: time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(exp, tag) {
+		t.Errorf("unexpected tag blob result:\ngot:\n%v\nexp:\n%v", tag, exp)
+	}
+}
+
+func Test_ListTagHistory(t *testing.T) {
+	s, c, err := newClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.String() == "/kapacitor/v1/blobs/tags/model/history" &&
+			r.Method == "GET" {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{
+	"link":{"rel":"self","href":"/kapacitor/v1/blobs/tags/model/history"},
+	"name": "model",
+	"history": [
+		{
+			"blob": {"rel":"self","href":"/kapacitor/v1/blobs/def"},
+			"blob-id": "def",
+			"time": "2017-07-02T00:00:00Z"
+		},
+		{
+			"blob": {"rel":"self","href":"/kapacitor/v1/blobs/abc"},
+			"blob-id": "abc",
+			"time": "2017-07-01T00:00:00Z"
+		}
+	]
+}`)
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "request: %v", r)
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	history, err := c.ListTagHistory(c.BlobTagHistoryLink("model"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := client.BlobTagHistory{
+		Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/blobs/tags/model/history"},
+		Name: "model",
+		History: []client.BlobTagEntry{
+			{
+				Blob:   client.Link{Relation: client.Self, Href: "/kapacitor/v1/blobs/def"},
+				BlobID: "def",
+				Time:   time.Date(2017, 7, 2, 0, 0, 0, 0, time.UTC),
+			},
+			{
+				Blob:   client.Link{Relation: client.Self, Href: "/kapacitor/v1/blobs/abc"},
+				BlobID: "abc",
+				Time:   time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	if !reflect.DeepEqual(exp, history) {
+		t.Errorf("unexpected tag history result:\ngot:\n%v\nexp:\n%v", history, exp)
+	}
+}
+
 func Test_LogLevel(t *testing.T) {
 	s, c, err := newClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var opts client.LogLevelOptions
@@ -34,7 +34,7 @@ var defaultURL = "http://localhost:9092"
 var defaultSkipVerify = false
 
 var mainFlags = flag.NewFlagSet("main", flag.ExitOnError)
-var kapacitordURL = mainFlags.String("url", "", "The URL http(s)://host:port of the kapacitord server. Defaults to the KAPACITOR_URL environment variable or "+defaultURL+" if not set.")
+var kapacitordURL = mainFlags.String("url", "", "The URL http(s)://[username:password@]host:port of the kapacitord server. Defaults to the KAPACITOR_URL environment variable or "+defaultURL+" if not set.")
 var skipVerify = mainFlags.Bool("skipVerify", false, "Disable SSL verification (note, this is insecure). Defaults to the KAPACITOR_UNSAFE_SSL environment variable or "+strconv.FormatBool(defaultSkipVerify)+" if not set.")
 
 var l = log.New(os.Stderr, "[run] ", log.LstdFlags)
@@ -50,18 +50,26 @@ Commands:
 	define                Create/update a task.
 	define-template       Create/update a template.
 	define-topic-handler  Create/update an alert handler for a topic.
+	define-inhibition     Create/update an inhibition rule between alert topics.
+	define-user           Create/update a user.
+	create-token          Create an API token or JWT for authenticating with the API.
+	silence               Create/update a silence to stop sending matching alerts to handlers.
+	ack                   Acknowledge an alert event to stop repeat notifications.
+	unack                 Remove the acknowledgement of an alert event.
 	replay                Replay a recording to a task.
 	replay-live           Replay data against a task without recording it.
 	enable                Enable and start running a task with live data.
 	disable               Stop running a task.
 	reload                Reload a running task with an updated task definition.
 	push                  Publish a task definition to another Kapacitor instance. Not implemented yet.
-	delete                Delete tasks, templates, recordings, replays, topics or topic-handlers.
-	list                  List information about tasks, templates, recordings, replays, topics, topic-handlers or service-tests.
+	delete                Delete tasks, templates, recordings, replays, topics, topic-handlers, silences, inhibitions, users or tokens.
+	list                  List information about tasks, templates, recordings, replays, topics, topic-handlers, silences, inhibitions, service-tests, users or tokens.
 	show                  Display detailed information about a task.
 	show-template         Display detailed information about a template.
 	show-topic-handler    Display detailed information about an alert handler for a topic.
 	show-topic            Display detailed information about an alert topic.
+	show-user             Display detailed information about a user.
+	audit                 Display the audit log of changes made via the API.
 	backup                Backup the Kapacitor database.
 	level                 Sets the logging level on the kapacitord server.
 	stats                 Display various stats about Kapacitor.
@@ -73,9 +81,18 @@ Commands:
 Options:
 `
 
+var usageEnvStr = `
+Environment:
+
+	KAPACITOR_URL         The URL of the kapacitord server, see the -url option.
+	KAPACITOR_UNSAFE_SSL  Disable SSL verification, see the -skipVerify option.
+	KAPACITOR_TOKEN       An API token or JWT used to authenticate with the kapacitord server.
+`
+
 func usage() {
 	fmt.Fprintln(os.Stderr, usageStr)
 	mainFlags.PrintDefaults()
+	fmt.Fprintln(os.Stderr, usageEnvStr)
 	os.Exit(1)
 }
 
@@ -105,7 +122,7 @@ func main() {
 	}
 
 	var err error
-	cli, err = connect(url, skipSSL)
+	cli, err = connect(url, skipSSL, os.Getenv("KAPACITOR_TOKEN"))
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(4)
@@ -142,6 +159,24 @@ func main() {
 	case "define-topic-handler":
 		commandArgs = args
 		commandF = doDefineTopicHandler
+	case "define-inhibition":
+		commandArgs = args
+		commandF = doDefineInhibition
+	case "define-user":
+		commandArgs = args
+		commandF = doDefineUser
+	case "create-token":
+		commandArgs = args
+		commandF = doCreateToken
+	case "silence":
+		commandArgs = args
+		commandF = doSilence
+	case "ack":
+		commandArgs = args
+		commandF = doAck
+	case "unack":
+		commandArgs = args
+		commandF = doUnack
 	case "replay":
 		replayFlags.Parse(args)
 		commandArgs = replayFlags.Args()
@@ -160,7 +195,8 @@ func main() {
 		commandArgs = args
 		commandF = doDisable
 	case "reload":
-		commandArgs = args
+		reloadFlags.Parse(args)
+		commandArgs = reloadFlags.Args()
 		commandF = doReload
 	case "delete":
 		commandArgs = args
@@ -179,8 +215,15 @@ func main() {
 		commandArgs = args
 		commandF = doShowTopicHandler
 	case "show-topic":
-		commandArgs = args
+		showTopicFlags.Parse(args)
+		commandArgs = showTopicFlags.Args()
 		commandF = doShowTopic
+	case "show-user":
+		commandArgs = args
+		commandF = doShowUser
+	case "audit":
+		commandArgs = args
+		commandF = doAudit
 	case "backup":
 		commandArgs = args
 		commandF = doBackup
@@ -216,7 +259,13 @@ func init() {
 	replayFlags.Usage = replayUsage
 	defineFlags.Usage = defineUsage
 	defineTemplateFlags.Usage = defineTemplateUsage
+	defineUserFlags.Usage = defineUserUsage
+	createTokenFlags.Usage = createTokenUsage
+	silenceFlags.Usage = silenceUsage
+	ackFlags.Usage = ackUsage
+	auditFlags.Usage = auditUsage
 	showFlags.Usage = showUsage
+	showTopicFlags.Usage = showTopicUsage
 
 	recordStreamFlags.Usage = recordStreamUsage
 	recordBatchFlags.Usage = recordBatchUsage
@@ -236,11 +285,18 @@ func (e responseError) Error() string {
 	return e.Err
 }
 
-func connect(url string, skipSSL bool) (*client.Client, error) {
-	return client.New(client.Config{
+func connect(url string, skipSSL bool, token string) (*client.Client, error) {
+	c := client.Config{
 		URL:                url,
 		InsecureSkipVerify: skipSSL,
-	})
+	}
+	if token != "" {
+		c.Credentials = &client.Credentials{
+			Method: client.BearerAuthentication,
+			Token:  token,
+		}
+	}
+	return client.New(c)
 }
 
 // Help
@@ -262,6 +318,18 @@ func doHelp(args []string) error {
 			defineTemplateFlags.Usage()
 		case "define-topic-handler":
 			defineTopicHandlerUsage()
+		case "define-inhibition":
+			defineInhibitionUsage()
+		case "define-user":
+			defineUserFlags.Usage()
+		case "create-token":
+			createTokenFlags.Usage()
+		case "silence":
+			silenceFlags.Usage()
+		case "ack":
+			ackFlags.Usage()
+		case "unack":
+			unackUsage()
 		case "replay":
 			replayFlags.Usage()
 		case "enable":
@@ -281,7 +349,11 @@ func doHelp(args []string) error {
 		case "show-topic-handler":
 			showTopicHandlerUsage()
 		case "show-topic":
-			showTopicUsage()
+			showTopicFlags.Usage()
+		case "show-user":
+			showUserUsage()
+		case "audit":
+			auditFlags.Usage()
 		case "backup":
 			backupUsage()
 		case "level":
@@ -882,6 +954,426 @@ func doDefineTopicHandler(args []string) error {
 	return err
 }
 
+func defineInhibitionUsage() {
+	var u = `Usage: kapacitor define-inhibition <inhibition id> <path to inhibition file>
+
+	Create or update an inhibition rule.
+
+	While an alert of the source topic matching the source tags is at or above the level,
+	alerts of the target topics that have the same values for the equal tags are not sent to handlers.
+
+	An inhibition is defined via a JSON or YAML file.
+
+For example:
+
+	Define an inhibition using the uplink.yaml file:
+
+		$ kapacitor define-inhibition uplink uplink.yaml
+
+	Where uplink.yaml contains:
+
+		source-topic: uplink
+		level: CRITICAL
+		target-topics:
+		  - cpu
+		  - mem
+		equal:
+		  - datacenter
+`
+	fmt.Fprintln(os.Stderr, u)
+}
+
+func doDefineInhibition(args []string) error {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "Must provide an inhibition ID and a path to an inhibition file.")
+		defineInhibitionUsage()
+		os.Exit(2)
+	}
+	id := args[0]
+	p := args[1]
+	f, err := os.Open(p)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open inhibition file %q", p)
+	}
+
+	// Decode file into InhibitionOptions
+	var opt client.InhibitionOptions
+	ext := path.Ext(p)
+	switch ext {
+	case ".yaml", ".yml":
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read inhibition file %q", p)
+		}
+		if err := yaml.Unmarshal(data, &opt); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal yaml inhibition file %q", p)
+		}
+	case ".json":
+		if err := json.NewDecoder(f).Decode(&opt); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal json inhibition file %q", p)
+		}
+	}
+	opt.ID = id
+
+	l := cli.InhibitionLink(id)
+	inhibition, _ := cli.Inhibition(l)
+	if inhibition.ID == "" {
+		_, err = cli.CreateInhibition(opt)
+	} else {
+		_, err = cli.ReplaceInhibition(l, opt)
+	}
+	return err
+}
+
+// Define User
+var (
+	defineUserFlags = flag.NewFlagSet("define-user", flag.ExitOnError)
+	duPassword      = defineUserFlags.String("password", "", "The password of the user. Required when creating a user.")
+	duAdmin         = defineUserFlags.Bool("admin", false, "Whether the user is an admin with all privileges.")
+	duPrivileges    = make(privileges)
+)
+
+func init() {
+	defineUserFlags.Var(&duPrivileges, "privilege", `A privilege of the form "resource=privilege[,privilege...]" where privilege is one of read, write, delete or all. The flag can be specified multiple times.`)
+}
+
+// privileges maps a resource to its privileges.
+type privileges map[string][]string
+
+func (p *privileges) String() string {
+	return fmt.Sprint(*p)
+}
+
+// Parse string of the form resource=privilege[,privilege...].
+func (p *privileges) Set(value string) error {
+	i := strings.IndexRune(value, '=')
+	if i <= 0 {
+		return errors.New("privilege must be in the form resource=privilege[,privilege...]")
+	}
+	resource := value[:i]
+	for _, privilege := range strings.Split(value[i+1:], ",") {
+		if privilege == "" {
+			return errors.New("privilege cannot be empty")
+		}
+		(*p)[resource] = append((*p)[resource], privilege)
+	}
+	return nil
+}
+
+func defineUserUsage() {
+	var u = `Usage: kapacitor define-user [options] <username>
+
+	Create or update a user.
+
+	When updating a user only the provided options are changed,
+	specifying any privileges replaces all existing privileges of the user.
+
+	Resources are paths of the API, for example /api/tasks, or databases, for example /database/telegraf_clean.
+	Resources may be patterns to grant privileges to a subset of tasks, templates or alert topics,
+	for example /api/tasks/teama_*. Listing only returns the items the user has privileges for.
+
+For example:
+
+	Create an admin user:
+
+		$ kapacitor define-user -password secret -admin alice
+
+	Create a user that can read and write tasks:
+
+		$ kapacitor define-user -password secret -privilege /api/tasks=read,write bob
+
+	Create a user that can only manage the tasks and alert topics of a team:
+
+		$ kapacitor define-user -password secret -privilege '/api/tasks/teama_*=all' -privilege '/api/preview/alerts/topics/teama_*=all' carol
+
+	Change the password of a user:
+
+		$ kapacitor define-user -password new-secret bob
+
+Options:
+`
+	fmt.Fprintln(os.Stderr, u)
+	defineUserFlags.PrintDefaults()
+}
+
+func doDefineUser(args []string) error {
+	defineUserFlags.Parse(args)
+	if defineUserFlags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Must provide a username.")
+		defineUserFlags.Usage()
+		os.Exit(2)
+	}
+	username := defineUserFlags.Arg(0)
+	adminSet := false
+	defineUserFlags.Visit(func(f *flag.Flag) {
+		if f.Name == "admin" {
+			adminSet = true
+		}
+	})
+
+	l := cli.UserLink(username)
+	user, _ := cli.User(l)
+	if user.Username == "" {
+		if *duPassword == "" {
+			return errors.New("must provide a password when creating a user")
+		}
+		_, err := cli.CreateUser(client.CreateUserOptions{
+			Username:   username,
+			Password:   *duPassword,
+			Admin:      *duAdmin,
+			Privileges: duPrivileges,
+		})
+		return err
+	}
+	opt := client.UpdateUserOptions{
+		Password: *duPassword,
+	}
+	if adminSet {
+		opt.Admin = duAdmin
+	}
+	if len(duPrivileges) > 0 {
+		opt.Privileges = duPrivileges
+	}
+	_, err := cli.UpdateUser(l, opt)
+	return err
+}
+
+// Create Token
+var (
+	createTokenFlags = flag.NewFlagSet("create-token", flag.ExitOnError)
+	ctType           = createTokenFlags.String("type", "api", "The type of the token, either api for a long-lived token or jwt for a short-lived JWT.")
+	ctUser           = createTokenFlags.String("user", "", "The user that owns the token. Defaults to the authenticated user, only admin users can create tokens for other users.")
+	ctDescription    = createTokenFlags.String("description", "", "A description of the token, for example what it is used for.")
+	ctExpiresIn      = createTokenFlags.Duration("expires-in", 0, "How long until the token expires. API tokens do not expire by default, JWTs expire after 1h by default.")
+	ctPrivileges     = make(privileges)
+)
+
+func init() {
+	createTokenFlags.Var(&ctPrivileges, "privilege", `Restrict the token to a privilege of the form "resource=privilege[,privilege...]". The flag can be specified multiple times. Defaults to all privileges of the user.`)
+}
+
+func createTokenUsage() {
+	var u = `Usage: kapacitor create-token [options]
+
+	Create a token for authenticating with the API using the header "Authorization: Bearer <token>".
+
+	The token is printed once and cannot be retrieved again.
+	Set the KAPACITOR_TOKEN environment variable to use the token with the kapacitor command.
+
+For example:
+
+	Create a token for a CI pipeline that can only deploy tasks:
+
+		$ kapacitor create-token -description ci -privilege /api/tasks=read,write
+
+	Create a JWT for the user bob that expires in 10 minutes:
+
+		$ kapacitor create-token -type jwt -user bob -expires-in 10m
+
+Options:
+`
+	fmt.Fprintln(os.Stderr, u)
+	createTokenFlags.PrintDefaults()
+}
+
+func doCreateToken(args []string) error {
+	createTokenFlags.Parse(args)
+	if createTokenFlags.NArg() != 0 {
+		createTokenFlags.Usage()
+		os.Exit(2)
+	}
+	opt := client.CreateTokenOptions{
+		Type:        *ctType,
+		Username:    *ctUser,
+		Description: *ctDescription,
+		ExpiresIn:   client.Duration(*ctExpiresIn),
+	}
+	if len(ctPrivileges) > 0 {
+		opt.Privileges = ctPrivileges
+	}
+	t, err := cli.CreateToken(opt)
+	if err != nil {
+		return err
+	}
+	fmt.Println(t.Token)
+	return nil
+}
+
+// Silence
+var (
+	silenceFlags    = flag.NewFlagSet("silence", flag.ExitOnError)
+	sTopic          = silenceFlags.String("topic", "", "Pattern matching the topics of silenced alerts.")
+	sEvent          = silenceFlags.String("event", "", "Pattern matching the IDs of silenced alerts.")
+	sStart          = silenceFlags.String("start", "", "The RFC3339 time the silence starts. Defaults to now.")
+	sEnd            = silenceFlags.String("end", "", "The RFC3339 time the silence ends. Defaults to never.")
+	sFor            = silenceFlags.Duration("for", 0, "How long the silence lasts from its start, alternative to -end.")
+	sSchedule       = silenceFlags.String("schedule", "", "Cron expression of a recurring schedule, the silence is only active for -schedule-duration after each time of the schedule.")
+	sScheduleLength = silenceFlags.Duration("schedule-duration", 0, "How long the silence is active after each time of the schedule.")
+	sComment        = silenceFlags.String("comment", "", "A comment describing the reason for the silence.")
+	sTags           = make(tagMatchers)
+)
+
+func init() {
+	silenceFlags.Var(&sTags, "tag", `Match alerts with a tag of the form "tag=pattern". The flag can be specified multiple times.`)
+}
+
+type tagMatchers map[string]string
+
+func (t *tagMatchers) String() string {
+	return fmt.Sprint(*t)
+}
+
+// Parse string of the form tag=pattern.
+func (t *tagMatchers) Set(value string) error {
+	i := strings.IndexRune(value, '=')
+	if i <= 0 {
+		return errors.New("tag matcher must be in the form tag=pattern")
+	}
+	(*t)[value[:i]] = value[i+1:]
+	return nil
+}
+
+func silenceUsage() {
+	var u = `Usage: kapacitor silence [options] [ID]
+
+	Create or update a silence.
+
+	Alerts matching all of the given matchers are not sent to any handlers while the silence is active.
+	The state of the alerts is still updated.
+	Patterns use shell/glob matching, see https://golang.org/pkg/path/#Match
+
+	If no ID is given a random ID is chosen. Updating a silence replaces its whole definition.
+
+For example:
+
+	Silence all alerts from the host serverA for two hours:
+
+		$ kapacitor silence -tag host=serverA -for 2h maintenance
+
+	Silence the alerts of the cpu topic every Sunday between 02:00 and 04:00:
+
+		$ kapacitor silence -topic cpu -schedule "0 2 * * SUN" -schedule-duration 2h
+
+Options:
+`
+	fmt.Fprintln(os.Stderr, u)
+	silenceFlags.PrintDefaults()
+}
+
+func doSilence(args []string) error {
+	silenceFlags.Parse(args)
+	if silenceFlags.NArg() > 1 {
+		silenceFlags.Usage()
+		os.Exit(2)
+	}
+	opt := client.SilenceOptions{
+		ID:       silenceFlags.Arg(0),
+		Topic:    *sTopic,
+		EventID:  *sEvent,
+		Schedule: *sSchedule,
+		Duration: client.Duration(*sScheduleLength),
+		Comment:  *sComment,
+	}
+	if len(sTags) > 0 {
+		opt.Tags = sTags
+	}
+	start := time.Now()
+	if *sStart != "" {
+		t, err := time.Parse(time.RFC3339, *sStart)
+		if err != nil {
+			return errors.Wrap(err, "invalid start time")
+		}
+		opt.Start = t
+		start = t
+	}
+	switch {
+	case *sEnd != "" && *sFor != 0:
+		return errors.New("cannot use both -end and -for")
+	case *sEnd != "":
+		t, err := time.Parse(time.RFC3339, *sEnd)
+		if err != nil {
+			return errors.Wrap(err, "invalid end time")
+		}
+		opt.End = t
+	case *sFor != 0:
+		opt.End = start.Add(*sFor)
+	}
+
+	exists := false
+	l := cli.SilenceLink(opt.ID)
+	if opt.ID != "" {
+		_, err := cli.Silence(l)
+		exists = err == nil
+	}
+	var s client.Silence
+	var err error
+	if exists {
+		s, err = cli.ReplaceSilence(l, opt)
+	} else {
+		s, err = cli.CreateSilence(opt)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(s.ID)
+	return nil
+}
+
+// Ack
+var (
+	ackFlags   = flag.NewFlagSet("ack", flag.ExitOnError)
+	ackComment = ackFlags.String("comment", "", "A comment describing the acknowledgement.")
+	ackTimeout = ackFlags.Duration("timeout", 0, "How long until the acknowledgement expires. By default it lasts until the level of the event changes.")
+)
+
+func ackUsage() {
+	var u = `Usage: kapacitor ack [options] <topic ID> <event ID>
+
+	Acknowledge an alert event.
+
+	Handlers are informed that the event is acknowledged, Slack and email handlers
+	do not send acknowledged events. The acknowledgement lasts until the level of the event changes
+	or the timeout expires.
+
+For example:
+
+		$ kapacitor ack -comment "looking into it" -timeout 1h cpu serverA
+
+Options:
+`
+	fmt.Fprintln(os.Stderr, u)
+	ackFlags.PrintDefaults()
+}
+
+func doAck(args []string) error {
+	ackFlags.Parse(args)
+	if ackFlags.NArg() != 2 {
+		ackFlags.Usage()
+		os.Exit(2)
+	}
+	_, err := cli.AckTopicEvent(cli.TopicEventLink(ackFlags.Arg(0), ackFlags.Arg(1)), client.EventAckOptions{
+		Comment: *ackComment,
+		Timeout: client.Duration(*ackTimeout),
+	})
+	return err
+}
+
+func unackUsage() {
+	var u = `Usage: kapacitor unack <topic ID> <event ID>
+
+	Remove the acknowledgement of an alert event.
+`
+	fmt.Fprintln(os.Stderr, u)
+}
+
+func doUnack(args []string) error {
+	if len(args) != 2 {
+		unackUsage()
+		os.Exit(2)
+	}
+	return cli.UnackTopicEvent(cli.TopicEventLink(args[0], args[1]))
+}
+
 // Replay
 var (
 	replayFlags = flag.NewFlagSet("replay", flag.ExitOnError)
@@ -1256,11 +1748,16 @@ func doDisable(args []string) error {
 }
 
 // Reload
+var (
+	reloadFlags = flag.NewFlagSet("reload", flag.ExitOnError)
+	rSideload   = reloadFlags.Bool("sideload", false, "Reread the files of all sideload sources instead of reloading tasks.")
+)
 
 func reloadUsage() {
-	var u = `Usage: kapacitor reload [task ID...]
+	var u = `Usage: kapacitor reload [-sideload] [task ID...]
 
 	Disable then enable a running task.
+	Tasks reread the files of their sideload sources when they are reloaded.
 
 For example:
 
@@ -1271,11 +1768,21 @@ For example:
 	Or, you can reload by glob:
 
 		$ kapacitor reload *_alert
+
+	Or, you can reread the files of all sideload sources without reloading any tasks:
+
+		$ kapacitor reload -sideload
+
+Options:
 `
 	fmt.Fprintln(os.Stderr, u)
+	reloadFlags.PrintDefaults()
 }
 
 func doReload(args []string) error {
+	if *rSideload {
+		return cli.SideloadReload()
+	}
 	if len(args) < 1 {
 		fmt.Fprintln(os.Stderr, "Must pass at least one task ID")
 		reloadUsage()
@@ -1468,17 +1975,34 @@ func doShowTopicHandler(args []string) error {
 	fmt.Println("Kind:", h.Kind)
 	fmt.Println("Match:", h.Match)
 	fmt.Println("Options:", string(options))
+	if h.Retry != nil {
+		fmt.Printf("Retry: %d attempts, backoff %v, max backoff %v\n", h.Retry.Attempts, time.Duration(h.Retry.Backoff), time.Duration(h.Retry.MaxBackoff))
+		if h.Retry.DeadLetterTopic != "" {
+			fmt.Println("Dead-letter topic:", h.Retry.DeadLetterTopic)
+		}
+	}
+	if h.RateLimit != nil {
+		fmt.Printf("Rate limit: %d events per %v\n", h.RateLimit.Count, time.Duration(h.RateLimit.Interval))
+	}
 	return nil
 }
 
 // Show Topic
 
+var (
+	showTopicFlags   = flag.NewFlagSet("show-topic", flag.ExitOnError)
+	showTopicHistory = showTopicFlags.Int("history", 0, "Number of the most recent level transitions of events to display.")
+)
+
 func showTopicUsage() {
-	var u = `Usage: kapacitor show-topic [topic ID]
+	var u = `Usage: kapacitor show-topic [-history N] [topic ID]
 
 	Show details about a specific topic.
+
+Options:
 `
 	fmt.Fprintln(os.Stderr, u)
+	showTopicFlags.PrintDefaults()
 }
 
 type topicEvents []client.TopicEvent
@@ -1524,25 +2048,98 @@ func doShowTopic(args []string) error {
 		handlerIDs[i] = h.ID
 	}
 
-	outFmt := fmt.Sprintf("%%-%ds%%-9s%%-%ds%%-23s\n", maxEvent+1, maxMessage+1)
+	outFmt := fmt.Sprintf("%%-%ds%%-9s%%-%ds%%-23s%%-11v%%s\n", maxEvent+1, maxMessage+1)
 	fmt.Println("ID:", topic.ID)
 	fmt.Println("Level:", topic.Level)
 	fmt.Println("Collected:", topic.Collected)
+	fmt.Println("Inhibited:", topic.Inhibited)
+	fmt.Println("Suppressed:", topic.Suppressed)
+	fmt.Println("Retried:", topic.Retried)
+	fmt.Println("Failed:", topic.Failed)
 	fmt.Printf("Handlers: [%s]\n", strings.Join(handlerIDs, ", "))
 	fmt.Println("Events:")
-	fmt.Printf(outFmt, "Event", "Level", "Message", "Date")
+	fmt.Printf(outFmt, "Event", "Level", "Message", "Date", "Inhibited", "Acknowledged")
 	for _, e := range te.Events {
-		fmt.Printf(outFmt, e.ID, e.State.Level, e.State.Message, e.State.Time.Local().Format(time.RFC822))
+		ack := ""
+		if e.State.Ack != nil {
+			ack = e.State.Ack.By
+			if e.State.Ack.Comment != "" {
+				ack += ": " + e.State.Ack.Comment
+			}
+		}
+		fmt.Printf(outFmt, e.ID, e.State.Level, e.State.Message, e.State.Time.Local().Format(time.RFC822), e.State.Inhibited, ack)
+	}
+	if *showTopicHistory > 0 {
+		return showTopicHistoryRecords(topic, *showTopicHistory)
+	}
+	return nil
+}
+
+func showTopicHistoryRecords(topic client.Topic, n int) error {
+	th, err := cli.ListTopicHistory(topic.HistoryLink, &client.ListTopicHistoryOptions{Limit: n})
+	if err != nil {
+		return err
+	}
+	maxEvent := 5   // len("Event")
+	maxMessage := 7 // len("Message")
+	for _, r := range th.Records {
+		if l := len(r.EventID); l > maxEvent {
+			maxEvent = l
+		}
+		if l := len(r.Message); l > maxMessage {
+			maxMessage = l
+		}
+	}
+	outFmt := fmt.Sprintf("%%-23s%%-%ds%%-19s%%-%ds%%s\n", maxEvent+1, maxMessage+1)
+	fmt.Println("History:")
+	fmt.Printf(outFmt, "Date", "Event", "Transition", "Message", "Duration")
+	for _, r := range th.Records {
+		fmt.Printf(outFmt, r.Time.Local().Format(time.RFC822), r.EventID, r.Previous+" -> "+r.Level, r.Message, time.Duration(r.Duration))
 	}
 	return nil
 }
 
 // List
 
+// Show User
+
+func showUserUsage() {
+	var u = `Usage: kapacitor show-user [username]
+
+	Show details about a specific user.
+`
+	fmt.Fprintln(os.Stderr, u)
+}
+
+func doShowUser(args []string) error {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Must specify one username")
+		showUserUsage()
+		os.Exit(2)
+	}
+
+	u, err := cli.User(cli.UserLink(args[0]))
+	if err != nil {
+		return err
+	}
+	fmt.Println("Username:", u.Username)
+	fmt.Println("Admin:", u.Admin)
+	fmt.Println("Privileges:")
+	resources := make([]string, 0, len(u.Privileges))
+	for r := range u.Privileges {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+	for _, r := range resources {
+		fmt.Printf("\t%s: %s\n", r, strings.Join(u.Privileges[r], ","))
+	}
+	return nil
+}
+
 func listUsage() {
-	var u = `Usage: kapacitor list (tasks|templates|recordings|replays|topics|topic-handlers|service-tests) [ID or pattern]...
+	var u = `Usage: kapacitor list (tasks|templates|recordings|replays|topics|topic-handlers|silences|inhibitions|service-tests|users|tokens) [ID or pattern]...
 
-	List tasks, templates, recordings, replays, topics or handlers and their current state.
+	List tasks, templates, recordings, replays, topics, handlers, silences, inhibitions, users or tokens and their current state.
 
 	If no ID or pattern is given then all items will be listed.
 
@@ -1836,8 +2433,140 @@ func doList(args []string) error {
 		for _, t := range allTopics {
 			fmt.Fprintf(os.Stdout, outFmt, t.ID, t.Level, t.Collected)
 		}
+	case "silences":
+		maxID := 2    // len("ID")
+		maxTopic := 5 // len("Topic")
+		maxEvent := 5 // len("Event")
+		var allSilences []client.Silence
+		for _, pattern := range patterns {
+			silences, err := cli.ListSilences(&client.ListSilencesOptions{
+				Pattern: pattern,
+			})
+			if err != nil {
+				return err
+			}
+			allSilences = append(allSilences, silences.Silences...)
+			for _, s := range silences.Silences {
+				if l := len(s.ID); l > maxID {
+					maxID = l
+				}
+				if l := len(s.Topic); l > maxTopic {
+					maxTopic = l
+				}
+				if l := len(s.EventID); l > maxEvent {
+					maxEvent = l
+				}
+			}
+		}
+		outFmt := fmt.Sprintf("%%-%dv%%-8v%%-%dv%%-%dv%%-26v%%v\n", maxID+1, maxTopic+1, maxEvent+1)
+		fmt.Fprintf(os.Stdout, outFmt, "ID", "Active", "Topic", "Event", "Ends", "Tags")
+		for _, s := range allSilences {
+			ends := "never"
+			if !s.End.IsZero() {
+				ends = s.End.Local().Format(time.RFC822)
+			}
+			var tags []string
+			for tag, pattern := range s.Tags {
+				tags = append(tags, tag+"="+pattern)
+			}
+			sort.Strings(tags)
+			fmt.Fprintf(os.Stdout, outFmt, s.ID, s.Active, s.Topic, s.EventID, ends, strings.Join(tags, ","))
+		}
+	case "inhibitions":
+		maxID := 2     // len("ID")
+		maxSource := 6 // len("Source")
+		var allInhibitions []client.Inhibition
+		for _, pattern := range patterns {
+			inhibitions, err := cli.ListInhibitions(&client.ListInhibitionsOptions{
+				Pattern: pattern,
+			})
+			if err != nil {
+				return err
+			}
+			allInhibitions = append(allInhibitions, inhibitions.Inhibitions...)
+			for _, i := range inhibitions.Inhibitions {
+				if l := len(i.ID); l > maxID {
+					maxID = l
+				}
+				if l := len(i.SourceTopic); l > maxSource {
+					maxSource = l
+				}
+			}
+		}
+		outFmt := fmt.Sprintf("%%-%dv%%-%dv%%-10v%%-30v%%v\n", maxID+1, maxSource+1)
+		fmt.Fprintf(os.Stdout, outFmt, "ID", "Source", "Level", "Targets", "Equal")
+		for _, i := range allInhibitions {
+			fmt.Fprintf(os.Stdout, outFmt, i.ID, i.SourceTopic, i.Level, strings.Join(i.TargetTopics, ","), strings.Join(i.Equal, ","))
+		}
+	case "users":
+		maxName := 8 // len("Username")
+		// The users are returned in sorted order already, no need to sort them here.
+		var allUsers []client.User
+		for _, pattern := range patterns {
+			offset := 0
+			for {
+				users, err := cli.ListUsers(&client.ListUsersOptions{
+					Pattern: pattern,
+					Offset:  offset,
+					Limit:   limit,
+				})
+				if err != nil {
+					return err
+				}
+				allUsers = append(allUsers, users...)
+				for _, u := range users {
+					if l := len(u.Username); l > maxName {
+						maxName = l
+					}
+				}
+				if len(users) != limit {
+					break
+				}
+				offset += limit
+			}
+		}
+		outFmt := fmt.Sprintf("%%-%dv%%-6v\n", maxName+1)
+		fmt.Fprintf(os.Stdout, outFmt, "Username", "Admin")
+		for _, u := range allUsers {
+			fmt.Fprintf(os.Stdout, outFmt, u.Username, u.Admin)
+		}
+	case "tokens":
+		maxUser := 8 // len("Username")
+		var allTokens []client.Token
+		for _, pattern := range patterns {
+			offset := 0
+			for {
+				tokens, err := cli.ListTokens(&client.ListTokensOptions{
+					Pattern: pattern,
+					Offset:  offset,
+					Limit:   limit,
+				})
+				if err != nil {
+					return err
+				}
+				allTokens = append(allTokens, tokens...)
+				for _, t := range tokens {
+					if l := len(t.Username); l > maxUser {
+						maxUser = l
+					}
+				}
+				if len(tokens) != limit {
+					break
+				}
+				offset += limit
+			}
+		}
+		outFmt := fmt.Sprintf("%%-17v%%-5v%%-%dv%%-26v%%-26v%%v\n", maxUser+1)
+		fmt.Fprintf(os.Stdout, outFmt, "ID", "Type", "Username", "Created", "Expires", "Description")
+		for _, t := range allTokens {
+			expires := "never"
+			if t.Expires != nil {
+				expires = t.Expires.Local().Format(time.RFC822)
+			}
+			fmt.Fprintf(os.Stdout, outFmt, t.ID, t.Type, t.Username, t.Created.Local().Format(time.RFC822), expires, t.Description)
+		}
 	default:
-		return fmt.Errorf("cannot list '%s' did you mean 'tasks', 'recordings', 'replays', 'topics', 'topic-handlers' or 'service-tests'?", kind)
+		return fmt.Errorf("cannot list '%s' did you mean 'tasks', 'recordings', 'replays', 'topics', 'topic-handlers', 'silences', 'inhibitions', 'service-tests', 'users' or 'tokens'?", kind)
 	}
 	return nil
 
@@ -1845,9 +2574,9 @@ func doList(args []string) error {
 
 // Delete
 func deleteUsage() {
-	var u = `Usage: kapacitor delete (tasks|templates|recordings|replays|topics|topic-handlers) [ID or pattern]...
+	var u = `Usage: kapacitor delete (tasks|templates|recordings|replays|topics|topic-handlers|silences|inhibitions|users|tokens) [ID or pattern]...
 
-	Delete a tasks, templates, recordings, replays, topics or handlers.
+	Delete a tasks, templates, recordings, replays, topics, handlers, silences, inhibitions or users, or revoke tokens.
 
 	If a task is enabled it will be disabled and then deleted.
 
@@ -2005,8 +2734,158 @@ func doDelete(args []string) error {
 				}
 			}
 		}
+	case "users":
+		for _, pattern := range args[1:] {
+			for {
+				users, err := cli.ListUsers(&client.ListUsersOptions{
+					Pattern: pattern,
+					Limit:   limit,
+				})
+				if err != nil {
+					return err
+				}
+				for _, u := range users {
+					err := cli.DeleteUser(u.Link)
+					if err != nil {
+						return err
+					}
+				}
+				if len(users) != limit {
+					break
+				}
+			}
+		}
+	case "silences":
+		for _, pattern := range args[1:] {
+			silences, err := cli.ListSilences(&client.ListSilencesOptions{
+				Pattern: pattern,
+			})
+			if err != nil {
+				return err
+			}
+			for _, s := range silences.Silences {
+				err := cli.DeleteSilence(s.Link)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	case "inhibitions":
+		for _, pattern := range args[1:] {
+			inhibitions, err := cli.ListInhibitions(&client.ListInhibitionsOptions{
+				Pattern: pattern,
+			})
+			if err != nil {
+				return err
+			}
+			for _, i := range inhibitions.Inhibitions {
+				err := cli.DeleteInhibition(i.Link)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	case "tokens":
+		for _, pattern := range args[1:] {
+			for {
+				tokens, err := cli.ListTokens(&client.ListTokensOptions{
+					Pattern: pattern,
+					Limit:   limit,
+				})
+				if err != nil {
+					return err
+				}
+				for _, t := range tokens {
+					err := cli.RevokeToken(t.Link)
+					if err != nil {
+						return err
+					}
+				}
+				if len(tokens) != limit {
+					break
+				}
+			}
+		}
 	default:
-		return fmt.Errorf("cannot delete '%s' did you mean 'tasks', 'templates', 'recordings', 'replays', 'topics' or 'topic-handlers'?", kind)
+		return fmt.Errorf("cannot delete '%s' did you mean 'tasks', 'templates', 'recordings', 'replays', 'topics', 'topic-handlers', 'silences', 'inhibitions', 'users' or 'tokens'?", kind)
+	}
+	return nil
+}
+
+// Audit
+var (
+	auditFlags    = flag.NewFlagSet("audit", flag.ExitOnError)
+	auditUser     = auditFlags.String("user", "", "Only display changes made by the user.")
+	auditResource = auditFlags.String("resource", "", "Only display changes to resources matching the glob pattern, i.e. tasks/cpu_*.")
+	auditAction   = auditFlags.String("action", "", "Only display changes of the action, one of create, update, delete, enable or disable.")
+	auditSince    = auditFlags.Duration("since", 0, "Only display changes made within the duration, i.e. 24h.")
+	auditLimit    = auditFlags.Int("limit", 100, "The maximum number of changes to display.")
+	auditDiff     = auditFlags.Bool("diff", false, "Display the change made to each resource.")
+)
+
+func auditUsage() {
+	var u = `Usage: kapacitor audit [options]
+
+	Display the audit log of changes made via the API, newest first.
+
+For example:
+
+	Display the changes made by bob within the last day:
+
+		$ kapacitor audit -user bob -since 24h
+
+	Display the changes made to the cpu_alert task:
+
+		$ kapacitor audit -resource tasks/cpu_alert -diff
+
+Options:
+`
+	fmt.Fprintln(os.Stderr, u)
+	auditFlags.PrintDefaults()
+}
+
+func doAudit(args []string) error {
+	auditFlags.Parse(args)
+	if auditFlags.NArg() != 0 {
+		auditFlags.Usage()
+		os.Exit(2)
+	}
+	opt := &client.ListAuditRecordsOptions{
+		User:     *auditUser,
+		Resource: *auditResource,
+		Action:   *auditAction,
+		Limit:    *auditLimit,
+	}
+	if *auditSince > 0 {
+		opt.Since = time.Now().Add(-*auditSince)
+	}
+	records, err := cli.ListAuditRecords(opt)
+	if err != nil {
+		return err
+	}
+	outFmt := "%-25s%-16s%-9s%s\n"
+	fmt.Fprintf(os.Stdout, outFmt, "Time", "User", "Action", "Resource")
+	for _, r := range records {
+		fmt.Fprintf(os.Stdout, outFmt, r.Time.Local().Format(time.RFC3339), r.Username, r.Action, r.Resource)
+		if !*auditDiff {
+			continue
+		}
+		var diff []byte
+		switch {
+		case r.Diff != nil:
+			diff = r.Diff
+		case r.After != nil:
+			diff = r.After
+		case r.Before != nil:
+			diff = r.Before
+		}
+		if diff != nil {
+			var b bytes.Buffer
+			if err := json.Indent(&b, diff, "\t", "  "); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "\t%s\n", b.String())
+		}
 	}
 	return nil
 }
@@ -2,30 +2,51 @@ package kapacitor
 
 import (
 	"log"
+	"sync"
 	"time"
 
 	"github.com/influxdata/kapacitor/edge"
 	"github.com/influxdata/kapacitor/models"
 	"github.com/influxdata/kapacitor/pipeline"
+	"github.com/pkg/errors"
 )
 
 type DerivativeNode struct {
 	node
 	d *pipeline.DerivativeNode
+
+	// mu protects the groups so that they can be snapshotted while the node is running.
+	mu     sync.Mutex
+	groups map[models.GroupID]*derivativeGroup
+	// Restored previous points for groups that have not yet been seen.
+	restored map[models.GroupID]pointSnapshot
+}
+
+// derivativeSnapshot is the snapshot state of a DerivativeNode.
+type derivativeSnapshot struct {
+	// The previous point of each group.
+	Previous map[models.GroupID]pointSnapshot
 }
 
 // Create a new derivative node.
 func newDerivativeNode(et *ExecutingTask, n *pipeline.DerivativeNode, l *log.Logger) (*DerivativeNode, error) {
 	dn := &DerivativeNode{
-		node: node{Node: n, et: et, logger: l},
-		d:    n,
+		node:     node{Node: n, et: et, logger: l},
+		d:        n,
+		groups:   make(map[models.GroupID]*derivativeGroup),
+		restored: make(map[models.GroupID]pointSnapshot),
 	}
 	// Create stateful expressions
 	dn.node.runF = dn.runDerivative
 	return dn, nil
 }
 
-func (n *DerivativeNode) runDerivative([]byte) error {
+func (n *DerivativeNode) runDerivative(snapshot []byte) error {
+	if snapshot != nil {
+		if err := n.restore(snapshot); err != nil {
+			return errors.Wrap(err, "failed to restore derivative snapshot")
+		}
+	}
 	consumer := edge.NewGroupedConsumer(
 		n.ins[0],
 		n,
@@ -34,10 +55,49 @@ func (n *DerivativeNode) runDerivative([]byte) error {
 	return consumer.Consume()
 }
 
+func (n *DerivativeNode) snapshot() ([]byte, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	s := derivativeSnapshot{
+		Previous: make(map[models.GroupID]pointSnapshot, len(n.groups)+len(n.restored)),
+	}
+	for id, p := range n.restored {
+		s.Previous[id] = p
+	}
+	for id, g := range n.groups {
+		if g.previous != nil {
+			s.Previous[id] = newBatchPointSnapshot(g.previous)
+		}
+	}
+	return encodeSnapshot(s)
+}
+
+func (n *DerivativeNode) restore(data []byte) error {
+	var s derivativeSnapshot
+	if err := decodeSnapshot(data, &s); err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for id, p := range s.Previous {
+		n.restored[id] = p
+	}
+	return nil
+}
+
 func (n *DerivativeNode) NewGroup(group edge.GroupInfo, first edge.PointMeta) (edge.Receiver, error) {
+	g := n.newGroup()
+	g.id = group.ID
+	n.mu.Lock()
+	if p, ok := n.restored[group.ID]; ok {
+		g.previous = p.batchPoint()
+		delete(n.restored, group.ID)
+	}
+	n.groups[group.ID] = g
+	n.mu.Unlock()
 	return edge.NewReceiverFromForwardReceiverWithStats(
 		n.outs,
-		edge.NewTimedForwardReceiver(n.timer, n.newGroup()),
+		edge.NewTimedForwardReceiver(n.timer, edge.NewLockedForwardReceiver(&n.mu, g)),
 	), nil
 }
 
@@ -49,6 +109,7 @@ func (n *DerivativeNode) newGroup() *derivativeGroup {
 
 type derivativeGroup struct {
 	n        *DerivativeNode
+	id       models.GroupID
 	previous edge.FieldsTagsTimeGetter
 }
 
@@ -115,6 +176,7 @@ func (g *derivativeGroup) Barrier(b edge.BarrierMessage) (edge.Message, error) {
 	return b, nil
 }
 func (g *derivativeGroup) DeleteGroup(d edge.DeleteGroupMessage) (edge.Message, error) {
+	delete(g.n.groups, g.id)
 	return d, nil
 }
 
@@ -62,6 +62,10 @@ func (ec *consumer) Consume() error {
 			if err := ec.r.Barrier(m); err != nil {
 				return err
 			}
+		case DeleteGroupMessage:
+			if err := ec.r.DeleteGroup(m); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("unexpected message of type %T", msg)
 		}
@@ -92,6 +96,7 @@ type MultiReceiver interface {
 	BufferedBatch(src int, batch BufferedBatchMessage) error
 	Point(src int, p PointMessage) error
 	Barrier(src int, b BarrierMessage) error
+	DeleteGroup(src int, d DeleteGroupMessage) error
 	Finish() error
 }
 
@@ -167,6 +172,10 @@ LOOP:
 				if err := c.r.Barrier(m.Src, msg); err != nil {
 					return err
 				}
+			case DeleteGroupMessage:
+				if err := c.r.DeleteGroup(m.Src, msg); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -108,11 +108,10 @@ func (c *groupedConsumer) Point(p PointMessage) error {
 }
 
 func (c *groupedConsumer) Barrier(b BarrierMessage) error {
-	// Barriers messages apply to all gorups
-	for _, r := range c.groups {
-		if err := r.Barrier(b); err != nil {
-			return err
-		}
+	// Barrier messages only apply to their own group
+	r, ok := c.groups[b.GroupID()]
+	if ok {
+		return r.Barrier(b)
 	}
 	return nil
 }
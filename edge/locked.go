@@ -0,0 +1,73 @@
+package edge
+
+import "sync"
+
+type lockedForwardReceiver struct {
+	l sync.Locker
+	r ForwardReceiver
+}
+type lockedForwardBufferedReceiver struct {
+	lockedForwardReceiver
+	b ForwardBufferedReceiver
+}
+
+// NewLockedForwardReceiver creates a forward receiver which holds the lock l while calling r.
+// This allows the state of r to be safely read from other goroutines, i.e. to snapshot it.
+func NewLockedForwardReceiver(l sync.Locker, r ForwardReceiver) ForwardReceiver {
+	b, ok := r.(ForwardBufferedReceiver)
+	if ok {
+		return &lockedForwardBufferedReceiver{
+			lockedForwardReceiver: lockedForwardReceiver{
+				l: l,
+				r: r,
+			},
+			b: b,
+		}
+	}
+	return &lockedForwardReceiver{
+		l: l,
+		r: r,
+	}
+}
+
+func (lr *lockedForwardReceiver) BeginBatch(begin BeginBatchMessage) (Message, error) {
+	lr.l.Lock()
+	defer lr.l.Unlock()
+	return lr.r.BeginBatch(begin)
+}
+
+func (lr *lockedForwardReceiver) BatchPoint(bp BatchPointMessage) (Message, error) {
+	lr.l.Lock()
+	defer lr.l.Unlock()
+	return lr.r.BatchPoint(bp)
+}
+
+func (lr *lockedForwardReceiver) EndBatch(end EndBatchMessage) (Message, error) {
+	lr.l.Lock()
+	defer lr.l.Unlock()
+	return lr.r.EndBatch(end)
+}
+
+func (lr *lockedForwardBufferedReceiver) BufferedBatch(batch BufferedBatchMessage) (Message, error) {
+	lr.l.Lock()
+	defer lr.l.Unlock()
+	return lr.b.BufferedBatch(batch)
+}
+
+func (lr *lockedForwardReceiver) Point(p PointMessage) (Message, error) {
+	lr.l.Lock()
+	defer lr.l.Unlock()
+	return lr.r.Point(p)
+}
+
+func (lr *lockedForwardReceiver) Barrier(b BarrierMessage) (Message, error) {
+	lr.l.Lock()
+	defer lr.l.Unlock()
+	return lr.r.Barrier(b)
+}
+
+func (lr *lockedForwardReceiver) DeleteGroup(d DeleteGroupMessage) (Message, error) {
+	lr.l.Lock()
+	defer lr.l.Unlock()
+	return lr.r.DeleteGroup(d)
+}
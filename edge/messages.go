@@ -132,6 +132,8 @@ func (m MessageType) String() string {
 		return "point"
 	case Barrier:
 		return "barrier"
+	case DeleteGroup:
+		return "delete_group"
 	default:
 		return fmt.Sprintf("unknown message type %d", int(m))
 	}
@@ -874,19 +876,34 @@ func (l BatchPointMessages) Len() int               { return len(l) }
 func (l BatchPointMessages) Less(i int, j int) bool { return l[i].Time().Before(l[j].Time()) }
 func (l BatchPointMessages) Swap(i int, j int)      { l[i], l[j] = l[j], l[i] }
 
-// BarrierMessage indicates that no data older than the barrier time will arrive.
+// BarrierMessage indicates that no data older than the barrier time will arrive for the group.
 type BarrierMessage interface {
 	Message
 	ShallowCopy() BarrierMessage
+	GroupInfoer
 	TimeSetter
+	// Idle reports whether the group received no data for the entire interval of the barrier.
+	Idle() bool
 }
 type barrierMessage struct {
-	time time.Time
+	group GroupInfo
+	time  time.Time
+	idle  bool
+}
+
+func NewBarrierMessage(group GroupInfo, time time.Time) BarrierMessage {
+	return &barrierMessage{
+		group: group,
+		time:  time,
+	}
 }
 
-func NewBarrierMessage(time time.Time) BarrierMessage {
+// NewIdleBarrierMessage creates a barrier for a group that received no data for the interval of the barrier.
+func NewIdleBarrierMessage(group GroupInfo, time time.Time) BarrierMessage {
 	return &barrierMessage{
-		time: time,
+		group: group,
+		time:  time,
+		idle:  true,
 	}
 }
 
@@ -899,12 +916,21 @@ func (b *barrierMessage) ShallowCopy() BarrierMessage {
 func (*barrierMessage) Type() MessageType {
 	return Barrier
 }
+func (b *barrierMessage) GroupID() models.GroupID {
+	return b.group.ID
+}
+func (b *barrierMessage) GroupInfo() GroupInfo {
+	return b.group
+}
 func (b *barrierMessage) Time() time.Time {
 	return b.time
 }
 func (b *barrierMessage) SetTime(time time.Time) {
 	b.time = time
 }
+func (b *barrierMessage) Idle() bool {
+	return b.idle
+}
 
 type DeleteGroupMessage interface {
 	Message
@@ -915,6 +941,12 @@ type deleteGroupMessage struct {
 	groupID models.GroupID
 }
 
+func NewDeleteGroupMessage(id models.GroupID) DeleteGroupMessage {
+	return &deleteGroupMessage{
+		groupID: id,
+	}
+}
+
 func (d *deleteGroupMessage) Type() MessageType {
 	return DeleteGroup
 }
@@ -128,6 +128,13 @@ func (e *statsEdge) incEmitted(group models.GroupID, infoF func() GroupInfo, cou
 	e.mu.Unlock()
 }
 
+// Remove the stats of a deleted group.
+func (e *statsEdge) deleteGroup(group models.GroupID) {
+	e.mu.Lock()
+	delete(e.groupStats, group)
+	e.mu.Unlock()
+}
+
 type batchStatsEdge struct {
 	statsEdge
 
@@ -187,6 +194,8 @@ func (e *batchStatsEdge) Emit() (m Message, ok bool) {
 			e.emitted.Add(1)
 			begin := b.Begin()
 			e.incEmitted(begin.GroupID(), begin.GroupInfo, int64(len(b.Points())))
+		case DeleteGroupMessage:
+			e.deleteGroup(b.GroupID())
 		default:
 			// Do not count other messages
 			// TODO(nathanielc): How should we count other messages?
@@ -217,10 +226,15 @@ func (e *streamStatsEdge) Collect(m Message) error {
 
 func (e *streamStatsEdge) Emit() (m Message, ok bool) {
 	m, ok = e.edge.Emit()
-	if ok && m.Type() == Point {
-		e.emitted.Add(1)
-		p := m.(GroupInfoer)
-		e.incEmitted(p.GroupID(), p.GroupInfo, 1)
+	if ok {
+		switch m.Type() {
+		case Point:
+			e.emitted.Add(1)
+			p := m.(GroupInfoer)
+			e.incEmitted(p.GroupID(), p.GroupInfo, 1)
+		case DeleteGroup:
+			e.deleteGroup(m.(DeleteGroupMessage).GroupID())
+		}
 	}
 	return
 }
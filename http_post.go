@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"sync"
+	"text/template"
 
 	"github.com/influxdata/kapacitor/bufpool"
 	"github.com/influxdata/kapacitor/edge"
@@ -20,6 +21,7 @@ type HTTPPostNode struct {
 	endpoint *httppost.Endpoint
 	mu       sync.RWMutex
 	bp       *bufpool.Pool
+	template *template.Template
 }
 
 // Create a new  HTTPPostNode which submits received items via POST to an HTTP endpoint
@@ -47,6 +49,12 @@ func newHTTPPostNode(et *ExecutingTask, n *pipeline.HTTPPostNode, l *log.Logger)
 		hn.endpoint = e
 	}
 
+	tmpl, err := httppost.ParseTemplate("row-template", n.RowTemplate)
+	if err != nil {
+		return nil, err
+	}
+	hn.template = tmpl
+
 	hn.node.runF = hn.runPost
 	return hn, nil
 }
@@ -110,16 +118,28 @@ func (g *httpPostGroup) DeleteGroup(d edge.DeleteGroupMessage) (edge.Message, er
 }
 
 func (n *HTTPPostNode) postRow(row *models.Row) {
-	result := new(models.Result)
-	result.Series = []*models.Row{row}
-
 	body := n.bp.Get()
 	defer n.bp.Put(body)
-	err := json.NewEncoder(body).Encode(result)
-	if err != nil {
-		n.incrementErrorCount()
-		n.logger.Printf("E! failed to marshal row data json: %v", err)
-		return
+
+	tmpl := n.template
+	if tmpl == nil {
+		tmpl = n.endpoint.RowTemplate()
+	}
+	if tmpl != nil {
+		if err := tmpl.Execute(body, row); err != nil {
+			n.incrementErrorCount()
+			n.logger.Printf("E! failed to execute row template: %v", err)
+			return
+		}
+	} else {
+		result := new(models.Result)
+		result.Series = []*models.Row{row}
+
+		if err := json.NewEncoder(body).Encode(result); err != nil {
+			n.incrementErrorCount()
+			n.logger.Printf("E! failed to marshal row data json: %v", err)
+			return
+		}
 	}
 	req, err := n.endpoint.NewHTTPRequest(body)
 	if err != nil {
@@ -2,6 +2,7 @@ package integrations
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -13,6 +14,8 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
 	"sync/atomic"
 	"testing"
 	"text/template"
@@ -31,11 +34,17 @@ import (
 	"github.com/influxdata/kapacitor/services/alert/alerttest"
 	"github.com/influxdata/kapacitor/services/alerta"
 	"github.com/influxdata/kapacitor/services/alerta/alertatest"
+	"github.com/influxdata/kapacitor/services/alertmanager"
+	"github.com/influxdata/kapacitor/services/alertmanager/alertmanagertest"
 	"github.com/influxdata/kapacitor/services/hipchat"
 	"github.com/influxdata/kapacitor/services/hipchat/hipchattest"
 	"github.com/influxdata/kapacitor/services/httppost"
 	"github.com/influxdata/kapacitor/services/httppost/httpposttest"
+	"github.com/influxdata/kapacitor/services/jira"
+	"github.com/influxdata/kapacitor/services/jira/jiratest"
 	k8s "github.com/influxdata/kapacitor/services/k8s/client"
+	"github.com/influxdata/kapacitor/services/kafka"
+	"github.com/influxdata/kapacitor/services/kafka/kafkatest"
 	"github.com/influxdata/kapacitor/services/logging/loggingtest"
 	"github.com/influxdata/kapacitor/services/opsgenie"
 	"github.com/influxdata/kapacitor/services/opsgenie/opsgenietest"
@@ -45,6 +54,7 @@ import (
 	"github.com/influxdata/kapacitor/services/pushover/pushovertest"
 	"github.com/influxdata/kapacitor/services/sensu"
 	"github.com/influxdata/kapacitor/services/sensu/sensutest"
+	"github.com/influxdata/kapacitor/services/sideload"
 	"github.com/influxdata/kapacitor/services/slack"
 	"github.com/influxdata/kapacitor/services/slack/slacktest"
 	"github.com/influxdata/kapacitor/services/smtp"
@@ -52,8 +62,12 @@ import (
 	"github.com/influxdata/kapacitor/services/snmptrap"
 	"github.com/influxdata/kapacitor/services/snmptrap/snmptraptest"
 	"github.com/influxdata/kapacitor/services/storage/storagetest"
+	"github.com/influxdata/kapacitor/services/syslog"
+	"github.com/influxdata/kapacitor/services/syslog/syslogtest"
 	"github.com/influxdata/kapacitor/services/talk"
 	"github.com/influxdata/kapacitor/services/talk/talktest"
+	"github.com/influxdata/kapacitor/services/teams"
+	"github.com/influxdata/kapacitor/services/teams/teamstest"
 	"github.com/influxdata/kapacitor/services/telegram"
 	"github.com/influxdata/kapacitor/services/telegram/telegramtest"
 	"github.com/influxdata/kapacitor/services/victorops"
@@ -2149,6 +2163,106 @@ stream
 	testStreamerWithOutput(t, "TestStream_DefaultEmptyTags", script, 15*time.Second, er, false, nil)
 }
 
+func TestStream_Sideload(t *testing.T) {
+	dir, err := filepath.Abs("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var script = `
+stream
+	|from()
+		.measurement('cpu')
+		.groupBy('host')
+	|sideload()
+		.source('file://sideload')
+		.order('host/{{.host}}.yml', 'service/{{.service}}.json', 'default.yml')
+		.field('threshold', 0.0)
+		.field('limits.mem', 0)
+		.tag('owner', 'unknown')
+	|httpOut('TestStream_Sideload')
+`
+	er := models.Result{
+		Series: models.Rows{
+			{
+				Name:    "cpu",
+				Tags:    map[string]string{"host": "serverA", "service": "web", "type": "idle", "owner": "web-team"},
+				Columns: []string{"time", "limits.mem", "threshold", "value"},
+				Values: [][]interface{}{[]interface{}{
+					time.Date(1971, 1, 1, 0, 0, 1, 0, time.UTC),
+					80.0,
+					70.0,
+					6.0,
+				}},
+			},
+			{
+				Name:    "cpu",
+				Tags:    map[string]string{"host": "serverB", "service": "web", "type": "idle", "owner": "web-team"},
+				Columns: []string{"time", "limits.mem", "threshold", "value"},
+				Values: [][]interface{}{[]interface{}{
+					time.Date(1971, 1, 1, 0, 0, 1, 0, time.UTC),
+					50.0,
+					80.5,
+					5.0,
+				}},
+			},
+			{
+				Name:    "cpu",
+				Tags:    map[string]string{"host": "serverC", "type": "idle", "owner": "ops"},
+				Columns: []string{"time", "limits.mem", "threshold", "value"},
+				Values: [][]interface{}{[]interface{}{
+					time.Date(1971, 1, 1, 0, 0, 1, 0, time.UTC),
+					80.0,
+					90.0,
+					4.0,
+				}},
+			},
+		},
+	}
+
+	tmInit := func(tm *kapacitor.TaskMaster) {
+		tm.SideloadService = sideload.NewService(sideload.Config{Dir: dir}, logService.NewLogger("[sideload] ", log.LstdFlags))
+	}
+
+	testStreamerWithOutput(t, "TestStream_Sideload", script, 5*time.Second, er, true, tmInit)
+}
+
+func TestStream_ChangeDetect(t *testing.T) {
+	var script = `
+stream
+	|from()
+		.measurement('service')
+		.groupBy('host')
+	|changeDetect('status')
+	|httpOut('TestStream_ChangeDetect')
+`
+	er := models.Result{
+		Series: models.Rows{
+			{
+				Name:    "service",
+				Tags:    map[string]string{"host": "serverA"},
+				Columns: []string{"time", "previous_status", "status"},
+				Values: [][]interface{}{[]interface{}{
+					time.Date(1971, 1, 1, 0, 0, 2, 0, time.UTC),
+					"up",
+					"down",
+				}},
+			},
+			{
+				Name:    "service",
+				Tags:    map[string]string{"host": "serverB"},
+				Columns: []string{"time", "previous_status", "status"},
+				Values: [][]interface{}{[]interface{}{
+					time.Date(1971, 1, 1, 0, 0, 3, 0, time.UTC),
+					"down",
+					"up",
+				}},
+			},
+		},
+	}
+
+	testStreamerWithOutput(t, "TestStream_ChangeDetect", script, 10*time.Second, er, true, nil)
+}
+
 func TestStream_Delete(t *testing.T) {
 	var script = `
 stream
@@ -2410,6 +2524,58 @@ stream
 	}
 }
 
+func TestStream_HttpPostSignedTemplate(t *testing.T) {
+	ts := httpposttest.NewServer()
+	defer ts.Close()
+
+	var script = `
+stream
+	|from()
+		.measurement('cpu')
+		.where(lambda: "host" == 'serverA')
+		.groupBy('host')
+	|httpPost()
+		.endpoint('test')
+`
+
+	tmInit := func(tm *kapacitor.TaskMaster) {
+		c := httppost.Config{}
+		c.URL = ts.URL
+		c.Endpoint = "test"
+		c.SigningSecret = "secret"
+		c.RowTemplate = `{{ .Name }},host={{ index .Tags "host" }} value={{ index (index .Values 0) 1 }}`
+		sl, err := httppost.NewService(httppost.Configs{c}, logService.NewLogger("[test_httppost_endpoint] ", log.LstdFlags))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tm.HTTPPostService = sl
+	}
+
+	testStreamerNoOutput(t, "TestStream_HttpPost", script, 13*time.Second, tmInit)
+
+	ts.Close()
+	var got []string
+	for _, r := range ts.Requests() {
+		timestamp := r.Header.Get(httppost.DefaultTimestampHeader)
+		if exp, got := httppost.Sign("secret", timestamp, []byte(r.Body)), r.Header.Get(httppost.DefaultSignatureHeader); got != exp {
+			t.Errorf("unexpected signature: got %q exp %q", got, exp)
+		}
+		got = append(got, r.Body)
+	}
+
+	exp := []string{
+		"cpu,host=serverA value=97.1",
+		"cpu,host=serverA value=92.6",
+		"cpu,host=serverA value=95.6",
+		"cpu,host=serverA value=93.1",
+		"cpu,host=serverA value=92.6",
+		"cpu,host=serverA value=95.8",
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected request bodies:\ngot\n%v\nexp\n%v", got, exp)
+	}
+}
+
 func TestStream_HttpPostEndpoint(t *testing.T) {
 	headers := map[string]string{"my": "header"}
 	requestCount := int32(0)
@@ -2552,7 +2718,10 @@ stream
 		c := httppost.Config{}
 		c.URL = ts.URL
 		c.Endpoint = "test"
-		sl := httppost.NewService(httppost.Configs{c}, logService.NewLogger("[test_httppost_endpoint] ", log.LstdFlags))
+		sl, err := httppost.NewService(httppost.Configs{c}, logService.NewLogger("[test_httppost_endpoint] ", log.LstdFlags))
+		if err != nil {
+			t.Fatal(err)
+		}
 		tm.HTTPPostService = sl
 	}
 
@@ -2563,6 +2732,70 @@ stream
 	}
 }
 
+func TestStream_KafkaOut(t *testing.T) {
+	ts, err := kafkatest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+
+	var script = `
+stream
+	|from()
+		.measurement('cpu')
+		.where(lambda: "host" == 'serverA')
+		.groupBy('host')
+	|kafkaOut('testTopic')
+		.cluster('default')
+		.format('line')
+	|httpOut('TestStream_HttpPost')
+`
+
+	er := models.Result{
+		Series: models.Rows{
+			{
+				Name:    "cpu",
+				Tags:    map[string]string{"host": "serverA", "type": "idle"},
+				Columns: []string{"time", "value"},
+				Values: [][]interface{}{[]interface{}{
+					time.Date(1971, 1, 1, 0, 0, 5, 0, time.UTC),
+					95.8,
+				}},
+			},
+		},
+	}
+
+	tmInit := func(tm *kapacitor.TaskMaster) {
+		c := kafka.NewConfig()
+		c.Enabled = true
+		c.ID = "default"
+		c.Brokers = []string{ts.Addr}
+		sl, err := kafka.NewService(kafka.Configs{c}, logService.NewLogger("[test_kafka] ", log.LstdFlags))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tm.KafkaService = sl
+	}
+	testStreamerWithOutput(t, "TestStream_HttpPost", script, 13*time.Second, er, false, tmInit)
+
+	ts.Close()
+	msgs, err := ts.Messages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []kafkatest.Message{
+		{Topic: "testTopic", Partition: 0, Offset: 0, Key: "host=serverA", Value: "cpu,host=serverA,type=idle value=97.1 31536000000000000"},
+		{Topic: "testTopic", Partition: 0, Offset: 1, Key: "host=serverA", Value: "cpu,host=serverA,type=idle value=92.6 31536001000000000"},
+		{Topic: "testTopic", Partition: 0, Offset: 2, Key: "host=serverA", Value: "cpu,host=serverA,type=idle value=95.6 31536002000000000"},
+		{Topic: "testTopic", Partition: 0, Offset: 3, Key: "host=serverA", Value: "cpu,host=serverA,type=idle value=93.1 31536003000000000"},
+		{Topic: "testTopic", Partition: 0, Offset: 4, Key: "host=serverA", Value: "cpu,host=serverA,type=idle value=92.6 31536004000000000"},
+		{Topic: "testTopic", Partition: 0, Offset: 5, Key: "host=serverA", Value: "cpu,host=serverA,type=idle value=95.8 31536005000000000"},
+	}
+	if !reflect.DeepEqual(msgs, exp) {
+		t.Errorf("unexpected messages:\ngot %v\nexp %v", msgs, exp)
+	}
+}
+
 func TestStream_HttpOutPassThrough(t *testing.T) {
 
 	var script = `
@@ -7999,7 +8232,10 @@ stream
 		c.URL = ts.URL
 		c.Endpoint = "test"
 		c.Headers = headers
-		sl := httppost.NewService(httppost.Configs{c}, logService.NewLogger("[test_pushover] ", log.LstdFlags))
+		sl, err := httppost.NewService(httppost.Configs{c}, logService.NewLogger("[test_pushover] ", log.LstdFlags))
+		if err != nil {
+			t.Fatal(err)
+		}
 		tm.HTTPPostService = sl
 	}
 	testStreamerNoOutput(t, "TestStream_Alert", script, 13*time.Second, tmInit)
@@ -8040,6 +8276,68 @@ stream
 	}
 }
 
+func TestStream_AlertHTTPPostSignedTemplate(t *testing.T) {
+	ts := httpposttest.NewServer()
+	defer ts.Close()
+
+	var script = `
+stream
+	|from()
+		.measurement('cpu')
+		.where(lambda: "host" == 'serverA')
+		.groupBy('host')
+	|window()
+		.period(10s)
+		.every(10s)
+	|count('value')
+	|alert()
+		.id('kapacitor.{{ .Name }}.{{ index .Tags "host" }}')
+		.info(lambda: "count" > 6.0)
+		.warn(lambda: "count" > 7.0)
+		.crit(lambda: "count" > 8.0)
+		.post()
+			.endpoint('test')
+		.post()
+			.endpoint('test')
+			.alertTemplate('{"id":{{ json .ID }}}')
+`
+	tmInit := func(tm *kapacitor.TaskMaster) {
+		c := httppost.Config{}
+		c.URL = ts.URL
+		c.Endpoint = "test"
+		c.SigningSecret = "secret"
+		c.SignatureHeader = "X-Signature"
+		c.AlertTemplate = `{"text":{{ json .Message }},"level":"{{ .Level }}","host":{{ json (index .Tags "host") }}}`
+		sl, err := httppost.NewService(httppost.Configs{c}, logService.NewLogger("[test_httppost] ", log.LstdFlags))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tm.HTTPPostService = sl
+	}
+	testStreamerNoOutput(t, "TestStream_Alert", script, 13*time.Second, tmInit)
+
+	ts.Close()
+	var got []interface{}
+	for _, r := range ts.Requests() {
+		timestamp := r.Header.Get(httppost.DefaultTimestampHeader)
+		if timestamp == "" {
+			t.Error("missing timestamp header")
+		}
+		if exp, got := httppost.Sign("secret", timestamp, []byte(r.Body)), r.Header.Get("X-Signature"); got != exp {
+			t.Errorf("unexpected signature: got %q exp %q", got, exp)
+		}
+		got = append(got, r.Body)
+	}
+
+	exp := []interface{}{
+		`{"text":"kapacitor.cpu.serverA is CRITICAL","level":"CRITICAL","host":"serverA"}`,
+		`{"id":"kapacitor.cpu.serverA"}`,
+	}
+	if err := compareListIgnoreOrder(got, exp, nil); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestStream_AlertVictorOps(t *testing.T) {
 	ts := victoropstest.NewServer()
 	defer ts.Close()
@@ -8166,6 +8464,435 @@ stream
 	}
 }
 
+func TestStream_AlertAlertmanager(t *testing.T) {
+	ts := alertmanagertest.NewServer()
+	defer ts.Close()
+
+	var script = `
+var warnThreshold = 7.0
+var critThreshold = 8.0
+
+stream
+	|from()
+		.measurement('cpu')
+		.where(lambda: "host" == 'serverA')
+		.groupBy('host')
+	|alert()
+		.id('kapacitor/{{ .Name }}/{{ index .Tags "host" }}')
+		.details('details')
+		.warn(lambda: "value" > warnThreshold)
+		.crit(lambda: "value" > critThreshold)
+		.stateChangesOnly()
+		.alertmanager()
+			.label('team', 'ops')
+`
+
+	tmInit := func(tm *kapacitor.TaskMaster) {
+		c := alertmanager.NewConfig()
+		c.Enabled = true
+		c.URL = ts.URL
+		sl := alertmanager.NewService(c, logService.NewLogger("[test_alertmanager] ", log.LstdFlags))
+		tm.AlertmanagerService = sl
+	}
+	now := time.Now()
+	testStreamerNoOutput(t, "TestStream_AlertDuration", script, 13*time.Second, tmInit)
+
+	labels := map[string]string{
+		"alertname": "kapacitor/cpu/serverA",
+		"host":      "serverA",
+		"type":      "idle",
+		"team":      "ops",
+	}
+	// Recoveries resolve the alert started by the first event after the previous recovery.
+	// Active alerts end in the future, unless they are posted again.
+	exp := []alertmanagertest.Request{
+		{
+			URL: "/api/v1/alerts",
+			Alerts: []alertmanagertest.Alert{{
+				Labels:      labels,
+				Annotations: map[string]string{"message": "kapacitor/cpu/serverA is CRITICAL", "details": "details"},
+				StartsAt:    time.Date(1971, 1, 1, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		{
+			URL: "/api/v1/alerts",
+			Alerts: []alertmanagertest.Alert{{
+				Labels:      labels,
+				Annotations: map[string]string{"message": "kapacitor/cpu/serverA is WARNING", "details": "details"},
+				StartsAt:    time.Date(1971, 1, 1, 0, 0, 0, 0, time.UTC),
+			}},
+		},
+		{
+			URL: "/api/v1/alerts",
+			Alerts: []alertmanagertest.Alert{{
+				Labels:      labels,
+				Annotations: map[string]string{"message": "kapacitor/cpu/serverA is OK", "details": "details"},
+				StartsAt:    time.Date(1971, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndsAt:      time.Date(1971, 1, 1, 0, 0, 4, 0, time.UTC),
+			}},
+		},
+		{
+			URL: "/api/v1/alerts",
+			Alerts: []alertmanagertest.Alert{{
+				Labels:      labels,
+				Annotations: map[string]string{"message": "kapacitor/cpu/serverA is WARNING", "details": "details"},
+				StartsAt:    time.Date(1971, 1, 1, 0, 0, 5, 0, time.UTC),
+			}},
+		},
+		{
+			URL: "/api/v1/alerts",
+			Alerts: []alertmanagertest.Alert{{
+				Labels:      labels,
+				Annotations: map[string]string{"message": "kapacitor/cpu/serverA is OK", "details": "details"},
+				StartsAt:    time.Date(1971, 1, 1, 0, 0, 5, 0, time.UTC),
+				EndsAt:      time.Date(1971, 1, 1, 0, 0, 8, 0, time.UTC),
+			}},
+		},
+	}
+
+	ts.Close()
+	if got := ts.RequestsEndingAfter(now); !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected alertmanager requests:\ngot\n%+v\nexp\n%+v", got, exp)
+	}
+}
+
+func TestStream_AlertJira(t *testing.T) {
+	ts := jiratest.NewServer()
+	defer ts.Close()
+
+	var script = `
+var warnThreshold = 7.0
+var critThreshold = 8.0
+
+stream
+	|from()
+		.measurement('cpu')
+		.where(lambda: "host" == 'serverA')
+		.groupBy('host')
+	|alert()
+		.id('kapacitor/{{ .Name }}/{{ index .Tags "host" }}')
+		.warn(lambda: "value" > warnThreshold)
+		.crit(lambda: "value" > critThreshold)
+		.stateChangesOnly()
+		.jira()
+			.issueType('Bug')
+			.labels('kapacitor')
+			.description('{{ .Message }} since {{ .Time }}')
+			.recoveryComment('{{ .Message }}')
+`
+
+	tmInit := func(tm *kapacitor.TaskMaster) {
+		c := jira.NewConfig()
+		c.Enabled = true
+		c.URL = ts.URL
+		c.Project = "OPS"
+		c.RecoveryTransition = "31"
+		tm.JiraService = jira.NewService(c, logService.NewLogger("[test_jira] ", log.LstdFlags))
+	}
+	testStreamerNoOutput(t, "TestStream_AlertDuration", script, 13*time.Second, tmInit)
+
+	// One issue is opened for each time the alert is active,
+	// and commented and transitioned when the alert recovers.
+	exp := []jiratest.Request{
+		{
+			Path: "/rest/api/2/issue",
+			Issue: &jiratest.Issue{
+				Project:     "OPS",
+				IssueType:   "Bug",
+				Summary:     "kapacitor/cpu/serverA is CRITICAL",
+				Description: "kapacitor/cpu/serverA is CRITICAL since 1971-01-01 00:00:00 +0000 UTC",
+				Labels:      []string{"kapacitor"},
+			},
+		},
+		{
+			Path:    "/rest/api/2/issue/OPS-1/comment",
+			Comment: "kapacitor/cpu/serverA is OK",
+		},
+		{
+			Path:       "/rest/api/2/issue/OPS-1/transitions",
+			Transition: "31",
+		},
+		{
+			Path: "/rest/api/2/issue",
+			Issue: &jiratest.Issue{
+				Project:     "OPS",
+				IssueType:   "Bug",
+				Summary:     "kapacitor/cpu/serverA is WARNING",
+				Description: "kapacitor/cpu/serverA is WARNING since 1971-01-01 00:00:05 +0000 UTC",
+				Labels:      []string{"kapacitor"},
+			},
+		},
+		{
+			Path:    "/rest/api/2/issue/OPS-2/comment",
+			Comment: "kapacitor/cpu/serverA is OK",
+		},
+		{
+			Path:       "/rest/api/2/issue/OPS-2/transitions",
+			Transition: "31",
+		},
+	}
+
+	ts.Close()
+	if got := ts.Requests(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected jira requests:\ngot\n%+v\nexp\n%+v", got, exp)
+	}
+}
+
+func TestStream_AlertBarrierDelete(t *testing.T) {
+	var script = `
+stream
+	|from()
+		.measurement('cpu')
+		.where(lambda: "host" == 'serverA')
+		.groupBy('host')
+	|barrier()
+		.idle(100ms)
+		.delete(TRUE)
+	|alert()
+		.id('kapacitor/{{ .Name }}/{{ index .Tags "host" }}')
+		.crit(lambda: "value" > 90.0)
+		.topic('barrier')
+`
+	clock, et, replayErr, tm := testStreamer(t, "TestStream_Alert", script, nil)
+	defer tm.Close()
+
+	// Replay all data but leave the task running so that the group becomes idle.
+	clock.Set(clock.Zero().Add(13 * time.Second))
+	if err := <-replayErr; err != nil {
+		t.Fatal(err)
+	}
+
+	// The alert is recovered once the idle group is deleted.
+	timeout := time.After(5 * time.Second)
+	for {
+		state, ok, err := tm.AlertService.EventState("barrier", "kapacitor/cpu/serverA")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok && state.Level == alert.OK {
+			if exp, got := "kapacitor/cpu/serverA is OK", state.Message; got != exp {
+				t.Errorf("unexpected recovery message: got %q exp %q", got, exp)
+			}
+			break
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("timed out waiting for alert to recover, last state %v", state)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	tm.Drain()
+	et.StopStats()
+}
+
+func TestStream_AlertTeams(t *testing.T) {
+	ts := teamstest.NewServer()
+	defer ts.Close()
+
+	var script = `
+stream
+	|from()
+		.measurement('cpu')
+		.where(lambda: "host" == 'serverA')
+		.groupBy('host')
+	|window()
+		.period(10s)
+		.every(10s)
+	|count('value')
+	|alert()
+		.id('kapacitor/{{ .Name }}/{{ index .Tags "host" }}')
+		.info(lambda: "count" > 6.0)
+		.warn(lambda: "count" > 7.0)
+		.crit(lambda: "count" > 8.0)
+		.teams()
+		.teams()
+			.channelURL('` + ts.URL + `/other')
+`
+
+	tmInit := func(tm *kapacitor.TaskMaster) {
+		c := teams.NewConfig()
+		c.Enabled = true
+		c.ChannelURL = ts.URL + "/default"
+		sl := teams.NewService(c, logService.NewLogger("[test_teams] ", log.LstdFlags))
+		tm.TeamsService = sl
+	}
+	testStreamerNoOutput(t, "TestStream_Alert", script, 13*time.Second, tmInit)
+
+	card := teamstest.Card{
+		Type:       "MessageCard",
+		Context:    "http://schema.org/extensions",
+		ThemeColor: "CC4A31",
+		Summary:    "kapacitor/cpu/serverA is CRITICAL",
+		Title:      "CRITICAL: kapacitor/cpu/serverA",
+		Text:       "kapacitor/cpu/serverA is CRITICAL",
+	}
+	exp := []interface{}{
+		teamstest.Request{
+			URL:  "/default",
+			Card: card,
+		},
+		teamstest.Request{
+			URL:  "/other",
+			Card: card,
+		},
+	}
+
+	ts.Close()
+	var got []interface{}
+	for _, g := range ts.Requests() {
+		got = append(got, g)
+	}
+
+	if err := compareListIgnoreOrder(got, exp, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStream_AlertKafka(t *testing.T) {
+	ts, err := kafkatest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+
+	var script = `
+stream
+	|from()
+		.measurement('cpu')
+		.where(lambda: "host" == 'serverA')
+		.groupBy('host')
+	|window()
+		.period(10s)
+		.every(10s)
+	|count('value')
+	|alert()
+		.id('kapacitor/{{ .Name }}/{{ index .Tags "host" }}')
+		.details('')
+		.info(lambda: "count" > 6.0)
+		.warn(lambda: "count" > 7.0)
+		.crit(lambda: "count" > 8.0)
+		.kafka()
+			.kafkaTopic('testTopic')
+`
+
+	tmInit := func(tm *kapacitor.TaskMaster) {
+		c := kafka.NewConfig()
+		c.Enabled = true
+		c.ID = "default"
+		c.Brokers = []string{ts.Addr}
+		sl, err := kafka.NewService(kafka.Configs{c}, logService.NewLogger("[test_kafka] ", log.LstdFlags))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tm.KafkaService = sl
+	}
+	testStreamerNoOutput(t, "TestStream_Alert", script, 13*time.Second, tmInit)
+
+	exp := []interface{}{
+		kafkatest.Message{
+			Topic:     "testTopic",
+			Partition: 0,
+			Offset:    0,
+			Key:       "kapacitor/cpu/serverA",
+			Value:     `{"id":"kapacitor/cpu/serverA","message":"kapacitor/cpu/serverA is CRITICAL","details":"","time":"1971-01-01T00:00:10Z","duration":0,"level":"CRITICAL","data":{"series":[{"name":"cpu","tags":{"host":"serverA"},"columns":["time","count"],"values":[["1971-01-01T00:00:10Z",10]]}]}}`,
+		},
+	}
+
+	ts.Close()
+	msgs, err := ts.Messages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []interface{}
+	for _, m := range msgs {
+		got = append(got, m)
+	}
+
+	if err := compareListIgnoreOrder(got, exp, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStream_AlertSyslog(t *testing.T) {
+	ts, err := syslogtest.NewServer("udp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+
+	var script = `
+stream
+	|from()
+		.measurement('cpu')
+		.where(lambda: "host" == 'serverA')
+		.groupBy('host')
+	|window()
+		.period(10s)
+		.every(10s)
+	|count('value')
+	|alert()
+		.id('kapacitor/{{ .Name }}/{{ index .Tags "host" }}')
+		.info(lambda: "count" > 6.0)
+		.warn(lambda: "count" > 7.0)
+		.crit(lambda: "count" > 8.0)
+		.syslog()
+		.syslog()
+			.facility('local3')
+			.appName('cpu-alerts')
+			.msgID('cpu')
+`
+
+	tmInit := func(tm *kapacitor.TaskMaster) {
+		c := syslog.NewConfig()
+		c.Enabled = true
+		c.Addr = ts.Addr
+		c.Hostname = "kapacitor.example.com"
+		sl := syslog.NewService(c, logService.NewLogger("[test_syslog] ", log.LstdFlags))
+		tm.SyslogService = sl
+	}
+	testStreamerNoOutput(t, "TestStream_Alert", script, 13*time.Second, tmInit)
+
+	procID := strconv.Itoa(os.Getpid())
+	exp := []interface{}{
+		syslogtest.Message{
+			// user facility, critical severity
+			Priority:       10,
+			Timestamp:      "1971-01-01T00:00:10Z",
+			Hostname:       "kapacitor.example.com",
+			AppName:        "kapacitor",
+			ProcID:         procID,
+			MsgID:          "-",
+			StructuredData: `[kapacitor@32473 host="serverA"]`,
+			Text:           "kapacitor/cpu/serverA is CRITICAL",
+		},
+		syslogtest.Message{
+			// local3 facility, critical severity
+			Priority:       154,
+			Timestamp:      "1971-01-01T00:00:10Z",
+			Hostname:       "kapacitor.example.com",
+			AppName:        "cpu-alerts",
+			ProcID:         procID,
+			MsgID:          "cpu",
+			StructuredData: `[kapacitor@32473 host="serverA"]`,
+			Text:           "kapacitor/cpu/serverA is CRITICAL",
+		},
+	}
+
+	ts.Close()
+	msgs, err := ts.Messages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []interface{}
+	for _, m := range msgs {
+		got = append(got, m)
+	}
+
+	if err := compareListIgnoreOrder(got, exp, nil); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestStream_AlertLog(t *testing.T) {
 	tmpDir, err := ioutil.TempDir("", "TestStream_AlertLog")
 	if err != nil {
@@ -8248,6 +8975,82 @@ stream
 
 }
 
+func TestStream_AlertLogRotate(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "TestStream_AlertLogRotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	logPath := filepath.Join(tmpDir, "alerts.log")
+
+	var script = fmt.Sprintf(`
+var warnThreshold = 7.0
+var critThreshold = 8.0
+
+stream
+	|from()
+		.measurement('cpu')
+		.where(lambda: "host" == 'serverA')
+		.groupBy('host')
+	|alert()
+		.id('kapacitor/{{ .Name }}/{{ index .Tags "host" }}')
+		.warn(lambda: "value" > warnThreshold)
+		.crit(lambda: "value" > critThreshold)
+		.stateChangesOnly()
+		.log('%s')
+			.format('text')
+			.template('{{ .Level }}')
+			.maxSize(10)
+			.maxBackups(2)
+			.compress()
+`, logPath)
+
+	testStreamerNoOutput(t, "TestStream_AlertDuration", script, 13*time.Second, nil)
+
+	// Every event exceeds the size of the file, so each one is logged to a new file.
+	// Only the last two rotated files are kept.
+	backups, err := filepath.Glob(logPath + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(backups)
+	var got []string
+	for _, b := range backups {
+		if filepath.Ext(b) != ".gz" {
+			t.Errorf("rotated file %s is not compressed", b)
+			continue
+		}
+		f, err := os.Open(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(r)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(data))
+	}
+	data, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, string(data))
+
+	exp := []string{
+		"OK\n",
+		"WARNING\n",
+		"OK\n",
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected log files:\ngot\n%q\nexp\n%q", got, exp)
+	}
+}
+
 func TestStream_AlertExec(t *testing.T) {
 	var script = `
 stream
@@ -10769,8 +11572,12 @@ func createTaskMaster() (*kapacitor.TaskMaster, error) {
 	tm.HTTPDService = httpdService
 	tm.TaskStore = taskStore{}
 	tm.DeadmanService = deadman{}
-	tm.HTTPPostService = httppost.NewService(nil, logService.NewLogger("[httppost] ", log.LstdFlags))
-	as := alertservice.NewService(logService.NewLogger("[alert] ", log.LstdFlags))
+	httpPostService, err := httppost.NewService(nil, logService.NewLogger("[httppost] ", log.LstdFlags))
+	if err != nil {
+		return nil, err
+	}
+	tm.HTTPPostService = httpPostService
+	as := alertservice.NewService(alertservice.NewConfig(), logService.NewLogger("[alert] ", log.LstdFlags))
 	as.StorageService = storagetest.New()
 	as.HTTPDService = httpdService
 	if err := as.Open(); err != nil {
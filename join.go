@@ -33,6 +33,40 @@ type JoinNode struct {
 
 	reported    map[int]bool
 	allReported bool
+
+	// mu protects all join state so that it can be snapshotted while the node is running.
+	mu sync.Mutex
+}
+
+// joinSnapshot is the snapshot state of a JoinNode.
+type joinSnapshot struct {
+	Groups          map[models.GroupID]joinGroupSnapshot
+	LowMarks        []joinLowMarkSnapshot
+	MatchBuffers    map[models.GroupID][]joinSrcPointSnapshot
+	SpecificBuffers map[models.GroupID][]joinSrcPointSnapshot
+	Reported        []int
+}
+
+type joinGroupSnapshot struct {
+	Sets       []joinsetSnapshot
+	Head       []time.Time
+	OldestTime time.Time
+}
+
+type joinsetSnapshot struct {
+	Time   time.Time
+	Values []joinSrcPointSnapshot
+}
+
+type joinSrcPointSnapshot struct {
+	Src int
+	Msg messageSnapshot
+}
+
+type joinLowMarkSnapshot struct {
+	Src     int
+	GroupID models.GroupID
+	Time    time.Time
 }
 
 // Create a new JoinNode, which takes pairs from parent streams combines them into a single point.
@@ -67,7 +101,12 @@ func newJoinNode(et *ExecutingTask, n *pipeline.JoinNode, l *log.Logger) (*JoinN
 	return jn, nil
 }
 
-func (n *JoinNode) runJoin([]byte) error {
+func (n *JoinNode) runJoin(snapshot []byte) error {
+	if snapshot != nil {
+		if err := n.restore(snapshot); err != nil {
+			return errors.Wrap(err, "failed to restore join snapshot")
+		}
+	}
 	consumer := edge.NewMultiConsumerWithStats(n.ins, n)
 	valueF := func() int64 {
 		n.groupsMu.RLock()
@@ -88,11 +127,41 @@ func (n *JoinNode) Point(src int, p edge.PointMessage) error {
 	return n.doMessage(src, p)
 }
 
+// Barrier emits any joined sets of the group that can no longer receive data from the parents.
+// Barriers are matched to groups by their group ID, as such barriers
+// on less specific points do not apply when joining on dimensions.
 func (n *JoinNode) Barrier(src int, b edge.BarrierMessage) error {
+	n.timer.Start()
+	defer n.timer.Stop()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if group, ok := n.groups[b.GroupID()]; ok {
+		if err := group.Barrier(src, b.Time().Round(n.j.Tolerance)); err != nil {
+			return err
+		}
+	}
 	return edge.Forward(n.outs, b)
 }
 
+// DeleteGroup removes all state for the group.
+func (n *JoinNode) DeleteGroup(src int, d edge.DeleteGroupMessage) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	id := d.GroupID()
+	n.groupsMu.Lock()
+	delete(n.groups, id)
+	n.groupsMu.Unlock()
+	delete(n.matchGroupsBuffer, id)
+	delete(n.specificGroupsBuffer, id)
+	for s := range n.ins {
+		delete(n.lowMarks, srcGroup{src: s, groupId: id})
+	}
+	return edge.Forward(n.outs, d)
+}
+
 func (n *JoinNode) Finish() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
 	// No more points are coming signal all groups to finish up.
 	for _, group := range n.groups {
 		if err := group.Finish(); err != nil {
@@ -114,6 +183,8 @@ type srcPoint struct {
 func (n *JoinNode) doMessage(src int, m messageMeta) error {
 	n.timer.Start()
 	defer n.timer.Stop()
+	n.mu.Lock()
+	defer n.mu.Unlock()
 	if len(n.j.Dimensions) > 0 {
 		// Match points with their group based on join dimensions.
 		n.matchPoints(srcPoint{Src: src, Msg: m})
@@ -125,6 +196,116 @@ func (n *JoinNode) doMessage(src int, m messageMeta) error {
 	return nil
 }
 
+func (n *JoinNode) snapshot() ([]byte, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	s := joinSnapshot{
+		Groups:          make(map[models.GroupID]joinGroupSnapshot, len(n.groups)),
+		MatchBuffers:    make(map[models.GroupID][]joinSrcPointSnapshot, len(n.matchGroupsBuffer)),
+		SpecificBuffers: make(map[models.GroupID][]joinSrcPointSnapshot, len(n.specificGroupsBuffer)),
+	}
+	for id, g := range n.groups {
+		gs, err := g.snapshot()
+		if err != nil {
+			return nil, err
+		}
+		s.Groups[id] = gs
+	}
+	for sg, t := range n.lowMarks {
+		s.LowMarks = append(s.LowMarks, joinLowMarkSnapshot{
+			Src:     sg.src,
+			GroupID: sg.groupId,
+			Time:    t,
+		})
+	}
+	for id, buf := range n.matchGroupsBuffer {
+		ps, err := snapshotSrcPoints(buf)
+		if err != nil {
+			return nil, err
+		}
+		s.MatchBuffers[id] = ps
+	}
+	for id, buf := range n.specificGroupsBuffer {
+		ps, err := snapshotSrcPoints(buf)
+		if err != nil {
+			return nil, err
+		}
+		s.SpecificBuffers[id] = ps
+	}
+	for src := range n.reported {
+		s.Reported = append(s.Reported, src)
+	}
+	return encodeSnapshot(s)
+}
+
+func (n *JoinNode) restore(data []byte) error {
+	var s joinSnapshot
+	if err := decodeSnapshot(data, &s); err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for id, gs := range s.Groups {
+		if len(gs.Head) != len(n.ins) {
+			return fmt.Errorf("snapshot has %d parents, expected %d", len(gs.Head), len(n.ins))
+		}
+		g := n.getOrCreateGroup(id)
+		if err := g.restore(gs); err != nil {
+			return err
+		}
+	}
+	for _, lm := range s.LowMarks {
+		n.lowMarks[srcGroup{src: lm.Src, groupId: lm.GroupID}] = lm.Time
+	}
+	for id, ps := range s.MatchBuffers {
+		buf, err := restoreSrcPoints(ps)
+		if err != nil {
+			return err
+		}
+		n.matchGroupsBuffer[id] = buf
+	}
+	for id, ps := range s.SpecificBuffers {
+		buf, err := restoreSrcPoints(ps)
+		if err != nil {
+			return err
+		}
+		n.specificGroupsBuffer[id] = buf
+	}
+	for _, src := range s.Reported {
+		n.reported[src] = true
+	}
+	n.allReported = len(n.reported) == len(n.ins)
+	return nil
+}
+
+func snapshotSrcPoints(points []srcPoint) ([]joinSrcPointSnapshot, error) {
+	ps := make([]joinSrcPointSnapshot, len(points))
+	for i, p := range points {
+		m, err := newMessageSnapshot(p.Msg)
+		if err != nil {
+			return nil, err
+		}
+		ps[i] = joinSrcPointSnapshot{Src: p.Src, Msg: m}
+	}
+	return ps, nil
+}
+
+func restoreSrcPoints(ps []joinSrcPointSnapshot) ([]srcPoint, error) {
+	points := make([]srcPoint, len(ps))
+	for i, p := range ps {
+		m, err := p.Msg.message()
+		if err != nil {
+			return nil, err
+		}
+		mm, ok := m.(messageMeta)
+		if !ok {
+			return nil, fmt.Errorf("unexpected message type %T", m)
+		}
+		points[i] = srcPoint{Src: p.Src, Msg: mm}
+	}
+	return points, nil
+}
+
 // The purpose of this method is to match more specific points
 // with the less specific points as they arrive.
 //
@@ -307,6 +488,52 @@ func (g *joinGroup) Finish() error {
 	return g.emitAll()
 }
 
+func (g *joinGroup) snapshot() (joinGroupSnapshot, error) {
+	s := joinGroupSnapshot{
+		Head:       g.head,
+		OldestTime: g.oldestTime,
+	}
+	for _, sets := range g.sets {
+		for _, set := range sets {
+			ss := joinsetSnapshot{
+				Time: set.time,
+			}
+			for src, v := range set.values {
+				if v == nil {
+					continue
+				}
+				m, err := newMessageSnapshot(v)
+				if err != nil {
+					return joinGroupSnapshot{}, err
+				}
+				ss.Values = append(ss.Values, joinSrcPointSnapshot{Src: src, Msg: m})
+			}
+			s.Sets = append(s.Sets, ss)
+		}
+	}
+	return s, nil
+}
+
+func (g *joinGroup) restore(s joinGroupSnapshot) error {
+	copy(g.head, s.Head)
+	g.oldestTime = s.OldestTime
+	for _, ss := range s.Sets {
+		set := g.newJoinset(ss.Time)
+		for _, v := range ss.Values {
+			if v.Src < 0 || v.Src >= set.expected {
+				return fmt.Errorf("invalid join source %d", v.Src)
+			}
+			m, err := v.Msg.message()
+			if err != nil {
+				return err
+			}
+			set.Set(v.Src, m)
+		}
+		g.sets[ss.Time] = append(g.sets[ss.Time], set)
+	}
+	return nil
+}
+
 // Collect a point from a given parent.
 // emit the oldest set if we have collected enough data.
 func (g *joinGroup) Collect(src int, p timeMessage) error {
@@ -352,6 +579,25 @@ func (g *joinGroup) Collect(src int, p timeMessage) error {
 	return nil
 }
 
+// Barrier marks that no data older than t will arrive from the parent src.
+// All sets that can no longer receive data are emitted.
+func (g *joinGroup) Barrier(src int, t time.Time) error {
+	if t.After(g.head[src]) {
+		g.head[src] = t
+	}
+	for len(g.sets) > 0 {
+		for _, h := range g.head {
+			if !h.After(g.oldestTime) {
+				return nil
+			}
+		}
+		if err := g.emit(false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (g *joinGroup) newJoinset(t time.Time) *joinset {
 	return newJoinset(
 		g.n,
@@ -0,0 +1,173 @@
+package kapacitor
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	imodels "github.com/influxdata/influxdb/models"
+	"github.com/influxdata/kapacitor/edge"
+	"github.com/influxdata/kapacitor/expvar"
+	"github.com/influxdata/kapacitor/models"
+	"github.com/influxdata/kapacitor/pipeline"
+	"github.com/influxdata/kapacitor/services/kafka"
+	"github.com/pkg/errors"
+)
+
+const (
+	statsKafkaMessagesWritten = "messages_written"
+	statsKafkaWriteErrors     = "write_errors"
+)
+
+type KafkaOutNode struct {
+	node
+	k *pipeline.KafkaOutNode
+
+	messagesWritten *expvar.Int
+	writeErrors     *expvar.Int
+}
+
+// kafkaPoint is the JSON format of points written to Kafka.
+type kafkaPoint struct {
+	Name   string        `json:"name"`
+	Time   time.Time     `json:"time"`
+	Tags   models.Tags   `json:"tags"`
+	Fields models.Fields `json:"fields"`
+}
+
+// Create a new KafkaOutNode which writes the received points to a Kafka topic.
+func newKafkaOutNode(et *ExecutingTask, n *pipeline.KafkaOutNode, l *log.Logger) (*KafkaOutNode, error) {
+	if et.tm.KafkaService == nil {
+		return nil, errors.New("no Kafka cluster configured cannot use the KafkaOutNode")
+	}
+	kn := &KafkaOutNode{
+		node: node{Node: n, et: et, logger: l},
+		k:    n,
+	}
+	kn.node.runF = kn.runOut
+	return kn, nil
+}
+
+func (n *KafkaOutNode) runOut([]byte) error {
+	n.messagesWritten = &expvar.Int{}
+	n.writeErrors = &expvar.Int{}
+
+	n.statMap.Set(statsKafkaMessagesWritten, n.messagesWritten)
+	n.statMap.Set(statsKafkaWriteErrors, n.writeErrors)
+
+	consumer := edge.NewGroupedConsumer(
+		n.ins[0],
+		n,
+	)
+	n.statMap.Set(statCardinalityGauge, consumer.CardinalityVar())
+	return consumer.Consume()
+}
+
+func (n *KafkaOutNode) NewGroup(group edge.GroupInfo, first edge.PointMeta) (edge.Receiver, error) {
+	g := &kafkaOutGroup{
+		n:      n,
+		buffer: new(edge.BatchBuffer),
+	}
+	if group.ID != "" {
+		g.key = []byte(group.ID)
+	}
+	return edge.NewReceiverFromForwardReceiverWithStats(
+		n.outs,
+		edge.NewTimedForwardReceiver(n.timer, g),
+	), nil
+}
+
+type kafkaOutGroup struct {
+	n      *KafkaOutNode
+	key    []byte
+	buffer *edge.BatchBuffer
+}
+
+func (g *kafkaOutGroup) BeginBatch(begin edge.BeginBatchMessage) (edge.Message, error) {
+	return nil, g.buffer.BeginBatch(begin)
+}
+
+func (g *kafkaOutGroup) BatchPoint(bp edge.BatchPointMessage) (edge.Message, error) {
+	return nil, g.buffer.BatchPoint(bp)
+}
+
+func (g *kafkaOutGroup) EndBatch(end edge.EndBatchMessage) (edge.Message, error) {
+	return g.BufferedBatch(g.buffer.BufferedBatchMessage(end))
+}
+
+func (g *kafkaOutGroup) BufferedBatch(batch edge.BufferedBatchMessage) (edge.Message, error) {
+	msgs := make([]kafka.Message, 0, len(batch.Points()))
+	for _, bp := range batch.Points() {
+		m, err := g.n.message(g.key, batch.Name(), bp.Time(), bp.Tags(), bp.Fields())
+		if err != nil {
+			g.n.incrementErrorCount()
+			g.n.logger.Println("E! failed to encode point:", err)
+			continue
+		}
+		msgs = append(msgs, m)
+	}
+	g.n.write(msgs)
+	return batch, nil
+}
+
+func (g *kafkaOutGroup) Point(p edge.PointMessage) (edge.Message, error) {
+	m, err := g.n.message(g.key, p.Name(), p.Time(), p.Tags(), p.Fields())
+	if err != nil {
+		g.n.incrementErrorCount()
+		g.n.logger.Println("E! failed to encode point:", err)
+		return p, nil
+	}
+	g.n.write([]kafka.Message{m})
+	return p, nil
+}
+
+func (g *kafkaOutGroup) Barrier(b edge.BarrierMessage) (edge.Message, error) {
+	return b, nil
+}
+func (g *kafkaOutGroup) DeleteGroup(d edge.DeleteGroupMessage) (edge.Message, error) {
+	return d, nil
+}
+
+// message encodes a point as a message in the format of the node.
+func (n *KafkaOutNode) message(key []byte, name string, t time.Time, tags models.Tags, fields models.Fields) (kafka.Message, error) {
+	m := kafka.Message{Key: key}
+	switch n.k.Format {
+	case pipeline.KafkaFormatLine:
+		p, err := imodels.NewPoint(name, imodels.NewTags(tags), imodels.Fields(fields), t)
+		if err != nil {
+			return m, err
+		}
+		m.Value = []byte(p.String())
+	default:
+		value, err := json.Marshal(kafkaPoint{
+			Name:   name,
+			Time:   t,
+			Tags:   tags,
+			Fields: fields,
+		})
+		if err != nil {
+			return m, err
+		}
+		m.Value = value
+	}
+	return m, nil
+}
+
+func (n *KafkaOutNode) write(msgs []kafka.Message) {
+	if len(msgs) == 0 {
+		return
+	}
+	written := len(msgs)
+	if err := n.et.tm.KafkaService.WriteMessages(n.k.Cluster, n.k.Topic, msgs...); err != nil {
+		// Only the failed messages were not written, the others were acknowledged.
+		if werr, ok := err.(*kafka.WriteError); ok {
+			written -= len(werr.Failed)
+		} else {
+			written = 0
+		}
+		n.writeErrors.Add(1)
+		n.incrementErrorCount()
+		n.logger.Printf("E! failed to write %d messages to Kafka topic %q: %v", len(msgs)-written, n.k.Topic, err)
+	}
+	n.messagesWritten.Add(int64(written))
+}
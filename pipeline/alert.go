@@ -28,7 +28,7 @@ const defaultDetailsTmpl = "{{ json . }}"
 // See AlertNode.Info, AlertNode.Warn, and AlertNode.Crit below.
 //
 // Different event handlers can be configured for each AlertNode.
-// Some handlers like Email, HipChat, Sensu, Slack, OpsGenie, VictorOps, PagerDuty, Telegram and Talk have a configuration
+// Some handlers like Email, HipChat, Sensu, Slack, OpsGenie, VictorOps, PagerDuty, Telegram, Talk and Teams have a configuration
 // option 'global' that indicates that all alerts implicitly use the handler.
 //
 // Available event handlers:
@@ -39,7 +39,9 @@ const defaultDetailsTmpl = "{{ json . }}"
 //    * email -- Send and email with alert data.
 //    * exec -- Execute a command passing alert data over STDIN.
 //    * HipChat -- Post alert message to HipChat room.
+//    * Jira -- Open an issue in Jira for the alert.
 //    * Alerta -- Post alert message to Alerta.
+//    * Alertmanager -- Post alert to Prometheus Alertmanager.
 //    * Sensu -- Post alert message to Sensu client.
 //    * Slack -- Post alert message to Slack channel.
 //    * SNMPTraps -- Trigger SNMP traps.
@@ -48,8 +50,11 @@ const defaultDetailsTmpl = "{{ json . }}"
 //    * PagerDuty -- Send alert to PagerDuty.
 //    * Pushover -- Send alert to Pushover.
 //    * Talk -- Post alert message to Talk client.
+//    * Teams -- Post alert message to Microsoft Teams channel.
 //    * Telegram -- Post alert message to Telegram client.
 //    * MQTT -- Post alert message to MQTT.
+//    * Kafka -- Write alert data to a Kafka topic.
+//    * Syslog -- Send alert message to a syslog server.
 //
 // See below for more details on configuring each handler.
 //
@@ -344,6 +349,14 @@ type AlertNode struct {
 	// tick:ignore
 	AlertaHandlers []*AlertaHandler `tick:"Alerta"`
 
+	// Send alert to Alertmanager.
+	// tick:ignore
+	AlertmanagerHandlers []*AlertmanagerHandler `tick:"Alertmanager"`
+
+	// Open issues in Jira.
+	// tick:ignore
+	JiraHandlers []*JiraHandler `tick:"Jira"`
+
 	// Send alert to OpsGenie
 	// tick:ignore
 	OpsGenieHandlers []*OpsGenieHandler `tick:"OpsGenie"`
@@ -352,13 +365,25 @@ type AlertNode struct {
 	// tick:ignore
 	TalkHandlers []*TalkHandler `tick:"Talk"`
 
+	// Send alert to Microsoft Teams.
+	// tick:ignore
+	TeamsHandlers []*TeamsHandler `tick:"Teams"`
+
 	// Send alert to MQTT
 	// tick:ignore
 	MQTTHandlers []*MQTTHandler `tick:"Mqtt"`
 
+	// Send alert to Kafka.
+	// tick:ignore
+	KafkaHandlers []*KafkaHandler `tick:"Kafka"`
+
 	// Send alert using SNMPtraps.
 	// tick:ignore
 	SNMPTrapHandlers []*SNMPTrapHandler `tick:"SnmpTrap"`
+
+	// Send alert to syslog.
+	// tick:ignore
+	SyslogHandlers []*SyslogHandler `tick:"Syslog"`
 }
 
 func newAlertNode(wants EdgeType) *AlertNode {
@@ -540,6 +565,18 @@ type AlertHTTPPostHandler struct {
 
 	// tick:ignore
 	Headers map[string]string `tick:"Header"`
+
+	// Template of the body of the request, executed with the same data as the message template.
+	// The json function returns the JSON encoding of a value.
+	// If empty uses the alert template of the endpoint, or posts the alert data as JSON.
+	//
+	// Example:
+	//    stream
+	//         |alert()
+	//             .post()
+	//                 .endpoint('example')
+	//                 .alertTemplate('{"text":{{ json .Message }},"level":"{{ .Level }}"}')
+	AlertTemplate string
 }
 
 func (a *AlertHTTPPostHandler) validate() error {
@@ -633,6 +670,10 @@ type EmailHandler struct {
 	// List of email recipients.
 	// tick:ignore
 	ToList []string `tick:"To"`
+
+	// Send emails for acknowledged events.
+	// tick:ignore
+	IsNotifyAcknowledged bool `tick:"NotifyAcknowledged"`
 }
 
 // Define the To addresses for the email alert.
@@ -663,6 +704,14 @@ func (h *EmailHandler) To(to ...string) *EmailHandler {
 	return h
 }
 
+// Send emails for events that have been acknowledged.
+// By default no emails are sent for acknowledged events until the level of the event changes.
+// tick:property
+func (h *EmailHandler) NotifyAcknowledged() *EmailHandler {
+	h.IsNotifyAcknowledged = true
+	return h
+}
+
 // Execute a command whenever an alert is triggered and pass the alert data over STDIN in JSON format.
 // tick:property
 func (a *AlertNode) Exec(executable string, args ...string) *ExecHandler {
@@ -696,6 +745,25 @@ type ExecHandler struct {
 //         |alert()
 //             .log('/tmp/alert')
 //             .mode(0644)
+//
+// The file can be rotated when it exceeds a size or at an interval,
+// the rotated files have the time of the rotation appended to their name.
+//
+// Example:
+//    stream
+//         |alert()
+//             .log('/var/log/kapacitor/alerts.log')
+//             .maxSize(104857600)
+//             .rotateEvery(24h)
+//             .maxBackups(7)
+//             .compress()
+//
+// Example:
+//    stream
+//         |alert()
+//             .log('/var/log/kapacitor/alerts.log')
+//             .format('text')
+//             .template('{{ .Time }} {{ .Level }} {{ .Message }}')
 // tick:property
 func (a *AlertNode) Log(filepath string) *LogHandler {
 	log := &LogHandler{
@@ -718,6 +786,40 @@ type LogHandler struct {
 	// File's mode and permissions, default is 0600
 	// NOTE: The leading 0 is required to interpret the value as an octal integer.
 	Mode int64
+
+	// Format of the lines, either 'json' for the alert data as JSON or 'text' for templated lines.
+	// Default is 'json'.
+	Format string
+
+	// Template of the lines in the text format, executed with the same data as the message template.
+	// If empty uses a line with the time, level, ID and message of the alert.
+	Template string
+
+	// Size in bytes after which the file is rotated.
+	// If zero the file is not rotated based on its size.
+	MaxSize int64
+
+	// Interval at which the file is rotated.
+	// The file is rotated when the first alert of a new interval is logged.
+	// If zero the file is not rotated based on time.
+	RotateEvery time.Duration
+
+	// Number of rotated files to keep.
+	// If zero all rotated files are kept.
+	MaxBackups int64
+
+	// Compress the rotated files with gzip.
+	// tick:ignore
+	IsCompress bool `tick:"Compress"`
+}
+
+// Compress the rotated files with gzip.
+// Files are compressed in the background after they are rotated,
+// so the newest rotated file may not be compressed yet.
+// tick:property
+func (h *LogHandler) Compress() *LogHandler {
+	h.IsCompress = true
+	return h
 }
 
 // Send alert to VictorOps.
@@ -1006,6 +1108,133 @@ func (a *AlertaHandler) Services(service ...string) *AlertaHandler {
 	return a
 }
 
+// Send the alert to Prometheus Alertmanager.
+// To use Alertmanager place its URL in the 'alertmanager' configuration section.
+//
+// Example:
+//    [alertmanager]
+//      enabled = true
+//      url = "http://alertmanager:9093"
+//
+// The alerts are posted to the v1 alerts API.
+// The tags of the alert become labels, and the alertname label is the ID of the alert.
+// The message and details of the alert become annotations.
+// Recoveries resolve the alert in Alertmanager.
+//
+// Alertmanager resolves alerts which are not repeated within its resolve timeout,
+// so alerts which should stay active must not use stateChangesOnly.
+//
+// Example:
+//    stream
+//         |alert()
+//             .alertmanager()
+//                 .label('team', 'ops')
+//
+// Send alerts to Alertmanager with an additional team label.
+// tick:property
+func (a *AlertNode) Alertmanager() *AlertmanagerHandler {
+	alertmanager := &AlertmanagerHandler{
+		AlertNode: a,
+	}
+	a.AlertmanagerHandlers = append(a.AlertmanagerHandlers, alertmanager)
+	return alertmanager
+}
+
+// tick:embedded:AlertNode.Alertmanager
+type AlertmanagerHandler struct {
+	*AlertNode
+
+	// Labels added to the labels created from the tags of the alert.
+	// tick:ignore
+	Labels map[string]string `tick:"Label"`
+}
+
+// Add a label to the alerts.
+// Labels must be the same for all events of an alert,
+// so that recoveries resolve the alert.
+// tick:property
+func (a *AlertmanagerHandler) Label(k, v string) *AlertmanagerHandler {
+	if a.Labels == nil {
+		a.Labels = map[string]string{}
+	}
+	a.Labels[k] = v
+	return a
+}
+
+// Open an issue for the alert in Jira, or another issue tracker with a Jira compatible REST API.
+// To use Jira place its URL, the credentials and the default project in the 'jira' configuration section.
+//
+// Example:
+//    [jira]
+//      enabled = true
+//      url = "https://example.atlassian.net"
+//      username = "kapacitor@example.com"
+//      token = "xxxxx"
+//      project = "OPS"
+//      recovery-transition = "31"
+//
+// Only one issue is opened while the alert is active, the key of the issue is kept in the state of the alert event.
+// When the alert recovers a comment is added to the issue and the recovery transition is performed, if any.
+//
+// Example:
+//    stream
+//         |alert()
+//             .warn(lambda: "usage_percent" > 80.0)
+//             .jira()
+//                 .project('OPS')
+//                 .labels('kapacitor', 'disk')
+//                 .summary('{{ index .Tags "host" }} disk usage is {{ .Level }}')
+//
+// Open an issue in the OPS project while the disk usage of a host is above 80%.
+// tick:property
+func (a *AlertNode) Jira() *JiraHandler {
+	jira := &JiraHandler{
+		AlertNode: a,
+	}
+	a.JiraHandlers = append(a.JiraHandlers, jira)
+	return jira
+}
+
+// tick:embedded:AlertNode.Jira
+type JiraHandler struct {
+	*AlertNode
+
+	// Project key of the issues.
+	// If empty uses the project from the configuration.
+	Project string
+
+	// Issue type of the issues.
+	// If empty uses the issue type from the configuration.
+	IssueType string
+
+	// Template of the summary of the issues, executed with the same data as the message template.
+	// If empty uses the message of the alert.
+	Summary string
+
+	// Template of the description of the issues, executed with the same data as the message template.
+	// If empty uses the message, ID, level, time and tags of the alert.
+	Description string
+
+	// Labels of the issues.
+	// tick:ignore
+	LabelsList []string `tick:"Labels"`
+
+	// ID of the transition performed on the issues when the alert recovers.
+	// If empty uses the recovery transition from the configuration.
+	RecoveryTransition string
+
+	// Template of the comment added to the issues when the alert recovers.
+	// If empty uses the ID and message of the alert.
+	RecoveryComment string
+}
+
+// Labels of the issues.
+// tick:property
+func (j *JiraHandler) Labels(labels ...string) *JiraHandler {
+	j.LabelsList = labels
+	return j
+}
+
 // Send alert to an MQTT broker
 // tick:property
 func (a *AlertNode) Mqtt(topic string) *MQTTHandler {
@@ -1043,6 +1272,106 @@ type MQTTHandler struct {
 	Retained bool
 }
 
+// Send the alert data as JSON to a Kafka topic.
+// The messages are keyed by the ID of the alert,
+// so that all events of an alert are written to the same partition.
+//
+// Example:
+//    [[kafka]]
+//      enabled = true
+//      id = "infra"
+//      brokers = ["localhost:9092"]
+//
+// Example:
+//    stream
+//         |alert()
+//             .kafka()
+//                 .cluster('infra')
+//                 .kafkaTopic('alerts')
+//
+// Send alerts to the topic 'alerts' of the Kafka cluster 'infra'.
+// tick:property
+func (a *AlertNode) Kafka() *KafkaHandler {
+	k := &KafkaHandler{
+		AlertNode: a,
+	}
+	a.KafkaHandlers = append(a.KafkaHandlers, k)
+	return k
+}
+
+// tick:embedded:AlertNode.Kafka
+type KafkaHandler struct {
+	*AlertNode
+
+	// Cluster is the ID of the configured Kafka cluster.
+	// May be empty if exactly one cluster is configured.
+	Cluster string
+
+	// The Kafka topic to which alerts are written.
+	KafkaTopic string
+}
+
+// Send the alert message to a syslog server.
+// The messages are formatted as defined in RFC 5424 and sent over UDP, TCP or TLS.
+//
+// Example:
+//    [syslog]
+//      enabled = true
+//      network = "tcp"
+//      addr = "syslog.example.com:514"
+//      facility = "local0"
+//      app-name = "kapacitor"
+//
+// The severity of the messages is derived from the level of the alert:
+//
+//    * OK -- informational
+//    * INFO -- notice
+//    * WARNING -- warning
+//    * CRITICAL -- critical
+//
+// The tags of the alert are sent as structured data.
+//
+// Example:
+//    stream
+//         |alert()
+//             .syslog()
+//
+// Send alerts to the syslog server in the configuration file.
+//
+// Example:
+//    stream
+//         |alert()
+//             .syslog()
+//                 .facility('local3')
+//                 .appName('cpu-alerts')
+//                 .msgID('cpu')
+//
+// Send alerts with a different facility, APP-NAME and MSGID.
+// tick:property
+func (a *AlertNode) Syslog() *SyslogHandler {
+	syslog := &SyslogHandler{
+		AlertNode: a,
+	}
+	a.SyslogHandlers = append(a.SyslogHandlers, syslog)
+	return syslog
+}
+
+// tick:embedded:AlertNode.Syslog
+type SyslogHandler struct {
+	*AlertNode
+
+	// Facility of the messages, i.e. user, daemon or local0 to local7.
+	// If empty uses the facility from the configuration.
+	Facility string
+
+	// APP-NAME of the messages.
+	// If empty uses the app-name from the configuration.
+	AppName string
+
+	// MSGID of the messages.
+	MsgID string
+}
+
 // Send the alert to Sensu.
 //
 // Example:
@@ -1246,6 +1575,18 @@ type SlackHandler struct {
 	// IconEmoji is an emoji name surrounded in ':' characters.
 	// The emoji image will replace the normal user icon for the slack bot.
 	IconEmoji string
+
+	// Send acknowledged events to Slack.
+	// tick:ignore
+	IsNotifyAcknowledged bool `tick:"NotifyAcknowledged"`
+}
+
+// Send events that have been acknowledged to Slack.
+// By default acknowledged events are not sent until the level of the event changes.
+// tick:property
+func (s *SlackHandler) NotifyAcknowledged() *SlackHandler {
+	s.IsNotifyAcknowledged = true
+	return s
 }
 
 // Send the alert to Telegram.
@@ -1459,6 +1800,67 @@ type TalkHandler struct {
 	*AlertNode
 }
 
+// Send the alert to a Microsoft Teams channel.
+// To allow Kapacitor to post to Teams,
+// add an Incoming Webhook connector to the channel
+// and place the generated URL in the 'teams' configuration section.
+//
+// Example:
+//    [teams]
+//      enabled = true
+//      channel-url = "https://outlook.office.com/webhook/xxxxxxxx/IncomingWebhook/xxxxxxxx/xxxxxxxx"
+//
+// The messages are posted as cards, colored by the level of the alert.
+//
+// Example:
+//    stream
+//         |alert()
+//             .teams()
+//
+// Send alerts to the Teams channel in the configuration file.
+//
+// Example:
+//    stream
+//         |alert()
+//             .teams()
+//             .channelURL('https://outlook.office.com/webhook/yyyyyyyy/IncomingWebhook/yyyyyyyy/yyyyyyyy')
+//
+// Send alerts to another Teams channel.
+//
+// If the 'teams' section in the configuration has the option: global = true
+// then all alerts are sent to Teams without the need to explicitly state it
+// in the TICKscript.
+//
+// Example:
+//    [teams]
+//      enabled = true
+//      channel-url = "https://outlook.office.com/webhook/xxxxxxxx/IncomingWebhook/xxxxxxxx/xxxxxxxx"
+//      global = true
+//      state-changes-only = true
+//
+// Example:
+//    stream
+//         |alert()
+//
+// Send alert to the Teams channel in the configuration file.
+// tick:property
+func (a *AlertNode) Teams() *TeamsHandler {
+	teams := &TeamsHandler{
+		AlertNode: a,
+	}
+	a.TeamsHandlers = append(a.TeamsHandlers, teams)
+	return teams
+}
+
+// tick:embedded:AlertNode.Teams
+type TeamsHandler struct {
+	*AlertNode
+
+	// Incoming webhook URL of the Teams channel in which to post messages.
+	// If empty uses the channel URL from the configuration.
+	ChannelURL string
+}
+
 // Send the alert using SNMP traps.
 // To allow Kapacitor to post SNMP traps,
 //
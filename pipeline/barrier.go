@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"errors"
+	"time"
+)
+
+// A BarrierNode emits barrier messages for groups that indicate no data older than the barrier will arrive.
+// Barriers allow downstream nodes to make progress for groups that are no longer receiving data,
+// for example a window emits its buffered data and a join emits any incomplete sets.
+//
+// Barriers are emitted based on the system clock, either once a group has been idle for a duration
+// or on a fixed period.
+// The time of a barrier is the time of the most recent point of the group
+// advanced by the time elapsed since that point arrived.
+// Any points that arrive with a time older than the most recent barrier of their group are dropped.
+//
+// Barriers apply to the groups of the data they are emitted for,
+// as such the barrier node should be placed after any groupBy nodes.
+//
+// Example:
+//    stream
+//        |from()
+//            .measurement('cpu')
+//            .groupBy('host')
+//        |barrier()
+//            .idle(1m)
+//            .delete(TRUE)
+//        |window()
+//            .period(10m)
+//            .every(1m)
+//        |mean('usage_idle')
+//        |alert()
+//            .crit(lambda: "mean" < 10)
+//
+// Each host that stops reporting has its window emitted after a minute of inactivity
+// and then its group is deleted, removing any window and alert state of the host.
+// If the alert for the host was active when the group is deleted a recovery event is sent.
+//
+// A barrier is idle when its group received no data for the entire idle duration or period.
+// On an idle barrier an active alert recovers and the stateDuration and stateCount nodes reset their state,
+// so the next point of the group starts a new state.
+// Barriers emitted on a period for groups that are still receiving data do not change their state.
+//
+// Available Statistics:
+//
+//    * dropped -- number of points dropped because they were older than the last barrier
+//
+type BarrierNode struct {
+	chainnode
+
+	// Emit a barrier once a group has received no data for the duration.
+	// Barriers continue to be emitted each idle duration until the group receives data.
+	Idle time.Duration
+
+	// Emit a barrier for each group every period.
+	Period time.Duration
+
+	// Delete the group after emitting a barrier.
+	// Nodes that receive the delete discard any state they hold for the group.
+	Delete bool
+}
+
+func newBarrierNode(wants EdgeType) *BarrierNode {
+	return &BarrierNode{
+		chainnode: newBasicChainNode("barrier", wants, wants),
+	}
+}
+
+func (n *BarrierNode) validate() error {
+	if n.Idle < 0 {
+		return errors.New("barrier idle must be positive")
+	}
+	if n.Period < 0 {
+		return errors.New("barrier period must be positive")
+	}
+	if n.Idle == 0 && n.Period == 0 {
+		return errors.New("barrier must specify either an idle duration or a period")
+	}
+	if n.Idle != 0 && n.Period != 0 {
+		return errors.New("barrier cannot specify both an idle duration and a period")
+	}
+	return nil
+}
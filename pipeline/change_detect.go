@@ -0,0 +1,46 @@
+package pipeline
+
+import (
+	"errors"
+)
+
+// Forward only the points whose watched fields have changed since the previous point of the group.
+// The previous value of each watched field is added to the forwarded point
+// as a field named with the prefix `previous_`.
+// The first point of each group is always forwarded and does not have any previous fields.
+//
+// Points that do not contain a watched field are not compared on that field.
+// The previous values are kept across batches, as such a batch only contains the points that changed.
+//
+// Example:
+//     stream
+//         |from()
+//             .measurement('inventory')
+//         |groupBy('device')
+//         |changeDetect('status')
+//         |alert()
+//             .info(lambda: TRUE)
+//             .message('{{ index .Tags "device" }} changed from {{ index .Fields "previous_status" }} to {{ index .Fields "status" }}')
+//
+// Only status transitions of each device are alerted on.
+type ChangeDetectNode struct {
+	chainnode
+
+	// The fields to watch for changes
+	// tick:ignore
+	Fields []string
+}
+
+func newChangeDetectNode(wants EdgeType, fields []string) *ChangeDetectNode {
+	return &ChangeDetectNode{
+		chainnode: newBasicChainNode("change_detect", wants, wants),
+		Fields:    fields,
+	}
+}
+
+func (n *ChangeDetectNode) validate() error {
+	if len(n.Fields) == 0 {
+		return errors.New("must specify at least one field to detect changes on")
+	}
+	return nil
+}
@@ -38,6 +38,19 @@ type HTTPPostNode struct {
 	// Headers
 	Headers map[string]string `tick:"Header"`
 
+	// Template of the body of the requests, executed with the posted row.
+	// The row has the fields Name, Tags, Columns and Values,
+	// and the json function returns the JSON encoding of a value.
+	// If empty uses the row template of the endpoint, or posts the row as JSON.
+	//
+	// Example:
+	//    stream
+	//         |httpPost()
+	//            .endpoint('example')
+	//            .rowTemplate('{"name":{{ json .Name }},"points":{{ json .Values }}}')
+	//
+	RowTemplate string
+
 	// tick:ignore
 	URLs []string
 }
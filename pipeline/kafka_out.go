@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Formats of the messages written by a KafkaOutNode
+const (
+	KafkaFormatJSON = "json"
+	KafkaFormatLine = "line"
+)
+
+// A KafkaOutNode writes the incoming data to a Kafka topic, one message per point.
+// The cluster is one of the Kafka clusters in the configuration.
+// Points of the same group are written with the group as key, so they are written to the same partition.
+//
+// Example:
+//    [[kafka]]
+//      enabled = true
+//      id = "metrics"
+//      brokers = ["localhost:9092"]
+//
+// Example:
+//    stream
+//        |from()
+//            .measurement('cpu')
+//            .groupBy('host')
+//        |window()
+//            .period(10s)
+//            .every(10s)
+//        |mean('usage_idle')
+//        |kafkaOut('cpu_mean')
+//            .cluster('metrics')
+//            .format('line')
+//
+// Write the mean idle usage of each host to the topic 'cpu_mean' as line protocol.
+type KafkaOutNode struct {
+	chainnode
+
+	// The Kafka topic
+	// tick:ignore
+	Topic string
+
+	// The ID of the Kafka cluster.
+	// May be empty if exactly one cluster is configured.
+	Cluster string
+
+	// The format of the messages, either 'json' or 'line'.
+	// JSON messages are objects with the name, time, tags and fields of the point.
+	// Line messages are the point in InfluxDB line protocol.
+	// Default: json
+	Format string
+}
+
+func newKafkaOutNode(wants EdgeType, topic string) *KafkaOutNode {
+	return &KafkaOutNode{
+		chainnode: newBasicChainNode("kafka_out", wants, wants),
+		Topic:     topic,
+		Format:    KafkaFormatJSON,
+	}
+}
+
+// tick:ignore
+func (n *KafkaOutNode) validate() error {
+	if n.Topic == "" {
+		return errors.New("must specify a kafka topic")
+	}
+	switch n.Format {
+	case KafkaFormatJSON, KafkaFormatLine:
+	default:
+		return fmt.Errorf("invalid format %q, must be one of %q or %q", n.Format, KafkaFormatJSON, KafkaFormatLine)
+	}
+	return nil
+}
@@ -342,6 +342,13 @@ func (n *chainnode) InfluxDBOut() *InfluxDBOutNode {
 	return i
 }
 
+// Create a Kafka output node that writes the incoming data to the Kafka topic.
+func (n *chainnode) KafkaOut(topic string) *KafkaOutNode {
+	k := newKafkaOutNode(n.provides, topic)
+	n.linkChild(k)
+	return k
+}
+
 // Create an kapacitor loopback node that will send data back into Kapacitor as a stream.
 func (n *chainnode) KapacitorLoopback() *KapacitorLoopbackNode {
 	k := newKapacitorLoopbackNode(n.provides)
@@ -433,6 +440,13 @@ func (n *chainnode) Derivative(field string) *DerivativeNode {
 	return s
 }
 
+// Create a new node that only forwards points when the given fields change.
+func (n *chainnode) ChangeDetect(fields ...string) *ChangeDetectNode {
+	s := newChangeDetectNode(n.Provides(), fields)
+	n.linkChild(s)
+	return s
+}
+
 // Create a new node that shifts the incoming points or batches in time.
 func (n *chainnode) Shift(shift time.Duration) *ShiftNode {
 	s := newShiftNode(n.Provides(), shift)
@@ -481,3 +495,17 @@ func (n *chainnode) StateCount(expression *ast.LambdaNode) *StateCountNode {
 	n.linkChild(sc)
 	return sc
 }
+
+// Create a node that can load data from external sources.
+func (n *chainnode) Sideload() *SideloadNode {
+	s := newSideloadNode(n.provides)
+	n.linkChild(s)
+	return s
+}
+
+// Create a node that emits barriers for groups on a period or once they are idle.
+func (n *chainnode) Barrier() *BarrierNode {
+	b := newBarrierNode(n.provides)
+	n.linkChild(b)
+	return b
+}
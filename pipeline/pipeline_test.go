@@ -65,6 +65,41 @@ w.every(1s)
 	}
 }
 
+func TestTICK_To_Pipeline_Barrier(t *testing.T) {
+	var tickScript = `
+stream
+	|from()
+		.groupBy('host')
+	|barrier()
+		.idle(1m)
+		.delete(TRUE)
+	|window()
+		.period(10s)
+		.every(10s)
+`
+
+	scope := stateful.NewScope()
+	p, err := CreatePipeline(tickScript, StreamEdge, scope, deadman{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, ok := p.sources[0].Children()[0].Children()[0].(*BarrierNode)
+	if !ok {
+		t.Fatalf("unexpected node type: exp BarrierNode got %T", p.sources[0].Children()[0].Children()[0])
+	}
+	if exp, got := time.Minute, b.Idle; exp != got {
+		t.Errorf("unexpected barrier idle exp %v got %v", exp, got)
+	}
+	if !b.Delete {
+		t.Error("expected barrier to delete groups")
+	}
+
+	_, err = CreatePipeline("stream|barrier().idle(1m).period(1m)", StreamEdge, stateful.NewScope(), deadman{}, nil)
+	if err == nil {
+		t.Error("expected error for barrier with both idle and period")
+	}
+}
+
 func TestPipelineSort(t *testing.T) {
 	assert := assert.New(t)
 
@@ -0,0 +1,120 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"text/template"
+)
+
+// Sideload adds fields and tags to points based on hierarchical data from various sources.
+//
+// Example:
+//        |sideload()
+//             .source('file://path/to/dir')
+//             .order('host/{{.host}}.yml', 'service/{{.service}}.yml', 'region/{{.region}}.yml')
+//             .field('cpu_threshold', 0.0)
+//             .tag('sla', 'none')
+//
+// Add a field `cpu_threshold` and a tag `sla` to each point based on the information contained in the sideload files.
+// The files are searched in the order given and the first file that contains the key provides the value.
+// If no file contains the key the default value is used.
+//
+// The order paths are templates, the tags of the point are available to the template.
+// If a tag used by a template does not exist on the point the path is skipped.
+//
+// Files may be YAML or JSON, nested values are referenced by joining their keys with a '.',
+// for example `thresholds.cpu`.
+//
+// The files are read when the task starts and can be reread by reloading the task
+// or by a POST request to the `/kapacitor/v1/sideload/reload` endpoint.
+//
+// Available Statistics:
+//
+//    * errors -- number of points that could not be sideloaded
+//
+type SideloadNode struct {
+	chainnode
+
+	// Source for the data, currently only `file://` based sources are supported.
+	// The source is relative to the `dir` of the `[sideload]` configuration,
+	// absolute sources must be within that directory.
+	Source string
+
+	// Order is a list of paths that indicate the hierarchical order.
+	// The paths are relative to the source and can have template markers like `{{.tagname}}` that will be replaced with the tag value of the point.
+	// The paths are then searched in order for the keys and the first value that is found is used.
+	// This allows for values to be overridden based on a hierarchy of tags.
+	// tick:ignore
+	OrderList []string `tick:"Order"`
+
+	// Fields is a list of fields to load.
+	// tick:ignore
+	Fields map[string]interface{} `tick:"Field"`
+	// Tags is a list of tags to load.
+	// tick:ignore
+	Tags map[string]string `tick:"Tag"`
+}
+
+func newSideloadNode(wants EdgeType) *SideloadNode {
+	return &SideloadNode{
+		chainnode: newBasicChainNode("sideload", wants, wants),
+		Fields:    make(map[string]interface{}),
+		Tags:      make(map[string]string),
+	}
+}
+
+// Order is a list of paths that indicate the hierarchical order.
+// The paths are relative to the source and can have template markers like `{{.tagname}}` that will be replaced with the tag value of the point.
+// The paths are then searched in order for the keys and the first value that is found is used.
+// This allows for values to be overridden based on a hierarchy of tags.
+// tick:property
+func (n *SideloadNode) Order(order ...string) *SideloadNode {
+	n.OrderList = order
+	return n
+}
+
+// Field is the name of a field to load from the source and its default value.
+// The type loaded must match the type of the default value.
+// Otherwise an error is recorded and the default value is used.
+// tick:property
+func (n *SideloadNode) Field(f string, v interface{}) *SideloadNode {
+	n.Fields[f] = v
+	return n
+}
+
+// Tag is the name of a tag to load from the source and its default value.
+// Loaded numbers and booleans are formatted as strings,
+// any other type records an error and the default value is used.
+// tick:property
+func (n *SideloadNode) Tag(t string, v string) *SideloadNode {
+	n.Tags[t] = v
+	return n
+}
+
+func (n *SideloadNode) validate() error {
+	if n.Source == "" {
+		return errors.New("must specify a source for sideload")
+	}
+	if len(n.OrderList) == 0 {
+		return errors.New("must specify at least one order path for sideload")
+	}
+	if len(n.Fields) == 0 && len(n.Tags) == 0 {
+		return errors.New("must specify at least one field or tag to sideload")
+	}
+	for _, o := range n.OrderList {
+		if _, err := template.New("order").Parse(o); err != nil {
+			return fmt.Errorf("invalid order template %q: %v", o, err)
+		}
+	}
+	for field, value := range n.Fields {
+		switch value.(type) {
+		case float64:
+		case int64:
+		case bool:
+		case string:
+		default:
+			return fmt.Errorf("unsupported type %T for field %q, field default values must be float,int,string or bool", value, field)
+		}
+	}
+	return nil
+}
@@ -12,7 +12,10 @@ import (
 	"time"
 
 	"github.com/influxdata/kapacitor/command"
+	"github.com/influxdata/kapacitor/services/alert"
 	"github.com/influxdata/kapacitor/services/alerta"
+	"github.com/influxdata/kapacitor/services/alertmanager"
+	"github.com/influxdata/kapacitor/services/audit"
 	"github.com/influxdata/kapacitor/services/azure"
 	"github.com/influxdata/kapacitor/services/config"
 	"github.com/influxdata/kapacitor/services/consul"
@@ -25,7 +28,10 @@ import (
 	"github.com/influxdata/kapacitor/services/httpd"
 	"github.com/influxdata/kapacitor/services/httppost"
 	"github.com/influxdata/kapacitor/services/influxdb"
+	"github.com/influxdata/kapacitor/services/jira"
 	"github.com/influxdata/kapacitor/services/k8s"
+	"github.com/influxdata/kapacitor/services/kafka"
+	"github.com/influxdata/kapacitor/services/localauth"
 	"github.com/influxdata/kapacitor/services/logging"
 	"github.com/influxdata/kapacitor/services/marathon"
 	"github.com/influxdata/kapacitor/services/mqtt"
@@ -38,14 +44,17 @@ import (
 	"github.com/influxdata/kapacitor/services/scraper"
 	"github.com/influxdata/kapacitor/services/sensu"
 	"github.com/influxdata/kapacitor/services/serverset"
+	"github.com/influxdata/kapacitor/services/sideload"
 	"github.com/influxdata/kapacitor/services/slack"
 	"github.com/influxdata/kapacitor/services/smtp"
 	"github.com/influxdata/kapacitor/services/snmptrap"
 	"github.com/influxdata/kapacitor/services/static_discovery"
 	"github.com/influxdata/kapacitor/services/stats"
 	"github.com/influxdata/kapacitor/services/storage"
+	"github.com/influxdata/kapacitor/services/syslog"
 	"github.com/influxdata/kapacitor/services/talk"
 	"github.com/influxdata/kapacitor/services/task_store"
+	"github.com/influxdata/kapacitor/services/teams"
 	"github.com/influxdata/kapacitor/services/telegram"
 	"github.com/influxdata/kapacitor/services/triton"
 	"github.com/influxdata/kapacitor/services/udf"
@@ -61,8 +70,12 @@ import (
 // Config represents the configuration format for the kapacitord binary.
 type Config struct {
 	HTTP           httpd.Config      `toml:"http"`
+	Auth           localauth.Config  `toml:"auth"`
+	Audit          audit.Config      `toml:"audit"`
+	Alert          alert.Config      `toml:"alert"`
 	Replay         replay.Config     `toml:"replay"`
 	Storage        storage.Config    `toml:"storage"`
+	Sideload       sideload.Config   `toml:"sideload"`
 	Task           task_store.Config `toml:"task"`
 	InfluxDB       []influxdb.Config `toml:"influxdb" override:"influxdb,element-key=name"`
 	Logging        logging.Config    `toml:"logging"`
@@ -75,20 +88,25 @@ type Config struct {
 	UDP      []udp.Config      `toml:"udp"`
 
 	// Alert handlers
-	Alerta    alerta.Config    `toml:"alerta" override:"alerta"`
-	HipChat   hipchat.Config   `toml:"hipchat" override:"hipchat"`
-	MQTT      mqtt.Configs     `toml:"mqtt" override:"mqtt,element-key=name"`
-	OpsGenie  opsgenie.Config  `toml:"opsgenie" override:"opsgenie"`
-	PagerDuty pagerduty.Config `toml:"pagerduty" override:"pagerduty"`
-	Pushover  pushover.Config  `toml:"pushover" override:"pushover"`
-	HTTPPost  httppost.Configs `toml:"httppost" override:"httppost,element-key=endpoint"`
-	SMTP      smtp.Config      `toml:"smtp" override:"smtp"`
-	SNMPTrap  snmptrap.Config  `toml:"snmptrap" override:"snmptrap"`
-	Sensu     sensu.Config     `toml:"sensu" override:"sensu"`
-	Slack     slack.Config     `toml:"slack" override:"slack"`
-	Talk      talk.Config      `toml:"talk" override:"talk"`
-	Telegram  telegram.Config  `toml:"telegram" override:"telegram"`
-	VictorOps victorops.Config `toml:"victorops" override:"victorops"`
+	Alerta       alerta.Config       `toml:"alerta" override:"alerta"`
+	Alertmanager alertmanager.Config `toml:"alertmanager" override:"alertmanager"`
+	HipChat      hipchat.Config      `toml:"hipchat" override:"hipchat"`
+	Jira         jira.Config         `toml:"jira" override:"jira"`
+	Kafka        kafka.Configs       `toml:"kafka" override:"kafka,element-key=id"`
+	MQTT         mqtt.Configs        `toml:"mqtt" override:"mqtt,element-key=name"`
+	OpsGenie     opsgenie.Config     `toml:"opsgenie" override:"opsgenie"`
+	PagerDuty    pagerduty.Config    `toml:"pagerduty" override:"pagerduty"`
+	Pushover     pushover.Config     `toml:"pushover" override:"pushover"`
+	HTTPPost     httppost.Configs    `toml:"httppost" override:"httppost,element-key=endpoint"`
+	SMTP         smtp.Config         `toml:"smtp" override:"smtp"`
+	SNMPTrap     snmptrap.Config     `toml:"snmptrap" override:"snmptrap"`
+	Sensu        sensu.Config        `toml:"sensu" override:"sensu"`
+	Slack        slack.Config        `toml:"slack" override:"slack"`
+	Syslog       syslog.Config       `toml:"syslog" override:"syslog"`
+	Talk         talk.Config         `toml:"talk" override:"talk"`
+	Teams        teams.Config        `toml:"teams" override:"teams"`
+	Telegram     telegram.Config     `toml:"telegram" override:"telegram"`
+	VictorOps    victorops.Config    `toml:"victorops" override:"victorops"`
 
 	// Discovery for scraping
 	Scraper         []scraper.Config          `toml:"scraper" override:"scraper,element-key=name"`
@@ -128,7 +146,11 @@ func NewConfig() *Config {
 	}
 
 	c.HTTP = httpd.NewConfig()
+	c.Auth = localauth.NewConfig()
+	c.Audit = audit.NewConfig()
+	c.Alert = alert.NewConfig()
 	c.Storage = storage.NewConfig()
+	c.Sideload = sideload.NewConfig()
 	c.Replay = replay.NewConfig()
 	c.Task = task_store.NewConfig()
 	c.InfluxDB = []influxdb.Config{influxdb.NewConfig()}
@@ -139,7 +161,10 @@ func NewConfig() *Config {
 	c.OpenTSDB = opentsdb.NewConfig()
 
 	c.Alerta = alerta.NewConfig()
+	c.Alertmanager = alertmanager.NewConfig()
 	c.HipChat = hipchat.NewConfig()
+	c.Jira = jira.NewConfig()
+	c.Kafka = kafka.Configs{}
 	c.MQTT = mqtt.Configs{}
 	c.OpsGenie = opsgenie.NewConfig()
 	c.PagerDuty = pagerduty.NewConfig()
@@ -149,7 +174,9 @@ func NewConfig() *Config {
 	c.Sensu = sensu.NewConfig()
 	c.Slack = slack.NewConfig()
 	c.Talk = talk.NewConfig()
+	c.Teams = teams.NewConfig()
 	c.SNMPTrap = snmptrap.NewConfig()
+	c.Syslog = syslog.NewConfig()
 	c.Telegram = telegram.NewConfig()
 	c.VictorOps = victorops.NewConfig()
 
@@ -179,6 +206,7 @@ func NewDemoConfig() (*Config, error) {
 	c.Replay.Dir = filepath.Join(homeDir, ".kapacitor", c.Replay.Dir)
 	c.Task.Dir = filepath.Join(homeDir, ".kapacitor", c.Task.Dir)
 	c.Storage.BoltDBPath = filepath.Join(homeDir, ".kapacitor", c.Storage.BoltDBPath)
+	c.Sideload.Dir = filepath.Join(homeDir, ".kapacitor", c.Sideload.Dir)
 	c.DataDir = filepath.Join(homeDir, ".kapacitor", c.DataDir)
 
 	return c, nil
@@ -198,9 +226,21 @@ func (c *Config) Validate() error {
 	if err := c.Storage.Validate(); err != nil {
 		return err
 	}
+	if err := c.Sideload.Validate(); err != nil {
+		return err
+	}
 	if err := c.HTTP.Validate(); err != nil {
 		return err
 	}
+	if err := c.Auth.Validate(); err != nil {
+		return errors.Wrap(err, "auth")
+	}
+	if err := c.Audit.Validate(); err != nil {
+		return errors.Wrap(err, "audit")
+	}
+	if err := c.Alert.Validate(); err != nil {
+		return errors.Wrap(err, "alert")
+	}
 	if err := c.Task.Validate(); err != nil {
 		return err
 	}
@@ -245,9 +285,18 @@ func (c *Config) Validate() error {
 	if err := c.Alerta.Validate(); err != nil {
 		return err
 	}
+	if err := c.Alertmanager.Validate(); err != nil {
+		return err
+	}
 	if err := c.HipChat.Validate(); err != nil {
 		return err
 	}
+	if err := c.Jira.Validate(); err != nil {
+		return err
+	}
+	if err := c.Kafka.Validate(); err != nil {
+		return err
+	}
 	if err := c.MQTT.Validate(); err != nil {
 		return err
 	}
@@ -272,12 +321,18 @@ func (c *Config) Validate() error {
 	if err := c.Sensu.Validate(); err != nil {
 		return err
 	}
+	if err := c.Syslog.Validate(); err != nil {
+		return err
+	}
 	if err := c.Slack.Validate(); err != nil {
 		return err
 	}
 	if err := c.Talk.Validate(); err != nil {
 		return err
 	}
+	if err := c.Teams.Validate(); err != nil {
+		return err
+	}
 	if err := c.Telegram.Validate(); err != nil {
 		return err
 	}
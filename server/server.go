@@ -23,7 +23,10 @@ import (
 	"github.com/influxdata/kapacitor/server/vars"
 	"github.com/influxdata/kapacitor/services/alert"
 	"github.com/influxdata/kapacitor/services/alerta"
+	"github.com/influxdata/kapacitor/services/alertmanager"
+	"github.com/influxdata/kapacitor/services/audit"
 	"github.com/influxdata/kapacitor/services/azure"
+	"github.com/influxdata/kapacitor/services/blobstore"
 	"github.com/influxdata/kapacitor/services/config"
 	"github.com/influxdata/kapacitor/services/consul"
 	"github.com/influxdata/kapacitor/services/deadman"
@@ -35,7 +38,10 @@ import (
 	"github.com/influxdata/kapacitor/services/httpd"
 	"github.com/influxdata/kapacitor/services/httppost"
 	"github.com/influxdata/kapacitor/services/influxdb"
+	"github.com/influxdata/kapacitor/services/jira"
 	"github.com/influxdata/kapacitor/services/k8s"
+	"github.com/influxdata/kapacitor/services/kafka"
+	"github.com/influxdata/kapacitor/services/localauth"
 	"github.com/influxdata/kapacitor/services/logging"
 	"github.com/influxdata/kapacitor/services/marathon"
 	"github.com/influxdata/kapacitor/services/mqtt"
@@ -50,14 +56,17 @@ import (
 	"github.com/influxdata/kapacitor/services/sensu"
 	"github.com/influxdata/kapacitor/services/serverset"
 	"github.com/influxdata/kapacitor/services/servicetest"
+	"github.com/influxdata/kapacitor/services/sideload"
 	"github.com/influxdata/kapacitor/services/slack"
 	"github.com/influxdata/kapacitor/services/smtp"
 	"github.com/influxdata/kapacitor/services/snmptrap"
 	"github.com/influxdata/kapacitor/services/static_discovery"
 	"github.com/influxdata/kapacitor/services/stats"
 	"github.com/influxdata/kapacitor/services/storage"
+	"github.com/influxdata/kapacitor/services/syslog"
 	"github.com/influxdata/kapacitor/services/talk"
 	"github.com/influxdata/kapacitor/services/task_store"
+	"github.com/influxdata/kapacitor/services/teams"
 	"github.com/influxdata/kapacitor/services/telegram"
 	"github.com/influxdata/kapacitor/services/triton"
 	"github.com/influxdata/kapacitor/services/udf"
@@ -100,6 +109,8 @@ type Server struct {
 	AuthService           auth.Interface
 	HTTPDService          *httpd.Service
 	StorageService        *storage.Service
+	BlobStoreService      *blobstore.Service
+	SideloadService       *sideload.Service
 	AlertService          *alert.Service
 	TaskStore             *task_store.Service
 	ReplayService         *replay.Service
@@ -189,8 +200,11 @@ func New(c *Config, buildInfo BuildInfo, logService logging.Interface) (*Server,
 	s.initHTTPDService()
 	s.appendStorageService()
 	s.appendAuthService()
+	s.appendAuditService()
 	s.appendConfigOverrideService()
 	s.appendTesterService()
+	s.appendBlobStoreService()
+	s.appendSideloadService()
 
 	// Init alert service
 	s.initAlertService()
@@ -205,14 +219,21 @@ func New(c *Config, buildInfo BuildInfo, logService logging.Interface) (*Server,
 
 	// Append Alert integration services
 	s.appendAlertaService()
+	s.appendAlertmanagerService()
+	s.appendJiraService()
 	s.appendHipChatService()
+	if err := s.appendKafkaService(); err != nil {
+		return nil, errors.Wrap(err, "kafka service")
+	}
 	if err := s.appendMQTTService(); err != nil {
 		return nil, errors.Wrap(err, "mqtt service")
 	}
 	s.appendOpsGenieService()
 	s.appendPagerDutyService()
 	s.appendPushoverService()
-	s.appendHTTPPostService()
+	if err := s.appendHTTPPostService(); err != nil {
+		return nil, errors.Wrap(err, "httppost service")
+	}
 	s.appendSMTPService()
 	s.appendTelegramService()
 	if err := s.appendSlackService(); err != nil {
@@ -220,7 +241,9 @@ func New(c *Config, buildInfo BuildInfo, logService logging.Interface) (*Server,
 	}
 	s.appendSNMPTrapService()
 	s.appendSensuService()
+	s.appendSyslogService()
 	s.appendTalkService()
+	s.appendTeamsService()
 	s.appendVictorOpsService()
 
 	// Append alert service
@@ -302,6 +325,26 @@ func (s *Server) appendStorageService() {
 	s.AppendService("storage", srv)
 }
 
+func (s *Server) appendBlobStoreService() {
+	l := s.LogService.NewLogger("[blobstore] ", log.LstdFlags)
+	srv := blobstore.NewService(l)
+	srv.StorageService = s.StorageService
+	srv.HTTPDService = s.HTTPDService
+
+	s.BlobStoreService = srv
+	s.AppendService("blobstore", srv)
+}
+
+func (s *Server) appendSideloadService() {
+	l := s.LogService.NewLogger("[sideload] ", log.LstdFlags)
+	srv := sideload.NewService(s.config.Sideload, l)
+	srv.HTTPDService = s.HTTPDService
+
+	s.TaskMaster.SideloadService = srv
+	s.SideloadService = srv
+	s.AppendService("sideload", srv)
+}
+
 func (s *Server) appendConfigOverrideService() {
 	l := s.LogService.NewLogger("[config-override] ", log.LstdFlags)
 	srv := config.NewService(s.config.ConfigOverride, s.config, l, s.configUpdates)
@@ -314,7 +357,7 @@ func (s *Server) appendConfigOverrideService() {
 
 func (s *Server) initAlertService() {
 	l := s.LogService.NewLogger("[alert] ", log.LstdFlags)
-	srv := alert.NewService(l)
+	srv := alert.NewService(s.config.Alert, l)
 
 	srv.Commander = s.Commander
 	srv.HTTPDService = s.HTTPDService
@@ -445,20 +488,62 @@ func (s *Server) appendDeadmanService() {
 func (s *Server) appendUDFService() {
 	l := s.LogService.NewLogger("[udf] ", log.LstdFlags)
 	srv := udf.NewService(s.config.UDF, l)
+	srv.BlobStoreService = s.BlobStoreService
 
 	s.TaskMaster.UDFService = srv
 	s.AppendService("udf", srv)
 }
 
 func (s *Server) appendAuthService() {
-	l := s.LogService.NewLogger("[noauth] ", log.LstdFlags)
-	srv := noauth.NewService(l)
+	if !s.config.HTTP.AuthEnabled {
+		l := s.LogService.NewLogger("[noauth] ", log.LstdFlags)
+		srv := noauth.NewService(l)
+
+		s.AuthService = srv
+		s.HTTPDService.Handler.AuthService = srv
+		s.AppendService("auth", srv)
+		return
+	}
+	l := s.LogService.NewLogger("[auth] ", log.LstdFlags)
+	srv := localauth.NewService(s.config.Auth, s.config.HTTP.SharedSecret, l)
+	srv.StorageService = s.StorageService
+	srv.HTTPDService = s.HTTPDService
 
 	s.AuthService = srv
 	s.HTTPDService.Handler.AuthService = srv
 	s.AppendService("auth", srv)
 }
 
+func (s *Server) appendAuditService() {
+	c := s.config.Audit
+	if !c.Enabled {
+		return
+	}
+	l := s.LogService.NewLogger("[audit] ", log.LstdFlags)
+	srv := audit.NewService(c, l)
+	srv.StorageService = s.StorageService
+	srv.HTTPDService = s.HTTPDService
+
+	s.HTTPDService.Handler.AuditService = srv
+	s.AppendService("audit", srv)
+}
+
+func (s *Server) appendKafkaService() error {
+	cs := s.config.Kafka
+	l := s.LogService.NewLogger("[kafka] ", log.LstdFlags)
+	srv, err := kafka.NewService(cs, l)
+	if err != nil {
+		return err
+	}
+
+	s.TaskMaster.KafkaService = srv
+	s.AlertService.KafkaService = srv
+
+	s.SetDynamicService("kafka", srv)
+	s.AppendService("kafka", srv)
+	return nil
+}
+
 func (s *Server) appendMQTTService() error {
 	cs := s.config.MQTT
 	l := s.LogService.NewLogger("[mqtt] ", log.LstdFlags)
@@ -524,16 +609,20 @@ func (s *Server) appendPushoverService() {
 	s.AppendService("pushover", srv)
 }
 
-func (s *Server) appendHTTPPostService() {
+func (s *Server) appendHTTPPostService() error {
 	c := s.config.HTTPPost
 	l := s.LogService.NewLogger("[httppost] ", log.LstdFlags)
-	srv := httppost.NewService(c, l)
+	srv, err := httppost.NewService(c, l)
+	if err != nil {
+		return err
+	}
 
 	s.TaskMaster.HTTPPostService = srv
 	s.AlertService.HTTPPostService = srv
 
 	s.SetDynamicService("httppost", srv)
 	s.AppendService("httppost", srv)
+	return nil
 }
 
 func (s *Server) appendSensuService() {
@@ -612,6 +701,30 @@ func (s *Server) appendAlertaService() {
 	s.AppendService("alerta", srv)
 }
 
+func (s *Server) appendJiraService() {
+	c := s.config.Jira
+	l := s.LogService.NewLogger("[jira] ", log.LstdFlags)
+	srv := jira.NewService(c, l)
+
+	s.TaskMaster.JiraService = srv
+	s.AlertService.JiraService = srv
+
+	s.SetDynamicService("jira", srv)
+	s.AppendService("jira", srv)
+}
+
+func (s *Server) appendAlertmanagerService() {
+	c := s.config.Alertmanager
+	l := s.LogService.NewLogger("[alertmanager] ", log.LstdFlags)
+	srv := alertmanager.NewService(c, l)
+
+	s.TaskMaster.AlertmanagerService = srv
+	s.AlertService.AlertmanagerService = srv
+
+	s.SetDynamicService("alertmanager", srv)
+	s.AppendService("alertmanager", srv)
+}
+
 func (s *Server) appendTalkService() {
 	c := s.config.Talk
 	l := s.LogService.NewLogger("[talk] ", log.LstdFlags)
@@ -624,6 +737,30 @@ func (s *Server) appendTalkService() {
 	s.AppendService("talk", srv)
 }
 
+func (s *Server) appendSyslogService() {
+	c := s.config.Syslog
+	l := s.LogService.NewLogger("[syslog] ", log.LstdFlags)
+	srv := syslog.NewService(c, l)
+
+	s.TaskMaster.SyslogService = srv
+	s.AlertService.SyslogService = srv
+
+	s.SetDynamicService("syslog", srv)
+	s.AppendService("syslog", srv)
+}
+
+func (s *Server) appendTeamsService() {
+	c := s.config.Teams
+	l := s.LogService.NewLogger("[teams] ", log.LstdFlags)
+	srv := teams.NewService(c, l)
+
+	s.TaskMaster.TeamsService = srv
+	s.AlertService.TeamsService = srv
+
+	s.SetDynamicService("teams", srv)
+	s.AppendService("teams", srv)
+}
+
 func (s *Server) appendCollectdService() {
 	c := s.config.Collectd
 	if !c.Enabled {
@@ -117,6 +117,7 @@ func (s *Server) Close() {
 	s.Server.Close()
 	os.RemoveAll(s.Config.Replay.Dir)
 	os.RemoveAll(filepath.Dir(s.Config.Storage.BoltDBPath))
+	os.RemoveAll(s.Config.Sideload.Dir)
 	os.RemoveAll(s.Config.DataDir)
 }
 
@@ -212,6 +213,7 @@ func NewConfig() *server.Config {
 	c.Reporting.Enabled = false
 	c.Replay.Dir = MustTempDir()
 	c.Storage.BoltDBPath = filepath.Join(MustTempDir(), "bolt.db")
+	c.Sideload.Dir = MustTempDir()
 	c.DataDir = MustTempDir()
 	c.HTTP.BindAddress = "127.0.0.1:0"
 	//c.HTTP.BindAddress = "127.0.0.1:9092"
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"net/mail"
 	"net/url"
 	"os"
@@ -17,6 +18,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -33,9 +35,13 @@ import (
 	"github.com/influxdata/kapacitor/server"
 	"github.com/influxdata/kapacitor/services/alert/alerttest"
 	"github.com/influxdata/kapacitor/services/alerta/alertatest"
+	"github.com/influxdata/kapacitor/services/alertmanager/alertmanagertest"
 	"github.com/influxdata/kapacitor/services/hipchat/hipchattest"
 	"github.com/influxdata/kapacitor/services/httppost"
+	"github.com/influxdata/kapacitor/services/jira/jiratest"
 	"github.com/influxdata/kapacitor/services/k8s"
+	"github.com/influxdata/kapacitor/services/kafka"
+	"github.com/influxdata/kapacitor/services/kafka/kafkatest"
 	"github.com/influxdata/kapacitor/services/mqtt"
 	"github.com/influxdata/kapacitor/services/mqtt/mqtttest"
 	"github.com/influxdata/kapacitor/services/opsgenie"
@@ -47,7 +53,9 @@ import (
 	"github.com/influxdata/kapacitor/services/slack/slacktest"
 	"github.com/influxdata/kapacitor/services/smtp/smtptest"
 	"github.com/influxdata/kapacitor/services/snmptrap/snmptraptest"
+	"github.com/influxdata/kapacitor/services/syslog/syslogtest"
 	"github.com/influxdata/kapacitor/services/talk/talktest"
+	"github.com/influxdata/kapacitor/services/teams/teamstest"
 	"github.com/influxdata/kapacitor/services/telegram"
 	"github.com/influxdata/kapacitor/services/telegram/telegramtest"
 	"github.com/influxdata/kapacitor/services/udf"
@@ -147,6 +155,8 @@ func TestServer_Authenticate_Fail(t *testing.T) {
 func TestServer_Authenticate_User(t *testing.T) {
 	conf := NewConfig()
 	conf.HTTP.AuthEnabled = true
+	conf.Auth.BootstrapUsername = "bob"
+	conf.Auth.BootstrapPassword = "bob's secure password"
 	s := OpenServer(conf)
 	cli, err := client.New(client.Config{
 		URL: s.URL(),
@@ -169,6 +179,310 @@ func TestServer_Authenticate_User(t *testing.T) {
 	}
 }
 
+func TestServer_Authenticate_User_Privileges(t *testing.T) {
+	conf := NewConfig()
+	conf.HTTP.AuthEnabled = true
+	conf.Auth.BootstrapUsername = "admin"
+	conf.Auth.BootstrapPassword = "admin secret"
+	s := OpenServer(conf)
+	defer s.Close()
+	newClient := func(username, password string) *client.Client {
+		cli, err := client.New(client.Config{
+			URL: s.URL(),
+			Credentials: &client.Credentials{
+				Method:   client.UserAuthentication,
+				Username: username,
+				Password: password,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cli
+	}
+	admin := newClient("admin", "admin secret")
+	if _, err := admin.CreateUser(client.CreateUserOptions{
+		Username: "bob",
+		Password: "bob secret",
+		Privileges: map[string][]string{
+			"/api/tasks": {"read"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := newClient("bob", "wrong").Ping(); err == nil {
+		t.Error("expected authentication error")
+	} else if exp, got := "authorization failed", err.Error(); got != exp {
+		t.Errorf("unexpected error message: got %q exp %q", got, exp)
+	}
+
+	bob := newClient("bob", "bob secret")
+	if _, err := bob.ListTasks(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.CreateTask(client.CreateTaskOptions{
+		ID:         "testTaskID",
+		Type:       client.StreamTask,
+		DBRPs:      []client.DBRP{{Database: "mydb", RetentionPolicy: "myrp"}},
+		TICKscript: "stream\n    |from()\n",
+	}); err == nil {
+		t.Error("expected authorization error creating task")
+	} else if exp, got := `user bob does not have "write" privilege for API endpoint "/kapacitor/v1/tasks"`, err.Error(); got != exp {
+		t.Errorf("unexpected error message: got %q exp %q", got, exp)
+	}
+}
+
+func TestServer_Authenticate_User_GrantPrivileges(t *testing.T) {
+	conf := NewConfig()
+	conf.HTTP.AuthEnabled = true
+	conf.Auth.BootstrapUsername = "admin"
+	conf.Auth.BootstrapPassword = "admin secret"
+	s := OpenServer(conf)
+	defer s.Close()
+	newClient := func(username, password string) *client.Client {
+		cli, err := client.New(client.Config{
+			URL: s.URL(),
+			Credentials: &client.Credentials{
+				Method:   client.UserAuthentication,
+				Username: username,
+				Password: password,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cli
+	}
+	admin := newClient("admin", "admin secret")
+	if _, err := admin.CreateUser(client.CreateUserOptions{
+		Username: "bob",
+		Password: "bob secret",
+		Privileges: map[string][]string{
+			"/api/users": {"all"},
+			"/api/tasks": {"read"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	bob := newClient("bob", "bob secret")
+	if _, err := bob.CreateUser(client.CreateUserOptions{
+		Username: "mallory",
+		Password: "mallory secret",
+		Admin:    true,
+	}); err == nil {
+		t.Error("expected authorization error creating admin user")
+	} else if exp, got := "user bob cannot grant admin status", err.Error(); got != exp {
+		t.Errorf("unexpected error message: got %q exp %q", got, exp)
+	}
+	if _, err := bob.CreateUser(client.CreateUserOptions{
+		Username: "mallory",
+		Password: "mallory secret",
+		Privileges: map[string][]string{
+			"/api/tasks": {"write"},
+		},
+	}); err == nil {
+		t.Error("expected authorization error creating user with more privileges")
+	}
+	if _, err := bob.UpdateUser(bob.UserLink("bob"), client.UpdateUserOptions{
+		Privileges: map[string][]string{
+			"/": {"all"},
+		},
+	}); err == nil {
+		t.Error("expected authorization error granting own privileges")
+	}
+	isAdmin := true
+	if _, err := bob.UpdateUser(bob.UserLink("bob"), client.UpdateUserOptions{
+		Admin: &isAdmin,
+	}); err == nil {
+		t.Error("expected authorization error granting own admin status")
+	} else if exp, got := "user bob cannot change the admin status of users", err.Error(); got != exp {
+		t.Errorf("unexpected error message: got %q exp %q", got, exp)
+	}
+	if _, err := bob.UpdateUser(bob.UserLink("admin"), client.UpdateUserOptions{
+		Password: "taken over",
+	}); err == nil {
+		t.Error("expected authorization error changing admin password")
+	}
+	if err := bob.DeleteUser(bob.UserLink("admin")); err == nil {
+		t.Error("expected authorization error deleting admin user")
+	}
+	if _, _, err := newClient("admin", "admin secret").Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Privileges the user has may be granted.
+	if _, err := bob.CreateUser(client.CreateUserOptions{
+		Username: "alice",
+		Password: "alice secret",
+		Privileges: map[string][]string{
+			"/api/tasks": {"read"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newClient("alice", "alice secret").ListTasks(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Users with privileges the user does not have cannot be changed or deleted.
+	if _, err := admin.CreateUser(client.CreateUserOptions{
+		Username: "carol",
+		Password: "carol secret",
+		Privileges: map[string][]string{
+			"/api/tasks": {"all"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.UpdateUser(bob.UserLink("carol"), client.UpdateUserOptions{
+		Password: "taken over",
+	}); err == nil {
+		t.Error("expected authorization error changing password of user with more privileges")
+	}
+	if err := bob.DeleteUser(bob.UserLink("carol")); err == nil {
+		t.Error("expected authorization error deleting user with more privileges")
+	}
+	if _, err := newClient("carol", "carol secret").ListTasks(nil); err != nil {
+		t.Fatal(err)
+	}
+	// Users may change themselves and users with a subset of their privileges.
+	if _, err := bob.UpdateUser(bob.UserLink("alice"), client.UpdateUserOptions{
+		Password: "new alice secret",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.UpdateUser(bob.UserLink("bob"), client.UpdateUserOptions{
+		Password: "new bob secret",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := newClient("bob", "new bob secret").DeleteUser(bob.UserLink("alice")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServer_Authenticate_User_ScopedPrivileges(t *testing.T) {
+	conf := NewConfig()
+	conf.HTTP.AuthEnabled = true
+	conf.Auth.BootstrapUsername = "admin"
+	conf.Auth.BootstrapPassword = "admin secret"
+	s := OpenServer(conf)
+	defer s.Close()
+	newClient := func(username, password string) *client.Client {
+		cli, err := client.New(client.Config{
+			URL: s.URL(),
+			Credentials: &client.Credentials{
+				Method:   client.UserAuthentication,
+				Username: username,
+				Password: password,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cli
+	}
+	createTask := func(cli *client.Client, id string) error {
+		_, err := cli.CreateTask(client.CreateTaskOptions{
+			ID:         id,
+			Type:       client.StreamTask,
+			DBRPs:      []client.DBRP{{Database: "mydb", RetentionPolicy: "myrp"}},
+			TICKscript: "stream\n    |from()\n",
+		})
+		return err
+	}
+	createTaskFromTemplate := func(cli *client.Client, id, templateID string) error {
+		_, err := cli.CreateTask(client.CreateTaskOptions{
+			ID:         id,
+			TemplateID: templateID,
+			DBRPs:      []client.DBRP{{Database: "mydb", RetentionPolicy: "myrp"}},
+		})
+		return err
+	}
+	admin := newClient("admin", "admin secret")
+	if err := createTask(admin, "teamb_cpu"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := admin.CreateUser(client.CreateUserOptions{
+		Username: "bob",
+		Password: "bob secret",
+		Privileges: map[string][]string{
+			"/api/tasks/teama_*": {"all"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	bob := newClient("bob", "bob secret")
+	if err := createTask(bob, "teama_cpu"); err != nil {
+		t.Fatal(err)
+	}
+	if err := createTask(bob, "teamb_mem"); err == nil {
+		t.Error("expected authorization error creating task of another team")
+	}
+	if _, err := bob.UpdateTask(bob.TaskLink("teamb_cpu"), client.UpdateTaskOptions{Status: client.Enabled}); err == nil {
+		t.Error("expected authorization error updating task of another team")
+	}
+	if _, err := bob.UpdateTask(bob.TaskLink("teama_cpu"), client.UpdateTaskOptions{ID: "teamb_cpu2"}); err == nil {
+		t.Error("expected authorization error renaming task to another team")
+	}
+	if err := bob.DeleteTask(bob.TaskLink("teamb_cpu")); err == nil {
+		t.Error("expected authorization error deleting task of another team")
+	}
+
+	tasks, err := bob.ListTasks(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "teama_cpu" {
+		t.Errorf("unexpected tasks listed: %v", tasks)
+	}
+	tasks, err = admin.ListTasks(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("unexpected tasks listed for admin: %v", tasks)
+	}
+	if _, err := bob.ListTemplates(nil); err == nil {
+		t.Error("expected authorization error listing templates")
+	}
+
+	// Grant access to the templates of the team
+	if _, err := admin.UpdateUser(admin.UserLink("bob"), client.UpdateUserOptions{
+		Privileges: map[string][]string{
+			"/api/tasks/teama_*":     {"all"},
+			"/api/templates/teama_*": {"all"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		cli *client.Client
+		id  string
+	}{{admin, "teamb_tmpl"}, {bob, "teama_tmpl"}} {
+		if _, err := c.cli.CreateTemplate(client.CreateTemplateOptions{
+			ID:         c.id,
+			Type:       client.StreamTask,
+			TICKscript: "stream\n    |from()\n",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	templates, err := bob.ListTemplates(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || templates[0].ID != "teama_tmpl" {
+		t.Errorf("unexpected templates listed: %v", templates)
+	}
+	if err := createTaskFromTemplate(bob, "teama_from_tmpl", "teamb_tmpl"); err == nil {
+		t.Error("expected authorization error creating task from template of another team")
+	}
+}
+
 func TestServer_Authenticate_Bearer_Fail(t *testing.T) {
 	secret := "secret"
 	// Create a new token object, specifying signing method and the claims
@@ -264,6 +578,8 @@ func TestServer_Authenticate_Bearer(t *testing.T) {
 	conf := NewConfig()
 	conf.HTTP.AuthEnabled = true
 	conf.HTTP.SharedSecret = secret
+	conf.Auth.BootstrapUsername = "bob"
+	conf.Auth.BootstrapPassword = "bob's secure password"
 	s := OpenServer(conf)
 	cli, err := client.New(client.Config{
 		URL: s.URL(),
@@ -285,100 +601,480 @@ func TestServer_Authenticate_Bearer(t *testing.T) {
 	}
 }
 
-func TestServer_CreateTask(t *testing.T) {
-	s, cli := OpenDefaultServer()
-	defer s.Close()
+func TestServer_Authenticate_Bearer_Claims(t *testing.T) {
+	secret := "secret"
+	newToken := func(username string) string {
+		// The privileges of the user are restricted to the claims.
+		token := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.MapClaims{
+			"username": username,
+			"exp":      time.Now().Add(10 * time.Second).Unix(),
+			"privileges": map[string][]string{
+				"/api/tasks":  {"read"},
+				"/api/topics": {"read"},
+			},
+		})
+		tokenString, err := token.SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tokenString
+	}
 
-	id := "testTaskID"
-	ttype := client.StreamTask
-	dbrps := []client.DBRP{
-		{
-			Database:        "mydb",
-			RetentionPolicy: "myrp",
-		},
-		{
-			Database:        "otherdb",
-			RetentionPolicy: "default",
+	conf := NewConfig()
+	conf.HTTP.AuthEnabled = true
+	conf.HTTP.SharedSecret = secret
+	conf.Auth.BootstrapUsername = "admin"
+	conf.Auth.BootstrapPassword = "admin secret"
+	s := OpenServer(conf)
+	defer s.Close()
+	admin, err := client.New(client.Config{
+		URL: s.URL(),
+		Credentials: &client.Credentials{
+			Method:   client.UserAuthentication,
+			Username: "admin",
+			Password: "admin secret",
 		},
-	}
-	tick := `stream
-    |from()
-        .measurement('test')
-`
-	task, err := cli.CreateTask(client.CreateTaskOptions{
-		ID:         id,
-		Type:       ttype,
-		DBRPs:      dbrps,
-		TICKscript: tick,
-		Status:     client.Disabled,
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	ti, err := cli.Task(task.Link, nil)
-	if err != nil {
+	if _, err := admin.CreateUser(client.CreateUserOptions{
+		Username: "ci",
+		Password: "ci secret",
+		Privileges: map[string][]string{
+			"/api/tasks": {"read", "write"},
+		},
+	}); err != nil {
 		t.Fatal(err)
 	}
 
-	if ti.Error != "" {
-		t.Fatal(ti.Error)
-	}
-	if ti.ID != id {
-		t.Fatalf("unexpected id got %s exp %s", ti.ID, id)
+	cli, err := client.New(client.Config{
+		URL: s.URL(),
+		Credentials: &client.Credentials{
+			Method: client.BearerAuthentication,
+			Token:  newToken("ci"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
 	}
-	if ti.Type != client.StreamTask {
-		t.Fatalf("unexpected type got %v exp %v", ti.Type, client.StreamTask)
+	if _, err := cli.ListTasks(nil); err != nil {
+		t.Fatal(err)
 	}
-	if ti.Status != client.Disabled {
-		t.Fatalf("unexpected status got %v exp %v", ti.Status, client.Disabled)
+	// The claims do not grant privileges the user does not have.
+	if _, err := cli.ListTopics(nil); err == nil {
+		t.Error("expected authorization error listing topics")
 	}
-	if !reflect.DeepEqual(ti.DBRPs, dbrps) {
-		t.Fatalf("unexpected dbrps got %s exp %s", ti.DBRPs, dbrps)
+	if _, err := cli.CreateTask(client.CreateTaskOptions{
+		ID:         "task",
+		Type:       client.StreamTask,
+		DBRPs:      []client.DBRP{{Database: "mydb", RetentionPolicy: "myrp"}},
+		TICKscript: "stream\n    |from()\n",
+	}); err == nil {
+		t.Error("expected authorization error creating task")
 	}
-	if ti.TICKscript != tick {
-		t.Fatalf("unexpected TICKscript got %s exp %s", ti.TICKscript, tick)
+
+	// Tokens of users that do not exist are rejected.
+	unknown, err := client.New(client.Config{
+		URL: s.URL(),
+		Credentials: &client.Credentials{
+			Method: client.BearerAuthentication,
+			Token:  newToken("unknown"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
 	}
-	dot := "digraph testTaskID {\nstream0 -> from1;\n}"
-	if ti.Dot != dot {
-		t.Fatalf("unexpected dot\ngot\n%s\nexp\n%s\n", ti.Dot, dot)
+	if _, err := unknown.ListTasks(nil); err == nil {
+		t.Error("expected authentication error for a token of an unknown user")
 	}
 }
 
-func TestServer_EnableTask(t *testing.T) {
-	s, cli := OpenDefaultServer()
+func TestServer_Authenticate_APIToken(t *testing.T) {
+	conf := NewConfig()
+	conf.HTTP.AuthEnabled = true
+	conf.HTTP.SharedSecret = "secret"
+	conf.Auth.BootstrapUsername = "admin"
+	conf.Auth.BootstrapPassword = "admin secret"
+	s := OpenServer(conf)
 	defer s.Close()
-
-	id := "testTaskID"
-	ttype := client.StreamTask
-	dbrps := []client.DBRP{
-		{
-			Database:        "mydb",
-			RetentionPolicy: "myrp",
-		},
-		{
-			Database:        "otherdb",
-			RetentionPolicy: "default",
+	admin, err := client.New(client.Config{
+		URL: s.URL(),
+		Credentials: &client.Credentials{
+			Method:   client.UserAuthentication,
+			Username: "admin",
+			Password: "admin secret",
 		},
-	}
-	tick := `stream
-    |from()
-        .measurement('test')
-`
-	task, err := cli.CreateTask(client.CreateTaskOptions{
-		ID:         id,
-		Type:       ttype,
-		DBRPs:      dbrps,
-		TICKscript: tick,
-		Status:     client.Disabled,
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = cli.UpdateTask(task.Link, client.UpdateTaskOptions{
-		Status: client.Enabled,
-	})
+	for _, typ := range []string{"api", "jwt"} {
+		token, err := admin.CreateToken(client.CreateTokenOptions{
+			Type:        typ,
+			Description: "ci",
+			Privileges: map[string][]string{
+				"/api/tasks": {"read", "write"},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cli, err := client.New(client.Config{
+			URL: s.URL(),
+			Credentials: &client.Credentials{
+				Method: client.BearerAuthentication,
+				Token:  token.Token,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cli.CreateTask(client.CreateTaskOptions{
+			ID:         "task_" + typ,
+			Type:       client.StreamTask,
+			DBRPs:      []client.DBRP{{Database: "mydb", RetentionPolicy: "myrp"}},
+			TICKscript: "stream\n    |from()\n",
+		}); err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		if _, err := cli.ListTopics(nil); err == nil {
+			t.Errorf("%s: expected authorization error listing topics", typ)
+		}
+
+		if err := admin.RevokeToken(token.Link); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cli.ListTasks(nil); err == nil {
+			t.Errorf("%s: expected authentication error using revoked token", typ)
+		}
+	}
+}
+
+func TestServer_Authenticate_JWT_CurrentPrivileges(t *testing.T) {
+	conf := NewConfig()
+	conf.HTTP.AuthEnabled = true
+	conf.HTTP.SharedSecret = "secret"
+	conf.Auth.BootstrapUsername = "admin"
+	conf.Auth.BootstrapPassword = "admin secret"
+	s := OpenServer(conf)
+	defer s.Close()
+	newClient := func(creds client.Credentials) *client.Client {
+		cli, err := client.New(client.Config{
+			URL:         s.URL(),
+			Credentials: &creds,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cli
+	}
+	admin := newClient(client.Credentials{
+		Method:   client.UserAuthentication,
+		Username: "admin",
+		Password: "admin secret",
+	})
+	if _, err := admin.CreateUser(client.CreateUserOptions{
+		Username: "bob",
+		Password: "bob secret",
+		Privileges: map[string][]string{
+			"/api/tasks":     {"read"},
+			"/api/templates": {"read"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	token, err := admin.CreateToken(client.CreateTokenOptions{
+		Type:     "jwt",
+		Username: "bob",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob := newClient(client.Credentials{
+		Method: client.BearerAuthentication,
+		Token:  token.Token,
+	})
+	if _, err := bob.ListTemplates(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Privileges removed from the user are removed from existing tokens.
+	if _, err := admin.UpdateUser(admin.UserLink("bob"), client.UpdateUserOptions{
+		Privileges: map[string][]string{
+			"/api/tasks": {"read"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.ListTasks(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.ListTemplates(nil); err == nil {
+		t.Error("expected authorization error listing templates after privilege was removed")
+	}
+
+	if err := admin.DeleteUser(admin.UserLink("bob")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.ListTasks(nil); err == nil {
+		t.Error("expected authentication error after user was deleted")
+	}
+}
+
+func TestServer_Audit(t *testing.T) {
+	conf := NewConfig()
+	conf.HTTP.AuthEnabled = true
+	conf.Auth.BootstrapUsername = "admin"
+	conf.Auth.BootstrapPassword = "admin secret"
+	s := OpenServer(conf)
+	defer s.Close()
+	cli, err := client.New(client.Config{
+		URL: s.URL(),
+		Credentials: &client.Credentials{
+			Method:   client.UserAuthentication,
+			Username: "admin",
+			Password: "admin secret",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := cli.CreateTask(client.CreateTaskOptions{
+		ID:         "testTaskID",
+		Type:       client.StreamTask,
+		DBRPs:      []client.DBRP{{Database: "mydb", RetentionPolicy: "myrp"}},
+		TICKscript: "stream\n    |from()\n",
+		Status:     client.Disabled,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.UpdateTask(task.Link, client.UpdateTaskOptions{Status: client.Enabled}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.UpdateTask(task.Link, client.UpdateTaskOptions{Status: client.Disabled}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.UpdateTask(task.Link, client.UpdateTaskOptions{TICKscript: "stream\n    |from()\n        .measurement('cpu')\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.DeleteTask(task.Link); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := cli.ListAuditRecords(&client.ListAuditRecordsOptions{
+		User:     "admin",
+		Resource: "tasks/*",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, r := range records {
+		if r.Resource != task.Link.Href {
+			t.Errorf("unexpected resource %s", r.Resource)
+		}
+		actions = append(actions, r.Action)
+	}
+	if exp := []string{"delete", "update", "disable", "enable", "create"}; !reflect.DeepEqual(actions, exp) {
+		t.Fatalf("unexpected actions: got %v exp %v", actions, exp)
+	}
+	var diff struct {
+		TICKscript string `json:"script"`
+	}
+	if err := json.Unmarshal(records[1].Diff, &diff); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff.TICKscript, "cpu") {
+		t.Errorf("unexpected diff %s", records[1].Diff)
+	}
+}
+
+func TestServer_Audit_Blobs(t *testing.T) {
+	conf := NewConfig()
+	conf.HTTP.AuthEnabled = true
+	conf.Auth.BootstrapUsername = "admin"
+	conf.Auth.BootstrapPassword = "admin secret"
+	s := OpenServer(conf)
+	defer s.Close()
+	cli, err := client.New(client.Config{
+		URL: s.URL(),
+		Credentials: &client.Credentials{
+			Method:   client.UserAuthentication,
+			Username: "admin",
+			Password: "admin secret",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The data of blobs is never recorded, even if it is valid JSON.
+	data := `{"weights":[1,2,3]}`
+	b, err := cli.CreateBlob(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagLink := cli.BlobTagLink("model")
+	if _, err := cli.TagBlob(tagLink, client.TagBlobOptions{Blob: b.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.DeleteBlobTag(tagLink); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.DeleteBlob(b.Link); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := cli.ListAuditRecords(&client.ListAuditRecordsOptions{
+		User: "admin",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type state struct {
+		ID     string `json:"id"`
+		Size   int64  `json:"size"`
+		Name   string `json:"name"`
+		BlobID string `json:"blob-id"`
+	}
+	type record struct {
+		Resource      string
+		Action        string
+		Before, After state
+	}
+	decode := func(raw json.RawMessage) state {
+		var st state
+		if raw == nil {
+			return st
+		}
+		if strings.Contains(string(raw), "weights") {
+			t.Errorf("blob data recorded in audit log: %s", raw)
+		}
+		if err := json.Unmarshal(raw, &st); err != nil {
+			t.Fatal(err)
+		}
+		return st
+	}
+	var got []record
+	for _, r := range records {
+		got = append(got, record{
+			Resource: r.Resource,
+			Action:   r.Action,
+			Before:   decode(r.Before),
+			After:    decode(r.After),
+		})
+	}
+	blobState := state{ID: b.ID, Size: int64(len(data))}
+	tagState := state{Name: "model", BlobID: b.ID}
+	exp := []record{
+		{Resource: b.Link.Href, Action: "delete", Before: blobState},
+		{Resource: tagLink.Href, Action: "delete", Before: tagState},
+		{Resource: tagLink.Href, Action: "create", After: tagState},
+		{Resource: b.Link.Href, Action: "create", After: blobState},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected audit records:\ngot\n%+v\nexp\n%+v", got, exp)
+	}
+}
+
+func TestServer_CreateTask(t *testing.T) {
+	s, cli := OpenDefaultServer()
+	defer s.Close()
+
+	id := "testTaskID"
+	ttype := client.StreamTask
+	dbrps := []client.DBRP{
+		{
+			Database:        "mydb",
+			RetentionPolicy: "myrp",
+		},
+		{
+			Database:        "otherdb",
+			RetentionPolicy: "default",
+		},
+	}
+	tick := `stream
+    |from()
+        .measurement('test')
+`
+	task, err := cli.CreateTask(client.CreateTaskOptions{
+		ID:         id,
+		Type:       ttype,
+		DBRPs:      dbrps,
+		TICKscript: tick,
+		Status:     client.Disabled,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ti, err := cli.Task(task.Link, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ti.Error != "" {
+		t.Fatal(ti.Error)
+	}
+	if ti.ID != id {
+		t.Fatalf("unexpected id got %s exp %s", ti.ID, id)
+	}
+	if ti.Type != client.StreamTask {
+		t.Fatalf("unexpected type got %v exp %v", ti.Type, client.StreamTask)
+	}
+	if ti.Status != client.Disabled {
+		t.Fatalf("unexpected status got %v exp %v", ti.Status, client.Disabled)
+	}
+	if !reflect.DeepEqual(ti.DBRPs, dbrps) {
+		t.Fatalf("unexpected dbrps got %s exp %s", ti.DBRPs, dbrps)
+	}
+	if ti.TICKscript != tick {
+		t.Fatalf("unexpected TICKscript got %s exp %s", ti.TICKscript, tick)
+	}
+	dot := "digraph testTaskID {\nstream0 -> from1;\n}"
+	if ti.Dot != dot {
+		t.Fatalf("unexpected dot\ngot\n%s\nexp\n%s\n", ti.Dot, dot)
+	}
+}
+
+func TestServer_EnableTask(t *testing.T) {
+	s, cli := OpenDefaultServer()
+	defer s.Close()
+
+	id := "testTaskID"
+	ttype := client.StreamTask
+	dbrps := []client.DBRP{
+		{
+			Database:        "mydb",
+			RetentionPolicy: "myrp",
+		},
+		{
+			Database:        "otherdb",
+			RetentionPolicy: "default",
+		},
+	}
+	tick := `stream
+    |from()
+        .measurement('test')
+`
+	task, err := cli.CreateTask(client.CreateTaskOptions{
+		ID:         id,
+		Type:       ttype,
+		DBRPs:      dbrps,
+		TICKscript: tick,
+		Status:     client.Disabled,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cli.UpdateTask(task.Link, client.UpdateTaskOptions{
+		Status: client.Enabled,
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -5983,33 +6679,97 @@ func TestServer_UpdateConfig(t *testing.T) {
 			},
 		},
 		{
-			section: "httppost",
-			element: "test",
+			section: "alertmanager",
 			setDefaults: func(c *server.Config) {
-				apc := httppost.Config{
-					Endpoint: "test",
-					URL:      "http://httppost.example.com",
-					Headers: map[string]string{
-						"testing": "works",
-					},
-				}
-				c.HTTPPost = httppost.Configs{apc}
+				c.Alertmanager.URL = "http://alertmanager.example.com:9093"
 			},
 			expDefaultSection: client.ConfigSection{
-				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/httppost"},
-				Elements: []client.ConfigElement{
-					{
-						Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/httppost/test"},
-						Options: map[string]interface{}{
-							"endpoint": "test",
-							"url":      "http://httppost.example.com",
-							"headers": map[string]interface{}{
-								"testing": "works",
-							},
-							"basic-auth": false,
-						},
-						Redacted: []string{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/alertmanager"},
+				Elements: []client.ConfigElement{{
+					Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/alertmanager/"},
+					Options: map[string]interface{}{
+						"enabled":              false,
+						"url":                  "http://alertmanager.example.com:9093",
+						"insecure-skip-verify": false,
+						"resend-interval":      "1m0s",
+					},
+				}},
+			},
+			expDefaultElement: client.ConfigElement{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/alertmanager/"},
+				Options: map[string]interface{}{
+					"enabled":              false,
+					"url":                  "http://alertmanager.example.com:9093",
+					"insecure-skip-verify": false,
+					"resend-interval":      "1m0s",
+				},
+			},
+			updates: []updateAction{
+				{
+					updateAction: client.ConfigUpdateAction{
+						Set: map[string]interface{}{
+							"enabled":              true,
+							"insecure-skip-verify": true,
+						},
+					},
+					expSection: client.ConfigSection{
+						Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/alertmanager"},
+						Elements: []client.ConfigElement{{
+							Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/alertmanager/"},
+							Options: map[string]interface{}{
+								"enabled":              true,
+								"url":                  "http://alertmanager.example.com:9093",
+								"insecure-skip-verify": true,
+								"resend-interval":      "1m0s",
+							},
+						}},
+					},
+					expElement: client.ConfigElement{
+						Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/alertmanager/"},
+						Options: map[string]interface{}{
+							"enabled":              true,
+							"url":                  "http://alertmanager.example.com:9093",
+							"insecure-skip-verify": true,
+							"resend-interval":      "1m0s",
+						},
+					},
+				},
+			},
+		},
+		{
+			section: "httppost",
+			element: "test",
+			setDefaults: func(c *server.Config) {
+				apc := httppost.Config{
+					Endpoint: "test",
+					URL:      "http://httppost.example.com",
+					Headers: map[string]string{
+						"testing": "works",
+					},
+				}
+				c.HTTPPost = httppost.Configs{apc}
+			},
+			expDefaultSection: client.ConfigSection{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/httppost"},
+				Elements: []client.ConfigElement{
+					{
+						Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/httppost/test"},
+						Options: map[string]interface{}{
+							"endpoint": "test",
+							"url":      "http://httppost.example.com",
+							"headers": map[string]interface{}{
+								"testing": "works",
+							},
+							"basic-auth":       false,
+							"signing-secret":   false,
+							"signature-header": "",
+							"timestamp-header": "",
+							"alert-template":   "",
+							"row-template":     "",
+						},
+						Redacted: []string{
 							"basic-auth",
+							"signing-secret",
 						}},
 				},
 			},
@@ -6021,10 +6781,16 @@ func TestServer_UpdateConfig(t *testing.T) {
 					"headers": map[string]interface{}{
 						"testing": "works",
 					},
-					"basic-auth": false,
+					"basic-auth":       false,
+					"signing-secret":   false,
+					"signature-header": "",
+					"timestamp-header": "",
+					"alert-template":   "",
+					"row-template":     "",
 				},
 				Redacted: []string{
 					"basic-auth",
+					"signing-secret",
 				},
 			},
 			updates: []updateAction{
@@ -6039,6 +6805,7 @@ func TestServer_UpdateConfig(t *testing.T) {
 								Username: "usr",
 								Password: "pass",
 							},
+							"signing-secret": "secret",
 						},
 					},
 					expSection: client.ConfigSection{
@@ -6051,10 +6818,16 @@ func TestServer_UpdateConfig(t *testing.T) {
 								"headers": map[string]interface{}{
 									"testing": "more",
 								},
-								"basic-auth": true,
+								"basic-auth":       true,
+								"signing-secret":   true,
+								"signature-header": "",
+								"timestamp-header": "",
+								"alert-template":   "",
+								"row-template":     "",
 							},
 							Redacted: []string{
 								"basic-auth",
+								"signing-secret",
 							},
 						}},
 					},
@@ -6066,10 +6839,16 @@ func TestServer_UpdateConfig(t *testing.T) {
 							"headers": map[string]interface{}{
 								"testing": "more",
 							},
-							"basic-auth": true,
+							"basic-auth":       true,
+							"signing-secret":   true,
+							"signature-header": "",
+							"timestamp-header": "",
+							"alert-template":   "",
+							"row-template":     "",
 						},
 						Redacted: []string{
 							"basic-auth",
+							"signing-secret",
 						},
 					},
 				},
@@ -6313,6 +7092,183 @@ func TestServer_UpdateConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			section: "jira",
+			setDefaults: func(c *server.Config) {
+				c.Jira.URL = "https://jira.example.com"
+				c.Jira.Project = "OPS"
+			},
+			expDefaultSection: client.ConfigSection{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/jira"},
+				Elements: []client.ConfigElement{{
+					Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/jira/"},
+					Options: map[string]interface{}{
+						"enabled":              false,
+						"url":                  "https://jira.example.com",
+						"username":             "",
+						"token":                false,
+						"project":              "OPS",
+						"issue-type":           "Task",
+						"recovery-transition":  "",
+						"insecure-skip-verify": false,
+					},
+					Redacted: []string{
+						"token",
+					},
+				}},
+			},
+			expDefaultElement: client.ConfigElement{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/jira/"},
+				Options: map[string]interface{}{
+					"enabled":              false,
+					"url":                  "https://jira.example.com",
+					"username":             "",
+					"token":                false,
+					"project":              "OPS",
+					"issue-type":           "Task",
+					"recovery-transition":  "",
+					"insecure-skip-verify": false,
+				},
+				Redacted: []string{
+					"token",
+				},
+			},
+			updates: []updateAction{
+				{
+					updateAction: client.ConfigUpdateAction{
+						Set: map[string]interface{}{
+							"enabled":             true,
+							"username":            "bob",
+							"token":               "secret",
+							"recovery-transition": "31",
+						},
+					},
+					expSection: client.ConfigSection{
+						Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/jira"},
+						Elements: []client.ConfigElement{{
+							Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/jira/"},
+							Options: map[string]interface{}{
+								"enabled":              true,
+								"url":                  "https://jira.example.com",
+								"username":             "bob",
+								"token":                true,
+								"project":              "OPS",
+								"issue-type":           "Task",
+								"recovery-transition":  "31",
+								"insecure-skip-verify": false,
+							},
+							Redacted: []string{
+								"token",
+							},
+						}},
+					},
+					expElement: client.ConfigElement{
+						Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/jira/"},
+						Options: map[string]interface{}{
+							"enabled":              true,
+							"url":                  "https://jira.example.com",
+							"username":             "bob",
+							"token":                true,
+							"project":              "OPS",
+							"issue-type":           "Task",
+							"recovery-transition":  "31",
+							"insecure-skip-verify": false,
+						},
+						Redacted: []string{
+							"token",
+						},
+					},
+				},
+			},
+		},
+		{
+			section: "kafka",
+			setDefaults: func(c *server.Config) {
+				c.Kafka = kafka.Configs{kafka.Config{
+					ID:       "default",
+					Brokers:  []string{"localhost:9092"},
+					Timeout:  toml.Duration(10 * time.Second),
+					ClientID: "kapacitor",
+				}}
+			},
+			element: "default",
+			expDefaultSection: client.ConfigSection{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/kafka"},
+				Elements: []client.ConfigElement{{
+					Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/kafka/default"},
+					Options: map[string]interface{}{
+						"enabled":              false,
+						"id":                   "default",
+						"brokers":              []interface{}{"localhost:9092"},
+						"timeout":              "10s",
+						"client-id":            "kapacitor",
+						"use-ssl":              false,
+						"ssl-ca":               "",
+						"ssl-cert":             "",
+						"ssl-key":              "",
+						"insecure-skip-verify": false,
+					},
+				}},
+			},
+			expDefaultElement: client.ConfigElement{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/kafka/default"},
+				Options: map[string]interface{}{
+					"enabled":              false,
+					"id":                   "default",
+					"brokers":              []interface{}{"localhost:9092"},
+					"timeout":              "10s",
+					"client-id":            "kapacitor",
+					"use-ssl":              false,
+					"ssl-ca":               "",
+					"ssl-cert":             "",
+					"ssl-key":              "",
+					"insecure-skip-verify": false,
+				},
+			},
+			updates: []updateAction{
+				{
+					updateAction: client.ConfigUpdateAction{
+						Set: map[string]interface{}{
+							"client-id": "kapacitor-default",
+						},
+					},
+					element: "default",
+					expSection: client.ConfigSection{
+						Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/kafka"},
+						Elements: []client.ConfigElement{{
+							Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/kafka/default"},
+							Options: map[string]interface{}{
+								"enabled":              false,
+								"id":                   "default",
+								"brokers":              []interface{}{"localhost:9092"},
+								"timeout":              "10s",
+								"client-id":            "kapacitor-default",
+								"use-ssl":              false,
+								"ssl-ca":               "",
+								"ssl-cert":             "",
+								"ssl-key":              "",
+								"insecure-skip-verify": false,
+							},
+						}},
+					},
+					expElement: client.ConfigElement{
+						Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/kafka/default"},
+						Options: map[string]interface{}{
+							"enabled":              false,
+							"id":                   "default",
+							"brokers":              []interface{}{"localhost:9092"},
+							"timeout":              "10s",
+							"client-id":            "kapacitor-default",
+							"use-ssl":              false,
+							"ssl-ca":               "",
+							"ssl-cert":             "",
+							"ssl-key":              "",
+							"insecure-skip-verify": false,
+						},
+					},
+				},
+			},
+		},
 		{
 			section: "mqtt",
 			setDefaults: func(c *server.Config) {
@@ -6905,6 +7861,95 @@ func TestServer_UpdateConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			section: "syslog",
+			expDefaultSection: client.ConfigSection{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/syslog"},
+				Elements: []client.ConfigElement{{
+					Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/syslog/"},
+					Options: map[string]interface{}{
+						"enabled":              false,
+						"network":              "udp",
+						"addr":                 "localhost:514",
+						"facility":             "user",
+						"app-name":             "kapacitor",
+						"hostname":             "",
+						"sd-id":                "kapacitor@32473",
+						"timeout":              "10s",
+						"ssl-ca":               "",
+						"ssl-cert":             "",
+						"ssl-key":              "",
+						"insecure-skip-verify": false,
+					},
+				}},
+			},
+			expDefaultElement: client.ConfigElement{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/syslog/"},
+				Options: map[string]interface{}{
+					"enabled":              false,
+					"network":              "udp",
+					"addr":                 "localhost:514",
+					"facility":             "user",
+					"app-name":             "kapacitor",
+					"hostname":             "",
+					"sd-id":                "kapacitor@32473",
+					"timeout":              "10s",
+					"ssl-ca":               "",
+					"ssl-cert":             "",
+					"ssl-key":              "",
+					"insecure-skip-verify": false,
+				},
+			},
+			updates: []updateAction{
+				{
+					updateAction: client.ConfigUpdateAction{
+						Set: map[string]interface{}{
+							"enabled":  true,
+							"network":  "tcp",
+							"addr":     "syslog.example.com:514",
+							"facility": "local0",
+						},
+					},
+					expSection: client.ConfigSection{
+						Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/syslog"},
+						Elements: []client.ConfigElement{{
+							Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/syslog/"},
+							Options: map[string]interface{}{
+								"enabled":              true,
+								"network":              "tcp",
+								"addr":                 "syslog.example.com:514",
+								"facility":             "local0",
+								"app-name":             "kapacitor",
+								"hostname":             "",
+								"sd-id":                "kapacitor@32473",
+								"timeout":              "10s",
+								"ssl-ca":               "",
+								"ssl-cert":             "",
+								"ssl-key":              "",
+								"insecure-skip-verify": false,
+							},
+						}},
+					},
+					expElement: client.ConfigElement{
+						Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/syslog/"},
+						Options: map[string]interface{}{
+							"enabled":              true,
+							"network":              "tcp",
+							"addr":                 "syslog.example.com:514",
+							"facility":             "local0",
+							"app-name":             "kapacitor",
+							"hostname":             "",
+							"sd-id":                "kapacitor@32473",
+							"timeout":              "10s",
+							"ssl-ca":               "",
+							"ssl-cert":             "",
+							"ssl-key":              "",
+							"insecure-skip-verify": false,
+						},
+					},
+				},
+			},
+		},
 		{
 			section: "talk",
 			setDefaults: func(c *server.Config) {
@@ -6972,42 +8017,110 @@ func TestServer_UpdateConfig(t *testing.T) {
 			},
 		},
 		{
-			section: "telegram",
-			setDefaults: func(c *server.Config) {
-				c.Telegram.ChatId = "kapacitor"
-			},
+			section: "teams",
 			expDefaultSection: client.ConfigSection{
-				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/telegram"},
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/teams"},
 				Elements: []client.ConfigElement{{
-					Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/telegram/"},
+					Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/teams/"},
 					Options: map[string]interface{}{
-						"chat-id":                  "kapacitor",
-						"disable-notification":     false,
-						"disable-web-page-preview": false,
-						"enabled":                  false,
-						"global":                   false,
-						"parse-mode":               "",
-						"state-changes-only":       false,
-						"token":                    false,
-						"url":                      telegram.DefaultTelegramURL,
+						"enabled":            false,
+						"channel-url":        false,
+						"global":             false,
+						"state-changes-only": false,
 					},
 					Redacted: []string{
-						"token",
+						"channel-url",
 					},
 				}},
 			},
 			expDefaultElement: client.ConfigElement{
-				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/telegram/"},
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/teams/"},
 				Options: map[string]interface{}{
-					"chat-id":                  "kapacitor",
-					"disable-notification":     false,
-					"disable-web-page-preview": false,
-					"enabled":                  false,
-					"global":                   false,
-					"parse-mode":               "",
-					"state-changes-only":       false,
-					"token":                    false,
-					"url":                      telegram.DefaultTelegramURL,
+					"enabled":            false,
+					"channel-url":        false,
+					"global":             false,
+					"state-changes-only": false,
+				},
+				Redacted: []string{
+					"channel-url",
+				},
+			},
+			updates: []updateAction{
+				{
+					updateAction: client.ConfigUpdateAction{
+						Set: map[string]interface{}{
+							"enabled":     true,
+							"channel-url": "https://outlook.office.com/webhook/secret-token",
+							"global":      true,
+						},
+					},
+					expSection: client.ConfigSection{
+						Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/teams"},
+						Elements: []client.ConfigElement{{
+							Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/teams/"},
+							Options: map[string]interface{}{
+								"enabled":            true,
+								"channel-url":        true,
+								"global":             true,
+								"state-changes-only": false,
+							},
+							Redacted: []string{
+								"channel-url",
+							},
+						}},
+					},
+					expElement: client.ConfigElement{
+						Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/teams/"},
+						Options: map[string]interface{}{
+							"enabled":            true,
+							"channel-url":        true,
+							"global":             true,
+							"state-changes-only": false,
+						},
+						Redacted: []string{
+							"channel-url",
+						},
+					},
+				},
+			},
+		},
+		{
+			section: "telegram",
+			setDefaults: func(c *server.Config) {
+				c.Telegram.ChatId = "kapacitor"
+			},
+			expDefaultSection: client.ConfigSection{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/telegram"},
+				Elements: []client.ConfigElement{{
+					Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/telegram/"},
+					Options: map[string]interface{}{
+						"chat-id":                  "kapacitor",
+						"disable-notification":     false,
+						"disable-web-page-preview": false,
+						"enabled":                  false,
+						"global":                   false,
+						"parse-mode":               "",
+						"state-changes-only":       false,
+						"token":                    false,
+						"url":                      telegram.DefaultTelegramURL,
+					},
+					Redacted: []string{
+						"token",
+					},
+				}},
+			},
+			expDefaultElement: client.ConfigElement{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/config/telegram/"},
+				Options: map[string]interface{}{
+					"chat-id":                  "kapacitor",
+					"disable-notification":     false,
+					"disable-web-page-preview": false,
+					"enabled":                  false,
+					"global":                   false,
+					"parse-mode":               "",
+					"state-changes-only":       false,
+					"token":                    false,
+					"url":                      telegram.DefaultTelegramURL,
 				},
 				Redacted: []string{
 					"token",
@@ -7278,6 +8391,16 @@ func TestServer_ListServiceTests(t *testing.T) {
 					},
 				},
 			},
+			{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/service-tests/alertmanager"},
+				Name: "alertmanager",
+				Options: client.ServiceTestOptions{
+					"alert-id": "testAlertID",
+					"message":  "test alertmanager message",
+					"level":    "CRITICAL",
+					"labels":   map[string]interface{}{},
+				},
+			},
 			{
 				Link: client.Link{Relation: "self", Href: "/kapacitor/v1/service-tests/azure"},
 				Name: "azure",
@@ -7344,6 +8467,27 @@ func TestServer_ListServiceTests(t *testing.T) {
 					"cluster": "",
 				},
 			},
+			{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/service-tests/jira"},
+				Name: "jira",
+				Options: client.ServiceTestOptions{
+					"project":     "",
+					"issue-type":  "Task",
+					"summary":     "test kapacitor issue",
+					"description": "test kapacitor issue description",
+					"labels":      []interface{}{},
+				},
+			},
+			{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/service-tests/kafka"},
+				Name: "kafka",
+				Options: client.ServiceTestOptions{
+					"cluster": "",
+					"topic":   "kapacitor",
+					"key":     "",
+					"message": "test kafka message",
+				},
+			},
 			{
 				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/service-tests/kubernetes"},
 				Name: "kubernetes",
@@ -7476,6 +8620,18 @@ func TestServer_ListServiceTests(t *testing.T) {
 					"id": "",
 				},
 			},
+			{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/service-tests/syslog"},
+				Name: "syslog",
+				Options: client.ServiceTestOptions{
+					"facility": "user",
+					"app-name": "kapacitor",
+					"msg-id":   "",
+					"message":  "test syslog message",
+					"level":    "CRITICAL",
+					"tags":     map[string]interface{}{},
+				},
+			},
 			{
 				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/service-tests/talk"},
 				Name: "talk",
@@ -7484,6 +8640,16 @@ func TestServer_ListServiceTests(t *testing.T) {
 					"text":  "test talk text",
 				},
 			},
+			{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/service-tests/teams"},
+				Name: "teams",
+				Options: client.ServiceTestOptions{
+					"channel-url": "",
+					"alert-id":    "testAlertID",
+					"message":     "test teams message",
+					"level":       "CRITICAL",
+				},
+			},
 			{
 				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/service-tests/telegram"},
 				Name: "telegram",
@@ -7607,6 +8773,18 @@ func TestServer_ListServiceTests_WithPattern(t *testing.T) {
 					"id": "",
 				},
 			},
+			{
+				Link: client.Link{Relation: client.Self, Href: "/kapacitor/v1/service-tests/syslog"},
+				Name: "syslog",
+				Options: client.ServiceTestOptions{
+					"facility": "user",
+					"app-name": "kapacitor",
+					"msg-id":   "",
+					"message":  "test syslog message",
+					"level":    "CRITICAL",
+					"tags":     map[string]interface{}{},
+				},
+			},
 		},
 	}
 	if got, exp := serviceTests.Link.Href, expServiceTests.Link.Href; got != exp {
@@ -7643,6 +8821,14 @@ func TestServer_DoServiceTest(t *testing.T) {
 				Message: "service is not enabled",
 			},
 		},
+		{
+			service: "alertmanager",
+			options: client.ServiceTestOptions{},
+			exp: client.ServiceTestResult{
+				Success: false,
+				Message: "service is not enabled",
+			},
+		},
 		{
 			service: "hipchat",
 			options: client.ServiceTestOptions{},
@@ -7676,6 +8862,24 @@ func TestServer_DoServiceTest(t *testing.T) {
 				Message: "cluster \"default\" is not enabled or does not exist",
 			},
 		},
+		{
+			service: "jira",
+			options: client.ServiceTestOptions{},
+			exp: client.ServiceTestResult{
+				Success: false,
+				Message: "service is not enabled",
+			},
+		},
+		{
+			service: "kafka",
+			options: client.ServiceTestOptions{
+				"cluster": "default",
+			},
+			exp: client.ServiceTestResult{
+				Success: false,
+				Message: "unknown kafka cluster \"default\"",
+			},
+		},
 		{
 			service: "kubernetes",
 			options: client.ServiceTestOptions{
@@ -7753,6 +8957,14 @@ func TestServer_DoServiceTest(t *testing.T) {
 				Message: "service is not enabled",
 			},
 		},
+		{
+			service: "syslog",
+			options: client.ServiceTestOptions{},
+			exp: client.ServiceTestResult{
+				Success: false,
+				Message: "service is not enabled",
+			},
+		},
 		{
 			service: "talk",
 			options: client.ServiceTestOptions{},
@@ -7761,6 +8973,14 @@ func TestServer_DoServiceTest(t *testing.T) {
 				Message: "service is not enabled",
 			},
 		},
+		{
+			service: "teams",
+			options: client.ServiceTestOptions{},
+			exp: client.ServiceTestResult{
+				Success: false,
+				Message: "service is not enabled",
+			},
+		},
 		{
 			service: "telegram",
 			options: client.ServiceTestOptions{},
@@ -8000,6 +9220,47 @@ func TestServer_AlertHandlers(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			handler: client.TopicHandler{
+				Kind: "alertmanager",
+				Options: map[string]interface{}{
+					"labels": map[string]string{
+						"team": "ops",
+					},
+				},
+			},
+			setup: func(c *server.Config, ha *client.TopicHandler) (context.Context, error) {
+				ts := alertmanagertest.NewServer()
+				ctxt := context.WithValue(nil, "server", ts)
+
+				c.Alertmanager.Enabled = true
+				c.Alertmanager.URL = ts.URL
+				return ctxt, nil
+			},
+			result: func(ctxt context.Context) error {
+				ts := ctxt.Value("server").(*alertmanagertest.Server)
+				ts.Close()
+				got := ts.RequestsEndingAfter(time.Now())
+				exp := []alertmanagertest.Request{{
+					URL: "/api/v1/alerts",
+					Alerts: []alertmanagertest.Alert{{
+						Labels: map[string]string{
+							"alertname": "id",
+							"team":      "ops",
+						},
+						Annotations: map[string]string{
+							"message": "message",
+							"details": "details",
+						},
+						StartsAt: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+					}},
+				}}
+				if !reflect.DeepEqual(exp, got) {
+					return fmt.Errorf("unexpected alertmanager request:\nexp\n%+v\ngot\n%+v\n", exp, got)
+				}
+				return nil
+			},
+		},
 		{
 			handler: client.TopicHandler{
 				Kind: "exec",
@@ -8116,6 +9377,84 @@ func TestServer_AlertHandlers(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			handler: client.TopicHandler{
+				Kind: "jira",
+				Options: map[string]interface{}{
+					"summary": "{{ .ID }} is {{ .Level }}",
+					"labels":  []string{"kapacitor"},
+				},
+			},
+			setup: func(c *server.Config, ha *client.TopicHandler) (context.Context, error) {
+				ts := jiratest.NewServer()
+				ctxt := context.WithValue(nil, "server", ts)
+
+				c.Jira.Enabled = true
+				c.Jira.URL = ts.URL
+				c.Jira.Project = "OPS"
+				return ctxt, nil
+			},
+			result: func(ctxt context.Context) error {
+				ts := ctxt.Value("server").(*jiratest.Server)
+				ts.Close()
+				got := ts.Requests()
+				exp := []jiratest.Request{{
+					Path: "/rest/api/2/issue",
+					Issue: &jiratest.Issue{
+						Project:     "OPS",
+						IssueType:   "Task",
+						Summary:     "id is CRITICAL",
+						Description: "message\n\nID: id\nLevel: CRITICAL\nTime: 1970-01-01 00:00:00 +0000 UTC",
+						Labels:      []string{"kapacitor"},
+					},
+				}}
+				if !reflect.DeepEqual(exp, got) {
+					return fmt.Errorf("unexpected jira request:\nexp\n%+v\ngot\n%+v\n", exp, got)
+				}
+				return nil
+			},
+		},
+		{
+			handler: client.TopicHandler{
+				Kind: "kafka",
+				Options: map[string]interface{}{
+					"topic": "testTopic",
+				},
+			},
+			setup: func(c *server.Config, ha *client.TopicHandler) (context.Context, error) {
+				ts, err := kafkatest.NewServer()
+				if err != nil {
+					return nil, err
+				}
+				ctxt := context.WithValue(nil, "server", ts)
+
+				kc := kafka.NewConfig()
+				kc.Enabled = true
+				kc.ID = "default"
+				kc.Brokers = []string{ts.Addr}
+				c.Kafka = kafka.Configs{kc}
+				return ctxt, nil
+			},
+			result: func(ctxt context.Context) error {
+				ts := ctxt.Value("server").(*kafkatest.Server)
+				ts.Close()
+				got, err := ts.Messages()
+				if err != nil {
+					return err
+				}
+				exp := []kafkatest.Message{{
+					Topic:     "testTopic",
+					Partition: 0,
+					Offset:    0,
+					Key:       "id",
+					Value:     string(adJSON),
+				}}
+				if !reflect.DeepEqual(exp, got) {
+					return fmt.Errorf("unexpected kafka messages:\nexp\n%+v\ngot\n%+v\n", exp, got)
+				}
+				return nil
+			},
+		},
 		{
 			handler: client.TopicHandler{
 				Kind: "mqtt",
@@ -8496,6 +9835,50 @@ func TestServer_AlertHandlers(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			handler: client.TopicHandler{
+				Kind: "syslog",
+				Options: map[string]interface{}{
+					"facility": "local0",
+					"msg-id":   "test",
+				},
+			},
+			setup: func(c *server.Config, ha *client.TopicHandler) (context.Context, error) {
+				ts, err := syslogtest.NewServer("tcp")
+				if err != nil {
+					return nil, err
+				}
+				ctxt := context.WithValue(nil, "server", ts)
+
+				c.Syslog.Enabled = true
+				c.Syslog.Network = "tcp"
+				c.Syslog.Addr = ts.Addr
+				c.Syslog.Hostname = "kapacitor.example.com"
+				return ctxt, nil
+			},
+			result: func(ctxt context.Context) error {
+				ts := ctxt.Value("server").(*syslogtest.Server)
+				ts.Close()
+				got, err := ts.Messages()
+				if err != nil {
+					return err
+				}
+				exp := []syslogtest.Message{{
+					Priority:       130,
+					Timestamp:      "1970-01-01T00:00:00Z",
+					Hostname:       "kapacitor.example.com",
+					AppName:        "kapacitor",
+					ProcID:         strconv.Itoa(os.Getpid()),
+					MsgID:          "test",
+					StructuredData: "-",
+					Text:           "message",
+				}}
+				if !reflect.DeepEqual(exp, got) {
+					return fmt.Errorf("unexpected syslog messages:\nexp\n%+v\ngot\n%+v\n", exp, got)
+				}
+				return nil
+			},
+		},
 		{
 			handler: client.TopicHandler{
 				Kind: "talk",
@@ -8527,6 +9910,39 @@ func TestServer_AlertHandlers(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			handler: client.TopicHandler{
+				Kind: "teams",
+			},
+			setup: func(c *server.Config, ha *client.TopicHandler) (context.Context, error) {
+				ts := teamstest.NewServer()
+				ctxt := context.WithValue(nil, "server", ts)
+
+				c.Teams.Enabled = true
+				c.Teams.ChannelURL = ts.URL + "/test/teams/url"
+				return ctxt, nil
+			},
+			result: func(ctxt context.Context) error {
+				ts := ctxt.Value("server").(*teamstest.Server)
+				ts.Close()
+				got := ts.Requests()
+				exp := []teamstest.Request{{
+					URL: "/test/teams/url",
+					Card: teamstest.Card{
+						Type:       "MessageCard",
+						Context:    "http://schema.org/extensions",
+						ThemeColor: "CC4A31",
+						Summary:    "message",
+						Title:      "CRITICAL: id",
+						Text:       "message",
+					},
+				}}
+				if !reflect.DeepEqual(exp, got) {
+					return fmt.Errorf("unexpected teams request:\nexp\n%+v\ngot\n%+v\n", exp, got)
+				}
+				return nil
+			},
+		},
 		{
 			handler: client.TopicHandler{
 				Kind: "tcp",
@@ -8955,46 +10371,1331 @@ alert value=2 0000000000002
 		}},
 	}
 
-	te, err := cli.ListTopicEvents(l, nil)
-	if err != nil {
+	te, err := cli.ListTopicEvents(l, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(te, expTopicEvents) {
+		t.Errorf("unexpected topic events for aggregate topic:\ngot\n%+v\nexp\n%+v\n", te, expTopicEvents)
+	}
+}
+
+func TestServer_Alert_Publish(t *testing.T) {
+	// Setup test TCP server
+	ts, err := alerttest.NewTCPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+
+	// Create default config
+	c := NewConfig()
+	s := OpenServer(c)
+	cli := Client(s)
+	defer s.Close()
+
+	publishTopic := "publish"
+
+	// Create task for alert
+	tick := `
+stream
+	|from()
+		.measurement('alert')
+	|alert()
+		.id('id')
+		.message('message')
+		.details('details')
+		.crit(lambda: "value" > 1.0)
+		.topic('` + publishTopic + `')
+`
+
+	if _, err := cli.CreateTask(client.CreateTaskOptions{
+		ID:   "publish_task",
+		Type: client.StreamTask,
+		DBRPs: []client.DBRP{{
+			Database:        "mydb",
+			RetentionPolicy: "myrp",
+		}},
+		TICKscript: tick,
+		Status:     client.Enabled,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create tpc handler on tcp topic
+	tcpTopic := "tcp"
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(tcpTopic), client.TopicHandlerOptions{
+		ID:   "tcp_handler",
+		Kind: "tcp",
+		Options: map[string]interface{}{
+			"address": ts.Addr,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create publish handler on publish topic
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(publishTopic), client.TopicHandlerOptions{
+		ID:   "publish_handler",
+		Kind: "publish",
+		Options: map[string]interface{}{
+			// Publish to tcpTopic
+			"topics": []string{tcpTopic},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write points
+	point := `alert value=2 0000000000`
+	v := url.Values{}
+	v.Add("precision", "s")
+	s.MustWrite("mydb", "myrp", point, v)
+
+	s.Restart()
+
+	// Check TCP handler got event
+	alertData := alert.Data{
+		ID:      "id",
+		Message: "message",
+		Details: "details",
+		Time:    time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		Level:   alert.Critical,
+		Data: models.Result{
+			Series: models.Rows{
+				{
+					Name:    "alert",
+					Columns: []string{"time", "value"},
+					Values: [][]interface{}{[]interface{}{
+						time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+						2.0,
+					}},
+				},
+			},
+		},
+	}
+	ts.Close()
+	exp := []alert.Data{alertData}
+	got := ts.Data()
+	if !reflect.DeepEqual(exp, got) {
+		t.Errorf("unexpected tcp request:\nexp\n%+v\ngot\n%+v\n", exp, got)
+	}
+
+	// Check event on topic
+	l := cli.TopicEventsLink(tcpTopic)
+	expTopicEvents := client.TopicEvents{
+		Link:  l,
+		Topic: tcpTopic,
+		Events: []client.TopicEvent{{
+			Link: client.Link{Relation: client.Self, Href: fmt.Sprintf("/kapacitor/v1preview/alerts/topics/%s/events/id", tcpTopic)},
+			ID:   "id",
+			State: client.EventState{
+				Message:  "message",
+				Details:  "details",
+				Time:     time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+				Duration: 0,
+				Level:    "CRITICAL",
+			},
+		}},
+	}
+
+	te, err := cli.ListTopicEvents(l, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(te, expTopicEvents) {
+		t.Errorf("unexpected topic events for publish topic:\ngot\n%+v\nexp\n%+v\n", te, expTopicEvents)
+	}
+}
+
+func TestServer_Alert_Match(t *testing.T) {
+	// Setup test TCP server
+	ts, err := alerttest.NewTCPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+
+	// Create default config
+	c := NewConfig()
+	s := OpenServer(c)
+	cli := Client(s)
+	defer s.Close()
+
+	topic := "test"
+
+	// Create task for alert
+	tick := `
+stream
+	|from()
+		.measurement('alert')
+	|alert()
+		.id('id')
+		.message('message')
+		.details('details')
+		.crit(lambda: "value" > 1.0)
+		.topic('` + topic + `')
+`
+
+	if _, err := cli.CreateTask(client.CreateTaskOptions{
+		ID:   "alert_task",
+		Type: client.StreamTask,
+		DBRPs: []client.DBRP{{
+			Database:        "mydb",
+			RetentionPolicy: "myrp",
+		}},
+		TICKscript: tick,
+		Status:     client.Enabled,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create tpc handler with match condition
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:   "tcp_handler",
+		Kind: "tcp",
+		Options: map[string]interface{}{
+			"address": ts.Addr,
+		},
+		Match: `"host" == 'serverA' AND level() == CRITICAL`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write points
+	point := `alert,host=serverA value=0 0000000000
+alert,host=serverB value=2 0000000001
+alert,host=serverB value=0 0000000002
+alert,host=serverA value=2 0000000003
+alert,host=serverB value=0 0000000004
+`
+	v := url.Values{}
+	v.Add("precision", "s")
+	s.MustWrite("mydb", "myrp", point, v)
+
+	s.Restart()
+
+	alertData := alert.Data{
+		ID:      "id",
+		Message: "message",
+		Details: "details",
+		Time:    time.Date(1970, 1, 1, 0, 0, 3, 0, time.UTC),
+		Level:   alert.Critical,
+		Data: models.Result{
+			Series: models.Rows{
+				{
+					Name:    "alert",
+					Tags:    map[string]string{"host": "serverA"},
+					Columns: []string{"time", "value"},
+					Values: [][]interface{}{[]interface{}{
+						time.Date(1970, 1, 1, 0, 0, 3, 0, time.UTC),
+						2.0,
+					}},
+				},
+			},
+		},
+	}
+	ts.Close()
+	exp := []alert.Data{alertData}
+	got := ts.Data()
+	if !reflect.DeepEqual(exp, got) {
+		t.Errorf("unexpected tcp request:\nexp\n%+v\ngot\n%+v\n", exp, got)
+	}
+
+	// Topic should have must recent event
+	l := cli.TopicEventsLink(topic)
+	expTopicEvents := client.TopicEvents{
+		Link:  l,
+		Topic: topic,
+		Events: []client.TopicEvent{{
+			Link: client.Link{Relation: client.Self, Href: fmt.Sprintf("/kapacitor/v1preview/alerts/topics/%s/events/id", topic)},
+			ID:   "id",
+			State: client.EventState{
+				Message:  "message",
+				Details:  "details",
+				Time:     time.Date(1970, 1, 1, 0, 0, 4, 0, time.UTC),
+				Duration: client.Duration(time.Second),
+				Level:    "OK",
+			},
+		}},
+	}
+
+	te, err := cli.ListTopicEvents(l, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(te, expTopicEvents) {
+		t.Errorf("unexpected topic events for publish topic:\ngot\n%+v\nexp\n%+v\n", te, expTopicEvents)
+	}
+}
+
+func TestServer_Alert_Silences(t *testing.T) {
+	// Setup test TCP server
+	ts, err := alerttest.NewTCPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+
+	// Create default config
+	c := NewConfig()
+	s := OpenServer(c)
+	cli := Client(s)
+	defer s.Close()
+
+	topic := "test"
+
+	// Create task for alert
+	tick := `
+stream
+	|from()
+		.measurement('alert')
+		.groupBy('host')
+	|alert()
+		.id('{{ index .Tags "host" }}')
+		.message('message')
+		.details('details')
+		.crit(lambda: "value" > 1.0)
+		.topic('` + topic + `')
+`
+
+	if _, err := cli.CreateTask(client.CreateTaskOptions{
+		ID:   "alert_task",
+		Type: client.StreamTask,
+		DBRPs: []client.DBRP{{
+			Database:        "mydb",
+			RetentionPolicy: "myrp",
+		}},
+		TICKscript: tick,
+		Status:     client.Enabled,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:   "tcp_handler",
+		Kind: "tcp",
+		Options: map[string]interface{}{
+			"address": ts.Addr,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Silence serverA while it is under maintenance
+	silence, err := cli.CreateSilence(client.SilenceOptions{
+		ID:      "maintenance",
+		Topic:   "te*",
+		Tags:    map[string]string{"host": "serverA"},
+		End:     time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+		Comment: "upgrade",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !silence.Active || silence.Link != cli.SilenceLink("maintenance") {
+		t.Fatalf("unexpected silence %+v", silence)
+	}
+	if _, err := cli.CreateSilence(client.SilenceOptions{ID: "invalid"}); err == nil {
+		t.Error("expected error creating silence without matchers")
+	}
+	// A silence that is only active for an hour at noon on the first of January.
+	scheduled, err := cli.CreateSilence(client.SilenceOptions{
+		ID:       "scheduled",
+		EventID:  "serverB",
+		Schedule: "0 12 1 1 *",
+		Duration: client.Duration(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if now := time.Now().UTC(); scheduled.Active != (now.YearDay() == 1 && now.Hour() == 12) {
+		t.Errorf("unexpected active state of scheduled silence %+v", scheduled)
+	}
+
+	// Write points
+	point := `alert,host=serverA value=2 0000000000
+alert,host=serverB value=2 0000000001
+`
+	v := url.Values{}
+	v.Add("precision", "s")
+	s.MustWrite("mydb", "myrp", point, v)
+
+	s.Restart()
+
+	ts.Close()
+	got := ts.Data()
+	if len(got) != 1 || got[0].ID != "serverB" {
+		t.Errorf("unexpected tcp requests, expected only the serverB event: %+v", got)
+	}
+
+	// The state of silenced events is still updated
+	te, err := cli.ListTopicEvents(cli.TopicEventsLink(topic), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(te.Events) != 2 {
+		t.Errorf("unexpected topic events %+v", te)
+	}
+	for _, e := range te.Events {
+		if e.State.Level != "CRITICAL" {
+			t.Errorf("unexpected level of event %s: %s", e.ID, e.State.Level)
+		}
+	}
+
+	// Silences are persisted
+	silences, err := cli.ListSilences(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(silences.Silences) != 2 || !reflect.DeepEqual(silences.Silences[0], silence) {
+		t.Errorf("unexpected silences:\ngot\n%+v\nexp\n%+v", silences.Silences, silence)
+	}
+
+	if err := cli.DeleteSilence(silence.Link); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.Silence(silence.Link); err == nil {
+		t.Error("expected error getting deleted silence")
+	}
+}
+
+func TestServer_Alert_Ack(t *testing.T) {
+	// Setup test TCP server
+	ts, err := alerttest.NewTCPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	slack := slacktest.NewServer()
+	defer slack.Close()
+
+	// Create default config
+	c := NewConfig()
+	c.Slack.Enabled = true
+	c.Slack.URL = slack.URL + "/test/slack/url"
+	s := OpenServer(c)
+	cli := Client(s)
+	defer s.Close()
+
+	topic := "test"
+
+	// Create task for alert
+	tick := `
+stream
+	|from()
+		.measurement('alert')
+	|alert()
+		.id('id')
+		.message('message')
+		.details('details')
+		.crit(lambda: "value" > 1.0)
+		.topic('` + topic + `')
+`
+
+	if _, err := cli.CreateTask(client.CreateTaskOptions{
+		ID:   "alert_task",
+		Type: client.StreamTask,
+		DBRPs: []client.DBRP{{
+			Database:        "mydb",
+			RetentionPolicy: "myrp",
+		}},
+		TICKscript: tick,
+		Status:     client.Enabled,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:   "tcp_handler",
+		Kind: "tcp",
+		Options: map[string]interface{}{
+			"address": ts.Addr,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:   "slack_handler",
+		Kind: "slack",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	v := url.Values{}
+	v.Add("precision", "s")
+	s.MustWrite("mydb", "myrp", `alert value=2 0000000000`, v)
+
+	eventLink := cli.TopicEventLink(topic, "id")
+	if _, err := cli.AckTopicEvent(cli.TopicEventLink(topic, "missing"), client.EventAckOptions{}); err == nil {
+		t.Error("expected error acknowledging unknown event")
+	}
+	ack, err := cli.AckTopicEvent(eventLink, client.EventAckOptions{Comment: "looking into it"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ack.By != "ADMIN_USER" || ack.Comment != "looking into it" || ack.Expires != nil {
+		t.Errorf("unexpected ack %+v", ack)
+	}
+
+	// Acknowledgements are persisted
+	s.Restart()
+
+	event, err := cli.TopicEvent(eventLink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.State.Ack == nil || event.State.Ack.Comment != "looking into it" {
+		t.Fatalf("expected event to be acknowledged after restart, got %+v", event.State)
+	}
+
+	// The acknowledgement is kept while the level is unchanged
+	s.MustWrite("mydb", "myrp", `alert value=3 0000000001`, v)
+	event, err = cli.TopicEvent(eventLink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.State.Ack == nil {
+		t.Fatalf("expected event to still be acknowledged, got %+v", event.State)
+	}
+
+	// The acknowledgement is removed once the level changes
+	s.MustWrite("mydb", "myrp", `alert value=0 0000000002`, v)
+	event, err = cli.TopicEvent(eventLink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.State.Ack != nil {
+		t.Fatalf("expected event to no longer be acknowledged, got %+v", event.State)
+	}
+
+	if _, err := cli.AckTopicEvent(eventLink, client.EventAckOptions{Timeout: client.Duration(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.UnackTopicEvent(eventLink); err != nil {
+		t.Fatal(err)
+	}
+	event, err = cli.TopicEvent(eventLink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.State.Ack != nil {
+		t.Errorf("expected event to be unacknowledged, got %+v", event.State)
+	}
+
+	// Handlers that do not notify acknowledged events skip the second critical event.
+	ts.Close()
+	if got := ts.Data(); len(got) != 3 {
+		t.Errorf("unexpected number of tcp requests, exp 3 got %d", len(got))
+	}
+	slack.Close()
+	got := slack.Requests()
+	if len(got) != 2 {
+		t.Fatalf("unexpected number of slack requests, exp 2 got %d", len(got))
+	}
+	if color := got[1].PostData.Attachments[0].Color; color != "good" {
+		t.Errorf("expected recovery slack request, got color %q", color)
+	}
+}
+
+func TestServer_Alert_Inhibitions(t *testing.T) {
+	// Setup test TCP server
+	ts, err := alerttest.NewTCPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+
+	// Create default config
+	c := NewConfig()
+	s := OpenServer(c)
+	cli := Client(s)
+	defer s.Close()
+
+	// Create tasks for the uplink and cpu alerts
+	for _, measurement := range []string{"uplink", "cpu"} {
+		tick := `
+stream
+	|from()
+		.measurement('` + measurement + `')
+		.groupBy(*)
+	|alert()
+		.id('{{ .Name }}:{{ index .Tags "host" }}')
+		.message('message')
+		.crit(lambda: "value" > 1.0)
+		.topic('` + measurement + `')
+`
+		if _, err := cli.CreateTask(client.CreateTaskOptions{
+			ID:   measurement + "_task",
+			Type: client.StreamTask,
+			DBRPs: []client.DBRP{{
+				Database:        "mydb",
+				RetentionPolicy: "myrp",
+			}},
+			TICKscript: tick,
+			Status:     client.Enabled,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink("cpu"), client.TopicHandlerOptions{
+		ID:   "tcp_handler",
+		Kind: "tcp",
+		Options: map[string]interface{}{
+			"address": ts.Addr,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	inhibition, err := cli.CreateInhibition(client.InhibitionOptions{
+		ID:           "uplink",
+		SourceTopic:  "uplink",
+		TargetTopics: []string{"c*"},
+		Equal:        []string{"dc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inhibition.Level != "CRITICAL" || inhibition.Link != cli.InhibitionLink("uplink") {
+		t.Errorf("unexpected inhibition %+v", inhibition)
+	}
+	if _, err := cli.CreateInhibition(client.InhibitionOptions{ID: "invalid", SourceTopic: "uplink"}); err == nil {
+		t.Error("expected error creating inhibition without target topics")
+	}
+
+	// Inhibitions are persisted
+	s.Restart()
+
+	// The tasks process points independently, wait for the events of a topic to be collected.
+	waitForEvent := func(topic, event, level string) {
+		retry := 0
+		for {
+			e, err := cli.TopicEvent(cli.TopicEventLink(topic, event))
+			if err == nil && e.State.Level == level {
+				return
+			}
+			retry++
+			if retry > 10 {
+				t.Fatalf("event %s of topic %s did not reach level %s", event, topic, level)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	v := url.Values{}
+	v.Add("precision", "s")
+	s.MustWrite("mydb", "myrp", `uplink,dc=east value=2 0000000000`, v)
+	waitForEvent("uplink", "uplink:", "CRITICAL")
+	s.MustWrite("mydb", "myrp", `cpu,dc=east,host=serverA value=2 0000000001
+cpu,dc=west,host=serverB value=2 0000000002
+`, v)
+	waitForEvent("cpu", "cpu:serverB", "CRITICAL")
+
+	te, err := cli.ListTopicEvents(cli.TopicEventsLink("cpu"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inhibited := make(map[string]bool)
+	for _, e := range te.Events {
+		inhibited[e.ID] = e.State.Inhibited
+	}
+	if exp := map[string]bool{"cpu:serverA": true, "cpu:serverB": false}; !reflect.DeepEqual(inhibited, exp) {
+		t.Errorf("unexpected inhibited events:\ngot\n%v\nexp\n%v", inhibited, exp)
+	}
+	topic, err := cli.Topic(cli.TopicLink("cpu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.Inhibited != 1 {
+		t.Errorf("unexpected inhibited count %d", topic.Inhibited)
+	}
+
+	// Once the uplink recovers the cpu alerts are handled again
+	s.MustWrite("mydb", "myrp", `uplink,dc=east value=0 0000000003`, v)
+	waitForEvent("uplink", "uplink:", "OK")
+	s.MustWrite("mydb", "myrp", `cpu,dc=east,host=serverA value=3 0000000004`, v)
+
+	for retry := 0; ; retry++ {
+		event, err := cli.TopicEvent(cli.TopicEventLink("cpu", "cpu:serverA"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !event.State.Inhibited {
+			break
+		}
+		if retry > 10 {
+			t.Fatalf("expected event to no longer be inhibited %+v", event.State)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	ts.Close()
+	var got []string
+	for _, d := range ts.Data() {
+		got = append(got, d.ID)
+	}
+	if exp := []string{"cpu:serverB", "cpu:serverA"}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected tcp requests:\ngot\n%v\nexp\n%v", got, exp)
+	}
+
+	if err := cli.DeleteInhibition(inhibition.Link); err != nil {
+		t.Fatal(err)
+	}
+	inhibitions, err := cli.ListInhibitions(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inhibitions.Inhibitions) != 0 {
+		t.Errorf("unexpected inhibitions after delete %+v", inhibitions.Inhibitions)
+	}
+}
+
+func TestServer_Alert_Inhibitions_ExistingSource(t *testing.T) {
+	// Create default config
+	c := NewConfig()
+	s := OpenServer(c)
+	cli := Client(s)
+	defer s.Close()
+
+	// Create tasks for the uplink and cpu alerts
+	for _, measurement := range []string{"uplink", "cpu"} {
+		tick := `
+stream
+	|from()
+		.measurement('` + measurement + `')
+		.groupBy(*)
+	|alert()
+		.id('{{ .Name }}:{{ index .Tags "host" }}')
+		.message('message')
+		.crit(lambda: "value" > 1.0)
+		.topic('` + measurement + `')
+`
+		if _, err := cli.CreateTask(client.CreateTaskOptions{
+			ID:   measurement + "_task",
+			Type: client.StreamTask,
+			DBRPs: []client.DBRP{{
+				Database:        "mydb",
+				RetentionPolicy: "myrp",
+			}},
+			TICKscript: tick,
+			Status:     client.Enabled,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	waitForEvent := func(topic, event, level string) client.TopicEvent {
+		retry := 0
+		for {
+			e, err := cli.TopicEvent(cli.TopicEventLink(topic, event))
+			if err == nil && e.State.Level == level {
+				return e
+			}
+			retry++
+			if retry > 10 {
+				t.Fatalf("event %s of topic %s did not reach level %s", event, topic, level)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	// The uplink is already down when the inhibition is created
+	v := url.Values{}
+	v.Add("precision", "s")
+	s.MustWrite("mydb", "myrp", `uplink,dc=east value=2 0000000000`, v)
+	waitForEvent("uplink", "uplink:", "CRITICAL")
+
+	if _, err := cli.CreateInhibition(client.InhibitionOptions{
+		ID:           "uplink",
+		SourceTopic:  "uplink",
+		TargetTopics: []string{"cpu"},
+		Equal:        []string{"dc"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.MustWrite("mydb", "myrp", `cpu,dc=east,host=serverA value=2 0000000001`, v)
+	if e := waitForEvent("cpu", "cpu:serverA", "CRITICAL"); !e.State.Inhibited {
+		t.Errorf("expected event to be inhibited by the existing uplink event %+v", e.State)
+	}
+
+	// The sources are rebuilt from the saved topic state after a restart
+	s.Restart()
+	waitForEvent("uplink", "uplink:", "CRITICAL")
+
+	s.MustWrite("mydb", "myrp", `cpu,dc=east,host=serverB value=2 0000000002`, v)
+	if e := waitForEvent("cpu", "cpu:serverB", "CRITICAL"); !e.State.Inhibited {
+		t.Errorf("expected event to be inhibited after restart %+v", e.State)
+	}
+}
+
+func TestServer_Alert_Escalate(t *testing.T) {
+	// Setup test TCP servers for each stage
+	first, err := alerttest.NewTCPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	second, err := alerttest.NewTCPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	// Create default config
+	c := NewConfig()
+	s := OpenServer(c)
+	cli := Client(s)
+	defer s.Close()
+
+	topic := "test"
+
+	// Create task for alert
+	tick := `
+stream
+	|from()
+		.measurement('alert')
+		.groupBy('host')
+	|alert()
+		.id('{{ index .Tags "host" }}')
+		.message('message')
+		.crit(lambda: "value" > 1.0)
+		.topic('` + topic + `')
+`
+
+	if _, err := cli.CreateTask(client.CreateTaskOptions{
+		ID:   "alert_task",
+		Type: client.StreamTask,
+		DBRPs: []client.DBRP{{
+			Database:        "mydb",
+			RetentionPolicy: "myrp",
+		}},
+		TICKscript: tick,
+		Status:     client.Enabled,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:   "escalate",
+		Kind: "escalate",
+		Options: map[string]interface{}{
+			"stages": []map[string]interface{}{{
+				"actions": []map[string]interface{}{{
+					"kind":    "tcp",
+					"options": map[string]interface{}{"address": first.Addr},
+				}},
+			}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Add a second stage that is notified if the event is still critical after a second.
+	if _, err := cli.ReplaceTopicHandler(cli.TopicHandlerLink(topic, "escalate"), client.TopicHandlerOptions{
+		ID:   "escalate",
+		Kind: "escalate",
+		Options: map[string]interface{}{
+			"stages": []map[string]interface{}{
+				{
+					"after": "0s",
+					"actions": []map[string]interface{}{{
+						"kind":    "tcp",
+						"options": map[string]interface{}{"address": first.Addr},
+					}},
+				},
+				{
+					"after": "1s",
+					"actions": []map[string]interface{}{{
+						"kind":    "tcp",
+						"options": map[string]interface{}{"address": second.Addr},
+					}},
+				},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:   "invalid",
+		Kind: "escalate",
+		Options: map[string]interface{}{
+			"stages": []map[string]interface{}{{
+				"actions": []map[string]interface{}{{"kind": "escalate"}},
+			}},
+		},
+	}); err == nil {
+		t.Error("expected error creating nested escalate handler")
+	}
+
+	point := `alert,host=serverA value=2 0000000000
+alert,host=serverB value=2 0000000000
+`
+	v := url.Values{}
+	v.Add("precision", "s")
+	s.MustWrite("mydb", "myrp", point, v)
+
+	// Acknowledged events are not escalated
+	if _, err := cli.AckTopicEvent(cli.TopicEventLink(topic, "serverB"), client.EventAckOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pending escalations are resumed once by a replaced handler with the same ID
+	h, err := cli.TopicHandler(cli.TopicHandlerLink(topic, "escalate"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.ReplaceTopicHandler(h.Link, client.TopicHandlerOptions{
+		ID:      h.ID,
+		Kind:    h.Kind,
+		Options: h.Options,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// A failed update keeps the existing handler
+	if _, err := cli.ReplaceTopicHandler(h.Link, client.TopicHandlerOptions{
+		ID:   h.ID,
+		Kind: h.Kind,
+		Options: map[string]interface{}{
+			"stages": []map[string]interface{}{{
+				"actions": []map[string]interface{}{
+					{
+						"kind":    "aggregate",
+						"options": map[string]interface{}{"interval": "1s", "topic": "agg"},
+					},
+					{"kind": "unknown"},
+				},
+			}},
+		},
+	}); err == nil {
+		t.Error("expected error replacing escalate handler with an invalid action")
+	}
+
+	// Pending escalations are resumed after a restart
+	s.Restart()
+	time.Sleep(1500 * time.Millisecond)
+
+	first.Close()
+	second.Close()
+	ids := func(data []alert.Data) []string {
+		var ids []string
+		for _, d := range data {
+			ids = append(ids, d.ID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	if got, exp := ids(first.Data()), []string{"serverA", "serverB"}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected events of the first stage:\ngot\n%v\nexp\n%v", got, exp)
+	}
+	if got, exp := ids(second.Data()), []string{"serverA"}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected events of the second stage:\ngot\n%v\nexp\n%v", got, exp)
+	}
+}
+
+func TestServer_Alert_History(t *testing.T) {
+	c := NewConfig()
+	s := OpenServer(c)
+	cli := Client(s)
+	defer s.Close()
+
+	topic := "test"
+	tick := `
+stream
+	|from()
+		.measurement('alert')
+	|alert()
+		.id('{{ index .Tags "host" }}')
+		.message('{{ .ID }} is {{ .Level }}')
+		.warn(lambda: "value" > 0.5)
+		.crit(lambda: "value" > 1.0)
+		.topic('` + topic + `')
+`
+	if _, err := cli.CreateTask(client.CreateTaskOptions{
+		ID:   "alert_task",
+		Type: client.StreamTask,
+		DBRPs: []client.DBRP{{
+			Database:        "mydb",
+			RetentionPolicy: "myrp",
+		}},
+		TICKscript: tick,
+		Status:     client.Enabled,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Events within the retention of the history
+	start := time.Now().Add(-time.Hour).Unix()
+	at := func(sec int64) time.Time {
+		return time.Unix(start+sec, 0).UTC()
+	}
+	v := url.Values{}
+	v.Add("precision", "s")
+	s.MustWrite("mydb", "myrp", fmt.Sprintf(`alert,host=a value=2 %d`, start), v)
+	s.MustWrite("mydb", "myrp", fmt.Sprintf(`alert,host=b value=0.7 %d`, start+1), v)
+	// No transition
+	s.MustWrite("mydb", "myrp", fmt.Sprintf(`alert,host=a value=3 %d`, start+2), v)
+	s.MustWrite("mydb", "myrp", fmt.Sprintf(`alert,host=a value=0 %d`, start+3), v)
+	s.MustWrite("mydb", "myrp", fmt.Sprintf(`alert,host=b value=2 %d`, start+4), v)
+
+	type transition struct {
+		Event, Previous, Level string
+		Time                   time.Time
+	}
+	history := func(opt *client.ListTopicHistoryOptions) []transition {
+		th, err := cli.ListTopicHistory(cli.TopicHistoryLink(topic), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]transition, len(th.Records))
+		for i, r := range th.Records {
+			got[i] = transition{r.EventID, r.Previous, r.Level, r.Time.UTC()}
+		}
+		return got
+	}
+	all := []transition{
+		{"b", "WARNING", "CRITICAL", at(4)},
+		{"a", "CRITICAL", "OK", at(3)},
+		{"b", "OK", "WARNING", at(1)},
+		{"a", "OK", "CRITICAL", at(0)},
+	}
+	testCases := []struct {
+		name string
+		opt  *client.ListTopicHistoryOptions
+		exp  []transition
+	}{
+		{
+			name: "all",
+			exp:  all,
+		},
+		{
+			name: "min-level",
+			opt:  &client.ListTopicHistoryOptions{MinLevel: "CRITICAL"},
+			exp:  []transition{all[0], all[3]},
+		},
+		{
+			name: "tag",
+			opt:  &client.ListTopicHistoryOptions{Tags: map[string]string{"host": "a"}},
+			exp:  []transition{all[1], all[3]},
+		},
+		{
+			name: "event",
+			opt:  &client.ListTopicHistoryOptions{EventID: "b*"},
+			exp:  []transition{all[0], all[2]},
+		},
+		{
+			name: "time range",
+			opt:  &client.ListTopicHistoryOptions{Since: at(1), Until: at(4)},
+			exp:  []transition{all[1], all[2]},
+		},
+		{
+			name: "paged",
+			opt:  &client.ListTopicHistoryOptions{Offset: 1, Limit: 2},
+			exp:  []transition{all[1], all[2]},
+		},
+	}
+	for _, tc := range testCases {
+		if got := history(tc.opt); !reflect.DeepEqual(got, tc.exp) {
+			t.Errorf("%s: unexpected history:\ngot\n%v\nexp\n%v", tc.name, got, tc.exp)
+		}
+	}
+
+	th, err := cli.ListTopicHistory(cli.TopicHistoryLink(topic), &client.ListTopicHistoryOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(th.Records) != 1 || th.Records[0].Message != "b is CRITICAL" || !reflect.DeepEqual(th.Records[0].Tags, map[string]string{"host": "b"}) {
+		t.Errorf("unexpected record %+v", th.Records)
+	}
+
+	// History is persisted
+	s.Restart()
+	if got := history(nil); !reflect.DeepEqual(got, all) {
+		t.Errorf("unexpected history after restart:\ngot\n%v\nexp\n%v", got, all)
+	}
+
+	// History is deleted with the topic
+	if err := cli.DeleteTopic(cli.TopicLink(topic)); err != nil {
+		t.Fatal(err)
+	}
+	if got := history(nil); len(got) != 0 {
+		t.Errorf("expected no history after deleting topic, got %v", got)
+	}
+}
+
+func TestServer_Alert_Retry(t *testing.T) {
+	// flaky fails the first two requests, broken fails all requests.
+	var flakyCount, brokenCount int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&flakyCount, 1) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer flaky.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&brokenCount, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	c := NewConfig()
+	s := OpenServer(c)
+	cli := Client(s)
+	defer s.Close()
+
+	topic := "retry_test"
+	tick := `
+stream
+	|from()
+		.measurement('alert')
+	|alert()
+		.id('id')
+		.message('message')
+		.crit(lambda: "value" > 1.0)
+		.topic('` + topic + `')
+`
+	if _, err := cli.CreateTask(client.CreateTaskOptions{
+		ID:   "alert_task",
+		Type: client.StreamTask,
+		DBRPs: []client.DBRP{{
+			Database:        "mydb",
+			RetentionPolicy: "myrp",
+		}},
+		TICKscript: tick,
+		Status:     client.Enabled,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, retry := range []*client.HandlerRetry{
+		{Attempts: -1},
+		{Attempts: 1, DeadLetterTopic: topic},
+	} {
+		if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+			ID:      "invalid",
+			Kind:    "post",
+			Options: map[string]interface{}{"url": flaky.URL},
+			Retry:   retry,
+		}); err == nil {
+			t.Errorf("expected error creating handler with retry %+v", retry)
+		}
+	}
+
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:      "flaky",
+		Kind:    "post",
+		Options: map[string]interface{}{"url": flaky.URL},
+		Retry: &client.HandlerRetry{
+			Attempts: 3,
+			Backoff:  client.Duration(10 * time.Millisecond),
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	exp := &client.HandlerRetry{
+		Attempts:        1,
+		Backoff:         client.Duration(10 * time.Millisecond),
+		MaxBackoff:      client.Duration(time.Second),
+		DeadLetterTopic: "dead_letters",
+	}
+	h, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:      "broken",
+		Kind:    "post",
+		Options: map[string]interface{}{"url": broken.URL},
+		Retry:   exp,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(h.Retry, exp) {
+		t.Errorf("unexpected retry got %+v exp %+v", h.Retry, exp)
+	}
+	// Template errors are permanent, the event is not retried.
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:   "template",
+		Kind: "post",
+		Options: map[string]interface{}{
+			"url":            broken.URL,
+			"alert-template": "{{ .Missing }}",
+		},
+		Retry: &client.HandlerRetry{
+			Attempts:        3,
+			Backoff:         client.Duration(10 * time.Millisecond),
+			DeadLetterTopic: "dead_letters",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	v := url.Values{}
+	v.Add("precision", "s")
+	s.MustWrite("mydb", "myrp", `alert value=2 0000000000`, v)
+
+	topicStats := func() (retried, failed float64) {
+		resp, err := http.Get(s.URL() + "/debug/vars")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var vars struct {
+			Kapacitor map[string]struct {
+				Name   string                 `json:"name"`
+				Tags   map[string]string      `json:"tags"`
+				Values map[string]interface{} `json:"values"`
+			} `json:"kapacitor"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+			t.Fatal(err)
+		}
+		for _, stat := range vars.Kapacitor {
+			if stat.Name == "topics" && stat.Tags["id"] == topic {
+				retried, _ = stat.Values["retried"].(float64)
+				failed, _ = stat.Values["failed"].(float64)
+			}
+		}
+		return
+	}
+
+	// Handlers retry independently, wait until all retries are done.
+	var event client.TopicEvent
+	var retried, failed float64
+	for i := 0; i < 20; i++ {
+		time.Sleep(50 * time.Millisecond)
+		event, err = cli.TopicEvent(cli.TopicEventLink("dead_letters", topic+":broken:id"))
+		retried, failed = topicStats()
+		if err == nil && atomic.LoadInt32(&flakyCount) == 3 && retried == 3 && failed == 2 {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.State.Level != "CRITICAL" || event.State.Message != "message" || !strings.Contains(event.State.Details, "500") {
+		t.Errorf("unexpected dead-letter event %+v", event.State)
+	}
+	if got := atomic.LoadInt32(&flakyCount); got != 3 {
+		t.Errorf("unexpected number of requests to flaky handler got %d exp 3", got)
+	}
+	if got := atomic.LoadInt32(&brokenCount); got != 2 {
+		t.Errorf("unexpected number of requests to broken handler got %d exp 2", got)
+	}
+	if retried != 3 || failed != 2 {
+		t.Errorf("unexpected topic stats got retried %v failed %v exp retried 3 failed 2", retried, failed)
+	}
+	event, err = cli.TopicEvent(cli.TopicEventLink("dead_letters", topic+":template:id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(event.State.Details, "template") {
+		t.Errorf("unexpected dead-letter event %+v", event.State)
+	}
+}
+
+func TestServer_Alert_Retry_NonBlocking(t *testing.T) {
+	// The handler fails all events of serverA and records the events of other hosts.
+	received := make(chan string, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ad := alert.Data{}
+		if err := json.NewDecoder(r.Body).Decode(&ad); err != nil {
+			t.Error(err)
+		}
+		if ad.ID == "serverA" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		received <- ad.ID
+	}))
+	defer ts.Close()
+
+	c := NewConfig()
+	s := OpenServer(c)
+	cli := Client(s)
+	defer s.Close()
+
+	topic := "retry_test"
+	tick := `
+stream
+	|from()
+		.measurement('alert')
+		.groupBy('host')
+	|alert()
+		.id('{{ index .Tags "host" }}')
+		.message('message')
+		.crit(lambda: "value" > 1.0)
+		.topic('` + topic + `')
+`
+	if _, err := cli.CreateTask(client.CreateTaskOptions{
+		ID:   "alert_task",
+		Type: client.StreamTask,
+		DBRPs: []client.DBRP{{
+			Database:        "mydb",
+			RetentionPolicy: "myrp",
+		}},
+		TICKscript: tick,
+		Status:     client.Enabled,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:      "post",
+		Kind:    "post",
+		Options: map[string]interface{}{"url": ts.URL},
+		Retry: &client.HandlerRetry{
+			Attempts: 3,
+			Backoff:  client.Duration(time.Hour),
+		},
+	}); err != nil {
 		t.Fatal(err)
 	}
-	if !reflect.DeepEqual(te, expTopicEvents) {
-		t.Errorf("unexpected topic events for aggregate topic:\ngot\n%+v\nexp\n%+v\n", te, expTopicEvents)
+
+	// Events of other hosts are handled while the event of serverA waits to be retried.
+	v := url.Values{}
+	v.Add("precision", "s")
+	s.MustWrite("mydb", "myrp", `alert,host=serverA value=2 0000000000
+alert,host=serverB value=2 0000000001
+`, v)
+	select {
+	case id := <-received:
+		if id != "serverB" {
+			t.Errorf("unexpected event %q", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the event of serverB")
 	}
 }
 
-func TestServer_Alert_Publish(t *testing.T) {
-	// Setup test TCP server
-	ts, err := alerttest.NewTCPServer()
+func TestServer_Alert_MatchFields(t *testing.T) {
+	prod, err := alerttest.NewTCPServer()
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer ts.Close()
+	defer prod.Close()
+	changed, err := alerttest.NewTCPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer changed.Close()
 
-	// Create default config
 	c := NewConfig()
 	s := OpenServer(c)
 	cli := Client(s)
 	defer s.Close()
 
-	publishTopic := "publish"
-
-	// Create task for alert
+	topic := "test"
 	tick := `
 stream
 	|from()
 		.measurement('alert')
 	|alert()
-		.id('id')
+		.id('{{ index .Tags "env" }}')
 		.message('message')
-		.details('details')
 		.crit(lambda: "value" > 1.0)
-		.topic('` + publishTopic + `')
+		.topic('` + topic + `')
 `
-
 	if _, err := cli.CreateTask(client.CreateTaskOptions{
-		ID:   "publish_task",
+		ID:   "alert_task",
 		Type: client.StreamTask,
 		DBRPs: []client.DBRP{{
 			Database:        "mydb",
@@ -9006,121 +11707,89 @@ stream
 		t.Fatal(err)
 	}
 
-	// Create tpc handler on tcp topic
-	tcpTopic := "tcp"
-	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(tcpTopic), client.TopicHandlerOptions{
-		ID:   "tcp_handler",
-		Kind: "tcp",
-		Options: map[string]interface{}{
-			"address": ts.Addr,
-		},
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:    "invalid",
+		Kind:  "tcp",
+		Match: `"level" == UNKNOWN`,
+	}); err == nil {
+		t.Error("expected error creating handler with invalid match expression")
+	}
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:      "prod",
+		Kind:    "tcp",
+		Options: map[string]interface{}{"address": prod.Addr},
+		Match:   `"level" == CRITICAL AND "env" == 'prod'`,
 	}); err != nil {
 		t.Fatal(err)
 	}
-
-	// Create publish handler on publish topic
-	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(publishTopic), client.TopicHandlerOptions{
-		ID:   "publish_handler",
-		Kind: "publish",
-		Options: map[string]interface{}{
-			// Publish to tcpTopic
-			"topics": []string{tcpTopic},
-		},
+	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:      "changed",
+		Kind:    "tcp",
+		Options: map[string]interface{}{"address": changed.Addr},
+		Match:   `"changed" AND "value" > 5.0`,
 	}); err != nil {
 		t.Fatal(err)
 	}
 
-	// Write points
-	point := `alert value=2 0000000000`
+	points := `alert,env=prod value=2 0000000000
+alert,env=dev value=7 0000000001
+alert,env=prod value=9 0000000002
+alert,env=prod value=0 0000000003
+`
 	v := url.Values{}
 	v.Add("precision", "s")
-	s.MustWrite("mydb", "myrp", point, v)
+	s.MustWrite("mydb", "myrp", points, v)
 
+	// Close the servers once all events are handled.
 	s.Restart()
+	prod.Close()
+	changed.Close()
 
-	// Check TCP handler got event
-	alertData := alert.Data{
-		ID:      "id",
-		Message: "message",
-		Details: "details",
-		Time:    time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
-		Level:   alert.Critical,
-		Data: models.Result{
-			Series: models.Rows{
-				{
-					Name:    "alert",
-					Columns: []string{"time", "value"},
-					Values: [][]interface{}{[]interface{}{
-						time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
-						2.0,
-					}},
-				},
-			},
-		},
+	type event struct {
+		ID   string
+		Time time.Time
 	}
-	ts.Close()
-	exp := []alert.Data{alertData}
-	got := ts.Data()
-	if !reflect.DeepEqual(exp, got) {
-		t.Errorf("unexpected tcp request:\nexp\n%+v\ngot\n%+v\n", exp, got)
+	events := func(data []alert.Data) []event {
+		events := make([]event, len(data))
+		for i, d := range data {
+			events[i] = event{d.ID, d.Time.UTC()}
+		}
+		return events
 	}
-
-	// Check event on topic
-	l := cli.TopicEventsLink(tcpTopic)
-	expTopicEvents := client.TopicEvents{
-		Link:  l,
-		Topic: tcpTopic,
-		Events: []client.TopicEvent{{
-			Link: client.Link{Relation: client.Self, Href: fmt.Sprintf("/kapacitor/v1preview/alerts/topics/%s/events/id", tcpTopic)},
-			ID:   "id",
-			State: client.EventState{
-				Message:  "message",
-				Details:  "details",
-				Time:     time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
-				Duration: 0,
-				Level:    "CRITICAL",
-			},
-		}},
+	at := func(sec int64) time.Time {
+		return time.Unix(sec, 0).UTC()
 	}
-
-	te, err := cli.ListTopicEvents(l, nil)
-	if err != nil {
-		t.Fatal(err)
+	if got, exp := events(prod.Data()), []event{{"prod", at(0)}, {"prod", at(2)}}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected events for prod handler:\ngot\n%v\nexp\n%v", got, exp)
 	}
-	if !reflect.DeepEqual(te, expTopicEvents) {
-		t.Errorf("unexpected topic events for publish topic:\ngot\n%+v\nexp\n%+v\n", te, expTopicEvents)
+	if got, exp := events(changed.Data()), []event{{"dev", at(1)}}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected events for changed handler:\ngot\n%v\nexp\n%v", got, exp)
 	}
 }
 
-func TestServer_Alert_Match(t *testing.T) {
-	// Setup test TCP server
+func TestServer_Alert_RateLimit(t *testing.T) {
 	ts, err := alerttest.NewTCPServer()
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer ts.Close()
 
-	// Create default config
 	c := NewConfig()
 	s := OpenServer(c)
 	cli := Client(s)
 	defer s.Close()
 
 	topic := "test"
-
-	// Create task for alert
 	tick := `
 stream
 	|from()
 		.measurement('alert')
 	|alert()
 		.id('id')
-		.message('message')
-		.details('details')
+		.message('message {{ index .Fields "value" }}')
 		.crit(lambda: "value" > 1.0)
 		.topic('` + topic + `')
 `
-
 	if _, err := cli.CreateTask(client.CreateTaskOptions{
 		ID:   "alert_task",
 		Type: client.StreamTask,
@@ -9134,82 +11803,77 @@ stream
 		t.Fatal(err)
 	}
 
-	// Create tpc handler with match condition
 	if _, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
-		ID:   "tcp_handler",
-		Kind: "tcp",
-		Options: map[string]interface{}{
-			"address": ts.Addr,
-		},
-		Match: `"host" == 'serverA' AND level() == CRITICAL`,
-	}); err != nil {
+		ID:        "invalid",
+		Kind:      "tcp",
+		Options:   map[string]interface{}{"address": ts.Addr},
+		RateLimit: &client.HandlerRateLimit{Count: 1},
+	}); err == nil {
+		t.Error("expected error creating handler with rate limit without interval")
+	}
+	exp := &client.HandlerRateLimit{
+		Count:    2,
+		Interval: client.Duration(time.Hour),
+		Message:  "Suppressed {{ .Count }} events",
+	}
+	h, err := cli.CreateTopicHandler(cli.TopicHandlersLink(topic), client.TopicHandlerOptions{
+		ID:        "limited",
+		Kind:      "tcp",
+		Options:   map[string]interface{}{"address": ts.Addr},
+		RateLimit: exp,
+	})
+	if err != nil {
 		t.Fatal(err)
 	}
+	if !reflect.DeepEqual(h.RateLimit, exp) {
+		t.Errorf("unexpected rate limit got %+v exp %+v", h.RateLimit, exp)
+	}
 
-	// Write points
-	point := `alert,host=serverA value=0 0000000000
-alert,host=serverB value=2 0000000001
-alert,host=serverB value=0 0000000002
-alert,host=serverA value=2 0000000003
-alert,host=serverB value=0 0000000004
+	points := `alert value=2 0000000000
+alert value=3 0000000001
+alert value=4 0000000002
+alert value=5 0000000003
+alert value=6 0000000004
 `
 	v := url.Values{}
 	v.Add("precision", "s")
-	s.MustWrite("mydb", "myrp", point, v)
-
-	s.Restart()
+	s.MustWrite("mydb", "myrp", points, v)
 
-	alertData := alert.Data{
-		ID:      "id",
-		Message: "message",
-		Details: "details",
-		Time:    time.Date(1970, 1, 1, 0, 0, 3, 0, time.UTC),
-		Level:   alert.Critical,
-		Data: models.Result{
-			Series: models.Rows{
-				{
-					Name:    "alert",
-					Tags:    map[string]string{"host": "serverA"},
-					Columns: []string{"time", "value"},
-					Values: [][]interface{}{[]interface{}{
-						time.Date(1970, 1, 1, 0, 0, 3, 0, time.UTC),
-						2.0,
-					}},
-				},
-			},
-		},
+	var tp client.Topic
+	for i := 0; i < 20; i++ {
+		tp, err = cli.Topic(cli.TopicLink(topic))
+		if err == nil && tp.Suppressed == 3 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
 	}
-	ts.Close()
-	exp := []alert.Data{alertData}
-	got := ts.Data()
-	if !reflect.DeepEqual(exp, got) {
-		t.Errorf("unexpected tcp request:\nexp\n%+v\ngot\n%+v\n", exp, got)
+	if err != nil {
+		t.Fatal(err)
 	}
-
-	// Topic should have must recent event
-	l := cli.TopicEventsLink(topic)
-	expTopicEvents := client.TopicEvents{
-		Link:  l,
-		Topic: topic,
-		Events: []client.TopicEvent{{
-			Link: client.Link{Relation: client.Self, Href: fmt.Sprintf("/kapacitor/v1preview/alerts/topics/%s/events/id", topic)},
-			ID:   "id",
-			State: client.EventState{
-				Message:  "message",
-				Details:  "details",
-				Time:     time.Date(1970, 1, 1, 0, 0, 4, 0, time.UTC),
-				Duration: client.Duration(time.Second),
-				Level:    "OK",
-			},
-		}},
+	if tp.Suppressed != 3 {
+		t.Errorf("unexpected number of suppressed events got %d exp 3", tp.Suppressed)
 	}
 
-	te, err := cli.ListTopicEvents(l, nil)
-	if err != nil {
-		t.Fatal(err)
+	// The digest of the suppressed events is handled when the handler is closed.
+	s.Restart()
+	ts.Close()
+
+	got := ts.Data()
+	if len(got) != 3 {
+		t.Fatalf("unexpected number of handled events got %d exp 3: %v", len(got), got)
 	}
-	if !reflect.DeepEqual(te, expTopicEvents) {
-		t.Errorf("unexpected topic events for publish topic:\ngot\n%+v\nexp\n%+v\n", te, expTopicEvents)
+	for i, msg := range []string{"message 2", "message 3"} {
+		if got[i].ID != "id" || got[i].Message != msg {
+			t.Errorf("unexpected event %d got %s %q exp id %q", i, got[i].ID, got[i].Message, msg)
+		}
+	}
+	digest := got[2]
+	if digest.ID != topic+":limited:digest" ||
+		digest.Message != "Suppressed 3 events" ||
+		digest.Details != "message 4\nmessage 5\nmessage 6" ||
+		digest.Level != alert.Critical ||
+		!digest.Time.Equal(time.Unix(4, 0)) {
+		t.Errorf("unexpected digest event %+v", digest)
 	}
 }
 
@@ -9605,6 +12269,7 @@ func TestServer_AlertTopic(t *testing.T) {
 		Collected:    0,
 		EventsLink:   client.Link{Relation: "events", Href: "/kapacitor/v1preview/alerts/topics/misc/events"},
 		HandlersLink: client.Link{Relation: "handlers", Href: "/kapacitor/v1preview/alerts/topics/misc/handlers"},
+		HistoryLink:  client.Link{Relation: "history", Href: "/kapacitor/v1preview/alerts/topics/misc/history"},
 	}
 	topic, err := cli.Topic(cli.TopicLink("misc"))
 	if err != nil {
@@ -9648,6 +12313,7 @@ func TestServer_AlertListTopics(t *testing.T) {
 				Level:        "OK",
 				EventsLink:   client.Link{Relation: "events", Href: "/kapacitor/v1preview/alerts/topics/misc/events"},
 				HandlersLink: client.Link{Relation: "handlers", Href: "/kapacitor/v1preview/alerts/topics/misc/handlers"},
+				HistoryLink:  client.Link{Relation: "history", Href: "/kapacitor/v1preview/alerts/topics/misc/history"},
 			},
 			{
 				Link:         client.Link{Relation: client.Self, Href: "/kapacitor/v1preview/alerts/topics/system"},
@@ -9655,6 +12321,7 @@ func TestServer_AlertListTopics(t *testing.T) {
 				Level:        "OK",
 				EventsLink:   client.Link{Relation: "events", Href: "/kapacitor/v1preview/alerts/topics/system/events"},
 				HandlersLink: client.Link{Relation: "handlers", Href: "/kapacitor/v1preview/alerts/topics/system/handlers"},
+				HistoryLink:  client.Link{Relation: "history", Href: "/kapacitor/v1preview/alerts/topics/system/history"},
 			},
 			{
 				Link:         client.Link{Relation: client.Self, Href: "/kapacitor/v1preview/alerts/topics/test"},
@@ -9662,6 +12329,7 @@ func TestServer_AlertListTopics(t *testing.T) {
 				Level:        "OK",
 				EventsLink:   client.Link{Relation: "events", Href: "/kapacitor/v1preview/alerts/topics/test/events"},
 				HandlersLink: client.Link{Relation: "handlers", Href: "/kapacitor/v1preview/alerts/topics/test/handlers"},
+				HistoryLink:  client.Link{Relation: "history", Href: "/kapacitor/v1preview/alerts/topics/test/history"},
 			},
 		},
 	}
@@ -9992,3 +12660,94 @@ func TestStorage_Backup(t *testing.T) {
 		t.Fatalf("unexpected dot\ngot\n%s\nexp\n%s\n", ti.Dot, dot)
 	}
 }
+
+func TestBlobStore(t *testing.T) {
+	s, cli := OpenDefaultServer()
+	defer s.Close()
+
+	readBlob := func(l client.Link) string {
+		r, err := cli.Blob(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	b1, err := cli.CreateBlob(strings.NewReader("model v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SHA-256 of the content
+	if got, exp := b1.ID, "97e79ca4f543a0ddb2336f125ebcc9bc9b453986408d58f8ce0fd0ce80be4bc2"; got != exp {
+		t.Fatalf("unexpected blob ID got %s exp %s", got, exp)
+	}
+	if got, exp := b1.Size, int64(len("model v1")); got != exp {
+		t.Errorf("unexpected blob size got %d exp %d", got, exp)
+	}
+	if got, exp := readBlob(b1.Link), "model v1"; got != exp {
+		t.Errorf("unexpected blob data got %q exp %q", got, exp)
+	}
+
+	// Creating the same content returns the same blob
+	b1Again, err := cli.CreateBlob(strings.NewReader("model v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b1Again.ID != b1.ID {
+		t.Errorf("expected content addressed blob IDs to match got %s exp %s", b1Again.ID, b1.ID)
+	}
+
+	b2, err := cli.CreateBlob(strings.NewReader("model v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tagLink := cli.BlobTagLink("model")
+	if _, err := cli.TagBlob(tagLink, client.TagBlobOptions{Blob: b1.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := readBlob(tagLink), "model v1"; got != exp {
+		t.Errorf("unexpected tagged blob data got %q exp %q", got, exp)
+	}
+	tag, err := cli.TagBlob(tagLink, client.TagBlobOptions{Blob: b2.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := tag.BlobID, b2.ID; got != exp {
+		t.Errorf("unexpected tag blob got %s exp %s", got, exp)
+	}
+	if got, exp := readBlob(tagLink), "model v2"; got != exp {
+		t.Errorf("unexpected tagged blob data got %q exp %q", got, exp)
+	}
+
+	history, err := cli.ListTagHistory(cli.BlobTagHistoryLink("model"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(history.History), 2; got != exp {
+		t.Fatalf("unexpected history length got %d exp %d", got, exp)
+	}
+	if got, exp := history.History[0].BlobID, b2.ID; got != exp {
+		t.Errorf("unexpected most recent history entry got %s exp %s", got, exp)
+	}
+	if got, exp := history.History[1].BlobID, b1.ID; got != exp {
+		t.Errorf("unexpected oldest history entry got %s exp %s", got, exp)
+	}
+
+	// Tagging a missing blob fails
+	if _, err := cli.TagBlob(tagLink, client.TagBlobOptions{Blob: "missing"}); err == nil {
+		t.Error("expected error tagging non-existent blob")
+	}
+
+	if err := cli.DeleteBlob(b1.Link); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.Blob(b1.Link); err == nil {
+		t.Error("expected error getting deleted blob")
+	}
+}
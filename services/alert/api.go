@@ -9,12 +9,16 @@ import (
 	"net/http"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/auth"
 	client "github.com/influxdata/kapacitor/client/v1"
 	"github.com/influxdata/kapacitor/services/httpd"
+	"github.com/influxdata/kapacitor/uuid"
 )
 
 const (
@@ -28,20 +32,37 @@ const (
 
 	topicEventsPath   = "events"
 	topicHandlersPath = "handlers"
+	topicHistoryPath  = "history"
+	eventAckPath      = "ack"
 
 	eventsPattern   = "*/" + topicEventsPath
 	eventPattern    = "*/" + topicEventsPath + "/*"
+	eventAckPattern = "*/" + topicEventsPath + "/*/" + eventAckPath
 	handlersPattern = "*/" + topicHandlersPath
 	handlerPattern  = "*/" + topicHandlersPath + "/*"
+	historyPattern  = "*/" + topicHistoryPath
 
 	eventsRelation   = "events"
 	handlersRelation = "handlers"
+	historyRelation  = "history"
+
+	silencesPath             = alertsPath + "/silences"
+	silencesPathAnchored     = alertsPath + "/silences/"
+	silencesBasePath         = httpd.BasePreviewPath + silencesPath
+	silencesBasePathAnchored = httpd.BasePreviewPath + silencesPathAnchored
+
+	inhibitionsPath             = alertsPath + "/inhibitions"
+	inhibitionsPathAnchored     = alertsPath + "/inhibitions/"
+	inhibitionsBasePath         = httpd.BasePreviewPath + inhibitionsPath
+	inhibitionsBasePathAnchored = httpd.BasePreviewPath + inhibitionsPathAnchored
 )
 
 type apiServer struct {
 	Registrar    HandlerSpecRegistrar
 	Topics       Topics
 	Persister    TopicPersister
+	Silences     SilenceRegistrar
+	Inhibitions  InhibitionRegistrar
 	routes       []httpd.Route
 	HTTPDService interface {
 		AddPreviewRoutes([]httpd.Route) error
@@ -54,9 +75,10 @@ func (s *apiServer) Open() error {
 	// Define API routes
 	s.routes = []httpd.Route{
 		{
-			Method:      "GET",
-			Pattern:     topicsPath,
-			HandlerFunc: s.handleListTopics,
+			Method:              "GET",
+			Pattern:             topicsPath,
+			HandlerFunc:         s.handleListTopics,
+			ScopedAuthorization: true,
 		},
 		{
 			Method:      "GET",
@@ -89,6 +111,68 @@ func (s *apiServer) Open() error {
 			Pattern:     topicsPathAnchored,
 			HandlerFunc: httpd.ServeOptions,
 		},
+		{
+			Method:      "GET",
+			Pattern:     silencesPath,
+			HandlerFunc: s.handleListSilences,
+		},
+		{
+			Method:      "POST",
+			Pattern:     silencesPath,
+			HandlerFunc: s.handleCreateSilence,
+		},
+		{
+			Method:      "GET",
+			Pattern:     silencesPathAnchored,
+			HandlerFunc: s.handleGetSilence,
+		},
+		{
+			Method:      "PUT",
+			Pattern:     silencesPathAnchored,
+			HandlerFunc: s.handleReplaceSilence,
+		},
+		{
+			Method:      "DELETE",
+			Pattern:     silencesPathAnchored,
+			HandlerFunc: s.handleDeleteSilence,
+		},
+		{
+			// Satisfy CORS checks.
+			Method:      "OPTIONS",
+			Pattern:     silencesPathAnchored,
+			HandlerFunc: httpd.ServeOptions,
+		},
+		{
+			Method:      "GET",
+			Pattern:     inhibitionsPath,
+			HandlerFunc: s.handleListInhibitions,
+		},
+		{
+			Method:      "POST",
+			Pattern:     inhibitionsPath,
+			HandlerFunc: s.handleCreateInhibition,
+		},
+		{
+			Method:      "GET",
+			Pattern:     inhibitionsPathAnchored,
+			HandlerFunc: s.handleGetInhibition,
+		},
+		{
+			Method:      "PUT",
+			Pattern:     inhibitionsPathAnchored,
+			HandlerFunc: s.handleReplaceInhibition,
+		},
+		{
+			Method:      "DELETE",
+			Pattern:     inhibitionsPathAnchored,
+			HandlerFunc: s.handleDeleteInhibition,
+		},
+		{
+			// Satisfy CORS checks.
+			Method:      "OPTIONS",
+			Pattern:     inhibitionsPathAnchored,
+			HandlerFunc: httpd.ServeOptions,
+		},
 	}
 
 	return s.HTTPDService.AddPreviewRoutes(s.routes)
@@ -107,7 +191,7 @@ func (s sortedTopics) Len() int               { return len(s) }
 func (s sortedTopics) Less(i int, j int) bool { return s[i].ID < s[j].ID }
 func (s sortedTopics) Swap(i int, j int)      { s[i], s[j] = s[j], s[i] }
 
-func (s *apiServer) handleListTopics(w http.ResponseWriter, r *http.Request) {
+func (s *apiServer) handleListTopics(w http.ResponseWriter, r *http.Request, user auth.User) {
 	pattern := r.URL.Query().Get("pattern")
 	if err := validatePattern(pattern); err != nil {
 		httpd.HttpError(w, fmt.Sprint("invalid pattern: ", err.Error()), true, http.StatusBadRequest)
@@ -126,6 +210,10 @@ func (s *apiServer) handleListTopics(w http.ResponseWriter, r *http.Request) {
 	}
 	list := make([]client.Topic, 0, len(states))
 	for topic, state := range states {
+		// Only list the topics the user is authorized to read.
+		if err := user.AuthorizeAction(auth.Action{Resource: topicResource(topic), Privilege: auth.ReadPrivilege}); err != nil {
+			continue
+		}
 		list = append(list, s.createClientTopic(topic, state))
 	}
 	sort.Sort(sortedTopics(list))
@@ -139,6 +227,11 @@ func (s *apiServer) handleListTopics(w http.ResponseWriter, r *http.Request) {
 	w.Write(httpd.MarshalJSON(topics, true))
 }
 
+// topicResource returns the resource that authorizes access to the topic, its events and handlers.
+func topicResource(topic string) string {
+	return httpd.APIResource(path.Join(topicsBasePath, topic))
+}
+
 func (s *apiServer) topicIDFromPath(p string) (id string) {
 	d := p
 	for d != "." {
@@ -179,19 +272,29 @@ func (s *apiServer) handleRouteTopicGet(w http.ResponseWriter, r *http.Request)
 	case pathMatch(eventPattern, p):
 		event := s.eventIDFromPath(p)
 		s.handleGetEvent(id, event, w, r)
+	case pathMatch(eventAckPattern, p):
+		event := s.eventIDFromPath(path.Dir(p))
+		s.handleGetEventAck(id, event, w, r)
 	case pathMatch(handlersPattern, p):
 		s.handleListHandlers(id, w, r)
 	case pathMatch(handlerPattern, p):
 		handler := s.handlerIDFromPath(p)
 		s.handleGetHandler(id, handler, w, r)
+	case pathMatch(historyPattern, p):
+		s.handleListHistory(id, w, r)
 	default:
 		s.handleGetTopic(id, w, r)
 	}
 }
 
-func (s *apiServer) handleRouteTopicPost(w http.ResponseWriter, r *http.Request) {
+func (s *apiServer) handleRouteTopicPost(w http.ResponseWriter, r *http.Request, user auth.User) {
 	p := strings.TrimPrefix(r.URL.Path, topicsBasePathAnchored)
 	topic := s.topicIDFromPath(p)
+	if pathMatch(eventAckPattern, p) {
+		event := s.eventIDFromPath(path.Dir(p))
+		s.handleAckEvent(topic, event, user, w, r)
+		return
+	}
 	s.handleCreateHandler(topic, w, r)
 }
 
@@ -211,7 +314,10 @@ func (s *apiServer) handleRouteTopicDelete(w http.ResponseWriter, r *http.Reques
 	p := strings.TrimPrefix(r.URL.Path, topicsBasePathAnchored)
 	topic := s.topicIDFromPath(p)
 	handler := s.handlerIDFromPath(p)
-	if topic == handler {
+	if pathMatch(eventAckPattern, p) {
+		event := s.eventIDFromPath(path.Dir(p))
+		s.handleUnackEvent(topic, event, w, r)
+	} else if topic == handler {
 		s.handleDeleteTopic(topic, w, r)
 	} else {
 		s.handleDeleteHandler(topic, handler, w, r)
@@ -227,12 +333,18 @@ func (s *apiServer) topicEventsLink(id string, r client.Relation) client.Link {
 func (s *apiServer) topicEventLink(topic, event string) client.Link {
 	return client.Link{Relation: client.Self, Href: path.Join(topicsBasePath, topic, topicEventsPath, event)}
 }
+func (s *apiServer) topicEventAckLink(topic, event string) client.Link {
+	return client.Link{Relation: client.Self, Href: path.Join(topicsBasePath, topic, topicEventsPath, event, eventAckPath)}
+}
 func (s *apiServer) topicHandlersLink(id string, r client.Relation) client.Link {
 	return client.Link{Relation: r, Href: path.Join(topicsBasePath, id, topicHandlersPath)}
 }
 func (s *apiServer) topicHandlerLink(topic, handler string) client.Link {
 	return client.Link{Relation: client.Self, Href: path.Join(topicsBasePath, topic, topicHandlersPath, handler)}
 }
+func (s *apiServer) topicHistoryLink(id string, r client.Relation) client.Link {
+	return client.Link{Relation: r, Href: path.Join(topicsBasePath, id, topicHistoryPath)}
+}
 
 func (s *apiServer) createClientTopic(topic string, state alert.TopicState) client.Topic {
 	return client.Topic{
@@ -240,8 +352,14 @@ func (s *apiServer) createClientTopic(topic string, state alert.TopicState) clie
 		Link:         s.topicLink(topic),
 		Level:        state.Level.String(),
 		Collected:    state.Collected,
+		Silenced:     state.Silenced,
+		Inhibited:    state.Inhibited,
+		Suppressed:   state.Suppressed,
+		Retried:      state.Retried,
+		Failed:       state.Failed,
 		EventsLink:   s.topicEventsLink(topic, eventsRelation),
 		HandlersLink: s.topicHandlersLink(topic, handlersRelation),
+		HistoryLink:  s.topicHistoryLink(topic, historyRelation),
 	}
 }
 
@@ -261,24 +379,61 @@ func (s *apiServer) handleGetTopic(id string, w http.ResponseWriter, r *http.Req
 	w.Write(httpd.MarshalJSON(topic, true))
 }
 
-func (s *apiServer) convertEventStateToClient(state alert.EventState) client.EventState {
-	return client.EventState{
-		Message:  state.Message,
-		Details:  state.Details,
-		Time:     state.Time,
-		Duration: client.Duration(state.Duration),
-		Level:    state.Level.String(),
+func (s *apiServer) convertEventStateToClient(topic string, state alert.EventState) client.EventState {
+	cs := client.EventState{
+		Message:     state.Message,
+		Details:     state.Details,
+		Time:        state.Time,
+		Duration:    client.Duration(state.Duration),
+		Level:       state.Level.String(),
+		Inhibited:   state.Inhibited,
+		Annotations: state.Annotations,
+	}
+	if state.Ack != nil {
+		ack := s.convertEventAckToClient(topic, state.ID, *state.Ack)
+		cs.Ack = &ack
+	}
+	return cs
+}
+
+func (s *apiServer) convertEventAckToClient(topic, event string, ack alert.Ack) client.EventAck {
+	ca := client.EventAck{
+		Link:    s.topicEventAckLink(topic, event),
+		By:      ack.By,
+		Comment: ack.Comment,
+		Time:    ack.Time,
+	}
+	if !ack.Expires.IsZero() {
+		expires := ack.Expires
+		ca.Expires = &expires
 	}
+	return ca
 }
 
 func (s *apiServer) convertHandlerSpec(spec HandlerSpec) client.TopicHandler {
-	return client.TopicHandler{
+	h := client.TopicHandler{
 		Link:    s.topicHandlerLink(spec.Topic, spec.ID),
 		ID:      spec.ID,
 		Kind:    spec.Kind,
 		Options: spec.Options,
 		Match:   spec.Match,
 	}
+	if spec.Retry != nil {
+		h.Retry = &client.HandlerRetry{
+			Attempts:        spec.Retry.Attempts,
+			Backoff:         client.Duration(spec.Retry.Backoff),
+			MaxBackoff:      client.Duration(spec.Retry.MaxBackoff),
+			DeadLetterTopic: spec.Retry.DeadLetterTopic,
+		}
+	}
+	if spec.RateLimit != nil {
+		h.RateLimit = &client.HandlerRateLimit{
+			Count:    spec.RateLimit.Count,
+			Interval: client.Duration(spec.RateLimit.Interval),
+			Message:  spec.RateLimit.Message,
+		}
+	}
+	return h
 }
 
 func (s *apiServer) handleListEvents(topic string, w http.ResponseWriter, r *http.Request) {
@@ -302,13 +457,102 @@ func (s *apiServer) handleListEvents(topic string, w http.ResponseWriter, r *htt
 		res.Events = append(res.Events, client.TopicEvent{
 			Link:  s.topicEventLink(topic, id),
 			ID:    id,
-			State: s.convertEventStateToClient(state),
+			State: s.convertEventStateToClient(topic, state),
 		})
 	}
 	w.WriteHeader(http.StatusOK)
 	w.Write(httpd.MarshalJSON(res, true))
 }
 
+func (s *apiServer) handleListHistory(topic string, w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	q := HistoryQuery{
+		EventID: params.Get("event"),
+		Limit:   100,
+	}
+	if q.EventID != "" {
+		if err := validatePattern(q.EventID); err != nil {
+			httpd.HttpError(w, fmt.Sprintf("invalid event pattern %q: %v", q.EventID, err), true, http.StatusBadRequest)
+			return
+		}
+	}
+	minLevel, err := alert.ParseLevel(params.Get("min-level"))
+	if err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusBadRequest)
+		return
+	}
+	q.MinLevel = minLevel
+	for _, tag := range params["tag"] {
+		parts := strings.SplitN(tag, "=", 2)
+		if len(parts) != 2 {
+			httpd.HttpError(w, fmt.Sprintf("invalid tag parameter %q must be of the form key=value", tag), true, http.StatusBadRequest)
+			return
+		}
+		if err := validatePattern(parts[1]); err != nil {
+			httpd.HttpError(w, fmt.Sprintf("invalid pattern for tag %q: %v", parts[0], err), true, http.StatusBadRequest)
+			return
+		}
+		if q.Tags == nil {
+			q.Tags = make(map[string]string)
+		}
+		q.Tags[parts[0]] = parts[1]
+	}
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"since", &q.Since}, {"until", &q.Until}} {
+		if v := params.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				httpd.HttpError(w, fmt.Sprintf("invalid %s parameter %q must be an RFC3339 time: %v", p.name, v, err), true, http.StatusBadRequest)
+				return
+			}
+			*p.t = t
+		}
+	}
+	if o := params.Get("offset"); o != "" {
+		i, err := strconv.ParseInt(o, 10, 64)
+		if err != nil {
+			httpd.HttpError(w, fmt.Sprintf("invalid offset parameter %q must be an integer: %s", o, err), true, http.StatusBadRequest)
+			return
+		}
+		q.Offset = int(i)
+	}
+	if l := params.Get("limit"); l != "" {
+		i, err := strconv.ParseInt(l, 10, 64)
+		if err != nil {
+			httpd.HttpError(w, fmt.Sprintf("invalid limit parameter %q must be an integer: %s", l, err), true, http.StatusBadRequest)
+			return
+		}
+		q.Limit = int(i)
+	}
+
+	records, err := s.Topics.History(topic, q)
+	if err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to get topic history: %s", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	res := client.TopicHistory{
+		Link:    s.topicHistoryLink(topic, client.Self),
+		Topic:   topic,
+		Records: make([]client.HistoryRecord, len(records)),
+	}
+	for i, record := range records {
+		res.Records[i] = client.HistoryRecord{
+			ID:       record.ID,
+			EventID:  record.EventID,
+			Message:  record.Message,
+			Time:     record.Time,
+			Duration: client.Duration(record.Duration),
+			Level:    record.Level.String(),
+			Previous: record.Previous.String(),
+			Tags:     record.Tags,
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(res, true))
+}
+
 func (s *apiServer) handleGetEvent(topic, eventID string, w http.ResponseWriter, r *http.Request) {
 	state, ok, err := s.Topics.EventState(topic, eventID)
 	if err != nil {
@@ -322,12 +566,82 @@ func (s *apiServer) handleGetEvent(topic, eventID string, w http.ResponseWriter,
 	event := client.TopicEvent{
 		Link:  s.topicEventLink(topic, eventID),
 		ID:    eventID,
-		State: s.convertEventStateToClient(state),
+		State: s.convertEventStateToClient(topic, state),
 	}
 	w.WriteHeader(http.StatusOK)
 	w.Write(httpd.MarshalJSON(event, true))
 }
 
+func (s *apiServer) handleGetEventAck(topic, eventID string, w http.ResponseWriter, r *http.Request) {
+	state, ok, err := s.Topics.EventState(topic, eventID)
+	if err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to get event state: %s", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		httpd.HttpError(w, fmt.Sprintf("unknown event %q in topic %q", eventID, topic), true, http.StatusNotFound)
+		return
+	}
+	if state.Ack == nil {
+		httpd.HttpError(w, fmt.Sprintf("event %q in topic %q is not acknowledged", eventID, topic), true, http.StatusNotFound)
+		return
+	}
+	ack := s.convertEventAckToClient(topic, eventID, *state.Ack)
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(ack, true))
+}
+
+func (s *apiServer) handleAckEvent(topic, eventID string, user auth.User, w http.ResponseWriter, r *http.Request) {
+	opt := client.EventAckOptions{}
+	if err := json.NewDecoder(r.Body).Decode(&opt); err != nil && err != io.EOF {
+		httpd.HttpError(w, fmt.Sprint("invalid acknowledgement json: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	if opt.Timeout < 0 {
+		httpd.HttpError(w, "acknowledgement timeout cannot be negative", true, http.StatusBadRequest)
+		return
+	}
+	if _, ok, err := s.Topics.EventState(topic, eventID); err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to get event state: %s", err.Error()), true, http.StatusInternalServerError)
+		return
+	} else if !ok {
+		httpd.HttpError(w, fmt.Sprintf("unknown event %q in topic %q", eventID, topic), true, http.StatusNotFound)
+		return
+	}
+
+	now := time.Now().UTC()
+	ack := alert.Ack{
+		By:      user.Name(),
+		Comment: opt.Comment,
+		Time:    now,
+	}
+	if opt.Timeout > 0 {
+		ack.Expires = now.Add(time.Duration(opt.Timeout))
+	}
+	if err := s.Topics.AckEvent(topic, eventID, ack); err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to acknowledge event: ", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	ca := s.convertEventAckToClient(topic, eventID, ack)
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(ca, true))
+}
+
+func (s *apiServer) handleUnackEvent(topic, eventID string, w http.ResponseWriter, r *http.Request) {
+	if _, ok, err := s.Topics.EventState(topic, eventID); err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to get event state: %s", err.Error()), true, http.StatusInternalServerError)
+		return
+	} else if !ok {
+		httpd.HttpError(w, fmt.Sprintf("unknown event %q in topic %q", eventID, topic), true, http.StatusNotFound)
+		return
+	}
+	if err := s.Topics.UnackEvent(topic, eventID); err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to remove acknowledgement: ", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (s *apiServer) handleListHandlers(topic string, w http.ResponseWriter, r *http.Request) {
 	pattern := r.URL.Query().Get("pattern")
 	if err := validatePattern(pattern); err != nil {
@@ -502,3 +816,269 @@ func (s *apiServer) handleGetHandler(topic, handler string, w http.ResponseWrite
 	w.WriteHeader(http.StatusOK)
 	w.Write(httpd.MarshalJSON(h, true))
 }
+
+func (s *apiServer) silenceLink(id string) client.Link {
+	return client.Link{Relation: client.Self, Href: path.Join(silencesBasePath, id)}
+}
+
+func (s *apiServer) convertSilence(sl Silence) client.Silence {
+	return client.Silence{
+		Link:     s.silenceLink(sl.ID),
+		ID:       sl.ID,
+		Topic:    sl.Topic,
+		EventID:  sl.EventID,
+		Tags:     sl.Tags,
+		Start:    sl.Start,
+		End:      sl.End,
+		Schedule: sl.Schedule,
+		Duration: client.Duration(sl.Duration),
+		Comment:  sl.Comment,
+		Active:   s.Silences.SilenceActive(sl.ID),
+	}
+}
+
+func (s *apiServer) silenceFromJSON(r io.Reader) (Silence, error) {
+	opt := client.SilenceOptions{}
+	if err := json.NewDecoder(r).Decode(&opt); err != nil {
+		return Silence{}, err
+	}
+	return Silence{
+		ID:       opt.ID,
+		Topic:    opt.Topic,
+		EventID:  opt.EventID,
+		Tags:     opt.Tags,
+		Start:    opt.Start,
+		End:      opt.End,
+		Schedule: opt.Schedule,
+		Duration: time.Duration(opt.Duration),
+		Comment:  opt.Comment,
+	}, nil
+}
+
+type sortedSilences []client.Silence
+
+func (s sortedSilences) Len() int               { return len(s) }
+func (s sortedSilences) Less(i int, j int) bool { return s[i].ID < s[j].ID }
+func (s sortedSilences) Swap(i int, j int)      { s[i], s[j] = s[j], s[i] }
+
+func (s *apiServer) handleListSilences(w http.ResponseWriter, r *http.Request) {
+	pattern := r.URL.Query().Get("pattern")
+	if err := validatePattern(pattern); err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid pattern: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	silences := s.Silences.Silences(pattern)
+	list := make([]client.Silence, len(silences))
+	for i, sl := range silences {
+		list[i] = s.convertSilence(sl)
+	}
+	sort.Sort(sortedSilences(list))
+
+	res := client.Silences{
+		Link:     client.Link{Relation: client.Self, Href: r.URL.String()},
+		Silences: list,
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(res, true))
+}
+
+func (s *apiServer) handleCreateSilence(w http.ResponseWriter, r *http.Request) {
+	sl, err := s.silenceFromJSON(r.Body)
+	if err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid silence json: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	if sl.ID == "" {
+		sl.ID = uuid.New().String()
+	}
+	if err := sl.Validate(); err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid silence: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	if err := s.Silences.CreateSilence(sl); err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to create silence: ", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(s.convertSilence(sl), true))
+}
+
+func (s *apiServer) handleGetSilence(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, silencesBasePathAnchored)
+	sl, ok := s.Silences.Silence(id)
+	if !ok {
+		httpd.HttpError(w, fmt.Sprintf("unknown silence: %q", id), true, http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(s.convertSilence(sl), true))
+}
+
+func (s *apiServer) handleReplaceSilence(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, silencesBasePathAnchored)
+	if _, ok := s.Silences.Silence(id); !ok {
+		httpd.HttpError(w, fmt.Sprintf("unknown silence: %q", id), true, http.StatusNotFound)
+		return
+	}
+	sl, err := s.silenceFromJSON(r.Body)
+	if err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid silence json: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	if sl.ID != "" && sl.ID != id {
+		httpd.HttpError(w, "cannot change the ID of a silence", true, http.StatusBadRequest)
+		return
+	}
+	sl.ID = id
+	if err := sl.Validate(); err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid silence: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	if err := s.Silences.ReplaceSilence(sl); err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to update silence: ", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(s.convertSilence(sl), true))
+}
+
+func (s *apiServer) handleDeleteSilence(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, silencesBasePathAnchored)
+	if err := s.Silences.DeleteSilence(id); err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to delete silence: ", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *apiServer) inhibitionLink(id string) client.Link {
+	return client.Link{Relation: client.Self, Href: path.Join(inhibitionsBasePath, id)}
+}
+
+func (s *apiServer) convertInhibition(in Inhibition) client.Inhibition {
+	return client.Inhibition{
+		Link:         s.inhibitionLink(in.ID),
+		ID:           in.ID,
+		SourceTopic:  in.SourceTopic,
+		SourceTags:   in.SourceTags,
+		Level:        in.Level.String(),
+		TargetTopics: in.TargetTopics,
+		Equal:        in.Equal,
+	}
+}
+
+func (s *apiServer) inhibitionFromJSON(r io.Reader) (Inhibition, error) {
+	opt := client.InhibitionOptions{}
+	if err := json.NewDecoder(r).Decode(&opt); err != nil {
+		return Inhibition{}, err
+	}
+	level := alert.Critical
+	if opt.Level != "" {
+		l, err := alert.ParseLevel(opt.Level)
+		if err != nil {
+			return Inhibition{}, err
+		}
+		level = l
+	}
+	return Inhibition{
+		ID:           opt.ID,
+		SourceTopic:  opt.SourceTopic,
+		SourceTags:   opt.SourceTags,
+		Level:        level,
+		TargetTopics: opt.TargetTopics,
+		Equal:        opt.Equal,
+	}, nil
+}
+
+type sortedInhibitions []client.Inhibition
+
+func (s sortedInhibitions) Len() int               { return len(s) }
+func (s sortedInhibitions) Less(i int, j int) bool { return s[i].ID < s[j].ID }
+func (s sortedInhibitions) Swap(i int, j int)      { s[i], s[j] = s[j], s[i] }
+
+func (s *apiServer) handleListInhibitions(w http.ResponseWriter, r *http.Request) {
+	pattern := r.URL.Query().Get("pattern")
+	if err := validatePattern(pattern); err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid pattern: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	inhibitions := s.Inhibitions.Inhibitions(pattern)
+	list := make([]client.Inhibition, len(inhibitions))
+	for i, in := range inhibitions {
+		list[i] = s.convertInhibition(in)
+	}
+	sort.Sort(sortedInhibitions(list))
+
+	res := client.Inhibitions{
+		Link:        client.Link{Relation: client.Self, Href: r.URL.String()},
+		Inhibitions: list,
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(res, true))
+}
+
+func (s *apiServer) handleCreateInhibition(w http.ResponseWriter, r *http.Request) {
+	in, err := s.inhibitionFromJSON(r.Body)
+	if err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid inhibition json: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	if err := in.Validate(); err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid inhibition: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	if err := s.Inhibitions.CreateInhibition(in); err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to create inhibition: ", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(s.convertInhibition(in), true))
+}
+
+func (s *apiServer) handleGetInhibition(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, inhibitionsBasePathAnchored)
+	in, ok := s.Inhibitions.Inhibition(id)
+	if !ok {
+		httpd.HttpError(w, fmt.Sprintf("unknown inhibition: %q", id), true, http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(s.convertInhibition(in), true))
+}
+
+func (s *apiServer) handleReplaceInhibition(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, inhibitionsBasePathAnchored)
+	if _, ok := s.Inhibitions.Inhibition(id); !ok {
+		httpd.HttpError(w, fmt.Sprintf("unknown inhibition: %q", id), true, http.StatusNotFound)
+		return
+	}
+	in, err := s.inhibitionFromJSON(r.Body)
+	if err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid inhibition json: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	if in.ID != "" && in.ID != id {
+		httpd.HttpError(w, "cannot change the ID of an inhibition", true, http.StatusBadRequest)
+		return
+	}
+	in.ID = id
+	if err := in.Validate(); err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid inhibition: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	if err := s.Inhibitions.ReplaceInhibition(in); err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to update inhibition: ", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(s.convertInhibition(in), true))
+}
+
+func (s *apiServer) handleDeleteInhibition(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, inhibitionsBasePathAnchored)
+	if err := s.Inhibitions.DeleteInhibition(id); err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to delete inhibition: ", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,46 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// Default duration the history of alert events is kept.
+	DefaultHistoryRetention = toml.Duration(30 * 24 * time.Hour)
+	// Default maximum number of records in the history of alert events.
+	DefaultHistoryMaxRecords = 100000
+)
+
+type Config struct {
+	// How long the history of alert events is kept, zero keeps the history forever.
+	HistoryRetention toml.Duration `toml:"history-retention"`
+	// Maximum number of records in the history, the oldest records are deleted first.
+	// Zero does not limit the number of records.
+	HistoryMaxRecords int `toml:"history-max-records"`
+	// Default topic that receives events which handlers failed to handle after all retries.
+	// Empty discards the events, unless the handler defines a dead-letter topic.
+	DeadLetterTopic string `toml:"dead-letter-topic"`
+}
+
+func NewConfig() Config {
+	return Config{
+		HistoryRetention:  DefaultHistoryRetention,
+		HistoryMaxRecords: DefaultHistoryMaxRecords,
+	}
+}
+
+func (c Config) Validate() error {
+	if c.HistoryRetention < 0 {
+		return fmt.Errorf("history-retention cannot be negative")
+	}
+	if c.HistoryMaxRecords < 0 {
+		return fmt.Errorf("history-max-records cannot be negative")
+	}
+	if c.DeadLetterTopic != "" && !validTopicID.MatchString(c.DeadLetterTopic) {
+		return fmt.Errorf("dead-letter-topic must contain only letters, numbers, '-', '.' and '_'. %q", c.DeadLetterTopic)
+	}
+	return nil
+}
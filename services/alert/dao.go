@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"path"
 	"regexp"
+	"strings"
 	"time"
 
+	"github.com/gorhill/cronexpr"
+	"github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/models"
 	"github.com/influxdata/kapacitor/services/storage"
 	"github.com/pkg/errors"
 )
@@ -69,6 +73,63 @@ type HandlerSpec struct {
 	Kind    string                 `json:"kind"`
 	Options map[string]interface{} `json:"options"`
 	Match   string                 `json:"match"`
+	// Retry defines how failed events are retried, nil does not retry failed events.
+	Retry *RetrySpec `json:"retry,omitempty"`
+	// RateLimit defines how many events the handler handles per interval, nil does not limit events.
+	RateLimit *RateLimitSpec `json:"rate-limit,omitempty"`
+}
+
+// RetrySpec defines how a handler retries events it failed to handle.
+type RetrySpec struct {
+	// Number of retries after the first attempt failed.
+	Attempts int `json:"attempts"`
+	// Delay before the first retry, doubled for each following retry.
+	Backoff toml.Duration `json:"backoff"`
+	// Maximum delay between retries, zero does not limit the delay.
+	MaxBackoff toml.Duration `json:"max-backoff"`
+	// Topic that receives events which failed all retries.
+	// Empty uses the default dead-letter topic of the alert service.
+	DeadLetterTopic string `json:"dead-letter-topic"`
+}
+
+func (r RetrySpec) Validate() error {
+	if r.Attempts < 0 {
+		return errors.New("retry attempts cannot be negative")
+	}
+	if r.Backoff < 0 || r.MaxBackoff < 0 {
+		return errors.New("retry backoff cannot be negative")
+	}
+	if r.DeadLetterTopic != "" && !validTopicID.MatchString(r.DeadLetterTopic) {
+		return fmt.Errorf("dead-letter topic must contain only letters, numbers, '-', '.' and '_'. %q", r.DeadLetterTopic)
+	}
+	return nil
+}
+
+// RateLimitSpec limits the number of events a handler handles per interval.
+// Events over the limit are suppressed and folded into a digest event,
+// which is handled at the end of the interval.
+type RateLimitSpec struct {
+	// Number of events handled per interval.
+	Count    int           `json:"count"`
+	Interval toml.Duration `json:"interval"`
+	// Template of the message of the digest event.
+	// Empty uses the default message.
+	Message string `json:"message"`
+}
+
+func (r RateLimitSpec) Validate() error {
+	if r.Count < 0 {
+		return errors.New("rate limit count cannot be negative")
+	}
+	if r.Interval <= 0 {
+		return errors.New("rate limit interval must be positive")
+	}
+	if r.Message != "" {
+		if _, err := newDigestTemplate(r.Message); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 var validHandlerID = regexp.MustCompile(`^[-\._\p{L}0-9]+$`)
@@ -84,6 +145,24 @@ func (h HandlerSpec) Validate() error {
 	if h.Kind == "" {
 		return errors.New("handler Kind must not be empty")
 	}
+	if h.Match != "" {
+		if _, _, err := parseMatch(h.Match); err != nil {
+			return err
+		}
+	}
+	if h.Retry != nil {
+		if err := h.Retry.Validate(); err != nil {
+			return err
+		}
+		if h.Retry.DeadLetterTopic == h.Topic {
+			return errors.New("dead-letter topic must not be the topic of the handler")
+		}
+	}
+	if h.RateLimit != nil {
+		if err := h.RateLimit.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -249,11 +328,23 @@ type TopicState struct {
 }
 
 type EventState struct {
-	Message  string        `json:"message"`
-	Details  string        `json:"details"`
-	Time     time.Time     `json:"time"`
-	Duration time.Duration `json:"duration"`
-	Level    alert.Level   `json:"level"`
+	Message   string        `json:"message"`
+	Details   string        `json:"details"`
+	Time      time.Time     `json:"time"`
+	Duration  time.Duration `json:"duration"`
+	Level     alert.Level   `json:"level"`
+	Ack       *Ack          `json:"ack,omitempty"`
+	Inhibited bool          `json:"inhibited,omitempty"`
+
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+type Ack struct {
+	By      string    `json:"by"`
+	Comment string    `json:"comment"`
+	Time    time.Time `json:"time"`
+	Expires time.Time `json:"expires"`
 }
 
 func (t TopicState) ObjectID() string {
@@ -338,3 +429,572 @@ func (kv *topicStateKV) List(pattern string, offset, limit int) ([]TopicState, e
 func (kv *topicStateKV) Rebuild() error {
 	return kv.store.Rebuild()
 }
+
+var (
+	ErrSilenceExists   = errors.New("silence already exists")
+	ErrNoSilenceExists = errors.New("no silence exists")
+)
+
+// Data access object for Silence data.
+type SilenceDAO interface {
+	// Retrieve a silence
+	Get(id string) (Silence, error)
+
+	// Create a silence.
+	// ErrSilenceExists is returned if a silence already exists with the same ID.
+	Create(s Silence) error
+
+	// Replace an existing silence.
+	// ErrNoSilenceExists is returned if the silence does not exist.
+	Replace(s Silence) error
+
+	// Delete a silence.
+	// It is not an error to delete an non-existent silence.
+	Delete(id string) error
+
+	// List silences matching a pattern.
+	// The pattern is shell/glob matching see https://golang.org/pkg/path/#Match
+	// Offset and limit are pagination bounds. Offset is inclusive starting at index 0.
+	// More results may exist while the number of returned items is equal to limit.
+	List(pattern string, offset, limit int) ([]Silence, error)
+
+	Rebuild() error
+}
+
+const silenceVersion = 1
+
+// Silence prevents matching events from being sent to handlers while it is active.
+// All of the non-empty matchers must match an event for it to be silenced.
+type Silence struct {
+	ID string `json:"id"`
+	// Pattern matching the topic of the event.
+	Topic string `json:"topic"`
+	// Pattern matching the ID of the event.
+	EventID string `json:"event-id"`
+	// Map of tag to a pattern matching the tag value of the event.
+	Tags map[string]string `json:"tags"`
+	// The silence is active between start and end, zero values are unbounded.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Cron expression of a recurring schedule, the silence is active
+	// for Duration after each time in the schedule.
+	Schedule string        `json:"schedule"`
+	Duration time.Duration `json:"duration"`
+	Comment  string        `json:"comment"`
+}
+
+var validSilenceID = validHandlerID
+
+func (s Silence) Validate() error {
+	if !validSilenceID.MatchString(s.ID) {
+		return fmt.Errorf("silence ID must contain only letters, numbers, '-', '.' and '_'. %q", s.ID)
+	}
+	if s.Topic == "" && s.EventID == "" && len(s.Tags) == 0 {
+		return errors.New("silence must match on at least one of topic, event ID or tags")
+	}
+	if err := validatePattern(s.Topic); err != nil {
+		return errors.Wrap(err, "invalid topic pattern")
+	}
+	if err := validatePattern(s.EventID); err != nil {
+		return errors.Wrap(err, "invalid event ID pattern")
+	}
+	for tag, pattern := range s.Tags {
+		if err := validatePattern(pattern); err != nil {
+			return errors.Wrapf(err, "invalid pattern for tag %q", tag)
+		}
+	}
+	if !s.Start.IsZero() && !s.End.IsZero() && !s.End.After(s.Start) {
+		return errors.New("silence end must be after start")
+	}
+	if s.Schedule != "" {
+		if _, err := cronexpr.Parse(s.Schedule); err != nil {
+			return errors.Wrap(err, "invalid schedule")
+		}
+		if s.Duration <= 0 {
+			return errors.New("silence duration must be positive for a schedule")
+		}
+	} else if s.Duration != 0 {
+		return errors.New("silence duration is only valid with a schedule")
+	}
+	return nil
+}
+
+func (s Silence) ObjectID() string {
+	return s.ID
+}
+
+func (s Silence) MarshalBinary() ([]byte, error) {
+	return storage.VersionJSONEncode(silenceVersion, s)
+}
+
+func (s *Silence) UnmarshalBinary(data []byte) error {
+	return storage.VersionJSONDecode(data, func(version int, dec *json.Decoder) error {
+		return dec.Decode(s)
+	})
+}
+
+// Key/Value store based implementation of the SilenceDAO
+type silenceKV struct {
+	store *storage.IndexedStore
+}
+
+func newSilenceKV(store storage.Interface) (*silenceKV, error) {
+	c := storage.DefaultIndexedStoreConfig("silences", func() storage.BinaryObject {
+		return new(Silence)
+	})
+	istore, err := storage.NewIndexedStore(store, c)
+	if err != nil {
+		return nil, err
+	}
+	return &silenceKV{
+		store: istore,
+	}, nil
+}
+
+func (kv *silenceKV) error(err error) error {
+	if err == storage.ErrObjectExists {
+		return ErrSilenceExists
+	} else if err == storage.ErrNoObjectExists {
+		return ErrNoSilenceExists
+	}
+	return err
+}
+
+func (kv *silenceKV) Get(id string) (Silence, error) {
+	o, err := kv.store.Get(id)
+	if err != nil {
+		return Silence{}, kv.error(err)
+	}
+	s, ok := o.(*Silence)
+	if !ok {
+		return Silence{}, storage.ImpossibleTypeErr(s, o)
+	}
+	return *s, nil
+}
+
+func (kv *silenceKV) Create(s Silence) error {
+	return kv.error(kv.store.Create(&s))
+}
+
+func (kv *silenceKV) Replace(s Silence) error {
+	return kv.error(kv.store.Replace(&s))
+}
+
+func (kv *silenceKV) Delete(id string) error {
+	return kv.error(kv.store.Delete(id))
+}
+
+func (kv *silenceKV) List(pattern string, offset, limit int) ([]Silence, error) {
+	objects, err := kv.store.List(storage.DefaultIDIndex, pattern, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	silences := make([]Silence, len(objects))
+	for i, o := range objects {
+		s, ok := o.(*Silence)
+		if !ok {
+			return nil, storage.ImpossibleTypeErr(s, o)
+		}
+		silences[i] = *s
+	}
+	return silences, nil
+}
+
+func (kv *silenceKV) Rebuild() error {
+	return kv.store.Rebuild()
+}
+
+var (
+	ErrInhibitionExists   = errors.New("inhibition already exists")
+	ErrNoInhibitionExists = errors.New("no inhibition exists")
+)
+
+// Data access object for Inhibition data.
+type InhibitionDAO interface {
+	// Retrieve an inhibition
+	Get(id string) (Inhibition, error)
+
+	// Create an inhibition.
+	// ErrInhibitionExists is returned if an inhibition already exists with the same ID.
+	Create(i Inhibition) error
+
+	// Replace an existing inhibition.
+	// ErrNoInhibitionExists is returned if the inhibition does not exist.
+	Replace(i Inhibition) error
+
+	// Delete an inhibition.
+	// It is not an error to delete an non-existent inhibition.
+	Delete(id string) error
+
+	// List inhibitions matching a pattern.
+	// The pattern is shell/glob matching see https://golang.org/pkg/path/#Match
+	// Offset and limit are pagination bounds. Offset is inclusive starting at index 0.
+	// More results may exist while the number of returned items is equal to limit.
+	List(pattern string, offset, limit int) ([]Inhibition, error)
+
+	Rebuild() error
+}
+
+const inhibitionVersion = 1
+
+// Inhibition is a rule that prevents events of the target topics from being sent to handlers,
+// while a matching event of the source topic is at or above the level.
+type Inhibition struct {
+	ID string `json:"id"`
+	// Pattern matching the topic of source events.
+	SourceTopic string `json:"source-topic"`
+	// Map of tag to a pattern matching the tag value of source events.
+	SourceTags map[string]string `json:"source-tags"`
+	// Minimum level of source events.
+	Level alert.Level `json:"level"`
+	// Patterns matching the topics of inhibited events.
+	TargetTopics []string `json:"target-topics"`
+	// Tags that must have the same value on the source and the inhibited events.
+	Equal []string `json:"equal"`
+}
+
+var validInhibitionID = validHandlerID
+
+func (i Inhibition) Validate() error {
+	if !validInhibitionID.MatchString(i.ID) {
+		return fmt.Errorf("inhibition ID must contain only letters, numbers, '-', '.' and '_'. %q", i.ID)
+	}
+	if i.SourceTopic == "" {
+		return errors.New("inhibition must have a source topic")
+	}
+	if err := validatePattern(i.SourceTopic); err != nil {
+		return errors.Wrap(err, "invalid source topic pattern")
+	}
+	for tag, pattern := range i.SourceTags {
+		if err := validatePattern(pattern); err != nil {
+			return errors.Wrapf(err, "invalid pattern for source tag %q", tag)
+		}
+	}
+	if i.Level == alert.OK {
+		return errors.New("inhibition level must be one of INFO, WARNING or CRITICAL")
+	}
+	if len(i.TargetTopics) == 0 {
+		return errors.New("inhibition must have at least one target topic")
+	}
+	for _, pattern := range i.TargetTopics {
+		if err := validatePattern(pattern); err != nil {
+			return errors.Wrap(err, "invalid target topic pattern")
+		}
+	}
+	return nil
+}
+
+func (i Inhibition) ObjectID() string {
+	return i.ID
+}
+
+func (i Inhibition) MarshalBinary() ([]byte, error) {
+	return storage.VersionJSONEncode(inhibitionVersion, i)
+}
+
+func (i *Inhibition) UnmarshalBinary(data []byte) error {
+	return storage.VersionJSONDecode(data, func(version int, dec *json.Decoder) error {
+		return dec.Decode(i)
+	})
+}
+
+// Key/Value store based implementation of the InhibitionDAO
+type inhibitionKV struct {
+	store *storage.IndexedStore
+}
+
+func newInhibitionKV(store storage.Interface) (*inhibitionKV, error) {
+	c := storage.DefaultIndexedStoreConfig("inhibitions", func() storage.BinaryObject {
+		return new(Inhibition)
+	})
+	istore, err := storage.NewIndexedStore(store, c)
+	if err != nil {
+		return nil, err
+	}
+	return &inhibitionKV{
+		store: istore,
+	}, nil
+}
+
+func (kv *inhibitionKV) error(err error) error {
+	if err == storage.ErrObjectExists {
+		return ErrInhibitionExists
+	} else if err == storage.ErrNoObjectExists {
+		return ErrNoInhibitionExists
+	}
+	return err
+}
+
+func (kv *inhibitionKV) Get(id string) (Inhibition, error) {
+	o, err := kv.store.Get(id)
+	if err != nil {
+		return Inhibition{}, kv.error(err)
+	}
+	i, ok := o.(*Inhibition)
+	if !ok {
+		return Inhibition{}, storage.ImpossibleTypeErr(i, o)
+	}
+	return *i, nil
+}
+
+func (kv *inhibitionKV) Create(i Inhibition) error {
+	return kv.error(kv.store.Create(&i))
+}
+
+func (kv *inhibitionKV) Replace(i Inhibition) error {
+	return kv.error(kv.store.Replace(&i))
+}
+
+func (kv *inhibitionKV) Delete(id string) error {
+	return kv.error(kv.store.Delete(id))
+}
+
+func (kv *inhibitionKV) List(pattern string, offset, limit int) ([]Inhibition, error) {
+	objects, err := kv.store.List(storage.DefaultIDIndex, pattern, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	inhibitions := make([]Inhibition, len(objects))
+	for j, o := range objects {
+		i, ok := o.(*Inhibition)
+		if !ok {
+			return nil, storage.ImpossibleTypeErr(i, o)
+		}
+		inhibitions[j] = *i
+	}
+	return inhibitions, nil
+}
+
+func (kv *inhibitionKV) Rebuild() error {
+	return kv.store.Rebuild()
+}
+
+// Data access object for Escalation data.
+type EscalationDAO interface {
+	// Put an escalation, replacing any existing escalation of the same event.
+	Put(e Escalation) error
+
+	// Delete an escalation.
+	// It is not an error to delete an non-existent escalation.
+	Delete(id string) error
+
+	// List escalations.
+	// Offset and limit are pagination bounds. Offset is inclusive starting at index 0.
+	// More results may exist while the number of returned items is equal to limit.
+	List(offset, limit int) ([]Escalation, error)
+
+	Rebuild() error
+}
+
+const escalationVersion = 1
+
+// Escalation is the pending escalation of an event by an escalate handler.
+type Escalation struct {
+	Topic   string `json:"topic"`
+	Handler string `json:"handler"`
+	EventID string `json:"event-id"`
+	// Time the escalation started.
+	Start time.Time `json:"start"`
+	// Number of stages that have been notified.
+	Stages int `json:"stages"`
+	// The event as it was last handled.
+	State      EventState             `json:"state"`
+	Name       string                 `json:"name"`
+	TaskName   string                 `json:"task-name"`
+	Group      string                 `json:"group"`
+	Tags       map[string]string      `json:"tags"`
+	Fields     map[string]interface{} `json:"fields"`
+	Result     models.Result          `json:"result"`
+	NoExternal bool                   `json:"no-external"`
+}
+
+func escalationID(topic, handler, event string) string {
+	return path.Join(topic, handler, event)
+}
+
+func (e Escalation) ObjectID() string {
+	return escalationID(e.Topic, e.Handler, e.EventID)
+}
+
+func (e Escalation) MarshalBinary() ([]byte, error) {
+	return storage.VersionJSONEncode(escalationVersion, e)
+}
+
+func (e *Escalation) UnmarshalBinary(data []byte) error {
+	return storage.VersionJSONDecode(data, func(version int, dec *json.Decoder) error {
+		return dec.Decode(e)
+	})
+}
+
+// Key/Value store based implementation of the EscalationDAO
+type escalationKV struct {
+	store *storage.IndexedStore
+}
+
+func newEscalationKV(store storage.Interface) (*escalationKV, error) {
+	c := storage.DefaultIndexedStoreConfig("escalations", func() storage.BinaryObject {
+		return new(Escalation)
+	})
+	istore, err := storage.NewIndexedStore(store, c)
+	if err != nil {
+		return nil, err
+	}
+	return &escalationKV{
+		store: istore,
+	}, nil
+}
+
+func (kv *escalationKV) Put(e Escalation) error {
+	return kv.store.Put(&e)
+}
+
+func (kv *escalationKV) Delete(id string) error {
+	return kv.store.Delete(id)
+}
+
+func (kv *escalationKV) List(offset, limit int) ([]Escalation, error) {
+	objects, err := kv.store.List(storage.DefaultIDIndex, "", offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	escalations := make([]Escalation, len(objects))
+	for i, o := range objects {
+		e, ok := o.(*Escalation)
+		if !ok {
+			return nil, storage.ImpossibleTypeErr(e, o)
+		}
+		escalations[i] = *e
+	}
+	return escalations, nil
+}
+
+func (kv *escalationKV) Rebuild() error {
+	return kv.store.Rebuild()
+}
+
+// Data access object for HistoryRecord data.
+type HistoryDAO interface {
+	// Create a record.
+	Create(r HistoryRecord) error
+
+	// Delete a record.
+	// It is not an error to delete an non-existent record.
+	Delete(id string) error
+
+	// List records of all topics ordered by time, oldest first.
+	// Offset and limit are pagination bounds. Offset is inclusive starting at index 0.
+	// More results may exist while the number of returned items is equal to limit.
+	List(offset, limit int) ([]HistoryRecord, error)
+
+	// ReverseList lists records of all topics ordered by time, newest first.
+	ReverseList(offset, limit int) ([]HistoryRecord, error)
+
+	// ReverseListTopic lists records of a topic ordered by time, newest first.
+	ReverseListTopic(topic string, offset, limit int) ([]HistoryRecord, error)
+
+	Rebuild() error
+}
+
+const historyRecordVersion = 1
+
+// HistoryRecord is a transition of the level of an event.
+type HistoryRecord struct {
+	// ID of the record of the form <topic>/<time>-<sequence>,
+	// IDs of a topic sort in the order of the time of the events.
+	ID       string            `json:"id"`
+	Topic    string            `json:"topic"`
+	EventID  string            `json:"event-id"`
+	Message  string            `json:"message"`
+	Time     time.Time         `json:"time"`
+	Duration time.Duration     `json:"duration"`
+	Level    alert.Level       `json:"level"`
+	Previous alert.Level       `json:"previous"`
+	Tags     map[string]string `json:"tags"`
+}
+
+func (r HistoryRecord) ObjectID() string {
+	return r.ID
+}
+
+func (r HistoryRecord) MarshalBinary() ([]byte, error) {
+	return storage.VersionJSONEncode(historyRecordVersion, r)
+}
+
+func (r *HistoryRecord) UnmarshalBinary(data []byte) error {
+	return storage.VersionJSONDecode(data, func(version int, dec *json.Decoder) error {
+		return dec.Decode(r)
+	})
+}
+
+const historyTimeIndex = "time"
+
+// Key/Value store based implementation of the HistoryDAO
+type historyKV struct {
+	store *storage.IndexedStore
+}
+
+func newHistoryKV(store storage.Interface) (*historyKV, error) {
+	c := storage.DefaultIndexedStoreConfig("history", func() storage.BinaryObject {
+		return new(HistoryRecord)
+	})
+	c.Indexes = append(c.Indexes, storage.Index{
+		Name: historyTimeIndex,
+		ValueFunc: func(o storage.BinaryObject) (string, error) {
+			r, ok := o.(*HistoryRecord)
+			if !ok {
+				return "", storage.ImpossibleTypeErr(r, o)
+			}
+			return path.Base(r.ID), nil
+		},
+	})
+	istore, err := storage.NewIndexedStore(store, c)
+	if err != nil {
+		return nil, err
+	}
+	return &historyKV{
+		store: istore,
+	}, nil
+}
+
+func (kv *historyKV) Create(r HistoryRecord) error {
+	return kv.store.Create(&r)
+}
+
+func (kv *historyKV) Delete(id string) error {
+	return kv.store.Delete(id)
+}
+
+func (kv *historyKV) List(offset, limit int) ([]HistoryRecord, error) {
+	return kv.records(kv.store.List(historyTimeIndex, "", offset, limit))
+}
+
+func (kv *historyKV) ReverseList(offset, limit int) ([]HistoryRecord, error) {
+	return kv.records(kv.store.ReverseList(historyTimeIndex, "", offset, limit))
+}
+
+func (kv *historyKV) ReverseListTopic(topic string, offset, limit int) ([]HistoryRecord, error) {
+	// IDs are prefixed with the topic, so they are sorted by topic and then by time.
+	return kv.records(kv.store.ReverseList(storage.DefaultIDIndex, historyTopicPattern.Replace(topic)+"/*", offset, limit))
+}
+
+// historyTopicPattern escapes the pattern metacharacters of a topic.
+var historyTopicPattern = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`)
+
+func (kv *historyKV) records(objects []storage.BinaryObject, err error) ([]HistoryRecord, error) {
+	if err != nil {
+		return nil, err
+	}
+	records := make([]HistoryRecord, len(objects))
+	for i, o := range objects {
+		r, ok := o.(*HistoryRecord)
+		if !ok {
+			return nil, storage.ImpossibleTypeErr(r, o)
+		}
+		records[i] = *r
+	}
+	return records, nil
+}
+
+func (kv *historyKV) Rebuild() error {
+	return kv.store.Rebuild()
+}
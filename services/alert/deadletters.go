@@ -0,0 +1,51 @@
+package alert
+
+import (
+	"fmt"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+const (
+	// Number of events buffered for the dead-letter topics.
+	deadLetterBufferSize = 1000
+)
+
+// deadLetterFunc returns a function that publishes the events that the handler failed to handle
+// to its dead-letter topic, or nil if the handler has no dead-letter topic.
+// The ID of the event is prefixed with the topic and ID of the handler,
+// and the details of the event are replaced with the error of the handler.
+func (s *Service) deadLetterFunc(spec HandlerSpec) func(alert.Event, error) {
+	topic := s.deadLetterTopic
+	if spec.Retry != nil && spec.Retry.DeadLetterTopic != "" {
+		topic = spec.Retry.DeadLetterTopic
+	}
+	if topic == "" || topic == spec.Topic {
+		// Never publish events back to the topic they failed on.
+		return nil
+	}
+	return func(event alert.Event, err error) {
+		id := event.State.ID
+		event.Topic = topic
+		event.State.ID = fmt.Sprintf("%s:%s:%s", spec.Topic, spec.ID, id)
+		event.State.Details = err.Error()
+		select {
+		case s.deadLetters <- event:
+		default:
+			s.logger.Printf("E! dropping event %q of topic %q, dead-letter topic %q is full", id, spec.Topic, topic)
+		}
+	}
+}
+
+func (s *Service) runDeadLetters() {
+	for {
+		select {
+		case event := <-s.deadLetters:
+			if err := s.Collect(event); err != nil {
+				s.logger.Printf("E! failed to collect event %q in dead-letter topic %q: %v", event.State.ID, event.Topic, err)
+			}
+		case <-s.closing:
+			return
+		}
+	}
+}
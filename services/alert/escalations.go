@@ -0,0 +1,90 @@
+package alert
+
+import (
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// Escalations returns the pending escalations of an escalate handler.
+func (s *Service) Escalations(topic, handler string) ([]PendingEscalation, error) {
+	var pending []PendingEscalation
+	offset := 0
+	limit := 100
+	for {
+		escalations, err := s.escalationsDAO.List(offset, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range escalations {
+			if e.Topic == topic && e.Handler == handler {
+				pending = append(pending, s.convertEscalationToAlert(e))
+			}
+		}
+
+		offset += limit
+		if len(escalations) != limit {
+			break
+		}
+	}
+	return pending, nil
+}
+
+func (s *Service) PutEscalation(topic, handler string, e PendingEscalation) error {
+	return s.escalationsDAO.Put(s.convertEscalationFromAlert(topic, handler, e))
+}
+
+func (s *Service) DeleteEscalation(topic, handler, event string) error {
+	return s.escalationsDAO.Delete(escalationID(topic, handler, event))
+}
+
+// deleteEscalations deletes all pending escalations of an escalate handler.
+func (s *Service) deleteEscalations(topic, handler string) error {
+	pending, err := s.Escalations(topic, handler)
+	if err != nil {
+		return err
+	}
+	for _, e := range pending {
+		if err := s.DeleteEscalation(topic, handler, e.Event.State.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) convertEscalationToAlert(e Escalation) PendingEscalation {
+	return PendingEscalation{
+		Event: alert.Event{
+			Topic: e.Topic,
+			State: s.convertEventStateToAlert(e.EventID, e.State),
+			Data: alert.EventData{
+				Name:     e.Name,
+				TaskName: e.TaskName,
+				Group:    e.Group,
+				Tags:     e.Tags,
+				Fields:   e.Fields,
+				Result:   e.Result,
+			},
+			NoExternal: e.NoExternal,
+		},
+		Start:  e.Start,
+		Stages: e.Stages,
+	}
+}
+
+func (s *Service) convertEscalationFromAlert(topic, handler string, e PendingEscalation) Escalation {
+	return Escalation{
+		Topic:      topic,
+		Handler:    handler,
+		EventID:    e.Event.State.ID,
+		Start:      e.Start,
+		Stages:     e.Stages,
+		State:      s.convertEventStateFromAlert(e.Event.State),
+		Name:       e.Event.Data.Name,
+		TaskName:   e.Event.Data.TaskName,
+		Group:      e.Event.Data.Group,
+		Tags:       e.Event.Data.Tags,
+		Fields:     e.Event.Data.Fields,
+		Result:     e.Event.Data.Result,
+		NoExternal: e.Event.NoExternal,
+	}
+}
@@ -0,0 +1,123 @@
+package alert
+
+import (
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// escalationStore is an in memory EscalationStore, the states of events are unknown.
+type escalationStore struct {
+	mu          sync.Mutex
+	escalations map[string]PendingEscalation
+}
+
+func newEscalationStore() *escalationStore {
+	return &escalationStore{escalations: make(map[string]PendingEscalation)}
+}
+
+func (s *escalationStore) EventState(topic, event string) (alert.EventState, bool, error) {
+	return alert.EventState{}, false, nil
+}
+
+func (s *escalationStore) Escalations(topic, handler string) ([]PendingEscalation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pending []PendingEscalation
+	for _, e := range s.escalations {
+		pending = append(pending, e)
+	}
+	return pending, nil
+}
+
+func (s *escalationStore) PutEscalation(topic, handler string, e PendingEscalation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.escalations[e.Event.State.ID] = e
+	return nil
+}
+
+func (s *escalationStore) DeleteEscalation(topic, handler, event string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.escalations, event)
+	return nil
+}
+
+func (s *escalationStore) Escalation(event string) (PendingEscalation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.escalations[event]
+	return e, ok
+}
+
+func newTestEscalateHandler(t *testing.T, store EscalationStore, stages ...alert.Handler) alert.Handler {
+	c := DefaultEscalateHandlerConfig()
+	c.Stages = []EscalationStageConfig{
+		{Actions: []EscalationActionConfig{{Kind: "log"}}},
+		{After: toml.Duration(30 * time.Millisecond), Actions: []EscalationActionConfig{{Kind: "log"}}},
+	}
+	handlers := make([][]alert.Handler, len(stages))
+	for i, s := range stages {
+		handlers[i] = []alert.Handler{s}
+	}
+	h, err := NewEscalateHandler("topic", "escalate", c, handlers, store, log.New(os.Stderr, "[escalate] ", log.LstdFlags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestEscalateHandler_ResumeAfterUpdate(t *testing.T) {
+	store := newEscalationStore()
+	old0, old1 := new(recordingHandler), new(recordingHandler)
+	oldH := newTestEscalateHandler(t, store, old0, old1)
+	if err := oldH.Handle(testEvent("id")); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(old0.Events()); got != 1 {
+		t.Fatalf("expected the first stage to be notified, got %d events", got)
+	}
+
+	// The old handler is closed when its spec is updated, the escalation stays pending.
+	oldH.(closer).Close()
+	time.Sleep(50 * time.Millisecond)
+	if got := len(old1.Events()); got != 0 {
+		t.Fatalf("unexpected %d events notified by a closed handler", got)
+	}
+
+	// The new handler resumes the escalation from its start, notifying the stage that is due.
+	new0, new1 := new(recordingHandler), new(recordingHandler)
+	newH := newTestEscalateHandler(t, store, new0, new1)
+	defer newH.(closer).Close()
+	for i := 0; len(new1.Events()) < 1; i++ {
+		if i > 100 {
+			t.Fatal("expected the pending stage to be notified by the new handler")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := len(new0.Events()); got != 0 {
+		t.Errorf("unexpected %d events notified again to the first stage", got)
+	}
+	if e, ok := store.Escalation("id"); !ok || e.Stages != 2 {
+		t.Errorf("unexpected pending escalation %+v, exists %t", e, ok)
+	}
+
+	// Recoveries are notified to the stages notified by either handler and end the escalation.
+	recovery := testEvent("id")
+	recovery.State.Level = alert.OK
+	if err := newH.Handle(recovery); err != nil {
+		t.Fatal(err)
+	}
+	if got0, got1 := len(new0.Events()), len(new1.Events()); got0 != 1 || got1 != 2 {
+		t.Errorf("unexpected notified events of the stages got %d, %d exp 1, 2", got0, got1)
+	}
+	if _, ok := store.Escalation("id"); ok {
+		t.Error("expected the escalation to end when the event recovers")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	text "text/template"
 	"time"
 
+	"github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/kapacitor/alert"
 	"github.com/influxdata/kapacitor/bufpool"
 	"github.com/influxdata/kapacitor/command"
@@ -24,9 +25,35 @@ import (
 // Default log mode for file
 const defaultLogFileMode = 0600
 
+// Formats of the lines written by the log handler.
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// Default template of the lines written by the log handler in the text format.
+const defaultLogTemplate = `{{ .Time.Format "2006-01-02T15:04:05.999999999Z07:00" }} {{ .Level }} {{ .ID }} {{ .Message }}`
+
 type LogHandlerConfig struct {
 	Path string      `mapstructure:"path"`
 	Mode os.FileMode `mapstructure:"mode"`
+
+	// Format of the lines, either json for the alert data as JSON or text for templated lines.
+	Format string `mapstructure:"format"`
+	// Template of the lines in the text format, executed with the same data as message templates.
+	// If empty uses a line with the time, level, ID and message of the alert.
+	Template string `mapstructure:"template"`
+
+	// Size after which the file is rotated, zero disables rotation based on size.
+	MaxSize toml.Size `mapstructure:"max-size"`
+	// Interval at which the file is rotated, zero disables rotation based on time.
+	// The file is rotated when the first event of a new interval is written,
+	// intervals of a day or less start at midnight UTC.
+	RotateEvery toml.Duration `mapstructure:"rotate-every"`
+	// Number of rotated files to keep, zero keeps all rotated files.
+	MaxBackups int `mapstructure:"max-backups"`
+	// Compress rotated files with gzip.
+	Compress bool `mapstructure:"compress"`
 }
 
 func (c LogHandlerConfig) Validate() error {
@@ -36,18 +63,42 @@ func (c LogHandlerConfig) Validate() error {
 	if !filepath.IsAbs(c.Path) {
 		return fmt.Errorf("log path must be absolute: %s is not absolute", c.Path)
 	}
+	switch c.Format {
+	case "", LogFormatJSON:
+		if c.Template != "" {
+			return fmt.Errorf("log template is only used with the %s format", LogFormatText)
+		}
+	case LogFormatText:
+		if _, err := text.New("log").Parse(c.Template); err != nil {
+			return errors.Wrap(err, "invalid log template")
+		}
+	default:
+		return fmt.Errorf("invalid log format %q, must be one of %s or %s", c.Format, LogFormatJSON, LogFormatText)
+	}
+	if c.MaxSize < 0 {
+		return fmt.Errorf("invalid max-size %d, must not be negative", c.MaxSize)
+	}
+	if c.RotateEvery < 0 {
+		return fmt.Errorf("invalid rotate-every %v, must not be negative", c.RotateEvery)
+	}
+	if c.MaxBackups < 0 {
+		return fmt.Errorf("invalid max-backups %d, must not be negative", c.MaxBackups)
+	}
 	return nil
 }
 
 type logHandler struct {
-	logpath string
-	mode    os.FileMode
-	logger  *log.Logger
+	logpath  string
+	mode     os.FileMode
+	template *text.Template
+	rotator  logRotator
+	logger   *log.Logger
 }
 
 func DefaultLogHandlerConfig() LogHandlerConfig {
 	return LogHandlerConfig{
-		Mode: defaultLogFileMode,
+		Mode:   defaultLogFileMode,
+		Format: LogFormatJSON,
 	}
 }
 
@@ -55,27 +106,66 @@ func NewLogHandler(c LogHandlerConfig, l *log.Logger) (alert.Handler, error) {
 	if err := c.Validate(); err != nil {
 		return nil, err
 	}
-	return &logHandler{
+	h := &logHandler{
 		logpath: c.Path,
 		mode:    c.Mode,
-		logger:  l,
-	}, nil
+		rotator: logRotator{
+			path:        c.Path,
+			mode:        c.Mode,
+			maxSize:     int64(c.MaxSize),
+			rotateEvery: time.Duration(c.RotateEvery),
+			maxBackups:  c.MaxBackups,
+			compress:    c.Compress,
+			logger:      l,
+		},
+		logger: l,
+	}
+	if c.Format == LogFormatText {
+		tmpl := c.Template
+		if tmpl == "" {
+			tmpl = defaultLogTemplate
+		}
+		t, err := text.New("log").Parse(tmpl)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid log template")
+		}
+		h.template = t
+	}
+	return h, nil
 }
 
-func (h *logHandler) Handle(event alert.Event) {
-	ad := event.AlertData()
+func (h *logHandler) Handle(event alert.Event) error {
+	var line bytes.Buffer
+	if h.template != nil {
+		if err := h.template.Execute(&line, event.TemplateData()); err != nil {
+			return alert.Permanent(fmt.Errorf("failed to execute log template: %v", err))
+		}
+		if !bytes.HasSuffix(line.Bytes(), []byte("\n")) {
+			line.WriteByte('\n')
+		}
+	} else {
+		if err := json.NewEncoder(&line).Encode(event.AlertData()); err != nil {
+			return alert.Permanent(fmt.Errorf("failed to marshal alert data json: %v", err))
+		}
+	}
+
+	unlock := lockLogFile(h.logpath)
+	defer unlock()
+
+	if err := h.rotator.rotateBefore(line.Len()); err != nil {
+		return fmt.Errorf("failed to rotate alert log file %s: %v", h.logpath, err)
+	}
 
 	f, err := os.OpenFile(h.logpath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, h.mode)
 	if err != nil {
-		h.logger.Printf("E! failed to open file %s for alert logging: %v", h.logpath, err)
-		return
+		return fmt.Errorf("failed to open file %s for alert logging: %v", h.logpath, err)
 	}
 	defer f.Close()
 
-	err = json.NewEncoder(f).Encode(ad)
-	if err != nil {
-		h.logger.Printf("E! failed to marshal alert data json: %v", err)
+	if _, err := f.Write(line.Bytes()); err != nil {
+		return fmt.Errorf("failed to write to alert log file %s: %v", h.logpath, err)
 	}
+	return nil
 }
 
 type ExecHandlerConfig struct {
@@ -104,15 +194,14 @@ func NewExecHandler(c ExecHandlerConfig, l *log.Logger) alert.Handler {
 	}
 }
 
-func (h *execHandler) Handle(event alert.Event) {
+func (h *execHandler) Handle(event alert.Event) error {
 	buf := h.bp.Get()
 	defer h.bp.Put(buf)
 	ad := event.AlertData()
 
 	err := json.NewEncoder(buf).Encode(ad)
 	if err != nil {
-		h.logger.Printf("E! failed to marshal alert data json: %v", err)
-		return
+		return alert.Permanent(fmt.Errorf("failed to marshal alert data json: %v", err))
 	}
 
 	cmd := h.commander.NewCommand(h.s)
@@ -122,14 +211,13 @@ func (h *execHandler) Handle(event alert.Event) {
 	cmd.Stderr(&out)
 	err = cmd.Start()
 	if err != nil {
-		h.logger.Printf("E! exec command failed: Output: %s: %v", out.String(), err)
-		return
+		return fmt.Errorf("exec command failed: Output: %s: %v", out.String(), err)
 	}
 	err = cmd.Wait()
 	if err != nil {
-		h.logger.Printf("E! exec command failed: Output: %s: %v", out.String(), err)
-		return
+		return fmt.Errorf("exec command failed: Output: %s: %v", out.String(), err)
 	}
+	return nil
 }
 
 type TCPHandlerConfig struct {
@@ -150,26 +238,27 @@ func NewTCPHandler(c TCPHandlerConfig, l *log.Logger) alert.Handler {
 	}
 }
 
-func (h *tcpHandler) Handle(event alert.Event) {
+func (h *tcpHandler) Handle(event alert.Event) error {
 	buf := h.bp.Get()
 	defer h.bp.Put(buf)
 	ad := event.AlertData()
 
 	err := json.NewEncoder(buf).Encode(ad)
 	if err != nil {
-		h.logger.Printf("E! failed to marshal alert data json: %v", err)
-		return
+		return alert.Permanent(fmt.Errorf("failed to marshal alert data json: %v", err))
 	}
 
 	conn, err := net.Dial("tcp", h.addr)
 	if err != nil {
-		h.logger.Printf("E! tcp handler: failed to connect to %s: %v", h.addr, err)
-		return
+		return fmt.Errorf("tcp handler: failed to connect to %s: %v", h.addr, err)
 	}
 	defer conn.Close()
 
 	buf.WriteByte('\n')
-	conn.Write(buf.Bytes())
+	if _, err := conn.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("tcp handler: failed to write to %s: %v", h.addr, err)
+	}
+	return nil
 }
 
 type AggregateHandlerConfig struct {
@@ -192,6 +281,46 @@ func newDefaultAggregateHandlerConfig(ec EventCollector) AggregateHandlerConfig
 	}
 }
 
+// newDigestTemplate parses and validates a template of the message of aggregated events.
+func newDigestTemplate(message string) (*text.Template, error) {
+	tmpl, err := text.New("message").Parse(message)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	md := aggregateMessageData{}
+	err = tmpl.Execute(&buf, md)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to evaluate message template with aggregate message data")
+	}
+	return tmpl, nil
+}
+
+// aggregateEvents folds the events into the aggregated event.
+// The aggregated event has the highest level, latest time and longest duration of the events,
+// the messages of the events as details and the series of all events.
+// It is only external if any of the events is external.
+func aggregateEvents(agg alert.Event, events []alert.Event) alert.Event {
+	details := make([]string, len(events))
+	agg.NoExternal = true
+	for i, e := range events {
+		if e.State.Level > agg.State.Level {
+			agg.State.Level = e.State.Level
+		}
+		if e.State.Time.After(agg.State.Time) {
+			agg.State.Time = e.State.Time
+		}
+		if e.State.Duration > agg.State.Duration {
+			agg.State.Duration = e.State.Duration
+		}
+		details[i] = e.State.Message
+		agg.Data.Result.Series = append(agg.Data.Result.Series, e.Data.Result.Series...)
+		agg.NoExternal = agg.NoExternal && e.NoExternal
+	}
+	agg.State.Details = strings.Join(details, "\n")
+	return agg
+}
+
 type aggregateHandler struct {
 	interval time.Duration
 	id       string
@@ -208,17 +337,10 @@ type aggregateHandler struct {
 }
 
 func NewAggregateHandler(c AggregateHandlerConfig, l *log.Logger) (alert.Handler, error) {
-	// Parse and validate message template
-	tmpl, err := text.New("message").Parse(c.Message)
+	tmpl, err := newDigestTemplate(c.Message)
 	if err != nil {
 		return nil, err
 	}
-	var buf bytes.Buffer
-	md := aggregateMessageData{}
-	err = tmpl.Execute(&buf, md)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to evaluate message template with aggregate message data")
-	}
 
 	h := &aggregateHandler{
 		interval:    time.Duration(c.Interval),
@@ -243,15 +365,12 @@ func (h *aggregateHandler) run() {
 	defer ticker.Stop()
 	var events []alert.Event
 	var messageBuf bytes.Buffer
-	// Keep track if this batch of events should be external.
-	external := false
 	for {
 		select {
 		case <-h.closing:
 			return
 		case e := <-h.events:
 			events = append(events, e)
-			external = external || !e.NoExternal
 		case <-ticker.C:
 			if len(events) == 0 {
 				continue
@@ -263,41 +382,27 @@ func (h *aggregateHandler) run() {
 			}
 			// Ignore error since we have validated the template already
 			_ = h.messageTmpl.Execute(&messageBuf, md)
-			details := make([]string, len(events))
-			agg := alert.Event{
+			agg := aggregateEvents(alert.Event{
 				Topic: h.topic,
 				State: alert.EventState{
 					ID:      h.id,
 					Message: messageBuf.String(),
 				},
-				NoExternal: !external,
-			}
-			for i, e := range events {
-				if e.State.Level > agg.State.Level {
-					agg.State.Level = e.State.Level
-				}
-				if e.State.Time.After(agg.State.Time) {
-					agg.State.Time = e.State.Time
-				}
-				if e.State.Duration > agg.State.Duration {
-					agg.State.Duration = e.State.Duration
-				}
-				details[i] = e.State.Message
-				agg.Data.Result.Series = append(agg.Data.Result.Series, e.Data.Result.Series...)
+			}, events)
+			if err := h.ec.Collect(agg); err != nil {
+				h.logger.Printf("E! failed to collect aggregated event %q: %v", h.id, err)
 			}
-			agg.State.Details = strings.Join(details, "\n")
-			h.ec.Collect(agg)
 			events = events[0:0]
-			external = false
 		}
 	}
 }
 
-func (h *aggregateHandler) Handle(event alert.Event) {
+func (h *aggregateHandler) Handle(event alert.Event) error {
 	select {
 	case h.events <- event:
 	case <-h.closing:
 	}
+	return nil
 }
 
 func (h *aggregateHandler) Close() {
@@ -305,6 +410,297 @@ func (h *aggregateHandler) Close() {
 	h.wg.Wait()
 }
 
+type EscalateHandlerConfig struct {
+	// Minimum level of escalated events.
+	Level alert.Level `mapstructure:"level"`
+	// Stages of the escalation, in the order they are notified.
+	Stages []EscalationStageConfig `mapstructure:"stages"`
+}
+
+type EscalationStageConfig struct {
+	// Time since the start of the escalation until the stage is notified.
+	After toml.Duration `mapstructure:"after"`
+	// Actions notified by the stage.
+	Actions []EscalationActionConfig `mapstructure:"actions"`
+}
+
+// EscalationActionConfig defines a nested handler of an escalation stage.
+type EscalationActionConfig struct {
+	Kind    string                 `mapstructure:"kind"`
+	Options map[string]interface{} `mapstructure:"options"`
+	Match   string                 `mapstructure:"match"`
+}
+
+func DefaultEscalateHandlerConfig() EscalateHandlerConfig {
+	return EscalateHandlerConfig{
+		Level: alert.Critical,
+	}
+}
+
+func (c EscalateHandlerConfig) Validate() error {
+	if c.Level == alert.OK {
+		return errors.New("escalation level must be one of INFO, WARNING or CRITICAL")
+	}
+	if len(c.Stages) == 0 {
+		return errors.New("escalation must have at least one stage")
+	}
+	for i, stage := range c.Stages {
+		if stage.After < 0 {
+			return fmt.Errorf("escalation stage %d must not start before the escalation", i)
+		}
+		if i > 0 && stage.After < c.Stages[i-1].After {
+			return fmt.Errorf("escalation stage %d must not start before the previous stage", i)
+		}
+		if len(stage.Actions) == 0 {
+			return fmt.Errorf("escalation stage %d must have at least one action", i)
+		}
+		for _, action := range stage.Actions {
+			switch action.Kind {
+			case "":
+				return fmt.Errorf("escalation stage %d has an action without a kind", i)
+			case "escalate":
+				return errors.New("escalate handlers cannot be nested")
+			}
+		}
+	}
+	return nil
+}
+
+// EscalationStore persists the pending escalations of escalate handlers.
+type EscalationStore interface {
+	// EventState returns the current state of the event.
+	EventState(topic, event string) (alert.EventState, bool, error)
+	// Escalations returns the pending escalations of a handler.
+	Escalations(topic, handler string) ([]PendingEscalation, error)
+	// PutEscalation saves the pending escalation of an event.
+	PutEscalation(topic, handler string, e PendingEscalation) error
+	// DeleteEscalation deletes the pending escalation of an event.
+	DeleteEscalation(topic, handler, event string) error
+}
+
+// PendingEscalation is an event that is being escalated.
+type PendingEscalation struct {
+	// The event as it was last handled.
+	Event alert.Event
+	// Time the escalation started.
+	Start time.Time
+	// Number of stages that have been notified.
+	Stages int
+}
+
+type escalationStage struct {
+	after    time.Duration
+	handlers []alert.Handler
+}
+
+type escalation struct {
+	PendingEscalation
+	timer *time.Timer
+}
+
+// escalateHandler notifies the stages of an escalation one after another,
+// while the event stays at or above the level and is not acknowledged.
+type escalateHandler struct {
+	topic string
+	id    string
+	level alert.Level
+
+	stages []escalationStage
+	store  EscalationStore
+
+	logger *log.Logger
+
+	mu          sync.Mutex
+	escalations map[string]*escalation
+	closed      bool
+}
+
+// NewEscalateHandler creates an escalate handler with the handlers of each stage,
+// and resumes the pending escalations of the handler from the store.
+func NewEscalateHandler(topic, id string, c EscalateHandlerConfig, stages [][]alert.Handler, store EscalationStore, l *log.Logger) (alert.Handler, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if len(stages) != len(c.Stages) {
+		return nil, fmt.Errorf("expected handlers for %d stages, got %d", len(c.Stages), len(stages))
+	}
+	h := &escalateHandler{
+		topic:       topic,
+		id:          id,
+		level:       c.Level,
+		stages:      make([]escalationStage, len(stages)),
+		store:       store,
+		logger:      l,
+		escalations: make(map[string]*escalation),
+	}
+	for i, handlers := range stages {
+		h.stages[i] = escalationStage{
+			after:    time.Duration(c.Stages[i].After),
+			handlers: handlers,
+		}
+	}
+
+	pending, err := store.Escalations(topic, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load pending escalations")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, p := range pending {
+		e := &escalation{PendingEscalation: p}
+		h.escalations[p.Event.State.ID] = e
+		h.schedule(e)
+	}
+	return h, nil
+}
+
+// Handle starts or updates the escalation of the event.
+// Failures of the handlers of stages are logged, since retrying would notify all stages again.
+func (h *escalateHandler) Handle(event alert.Event) error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	id := event.State.ID
+	escalating := event.State.Level >= h.level && !event.State.Acknowledged()
+	e, ok := h.escalations[id]
+	var notify []alert.Handler
+	switch {
+	case !ok && !escalating:
+	case !ok:
+		e = &escalation{
+			PendingEscalation: PendingEscalation{
+				Event: event,
+				Start: time.Now(),
+			},
+		}
+		h.escalations[id] = e
+		notify = h.advance(e)
+	default:
+		// Stages that have been notified receive all updates of the event.
+		for _, stage := range h.stages[:e.Stages] {
+			notify = append(notify, stage.handlers...)
+		}
+		if escalating {
+			e.Event = event
+			h.persist(e)
+		} else {
+			h.stop(e)
+		}
+	}
+	h.mu.Unlock()
+
+	h.notify(notify, event)
+	return nil
+}
+
+// escalate notifies the next stages of an escalation if the event is still escalating.
+func (h *escalateHandler) escalate(id string) {
+	h.mu.Lock()
+	e, ok := h.escalations[id]
+	if h.closed || !ok {
+		h.mu.Unlock()
+		return
+	}
+	// The event may have been acknowledged since it was last handled.
+	// Use the last handled event if its state is unknown, i.e. while the state is being restored.
+	if state, ok, err := h.store.EventState(h.topic, id); err != nil {
+		h.logger.Printf("E! failed to get state of escalated event %q: %v", id, err)
+	} else if ok {
+		if state.Level < h.level || state.Acknowledged() {
+			h.stop(e)
+			h.mu.Unlock()
+			return
+		}
+		e.Event.State = state
+	}
+	event := e.Event
+	notify := h.advance(e)
+	h.mu.Unlock()
+
+	h.notify(notify, event)
+}
+
+// notify passes the event to the handlers of stages.
+func (h *escalateHandler) notify(handlers []alert.Handler, event alert.Event) {
+	for _, hdlr := range handlers {
+		if err := hdlr.Handle(event); err != nil {
+			h.logger.Printf("E! failed to notify escalation stage of event %q: %v", event.State.ID, err)
+		}
+	}
+}
+
+// advance returns the handlers of all stages that are due and schedules the next stage.
+// Caller must have lock.
+func (h *escalateHandler) advance(e *escalation) []alert.Handler {
+	var notify []alert.Handler
+	elapsed := time.Since(e.Start)
+	for e.Stages < len(h.stages) && h.stages[e.Stages].after <= elapsed {
+		notify = append(notify, h.stages[e.Stages].handlers...)
+		e.Stages++
+	}
+	h.schedule(e)
+	h.persist(e)
+	return notify
+}
+
+// schedule starts a timer for the next stage of the escalation.
+// Caller must have lock.
+func (h *escalateHandler) schedule(e *escalation) {
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	if e.Stages >= len(h.stages) {
+		return
+	}
+	id := e.Event.State.ID
+	e.timer = time.AfterFunc(time.Until(e.Start.Add(h.stages[e.Stages].after)), func() {
+		h.escalate(id)
+	})
+}
+
+// stop ends the escalation of an event.
+// Caller must have lock.
+func (h *escalateHandler) stop(e *escalation) {
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+	id := e.Event.State.ID
+	delete(h.escalations, id)
+	if err := h.store.DeleteEscalation(h.topic, h.id, id); err != nil {
+		h.logger.Printf("E! failed to delete escalation of event %q: %v", id, err)
+	}
+}
+
+// persist saves the escalation so it can be resumed after a restart.
+// Caller must have lock.
+func (h *escalateHandler) persist(e *escalation) {
+	if err := h.store.PutEscalation(h.topic, h.id, e.PendingEscalation); err != nil {
+		h.logger.Printf("E! failed to save escalation of event %q: %v", e.Event.State.ID, err)
+	}
+}
+
+// Close stops all pending escalations, they remain in the store to be resumed.
+func (h *escalateHandler) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, e := range h.escalations {
+		if e.timer != nil {
+			e.timer.Stop()
+		}
+	}
+	for _, stage := range h.stages {
+		for _, hdlr := range stage.handlers {
+			if c, ok := hdlr.(closer); ok {
+				c.Close()
+			}
+		}
+	}
+}
+
 type PublishHandlerConfig struct {
 	Topics []string `mapstructure:"topics"`
 	ec     EventCollector
@@ -312,20 +708,61 @@ type PublishHandlerConfig struct {
 type publishHandler struct {
 	c      PublishHandlerConfig
 	logger *log.Logger
+
+	mu sync.Mutex
+	// published holds the topics each failed event has been published to,
+	// so that retries of the event are only published to the topics that failed.
+	published map[string]publishedEvent
+}
+
+// publishedEvent is the set of topics an event has been published to.
+type publishedEvent struct {
+	time   time.Time
+	topics map[string]bool
 }
 
 func NewPublishHandler(c PublishHandlerConfig, l *log.Logger) alert.Handler {
 	return &publishHandler{
-		c:      c,
-		logger: l,
+		c:         c,
+		logger:    l,
+		published: make(map[string]publishedEvent),
 	}
 }
 
-func (h *publishHandler) Handle(event alert.Event) {
+func (h *publishHandler) Handle(event alert.Event) error {
+	id := event.State.ID
+	h.mu.Lock()
+	p, ok := h.published[id]
+	h.mu.Unlock()
+	if !ok || !p.time.Equal(event.State.Time) {
+		// Not a retry of the event, publish it to all topics.
+		p = publishedEvent{
+			time:   event.State.Time,
+			topics: make(map[string]bool, len(h.c.Topics)),
+		}
+	}
+
+	var errs []string
 	for _, t := range h.c.Topics {
+		if p.topics[t] {
+			continue
+		}
 		event.Topic = t
-		h.c.ec.Collect(event)
+		if err := h.c.ec.Collect(event); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", t, err))
+			continue
+		}
+		p.topics[t] = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(errs) != 0 {
+		h.published[id] = p
+		return fmt.Errorf("failed to publish event to topics: %s", strings.Join(errs, "; "))
 	}
+	delete(h.published, id)
+	return nil
 }
 
 // ExternalHandler wraps an existing handler that calls out to external services.
@@ -340,15 +777,196 @@ func newExternalHandler(h alert.Handler) *externalHandler {
 	}
 }
 
-func (h *externalHandler) Handle(event alert.Event) {
-	if !event.NoExternal {
-		h.h.Handle(event)
+func (h *externalHandler) Handle(event alert.Event) error {
+	if event.NoExternal {
+		return nil
+	}
+	return h.h.Handle(event)
+}
+
+func (h *externalHandler) Close() {
+	if c, ok := h.h.(closer); ok {
+		c.Close()
+	}
+}
+
+// retryHandler wraps a handler of a handler spec, so that failed events are retried
+// according to the retry spec and passed to the dead-letter function after all retries failed.
+type retryHandler struct {
+	h          alert.Handler
+	policy     alert.RetryPolicy
+	deadLetter func(alert.Event, error)
+}
+
+func newRetryHandler(h alert.Handler, spec *RetrySpec, deadLetter func(alert.Event, error)) *retryHandler {
+	r := &retryHandler{
+		h:          h,
+		deadLetter: deadLetter,
+	}
+	if spec != nil {
+		r.policy = alert.RetryPolicy{
+			Attempts:   spec.Attempts,
+			Backoff:    time.Duration(spec.Backoff),
+			MaxBackoff: time.Duration(spec.MaxBackoff),
+		}
+	}
+	return r
+}
+
+func (h *retryHandler) Handle(event alert.Event) error {
+	return h.h.Handle(event)
+}
+
+func (h *retryHandler) RetryPolicy() alert.RetryPolicy {
+	return h.policy
+}
+
+func (h *retryHandler) DeadLetter(event alert.Event, err error) {
+	if h.deadLetter != nil {
+		h.deadLetter(event, err)
+	}
+}
+
+func (h *retryHandler) Close() {
+	if c, ok := h.h.(closer); ok {
+		c.Close()
+	}
+}
+
+const defaultDigestMessage = "Suppressed {{ .Count }} events in the last {{ .Interval }}."
+
+// digestSampleSize is the maximum number of suppressed events whose details are included in a digest.
+const digestSampleSize = 20
+
+// rateLimitHandler passes at most a number of events per interval to the wrapped handler.
+// Events over the limit are suppressed and summarized in a digest event,
+// which the topic takes at the end of each interval and handles like any other event.
+// Retries of failed events are passed to the wrapped handler without counting against the limit.
+type rateLimitHandler struct {
+	h alert.Handler
+	// retrier is the wrapped handler if it retries failed events.
+	retrier  alert.Retrier
+	limit    int
+	interval time.Duration
+	// ID of the digest events.
+	id    string
+	topic string
+	// suppress is called for each suppressed event.
+	suppress func()
+
+	messageTmpl *text.Template
+
+	mu      sync.Mutex
+	handled int
+	// suppressed is the number of events suppressed in the current interval,
+	// of which the first digestSampleSize events are kept in sample.
+	suppressed int
+	sample     []alert.Event
+}
+
+func newRateLimitHandler(h alert.Handler, spec HandlerSpec, suppress func()) (*rateLimitHandler, error) {
+	message := spec.RateLimit.Message
+	if message == "" {
+		message = defaultDigestMessage
+	}
+	tmpl, err := newDigestTemplate(message)
+	if err != nil {
+		return nil, err
+	}
+	r := &rateLimitHandler{
+		h:           h,
+		limit:       spec.RateLimit.Count,
+		interval:    time.Duration(spec.RateLimit.Interval),
+		id:          fmt.Sprintf("%s:%s:digest", spec.Topic, spec.ID),
+		topic:       spec.Topic,
+		suppress:    suppress,
+		messageTmpl: tmpl,
+	}
+	r.retrier, _ = h.(alert.Retrier)
+	return r, nil
+}
+
+func (h *rateLimitHandler) Handle(event alert.Event) error {
+	h.mu.Lock()
+	if h.handled < h.limit {
+		h.handled++
+		h.mu.Unlock()
+		return h.h.Handle(event)
+	}
+	h.suppressed++
+	if len(h.sample) < digestSampleSize {
+		h.sample = append(h.sample, event)
 	}
+	h.mu.Unlock()
+	if h.suppress != nil {
+		h.suppress()
+	}
+	return nil
 }
 
+func (h *rateLimitHandler) DigestInterval() time.Duration {
+	return h.interval
+}
+
+// Digest starts a new interval and returns the digest of the events suppressed in the last interval.
+func (h *rateLimitHandler) Digest() (alert.Event, bool) {
+	h.mu.Lock()
+	count, events := h.suppressed, h.sample
+	h.suppressed = 0
+	h.sample = nil
+	h.handled = 0
+	h.mu.Unlock()
+	if count == 0 {
+		return alert.Event{}, false
+	}
+
+	var buf bytes.Buffer
+	md := aggregateMessageData{
+		Interval: h.interval,
+		Count:    count,
+	}
+	// Ignore error since we have validated the template already
+	_ = h.messageTmpl.Execute(&buf, md)
+	return aggregateEvents(alert.Event{
+		Topic: h.topic,
+		State: alert.EventState{
+			ID:      h.id,
+			Message: buf.String(),
+		},
+	}, events), true
+}
+
+func (h *rateLimitHandler) Redeliver(event alert.Event) error {
+	return h.h.Handle(event)
+}
+
+func (h *rateLimitHandler) RetryPolicy() alert.RetryPolicy {
+	if h.retrier == nil {
+		return alert.RetryPolicy{}
+	}
+	return h.retrier.RetryPolicy()
+}
+
+func (h *rateLimitHandler) DeadLetter(event alert.Event, err error) {
+	if h.retrier != nil {
+		h.retrier.DeadLetter(event, err)
+	}
+}
+
+func (h *rateLimitHandler) Close() {
+	if c, ok := h.h.(closer); ok {
+		c.Close()
+	}
+}
+
+// matchHandler passes only the events that match an expression to the wrapped handler.
+// The expression may reference tags and fields of the event,
+// as well as the "level" and "changed" properties of the event if no tag or field has that name.
 type matchHandler struct {
 	h alert.Handler
 
+	// mu protects the scope, since nested handlers of escalations are called concurrently.
+	mu    sync.Mutex
 	scope *stateful.Scope
 	expr  stateful.Expression
 
@@ -372,6 +990,12 @@ const (
 	durationFunc = "duration"
 )
 
+// Properties of the event that can be referenced in match expressions.
+const (
+	levelReference   = "level"
+	changedReference = "changed"
+)
+
 var matchIdentifiers = map[string]interface{}{
 	"OK":       int64(alert.OK),
 	"INFO":     int64(alert.Info),
@@ -379,10 +1003,11 @@ var matchIdentifiers = map[string]interface{}{
 	"CRITICAL": int64(alert.Critical),
 }
 
-func newMatchHandler(match string, h alert.Handler, l *log.Logger) (*matchHandler, error) {
+// parseMatch parses and compiles a match expression.
+func parseMatch(match string) (*ast.LambdaNode, stateful.Expression, error) {
 	lambda, err := ast.ParseLambda(match)
 	if err != nil {
-		return nil, errors.Wrap(err, "invalid match expression")
+		return nil, nil, errors.Wrap(err, "invalid match expression")
 	}
 
 	// Replace identifiers with static values
@@ -397,12 +1022,20 @@ func newMatchHandler(match string, h alert.Handler, l *log.Logger) (*matchHandle
 		return n, nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, errors.Wrap(err, "invalid match expression")
 	}
 
 	expr, err := stateful.NewExpression(lambda.Expression)
 	if err != nil {
-		return nil, errors.Wrap(err, "invalid match expression")
+		return nil, nil, errors.Wrap(err, "invalid match expression")
+	}
+	return lambda, expr, nil
+}
+
+func newMatchHandler(match string, h alert.Handler, l *log.Logger) (*matchHandler, error) {
+	lambda, expr, err := parseMatch(match)
+	if err != nil {
+		return nil, err
 	}
 
 	mh := &matchHandler{
@@ -435,11 +1068,18 @@ func newMatchHandler(match string, h alert.Handler, l *log.Logger) (*matchHandle
 	return mh, nil
 }
 
-func (h *matchHandler) Handle(event alert.Event) {
+func (h *matchHandler) Handle(event alert.Event) error {
 	if ok, err := h.match(event); err != nil {
 		h.logger.Println("E! failed to evaluate match expression:", err)
 	} else if ok {
-		h.h.Handle(event)
+		return h.h.Handle(event)
+	}
+	return nil
+}
+
+func (h *matchHandler) Close() {
+	if c, ok := h.h.(closer); ok {
+		c.Close()
 	}
 }
 
@@ -459,6 +1099,9 @@ func init() {
 }
 
 func (h *matchHandler) match(event alert.Event) (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	// Populate scope
 	h.scope.Reset()
 
@@ -522,12 +1165,18 @@ func (h *matchHandler) match(event alert.Event) (bool, error) {
 		})
 	}
 
-	// Set tag values on scope
+	// Set tag, field and event property values on scope
 	for _, v := range h.vars {
 		if tag, ok := event.Data.Tags[v]; ok {
 			h.scope.Set(v, tag)
+		} else if field, ok := event.Data.Fields[v]; ok {
+			h.scope.Set(v, field)
+		} else if v == levelReference {
+			h.scope.Set(v, int64(event.State.Level))
+		} else if v == changedReference {
+			h.scope.Set(v, event.State.Level != event.PreviousState().Level)
 		} else {
-			return false, fmt.Errorf("no tag exists for %s", v)
+			return false, fmt.Errorf("no tag or field exists for %s", v)
 		}
 	}
 
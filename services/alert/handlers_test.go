@@ -0,0 +1,242 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// recordingHandler records the events it handles and fails the first failures events.
+type recordingHandler struct {
+	mu       sync.Mutex
+	failures int
+	events   []alert.Event
+}
+
+func (h *recordingHandler) Handle(event alert.Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event)
+	if h.failures > 0 {
+		h.failures--
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func (h *recordingHandler) Events() []alert.Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]alert.Event(nil), h.events...)
+}
+
+func rateLimitSpec(count int, interval time.Duration) HandlerSpec {
+	return HandlerSpec{
+		ID:    "limited",
+		Topic: "topic",
+		RateLimit: &RateLimitSpec{
+			Count:    count,
+			Interval: toml.Duration(interval),
+			Message:  "Suppressed {{ .Count }} events",
+		},
+	}
+}
+
+func testEvent(id string) alert.Event {
+	return alert.Event{
+		Topic: "topic",
+		State: alert.EventState{
+			ID:      id,
+			Message: "message " + id,
+			Level:   alert.Critical,
+		},
+	}
+}
+
+func TestRateLimitHandler_RetriesAreNotLimited(t *testing.T) {
+	topics := alert.NewTopics(log.New(os.Stderr, "[alert] ", log.LstdFlags))
+	inner := &recordingHandler{failures: 1}
+	retry := newRetryHandler(inner, &RetrySpec{Attempts: 1, Backoff: toml.Duration(time.Millisecond)}, nil)
+	h, err := newRateLimitHandler(retry, rateLimitSpec(1, time.Hour), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topics.RegisterHandler("topic", h)
+
+	if err := topics.Collect(testEvent("a")); err != nil {
+		t.Fatal(err)
+	}
+	// Wait for the retry of the failed event, which must not be suppressed by the limit.
+	for i := 0; len(inner.Events()) < 2; i++ {
+		if i > 100 {
+			t.Fatalf("expected the failed event to be retried, got %d events", len(inner.Events()))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := topics.Collect(testEvent("b")); err != nil {
+		t.Fatal(err)
+	}
+	// The digest of the suppressed event is handled by the topic when it closes.
+	topics.Close()
+
+	var got []string
+	for _, e := range inner.Events() {
+		got = append(got, e.State.ID)
+	}
+	if exp := []string{"a", "a", "topic:limited:digest"}; fmt.Sprint(got) != fmt.Sprint(exp) {
+		t.Errorf("unexpected events got %v exp %v", got, exp)
+	}
+}
+
+func TestRateLimitHandler_DigestSample(t *testing.T) {
+	h, err := newRateLimitHandler(&recordingHandler{}, rateLimitSpec(0, time.Hour), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const count = digestSampleSize + 10
+	for i := 0; i < count; i++ {
+		if err := h.Handle(testEvent(fmt.Sprint(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	digest, ok := h.Digest()
+	if !ok {
+		t.Fatal("expected a digest of the suppressed events")
+	}
+	if exp, got := fmt.Sprintf("Suppressed %d events", count), digest.State.Message; got != exp {
+		t.Errorf("unexpected digest message got %q exp %q", got, exp)
+	}
+	// Only a sample of the suppressed events is kept.
+	if exp, got := digestSampleSize, len(strings.Split(digest.State.Details, "\n")); got != exp {
+		t.Errorf("unexpected number of details got %d exp %d", got, exp)
+	}
+}
+
+func TestRateLimitHandler_IntervalRollover(t *testing.T) {
+	inner := &recordingHandler{}
+	suppressed := 0
+	h, err := newRateLimitHandler(inner, rateLimitSpec(1, time.Hour), func() { suppressed++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if err := h.Handle(testEvent(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if exp, got := 1, suppressed; got != exp {
+		t.Fatalf("unexpected suppressed events got %d exp %d", got, exp)
+	}
+	digest, ok := h.Digest()
+	if !ok {
+		t.Fatal("expected a digest of the suppressed events")
+	}
+	if exp, got := "Suppressed 1 events", digest.State.Message; got != exp {
+		t.Errorf("unexpected digest message got %q exp %q", got, exp)
+	}
+
+	// The digest starts a new interval, in which events are handled again up to the limit.
+	for _, id := range []string{"c", "d"} {
+		if err := h.Handle(testEvent(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got []string
+	for _, e := range inner.Events() {
+		got = append(got, e.State.ID)
+	}
+	if exp := []string{"a", "c"}; fmt.Sprint(got) != fmt.Sprint(exp) {
+		t.Errorf("unexpected handled events got %v exp %v", got, exp)
+	}
+	if digest, ok := h.Digest(); !ok || digest.State.Message != "Suppressed 1 events" {
+		t.Errorf("unexpected digest of the second interval %q, ok %t", digest.State.Message, ok)
+	}
+	// Intervals without suppressed events have no digest.
+	if _, ok := h.Digest(); ok {
+		t.Error("unexpected digest of an interval without suppressed events")
+	}
+}
+
+func TestRateLimitHandler_TopicIntervalRollover(t *testing.T) {
+	topics := alert.NewTopics(log.New(os.Stderr, "[alert] ", log.LstdFlags))
+	inner := &recordingHandler{}
+	h, err := newRateLimitHandler(inner, rateLimitSpec(1, 20*time.Millisecond), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topics.RegisterHandler("topic", h)
+
+	for _, id := range []string{"a", "b"} {
+		if err := topics.Collect(testEvent(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The topic handles the digest at the end of the interval.
+	for i := 0; len(inner.Events()) < 2; i++ {
+		if i > 100 {
+			t.Fatalf("expected the digest to be handled, got %d events", len(inner.Events()))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := topics.Collect(testEvent("c")); err != nil {
+		t.Fatal(err)
+	}
+	topics.Close()
+
+	var got []string
+	for _, e := range inner.Events() {
+		got = append(got, e.State.ID)
+	}
+	if exp := []string{"a", "topic:limited:digest", "c"}; fmt.Sprint(got) != fmt.Sprint(exp) {
+		t.Errorf("unexpected events got %v exp %v", got, exp)
+	}
+}
+
+// failingCollector fails to collect events of a topic a number of times.
+type failingCollector struct {
+	failures map[string]int
+	topics   []string
+}
+
+func (c *failingCollector) Collect(event alert.Event) error {
+	if c.failures[event.Topic] > 0 {
+		c.failures[event.Topic]--
+		return errors.New("failed")
+	}
+	c.topics = append(c.topics, event.Topic)
+	return nil
+}
+
+func TestPublishHandler_RetryFailedTopics(t *testing.T) {
+	ec := &failingCollector{failures: map[string]int{"b": 1}}
+	h := NewPublishHandler(PublishHandlerConfig{
+		Topics: []string{"a", "b", "c"},
+		ec:     ec,
+	}, log.New(os.Stderr, "[publish] ", log.LstdFlags))
+
+	event := testEvent("id")
+	event.State.Time = time.Unix(1, 0)
+	if err := h.Handle(event); err == nil {
+		t.Fatal("expected error publishing to a failing topic")
+	}
+	// The retry is only published to the topic that failed.
+	if err := h.Handle(event); err != nil {
+		t.Fatal(err)
+	}
+	// A new event is published to all topics.
+	event.State.Time = time.Unix(2, 0)
+	if err := h.Handle(event); err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := []string{"a", "c", "b", "a", "b", "c"}, ec.topics; fmt.Sprint(got) != fmt.Sprint(exp) {
+		t.Errorf("unexpected published topics got %v exp %v", got, exp)
+	}
+}
@@ -0,0 +1,195 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+const (
+	// How often records older than the retention are deleted.
+	historyPurgeInterval = time.Hour
+	// Number of records read at a time when querying and purging the history.
+	historyPageSize = 100
+)
+
+// recordTransition records the event in the history if its level changed from the previous level.
+func (s *Service) recordTransition(event alert.Event, previous alert.Level) {
+	if event.State.Level == previous {
+		return
+	}
+	r := HistoryRecord{
+		ID:       s.nextHistoryID(event.Topic, event.State.Time),
+		Topic:    event.Topic,
+		EventID:  event.State.ID,
+		Message:  event.State.Message,
+		Time:     event.State.Time,
+		Duration: event.State.Duration,
+		Level:    event.State.Level,
+		Previous: previous,
+		Tags:     event.Data.Tags,
+	}
+	if err := s.historyDAO.Create(r); err != nil {
+		s.logger.Printf("E! failed to record history of event %q of topic %q: %v", r.EventID, r.Topic, err)
+	}
+}
+
+// nextHistoryID returns a unique ID that sorts by the topic and the time of the event.
+// Events with the same time are ordered by the order they were recorded.
+func (s *Service) nextHistoryID(topic string, t time.Time) string {
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+	seq := time.Now().UnixNano()
+	if seq <= s.lastHistorySeq {
+		seq = s.lastHistorySeq + 1
+	}
+	s.lastHistorySeq = seq
+	return fmt.Sprintf("%s/%019d-%019d", topic, t.UnixNano(), seq)
+}
+
+// HistoryQuery filters the history of a topic.
+type HistoryQuery struct {
+	// Glob pattern matched against the event ID.
+	EventID string
+	// Only transitions to levels greater or equal to MinLevel are returned.
+	MinLevel alert.Level
+	// Glob patterns matched against the tags of the event.
+	Tags map[string]string
+	// Only records in the time range [Since, Until) are returned, zero values are unbounded.
+	Since time.Time
+	Until time.Time
+
+	Offset int
+	Limit  int
+}
+
+func (q HistoryQuery) match(r HistoryRecord) bool {
+	if r.Level < q.MinLevel {
+		return false
+	}
+	if q.EventID != "" && !alert.PatternMatch(q.EventID, r.EventID) {
+		return false
+	}
+	if !q.Until.IsZero() && !r.Time.Before(q.Until) {
+		return false
+	}
+	for tag, pattern := range q.Tags {
+		value, ok := r.Tags[tag]
+		if !ok || !alert.PatternMatch(pattern, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// History returns the records of the topic that match the query, newest first.
+func (s *Service) History(topic string, q HistoryQuery) ([]HistoryRecord, error) {
+	var matches []HistoryRecord
+	for offset := 0; ; offset += historyPageSize {
+		records, err := s.historyDAO.ReverseListTopic(topic, offset, historyPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			if !q.Since.IsZero() && r.Time.Before(q.Since) {
+				// All remaining records are older
+				return matches, nil
+			}
+			if !q.match(r) {
+				continue
+			}
+			if q.Offset > 0 {
+				q.Offset--
+				continue
+			}
+			matches = append(matches, r)
+			if q.Limit >= 0 && len(matches) == q.Limit {
+				return matches, nil
+			}
+		}
+		if len(records) != historyPageSize {
+			return matches, nil
+		}
+	}
+}
+
+// deleteHistory deletes all records of the topic.
+func (s *Service) deleteHistory(topic string) error {
+	for {
+		records, err := s.historyDAO.ReverseListTopic(topic, 0, historyPageSize)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			if err := s.historyDAO.Delete(r.ID); err != nil {
+				return err
+			}
+		}
+		if len(records) != historyPageSize {
+			return nil
+		}
+	}
+}
+
+func (s *Service) runHistoryPurge() {
+	ticker := time.NewTicker(historyPurgeInterval)
+	defer ticker.Stop()
+	for {
+		var cutoff time.Time
+		if s.historyRetention > 0 {
+			cutoff = time.Now().Add(-s.historyRetention)
+		}
+		if err := s.PurgeHistory(cutoff, s.historyMaxRecords); err != nil {
+			s.logger.Println("E! failed to purge alert history:", err)
+		}
+		select {
+		case <-s.closing:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeHistory deletes all records older than the cutoff,
+// and the oldest records in excess of maxRecords.
+// A zero cutoff or maxRecords disables the respective limit.
+func (s *Service) PurgeHistory(cutoff time.Time, maxRecords int) error {
+	if !cutoff.IsZero() {
+	Retention:
+		for {
+			records, err := s.historyDAO.List(0, historyPageSize)
+			if err != nil {
+				return err
+			}
+			for _, r := range records {
+				if !r.Time.Before(cutoff) {
+					break Retention
+				}
+				if err := s.historyDAO.Delete(r.ID); err != nil {
+					return err
+				}
+			}
+			if len(records) != historyPageSize {
+				break
+			}
+		}
+	}
+	if maxRecords > 0 {
+		for {
+			records, err := s.historyDAO.ReverseList(maxRecords, historyPageSize)
+			if err != nil {
+				return err
+			}
+			for _, r := range records {
+				if err := s.historyDAO.Delete(r.ID); err != nil {
+					return err
+				}
+			}
+			if len(records) != historyPageSize {
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package alert
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/storage"
+)
+
+func newHistoryService(t *testing.T) *Service {
+	historyDAO, err := newHistoryKV(storage.NewMemStore("history"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Service{
+		historyDAO: historyDAO,
+		logger:     log.New(os.Stderr, "[alert] ", log.LstdFlags),
+	}
+}
+
+// historyTimes returns the times of all records of the topics, oldest first, in minutes since start.
+func historyTimes(t *testing.T, s *Service, start time.Time, topics ...string) map[string][]int {
+	times := make(map[string][]int)
+	for _, topic := range topics {
+		records, err := s.History(topic, HistoryQuery{Limit: -1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := len(records) - 1; i >= 0; i-- {
+			times[topic] = append(times[topic], int(records[i].Time.Sub(start)/time.Minute))
+		}
+	}
+	return times
+}
+
+func TestService_PurgeHistory(t *testing.T) {
+	s := newHistoryService(t)
+	start := time.Date(2017, 3, 1, 0, 0, 0, 0, time.UTC)
+	// Transitions of the events of two topics, alternating between critical and OK.
+	transitions := []struct {
+		topic   string
+		minutes int
+	}{
+		{topic: "a", minutes: 1},
+		{topic: "b", minutes: 2},
+		{topic: "b", minutes: 3},
+		{topic: "a", minutes: 4},
+		{topic: "b", minutes: 5},
+	}
+	levels := make(map[string]alert.Level)
+	for _, tr := range transitions {
+		previous := levels[tr.topic]
+		level := alert.Critical
+		if previous == alert.Critical {
+			level = alert.OK
+		}
+		levels[tr.topic] = level
+		s.recordTransition(alert.Event{
+			Topic: tr.topic,
+			State: alert.EventState{
+				ID:    "id",
+				Time:  start.Add(time.Duration(tr.minutes) * time.Minute),
+				Level: level,
+			},
+		}, previous)
+	}
+
+	// Records older than the retention are purged, in the order of their time across topics.
+	if err := s.PurgeHistory(start.Add(3*time.Minute+30*time.Second), 0); err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := map[string][]int{"a": {4}, "b": {5}}, historyTimes(t, s, start, "a", "b"); fmt.Sprint(got) != fmt.Sprint(exp) {
+		t.Errorf("unexpected history after purging by retention got %v exp %v", got, exp)
+	}
+
+	// The oldest records in excess of the maximum are purged.
+	if err := s.PurgeHistory(time.Time{}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := map[string][]int{"b": {5}}, historyTimes(t, s, start, "a", "b"); fmt.Sprint(got) != fmt.Sprint(exp) {
+		t.Errorf("unexpected history after purging by maximum records got %v exp %v", got, exp)
+	}
+}
@@ -0,0 +1,230 @@
+package alert
+
+import (
+	"fmt"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// inhibition is an Inhibition with the source events that are currently inhibiting target events.
+// Source events are rebuilt from the state of the topics when the inhibition is loaded, created or replaced.
+type inhibition struct {
+	Inhibition
+	// Tags of the source events at or above the level, keyed by topic and event ID.
+	sources map[inhibitionSource]map[string]string
+}
+
+type inhibitionSource struct {
+	Topic   string
+	EventID string
+}
+
+func newInhibition(i Inhibition) (*inhibition, error) {
+	if err := i.Validate(); err != nil {
+		return nil, err
+	}
+	return &inhibition{
+		Inhibition: i,
+		sources:    make(map[inhibitionSource]map[string]string),
+	}, nil
+}
+
+// observe records or forgets the event as a source of the inhibition.
+func (i *inhibition) observe(event alert.Event) {
+	if !alert.PatternMatch(i.SourceTopic, event.Topic) {
+		return
+	}
+	for tag, pattern := range i.SourceTags {
+		value, ok := event.Data.Tags[tag]
+		if !ok || !alert.PatternMatch(pattern, value) {
+			return
+		}
+	}
+	src := inhibitionSource{Topic: event.Topic, EventID: event.State.ID}
+	if event.State.Level >= i.Level {
+		i.sources[src] = event.Data.Tags
+	} else {
+		delete(i.sources, src)
+	}
+}
+
+// observeTopics records the current events of the topics as sources of the inhibition,
+// so that events collected before the inhibition existed still inhibit target events.
+func (i *inhibition) observeTopics(topics *alert.Topics) {
+	for id := range topics.TopicState(i.SourceTopic, i.Level) {
+		topic, ok := topics.Topic(id)
+		if !ok {
+			continue
+		}
+		for _, state := range topic.EventStates(i.Level) {
+			i.observe(alert.Event{
+				Topic: id,
+				State: state,
+				Data:  alert.EventData{Tags: state.Tags},
+			})
+		}
+	}
+}
+
+// inhibits reports whether the event is inhibited by any of the source events.
+// An event never inhibits itself.
+func (i *inhibition) inhibits(event alert.Event) bool {
+	target := false
+	for _, pattern := range i.TargetTopics {
+		if alert.PatternMatch(pattern, event.Topic) {
+			target = true
+			break
+		}
+	}
+	if !target {
+		return false
+	}
+	self := inhibitionSource{Topic: event.Topic, EventID: event.State.ID}
+	for src, tags := range i.sources {
+		if src == self {
+			continue
+		}
+		equal := true
+		for _, tag := range i.Equal {
+			if tags[tag] != event.Data.Tags[tag] {
+				equal = false
+				break
+			}
+		}
+		if equal {
+			return true
+		}
+	}
+	return false
+}
+
+// forgetTopic removes all source events of the topic.
+func (i *inhibition) forgetTopic(topic string) {
+	for src := range i.sources {
+		if src.Topic == topic {
+			delete(i.sources, src)
+		}
+	}
+}
+
+func (s *Service) loadSavedInhibitions() error {
+	offset := 0
+	limit := 100
+	for {
+		inhibitions, err := s.inhibitionsDAO.List("", offset, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, in := range inhibitions {
+			inhibition, err := newInhibition(in)
+			if err != nil {
+				s.logger.Printf("E! failed to load inhibition %q on startup: %v", in.ID, err)
+				continue
+			}
+			inhibition.observeTopics(s.topics)
+			s.inhibitions[in.ID] = inhibition
+		}
+
+		offset += limit
+		if len(inhibitions) != limit {
+			break
+		}
+	}
+	return nil
+}
+
+// Inhibited records the event as a source of the inhibitions it matches,
+// and reports whether the event is inhibited by a source event of another inhibition.
+func (s *Service) Inhibited(event alert.Event) bool {
+	s.inhibitionsMu.Lock()
+	defer s.inhibitionsMu.Unlock()
+	for _, in := range s.inhibitions {
+		in.observe(event)
+	}
+	for _, in := range s.inhibitions {
+		if in.inhibits(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// forgetInhibitionSources removes the events of a deleted topic from all inhibitions.
+func (s *Service) forgetInhibitionSources(topic string) {
+	s.inhibitionsMu.Lock()
+	defer s.inhibitionsMu.Unlock()
+	for _, in := range s.inhibitions {
+		in.forgetTopic(topic)
+	}
+}
+
+func (s *Service) CreateInhibition(i Inhibition) error {
+	inhibition, err := newInhibition(i)
+	if err != nil {
+		return err
+	}
+
+	s.inhibitionsMu.Lock()
+	defer s.inhibitionsMu.Unlock()
+	if _, ok := s.inhibitions[i.ID]; ok {
+		return fmt.Errorf("cannot create inhibition, inhibition with ID %q already exists", i.ID)
+	}
+	if err := s.inhibitionsDAO.Create(i); err != nil {
+		return err
+	}
+	inhibition.observeTopics(s.topics)
+	s.inhibitions[i.ID] = inhibition
+	return nil
+}
+
+// ReplaceInhibition replaces an existing inhibition.
+// The source events of the inhibition are rebuilt from the current topic state, since they may no longer match.
+func (s *Service) ReplaceInhibition(i Inhibition) error {
+	inhibition, err := newInhibition(i)
+	if err != nil {
+		return err
+	}
+
+	s.inhibitionsMu.Lock()
+	defer s.inhibitionsMu.Unlock()
+	if err := s.inhibitionsDAO.Replace(i); err != nil {
+		return err
+	}
+	inhibition.observeTopics(s.topics)
+	s.inhibitions[i.ID] = inhibition
+	return nil
+}
+
+func (s *Service) DeleteInhibition(id string) error {
+	s.inhibitionsMu.Lock()
+	defer s.inhibitionsMu.Unlock()
+	if err := s.inhibitionsDAO.Delete(id); err != nil {
+		return err
+	}
+	delete(s.inhibitions, id)
+	return nil
+}
+
+func (s *Service) Inhibition(id string) (Inhibition, bool) {
+	s.inhibitionsMu.RLock()
+	defer s.inhibitionsMu.RUnlock()
+	in, ok := s.inhibitions[id]
+	if !ok {
+		return Inhibition{}, false
+	}
+	return in.Inhibition, true
+}
+
+// Inhibitions returns the inhibitions with IDs matching the pattern.
+func (s *Service) Inhibitions(pattern string) []Inhibition {
+	s.inhibitionsMu.RLock()
+	defer s.inhibitionsMu.RUnlock()
+	inhibitions := make([]Inhibition, 0, len(s.inhibitions))
+	for id, in := range s.inhibitions {
+		if alert.PatternMatch(pattern, id) {
+			inhibitions = append(inhibitions, in.Inhibition)
+		}
+	}
+	return inhibitions
+}
@@ -0,0 +1,89 @@
+package alert
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+func newTestInhibition(t *testing.T) *inhibition {
+	in, err := newInhibition(Inhibition{
+		ID:           "db_down",
+		SourceTopic:  "db",
+		Level:        alert.Critical,
+		TargetTopics: []string{"app*"},
+		Equal:        []string{"host"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return in
+}
+
+func hostEvent(topic, id, host string, level alert.Level) alert.Event {
+	return alert.Event{
+		Topic: topic,
+		State: alert.EventState{
+			ID:    id,
+			Level: level,
+		},
+		Data: alert.EventData{
+			Tags: map[string]string{"host": host},
+		},
+	}
+}
+
+func TestInhibition_SourceRecovery(t *testing.T) {
+	in := newTestInhibition(t)
+	target := hostEvent("app", "latency", "serverA", alert.Critical)
+	other := hostEvent("app", "latency", "serverB", alert.Critical)
+
+	steps := []struct {
+		source    alert.Level
+		inhibited bool
+	}{
+		{source: alert.Critical, inhibited: true},
+		// Sources below the level of the inhibition recover it.
+		{source: alert.Warning, inhibited: false},
+		{source: alert.Critical, inhibited: true},
+		{source: alert.OK, inhibited: false},
+	}
+	for i, s := range steps {
+		in.observe(hostEvent("db", "down", "serverA", s.source))
+		if got := in.inhibits(target); got != s.inhibited {
+			t.Errorf("%d: unexpected inhibited after %v source got %t exp %t", i, s.source, got, s.inhibited)
+		}
+		// Events with other tags are never inhibited.
+		if in.inhibits(other) {
+			t.Errorf("%d: unexpected inhibited event of another host", i)
+		}
+	}
+}
+
+func TestInhibition_ObserveTopics(t *testing.T) {
+	topics := alert.NewTopics(log.New(os.Stderr, "[alert] ", log.LstdFlags))
+	defer topics.Close()
+	target := hostEvent("app", "latency", "serverA", alert.Critical)
+
+	if err := topics.Collect(hostEvent("db", "down", "serverA", alert.Critical)); err != nil {
+		t.Fatal(err)
+	}
+	// Source events collected before the inhibition existed inhibit target events.
+	in := newTestInhibition(t)
+	in.observeTopics(topics)
+	if !in.inhibits(target) {
+		t.Error("expected event to be inhibited by an existing source event")
+	}
+
+	// Recovered source events do not inhibit target events once the inhibition is rebuilt.
+	if err := topics.Collect(hostEvent("db", "down", "serverA", alert.OK)); err != nil {
+		t.Fatal(err)
+	}
+	in = newTestInhibition(t)
+	in.observeTopics(topics)
+	if in.inhibits(target) {
+		t.Error("unexpected event inhibited by a recovered source event")
+	}
+}
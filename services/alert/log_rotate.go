@@ -0,0 +1,199 @@
+package alert
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format of the time in the names of rotated log files.
+// Names of rotated files sort in the order the files were rotated.
+const logBackupTimeFormat = "20060102T150405.000000000"
+
+const gzipExt = ".gz"
+
+// pathLocks are locks by the path of a file.
+type pathLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock locks the file at path and returns the function unlocking it.
+func (p *pathLocks) lock(path string) func() {
+	p.mu.Lock()
+	l, ok := p.locks[path]
+	if !ok {
+		l = new(sync.Mutex)
+		p.locks[path] = l
+	}
+	p.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+// logFileLocks serializes the writes and rotations of the log files,
+// which may be shared by the log handlers of several tasks and topics.
+var logFileLocks = &pathLocks{locks: make(map[string]*sync.Mutex)}
+
+// logBackupLocks serializes the compression and removal of the rotated files of each log file,
+// which happen in the background so that writes to the log file are not blocked.
+var logBackupLocks = &pathLocks{locks: make(map[string]*sync.Mutex)}
+
+// lockLogFile locks the log file at path and returns the function unlocking it.
+func lockLogFile(path string) func() {
+	return logFileLocks.lock(path)
+}
+
+// logRotator rotates a log file based on its size and on the time it was last written.
+// Rotated files are renamed with the time of the rotation appended to the name of the file.
+type logRotator struct {
+	path        string
+	mode        os.FileMode
+	maxSize     int64
+	rotateEvery time.Duration
+	maxBackups  int
+	compress    bool
+	logger      *log.Logger
+}
+
+// rotateBefore rotates the file if writing n more bytes to it exceeds the maximum size,
+// or if it was last written in a previous interval.
+// Caller must have the lock of the file.
+func (r logRotator) rotateBefore(n int) error {
+	if r.maxSize == 0 && r.rotateEvery == 0 {
+		return nil
+	}
+	fi, err := os.Stat(r.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Size() == 0 {
+		return nil
+	}
+	now := time.Now()
+	rotate := r.maxSize > 0 && fi.Size()+int64(n) > r.maxSize ||
+		r.rotateEvery > 0 && !fi.ModTime().Truncate(r.rotateEvery).Equal(now.Truncate(r.rotateEvery))
+	if !rotate {
+		return nil
+	}
+	return r.rotate(now)
+}
+
+// rotate renames the file and removes the oldest rotated files.
+// Rotated files are compressed in the background, since compressing a large file would block writes to the log file.
+func (r logRotator) rotate(now time.Time) error {
+	backup := r.path + "." + now.UTC().Format(logBackupTimeFormat)
+	if err := os.Rename(r.path, backup); err != nil {
+		return err
+	}
+	if r.compress {
+		go r.compressBackups()
+		return nil
+	}
+	if r.maxBackups > 0 {
+		unlock := logBackupLocks.lock(r.path)
+		defer unlock()
+		return r.removeBackups()
+	}
+	return nil
+}
+
+// compressBackups compresses the rotated files that are not compressed yet and removes the oldest rotated files.
+// Files left uncompressed, i.e. when Kapacitor stopped while compressing them, are compressed with the next rotation.
+func (r logRotator) compressBackups() {
+	unlock := logBackupLocks.lock(r.path)
+	defer unlock()
+	backups, err := r.backups()
+	if err != nil {
+		r.logger.Printf("E! failed to list rotated alert log files of %s: %v", r.path, err)
+		return
+	}
+	for _, backup := range backups {
+		if strings.HasSuffix(backup, gzipExt) {
+			continue
+		}
+		if err := compressFile(backup, r.mode); err != nil {
+			r.logger.Printf("E! failed to compress %s: %v", backup, err)
+		}
+	}
+	if r.maxBackups > 0 {
+		if err := r.removeBackups(); err != nil {
+			r.logger.Printf("E! failed to remove rotated alert log files of %s: %v", r.path, err)
+		}
+	}
+}
+
+// backups returns the paths of the rotated files from the oldest to the newest.
+func (r logRotator) backups() ([]string, error) {
+	dir, name := filepath.Split(r.path)
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := name + "."
+	var backups []string
+	for _, fi := range infos {
+		if fi.IsDir() || !strings.HasPrefix(fi.Name(), prefix) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimPrefix(fi.Name(), prefix), gzipExt)
+		if _, err := time.Parse(logBackupTimeFormat, ts); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, fi.Name()))
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// removeBackups removes the oldest rotated files so that at most maxBackups are kept.
+func (r logRotator) removeBackups() error {
+	backups, err := r.backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > r.maxBackups {
+		if err := os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// compressFile replaces the file at path with a gzip compressed copy with the .gz extension.
+func compressFile(path string, mode os.FileMode) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+gzipExt, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
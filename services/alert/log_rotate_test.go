@@ -0,0 +1,144 @@
+package alert_test
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/kapacitor/alert"
+	alertservice "github.com/influxdata/kapacitor/services/alert"
+)
+
+func TestLogHandler_RotateEvery(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "TestLogHandler_RotateEvery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	logPath := filepath.Join(tmpDir, "alerts.log")
+
+	c := alertservice.DefaultLogHandlerConfig()
+	c.Path = logPath
+	c.Format = alertservice.LogFormatText
+	c.Template = "{{ .Message }}"
+	c.RotateEvery = toml.Duration(time.Hour)
+	c.MaxBackups = 1
+	h, err := alertservice.NewLogHandler(c, log.New(os.Stderr, "[log] ", log.LstdFlags))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handle := func(message string) {
+		if err := h.Handle(alert.Event{State: alert.EventState{Message: message}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// age makes the log file look like it was last written in a previous interval.
+	age := func() {
+		past := time.Now().Add(-2 * time.Hour)
+		if err := os.Chtimes(logPath, past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handle("first")
+	handle("second")
+	age()
+	handle("third")
+	age()
+	handle("fourth")
+
+	backups, err := filepath.Glob(logPath + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("unexpected rotated files: got %v exp 1 file", backups)
+	}
+	for path, exp := range map[string]string{
+		backups[0]: "third\n",
+		logPath:    "fourth\n",
+	} {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); got != exp {
+			t.Errorf("unexpected content of %s: got %q exp %q", path, got, exp)
+		}
+	}
+}
+
+func TestLogHandler_Compress(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "TestLogHandler_Compress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	logPath := filepath.Join(tmpDir, "alerts.log")
+
+	c := alertservice.DefaultLogHandlerConfig()
+	c.Path = logPath
+	c.Format = alertservice.LogFormatText
+	c.Template = "{{ .Message }}"
+	c.MaxSize = 1
+	c.MaxBackups = 2
+	c.Compress = true
+	h, err := alertservice.NewLogHandler(c, log.New(os.Stderr, "[log] ", log.LstdFlags))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each message rotates the file, since it exceeds the maximum size.
+	for _, message := range []string{"first", "second", "third", "fourth"} {
+		if err := h.Handle(alert.Event{State: alert.EventState{Message: message}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Rotated files are compressed in the background, and only the newest are kept.
+	var backups []string
+	for i := 0; ; i++ {
+		backups, err = filepath.Glob(logPath + ".*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(backups) == 2 && filepath.Ext(backups[0]) == ".gz" && filepath.Ext(backups[1]) == ".gz" {
+			break
+		}
+		if i > 100 {
+			t.Fatalf("expected two compressed rotated files, got %v", backups)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for i, exp := range []string{"second\n", "third\n"} {
+		f, err := os.Open(backups[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(gz)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); got != exp {
+			t.Errorf("unexpected content of %s: got %q exp %q", backups[i], got, exp)
+		}
+	}
+	data, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := "fourth\n", string(data); got != exp {
+		t.Errorf("unexpected content of %s: got %q exp %q", logPath, got, exp)
+	}
+}
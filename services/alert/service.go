@@ -9,13 +9,17 @@ import (
 	"reflect"
 	"regexp"
 	"sync"
+	"time"
 
 	"github.com/influxdata/kapacitor/alert"
 	"github.com/influxdata/kapacitor/command"
 	"github.com/influxdata/kapacitor/services/alerta"
+	"github.com/influxdata/kapacitor/services/alertmanager"
 	"github.com/influxdata/kapacitor/services/hipchat"
 	"github.com/influxdata/kapacitor/services/httpd"
 	"github.com/influxdata/kapacitor/services/httppost"
+	"github.com/influxdata/kapacitor/services/jira"
+	"github.com/influxdata/kapacitor/services/kafka"
 	"github.com/influxdata/kapacitor/services/mqtt"
 	"github.com/influxdata/kapacitor/services/opsgenie"
 	"github.com/influxdata/kapacitor/services/pagerduty"
@@ -25,6 +29,8 @@ import (
 	"github.com/influxdata/kapacitor/services/smtp"
 	"github.com/influxdata/kapacitor/services/snmptrap"
 	"github.com/influxdata/kapacitor/services/storage"
+	"github.com/influxdata/kapacitor/services/syslog"
+	"github.com/influxdata/kapacitor/services/teams"
 	"github.com/influxdata/kapacitor/services/telegram"
 	"github.com/influxdata/kapacitor/services/victorops"
 	"github.com/mitchellh/mapstructure"
@@ -34,8 +40,12 @@ import (
 type Service struct {
 	mu sync.RWMutex
 
-	specsDAO  HandlerSpecDAO
-	topicsDAO TopicStateDAO
+	specsDAO       HandlerSpecDAO
+	topicsDAO      TopicStateDAO
+	silencesDAO    SilenceDAO
+	inhibitionsDAO InhibitionDAO
+	escalationsDAO EscalationDAO
+	historyDAO     HistoryDAO
 
 	APIServer *apiServer
 
@@ -43,6 +53,24 @@ type Service struct {
 
 	closedTopics map[string]bool
 
+	silencesMu sync.RWMutex
+	silences   map[string]*silence
+
+	inhibitionsMu sync.RWMutex
+	inhibitions   map[string]*inhibition
+
+	historyRetention  time.Duration
+	historyMaxRecords int
+	// Serialize history record IDs
+	historyMu      sync.Mutex
+	lastHistorySeq int64
+
+	deadLetterTopic string
+	deadLetters     chan alert.Event
+
+	closing chan struct{}
+	wg      sync.WaitGroup
+
 	topics         *alert.Topics
 	EventCollector EventCollector
 
@@ -65,9 +93,18 @@ type Service struct {
 		DefaultHandlerConfig() alerta.HandlerConfig
 		Handler(alerta.HandlerConfig, *log.Logger) (alert.Handler, error)
 	}
+	AlertmanagerService interface {
+		Handler(alertmanager.HandlerConfig, string, alertmanager.EventStater, *log.Logger) alert.Handler
+	}
 	HipChatService interface {
 		Handler(hipchat.HandlerConfig, *log.Logger) alert.Handler
 	}
+	JiraService interface {
+		Handler(jira.HandlerConfig, jira.EventAnnotator, *log.Logger) (alert.Handler, error)
+	}
+	KafkaService interface {
+		Handler(kafka.HandlerConfig, *log.Logger) alert.Handler
+	}
 	MQTTService interface {
 		Handler(mqtt.HandlerConfig, *log.Logger) alert.Handler
 	}
@@ -81,7 +118,7 @@ type Service struct {
 		Handler(pushover.HandlerConfig, *log.Logger) alert.Handler
 	}
 	HTTPPostService interface {
-		Handler(httppost.HandlerConfig, *log.Logger) alert.Handler
+		Handler(httppost.HandlerConfig, *log.Logger) (alert.Handler, error)
 	}
 	SensuService interface {
 		Handler(sensu.HandlerConfig, *log.Logger) (alert.Handler, error)
@@ -95,9 +132,15 @@ type Service struct {
 	SNMPTrapService interface {
 		Handler(snmptrap.HandlerConfig, *log.Logger) (alert.Handler, error)
 	}
+	SyslogService interface {
+		Handler(syslog.HandlerConfig, *log.Logger) (alert.Handler, error)
+	}
 	TalkService interface {
 		Handler(*log.Logger) alert.Handler
 	}
+	TeamsService interface {
+		Handler(teams.HandlerConfig, *log.Logger) alert.Handler
+	}
 	TelegramService interface {
 		Handler(telegram.HandlerConfig, *log.Logger) alert.Handler
 	}
@@ -106,18 +149,28 @@ type Service struct {
 	}
 }
 
-func NewService(l *log.Logger) *Service {
+func NewService(c Config, l *log.Logger) *Service {
 	s := &Service{
-		handlers:     make(map[string]map[string]handler),
-		closedTopics: make(map[string]bool),
-		topics:       alert.NewTopics(l),
-		logger:       l,
-	}
+		handlers:          make(map[string]map[string]handler),
+		closedTopics:      make(map[string]bool),
+		silences:          make(map[string]*silence),
+		inhibitions:       make(map[string]*inhibition),
+		historyRetention:  time.Duration(c.HistoryRetention),
+		historyMaxRecords: c.HistoryMaxRecords,
+		deadLetterTopic:   c.DeadLetterTopic,
+		deadLetters:       make(chan alert.Event, deadLetterBufferSize),
+		topics:            alert.NewTopics(l),
+		logger:            l,
+	}
+	s.topics.Silencer = s
+	s.topics.Inhibitor = s
 	s.APIServer = &apiServer{
-		Registrar: s,
-		Topics:    s,
-		Persister: s,
-		logger:    l,
+		Registrar:   s,
+		Topics:      s,
+		Persister:   s,
+		Silences:    s,
+		Inhibitions: s,
+		logger:      l,
 	}
 	s.EventCollector = s
 	return s
@@ -128,6 +181,14 @@ const (
 	handlerSpecsAPIName = "handler-specs"
 	// Public name of the handler specs store.
 	topicStatesAPIName = "topic-states"
+	// Public name of the silences store.
+	silencesAPIName = "silences"
+	// Public name of the inhibitions store.
+	inhibitionsAPIName = "inhibitions"
+	// Public name of the escalations store.
+	escalationsAPIName = "escalations"
+	// Public name of the event history store.
+	historyAPIName = "history"
 	// The storage namespace for all task data.
 	alertNamespace = "alert_store"
 )
@@ -150,6 +211,30 @@ func (s *Service) Open() error {
 	}
 	s.topicsDAO = topicsDAO
 	s.StorageService.Register(topicStatesAPIName, s.topicsDAO)
+	silencesDAO, err := newSilenceKV(store)
+	if err != nil {
+		return err
+	}
+	s.silencesDAO = silencesDAO
+	s.StorageService.Register(silencesAPIName, s.silencesDAO)
+	inhibitionsDAO, err := newInhibitionKV(store)
+	if err != nil {
+		return err
+	}
+	s.inhibitionsDAO = inhibitionsDAO
+	s.StorageService.Register(inhibitionsAPIName, s.inhibitionsDAO)
+	escalationsDAO, err := newEscalationKV(store)
+	if err != nil {
+		return err
+	}
+	s.escalationsDAO = escalationsDAO
+	s.StorageService.Register(escalationsAPIName, s.escalationsDAO)
+	historyDAO, err := newHistoryKV(store)
+	if err != nil {
+		return err
+	}
+	s.historyDAO = historyDAO
+	s.StorageService.Register(historyAPIName, s.historyDAO)
 
 	// Migrate v1.2 handlers
 	if err := s.migrateHandlerSpecs(store); err != nil {
@@ -166,18 +251,52 @@ func (s *Service) Open() error {
 		return err
 	}
 
+	// Load saved silences
+	if err := s.loadSavedSilences(); err != nil {
+		return err
+	}
+
+	// Load saved inhibitions
+	if err := s.loadSavedInhibitions(); err != nil {
+		return err
+	}
+
 	s.APIServer.HTTPDService = s.HTTPDService
 	if err := s.APIServer.Open(); err != nil {
 		return err
 	}
 
+	s.closing = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.runDeadLetters()
+	}()
+	if s.historyRetention > 0 || s.historyMaxRecords > 0 {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.runHistoryPurge()
+		}()
+	}
 	return nil
 }
 
 func (s *Service) Close() error {
+	if s.closing != nil {
+		close(s.closing)
+		s.wg.Wait()
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.topics.Close()
+	for _, handlers := range s.handlers {
+		for _, h := range handlers {
+			if c, ok := h.Handler.(closer); ok {
+				c.Close()
+			}
+		}
+	}
 	return s.APIServer.Close()
 }
 
@@ -337,14 +456,26 @@ func (s *Service) convertEventStatesToAlert(states map[string]EventState) map[st
 	return newStates
 }
 func (s *Service) convertEventStateToAlert(id string, state EventState) alert.EventState {
-	return alert.EventState{
-		ID:       id,
-		Message:  state.Message,
-		Details:  state.Details,
-		Time:     state.Time,
-		Duration: state.Duration,
-		Level:    state.Level,
+	es := alert.EventState{
+		ID:          id,
+		Message:     state.Message,
+		Details:     state.Details,
+		Time:        state.Time,
+		Duration:    state.Duration,
+		Level:       state.Level,
+		Inhibited:   state.Inhibited,
+		Annotations: state.Annotations,
+		Tags:        state.Tags,
+	}
+	if state.Ack != nil {
+		es.Ack = &alert.Ack{
+			By:      state.Ack.By,
+			Comment: state.Ack.Comment,
+			Time:    state.Ack.Time,
+			Expires: state.Ack.Expires,
+		}
 	}
+	return es
 }
 
 func (s *Service) convertEventStatesFromAlert(states map[string]alert.EventState) map[string]EventState {
@@ -356,13 +487,25 @@ func (s *Service) convertEventStatesFromAlert(states map[string]alert.EventState
 }
 
 func (s *Service) convertEventStateFromAlert(state alert.EventState) EventState {
-	return EventState{
-		Message:  state.Message,
-		Details:  state.Details,
-		Time:     state.Time,
-		Duration: state.Duration,
-		Level:    state.Level,
+	es := EventState{
+		Message:     state.Message,
+		Details:     state.Details,
+		Time:        state.Time,
+		Duration:    state.Duration,
+		Level:       state.Level,
+		Inhibited:   state.Inhibited,
+		Annotations: state.Annotations,
+		Tags:        state.Tags,
+	}
+	if state.Ack != nil {
+		es.Ack = &Ack{
+			By:      state.Ack.By,
+			Comment: state.Ack.Comment,
+			Time:    state.Ack.Time,
+			Expires: state.Ack.Expires,
+		}
 	}
+	return es
 }
 
 func (s *Service) loadSavedTopicStates() error {
@@ -410,10 +553,14 @@ func (s *Service) Collect(event alert.Event) error {
 		}
 	}
 
-	err := s.topics.Collect(event)
+	previous, _, err := s.EventState(event.Topic, event.State.ID)
 	if err != nil {
 		return err
 	}
+	if err := s.topics.Collect(event); err != nil {
+		return err
+	}
+	s.recordTransition(event, previous.Level)
 	return s.persistTopicState(event.Topic)
 }
 
@@ -487,6 +634,10 @@ func (s *Service) DeleteTopic(topic string) error {
 	defer s.mu.Unlock()
 	delete(s.closedTopics, topic)
 	s.topics.DeleteTopic(topic)
+	s.forgetInhibitionSources(topic)
+	if err := s.deleteHistory(topic); err != nil {
+		return err
+	}
 	return s.topicsDAO.Delete(topic)
 }
 
@@ -495,6 +646,30 @@ func (s *Service) UpdateEvent(topic string, event alert.EventState) error {
 	return s.persistTopicState(topic)
 }
 
+// AckEvent acknowledges an event and persists the acknowledgement.
+func (s *Service) AckEvent(topic, event string, ack alert.Ack) error {
+	if err := s.topics.AckEvent(topic, event, ack); err != nil {
+		return err
+	}
+	return s.persistTopicState(topic)
+}
+
+// UnackEvent removes the acknowledgement of an event.
+func (s *Service) UnackEvent(topic, event string) error {
+	if err := s.topics.UnackEvent(topic, event); err != nil {
+		return err
+	}
+	return s.persistTopicState(topic)
+}
+
+// AnnotateEvent sets an annotation of an event and persists it.
+func (s *Service) AnnotateEvent(topic, event, key, value string) error {
+	if err := s.topics.AnnotateEvent(topic, event, key, value); err != nil {
+		return err
+	}
+	return s.persistTopicState(topic)
+}
+
 func (s *Service) RegisterAnonHandler(topic string, h alert.Handler) {
 	s.topics.RegisterHandler(topic, h)
 }
@@ -531,11 +706,13 @@ func (s *Service) RegisterHandlerSpec(spec HandlerSpec) error {
 
 	_, ok := s.handlers[spec.Topic][spec.ID]
 	if ok {
+		closeHandler(h)
 		return fmt.Errorf("cannot register handler, handler with ID %q already exists", spec.ID)
 	}
 
 	// Persist handler spec
 	if err := s.specsDAO.Create(spec); err != nil {
+		closeHandler(h)
 		return err
 	}
 
@@ -549,6 +726,12 @@ type closer interface {
 	Close()
 }
 
+func closeHandler(h handler) {
+	if c, ok := h.Handler.(closer); ok {
+		c.Close()
+	}
+}
+
 func (s *Service) DeregisterHandlerSpec(topic, handler string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -561,12 +744,15 @@ func (s *Service) DeregisterHandlerSpec(topic, handler string) error {
 			return err
 		}
 		s.topics.DeregisterHandler(topic, h.Handler)
-
-		if ha, ok := h.Handler.(closer); ok {
-			ha.Close()
-		}
+		closeHandler(h)
 
 		delete(s.handlers[h.Spec.Topic], handler)
+
+		if h.Spec.Kind == "escalate" {
+			if err := s.deleteEscalations(topic, handler); err != nil {
+				return errors.Wrap(err, "failed to delete pending escalations")
+			}
+		}
 	}
 	return nil
 }
@@ -579,26 +765,43 @@ func (s *Service) UpdateHandlerSpec(oldSpec, newSpec HandlerSpec) error {
 		return errors.New("cannot change topic in update")
 	}
 	topic := newSpec.Topic
-	newH, err := s.createHandlerFromSpec(newSpec)
-	if err != nil {
-		return err
-	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	oldH := s.handlers[topic][oldSpec.ID]
 
+	// An escalate handler with the same ID resumes the pending escalations of the old handler.
+	// The old handler is stopped first, so that its timers do not notify the stages again
+	// while the new handler is created, and it is recreated if the update fails.
+	resume := oldSpec.Kind == "escalate" && newSpec.Kind == "escalate" && newSpec.ID == oldSpec.ID
+	if resume {
+		closeHandler(oldH)
+	}
+	newH, err := s.createHandlerFromSpec(newSpec)
+	if err != nil {
+		if resume {
+			s.reopenHandler(oldH)
+		}
+		return err
+	}
+
 	// Persist new handler specs
 	if newSpec.ID == oldSpec.ID {
 		if err := s.specsDAO.Replace(newSpec); err != nil {
+			closeHandler(newH)
+			if resume {
+				s.reopenHandler(oldH)
+			}
 			return err
 		}
 	} else {
 		if err := s.specsDAO.Create(newSpec); err != nil {
+			closeHandler(newH)
 			return err
 		}
 		if err := s.specsDAO.Delete(oldSpec.Topic, oldSpec.ID); err != nil {
+			closeHandler(newH)
 			return err
 		}
 	}
@@ -607,9 +810,31 @@ func (s *Service) UpdateHandlerSpec(oldSpec, newSpec HandlerSpec) error {
 	s.setTopicHandler(newSpec.Topic, newSpec.ID, newH)
 
 	s.topics.ReplaceHandler(topic, oldH.Handler, newH.Handler)
+	if !resume {
+		closeHandler(oldH)
+	}
+
+	// Pending escalations are resumed by the new handler if it has the same ID.
+	if oldSpec.Kind == "escalate" && (newSpec.Kind != "escalate" || newSpec.ID != oldSpec.ID) {
+		if err := s.deleteEscalations(oldSpec.Topic, oldSpec.ID); err != nil {
+			return errors.Wrap(err, "failed to delete pending escalations")
+		}
+	}
 	return nil
 }
 
+// reopenHandler replaces a closed handler with a new handler created from its spec.
+// Caller must have lock.
+func (s *Service) reopenHandler(h handler) {
+	newH, err := s.createHandlerFromSpec(h.Spec)
+	if err != nil {
+		s.logger.Printf("E! failed to reopen handler %q of topic %q: %v", h.Spec.ID, h.Spec.Topic, err)
+		return
+	}
+	s.setTopicHandler(h.Spec.Topic, h.Spec.ID, newH)
+	s.topics.ReplaceHandler(h.Spec.Topic, h.Handler, newH.Handler)
+}
+
 // TopicState returns the state for the specified topic.
 func (s *Service) TopicState(topic string) (alert.TopicState, bool, error) {
 	t, ok := s.topics.Topic(topic)
@@ -739,6 +964,53 @@ func (s *Service) createHandlerFromSpec(spec HandlerSpec) (handler, error) {
 			return handler{}, err
 		}
 		h = newExternalHandler(h)
+	case "alertmanager":
+		c := alertmanager.HandlerConfig{}
+		err = decodeOptions(spec.Options, &c)
+		if err != nil {
+			return handler{}, err
+		}
+		h = s.AlertmanagerService.Handler(c, spec.Topic, s, s.logger)
+		h = newExternalHandler(h)
+	case "escalate":
+		c := DefaultEscalateHandlerConfig()
+		err = decodeOptions(spec.Options, &c)
+		if err != nil {
+			return handler{}, err
+		}
+		if err := c.Validate(); err != nil {
+			return handler{}, err
+		}
+		stages := make([][]alert.Handler, len(c.Stages))
+		// closeStages closes the handlers of the stages that have already been created.
+		closeStages := func() {
+			for _, handlers := range stages {
+				for _, ah := range handlers {
+					closeHandler(handler{Handler: ah})
+				}
+			}
+		}
+		for i, stage := range c.Stages {
+			for j, action := range stage.Actions {
+				ah, err := s.createHandlerFromSpec(HandlerSpec{
+					ID:      fmt.Sprintf("%s-stage-%d-%d", spec.ID, i, j),
+					Topic:   spec.Topic,
+					Kind:    action.Kind,
+					Options: action.Options,
+					Match:   action.Match,
+				})
+				if err != nil {
+					closeStages()
+					return handler{}, errors.Wrapf(err, "invalid action %d of escalation stage %d", j, i)
+				}
+				stages[i] = append(stages[i], ah.Handler)
+			}
+		}
+		h, err = NewEscalateHandler(spec.Topic, spec.ID, c, stages, s, s.logger)
+		if err != nil {
+			closeStages()
+			return handler{}, err
+		}
 	case "exec":
 		c := ExecHandlerConfig{
 			Commander: s.Commander,
@@ -768,6 +1040,25 @@ func (s *Service) createHandlerFromSpec(spec HandlerSpec) (handler, error) {
 			return handler{}, err
 		}
 		h = newExternalHandler(h)
+	case "jira":
+		c := jira.HandlerConfig{}
+		err = decodeOptions(spec.Options, &c)
+		if err != nil {
+			return handler{}, err
+		}
+		h, err = s.JiraService.Handler(c, s, s.logger)
+		if err != nil {
+			return handler{}, err
+		}
+		h = newExternalHandler(h)
+	case "kafka":
+		c := kafka.HandlerConfig{}
+		err = decodeOptions(spec.Options, &c)
+		if err != nil {
+			return handler{}, err
+		}
+		h = s.KafkaService.Handler(c, s.logger)
+		h = newExternalHandler(h)
 	case "mqtt":
 		c := mqtt.HandlerConfig{}
 		err = decodeOptions(spec.Options, &c)
@@ -806,7 +1097,10 @@ func (s *Service) createHandlerFromSpec(spec HandlerSpec) (handler, error) {
 		if err != nil {
 			return handler{}, err
 		}
-		h = s.HTTPPostService.Handler(c, s.logger)
+		h, err = s.HTTPPostService.Handler(c, s.logger)
+		if err != nil {
+			return handler{}, err
+		}
 		h = newExternalHandler(h)
 	case "publish":
 		c := PublishHandlerConfig{
@@ -855,6 +1149,17 @@ func (s *Service) createHandlerFromSpec(spec HandlerSpec) (handler, error) {
 			return handler{}, err
 		}
 		h = newExternalHandler(h)
+	case "syslog":
+		c := syslog.HandlerConfig{}
+		err = decodeOptions(spec.Options, &c)
+		if err != nil {
+			return handler{}, err
+		}
+		h, err = s.SyslogService.Handler(c, s.logger)
+		if err != nil {
+			return handler{}, err
+		}
+		h = newExternalHandler(h)
 	case "talk":
 		h = s.TalkService.Handler(s.logger)
 		h = newExternalHandler(h)
@@ -866,6 +1171,14 @@ func (s *Service) createHandlerFromSpec(spec HandlerSpec) (handler, error) {
 		}
 		h = NewTCPHandler(c, s.logger)
 		h = newExternalHandler(h)
+	case "teams":
+		c := teams.HandlerConfig{}
+		err = decodeOptions(spec.Options, &c)
+		if err != nil {
+			return handler{}, err
+		}
+		h = s.TeamsService.Handler(c, s.logger)
+		h = newExternalHandler(h)
 	case "telegram":
 		c := telegram.HandlerConfig{}
 		err = decodeOptions(spec.Options, &c)
@@ -883,11 +1196,30 @@ func (s *Service) createHandlerFromSpec(spec HandlerSpec) (handler, error) {
 		h = s.VictorOpsService.Handler(c, s.logger)
 		h = newExternalHandler(h)
 	default:
-		err = fmt.Errorf("unsupported action kind %q", spec.Kind)
+		return handler{}, fmt.Errorf("unsupported action kind %q", spec.Kind)
 	}
 	if spec.Match != "" {
 		// Wrap handler in match handler
-		h, err = newMatchHandler(spec.Match, h, s.logger)
+		mh, err := newMatchHandler(spec.Match, h, s.logger)
+		if err != nil {
+			closeHandler(handler{Handler: h})
+			return handler{}, err
+		}
+		h = mh
+	}
+	if spec.Retry != nil || s.deadLetterTopic != "" {
+		// Wrap handler in retry handler
+		h = newRetryHandler(h, spec.Retry, s.deadLetterFunc(spec))
+	}
+	if spec.RateLimit != nil {
+		// Wrap retry handler in rate limit handler, so that retries are not rate limited.
+		topic := spec.Topic
+		rh, err := newRateLimitHandler(h, spec, func() { s.topics.SuppressEvent(topic) })
+		if err != nil {
+			closeHandler(handler{Handler: h})
+			return handler{}, err
+		}
+		h = rh
 	}
-	return handler{Spec: spec, Handler: h}, err
+	return handler{Spec: spec, Handler: h}, nil
 }
@@ -0,0 +1,84 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/storage"
+)
+
+func TestService_AckEvent(t *testing.T) {
+	s := newHistoryService(t)
+	topicsDAO, err := newTopicStateKV(storage.NewMemStore("topics"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.topicsDAO = topicsDAO
+	s.topics = alert.NewTopics(s.logger)
+	defer s.topics.Close()
+
+	collect := func(level alert.Level) {
+		event := testEvent("id")
+		event.State.Level = level
+		event.State.Time = time.Now()
+		if err := s.Collect(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// acked reports whether the acknowledgement of the event is kept and persisted.
+	acked := func() bool {
+		state, ok, err := s.EventState("topic", "id")
+		if err != nil || !ok {
+			t.Fatalf("expected event state to exist, err: %v", err)
+		}
+		ts, err := s.topicsDAO.Get("topic")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if persisted := ts.EventStates["id"].Ack != nil; persisted != state.Acknowledged() {
+			t.Fatalf("unexpected persisted acknowledgement %t, acknowledged %t", persisted, state.Acknowledged())
+		}
+		return state.Acknowledged()
+	}
+
+	collect(alert.Critical)
+	if err := s.AckEvent("topic", "id", alert.Ack{By: "bob", Time: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if !acked() {
+		t.Fatal("expected event to be acknowledged")
+	}
+	// The acknowledgement lasts while the level stays the same.
+	collect(alert.Critical)
+	if !acked() {
+		t.Error("expected acknowledgement to last while the level is the same")
+	}
+	// The acknowledgement ends when the level changes.
+	collect(alert.Warning)
+	if acked() {
+		t.Error("expected acknowledgement to end when the level changes")
+	}
+
+	// Expired acknowledgements end with the next event.
+	if err := s.AckEvent("topic", "id", alert.Ack{By: "bob", Time: time.Now(), Expires: time.Now().Add(-time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	collect(alert.Warning)
+	if acked() {
+		t.Error("expected expired acknowledgement to end")
+	}
+
+	if err := s.AckEvent("topic", "id", alert.Ack{By: "bob", Time: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UnackEvent("topic", "id"); err != nil {
+		t.Fatal(err)
+	}
+	if acked() {
+		t.Error("expected acknowledgement to be removed")
+	}
+	if err := s.AckEvent("topic", "unknown", alert.Ack{By: "bob"}); err == nil {
+		t.Error("expected error acknowledging an unknown event")
+	}
+}
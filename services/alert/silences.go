@@ -0,0 +1,177 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gorhill/cronexpr"
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// silence is a Silence with its parsed schedule.
+type silence struct {
+	Silence
+	schedule *cronexpr.Expression
+}
+
+func newSilence(s Silence) (*silence, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	sl := &silence{Silence: s}
+	if s.Schedule != "" {
+		expr, err := cronexpr.Parse(s.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		sl.schedule = expr
+	}
+	return sl, nil
+}
+
+// Active reports whether the silence is active at the given time.
+func (s *silence) Active(now time.Time) bool {
+	if !s.Start.IsZero() && now.Before(s.Start) {
+		return false
+	}
+	if !s.End.IsZero() && !now.Before(s.End) {
+		return false
+	}
+	if s.schedule != nil {
+		// The silence is active if the schedule had a time within the last duration.
+		next := s.schedule.Next(now.Add(-s.Duration))
+		return !next.IsZero() && !next.After(now)
+	}
+	return true
+}
+
+// Match reports whether the event matches all matchers of the silence.
+func (s *silence) Match(event alert.Event) bool {
+	if s.Topic != "" && !alert.PatternMatch(s.Topic, event.Topic) {
+		return false
+	}
+	if s.EventID != "" && !alert.PatternMatch(s.EventID, event.State.ID) {
+		return false
+	}
+	for tag, pattern := range s.Tags {
+		value, ok := event.Data.Tags[tag]
+		if !ok || !alert.PatternMatch(pattern, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Service) loadSavedSilences() error {
+	offset := 0
+	limit := 100
+	for {
+		silences, err := s.silencesDAO.List("", offset, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, sl := range silences {
+			silence, err := newSilence(sl)
+			if err != nil {
+				s.logger.Printf("E! failed to load silence %q on startup: %v", sl.ID, err)
+				continue
+			}
+			s.silences[sl.ID] = silence
+		}
+
+		offset += limit
+		if len(silences) != limit {
+			break
+		}
+	}
+	return nil
+}
+
+// Silenced reports whether the event is matched by an active silence.
+// Silences are active based on the current time, not the time of the event.
+func (s *Service) Silenced(event alert.Event) bool {
+	now := time.Now()
+	s.silencesMu.RLock()
+	defer s.silencesMu.RUnlock()
+	for _, sl := range s.silences {
+		if sl.Active(now) && sl.Match(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// SilenceActive reports whether the silence is currently active.
+func (s *Service) SilenceActive(id string) bool {
+	s.silencesMu.RLock()
+	defer s.silencesMu.RUnlock()
+	sl, ok := s.silences[id]
+	return ok && sl.Active(time.Now())
+}
+
+func (s *Service) CreateSilence(sl Silence) error {
+	silence, err := newSilence(sl)
+	if err != nil {
+		return err
+	}
+
+	s.silencesMu.Lock()
+	defer s.silencesMu.Unlock()
+	if _, ok := s.silences[sl.ID]; ok {
+		return fmt.Errorf("cannot create silence, silence with ID %q already exists", sl.ID)
+	}
+	if err := s.silencesDAO.Create(sl); err != nil {
+		return err
+	}
+	s.silences[sl.ID] = silence
+	return nil
+}
+
+func (s *Service) ReplaceSilence(sl Silence) error {
+	silence, err := newSilence(sl)
+	if err != nil {
+		return err
+	}
+
+	s.silencesMu.Lock()
+	defer s.silencesMu.Unlock()
+	if err := s.silencesDAO.Replace(sl); err != nil {
+		return err
+	}
+	s.silences[sl.ID] = silence
+	return nil
+}
+
+func (s *Service) DeleteSilence(id string) error {
+	s.silencesMu.Lock()
+	defer s.silencesMu.Unlock()
+	if err := s.silencesDAO.Delete(id); err != nil {
+		return err
+	}
+	delete(s.silences, id)
+	return nil
+}
+
+func (s *Service) Silence(id string) (Silence, bool) {
+	s.silencesMu.RLock()
+	defer s.silencesMu.RUnlock()
+	sl, ok := s.silences[id]
+	if !ok {
+		return Silence{}, false
+	}
+	return sl.Silence, true
+}
+
+// Silences returns the silences with IDs matching the pattern.
+func (s *Service) Silences(pattern string) []Silence {
+	s.silencesMu.RLock()
+	defer s.silencesMu.RUnlock()
+	silences := make([]Silence, 0, len(s.silences))
+	for id, sl := range s.silences {
+		if alert.PatternMatch(pattern, id) {
+			silences = append(silences, sl.Silence)
+		}
+	}
+	return silences
+}
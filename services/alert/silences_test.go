@@ -0,0 +1,73 @@
+package alert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSilence_Active_Schedule(t *testing.T) {
+	// Every night from 22:00 for eight hours, within the first week of March.
+	sl, err := newSilence(Silence{
+		ID:       "nightly",
+		Topic:    "topic",
+		Start:    time.Date(2017, 3, 1, 0, 0, 0, 0, time.UTC),
+		End:      time.Date(2017, 3, 8, 0, 0, 0, 0, time.UTC),
+		Schedule: "0 22 * * *",
+		Duration: 8 * time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		now    time.Time
+		active bool
+	}{
+		{now: time.Date(2017, 3, 2, 21, 59, 0, 0, time.UTC), active: false},
+		{now: time.Date(2017, 3, 2, 22, 0, 0, 0, time.UTC), active: true},
+		// The silence is active across midnight.
+		{now: time.Date(2017, 3, 3, 3, 0, 0, 0, time.UTC), active: true},
+		{now: time.Date(2017, 3, 3, 5, 59, 0, 0, time.UTC), active: true},
+		{now: time.Date(2017, 3, 3, 6, 0, 0, 0, time.UTC), active: false},
+		{now: time.Date(2017, 3, 3, 12, 0, 0, 0, time.UTC), active: false},
+		// The schedule recurs the next day.
+		{now: time.Date(2017, 3, 3, 23, 0, 0, 0, time.UTC), active: true},
+		// The schedule only recurs between start and end.
+		{now: time.Date(2017, 2, 28, 23, 0, 0, 0, time.UTC), active: false},
+		{now: time.Date(2017, 3, 1, 1, 0, 0, 0, time.UTC), active: true},
+		{now: time.Date(2017, 3, 7, 23, 0, 0, 0, time.UTC), active: true},
+		{now: time.Date(2017, 3, 8, 1, 0, 0, 0, time.UTC), active: false},
+	}
+	for _, tc := range testCases {
+		if got := sl.Active(tc.now); got != tc.active {
+			t.Errorf("unexpected active at %v got %t exp %t", tc.now, got, tc.active)
+		}
+	}
+}
+
+func TestSilence_Active_WeeklySchedule(t *testing.T) {
+	// Mondays from 09:00 for an hour.
+	sl, err := newSilence(Silence{
+		ID:       "maintenance",
+		Topic:    "topic",
+		Schedule: "0 9 * * MON",
+		Duration: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		now    time.Time
+		active bool
+	}{
+		// 2017-03-06 is a Monday.
+		{now: time.Date(2017, 3, 6, 9, 30, 0, 0, time.UTC), active: true},
+		{now: time.Date(2017, 3, 6, 10, 0, 0, 0, time.UTC), active: false},
+		{now: time.Date(2017, 3, 7, 9, 30, 0, 0, time.UTC), active: false},
+		{now: time.Date(2017, 3, 13, 9, 0, 0, 0, time.UTC), active: true},
+	}
+	for _, tc := range testCases {
+		if got := sl.Active(tc.now); got != tc.active {
+			t.Errorf("unexpected active at %v got %t exp %t", tc.now, got, tc.active)
+		}
+	}
+}
@@ -28,6 +28,14 @@ type Topics interface {
 	// EventStates returns the current state of events for the specified topic.
 	// Only events greater or equal to minLevel will be returned
 	EventStates(topic string, minLevel alert.Level) (map[string]alert.EventState, error)
+
+	// AckEvent acknowledges the event, replacing any existing acknowledgement.
+	AckEvent(topic, event string, ack alert.Ack) error
+	// UnackEvent removes the acknowledgement of the event.
+	UnackEvent(topic, event string) error
+
+	// History returns the level transitions of events of the topic that match the query, newest first.
+	History(topic string, q HistoryQuery) ([]HistoryRecord, error)
 }
 
 // AnonHandlerRegistrar is responsible for directly registering handlers for anonymous topics.
@@ -51,6 +59,11 @@ type Events interface {
 	UpdateEvent(topic string, event alert.EventState) error
 	// EventState returns the current events state.
 	EventState(topic, event string) (alert.EventState, bool, error)
+	// EventStates returns the current state of events for the specified topic.
+	// Only events greater or equal to minLevel will be returned
+	EventStates(topic string, minLevel alert.Level) (map[string]alert.EventState, error)
+	// AnnotateEvent sets an annotation of an event.
+	AnnotateEvent(topic, event, key, value string) error
 }
 
 // TopicPersister is responsible for controlling the persistence of topic state.
@@ -63,6 +76,34 @@ type TopicPersister interface {
 	RestoreTopic(topic string) error
 }
 
+type SilenceRegistrar interface {
+	// CreateSilence saves and activates a new silence.
+	CreateSilence(s Silence) error
+	// ReplaceSilence replaces an existing silence.
+	ReplaceSilence(s Silence) error
+	// DeleteSilence deletes a silence.
+	DeleteSilence(id string) error
+	// Silence returns a silence.
+	Silence(id string) (Silence, bool)
+	// Silences returns the silences with IDs matching the pattern.
+	Silences(pattern string) []Silence
+	// SilenceActive reports whether the silence is currently active.
+	SilenceActive(id string) bool
+}
+
+type InhibitionRegistrar interface {
+	// CreateInhibition saves and activates a new inhibition.
+	CreateInhibition(i Inhibition) error
+	// ReplaceInhibition replaces an existing inhibition.
+	ReplaceInhibition(i Inhibition) error
+	// DeleteInhibition deletes an inhibition.
+	DeleteInhibition(id string) error
+	// Inhibition returns an inhibition.
+	Inhibition(id string) (Inhibition, bool)
+	// Inhibitions returns the inhibitions with IDs matching the pattern.
+	Inhibitions(pattern string) []Inhibition
+}
+
 type handler struct {
 	Spec    HandlerSpec
 	Handler alert.Handler
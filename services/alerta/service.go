@@ -334,13 +334,12 @@ type eventData struct {
 	Tags map[string]string
 }
 
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
 	td := event.TemplateData()
 	var buf bytes.Buffer
 	err := h.resourceTmpl.Execute(&buf, td)
 	if err != nil {
-		h.logger.Printf("E! failed to evaluate Alerta Resource template %s: %v", h.c.Resource, err)
-		return
+		return alert.Permanent(fmt.Errorf("failed to evaluate Alerta Resource template %s: %v", h.c.Resource, err))
 	}
 	resource := buf.String()
 	buf.Reset()
@@ -354,32 +353,28 @@ func (h *handler) Handle(event alert.Event) {
 	}
 	err = h.eventTmpl.Execute(&buf, data)
 	if err != nil {
-		h.logger.Printf("E! failed to evaluate Alerta Event template %s: %v", h.c.Event, err)
-		return
+		return alert.Permanent(fmt.Errorf("failed to evaluate Alerta Event template %s: %v", h.c.Event, err))
 	}
 	eventStr := buf.String()
 	buf.Reset()
 
 	err = h.environmentTmpl.Execute(&buf, td)
 	if err != nil {
-		h.logger.Printf("E! failed to evaluate Alerta Environment template %s: %v", h.c.Environment, err)
-		return
+		return alert.Permanent(fmt.Errorf("failed to evaluate Alerta Environment template %s: %v", h.c.Environment, err))
 	}
 	environment := buf.String()
 	buf.Reset()
 
 	err = h.groupTmpl.Execute(&buf, td)
 	if err != nil {
-		h.logger.Printf("E! failed to evaluate Alerta Group template %s: %v", h.c.Group, err)
-		return
+		return alert.Permanent(fmt.Errorf("failed to evaluate Alerta Group template %s: %v", h.c.Group, err))
 	}
 	group := buf.String()
 	buf.Reset()
 
 	err = h.valueTmpl.Execute(&buf, td)
 	if err != nil {
-		h.logger.Printf("E! failed to evaluate Alerta Value template %s: %v", h.c.Value, err)
-		return
+		return alert.Permanent(fmt.Errorf("failed to evaluate Alerta Value template %s: %v", h.c.Value, err))
 	}
 	value := buf.String()
 
@@ -417,6 +412,7 @@ func (h *handler) Handle(event alert.Event) {
 		service,
 		event.Data.Result,
 	); err != nil {
-		h.logger.Printf("E! failed to send event to Alerta: %v", err)
+		return fmt.Errorf("failed to send event to Alerta: %v", err)
 	}
+	return nil
 }
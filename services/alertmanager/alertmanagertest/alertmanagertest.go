@@ -0,0 +1,89 @@
+package alertmanagertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+type Server struct {
+	mu       sync.Mutex
+	ts       *httptest.Server
+	URL      string
+	requests []Request
+	closed   bool
+}
+
+func NewServer() *Server {
+	s := new(Server)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ar := Request{
+			URL: r.URL.String(),
+		}
+		dec := json.NewDecoder(r.Body)
+		if err := dec.Decode(&ar.Alerts); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"status": "error",
+				"error":  err.Error(),
+			})
+			return
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, ar)
+		s.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]string{
+			"status": "success",
+		})
+	}))
+	s.ts = ts
+	s.URL = ts.URL
+	return s
+}
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// RequestsEndingAfter returns the requests with the end times of the alerts that end after t cleared,
+// since active alerts are posted with an end time in the future.
+func (s *Server) RequestsEndingAfter(t time.Time) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := make([]Request, len(s.requests))
+	for i, r := range s.requests {
+		requests[i] = Request{
+			URL:    r.URL,
+			Alerts: make([]Alert, len(r.Alerts)),
+		}
+		for j, a := range r.Alerts {
+			if a.EndsAt.After(t) {
+				a.EndsAt = time.Time{}
+			}
+			requests[i].Alerts[j] = a
+		}
+	}
+	return requests
+}
+func (s *Server) Close() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.ts.Close()
+}
+
+type Request struct {
+	URL    string
+	Alerts []Alert
+}
+
+type Alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
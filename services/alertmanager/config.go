@@ -0,0 +1,54 @@
+package alertmanager
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	// Whether Alertmanager integration is enabled.
+	Enabled bool `toml:"enabled" override:"enabled"`
+	// The URL of the Alertmanager server.
+	URL string `toml:"url" override:"url"`
+	// Whether to skip the tls verification of the Alertmanager host
+	InsecureSkipVerify bool `toml:"insecure-skip-verify" override:"insecure-skip-verify"`
+	// How often active alerts are posted again.
+	// Alerts are resolved by Alertmanager if they are not posted again within four intervals.
+	ResendInterval toml.Duration `toml:"resend-interval" override:"resend-interval"`
+}
+
+const (
+	DefaultURL            = "http://localhost:9093"
+	DefaultResendInterval = time.Minute
+)
+
+func NewConfig() Config {
+	return Config{
+		URL:            DefaultURL,
+		ResendInterval: toml.Duration(DefaultResendInterval),
+	}
+}
+
+// resendInterval returns the interval of posting active alerts again, zero uses the default interval.
+func (c Config) resendInterval() time.Duration {
+	if c.ResendInterval == 0 {
+		return DefaultResendInterval
+	}
+	return time.Duration(c.ResendInterval)
+}
+
+func (c Config) Validate() error {
+	if c.Enabled && c.URL == "" {
+		return errors.New("must specify url")
+	}
+	if _, err := url.Parse(c.URL); err != nil {
+		return errors.Wrapf(err, "invalid url %q", c.URL)
+	}
+	if c.ResendInterval < 0 {
+		return errors.New("resend-interval cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+package alertmanager
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/pkg/errors"
+)
+
+// alertNameLabel is the label containing the ID of the alert.
+const alertNameLabel = "alertname"
+
+// resolveIntervals is the number of resend intervals after which Alertmanager resolves an active alert,
+// unless the alert is posted again.
+const resolveIntervals = 4
+
+type Service struct {
+	configValue atomic.Value
+	clientValue atomic.Value
+	logger      *log.Logger
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	s := &Service{
+		logger: l,
+	}
+	s.configValue.Store(c)
+	s.clientValue.Store(newClient(c))
+	return s
+}
+
+func newClient(c Config) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify},
+		},
+	}
+}
+
+func (s *Service) Open() error {
+	return nil
+}
+
+func (s *Service) Close() error {
+	return nil
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if l := len(newConfig); l != 1 {
+		return fmt.Errorf("expected only one new config object, got %d", l)
+	}
+	if c, ok := newConfig[0].(Config); !ok {
+		return fmt.Errorf("expected config object to be of type %T, got %T", c, newConfig[0])
+	} else {
+		s.configValue.Store(c)
+		s.clientValue.Store(newClient(c))
+	}
+	return nil
+}
+
+type testOptions struct {
+	AlertID string            `json:"alert-id"`
+	Message string            `json:"message"`
+	Level   alert.Level       `json:"level"`
+	Labels  map[string]string `json:"labels"`
+}
+
+func (s *Service) TestOptions() interface{} {
+	return &testOptions{
+		AlertID: "testAlertID",
+		Message: "test alertmanager message",
+		Level:   alert.Critical,
+		Labels:  map[string]string{},
+	}
+}
+
+func (s *Service) Test(options interface{}) error {
+	o, ok := options.(*testOptions)
+	if !ok {
+		return fmt.Errorf("unexpected options type %T", options)
+	}
+	now := time.Now()
+	return s.Alert(o.AlertID, o.Message, "", o.Level, now, now, o.Labels)
+}
+
+// postedAlert is an alert as posted to the Alertmanager v1 alerts API.
+type postedAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
+
+// Alert posts an alert to Alertmanager.
+// The alert is resolved if the level is OK, in which case t is the time the alert ended.
+// Otherwise the alert ends after a number of resend intervals, unless it is posted again.
+func (s *Service) Alert(id, message, details string, level alert.Level, start, t time.Time, labels map[string]string) error {
+	return s.postAlerts([]postedAlert{s.newPostedAlert(id, message, details, level, start, t, labels)})
+}
+
+func (s *Service) newPostedAlert(id, message, details string, level alert.Level, start, t time.Time, labels map[string]string) postedAlert {
+	a := postedAlert{
+		Labels: map[string]string{
+			alertNameLabel: id,
+		},
+		Annotations: map[string]string{
+			"message": message,
+		},
+		StartsAt: start.UTC(),
+	}
+	for k, v := range labels {
+		a.Labels[labelName(k)] = v
+	}
+	if details != "" {
+		a.Annotations["details"] = details
+	}
+	if level == alert.OK {
+		a.EndsAt = t.UTC()
+	} else {
+		a.EndsAt = time.Now().Add(resolveIntervals * s.config().resendInterval()).UTC()
+	}
+	return a
+}
+
+// postAlerts posts the alerts to Alertmanager in a single request.
+func (s *Service) postAlerts(alerts []postedAlert) error {
+	req, err := s.preparePost(alerts)
+	if err != nil {
+		return err
+	}
+
+	client := s.clientValue.Load().(*http.Client)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		type response struct {
+			Error string `json:"error"`
+		}
+		r := &response{Error: fmt.Sprintf("failed to understand Alertmanager response. code: %d content: %s", resp.StatusCode, string(body))}
+		b := bytes.NewReader(body)
+		dec := json.NewDecoder(b)
+		dec.Decode(r)
+		return errors.New(r.Error)
+	}
+	return nil
+}
+
+func (s *Service) preparePost(alerts []postedAlert) (*http.Request, error) {
+	c := s.config()
+
+	if !c.Enabled {
+		return nil, errors.New("service is not enabled")
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, "api/v1/alerts")
+
+	var post bytes.Buffer
+	enc := json.NewEncoder(&post)
+	if err := enc.Encode(alerts); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), &post)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	return req, nil
+}
+
+// labelName returns a valid Prometheus label name,
+// replacing the characters which are not allowed with underscores.
+func labelName(s string) string {
+	if s == "" {
+		return "_"
+	}
+	b := []byte(s)
+	for i, c := range b {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+type HandlerConfig struct {
+	// Labels added to the labels created from the tags of the alert.
+	// The alertname label is the ID of the alert unless it is set here.
+	Labels map[string]string `mapstructure:"labels"`
+}
+
+// EventStater returns the states of the events of a topic.
+type EventStater interface {
+	EventStates(topic string, minLevel alert.Level) (map[string]alert.EventState, error)
+}
+
+type handler struct {
+	s      *Service
+	c      HandlerConfig
+	logger *log.Logger
+
+	// topic and states restore the active alerts, which are otherwise lost when the handler is replaced or Kapacitor restarts.
+	topic    string
+	states   EventStater
+	restored bool
+
+	// postMu orders the posts of the handler, so that a resend of an alert is not posted after its recovery.
+	postMu sync.Mutex
+
+	mu sync.Mutex
+	// active are the last events of the alerts that are not resolved, they are posted again every resend interval.
+	active map[string]alert.Event
+	timer  *time.Timer
+	closed bool
+}
+
+// Handler returns a handler posting the events of the topic as alerts.
+// The active alerts are restored from the event states of the topic, if states is not nil.
+func (s *Service) Handler(c HandlerConfig, topic string, states EventStater, l *log.Logger) alert.Handler {
+	h := &handler{
+		s:        s,
+		c:        c,
+		logger:   l,
+		topic:    topic,
+		states:   states,
+		restored: states == nil,
+		active:   make(map[string]alert.Event),
+	}
+	if !h.restored {
+		// The topic may not be restored yet, the active alerts are restored when the first event is handled
+		// or they are first posted again.
+		h.mu.Lock()
+		h.schedule()
+		h.mu.Unlock()
+	}
+	return h
+}
+
+// Handle posts the event as an alert labeled with the tags of the event.
+// Active alerts are posted again until they are resolved, so that Alertmanager does not resolve them.
+func (h *handler) Handle(event alert.Event) error {
+	h.mu.Lock()
+	h.restore()
+	if event.State.Level == alert.OK {
+		delete(h.active, event.State.ID)
+	} else if !h.closed {
+		h.active[event.State.ID] = event
+		h.schedule()
+	}
+	h.mu.Unlock()
+
+	h.postMu.Lock()
+	defer h.postMu.Unlock()
+	if err := h.s.postAlerts([]postedAlert{h.postedAlert(event)}); err != nil {
+		return fmt.Errorf("failed to send event to Alertmanager: %v", err)
+	}
+	return nil
+}
+
+// restore adds the events of the topic that are not OK to the active alerts, once the topic is restored.
+// Caller must have lock.
+func (h *handler) restore() {
+	if h.restored {
+		return
+	}
+	states, err := h.states.EventStates(h.topic, alert.Info)
+	if err != nil {
+		// The topic does not exist yet.
+		return
+	}
+	h.restored = true
+	for id, state := range states {
+		if _, ok := h.active[id]; ok {
+			continue
+		}
+		h.active[id] = alert.Event{
+			Topic: h.topic,
+			State: state,
+			Data: alert.EventData{
+				Tags: state.Tags,
+			},
+		}
+	}
+}
+
+// schedule starts the timer of posting the active alerts again, if it is not running.
+// Caller must have lock.
+func (h *handler) schedule() {
+	if h.timer == nil {
+		h.timer = time.AfterFunc(h.s.config().resendInterval(), h.resend)
+	}
+}
+
+// resend posts all active alerts again in a single request.
+func (h *handler) resend() {
+	h.postMu.Lock()
+	defer h.postMu.Unlock()
+
+	h.mu.Lock()
+	h.timer = nil
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.restore()
+	alerts := make([]postedAlert, 0, len(h.active))
+	for _, event := range h.active {
+		alerts = append(alerts, h.postedAlert(event))
+	}
+	if len(h.active) > 0 || !h.restored {
+		h.schedule()
+	}
+	h.mu.Unlock()
+
+	if len(alerts) == 0 {
+		return
+	}
+	if err := h.s.postAlerts(alerts); err != nil {
+		h.logger.Printf("E! failed to send %d active alerts to Alertmanager again: %v", len(alerts), err)
+	}
+}
+
+// Close stops posting the active alerts again.
+func (h *handler) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+}
+
+// postedAlert returns the alert of the event.
+// Labels must be the same for all events of an alert, so that recoveries resolve the alert,
+// the level is therefore not part of the labels.
+func (h *handler) postedAlert(event alert.Event) postedAlert {
+	labels := make(map[string]string, len(event.Data.Tags)+len(h.c.Labels))
+	for k, v := range event.Data.Tags {
+		labels[k] = v
+	}
+	for k, v := range h.c.Labels {
+		labels[k] = v
+	}
+	start := event.State.Time.Add(-event.State.Duration)
+	return h.s.newPostedAlert(
+		event.State.ID,
+		event.State.Message,
+		event.State.Details,
+		event.State.Level,
+		start,
+		event.State.Time,
+		labels,
+	)
+}
@@ -0,0 +1,138 @@
+package alertmanager_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/alertmanager"
+	"github.com/influxdata/kapacitor/services/alertmanager/alertmanagertest"
+)
+
+func TestHandler_Resend(t *testing.T) {
+	ts := alertmanagertest.NewServer()
+	defer ts.Close()
+
+	c := alertmanager.NewConfig()
+	c.Enabled = true
+	c.URL = ts.URL
+	c.ResendInterval = toml.Duration(10 * time.Millisecond)
+	s := alertmanager.NewService(c, log.New(os.Stderr, "[alertmanager] ", log.LstdFlags))
+	h := s.Handler(alertmanager.HandlerConfig{}, "topic", nil, log.New(os.Stderr, "[alertmanager] ", log.LstdFlags))
+	defer h.(interface {
+		Close()
+	}).Close()
+
+	event := func(level alert.Level) alert.Event {
+		return alert.Event{
+			State: alert.EventState{
+				ID:      "id",
+				Message: "message",
+				Time:    time.Now(),
+				Level:   level,
+			},
+		}
+	}
+
+	start := time.Now()
+	if err := h.Handle(event(alert.Critical)); err != nil {
+		t.Fatal(err)
+	}
+	// The active alert is posted again until it is resolved.
+	for i := 0; len(ts.Requests()) < 3; i++ {
+		if i > 100 {
+			t.Fatalf("expected the active alert to be posted again, got %d requests", len(ts.Requests()))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, r := range ts.Requests() {
+		if endsAt := r.Alerts[0].EndsAt; endsAt.Before(start.Add(40 * time.Millisecond)) {
+			t.Errorf("expected active alert to end after four resend intervals, ends at %v", endsAt)
+		}
+	}
+
+	ok := event(alert.OK)
+	if err := h.Handle(ok); err != nil {
+		t.Fatal(err)
+	}
+	n := len(ts.Requests())
+	time.Sleep(50 * time.Millisecond)
+	requests := ts.Requests()
+	if len(requests) != n {
+		t.Fatalf("expected the resolved alert not to be posted again, got %d requests exp %d", len(requests), n)
+	}
+	if endsAt := requests[n-1].Alerts[0].EndsAt; !endsAt.Equal(ok.State.Time.UTC()) {
+		t.Errorf("unexpected end of resolved alert got %v exp %v", endsAt, ok.State.Time.UTC())
+	}
+}
+
+// eventStater returns the event states of a topic, once the topic is restored.
+type eventStater struct {
+	mu       sync.Mutex
+	restored bool
+	states   map[string]alert.EventState
+}
+
+func (s *eventStater) EventStates(topic string, minLevel alert.Level) (map[string]alert.EventState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.restored {
+		return nil, fmt.Errorf("unknown topic %q", topic)
+	}
+	states := make(map[string]alert.EventState)
+	for id, state := range s.states {
+		if state.Level >= minLevel {
+			states[id] = state
+		}
+	}
+	return states, nil
+}
+
+func TestHandler_RestoreActive(t *testing.T) {
+	ts := alertmanagertest.NewServer()
+	defer ts.Close()
+
+	c := alertmanager.NewConfig()
+	c.Enabled = true
+	c.URL = ts.URL
+	c.ResendInterval = toml.Duration(10 * time.Millisecond)
+	s := alertmanager.NewService(c, log.New(os.Stderr, "[alertmanager] ", log.LstdFlags))
+	states := &eventStater{
+		states: map[string]alert.EventState{
+			"a":  {ID: "a", Message: "message a", Time: time.Now(), Level: alert.Critical, Tags: map[string]string{"host": "serverA"}},
+			"b":  {ID: "b", Message: "message b", Time: time.Now(), Level: alert.Warning, Tags: map[string]string{"host": "serverB"}},
+			"ok": {ID: "ok", Message: "message ok", Time: time.Now(), Level: alert.OK},
+		},
+	}
+	h := s.Handler(alertmanager.HandlerConfig{}, "topic", states, log.New(os.Stderr, "[alertmanager] ", log.LstdFlags))
+	defer h.(interface {
+		Close()
+	}).Close()
+
+	// The active alerts are restored once the topic is restored, without any event being handled.
+	time.Sleep(30 * time.Millisecond)
+	states.mu.Lock()
+	states.restored = true
+	states.mu.Unlock()
+	for i := 0; len(ts.Requests()) < 1; i++ {
+		if i > 100 {
+			t.Fatal("expected the restored alerts to be posted again")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// All active alerts are posted in a single request.
+	alerts := ts.Requests()[0].Alerts
+	got := make(map[string]string, len(alerts))
+	for _, a := range alerts {
+		got[a.Labels["alertname"]] = a.Labels["host"]
+	}
+	if exp := map[string]string{"a": "serverA", "b": "serverB"}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected restored alerts got %v exp %v", got, exp)
+	}
+}
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// Default duration audit records are kept.
+	DefaultRetention = toml.Duration(30 * 24 * time.Hour)
+)
+
+type Config struct {
+	// Whether changes made via the API are recorded.
+	Enabled bool `toml:"enabled"`
+	// How long audit records are kept, zero keeps records forever.
+	Retention toml.Duration `toml:"retention"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Enabled:   true,
+		Retention: DefaultRetention,
+	}
+}
+
+func (c Config) Validate() error {
+	if c.Retention < 0 {
+		return fmt.Errorf("retention cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/kapacitor/services/storage"
+)
+
+// Data access object for audit Record data.
+type RecordDAO interface {
+	// Create a record.
+	Create(r Record) error
+
+	// Delete a record.
+	// It is not an error to delete an non-existent record.
+	Delete(id string) error
+
+	// List records ordered by time, oldest first.
+	// Offset and limit are pagination bounds. Offset is inclusive starting at index 0.
+	// More results may exist while the number of returned items is equal to limit.
+	List(offset, limit int) ([]Record, error)
+
+	// ReverseList lists records ordered by time, newest first.
+	ReverseList(offset, limit int) ([]Record, error)
+
+	// Rebuild fixes all indexes of the data.
+	Rebuild() error
+}
+
+//--------------------------------------------------------------------
+// The following structures are stored in a database via JSON encoding.
+// Changes to the structures could break existing data.
+
+// version is the current version of the Record structure.
+const version = 1
+
+// Record is an entry of the audit log.
+type Record struct {
+	// ID of the record, IDs sort in the order the records were created.
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Username string    `json:"username"`
+	Source   string    `json:"source"`
+	Method   string    `json:"method"`
+	Resource string    `json:"resource"`
+	Action   string    `json:"action"`
+	// JSON representation of the resource before and after the change.
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+func (r Record) ObjectID() string {
+	return r.ID
+}
+
+func (r Record) MarshalBinary() ([]byte, error) {
+	return storage.VersionJSONEncode(version, r)
+}
+
+func (r *Record) UnmarshalBinary(data []byte) error {
+	return storage.VersionJSONDecode(data, func(version int, dec *json.Decoder) error {
+		return dec.Decode(r)
+	})
+}
+
+// Key/Value store based implementation of the RecordDAO
+type recordKV struct {
+	store *storage.IndexedStore
+}
+
+func newRecordKV(store storage.Interface) (*recordKV, error) {
+	c := storage.DefaultIndexedStoreConfig("records", func() storage.BinaryObject {
+		return new(Record)
+	})
+	istore, err := storage.NewIndexedStore(store, c)
+	if err != nil {
+		return nil, err
+	}
+	return &recordKV{
+		store: istore,
+	}, nil
+}
+
+func (kv *recordKV) Create(r Record) error {
+	return kv.store.Create(&r)
+}
+
+func (kv *recordKV) Delete(id string) error {
+	return kv.store.Delete(id)
+}
+
+func (kv *recordKV) List(offset, limit int) ([]Record, error) {
+	objects, err := kv.store.List(storage.DefaultIDIndex, "", offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return kv.records(objects)
+}
+
+func (kv *recordKV) ReverseList(offset, limit int) ([]Record, error) {
+	objects, err := kv.store.ReverseList(storage.DefaultIDIndex, "", offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return kv.records(objects)
+}
+
+func (kv *recordKV) records(objects []storage.BinaryObject) ([]Record, error) {
+	records := make([]Record, len(objects))
+	for i, object := range objects {
+		r, ok := object.(*Record)
+		if !ok {
+			return nil, storage.ImpossibleTypeErr(r, object)
+		}
+		records[i] = *r
+	}
+	return records, nil
+}
+
+func (kv *recordKV) Rebuild() error {
+	return kv.store.Rebuild()
+}
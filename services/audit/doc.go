@@ -0,0 +1,8 @@
+/*
+The audit package records an audit log of all API requests that change resources.
+
+Each record contains the user that made the request, the time, the changed resource
+and the state of the resource before and after the request.
+Records are kept for the configured retention and are queried via the /kapacitor/v1/audit HTTP endpoint.
+*/
+package audit
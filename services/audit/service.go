@@ -0,0 +1,329 @@
+package audit
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	client "github.com/influxdata/kapacitor/client/v1"
+	"github.com/influxdata/kapacitor/services/httpd"
+	"github.com/influxdata/kapacitor/services/storage"
+)
+
+const (
+	auditPath = "/audit"
+
+	// Public name of the audit records store
+	recordsAPIName = "audit"
+	// The storage namespace for all audit data.
+	auditNamespace = "audit"
+
+	// How often records older than the retention are deleted.
+	purgeInterval = time.Hour
+	// Number of records read at a time when querying and purging.
+	pageSize = 100
+)
+
+// Service records changes made via the API and provides an API for querying the records.
+type Service struct {
+	retention time.Duration
+
+	records RecordDAO
+
+	// Serialize record IDs
+	mu     sync.Mutex
+	lastID int64
+
+	routes []httpd.Route
+
+	closing chan struct{}
+	wg      sync.WaitGroup
+
+	StorageService interface {
+		Store(namespace string) storage.Interface
+		Register(name string, store storage.StoreActioner)
+	}
+	HTTPDService interface {
+		AddRoutes([]httpd.Route) error
+		DelRoutes([]httpd.Route)
+	}
+
+	logger *log.Logger
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	return &Service{
+		retention: time.Duration(c.Retention),
+		logger:    l,
+	}
+}
+
+func (s *Service) Open() error {
+	store := s.StorageService.Store(auditNamespace)
+	records, err := newRecordKV(store)
+	if err != nil {
+		return err
+	}
+	s.records = records
+	s.StorageService.Register(recordsAPIName, s.records)
+
+	// Define API routes
+	s.routes = []httpd.Route{
+		{
+			Method:      "GET",
+			Pattern:     auditPath,
+			HandlerFunc: s.handleListRecords,
+		},
+	}
+	if err := s.HTTPDService.AddRoutes(s.routes); err != nil {
+		return err
+	}
+
+	s.closing = make(chan struct{})
+	if s.retention > 0 {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.runPurge()
+		}()
+	}
+	return nil
+}
+
+func (s *Service) Close() error {
+	if s.HTTPDService != nil {
+		s.HTTPDService.DelRoutes(s.routes)
+	}
+	if s.closing != nil {
+		close(s.closing)
+		s.wg.Wait()
+	}
+	return nil
+}
+
+// Record stores an audit event.
+func (s *Service) Record(e httpd.AuditEvent) {
+	r := Record{
+		ID:       s.nextID(e.Time),
+		Time:     e.Time,
+		Username: e.Username,
+		Source:   e.Source,
+		Method:   e.Method,
+		Resource: e.Resource,
+		Action:   e.Action,
+		Before:   e.Before,
+		After:    e.After,
+	}
+	if err := s.records.Create(r); err != nil {
+		s.logger.Printf("E! failed to record %s of %s by %s: %v", e.Action, e.Resource, e.Username, err)
+	}
+}
+
+// nextID returns a unique ID that sorts after all previous IDs.
+func (s *Service) nextID(t time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := t.UnixNano()
+	if id <= s.lastID {
+		id = s.lastID + 1
+	}
+	s.lastID = id
+	return fmt.Sprintf("%019d", id)
+}
+
+// Query filters audit records.
+type Query struct {
+	Username string
+	// Glob pattern matched against the path of the resource,
+	// either the full path or the path relative to the API base path.
+	Resource string
+	Action   string
+	// Only records in the time range [Since, Until) are returned, zero values are unbounded.
+	Since time.Time
+	Until time.Time
+
+	Offset int
+	Limit  int
+}
+
+func (q Query) match(r Record) bool {
+	if q.Username != "" && q.Username != r.Username {
+		return false
+	}
+	if q.Action != "" && q.Action != r.Action {
+		return false
+	}
+	if !q.Until.IsZero() && !r.Time.Before(q.Until) {
+		return false
+	}
+	if q.Resource != "" {
+		matched, _ := path.Match(q.Resource, r.Resource)
+		if !matched {
+			for _, base := range []string{httpd.BasePath, httpd.BasePreviewPath} {
+				if rel := strings.TrimPrefix(r.Resource, base+"/"); rel != r.Resource {
+					matched, _ = path.Match(q.Resource, rel)
+					break
+				}
+			}
+		}
+		return matched
+	}
+	return true
+}
+
+// Records returns the records that match the query, newest first.
+func (s *Service) Records(q Query) ([]Record, error) {
+	var matches []Record
+	for offset := 0; ; offset += pageSize {
+		records, err := s.records.ReverseList(offset, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			if !q.Since.IsZero() && r.Time.Before(q.Since) {
+				// All remaining records are older
+				return matches, nil
+			}
+			if !q.match(r) {
+				continue
+			}
+			if q.Offset > 0 {
+				q.Offset--
+				continue
+			}
+			matches = append(matches, r)
+			if q.Limit >= 0 && len(matches) == q.Limit {
+				return matches, nil
+			}
+		}
+		if len(records) != pageSize {
+			return matches, nil
+		}
+	}
+}
+
+func (s *Service) runPurge() {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.Purge(time.Now().Add(-s.retention)); err != nil {
+			s.logger.Println("E! failed to purge audit records:", err)
+		}
+		select {
+		case <-s.closing:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes all records older than the cutoff.
+func (s *Service) Purge(cutoff time.Time) error {
+	for {
+		records, err := s.records.List(0, pageSize)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			if !r.Time.Before(cutoff) {
+				return nil
+			}
+			if err := s.records.Delete(r.ID); err != nil {
+				return err
+			}
+		}
+		if len(records) != pageSize {
+			return nil
+		}
+	}
+}
+
+func convertRecord(r Record) client.AuditRecord {
+	cr := client.AuditRecord{
+		ID:       r.ID,
+		Time:     r.Time,
+		Username: r.Username,
+		Source:   r.Source,
+		Method:   r.Method,
+		Resource: r.Resource,
+		Action:   r.Action,
+		Before:   r.Before,
+		After:    r.After,
+	}
+	if r.Before != nil && r.After != nil {
+		if diff, err := jsonpatch.CreateMergePatch(r.Before, r.After); err == nil {
+			cr.Diff = diff
+		}
+	}
+	return cr
+}
+
+func (s *Service) handleListRecords(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	q := Query{
+		Username: params.Get("user"),
+		Resource: params.Get("resource"),
+		Action:   params.Get("action"),
+		Limit:    100,
+	}
+	if q.Resource != "" {
+		if _, err := path.Match(q.Resource, ""); err != nil {
+			httpd.HttpError(w, fmt.Sprintf("invalid resource pattern %q: %v", q.Resource, err), true, http.StatusBadRequest)
+			return
+		}
+	}
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"since", &q.Since}, {"until", &q.Until}} {
+		if v := params.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				httpd.HttpError(w, fmt.Sprintf("invalid %s parameter %q must be an RFC3339 time: %v", p.name, v, err), true, http.StatusBadRequest)
+				return
+			}
+			*p.t = t
+		}
+	}
+	if o := params.Get("offset"); o != "" {
+		i, err := strconv.ParseInt(o, 10, 64)
+		if err != nil {
+			httpd.HttpError(w, fmt.Sprintf("invalid offset parameter %q must be an integer: %s", o, err), true, http.StatusBadRequest)
+			return
+		}
+		q.Offset = int(i)
+	}
+	if l := params.Get("limit"); l != "" {
+		i, err := strconv.ParseInt(l, 10, 64)
+		if err != nil {
+			httpd.HttpError(w, fmt.Sprintf("invalid limit parameter %q must be an integer: %s", l, err), true, http.StatusBadRequest)
+			return
+		}
+		q.Limit = int(i)
+	}
+
+	records, err := s.Records(q)
+	if err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to query audit records: ", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	type response struct {
+		Link    client.Link          `json:"link"`
+		Records []client.AuditRecord `json:"records"`
+	}
+	resp := response{
+		Link:    client.Link{Relation: client.Self, Href: r.URL.String()},
+		Records: make([]client.AuditRecord, len(records)),
+	}
+	for i, record := range records {
+		resp.Records[i] = convertRecord(record)
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(resp, true))
+}
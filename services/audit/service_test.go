@@ -0,0 +1,188 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	client "github.com/influxdata/kapacitor/client/v1"
+	"github.com/influxdata/kapacitor/services/audit"
+	"github.com/influxdata/kapacitor/services/httpd"
+	"github.com/influxdata/kapacitor/services/httpd/httpdtest"
+	"github.com/influxdata/kapacitor/services/storage/storagetest"
+)
+
+func OpenNewService(c audit.Config) (*audit.Service, *httpdtest.Server) {
+	service := audit.NewService(c, log.New(os.Stderr, "[audit] ", log.LstdFlags))
+	service.StorageService = storagetest.New()
+	server := httpdtest.NewServer(testing.Verbose())
+	service.HTTPDService = server
+	server.Handler.AuditService = service
+	if err := service.Open(); err != nil {
+		panic(err)
+	}
+	return service, server
+}
+
+// widgets is a minimal API resource used to generate audit events.
+type widget struct {
+	Link   client.Link `json:"link"`
+	ID     string      `json:"id"`
+	Status string      `json:"status"`
+}
+
+type widgets map[string]widget
+
+func (ws widgets) routes() []httpd.Route {
+	get := func(w http.ResponseWriter, r *http.Request) {
+		wg, ok := ws[path.Base(r.URL.Path)]
+		if !ok {
+			httpd.HttpError(w, "no widget", true, http.StatusNotFound)
+			return
+		}
+		w.Write(httpd.MarshalJSON(wg, true))
+	}
+	create := func(w http.ResponseWriter, r *http.Request) {
+		var wg widget
+		json.NewDecoder(r.Body).Decode(&wg)
+		wg.Link = client.Link{Relation: client.Self, Href: path.Join(httpd.BasePath, "widgets", wg.ID)}
+		ws[wg.ID] = wg
+		w.Write(httpd.MarshalJSON(wg, true))
+	}
+	update := func(w http.ResponseWriter, r *http.Request) {
+		wg := ws[path.Base(r.URL.Path)]
+		json.NewDecoder(r.Body).Decode(&wg)
+		ws[wg.ID] = wg
+		w.Write(httpd.MarshalJSON(wg, true))
+	}
+	del := func(w http.ResponseWriter, r *http.Request) {
+		delete(ws, path.Base(r.URL.Path))
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return []httpd.Route{
+		{Method: "GET", Pattern: "/widgets/", HandlerFunc: get},
+		{Method: "POST", Pattern: "/widgets", HandlerFunc: create},
+		{Method: "PATCH", Pattern: "/widgets/", HandlerFunc: update},
+		{Method: "DELETE", Pattern: "/widgets/", HandlerFunc: del},
+	}
+}
+
+func do(t *testing.T, method, url, body string) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestService_Records(t *testing.T) {
+	service, server := OpenNewService(audit.NewConfig())
+	defer server.Close()
+	defer service.Close()
+	if err := server.AddRoutes(widgets{}.routes()); err != nil {
+		t.Fatal(err)
+	}
+
+	base := server.Server.URL + httpd.BasePath + "/widgets"
+	start := time.Now()
+	do(t, "POST", base, `{"id":"a","status":"enabled"}`)
+	do(t, "PATCH", base+"/a", `{"status":"disabled"}`)
+	// Failed requests are not recorded
+	do(t, "GET", base+"/missing", "")
+	do(t, "POST", base, `{"id":"b","status":"enabled"}`)
+	do(t, "DELETE", base+"/a", "")
+
+	cli, err := client.New(client.Config{URL: server.Server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := cli.ListAuditRecords(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type summary struct {
+		Action, Resource, User string
+	}
+	got := make([]summary, len(records))
+	for i, r := range records {
+		got[i] = summary{r.Action, r.Resource, r.Username}
+		if r.Time.Before(start.Add(-time.Second)) {
+			t.Errorf("unexpected time of record %d: %v", i, r.Time)
+		}
+	}
+	exp := []summary{
+		{"delete", "/kapacitor/v1/widgets/a", "ADMIN_USER"},
+		{"create", "/kapacitor/v1/widgets/b", "ADMIN_USER"},
+		{"disable", "/kapacitor/v1/widgets/a", "ADMIN_USER"},
+		{"create", "/kapacitor/v1/widgets/a", "ADMIN_USER"},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected records:\ngot\n%v\nexp\n%v", got, exp)
+	}
+	var diff map[string]interface{}
+	if err := json.Unmarshal(records[2].Diff, &diff); err != nil {
+		t.Fatal(err)
+	}
+	if exp := map[string]interface{}{"status": "disabled"}; !reflect.DeepEqual(diff, exp) {
+		t.Errorf("unexpected diff %v", diff)
+	}
+	if records[0].Before == nil || records[0].After != nil {
+		t.Errorf("unexpected state of deleted resource: before %s after %s", records[0].Before, records[0].After)
+	}
+
+	filtered, err := cli.ListAuditRecords(&client.ListAuditRecordsOptions{
+		Resource: "widgets/a",
+		Action:   "create",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || filtered[0].ID != records[3].ID {
+		t.Errorf("unexpected filtered records %v", filtered)
+	}
+	paged, err := cli.ListAuditRecords(&client.ListAuditRecordsOptions{Offset: 1, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paged) != 2 || paged[0].ID != records[1].ID || paged[1].ID != records[2].ID {
+		t.Errorf("unexpected paged records %v", paged)
+	}
+	recent, err := cli.ListAuditRecords(&client.ListAuditRecordsOptions{Since: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 0 {
+		t.Errorf("unexpected records in the future %v", recent)
+	}
+}
+
+func TestService_Retention(t *testing.T) {
+	service, server := OpenNewService(audit.NewConfig())
+	defer server.Close()
+	defer service.Close()
+
+	now := time.Now().UTC()
+	service.Record(httpd.AuditEvent{Time: now.Add(-60 * 24 * time.Hour), Resource: "/old", Action: httpd.AuditUpdate})
+	service.Record(httpd.AuditEvent{Time: now, Resource: "/new", Action: httpd.AuditUpdate})
+
+	if err := service.Purge(now.Add(-time.Duration(audit.DefaultRetention))); err != nil {
+		t.Fatal(err)
+	}
+	records, err := service.Records(audit.Query{Limit: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Resource != "/new" {
+		t.Errorf("unexpected records after purge %v", records)
+	}
+}
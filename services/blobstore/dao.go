@@ -0,0 +1,319 @@
+package blobstore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/influxdata/kapacitor/services/storage"
+)
+
+var (
+	ErrNoBlobExists  = errors.New("no blob exists")
+	ErrNoTagExists   = errors.New("no tag exists")
+	ErrNoChunkExists = errors.New("no blob chunk exists")
+)
+
+// Data access object for Blob data.
+// The data of a blob is stored in chunks separate from the blob,
+// so that blobs of any size can be streamed in and out of the store.
+type BlobDAO interface {
+	// Retrieve a blob, without the data of its chunks.
+	Get(id string) (Blob, error)
+
+	// Create a blob once all of its chunks have been stored.
+	// Since blobs are content addressed creating a blob that already exists is not an error,
+	// ErrObjectExists is returned so that the caller can delete the chunks it stored.
+	Create(b Blob) error
+
+	// Delete a blob and its chunks.
+	// It is not an error to delete an non-existent blob.
+	Delete(id string) error
+
+	// Store a chunk of data.
+	PutChunk(key string, index int, data []byte) error
+
+	// Retrieve a chunk of data.
+	Chunk(key string, index int) ([]byte, error)
+
+	// Delete the chunks of data stored under the key.
+	DeleteChunks(key string, count int) error
+
+	// Whether a blob exists in the store.
+	Exists(id string) (bool, error)
+
+	// Rebuild fixes all indexes of the data.
+	Rebuild() error
+}
+
+// Data access object for Tag data.
+type TagDAO interface {
+	// Retrieve a tag
+	Get(name string) (Tag, error)
+
+	// Put a tag, creating it or replacing it if it already exists.
+	Put(t Tag) error
+
+	// Delete a tag.
+	// It is not an error to delete an non-existent tag.
+	Delete(name string) error
+
+	// List tags matching a pattern.
+	// The pattern is shell/glob matching see https://golang.org/pkg/path/#Match
+	// Offset and limit are pagination bounds. Offset is inclusive starting at index 0.
+	// More results may exist while the number of returned items is equal to limit.
+	List(pattern string, offset, limit int) ([]Tag, error)
+
+	// Rebuild fixes all indexes of the data.
+	Rebuild() error
+}
+
+//--------------------------------------------------------------------
+// The following structures are stored in a database via gob encoding.
+// Changes to the structures could break existing data.
+//
+// Many of these structures are exact copies of structures found elsewhere,
+// this is intentional so that all structures stored in the database are
+// defined here and nowhere else. So as to not accidentally change
+// the gob serialization format in incompatible ways.
+
+type Blob struct {
+	// Content address of the blob, the hex encoded SHA-256 sum of the data.
+	ID      string
+	Size    int64
+	Created time.Time
+	// Data of the blob, blobs created before data was stored in chunks store their data here.
+	Data []byte
+	// Key and number of the chunks that store the data of the blob.
+	ChunksKey string
+	Chunks    int
+}
+
+type rawBlob Blob
+
+func (b Blob) ObjectID() string {
+	return b.ID
+}
+
+func (b Blob) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode((rawBlob)(b))
+	return buf.Bytes(), err
+}
+
+func (b *Blob) UnmarshalBinary(data []byte) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode((*rawBlob)(b))
+}
+
+type Tag struct {
+	Name string
+	// History of the blobs the tag has pointed to, oldest first.
+	// The last entry is the current blob for the tag.
+	History []TagEntry
+}
+
+type TagEntry struct {
+	BlobID string
+	Time   time.Time
+}
+
+// Current returns the most recent entry of the tag.
+func (t Tag) Current() (TagEntry, bool) {
+	if len(t.History) == 0 {
+		return TagEntry{}, false
+	}
+	return t.History[len(t.History)-1], true
+}
+
+type rawTag Tag
+
+func (t Tag) ObjectID() string {
+	return t.Name
+}
+
+func (t Tag) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode((rawTag)(t))
+	return buf.Bytes(), err
+}
+
+func (t *Tag) UnmarshalBinary(data []byte) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode((*rawTag)(t))
+}
+
+// Prefix of the keys of blob chunks.
+const chunksPrefix = "blob_chunks"
+
+// Key/Value based implementation of the BlobDAO.
+type blobKV struct {
+	store  *storage.IndexedStore
+	chunks storage.Interface
+}
+
+func newBlobKV(store storage.Interface) (*blobKV, error) {
+	c := storage.DefaultIndexedStoreConfig("blobs", func() storage.BinaryObject {
+		return new(Blob)
+	})
+	istore, err := storage.NewIndexedStore(store, c)
+	if err != nil {
+		return nil, err
+	}
+	return &blobKV{
+		store:  istore,
+		chunks: store,
+	}, nil
+}
+
+func (kv *blobKV) Rebuild() error {
+	return kv.store.Rebuild()
+}
+
+func (kv *blobKV) error(err error) error {
+	if err == storage.ErrNoObjectExists {
+		return ErrNoBlobExists
+	}
+	return err
+}
+
+func (kv *blobKV) Get(id string) (Blob, error) {
+	o, err := kv.store.Get(id)
+	if err != nil {
+		return Blob{}, kv.error(err)
+	}
+	b, ok := o.(*Blob)
+	if !ok {
+		return Blob{}, storage.ImpossibleTypeErr(b, o)
+	}
+	return *b, nil
+}
+
+func (kv *blobKV) Create(b Blob) error {
+	return kv.store.Create(&b)
+}
+
+func (kv *blobKV) Delete(id string) error {
+	b, err := kv.Get(id)
+	if err == ErrNoBlobExists {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := kv.store.Delete(id); err != nil {
+		return err
+	}
+	return kv.DeleteChunks(b.ChunksKey, b.Chunks)
+}
+
+func chunkKey(key string, index int) string {
+	return path.Join(chunksPrefix, key, fmt.Sprintf("%010d", index))
+}
+
+func (kv *blobKV) PutChunk(key string, index int, data []byte) error {
+	return kv.chunks.Update(func(tx storage.Tx) error {
+		return tx.Put(chunkKey(key, index), data)
+	})
+}
+
+func (kv *blobKV) Chunk(key string, index int) ([]byte, error) {
+	var data []byte
+	err := kv.chunks.View(func(tx storage.ReadOnlyTx) error {
+		kv, err := tx.Get(chunkKey(key, index))
+		if err == storage.ErrNoKeyExists {
+			return ErrNoChunkExists
+		} else if err != nil {
+			return err
+		}
+		data = kv.Value
+		return nil
+	})
+	return data, err
+}
+
+func (kv *blobKV) DeleteChunks(key string, count int) error {
+	return kv.chunks.Update(func(tx storage.Tx) error {
+		for i := 0; i < count; i++ {
+			if err := tx.Delete(chunkKey(key, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (kv *blobKV) Exists(id string) (bool, error) {
+	_, err := kv.store.Get(id)
+	if err == storage.ErrNoObjectExists {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Key/Value based implementation of the TagDAO.
+type tagKV struct {
+	store *storage.IndexedStore
+}
+
+func newTagKV(store storage.Interface) (*tagKV, error) {
+	c := storage.DefaultIndexedStoreConfig("tags", func() storage.BinaryObject {
+		return new(Tag)
+	})
+	istore, err := storage.NewIndexedStore(store, c)
+	if err != nil {
+		return nil, err
+	}
+	return &tagKV{
+		store: istore,
+	}, nil
+}
+
+func (kv *tagKV) Rebuild() error {
+	return kv.store.Rebuild()
+}
+
+func (kv *tagKV) error(err error) error {
+	if err == storage.ErrNoObjectExists {
+		return ErrNoTagExists
+	}
+	return err
+}
+
+func (kv *tagKV) Get(name string) (Tag, error) {
+	o, err := kv.store.Get(name)
+	if err != nil {
+		return Tag{}, kv.error(err)
+	}
+	t, ok := o.(*Tag)
+	if !ok {
+		return Tag{}, storage.ImpossibleTypeErr(t, o)
+	}
+	return *t, nil
+}
+
+func (kv *tagKV) Put(t Tag) error {
+	return kv.store.Put(&t)
+}
+
+func (kv *tagKV) Delete(name string) error {
+	return kv.store.Delete(name)
+}
+
+func (kv *tagKV) List(pattern string, offset, limit int) ([]Tag, error) {
+	objects, err := kv.store.List(storage.DefaultIDIndex, pattern, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]Tag, len(objects))
+	for i, o := range objects {
+		t, ok := o.(*Tag)
+		if !ok {
+			return nil, storage.ImpossibleTypeErr(t, o)
+		}
+		tags[i] = *t
+	}
+	return tags, nil
+}
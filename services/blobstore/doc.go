@@ -0,0 +1,10 @@
+/*
+The blobstore package provides a store for arbitrary immutable data.
+
+Blobs are content addressed, their ID is the SHA-256 sum of their data.
+Tags name blobs and record the history of which blobs they have referenced,
+retrieving a blob by tag returns the blob most recently associated with the tag.
+
+See BLOB_STORE_DESIGN.md for the complete design.
+*/
+package blobstore
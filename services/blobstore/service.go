@@ -0,0 +1,620 @@
+package blobstore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	client "github.com/influxdata/kapacitor/client/v1"
+	"github.com/influxdata/kapacitor/services/httpd"
+	"github.com/influxdata/kapacitor/services/storage"
+)
+
+const (
+	blobsPath            = "/blobs"
+	blobsPathAnchored    = "/blobs/"
+	blobTagsPath         = blobsPath + "/tags"
+	blobTagsPathAnchored = blobTagsPath + "/"
+
+	blobsBasePathAnchored    = httpd.BasePath + blobsPathAnchored
+	blobTagsBasePathAnchored = httpd.BasePath + blobTagsPathAnchored
+
+	tagHistoryPath = "history"
+)
+
+const (
+	// Public name of the blobs store
+	blobsAPIName = "blobs"
+	// Public name of the tags store
+	tagsAPIName = "blob-tags"
+	// The storage namespace for all blob data.
+	blobsNamespace = "blob_store"
+)
+
+// chunkSize is the size in bytes of the chunks the data of blobs is stored in.
+// Each chunk is stored in its own transaction, so blobs are never held in memory in their entirety.
+const chunkSize = 1024 * 1024
+
+var validTagName = regexp.MustCompile(`^[-\._\p{L}0-9]+$`)
+
+// Service stores content addressed blobs and maintains named tags of those blobs.
+type Service struct {
+	blobs BlobDAO
+	tags  TagDAO
+
+	// Serialize tag updates so that history is not lost.
+	tagMu sync.Mutex
+
+	routes []httpd.Route
+
+	StorageService interface {
+		Store(namespace string) storage.Interface
+		Register(name string, store storage.StoreActioner)
+	}
+	HTTPDService interface {
+		AddRoutes([]httpd.Route) error
+		DelRoutes([]httpd.Route)
+	}
+
+	logger *log.Logger
+}
+
+func NewService(l *log.Logger) *Service {
+	return &Service{
+		logger: l,
+	}
+}
+
+func (s *Service) Open() error {
+	store := s.StorageService.Store(blobsNamespace)
+	blobs, err := newBlobKV(store)
+	if err != nil {
+		return err
+	}
+	s.blobs = blobs
+	s.StorageService.Register(blobsAPIName, s.blobs)
+
+	tags, err := newTagKV(store)
+	if err != nil {
+		return err
+	}
+	s.tags = tags
+	s.StorageService.Register(tagsAPIName, s.tags)
+
+	s.routes = []httpd.Route{
+		{
+			Method:      "POST",
+			Pattern:     blobsPath,
+			HandlerFunc: s.handleCreateBlob,
+		},
+		{
+			Method:      "GET",
+			Pattern:     blobsPathAnchored,
+			HandlerFunc: s.handleBlob,
+			// Do not gzip the data so that Content-Length is preserved.
+			NoGzip: true,
+			NoJSON: true,
+			// Audit the metadata of blobs instead of their data.
+			StateHandlerFunc: s.handleBlobState,
+		},
+		{
+			Method:      "DELETE",
+			Pattern:     blobsPathAnchored,
+			HandlerFunc: s.handleDeleteBlob,
+		},
+		{
+			Method:      "OPTIONS",
+			Pattern:     blobsPathAnchored,
+			HandlerFunc: httpd.ServeOptions,
+		},
+		{
+			Method:      "GET",
+			Pattern:     blobTagsPathAnchored,
+			HandlerFunc: s.handleTag,
+			// Do not gzip the data so that Content-Length is preserved.
+			NoGzip: true,
+			NoJSON: true,
+			// Audit the blobs referenced by tags instead of their data.
+			StateHandlerFunc: s.handleTagState,
+		},
+		{
+			Method:      "PUT",
+			Pattern:     blobTagsPathAnchored,
+			HandlerFunc: s.handleTagBlob,
+		},
+		{
+			Method:      "DELETE",
+			Pattern:     blobTagsPathAnchored,
+			HandlerFunc: s.handleDeleteTag,
+		},
+		{
+			Method:      "OPTIONS",
+			Pattern:     blobTagsPathAnchored,
+			HandlerFunc: httpd.ServeOptions,
+		},
+	}
+
+	return s.HTTPDService.AddRoutes(s.routes)
+}
+
+func (s *Service) Close() error {
+	if s.HTTPDService != nil {
+		s.HTTPDService.DelRoutes(s.routes)
+	}
+	return nil
+}
+
+// BlobID returns the content address of the data.
+func BlobID(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateBlob stores the data and returns the resulting blob.
+// Creating a blob with the same content more than once results in the same blob.
+func (s *Service) CreateBlob(data []byte) (Blob, error) {
+	return s.CreateBlobFrom(bytes.NewReader(data))
+}
+
+// CreateBlobFrom streams the data read from r into the store and returns the resulting blob.
+// Creating a blob with the same content more than once results in the same blob.
+func (s *Service) CreateBlobFrom(r io.Reader) (Blob, error) {
+	key, err := newChunksKey()
+	if err != nil {
+		return Blob{}, err
+	}
+	b := Blob{
+		Created:   time.Now().UTC(),
+		ChunksKey: key,
+	}
+	h := sha256.New()
+	for {
+		// The store may keep the chunk, so each chunk needs its own buffer.
+		chunk := make([]byte, chunkSize)
+		n, err := io.ReadFull(r, chunk)
+		if n > 0 {
+			if err := s.blobs.PutChunk(key, b.Chunks, chunk[:n]); err != nil {
+				s.deleteChunks(b)
+				return Blob{}, err
+			}
+			h.Write(chunk[:n])
+			b.Chunks++
+			b.Size += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		} else if err != nil {
+			s.deleteChunks(b)
+			return Blob{}, err
+		}
+	}
+	b.ID = hex.EncodeToString(h.Sum(nil))
+	if err := s.blobs.Create(b); err == storage.ErrObjectExists {
+		// The content is identical to an existing blob, keep the existing blob.
+		s.deleteChunks(b)
+	} else if err != nil {
+		s.deleteChunks(b)
+		return Blob{}, err
+	}
+	// Return the stored blob, which may have been created previously.
+	return s.BlobInfo(b.ID)
+}
+
+// newChunksKey returns a unique key for the chunks of a new blob.
+// The content address of a blob is only known once all of its data has been stored.
+func newChunksKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// deleteChunks deletes the chunks of a blob that could not be created.
+func (s *Service) deleteChunks(b Blob) {
+	if err := s.blobs.DeleteChunks(b.ChunksKey, b.Chunks); err != nil {
+		s.logger.Printf("E! failed to delete chunks of blob %q: %v", b.ID, err)
+	}
+}
+
+// Blob retrieves a blob by its ID, including its data.
+func (s *Service) Blob(id string) (Blob, error) {
+	b, err := s.blobs.Get(id)
+	if err != nil {
+		return Blob{}, err
+	}
+	return s.readData(b)
+}
+
+// BlobInfo retrieves a blob by its ID without its data.
+func (s *Service) BlobInfo(id string) (Blob, error) {
+	b, err := s.blobs.Get(id)
+	if err != nil {
+		return Blob{}, err
+	}
+	b.Data = nil
+	return b, nil
+}
+
+// BlobByTag retrieves the blob currently associated with the tag, including its data.
+func (s *Service) BlobByTag(name string) (Blob, error) {
+	b, err := s.blobByTag(name)
+	if err != nil {
+		return Blob{}, err
+	}
+	return s.readData(b)
+}
+
+// blobByTag retrieves the blob currently associated with the tag, without reading its chunks.
+func (s *Service) blobByTag(name string) (Blob, error) {
+	t, err := s.tags.Get(name)
+	if err != nil {
+		return Blob{}, err
+	}
+	current, ok := t.Current()
+	if !ok {
+		return Blob{}, ErrNoBlobExists
+	}
+	return s.blobs.Get(current.BlobID)
+}
+
+// readData reads the chunks of the blob into its data.
+func (s *Service) readData(b Blob) (Blob, error) {
+	if b.Chunks == 0 {
+		return b, nil
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, b.Size))
+	if err := s.writeData(buf, b); err != nil {
+		return Blob{}, err
+	}
+	b.Data = buf.Bytes()
+	return b, nil
+}
+
+// writeData writes the data of the blob to w one chunk at a time.
+func (s *Service) writeData(w io.Writer, b Blob) error {
+	if b.Chunks == 0 {
+		// The blob was stored before data was stored in chunks.
+		_, err := w.Write(b.Data)
+		return err
+	}
+	for i := 0; i < b.Chunks; i++ {
+		data, err := s.blobs.Chunk(b.ChunksKey, i)
+		if err == ErrNoChunkExists {
+			// The blob was deleted while its data was read.
+			return ErrNoBlobExists
+		} else if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteBlob removes a blob from the store.
+// Tags that reference the blob are left intact.
+func (s *Service) DeleteBlob(id string) error {
+	return s.blobs.Delete(id)
+}
+
+// TagBlob associates the tag with the blob, recording the change in the tag history.
+func (s *Service) TagBlob(name, id string) (Tag, error) {
+	if !validTagName.MatchString(name) {
+		return Tag{}, fmt.Errorf("tag name must contain only letters, numbers, '-', '.' and '_'. %q", name)
+	}
+	if exists, err := s.blobs.Exists(id); err != nil {
+		return Tag{}, err
+	} else if !exists {
+		return Tag{}, ErrNoBlobExists
+	}
+
+	s.tagMu.Lock()
+	defer s.tagMu.Unlock()
+
+	t, err := s.tags.Get(name)
+	if err == ErrNoTagExists {
+		t = Tag{Name: name}
+	} else if err != nil {
+		return Tag{}, err
+	}
+	if current, ok := t.Current(); ok && current.BlobID == id {
+		// Tag already points to the blob
+		return t, nil
+	}
+	t.History = append(t.History, TagEntry{
+		BlobID: id,
+		Time:   time.Now().UTC(),
+	})
+	if err := s.tags.Put(t); err != nil {
+		return Tag{}, err
+	}
+	return t, nil
+}
+
+// Tag retrieves a tag and its history.
+func (s *Service) Tag(name string) (Tag, error) {
+	return s.tags.Get(name)
+}
+
+// DeleteTag removes a tag and its history, the blobs it referenced are left intact.
+func (s *Service) DeleteTag(name string) error {
+	s.tagMu.Lock()
+	defer s.tagMu.Unlock()
+	return s.tags.Delete(name)
+}
+
+func blobLink(id string) client.Link {
+	return client.Link{Relation: client.Self, Href: path.Join(httpd.BasePath, blobsPath, id)}
+}
+
+func tagLink(name string) client.Link {
+	return client.Link{Relation: client.Self, Href: path.Join(httpd.BasePath, blobTagsPath, name)}
+}
+
+func tagHistoryLink(name string) client.Link {
+	return client.Link{Relation: client.Self, Href: path.Join(httpd.BasePath, blobTagsPath, name, tagHistoryPath)}
+}
+
+func convertBlob(b Blob) client.Blob {
+	return client.Blob{
+		Link:    blobLink(b.ID),
+		ID:      b.ID,
+		Size:    b.Size,
+		Created: b.Created,
+	}
+}
+
+func convertTag(t Tag) client.BlobTag {
+	tag := client.BlobTag{
+		Link: tagLink(t.Name),
+		Name: t.Name,
+	}
+	if current, ok := t.Current(); ok {
+		tag.Blob = blobLink(current.BlobID)
+		tag.BlobID = current.BlobID
+		tag.Modified = current.Time
+	}
+	return tag
+}
+
+func convertTagHistory(t Tag) client.BlobTagHistory {
+	history := client.BlobTagHistory{
+		Link:    tagHistoryLink(t.Name),
+		Name:    t.Name,
+		History: make([]client.BlobTagEntry, len(t.History)),
+	}
+	// Most recent entries first
+	for i, e := range t.History {
+		history.History[len(t.History)-1-i] = client.BlobTagEntry{
+			Blob:   blobLink(e.BlobID),
+			BlobID: e.BlobID,
+			Time:   e.Time,
+		}
+	}
+	return history
+}
+
+// writeBlob streams the data of the blob to the response.
+func (s *Service) writeBlob(w http.ResponseWriter, b Blob) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(b.Size, 10))
+	w.WriteHeader(http.StatusOK)
+	if err := s.writeData(w, b); err != nil {
+		// The status has already been written, the client sees a short response.
+		s.logger.Printf("E! failed to write data of blob %q: %v", b.ID, err)
+	}
+}
+
+// errReader records the error of reading from the wrapped reader.
+type errReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+func (s *Service) handleCreateBlob(w http.ResponseWriter, r *http.Request) {
+	body := &errReader{r: r.Body}
+	b, err := s.CreateBlobFrom(body)
+	if body.err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to read blob data: ", body.err.Error()), true, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to create blob: ", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(convertBlob(b), true))
+}
+
+func (s *Service) blobIDFromPath(p string) (string, error) {
+	id := strings.TrimPrefix(p, blobsBasePathAnchored)
+	if id == "" || strings.Contains(id, "/") {
+		return "", fmt.Errorf("invalid blob path %q", p)
+	}
+	return id, nil
+}
+
+func (s *Service) handleBlob(w http.ResponseWriter, r *http.Request) {
+	id, err := s.blobIDFromPath(r.URL.Path)
+	if err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusBadRequest)
+		return
+	}
+	b, err := s.blobs.Get(id)
+	if err == ErrNoBlobExists {
+		httpd.HttpError(w, fmt.Sprintf("no blob exists with ID %q", id), true, http.StatusNotFound)
+		return
+	} else if err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to get blob %q: %v", id, err), true, http.StatusInternalServerError)
+		return
+	}
+	s.writeBlob(w, b)
+}
+
+// handleBlobState writes the metadata of a blob, it is used to record the state of blobs in the audit log.
+func (s *Service) handleBlobState(w http.ResponseWriter, r *http.Request) {
+	id, err := s.blobIDFromPath(r.URL.Path)
+	if err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusBadRequest)
+		return
+	}
+	b, err := s.BlobInfo(id)
+	if err == ErrNoBlobExists {
+		httpd.HttpError(w, fmt.Sprintf("no blob exists with ID %q", id), true, http.StatusNotFound)
+		return
+	} else if err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to get blob %q: %v", id, err), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(convertBlob(b), true))
+}
+
+func (s *Service) handleDeleteBlob(w http.ResponseWriter, r *http.Request) {
+	id, err := s.blobIDFromPath(r.URL.Path)
+	if err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusBadRequest)
+		return
+	}
+	if err := s.DeleteBlob(id); err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to delete blob %q: %v", id, err), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tagFromPath returns the tag name and whether the history of the tag was requested.
+func (s *Service) tagFromPath(p string) (string, bool, error) {
+	rest := strings.TrimPrefix(p, blobTagsBasePathAnchored)
+	name := rest
+	history := false
+	if i := strings.Index(rest, "/"); i >= 0 {
+		name = rest[:i]
+		if rest[i+1:] != tagHistoryPath {
+			return "", false, fmt.Errorf("invalid tag path %q", p)
+		}
+		history = true
+	}
+	if name == "" {
+		return "", false, fmt.Errorf("invalid tag path %q", p)
+	}
+	return name, history, nil
+}
+
+func (s *Service) handleTag(w http.ResponseWriter, r *http.Request) {
+	name, history, err := s.tagFromPath(r.URL.Path)
+	if err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusBadRequest)
+		return
+	}
+	if history {
+		t, err := s.Tag(name)
+		if err == ErrNoTagExists {
+			httpd.HttpError(w, fmt.Sprintf("no tag exists with name %q", name), true, http.StatusNotFound)
+			return
+		} else if err != nil {
+			httpd.HttpError(w, fmt.Sprintf("failed to get tag %q: %v", name, err), true, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write(httpd.MarshalJSON(convertTagHistory(t), true))
+		return
+	}
+	b, err := s.blobByTag(name)
+	if err == ErrNoTagExists {
+		httpd.HttpError(w, fmt.Sprintf("no tag exists with name %q", name), true, http.StatusNotFound)
+		return
+	} else if err == ErrNoBlobExists {
+		httpd.HttpError(w, fmt.Sprintf("blob for tag %q no longer exists", name), true, http.StatusNotFound)
+		return
+	} else if err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to get blob for tag %q: %v", name, err), true, http.StatusInternalServerError)
+		return
+	}
+	s.writeBlob(w, b)
+}
+
+// handleTagState writes the tag and the blob it references, it is used to record the state of tags in the audit log.
+func (s *Service) handleTagState(w http.ResponseWriter, r *http.Request) {
+	name, history, err := s.tagFromPath(r.URL.Path)
+	if err != nil || history {
+		httpd.HttpError(w, fmt.Sprintf("invalid tag path %q", r.URL.Path), true, http.StatusBadRequest)
+		return
+	}
+	t, err := s.Tag(name)
+	if err == ErrNoTagExists {
+		httpd.HttpError(w, fmt.Sprintf("no tag exists with name %q", name), true, http.StatusNotFound)
+		return
+	} else if err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to get tag %q: %v", name, err), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(convertTag(t), true))
+}
+
+func (s *Service) handleTagBlob(w http.ResponseWriter, r *http.Request) {
+	name, history, err := s.tagFromPath(r.URL.Path)
+	if err != nil || history {
+		httpd.HttpError(w, fmt.Sprintf("invalid tag path %q", r.URL.Path), true, http.StatusBadRequest)
+		return
+	}
+	opt := client.TagBlobOptions{}
+	if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid JSON: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	if opt.Blob == "" {
+		httpd.HttpError(w, "must provide a blob ID", true, http.StatusBadRequest)
+		return
+	}
+	if !validTagName.MatchString(name) {
+		httpd.HttpError(w, fmt.Sprintf("tag name must contain only letters, numbers, '-', '.' and '_'. %q", name), true, http.StatusBadRequest)
+		return
+	}
+	t, err := s.TagBlob(name, opt.Blob)
+	if err == ErrNoBlobExists {
+		httpd.HttpError(w, fmt.Sprintf("no blob exists with ID %q", opt.Blob), true, http.StatusNotFound)
+		return
+	} else if err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to tag blob: %v", err), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(convertTag(t), true))
+}
+
+func (s *Service) handleDeleteTag(w http.ResponseWriter, r *http.Request) {
+	name, history, err := s.tagFromPath(r.URL.Path)
+	if err != nil || history {
+		httpd.HttpError(w, fmt.Sprintf("invalid tag path %q", r.URL.Path), true, http.StatusBadRequest)
+		return
+	}
+	if err := s.DeleteTag(name); err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to delete tag %q: %v", name, err), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,287 @@
+package blobstore
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/kapacitor/services/storage"
+)
+
+func newTestService(t *testing.T) *Service {
+	store := storage.NewMemStore("blob_store")
+	blobs, err := newBlobKV(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := newTagKV(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(log.New(os.Stderr, "[blobstore] ", log.LstdFlags))
+	s.blobs = blobs
+	s.tags = tags
+	return s
+}
+
+func TestBlob_MarshalBinary(t *testing.T) {
+	b := Blob{
+		ID:   BlobID([]byte("data")),
+		Size: 4,
+		Data: []byte("data"),
+	}
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Blob{}
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, b) {
+		t.Errorf("unexpected blob:\ngot\n%v\nexp\n%v\n", got, b)
+	}
+}
+
+func TestService_CreateBlob(t *testing.T) {
+	s := newTestService(t)
+
+	b1, err := s.CreateBlob([]byte("model v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := BlobID([]byte("model v1")); b1.ID != exp {
+		t.Errorf("unexpected blob ID: got %s exp %s", b1.ID, exp)
+	}
+	if b1.Size != 8 {
+		t.Errorf("unexpected blob size: got %d exp 8", b1.Size)
+	}
+
+	// Creating the same content again returns the original blob.
+	b1Again, err := s.CreateBlob([]byte("model v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b1Again.Created.Equal(b1.Created) {
+		t.Errorf("expected the original blob to be returned, got created %v exp %v", b1Again.Created, b1.Created)
+	}
+
+	got, err := s.Blob(b1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data, []byte("model v1")) {
+		t.Errorf("unexpected blob data: got %q", got.Data)
+	}
+
+	if err := s.DeleteBlob(b1.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Blob(b1.ID); err != ErrNoBlobExists {
+		t.Errorf("unexpected error after delete: got %v exp %v", err, ErrNoBlobExists)
+	}
+}
+
+func TestService_CreateBlob_Chunks(t *testing.T) {
+	s := newTestService(t)
+
+	// The data spans several chunks, the last of which is partial.
+	data := make([]byte, 2*chunkSize+10)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	b, err := s.CreateBlobFrom(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := BlobID(data); b.ID != exp {
+		t.Errorf("unexpected blob ID: got %s exp %s", b.ID, exp)
+	}
+	if b.Size != int64(len(data)) || b.Chunks != 3 || b.Data != nil {
+		t.Errorf("unexpected blob: got size %d chunks %d data %d bytes", b.Size, b.Chunks, len(b.Data))
+	}
+
+	got, err := s.Blob(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data, data) {
+		t.Error("unexpected blob data")
+	}
+
+	// Creating the same content again does not keep a second copy of the chunks.
+	again, err := s.CreateBlobFrom(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ChunksKey != b.ChunksKey {
+		t.Errorf("expected the original chunks to be kept, got %s exp %s", again.ChunksKey, b.ChunksKey)
+	}
+
+	if err := s.DeleteBlob(b.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.blobs.Chunk(b.ChunksKey, 0); err != ErrNoChunkExists {
+		t.Errorf("unexpected error getting chunk of deleted blob: got %v exp %v", err, ErrNoChunkExists)
+	}
+}
+
+func TestService_Blob_Unchunked(t *testing.T) {
+	s := newTestService(t)
+
+	// Blobs created before data was chunked store their data in the blob.
+	data := []byte("model v0")
+	if err := s.blobs.Create(Blob{ID: BlobID(data), Size: int64(len(data)), Data: data}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Blob(BlobID(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data, data) {
+		t.Errorf("unexpected blob data: got %q", got.Data)
+	}
+	info, err := s.BlobInfo(BlobID(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Data != nil || info.Size != int64(len(data)) {
+		t.Errorf("unexpected blob info %+v", info)
+	}
+}
+
+func TestService_TagBlob_History(t *testing.T) {
+	s := newTestService(t)
+
+	b1, err := s.CreateBlob([]byte("model v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2, err := s.CreateBlob([]byte("model v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.TagBlob("model", "missing"); err != ErrNoBlobExists {
+		t.Errorf("unexpected error tagging missing blob: got %v exp %v", err, ErrNoBlobExists)
+	}
+	if _, err := s.TagBlob("bad/name", b1.ID); err == nil {
+		t.Error("expected error tagging with invalid name")
+	}
+
+	for _, id := range []string{b1.ID, b2.ID, b2.ID, b1.ID} {
+		if _, err := s.TagBlob("model", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tag, err := s.Tag("model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Re-tagging the current blob does not add history.
+	var history []string
+	for _, e := range tag.History {
+		history = append(history, e.BlobID)
+	}
+	if exp := []string{b1.ID, b2.ID, b1.ID}; !reflect.DeepEqual(history, exp) {
+		t.Errorf("unexpected tag history:\ngot\n%v\nexp\n%v\n", history, exp)
+	}
+
+	got, err := s.BlobByTag("model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != b1.ID {
+		t.Errorf("unexpected blob by tag: got %s exp %s", got.ID, b1.ID)
+	}
+
+	// Deleting the referenced blob leaves the tag dangling.
+	if err := s.DeleteBlob(b1.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.BlobByTag("model"); err != ErrNoBlobExists {
+		t.Errorf("unexpected error for dangling tag: got %v exp %v", err, ErrNoBlobExists)
+	}
+
+	if err := s.DeleteTag("model"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Tag("model"); err != ErrNoTagExists {
+		t.Errorf("unexpected error after delete: got %v exp %v", err, ErrNoTagExists)
+	}
+}
+
+// failingTagDAO fails all operations.
+type failingTagDAO struct{}
+
+func (failingTagDAO) Get(string) (Tag, error)              { return Tag{}, errors.New("storage failed") }
+func (failingTagDAO) Put(Tag) error                        { return errors.New("storage failed") }
+func (failingTagDAO) Delete(string) error                  { return errors.New("storage failed") }
+func (failingTagDAO) List(string, int, int) ([]Tag, error) { return nil, errors.New("storage failed") }
+func (failingTagDAO) Rebuild() error                       { return errors.New("storage failed") }
+
+func TestService_HandleTagBlob(t *testing.T) {
+	s := newTestService(t)
+	b, err := s.CreateBlob([]byte("model v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name    string
+		tag     string
+		blob    string
+		failing bool
+		code    int
+	}{
+		{name: "tagged", tag: "model", blob: b.ID, code: http.StatusOK},
+		{name: "unknown blob", tag: "model", blob: "missing", code: http.StatusNotFound},
+		{name: "invalid name", tag: "bad*name", blob: b.ID, code: http.StatusBadRequest},
+		{name: "storage error", tag: "model", blob: b.ID, failing: true, code: http.StatusInternalServerError},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tags := s.tags
+			if tc.failing {
+				s.tags = failingTagDAO{}
+				defer func() { s.tags = tags }()
+			}
+			r := httptest.NewRequest("PUT", blobTagsBasePathAnchored+tc.tag, strings.NewReader(`{"blob":"`+tc.blob+`"}`))
+			w := httptest.NewRecorder()
+			s.handleTagBlob(w, r)
+			if w.Code != tc.code {
+				t.Errorf("unexpected status code got %d exp %d: %s", w.Code, tc.code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTagKV_List(t *testing.T) {
+	kv, err := newTagKV(storage.NewMemStore("tags"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"model-a", "model-b", "other"} {
+		if err := kv.Put(Tag{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tags, err := kv.List("model-*", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	if exp := []string{"model-a", "model-b"}; !reflect.DeepEqual(names, exp) {
+		t.Errorf("unexpected tags:\ngot\n%v\nexp\n%v\n", names, exp)
+	}
+}
@@ -187,13 +187,14 @@ func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
 	}
 }
 
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
 	if err := h.s.Alert(
 		h.c.Room,
 		h.c.Token,
 		event.State.Message,
 		event.State.Level,
 	); err != nil {
-		h.logger.Println("E! failed to send event to HipChat", err)
+		return fmt.Errorf("failed to send event to HipChat: %v", err)
 	}
+	return nil
 }
@@ -0,0 +1,183 @@
+package httpd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/kapacitor/auth"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditEnable  = "enable"
+	AuditDisable = "disable"
+)
+
+// AuditEvent describes a successful API request that changed a resource.
+type AuditEvent struct {
+	Time     time.Time
+	Username string
+	// Remote address of the request
+	Source string
+	Method string
+	// URL path of the changed resource
+	Resource string
+	// One of create, update, delete, enable or disable
+	Action string
+	// JSON representation of the resource before and after the request.
+	// Nil if the resource did not exist.
+	Before []byte
+	After  []byte
+}
+
+// auditedMethod reports whether requests with the method change resources.
+func auditedMethod(method string) bool {
+	switch method {
+	case "POST", "PATCH", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// audit records the requests that change resources with the audit service.
+// The state of the resource is retrieved from the GET route of the resource before and after the request.
+// The state before the request is not retrieved for POST requests to collections,
+// since the created resource is unknown until the request completes.
+func (h *Handler) audit(inner AuthorizationHandler, collection bool) AuthorizationHandler {
+	return func(w http.ResponseWriter, r *http.Request, user auth.User) {
+		if h.AuditService == nil || !auditedMethod(r.Method) {
+			inner(w, r, user)
+			return
+		}
+		resource := r.URL.Path
+		var before []byte
+		if r.Method != "POST" || !collection {
+			before = h.resourceState(resource)
+		}
+
+		aw := &auditResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		inner(aw, r, user)
+		if aw.status/100 != 2 {
+			return
+		}
+
+		// Created resources are identified by the link in the response.
+		if r.Method == "POST" {
+			if href := linkHref(aw.body.Bytes()); href != "" && href != resource {
+				resource = href
+				before = nil
+			}
+		}
+		var after []byte
+		if r.Method != "DELETE" {
+			after = h.resourceState(resource)
+		}
+
+		h.AuditService.Record(AuditEvent{
+			Time:     time.Now().UTC(),
+			Username: user.Name(),
+			Source:   r.RemoteAddr,
+			Method:   r.Method,
+			Resource: resource,
+			Action:   auditAction(r.Method, before, after),
+			Before:   before,
+			After:    after,
+		})
+	}
+}
+
+// auditAction determines the action of a request from its method and the change of the resource.
+func auditAction(method string, before, after []byte) string {
+	switch {
+	case method == "DELETE":
+		return AuditDelete
+	case before == nil && after != nil:
+		return AuditCreate
+	case before == nil:
+		// Actions on resources without a representation, i.e. storage actions
+		return AuditUpdate
+	}
+	// Changes to the status of a resource are enabling or disabling it.
+	type status struct {
+		Status string `json:"status"`
+	}
+	var b, a status
+	if json.Unmarshal(before, &b) == nil && json.Unmarshal(after, &a) == nil && b.Status != a.Status {
+		switch a.Status {
+		case "enabled":
+			return AuditEnable
+		case "disabled":
+			return AuditDisable
+		}
+	}
+	return AuditUpdate
+}
+
+// linkHref returns the href of the self link of a JSON response.
+func linkHref(body []byte) string {
+	var resp struct {
+		Link struct {
+			Href string `json:"href"`
+		} `json:"link"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return ""
+	}
+	return resp.Link.Href
+}
+
+// resourceState returns the JSON representation of the resource at the path,
+// or nil if it cannot be retrieved.
+func (h *Handler) resourceState(p string) []byte {
+	req, err := http.NewRequest("GET", p, nil)
+	if err != nil {
+		return nil
+	}
+	sw := &stateResponseWriter{header: make(http.Header), status: http.StatusOK}
+	h.stateMux.ServeHTTP(sw, req)
+	if sw.status != http.StatusOK || !json.Valid(sw.body.Bytes()) {
+		return nil
+	}
+	return sw.body.Bytes()
+}
+
+// auditResponseWriter captures the status and body of a response.
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *auditResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// stateResponseWriter buffers the response of an internal request for the state of a resource.
+type stateResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *stateResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *stateResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *stateResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
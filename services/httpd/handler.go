@@ -43,6 +43,17 @@ const (
 	BasePreviewPath = "/kapacitor/v1preview"
 	// Name of the special user for subscriptions
 	SubscriptionUser = "~subscriber"
+
+	// Challenge sent to clients that fail to authenticate so they may retry with basic auth.
+	basicAuthChallenge = `Basic realm="kapacitor"`
+
+	// Issuer of the JWTs created by Kapacitor, only these tokens can be revoked.
+	TokenIssuer = "kapacitor"
+	// Claims of a JWT that define the privileges of the user.
+	// When either claim is present the user is restricted to the privileges of the claims,
+	// which never exceed the privileges of the user the token was issued for.
+	AdminClaim      = "admin"
+	PrivilegesClaim = "privileges"
 )
 
 // AuthenticationMethod defines the type of authentication used.
@@ -63,6 +74,15 @@ type Route struct {
 	HandlerFunc interface{}
 	NoGzip      bool
 	NoJSON      bool
+	// ScopedAuthorization allows the request if the user is authorized for any resource below the route.
+	// The handler is then responsible for authorizing access to the individual resources.
+	// Only valid for handlers that accept the authenticated user.
+	ScopedAuthorization bool
+	// NoAudit disables recording requests to the route in the audit log.
+	NoAudit bool
+	// StateHandlerFunc retrieves the state of audited resources in place of the HandlerFunc of a GET route,
+	// i.e. for routes that return raw data instead of a JSON representation of the resource.
+	StateHandlerFunc interface{}
 }
 
 // Handler represents an HTTP handler for the Kapacitor API server.
@@ -78,6 +98,12 @@ type Handler struct {
 
 	AuthService auth.Interface
 
+	AuditService interface {
+		Record(AuditEvent)
+	}
+	// Unauthenticated GET routes used to retrieve the state of audited resources.
+	stateMux *ServeMux
+
 	PointsWriter interface {
 		WritePoints(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error
 	}
@@ -111,6 +137,7 @@ func NewHandler(
 ) *Handler {
 	h := &Handler{
 		methodMux:             make(map[string]*ServeMux),
+		stateMux:              NewServeMux(),
 		requireAuthentication: requireAuthentication,
 		sharedSecret:          sharedSecret,
 		allowGzip:             allowGzip,
@@ -138,6 +165,7 @@ func NewHandler(
 			Method:      method,
 			Pattern:     "/",
 			HandlerFunc: h.serve404,
+			NoAudit:     true,
 		}
 		h.addRawRoute(route)
 		previewRoute := Route{
@@ -145,6 +173,7 @@ func NewHandler(
 			Method:      method,
 			Pattern:     BasePreviewPath + "/",
 			HandlerFunc: h.rewritePreview,
+			NoAudit:     true,
 		}
 		h.addRawRoute(previewRoute)
 	}
@@ -167,6 +196,7 @@ func NewHandler(
 			Method:      "POST",
 			Pattern:     BasePath + "/write",
 			HandlerFunc: h.serveWrite,
+			NoAudit:     true,
 		},
 		{
 			// Satisfy CORS checks.
@@ -179,6 +209,7 @@ func NewHandler(
 			Method:      "POST",
 			Pattern:     "/write",
 			HandlerFunc: h.serveWrite,
+			NoAudit:     true,
 		},
 		{
 			// Satisfy CORS checks.
@@ -286,19 +317,32 @@ func (h *Handler) addRawRoutes(routes []Route) error {
 
 // Add a route without prepending the BasePath
 func (h *Handler) addRawRoute(r Route) error {
-	var handler http.Handler
+	var handler AuthorizationHandler
 	// If it's a handler func that requires special authorization, wrap it in authentication only.
 	if hf, ok := r.HandlerFunc.(func(http.ResponseWriter, *http.Request, auth.User)); ok {
-		handler = authenticate(authorizeForward(hf), h, h.requireAuthentication)
+		if r.ScopedAuthorization {
+			handler = authorizeScopedForward(hf)
+		} else {
+			handler = authorizeForward(hf)
+		}
+	} else if r.ScopedAuthorization {
+		return errors.New("route with scoped authorization must have a handler function that accepts the user")
 	}
 
 	// This is a normal handler signature so perform standard authentication/authorization.
 	if hf, ok := r.HandlerFunc.(func(http.ResponseWriter, *http.Request)); ok {
-		handler = authenticate(authorize(hf), h, h.requireAuthentication)
+		handler = authorize(hf)
 	}
 	if handler == nil {
 		return errors.New("route does not have valid handler function")
 	}
+	if !r.NoAudit {
+		handler = h.audit(handler, !strings.HasSuffix(r.Pattern, "/"))
+	}
+	return h.handleRoute(r, authenticate(handler, h, h.requireAuthentication))
+}
+
+func (h *Handler) handleRoute(r Route, handler http.Handler) error {
 
 	// Set basic handlers for all requests
 	if !r.NoJSON {
@@ -320,7 +364,31 @@ func (h *Handler) addRawRoute(r Route) error {
 	if !ok {
 		return fmt.Errorf("unsupported method %q", r.Method)
 	}
-	return mux.Handle(r.Pattern, handler)
+	if err := mux.Handle(r.Pattern, handler); err != nil {
+		return err
+	}
+	if r.Method == "GET" && !r.NoAudit {
+		hf := r.HandlerFunc
+		if r.StateHandlerFunc != nil {
+			hf = r.StateHandlerFunc
+		}
+		return h.stateMux.Handle(r.Pattern, stateHandler(hf))
+	}
+	return nil
+}
+
+// stateHandler returns the handler of a GET route without authentication,
+// it is only used internally to retrieve the state of audited resources.
+func stateHandler(hf interface{}) http.Handler {
+	switch hf := hf.(type) {
+	case func(http.ResponseWriter, *http.Request, auth.User):
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hf(w, r, auth.AdminUser)
+		})
+	case func(http.ResponseWriter, *http.Request):
+		return http.HandlerFunc(hf)
+	}
+	return http.NotFoundHandler()
 }
 
 func (h *Handler) DelRoutes(routes []Route) {
@@ -341,6 +409,9 @@ func (h *Handler) delRawRoute(r Route) {
 	if ok {
 		mux.Deregister(r.Pattern)
 	}
+	if r.Method == "GET" {
+		h.stateMux.Deregister(r.Pattern)
+	}
 }
 
 // RewritePreview rewrites the URL path from BasePreviewPath to BasePath,
@@ -585,6 +656,7 @@ func authenticate(inner AuthorizationHandler, h *Handler, requireAuthentication
 		creds, err := parseCredentials(r)
 		if err != nil {
 			h.statMap.Add(statAuthFail, 1)
+			w.Header().Set("WWW-Authenticate", basicAuthChallenge)
 			HttpError(w, err.Error(), false, http.StatusUnauthorized)
 			return
 		}
@@ -600,10 +672,25 @@ func authenticate(inner AuthorizationHandler, h *Handler, requireAuthentication
 			user, err = h.AuthService.Authenticate(creds.Username, creds.Password)
 			if err != nil {
 				h.statMap.Add(statAuthFail, 1)
+				w.Header().Set("WWW-Authenticate", basicAuthChallenge)
 				HttpError(w, "authorization failed", false, http.StatusUnauthorized)
 				return
 			}
 		case BearerAuthentication:
+			if !isJWT(creds.Token) {
+				// Long-lived API token
+				if user, err = h.AuthService.APITokenUser(creds.Token); err != nil {
+					h.statMap.Add(statAuthFail, 1)
+					HttpError(w, fmt.Sprintf("invalid token: %s", err.Error()), false, http.StatusUnauthorized)
+					return
+				}
+				break
+			}
+			if h.sharedSecret == "" {
+				h.statMap.Add(statAuthFail, 1)
+				HttpError(w, "JWT authentication requires a shared secret to be configured", false, http.StatusUnauthorized)
+				return
+			}
 			keyLookupFn := func(token *jwt.Token) (interface{}, error) {
 				// Check for expected signing method.
 				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -645,10 +732,35 @@ func authenticate(inner AuthorizationHandler, h *Handler, requireAuthentication
 				return
 			}
 
-			if user, err = h.AuthService.User(username); err != nil {
+			// Check that tokens issued by Kapacitor have not been revoked.
+			iss, _ := claims["iss"].(string)
+			if iss == TokenIssuer {
+				id, _ := claims["jti"].(string)
+				if revoked, err := h.AuthService.TokenRevoked(id); err != nil {
+					HttpError(w, err.Error(), false, http.StatusUnauthorized)
+					return
+				} else if revoked {
+					HttpError(w, "token has been revoked", false, http.StatusUnauthorized)
+					return
+				}
+			}
+
+			var fromClaims bool
+			if user, fromClaims, err = claimsUser(username, claims); err != nil {
+				HttpError(w, fmt.Sprintf("invalid token: %s", err.Error()), false, http.StatusUnauthorized)
+				return
+			}
+			owner, err := h.AuthService.User(username)
+			if err != nil {
 				HttpError(w, err.Error(), false, http.StatusUnauthorized)
 				return
 			}
+			if fromClaims {
+				// Tokens never have more privileges than their user currently has, whoever issued them.
+				user = restrictUser(user, owner)
+			} else {
+				user = owner
+			}
 		case SubscriptionAuthentication:
 			if user, err = h.AuthService.SubscriptionUser(creds.Token); err != nil {
 				HttpError(w, err.Error(), false, http.StatusUnauthorized)
@@ -661,6 +773,72 @@ func authenticate(inner AuthorizationHandler, h *Handler, requireAuthentication
 	})
 }
 
+// isJWT reports whether the bearer token is a JWT, as opposed to an API token.
+// JWTs consist of three parts separated by periods.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// claimsUser creates a user from the admin and privileges claims of a token.
+// The privileges claim is a map of resource to a list of privilege names.
+// Returns false if the token contains neither claim.
+func claimsUser(username string, claims jwt.MapClaims) (auth.User, bool, error) {
+	adminClaim, hasAdmin := claims[AdminClaim]
+	privilegesClaim, hasPrivileges := claims[PrivilegesClaim]
+	if !hasAdmin && !hasPrivileges {
+		return auth.User{}, false, nil
+	}
+	admin := false
+	if hasAdmin {
+		a, ok := adminClaim.(bool)
+		if !ok {
+			return auth.User{}, false, fmt.Errorf("%s claim must be a boolean", AdminClaim)
+		}
+		admin = a
+	}
+	privileges := make(map[string][]auth.Privilege)
+	if hasPrivileges {
+		resources, ok := privilegesClaim.(map[string]interface{})
+		if !ok {
+			return auth.User{}, false, fmt.Errorf("%s claim must be a map of resource to privileges", PrivilegesClaim)
+		}
+		for resource, ps := range resources {
+			list, ok := ps.([]interface{})
+			if !ok {
+				return auth.User{}, false, fmt.Errorf("privileges of resource %q must be a list", resource)
+			}
+			for _, p := range list {
+				name, _ := p.(string)
+				privilege, err := auth.ParsePrivilege(name)
+				if err != nil {
+					return auth.User{}, false, err
+				}
+				privileges[resource] = append(privileges[resource], privilege)
+			}
+		}
+	}
+	return auth.NewUser(username, nil, admin, privileges), true, nil
+}
+
+// restrictUser returns the user with only the admin status and privileges that the owner also has.
+func restrictUser(user, owner auth.User) auth.User {
+	if owner.IsAdmin() {
+		return user
+	}
+	if user.IsAdmin() {
+		return owner
+	}
+	privileges := make(map[string][]auth.Privilege)
+	for resource, ps := range user.Privileges() {
+		for _, p := range ps {
+			if owner.AuthorizeAction(auth.Action{Resource: resource, Privilege: p}) == nil {
+				privileges[resource] = append(privileges[resource], p)
+			}
+		}
+	}
+	return auth.NewUser(user.Name(), nil, false, privileges)
+}
+
 // Map an HTTP method to an auth.Privilege.
 func requiredPrivilegeForHTTPMethod(method string) (auth.Privilege, error) {
 	switch m := strings.ToUpper(method); m {
@@ -682,18 +860,27 @@ type missingPrivilege interface {
 	MissingPrivlege() auth.Privilege
 }
 
+// APIResource returns the resource that is authorized for requests to the URL path.
+func APIResource(p string) string {
+	return auth.APIResource(strings.TrimPrefix(p, BasePath))
+}
+
 // Check if user is authorized to perform request.
-func authorizeRequest(r *http.Request, user auth.User) error {
+func authorizeRequest(r *http.Request, user auth.User, scoped bool) error {
 	// Now that we have a user authorize the request
 	rp, err := requiredPrivilegeForHTTPMethod(r.Method)
 	if err != nil {
 		return err
 	}
 	action := auth.Action{
-		Resource:  auth.APIResource(strings.TrimPrefix(r.URL.Path, BasePath)),
+		Resource:  APIResource(r.URL.Path),
 		Privilege: rp,
 	}
-	err = user.AuthorizeAction(action)
+	if scoped {
+		err = user.AuthorizeAnyAction(action)
+	} else {
+		err = user.AuthorizeAction(action)
+	}
 	if err != nil {
 		if mp, ok := err.(missingPrivilege); ok {
 			return fmt.Errorf("user %s does not have \"%v\" privilege for API endpoint %q", user.Name(), mp.MissingPrivlege(), r.URL.Path)
@@ -707,7 +894,7 @@ func authorizeRequest(r *http.Request, user auth.User) error {
 // Authorize the request and call normal inner handler.
 func authorize(inner http.HandlerFunc) AuthorizationHandler {
 	return func(w http.ResponseWriter, r *http.Request, user auth.User) {
-		if err := authorizeRequest(r, user); err != nil {
+		if err := authorizeRequest(r, user, false); err != nil {
 			HttpError(w, err.Error(), false, http.StatusForbidden)
 			return
 		}
@@ -718,7 +905,18 @@ func authorize(inner http.HandlerFunc) AuthorizationHandler {
 // Authorize the request and forward user to inner handler.
 func authorizeForward(inner AuthorizationHandler) AuthorizationHandler {
 	return func(w http.ResponseWriter, r *http.Request, user auth.User) {
-		if err := authorizeRequest(r, user); err != nil {
+		if err := authorizeRequest(r, user, false); err != nil {
+			HttpError(w, err.Error(), false, http.StatusForbidden)
+			return
+		}
+		inner(w, r, user)
+	}
+}
+
+// Authorize the request for any resource below the requested resource and forward user to inner handler.
+func authorizeScopedForward(inner AuthorizationHandler) AuthorizationHandler {
+	return func(w http.ResponseWriter, r *http.Request, user auth.User) {
+		if err := authorizeRequest(r, user, true); err != nil {
 			HttpError(w, err.Error(), false, http.StatusForbidden)
 			return
 		}
@@ -30,6 +30,23 @@ type Config struct {
 	URL       string            `toml:"url" override:"url"`
 	Headers   map[string]string `toml:"headers" override:"headers"`
 	BasicAuth BasicAuth         `toml:"basic-auth" override:"basic-auth,redact"`
+
+	// Shared secret used to sign the requests with HMAC-SHA256.
+	// Requests are not signed if empty.
+	SigningSecret string `toml:"signing-secret" override:"signing-secret,redact"`
+	// Name of the header containing the signature of the request.
+	// If empty uses DefaultSignatureHeader.
+	SignatureHeader string `toml:"signature-header" override:"signature-header"`
+	// Name of the header containing the timestamp of the request.
+	// If empty uses DefaultTimestampHeader.
+	TimestampHeader string `toml:"timestamp-header" override:"timestamp-header"`
+
+	// Template of the body of alert requests, executed with the alert.TemplateData of the event.
+	// If empty the alert data is posted as JSON.
+	AlertTemplate string `toml:"alert-template" override:"alert-template"`
+	// Template of the body of requests from the httpPost node, executed with the posted row.
+	// If empty the row is posted as JSON.
+	RowTemplate string `toml:"row-template" override:"row-template"`
 }
 
 // Validate ensures that all configurations options are valid. The Endpoint,
@@ -47,6 +64,14 @@ func (c Config) Validate() error {
 		return errors.Wrapf(err, "invalid URL %q", c.URL)
 	}
 
+	if _, err := ParseTemplate("alert-template", c.AlertTemplate); err != nil {
+		return err
+	}
+
+	if _, err := ParseTemplate("row-template", c.RowTemplate); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -66,12 +91,16 @@ func (cs Configs) Validate() error {
 }
 
 // index generates a map from config.Endpoint to config
-func (cs Configs) index() map[string]*Endpoint {
+func (cs Configs) index() (map[string]*Endpoint, error) {
 	m := map[string]*Endpoint{}
 
 	for _, c := range cs {
-		m[c.Endpoint] = NewEndpoint(c.URL, c.Headers, c.BasicAuth)
+		e, err := newEndpointFromConfig(c)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid endpoint %q", c.Endpoint)
+		}
+		m[c.Endpoint] = e
 	}
 
-	return m
+	return m, nil
 }
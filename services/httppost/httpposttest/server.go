@@ -2,8 +2,10 @@ package httpposttest
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 
 	"github.com/influxdata/kapacitor/alert"
 )
@@ -51,3 +53,47 @@ func (s *AlertServer) Close() {
 	s.closed = true
 	s.ts.Close()
 }
+
+// Server records the headers and bodies of the requests posted to it.
+type Server struct {
+	ts       *httptest.Server
+	URL      string
+	mu       sync.Mutex
+	requests []Request
+	closed   bool
+}
+
+func NewServer() *Server {
+	s := new(Server)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, Request{
+			Header: r.Header,
+			Body:   string(body),
+		})
+	}))
+	s.ts = ts
+	s.URL = ts.URL
+	return s
+}
+
+type Request struct {
+	Header http.Header
+	Body   string
+}
+
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) Close() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.ts.Close()
+}
@@ -6,9 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"sync"
+	"text/template"
+	"time"
 
 	"github.com/influxdata/kapacitor/alert"
 	"github.com/influxdata/kapacitor/bufpool"
@@ -21,6 +24,13 @@ type Endpoint struct {
 	headers map[string]string
 	auth    BasicAuth
 	closed  bool
+
+	signingSecret   string
+	signatureHeader string
+	timestampHeader string
+
+	alertTemplate *template.Template
+	rowTemplate   *template.Template
 }
 
 func NewEndpoint(url string, headers map[string]string, auth BasicAuth) *Endpoint {
@@ -30,6 +40,14 @@ func NewEndpoint(url string, headers map[string]string, auth BasicAuth) *Endpoin
 		auth:    auth,
 	}
 }
+
+func newEndpointFromConfig(c Config) (*Endpoint, error) {
+	e := new(Endpoint)
+	if err := e.Update(c); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
 func (e *Endpoint) Close() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -37,12 +55,43 @@ func (e *Endpoint) Close() {
 	return
 }
 
-func (e *Endpoint) Update(c Config) {
+func (e *Endpoint) Update(c Config) error {
+	alertTemplate, err := ParseTemplate("alert-template", c.AlertTemplate)
+	if err != nil {
+		return err
+	}
+	rowTemplate, err := ParseTemplate("row-template", c.RowTemplate)
+	if err != nil {
+		return err
+	}
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	e.url = c.URL
 	e.headers = c.Headers
 	e.auth = c.BasicAuth
+	e.signingSecret = c.SigningSecret
+	e.signatureHeader = c.SignatureHeader
+	e.timestampHeader = c.TimestampHeader
+	e.alertTemplate = alertTemplate
+	e.rowTemplate = rowTemplate
+	return nil
+}
+
+// AlertTemplate returns the template of the body of alert requests,
+// or nil if the alert data is posted as JSON.
+func (e *Endpoint) AlertTemplate() *template.Template {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.alertTemplate
+}
+
+// RowTemplate returns the template of the body of requests from the httpPost node,
+// or nil if the row is posted as JSON.
+func (e *Endpoint) RowTemplate() *template.Template {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.rowTemplate
 }
 
 func (e *Endpoint) NewHTTPRequest(body io.Reader) (req *http.Request, err error) {
@@ -52,6 +101,18 @@ func (e *Endpoint) NewHTTPRequest(body io.Reader) (req *http.Request, err error)
 		return nil, errors.New("endpoint was closed")
 	}
 
+	// The whole body is needed to sign the request.
+	var signature, ts string
+	if e.signingSecret != "" {
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %v", err)
+		}
+		ts = timestamp(time.Now())
+		signature = Sign(e.signingSecret, ts, b)
+		body = bytes.NewReader(b)
+	}
+
 	req, err = http.NewRequest("POST", e.url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create POST request: %v", err)
@@ -65,6 +126,19 @@ func (e *Endpoint) NewHTTPRequest(body io.Reader) (req *http.Request, err error)
 		req.Header.Add(k, v)
 	}
 
+	if signature != "" {
+		signatureHeader := e.signatureHeader
+		if signatureHeader == "" {
+			signatureHeader = DefaultSignatureHeader
+		}
+		timestampHeader := e.timestampHeader
+		if timestampHeader == "" {
+			timestampHeader = DefaultTimestampHeader
+		}
+		req.Header.Set(timestampHeader, ts)
+		req.Header.Set(signatureHeader, signature)
+	}
+
 	return req, nil
 }
 
@@ -74,12 +148,16 @@ type Service struct {
 	logger    *log.Logger
 }
 
-func NewService(c Configs, l *log.Logger) *Service {
+func NewService(c Configs, l *log.Logger) (*Service, error) {
+	endpoints, err := c.index()
+	if err != nil {
+		return nil, err
+	}
 	s := &Service{
 		logger:    l,
-		endpoints: c.index(),
+		endpoints: endpoints,
 	}
-	return s
+	return s, nil
 }
 
 func (s *Service) Endpoint(name string) (*Endpoint, bool) {
@@ -103,10 +181,16 @@ func (s *Service) Update(newConfigs []interface{}) error {
 			}
 			e, ok := s.endpoints[c.Endpoint]
 			if !ok {
-				s.endpoints[c.Endpoint] = NewEndpoint(c.URL, c.Headers, c.BasicAuth)
+				e, err := newEndpointFromConfig(c)
+				if err != nil {
+					return err
+				}
+				s.endpoints[c.Endpoint] = e
 				continue
 			}
-			e.Update(c)
+			if err := e.Update(c); err != nil {
+				return err
+			}
 
 			endpointSet[c.Endpoint] = true
 		} else {
@@ -185,6 +269,9 @@ type HandlerConfig struct {
 	URL      string            `mapstructure:"url"`
 	Endpoint string            `mapstructure:"endpoint"`
 	Headers  map[string]string `mapstructure:"headers"`
+	// Template of the body of the requests, executed with the alert.TemplateData of the event.
+	// If empty uses the alert template of the endpoint.
+	AlertTemplate string `mapstructure:"alert-template"`
 }
 
 type handler struct {
@@ -193,9 +280,15 @@ type handler struct {
 	endpoint *Endpoint
 	logger   *log.Logger
 	headers  map[string]string
+	template *template.Template
 }
 
-func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
+func (s *Service) Handler(c HandlerConfig, l *log.Logger) (alert.Handler, error) {
+	tmpl, err := ParseTemplate("alert-template", c.AlertTemplate)
+	if err != nil {
+		return nil, err
+	}
+
 	e, ok := s.Endpoint(c.Endpoint)
 	if !ok {
 		e = NewEndpoint(c.URL, nil, BasicAuth{})
@@ -207,7 +300,8 @@ func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
 		endpoint: e,
 		logger:   l,
 		headers:  c.Headers,
-	}
+		template: tmpl,
+	}, nil
 }
 
 func (h *handler) NewHTTPRequest(body io.Reader) (req *http.Request, err error) {
@@ -223,32 +317,43 @@ func (h *handler) NewHTTPRequest(body io.Reader) (req *http.Request, err error)
 	return
 }
 
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
 	var err error
 
 	// Construct the body of the HTTP request
 	body := h.bp.Get()
 	defer h.bp.Put(body)
-	ad := event.AlertData()
 
-	err = json.NewEncoder(body).Encode(ad)
-	if err != nil {
-		h.logger.Printf("E! failed to marshal alert data json: %v", err)
-		return
+	tmpl := h.template
+	if tmpl == nil {
+		tmpl = h.endpoint.AlertTemplate()
+	}
+	if tmpl != nil {
+		err = tmpl.Execute(body, event.TemplateData())
+		if err != nil {
+			return alert.Permanent(fmt.Errorf("failed to execute alert template: %v", err))
+		}
+	} else {
+		err = json.NewEncoder(body).Encode(event.AlertData())
+		if err != nil {
+			return alert.Permanent(fmt.Errorf("failed to marshal alert data json: %v", err))
+		}
 	}
 
 	req, err := h.NewHTTPRequest(body)
 	if err != nil {
-		h.logger.Printf("E! fail to create HTTP request: %v", err)
-		return
+		return alert.Permanent(fmt.Errorf("failed to create HTTP request: %v", err))
 	}
 
 	// Execute the request
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		h.logger.Printf("E! failed to POST alert data: %v", err)
-		return
+		return fmt.Errorf("failed to POST alert data: %v", err)
 	}
 	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to POST alert data: unexpected response status %d", resp.StatusCode)
+	}
+	return nil
 }
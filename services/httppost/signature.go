@@ -0,0 +1,37 @@
+package httppost
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultSignatureHeader is the default name of the header containing the signature of a request.
+	DefaultSignatureHeader = "X-Kapacitor-Signature"
+	// DefaultTimestampHeader is the default name of the header containing the timestamp of a request.
+	DefaultTimestampHeader = "X-Kapacitor-Timestamp"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign returns the signature of a request body sent at the timestamp.
+//
+// The signature is the hex encoded HMAC-SHA256 of the timestamp, a period and the body,
+// keyed with the shared secret and prefixed with "sha256=".
+// The timestamp is the time the request was sent as seconds since the Unix epoch,
+// so that receivers can reject replayed requests.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// timestamp returns the timestamp of a request sent at t.
+func timestamp(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
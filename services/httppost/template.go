@@ -0,0 +1,33 @@
+package httppost
+
+import (
+	"encoding/json"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// templateFuncs are the functions available to body templates in addition to the builtin functions.
+var templateFuncs = template.FuncMap{
+	// json returns the JSON encoding of a value,
+	// so that templates can produce valid JSON documents, e.g. {"text":{{json .Message}}}.
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	},
+}
+
+// ParseTemplate parses a body template, returning nil if the template is empty.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", name)
+	}
+	return t, nil
+}
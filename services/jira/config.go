@@ -0,0 +1,45 @@
+package jira
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	// Whether Jira integration is enabled.
+	Enabled bool `toml:"enabled" override:"enabled"`
+	// The base URL of the Jira REST API, i.e. https://example.atlassian.net.
+	URL string `toml:"url" override:"url"`
+	// The username used to authenticate, i.e. the email address of a Jira Cloud user.
+	Username string `toml:"username" override:"username"`
+	// The API token or password used to authenticate.
+	Token string `toml:"token" override:"token,redact"`
+	// The default project key of the issues.
+	Project string `toml:"project" override:"project"`
+	// The default issue type of the issues.
+	IssueType string `toml:"issue-type" override:"issue-type"`
+	// The default ID of the transition of the issues on recovery, i.e. to close them.
+	// If empty issues are only commented on recovery.
+	RecoveryTransition string `toml:"recovery-transition" override:"recovery-transition"`
+	// Whether to skip the tls verification of the Jira host
+	InsecureSkipVerify bool `toml:"insecure-skip-verify" override:"insecure-skip-verify"`
+}
+
+const DefaultIssueType = "Task"
+
+func NewConfig() Config {
+	return Config{
+		IssueType: DefaultIssueType,
+	}
+}
+
+func (c Config) Validate() error {
+	if c.Enabled && c.URL == "" {
+		return errors.New("must specify url")
+	}
+	if _, err := url.Parse(c.URL); err != nil {
+		return errors.Wrapf(err, "invalid url %q", c.URL)
+	}
+	return nil
+}
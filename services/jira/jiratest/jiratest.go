@@ -0,0 +1,149 @@
+// Package jiratest provides a stand-in for the issue API of Jira.
+package jiratest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+const issuePath = "/rest/api/2/issue"
+
+type Server struct {
+	mu       sync.Mutex
+	ts       *httptest.Server
+	URL      string
+	requests []Request
+	issues   map[string]int
+	closed   bool
+
+	// TransitionErrors is the number of transition requests to reject before accepting them again.
+	TransitionErrors int
+}
+
+// NewServer starts a server creating issues with keys made of the project key and a sequence number, i.e. PROJ-1.
+func NewServer() *Server {
+	s := &Server{
+		issues: make(map[string]int),
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		req := Request{
+			Path: r.URL.Path,
+		}
+		req.Username, req.Password, _ = r.BasicAuth()
+		var err error
+		switch {
+		case r.URL.Path == issuePath:
+			var issue struct {
+				Fields struct {
+					Project struct {
+						Key string `json:"key"`
+					} `json:"project"`
+					IssueType struct {
+						Name string `json:"name"`
+					} `json:"issuetype"`
+					Summary     string   `json:"summary"`
+					Description string   `json:"description"`
+					Labels      []string `json:"labels"`
+				} `json:"fields"`
+			}
+			if err = json.NewDecoder(r.Body).Decode(&issue); err != nil {
+				break
+			}
+			req.Issue = &Issue{
+				Project:     issue.Fields.Project.Key,
+				IssueType:   issue.Fields.IssueType.Name,
+				Summary:     issue.Fields.Summary,
+				Description: issue.Fields.Description,
+				Labels:      issue.Fields.Labels,
+			}
+			s.issues[req.Issue.Project]++
+			key := fmt.Sprintf("%s-%d", req.Issue.Project, s.issues[req.Issue.Project])
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{
+				"id":   key,
+				"key":  key,
+				"self": s.URL + issuePath + "/" + key,
+			})
+		case strings.HasSuffix(r.URL.Path, "/comment"):
+			var comment struct {
+				Body string `json:"body"`
+			}
+			if err = json.NewDecoder(r.Body).Decode(&comment); err != nil {
+				break
+			}
+			req.Comment = comment.Body
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(comment)
+		case strings.HasSuffix(r.URL.Path, "/transitions"):
+			if s.TransitionErrors > 0 {
+				s.TransitionErrors--
+				err = fmt.Errorf("transition is not valid")
+				break
+			}
+			var transition struct {
+				Transition struct {
+					ID string `json:"id"`
+				} `json:"transition"`
+			}
+			if err = json.NewDecoder(r.Body).Decode(&transition); err != nil {
+				break
+			}
+			req.Transition = transition.Transition.ID
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			err = fmt.Errorf("unknown path %s", r.URL.Path)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string][]string{
+				"errorMessages": {err.Error()},
+			})
+			return
+		}
+		s.requests = append(s.requests, req)
+	}))
+	s.ts = ts
+	s.URL = ts.URL
+	return s
+}
+
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) Close() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.ts.Close()
+}
+
+// Request is a request to create, comment or transition an issue.
+type Request struct {
+	Path     string
+	Username string
+	Password string
+	// Issue created by the request, nil unless the request created an issue.
+	Issue *Issue
+	// Comment added by the request.
+	Comment string
+	// ID of the transition performed by the request.
+	Transition string
+}
+
+type Issue struct {
+	Project     string
+	IssueType   string
+	Summary     string
+	Description string
+	Labels      []string
+}
@@ -0,0 +1,431 @@
+package jira
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	text "text/template"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/pkg/errors"
+)
+
+// Maximum length of the summary of an issue.
+const maxSummaryLen = 255
+
+// Default templates of the issues
+const (
+	defaultSummaryTemplate         = `{{ .Message }}`
+	defaultDescriptionTemplate     = "{{ .Message }}\n\nID: {{ .ID }}\nLevel: {{ .Level }}\nTime: {{ .Time }}{{ range $k, $v := .Tags }}\n{{ $k }}: {{ $v }}{{ end }}"
+	defaultRecoveryCommentTemplate = `{{ .ID }} recovered: {{ .Message }}`
+)
+
+type Service struct {
+	configValue atomic.Value
+	clientValue atomic.Value
+	logger      *log.Logger
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	s := &Service{
+		logger: l,
+	}
+	s.configValue.Store(c)
+	s.clientValue.Store(newClient(c))
+	return s
+}
+
+func newClient(c Config) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify},
+		},
+	}
+}
+
+func (s *Service) Open() error {
+	return nil
+}
+
+func (s *Service) Close() error {
+	return nil
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if l := len(newConfig); l != 1 {
+		return fmt.Errorf("expected only one new config object, got %d", l)
+	}
+	if c, ok := newConfig[0].(Config); !ok {
+		return fmt.Errorf("expected config object to be of type %T, got %T", c, newConfig[0])
+	} else {
+		s.configValue.Store(c)
+		s.clientValue.Store(newClient(c))
+	}
+	return nil
+}
+
+type testOptions struct {
+	Project     string   `json:"project"`
+	IssueType   string   `json:"issue-type"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+}
+
+func (s *Service) TestOptions() interface{} {
+	c := s.config()
+	return &testOptions{
+		Project:     c.Project,
+		IssueType:   c.IssueType,
+		Summary:     "test kapacitor issue",
+		Description: "test kapacitor issue description",
+		Labels:      []string{},
+	}
+}
+
+func (s *Service) Test(options interface{}) error {
+	o, ok := options.(*testOptions)
+	if !ok {
+		return fmt.Errorf("unexpected options type %T", options)
+	}
+	_, err := s.CreateIssue(o.Project, o.IssueType, o.Summary, o.Description, o.Labels)
+	return err
+}
+
+type issueFields struct {
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	IssueType struct {
+		Name string `json:"name"`
+	} `json:"issuetype"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+// CreateIssue creates an issue and returns its key.
+// If empty the project and issue type from the configuration are used.
+func (s *Service) CreateIssue(project, issueType, summary, description string, labels []string) (string, error) {
+	c := s.config()
+	if !c.Enabled {
+		return "", errors.New("service is not enabled")
+	}
+	if project == "" {
+		project = c.Project
+	}
+	if project == "" {
+		return "", errors.New("no project specified")
+	}
+	if issueType == "" {
+		issueType = c.IssueType
+	}
+	if issueType == "" {
+		issueType = DefaultIssueType
+	}
+
+	var issue struct {
+		Fields issueFields `json:"fields"`
+	}
+	issue.Fields.Project.Key = project
+	issue.Fields.IssueType.Name = issueType
+	issue.Fields.Summary = summary
+	issue.Fields.Description = description
+	issue.Fields.Labels = labels
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := s.post("rest/api/2/issue", issue, &created); err != nil {
+		return "", err
+	}
+	if created.Key == "" {
+		return "", errors.New("no issue key in response")
+	}
+	return created.Key, nil
+}
+
+// AddComment adds a comment to the issue.
+func (s *Service) AddComment(key, comment string) error {
+	return s.post(
+		path.Join("rest/api/2/issue", key, "comment"),
+		map[string]string{"body": comment},
+		nil,
+	)
+}
+
+// TransitionIssue performs the transition with the given ID on the issue.
+func (s *Service) TransitionIssue(key, transition string) error {
+	return s.post(
+		path.Join("rest/api/2/issue", key, "transitions"),
+		map[string]map[string]string{"transition": {"id": transition}},
+		nil,
+	)
+}
+
+// post posts the JSON encoded body to the path of the API and decodes the response into result, unless it is nil.
+func (s *Service) post(p string, body, result interface{}) error {
+	c := s.config()
+	if !c.Enabled {
+		return errors.New("service is not enabled")
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return err
+	}
+	u.Path = path.Join(u.Path, p)
+
+	var post bytes.Buffer
+	if err := json.NewEncoder(&post).Encode(body); err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", u.String(), &post)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Username != "" || c.Token != "" {
+		req.SetBasicAuth(c.Username, c.Token)
+	}
+
+	client := s.clientValue.Load().(*http.Client)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		return responseError(resp.StatusCode, data)
+	}
+	if result == nil {
+		return nil
+	}
+	return errors.Wrap(json.NewDecoder(resp.Body).Decode(result), "failed to decode Jira response")
+}
+
+// responseError returns the error of a failed request, using the error messages of the Jira response if any.
+func responseError(code int, data []byte) error {
+	var r struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &r); err != nil || len(r.ErrorMessages)+len(r.Errors) == 0 {
+		return fmt.Errorf("failed to understand Jira response. code: %d content: %s", code, string(data))
+	}
+	msgs := r.ErrorMessages
+	for field, msg := range r.Errors {
+		msgs = append(msgs, field+": "+msg)
+	}
+	return fmt.Errorf("Jira request failed with code %d: %s", code, strings.Join(msgs, "; "))
+}
+
+// IssueAnnotation returns the key of the event annotation containing the key of the issue opened in the project.
+func IssueAnnotation(project string) string {
+	return "jira-issue:" + project
+}
+
+// EventAnnotator stores annotations in the state of events.
+type EventAnnotator interface {
+	AnnotateEvent(topic, event, key, value string) error
+}
+
+type HandlerConfig struct {
+	// Project key of the issues.
+	// If empty uses the project from the configuration.
+	Project string `mapstructure:"project"`
+	// Issue type of the issues.
+	// If empty uses the issue type from the configuration.
+	IssueType string `mapstructure:"issue-type"`
+	// Template of the summary of the issues.
+	// If empty uses the message of the event.
+	Summary string `mapstructure:"summary"`
+	// Template of the description of the issues.
+	// If empty uses the message, ID, level, time and tags of the event.
+	Description string `mapstructure:"description"`
+	// Labels of the issues.
+	Labels []string `mapstructure:"labels"`
+	// ID of the transition of the issues on recovery.
+	// If empty uses the recovery transition from the configuration.
+	RecoveryTransition string `mapstructure:"recovery-transition"`
+	// Template of the comment added to the issues on recovery.
+	// If empty uses the ID and message of the event.
+	RecoveryComment string `mapstructure:"recovery-comment"`
+}
+
+type eventKey struct {
+	topic string
+	id    string
+}
+
+// handler opens an issue for an event, and comments and transitions the issue when the event recovers.
+// Only one issue is opened while the event is active, the key of the issue is kept in the state of the event,
+// so that it survives restarts.
+type handler struct {
+	s         *Service
+	c         HandlerConfig
+	annotator EventAnnotator
+	logger    *log.Logger
+
+	summary         *text.Template
+	description     *text.Template
+	recoveryComment *text.Template
+
+	mu sync.Mutex
+	// Keys of the issues opened for active events.
+	// Events may be queued before the key of the issue is stored in their state.
+	issues map[eventKey]string
+	// Issues commented on recovery whose transition failed,
+	// so that retrying the recovery does not comment them again.
+	commented map[string]bool
+}
+
+func (s *Service) Handler(c HandlerConfig, a EventAnnotator, l *log.Logger) (alert.Handler, error) {
+	summary, err := parseTemplate("summary", c.Summary, defaultSummaryTemplate)
+	if err != nil {
+		return nil, err
+	}
+	description, err := parseTemplate("description", c.Description, defaultDescriptionTemplate)
+	if err != nil {
+		return nil, err
+	}
+	recoveryComment, err := parseTemplate("recovery-comment", c.RecoveryComment, defaultRecoveryCommentTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{
+		s:               s,
+		c:               c,
+		annotator:       a,
+		logger:          l,
+		summary:         summary,
+		description:     description,
+		recoveryComment: recoveryComment,
+		issues:          make(map[eventKey]string),
+		commented:       make(map[string]bool),
+	}, nil
+}
+
+func parseTemplate(name, tmpl, defaultTmpl string) (*text.Template, error) {
+	if tmpl == "" {
+		tmpl = defaultTmpl
+	}
+	t, err := text.New(name).Parse(tmpl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s template", name)
+	}
+	return t, nil
+}
+
+func render(t *text.Template, td alert.TemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, td); err != nil {
+		return "", alert.Permanent(errors.Wrapf(err, "failed to execute %s template", t.Name()))
+	}
+	return buf.String(), nil
+}
+
+func (h *handler) Handle(event alert.Event) error {
+	c := h.s.config()
+	project := h.c.Project
+	if project == "" {
+		project = c.Project
+	}
+	annotation := IssueAnnotation(project)
+	k := eventKey{topic: event.Topic, id: event.State.ID}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	issue, ok := h.issues[k]
+	if !ok {
+		issue = event.State.Annotations[annotation]
+	}
+
+	if event.State.Level == alert.OK {
+		if issue == "" {
+			// No issue was opened for the event.
+			return nil
+		}
+		if err := h.recover(c, issue, event.TemplateData()); err != nil {
+			return errors.Wrapf(err, "failed to recover Jira issue %s", issue)
+		}
+		delete(h.issues, k)
+		return nil
+	}
+
+	if issue != "" {
+		// The event already has an open issue.
+		return nil
+	}
+	key, err := h.open(project, event.TemplateData())
+	if err != nil {
+		return errors.Wrap(err, "failed to create Jira issue")
+	}
+	h.issues[k] = key
+	if h.annotator != nil {
+		if err := h.annotator.AnnotateEvent(event.Topic, event.State.ID, annotation, key); err != nil {
+			return fmt.Errorf("failed to store Jira issue %s in the state of event %s: %v", key, event.State.ID, err)
+		}
+	}
+	return nil
+}
+
+func (h *handler) open(project string, td alert.TemplateData) (string, error) {
+	summary, err := render(h.summary, td)
+	if err != nil {
+		return "", err
+	}
+	// Summaries are limited to a single line.
+	summary = strings.Join(strings.Fields(summary), " ")
+	if r := []rune(summary); len(r) > maxSummaryLen {
+		summary = string(r[:maxSummaryLen])
+	}
+	description, err := render(h.description, td)
+	if err != nil {
+		return "", err
+	}
+	return h.s.CreateIssue(project, h.c.IssueType, summary, description, h.c.Labels)
+}
+
+func (h *handler) recover(c Config, issue string, td alert.TemplateData) error {
+	if !h.commented[issue] {
+		comment, err := render(h.recoveryComment, td)
+		if err != nil {
+			return err
+		}
+		if err := h.s.AddComment(issue, comment); err != nil {
+			return err
+		}
+		h.commented[issue] = true
+	}
+	transition := h.c.RecoveryTransition
+	if transition == "" {
+		transition = c.RecoveryTransition
+	}
+	if transition != "" {
+		if err := h.s.TransitionIssue(issue, transition); err != nil {
+			return err
+		}
+	}
+	delete(h.commented, issue)
+	return nil
+}
@@ -0,0 +1,185 @@
+package jira_test
+
+import (
+	"log"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/jira"
+	"github.com/influxdata/kapacitor/services/jira/jiratest"
+)
+
+type annotation struct {
+	topic, event, key, value string
+}
+
+type annotator struct {
+	annotations []annotation
+}
+
+func (a *annotator) AnnotateEvent(topic, event, key, value string) error {
+	a.annotations = append(a.annotations, annotation{topic, event, key, value})
+	return nil
+}
+
+func TestHandler_Dedup(t *testing.T) {
+	ts := jiratest.NewServer()
+	defer ts.Close()
+
+	c := jira.NewConfig()
+	c.Enabled = true
+	c.URL = ts.URL
+	c.Username = "bob"
+	c.Token = "secret"
+	c.Project = "OPS"
+	c.RecoveryTransition = "31"
+	s := jira.NewService(c, log.New(os.Stderr, "[jira] ", log.LstdFlags))
+
+	a := new(annotator)
+	hc := jira.HandlerConfig{
+		Summary: "{{ .ID }} is {{ .Level }}",
+	}
+	h, err := s.Handler(hc, a, log.New(os.Stderr, "[jira] ", log.LstdFlags))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := func(level alert.Level, annotations map[string]string) alert.Event {
+		return alert.Event{
+			Topic: "topic",
+			State: alert.EventState{
+				ID:          "id",
+				Message:     "message",
+				Time:        time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+				Level:       level,
+				Annotations: annotations,
+			},
+		}
+	}
+	// The second event is handled before the key of the issue is stored in the state of the event.
+	for _, e := range []alert.Event{
+		event(alert.Warning, nil),
+		event(alert.Critical, nil),
+		event(alert.OK, nil),
+	} {
+		if err := h.Handle(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A new handler, i.e. after a restart, finds the issue in the state of the event.
+	h, err = s.Handler(hc, a, log.New(os.Stderr, "[jira] ", log.LstdFlags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	annotations := map[string]string{jira.IssueAnnotation("OPS"): "OPS-7"}
+	for _, e := range []alert.Event{
+		event(alert.Warning, annotations),
+		event(alert.OK, annotations),
+	} {
+		if err := h.Handle(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expAnnotations := []annotation{{"topic", "id", "jira-issue:OPS", "OPS-1"}}
+	if got, exp := a.annotations, expAnnotations; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected annotations:\ngot\n%+v\nexp\n%+v", got, exp)
+	}
+	expRequests := []jiratest.Request{
+		{
+			Path:     "/rest/api/2/issue",
+			Username: "bob",
+			Password: "secret",
+			Issue: &jiratest.Issue{
+				Project:     "OPS",
+				IssueType:   "Task",
+				Summary:     "id is WARNING",
+				Description: "message\n\nID: id\nLevel: WARNING\nTime: 2017-01-01 00:00:00 +0000 UTC",
+			},
+		},
+		{
+			Path:     "/rest/api/2/issue/OPS-1/comment",
+			Username: "bob",
+			Password: "secret",
+			Comment:  "id recovered: message",
+		},
+		{
+			Path:       "/rest/api/2/issue/OPS-1/transitions",
+			Username:   "bob",
+			Password:   "secret",
+			Transition: "31",
+		},
+		{
+			Path:     "/rest/api/2/issue/OPS-7/comment",
+			Username: "bob",
+			Password: "secret",
+			Comment:  "id recovered: message",
+		},
+		{
+			Path:       "/rest/api/2/issue/OPS-7/transitions",
+			Username:   "bob",
+			Password:   "secret",
+			Transition: "31",
+		},
+	}
+	if got, exp := ts.Requests(), expRequests; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected requests:\ngot\n%+v\nexp\n%+v", got, exp)
+	}
+}
+
+func TestHandler_RecoverRetry(t *testing.T) {
+	ts := jiratest.NewServer()
+	defer ts.Close()
+
+	c := jira.NewConfig()
+	c.Enabled = true
+	c.URL = ts.URL
+	c.Project = "OPS"
+	c.RecoveryTransition = "31"
+	s := jira.NewService(c, log.New(os.Stderr, "[jira] ", log.LstdFlags))
+
+	h, err := s.Handler(jira.HandlerConfig{}, nil, log.New(os.Stderr, "[jira] ", log.LstdFlags))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := func(level alert.Level) alert.Event {
+		return alert.Event{
+			Topic: "topic",
+			State: alert.EventState{
+				ID:      "id",
+				Message: "message",
+				Time:    time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+				Level:   level,
+			},
+		}
+	}
+	if err := h.Handle(event(alert.Critical)); err != nil {
+		t.Fatal(err)
+	}
+	ts.TransitionErrors = 1
+	if err := h.Handle(event(alert.OK)); err == nil {
+		t.Fatal("expected error when the transition fails")
+	}
+	// Retrying the recovery only transitions the issue.
+	if err := h.Handle(event(alert.OK)); err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, r := range ts.Requests() {
+		paths = append(paths, r.Path)
+	}
+	expPaths := []string{
+		"/rest/api/2/issue",
+		"/rest/api/2/issue/OPS-1/comment",
+		"/rest/api/2/issue/OPS-1/transitions",
+	}
+	if !reflect.DeepEqual(paths, expPaths) {
+		t.Errorf("unexpected requests:\ngot\n%v\nexp\n%v", paths, expPaths)
+	}
+}
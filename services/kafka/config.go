@@ -0,0 +1,82 @@
+package kafka
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultTimeout  = 10 * time.Second
+	DefaultClientID = "kapacitor"
+)
+
+type Config struct {
+	// Whether the Kafka cluster is enabled.
+	Enabled bool `toml:"enabled" override:"enabled"`
+	// ID is a unique identifier for the Kafka cluster.
+	ID string `toml:"id" override:"id"`
+	// Brokers used to discover the cluster, as a list of host:port addresses.
+	Brokers []string `toml:"brokers" override:"brokers"`
+	// Timeout of requests to the brokers.
+	Timeout toml.Duration `toml:"timeout" override:"timeout"`
+	// ClientID sent to the brokers.
+	ClientID string `toml:"client-id" override:"client-id"`
+
+	// Connect to the brokers using TLS.
+	UseSSL bool `toml:"use-ssl" override:"use-ssl"`
+	// Path to CA file
+	SSLCA string `toml:"ssl-ca" override:"ssl-ca"`
+	// Path to host cert file
+	SSLCert string `toml:"ssl-cert" override:"ssl-cert"`
+	// Path to cert key file
+	SSLKey string `toml:"ssl-key" override:"ssl-key"`
+	// Use SSL but skip chain & host verification
+	InsecureSkipVerify bool `toml:"insecure-skip-verify" override:"insecure-skip-verify"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Timeout:  toml.Duration(DefaultTimeout),
+		ClientID: DefaultClientID,
+	}
+}
+
+func (c Config) Validate() error {
+	if c.ID == "" {
+		return errors.New("must specify an id for the kafka cluster")
+	}
+	if c.Enabled && len(c.Brokers) == 0 {
+		return errors.New("must specify at least one broker")
+	}
+	for _, b := range c.Brokers {
+		if _, _, err := net.SplitHostPort(b); err != nil {
+			return errors.Wrapf(err, "invalid broker address %q", b)
+		}
+	}
+	if c.Timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	return nil
+}
+
+type Configs []Config
+
+// Validate calls config.Validate for each element in Configs
+// and checks that the IDs of the clusters are unique.
+func (cs Configs) Validate() error {
+	ids := make(map[string]bool, len(cs))
+	for _, c := range cs {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+		if ids[c.ID] {
+			return fmt.Errorf("duplicate kafka cluster id %q", c.ID)
+		}
+		ids[c.ID] = true
+	}
+	return nil
+}
@@ -0,0 +1,571 @@
+// Package kafkatest provides a stand-in Kafka broker for tests.
+// The broker handles the ApiVersions, metadata and produce requests used by the kafka service and records the produced messages.
+package kafkatest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// API keys of the handled requests
+const (
+	produceKey     = 0
+	metadataKey    = 3
+	apiVersionsKey = 18
+)
+
+// errNotLeader is the error code of partitions that the broker is not the leader of.
+const errNotLeader = 6
+
+// versionRange is a range of versions of a request supported by the broker.
+type versionRange struct {
+	min, max int16
+}
+
+// versions are the versions of the requests advertised by the broker.
+// The first versions of the produce and metadata requests were removed in Kafka 4.0.
+var versions = map[int16]versionRange{
+	produceKey:     {min: 3, max: 11},
+	metadataKey:    {min: 4, max: 12},
+	apiVersionsKey: {min: 0, max: 4},
+}
+
+// handledVersions are the versions of the requests the broker can decode.
+// Requests with other versions fail, even if they are advertised.
+var handledVersions = map[int16][]int16{
+	produceKey:     {0, 3},
+	metadataKey:    {0, 4},
+	apiVersionsKey: {0},
+}
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+type Server struct {
+	// Addr of the broker
+	Addr string
+	// Number of partitions of each topic
+	Partitions int
+	// Legacy makes the broker behave like a broker before Kafka 0.10,
+	// which closes the connection on ApiVersions requests and only supports the first versions of the requests.
+	Legacy bool
+	// Failures are the number of produce requests of each partition that fail,
+	// as if the broker was not the leader of the partition.
+	Failures map[int32]int
+
+	l      net.Listener
+	wg     sync.WaitGroup
+	closed bool
+
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	messages []Message
+	offsets  map[string]int64
+	err      error
+}
+
+// Message is a message produced to the broker.
+type Message struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       string
+	Value     string
+}
+
+func NewServer() (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		Addr:       l.Addr().String(),
+		Partitions: 1,
+		l:          l,
+		conns:      make(map[net.Conn]bool),
+		offsets:    make(map[string]int64),
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run()
+	}()
+	return s, nil
+}
+
+// Messages returns the produced messages and the first error handling requests.
+func (s *Server) Messages() ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messages, s.err
+}
+
+func (s *Server) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.l.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) run() {
+	for {
+		conn, err := s.l.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = true
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			if err := s.serve(conn); err != nil && err != io.EOF {
+				s.setErr(err)
+			}
+		}()
+	}
+}
+
+func (s *Server) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil && !s.closed {
+		s.err = err
+	}
+}
+
+func (s *Server) serve(conn net.Conn) error {
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return err
+		}
+		b := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(conn, b); err != nil {
+			return err
+		}
+		r := &reader{b: b}
+		apiKey := r.int16()
+		version := r.int16()
+		correlationID := r.int32()
+		// Client ID
+		r.string()
+
+		s.mu.Lock()
+		legacy := s.Legacy
+		s.mu.Unlock()
+		if legacy {
+			if apiKey == apiVersionsKey {
+				// Unknown requests close the connection
+				return nil
+			}
+			if version != 0 {
+				return fmt.Errorf("unsupported version %d of request %d", version, apiKey)
+			}
+		} else if v, ok := versions[apiKey]; !ok || version < v.min || version > v.max {
+			return fmt.Errorf("unsupported version %d of request %d", version, apiKey)
+		}
+		if !handles(apiKey, version) {
+			return fmt.Errorf("version %d of request %d is not handled", version, apiKey)
+		}
+
+		var w writer
+		w.int32(correlationID)
+		switch apiKey {
+		case apiVersionsKey:
+			s.apiVersions(&w)
+		case metadataKey:
+			s.metadata(r, &w, version)
+		case produceKey:
+			s.produce(r, &w, version)
+		default:
+			return errors.New("unsupported request " + strconv.Itoa(int(apiKey)))
+		}
+		if r.err != nil {
+			return r.err
+		}
+		var resp writer
+		resp.bytes(w.Bytes())
+		if _, err := conn.Write(resp.Bytes()); err != nil {
+			return err
+		}
+	}
+}
+
+func handles(apiKey, version int16) bool {
+	for _, v := range handledVersions[apiKey] {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) apiVersions(w *writer) {
+	// Error code
+	w.int16(0)
+	w.int32(int32(len(versions)))
+	for key, v := range versions {
+		w.int16(key)
+		w.int16(v.min)
+		w.int16(v.max)
+	}
+}
+
+func (s *Server) metadata(r *reader, w *writer, version int16) {
+	host, portStr, _ := net.SplitHostPort(s.Addr)
+	port, _ := strconv.Atoi(portStr)
+	if version >= 4 {
+		// Throttle time
+		w.int32(0)
+	}
+	// The broker is the only node of the cluster
+	w.int32(1)
+	w.int32(0)
+	w.string(host)
+	w.int32(int32(port))
+	if version >= 4 {
+		// Rack, cluster ID and controller ID
+		w.int16(-1)
+		w.int16(-1)
+		w.int32(0)
+	}
+
+	n := r.int32()
+	topics := make([]string, 0, n)
+	for i := int32(0); i < n && r.err == nil; i++ {
+		topics = append(topics, r.string())
+	}
+	if version >= 4 {
+		// Allow auto topic creation
+		r.int8()
+	}
+	if r.err == nil && len(r.b) != 0 {
+		r.err = errMalformedRequest
+	}
+	w.int32(int32(len(topics)))
+	for _, topic := range topics {
+		// Error code
+		w.int16(0)
+		w.string(topic)
+		if version >= 4 {
+			// Is internal
+			w.int8(0)
+		}
+		w.int32(int32(s.Partitions))
+		for p := 0; p < s.Partitions; p++ {
+			// Error code, partition and leader
+			w.int16(0)
+			w.int32(int32(p))
+			w.int32(0)
+			// Replicas and in-sync replicas
+			w.int32(1)
+			w.int32(0)
+			w.int32(1)
+			w.int32(0)
+		}
+	}
+}
+
+func (s *Server) produce(r *reader, w *writer, version int16) {
+	if version >= 3 {
+		// Transactional ID
+		r.string()
+	}
+	// Acks and timeout
+	r.int16()
+	r.int32()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := r.int32()
+	w.int32(n)
+	for i := int32(0); i < n; i++ {
+		topic := r.string()
+		w.string(topic)
+		p := r.int32()
+		w.int32(p)
+		for j := int32(0); j < p; j++ {
+			partition := r.int32()
+			set := &reader{b: r.bytes()}
+			key := topic + "/" + strconv.Itoa(int(partition))
+			offset := s.offsets[key]
+			var kvs [][2][]byte
+			if version >= 3 {
+				kvs = readRecordBatch(set)
+			} else {
+				kvs = readMessageSet(set)
+			}
+			if s.Failures[partition] > 0 {
+				s.Failures[partition]--
+				w.int32(partition)
+				w.int16(errNotLeader)
+				w.int64(-1)
+				if version >= 3 {
+					// Log append time
+					w.int64(-1)
+				}
+				continue
+			}
+			for _, kv := range kvs {
+				s.messages = append(s.messages, Message{
+					Topic:     topic,
+					Partition: partition,
+					Offset:    s.offsets[key],
+					Key:       string(kv[0]),
+					Value:     string(kv[1]),
+				})
+				s.offsets[key]++
+			}
+			if set.err != nil {
+				r.err = set.err
+			}
+			w.int32(partition)
+			w.int16(0)
+			w.int64(offset)
+			if version >= 3 {
+				// Log append time
+				w.int64(-1)
+			}
+		}
+	}
+	if version >= 3 {
+		// Throttle time
+		w.int32(0)
+	}
+}
+
+// readMessageSet reads the keys and values of a message set with messages in the first format.
+func readMessageSet(set *reader) [][2][]byte {
+	var kvs [][2][]byte
+	for len(set.b) > 0 && set.err == nil {
+		// Offset and size
+		set.int64()
+		size := set.int32()
+		msg := &reader{b: set.next(int(size))}
+		crc := uint32(msg.int32())
+		if msg.err == nil && crc != crc32.ChecksumIEEE(msg.b) {
+			set.err = errors.New("invalid message checksum")
+			break
+		}
+		if magic := msg.int8(); magic != 0 {
+			set.err = fmt.Errorf("unexpected magic byte %d", magic)
+			break
+		}
+		// Attributes
+		msg.int8()
+		kvs = append(kvs, [2][]byte{msg.bytes(), msg.bytes()})
+		if msg.err != nil {
+			set.err = msg.err
+		}
+	}
+	return kvs
+}
+
+// readRecordBatch reads the keys and values of the records of a record batch.
+func readRecordBatch(set *reader) [][2][]byte {
+	// Base offset
+	set.int64()
+	length := set.int32()
+	batch := &reader{b: set.next(int(length))}
+	if set.err == nil && len(set.b) != 0 {
+		set.err = errors.New("unexpected data after record batch")
+		return nil
+	}
+	// Partition leader epoch
+	batch.int32()
+	if magic := batch.int8(); batch.err == nil && magic != 2 {
+		set.err = fmt.Errorf("unexpected magic byte %d", magic)
+		return nil
+	}
+	crc := uint32(batch.int32())
+	if batch.err == nil && crc != crc32.Checksum(batch.b, crc32c) {
+		set.err = errors.New("invalid record batch checksum")
+		return nil
+	}
+	// Attributes, last offset delta, first and max timestamps, producer ID, epoch and base sequence
+	batch.int16()
+	lastOffsetDelta := batch.int32()
+	batch.int64()
+	batch.int64()
+	batch.int64()
+	batch.int16()
+	batch.int32()
+	n := batch.int32()
+	if batch.err == nil && n != lastOffsetDelta+1 {
+		set.err = errors.New("unexpected number of records")
+		return nil
+	}
+	var kvs [][2][]byte
+	for i := int32(0); i < n && batch.err == nil; i++ {
+		record := &reader{b: batch.next(int(batch.varint()))}
+		// Attributes and timestamp delta
+		record.int8()
+		record.varint()
+		if delta := record.varint(); record.err == nil && delta != int64(i) {
+			set.err = fmt.Errorf("unexpected offset delta %d of record %d", delta, i)
+			return nil
+		}
+		kv := [2][]byte{record.varbytes(), record.varbytes()}
+		if headers := record.varint(); record.err == nil && (headers != 0 || len(record.b) != 0) {
+			record.err = errMalformedRequest
+		}
+		if record.err != nil {
+			set.err = record.err
+			return nil
+		}
+		kvs = append(kvs, kv)
+	}
+	if batch.err == nil && len(batch.b) != 0 {
+		batch.err = errMalformedRequest
+	}
+	if batch.err != nil {
+		set.err = batch.err
+	}
+	return kvs
+}
+
+var errMalformedRequest = errors.New("malformed request")
+
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.b) < n {
+		r.err = errMalformedRequest
+		return nil
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *reader) int8() int8 {
+	b := r.next(1)
+	if b == nil {
+		return 0
+	}
+	return int8(b[0])
+}
+
+func (r *reader) int16() int16 {
+	b := r.next(2)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (r *reader) int32() int32 {
+	b := r.next(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (r *reader) int64() int64 {
+	b := r.next(8)
+	if b == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+func (r *reader) string() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.next(int(n)))
+}
+
+func (r *reader) bytes() []byte {
+	n := r.int32()
+	if n < 0 {
+		return nil
+	}
+	return r.next(int(n))
+}
+
+func (r *reader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.b)
+	if n <= 0 {
+		r.err = errMalformedRequest
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) varbytes() []byte {
+	n := r.varint()
+	if n < 0 {
+		return nil
+	}
+	return r.next(int(n))
+}
+
+type writer struct {
+	bytes.Buffer
+}
+
+func (w *writer) int8(v int8) {
+	w.WriteByte(byte(v))
+}
+
+func (w *writer) int16(v int16) {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], uint16(v))
+	w.Write(b[:])
+}
+
+func (w *writer) int32(v int32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	w.Write(b[:])
+}
+
+func (w *writer) int64(v int64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	w.Write(b[:])
+}
+
+func (w *writer) string(s string) {
+	w.int16(int16(len(s)))
+	w.WriteString(s)
+}
+
+func (w *writer) bytes(b []byte) {
+	w.int32(int32(len(b)))
+	w.Write(b)
+}
@@ -0,0 +1,493 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// The subset of the Kafka protocol needed to produce messages.
+// The versions of the requests are negotiated with each broker using an ApiVersions request.
+// Brokers before Kafka 0.10 do not support ApiVersions requests and are sent the first versions of the requests,
+// which were removed in Kafka 4.0, newer brokers are sent the record batch format introduced in Kafka 0.11.
+// See https://kafka.apache.org/protocol for details of the protocol.
+
+// API keys of the requests
+const (
+	produceKey     = 0
+	metadataKey    = 3
+	apiVersionsKey = 18
+)
+
+// Error codes of the responses
+const (
+	errNone               = 0
+	errLeaderNotAvailable = 5
+	errNotLeader          = 6
+)
+
+// supportedVersions are the versions of the requests that can be encoded, in order of preference.
+var supportedVersions = map[int16][]int16{
+	produceKey:  {3, 0},
+	metadataKey: {4, 0},
+}
+
+// legacyVersions are the versions of the requests sent to brokers that do not support ApiVersions requests.
+var legacyVersions = map[int16]int16{
+	produceKey:  0,
+	metadataKey: 0,
+}
+
+// crc32c is the checksum of record batches.
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// maxResponseSize limits the size of responses read from brokers.
+const maxResponseSize = 64 * 1024 * 1024
+
+var errMalformedResponse = errors.New("malformed response")
+
+// KafkaError is an error code returned by a broker.
+type KafkaError int16
+
+func (e KafkaError) Error() string {
+	switch e {
+	case errLeaderNotAvailable:
+		return "kafka: leader not available"
+	case errNotLeader:
+		return "kafka: not leader for partition"
+	}
+	return fmt.Sprintf("kafka: error code %d", int16(e))
+}
+
+// encoder encodes the primitive types of the protocol.
+type encoder struct {
+	bytes.Buffer
+}
+
+func (e *encoder) int8(v int8) {
+	e.WriteByte(byte(v))
+}
+
+func (e *encoder) int16(v int16) {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], uint16(v))
+	e.Write(b[:])
+}
+
+func (e *encoder) int32(v int32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	e.Write(b[:])
+}
+
+func (e *encoder) int64(v int64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	e.Write(b[:])
+}
+
+func (e *encoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.WriteString(s)
+}
+
+// varint encodes a zig-zag encoded variable length integer.
+func (e *encoder) varint(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(b[:], v)
+	e.Write(b[:n])
+}
+
+// varbytes encodes bytes with a variable length, nil is encoded as null.
+func (e *encoder) varbytes(b []byte) {
+	if b == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(b)))
+	e.Write(b)
+}
+
+// bytes encodes the bytes, nil is encoded as null.
+func (e *encoder) bytes(b []byte) {
+	if b == nil {
+		e.int32(-1)
+		return
+	}
+	e.int32(int32(len(b)))
+	e.Write(b)
+}
+
+// decoder decodes the primitive types of the protocol.
+// The first error is retained and all following reads return zero values.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.b) < n {
+		d.err = errMalformedResponse
+		return nil
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b
+}
+
+func (d *decoder) int8() int8 {
+	b := d.next(1)
+	if b == nil {
+		return 0
+	}
+	return int8(b[0])
+}
+
+func (d *decoder) int16() int16 {
+	b := d.next(2)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (d *decoder) int32() int32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (d *decoder) int64() int64 {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.next(int(n)))
+}
+
+// arrayLen decodes the length of an array, null arrays have length zero.
+func (d *decoder) arrayLen() int {
+	n := int(d.int32())
+	if n < 0 {
+		return 0
+	}
+	// Each element has at least one byte.
+	if n > len(d.b) {
+		d.err = errMalformedResponse
+		return 0
+	}
+	return n
+}
+
+// writeRequest writes a request with its header to w.
+func writeRequest(w io.Writer, apiKey, version int16, correlationID int32, clientID string, body []byte) error {
+	var e encoder
+	// Size is written after the header is encoded
+	e.int32(0)
+	e.int16(apiKey)
+	e.int16(version)
+	e.int32(correlationID)
+	e.string(clientID)
+	e.Write(body)
+	b := e.Bytes()
+	binary.BigEndian.PutUint32(b, uint32(len(b)-4))
+	_, err := w.Write(b)
+	return err
+}
+
+// readResponse reads the response to the request with the correlation ID from r.
+func readResponse(r io.Reader, correlationID int32) (*decoder, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := int32(binary.BigEndian.Uint32(size[:]))
+	if n < 4 || n > maxResponseSize {
+		return nil, errMalformedResponse
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	d := &decoder{b: b}
+	if id := d.int32(); id != correlationID {
+		return nil, fmt.Errorf("unexpected correlation ID %d, expected %d", id, correlationID)
+	}
+	return d, nil
+}
+
+// versionRange is the range of versions of a request supported by a broker.
+type versionRange struct {
+	Min int16
+	Max int16
+}
+
+// decodeAPIVersionsResponse decodes the first version of the ApiVersions response.
+func decodeAPIVersionsResponse(d *decoder) (map[int16]versionRange, error) {
+	if code := d.int16(); d.err == nil && code != errNone {
+		return nil, KafkaError(code)
+	}
+	versions := make(map[int16]versionRange)
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		key := d.int16()
+		versions[key] = versionRange{
+			Min: d.int16(),
+			Max: d.int16(),
+		}
+	}
+	return versions, d.err
+}
+
+// selectVersions selects the preferred supported version of each request within the versions supported by the broker.
+func selectVersions(broker map[int16]versionRange) (map[int16]int16, error) {
+	selected := make(map[int16]int16, len(supportedVersions))
+	for key, versions := range supportedVersions {
+		r, ok := broker[key]
+		if !ok {
+			return nil, fmt.Errorf("broker does not support requests with API key %d", key)
+		}
+		found := false
+		for _, v := range versions {
+			if r.Min <= v && v <= r.Max {
+				selected[key] = v
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("broker supports versions %d to %d of requests with API key %d, supported versions are %v", r.Min, r.Max, key, versions)
+		}
+	}
+	return selected, nil
+}
+
+type brokerMetadata struct {
+	NodeID int32
+	Host   string
+	Port   int32
+}
+
+type partitionMetadata struct {
+	Error  int16
+	ID     int32
+	Leader int32
+}
+
+type topicMetadata struct {
+	Error      int16
+	Name       string
+	Partitions []partitionMetadata
+}
+
+type metadataResponse struct {
+	Brokers []brokerMetadata
+	Topics  []topicMetadata
+}
+
+func encodeMetadataRequest(version int16, topics []string) []byte {
+	var e encoder
+	e.int32(int32(len(topics)))
+	for _, t := range topics {
+		e.string(t)
+	}
+	if version >= 4 {
+		// Do not create topics automatically
+		e.int8(0)
+	}
+	return e.Bytes()
+}
+
+func decodeMetadataResponse(d *decoder, version int16) (metadataResponse, error) {
+	var m metadataResponse
+	if version >= 3 {
+		// Throttle time
+		d.int32()
+	}
+	n := d.arrayLen()
+	for i := 0; i < n; i++ {
+		m.Brokers = append(m.Brokers, brokerMetadata{
+			NodeID: d.int32(),
+			Host:   d.string(),
+			Port:   d.int32(),
+		})
+		if version >= 1 {
+			// Rack
+			d.string()
+		}
+	}
+	if version >= 2 {
+		// Cluster ID
+		d.string()
+	}
+	if version >= 1 {
+		// Controller ID
+		d.int32()
+	}
+	n = d.arrayLen()
+	for i := 0; i < n; i++ {
+		t := topicMetadata{
+			Error: d.int16(),
+			Name:  d.string(),
+		}
+		if version >= 1 {
+			// Is internal
+			d.int8()
+		}
+		p := d.arrayLen()
+		for j := 0; j < p; j++ {
+			t.Partitions = append(t.Partitions, partitionMetadata{
+				Error:  d.int16(),
+				ID:     d.int32(),
+				Leader: d.int32(),
+			})
+			// Replicas and in-sync replicas are not used
+			for k, r := 0, d.arrayLen(); k < r; k++ {
+				d.int32()
+			}
+			for k, r := 0, d.arrayLen(); k < r; k++ {
+				d.int32()
+			}
+		}
+		m.Topics = append(m.Topics, t)
+	}
+	return m, d.err
+}
+
+// encodeMessageSet encodes the messages as a message set with messages in the first format.
+func encodeMessageSet(msgs []Message) []byte {
+	var set, msg encoder
+	for _, m := range msgs {
+		msg.Reset()
+		// Magic byte
+		msg.int8(0)
+		// Attributes, no compression
+		msg.int8(0)
+		msg.bytes(m.Key)
+		msg.bytes(m.Value)
+
+		// Offset is assigned by the broker
+		set.int64(0)
+		set.int32(int32(4 + msg.Len()))
+		set.int32(int32(crc32.ChecksumIEEE(msg.Bytes())))
+		set.Write(msg.Bytes())
+	}
+	return set.Bytes()
+}
+
+// encodeRecordBatch encodes the messages as a record batch, created at the timestamp.
+func encodeRecordBatch(msgs []Message, timestamp time.Time) []byte {
+	var records, record encoder
+	for i, m := range msgs {
+		record.Reset()
+		// Attributes
+		record.int8(0)
+		// Timestamp delta
+		record.varint(0)
+		// Offset delta
+		record.varint(int64(i))
+		record.varbytes(m.Key)
+		record.varbytes(m.Value)
+		// Headers
+		record.varint(0)
+
+		records.varint(int64(record.Len()))
+		records.Write(record.Bytes())
+	}
+
+	ts := timestamp.UnixNano() / int64(time.Millisecond)
+	// The checksum covers the batch from the attributes to the end.
+	var body encoder
+	// Attributes, no compression and create time
+	body.int16(0)
+	// Last offset delta
+	body.int32(int32(len(msgs) - 1))
+	// First and max timestamps
+	body.int64(ts)
+	body.int64(ts)
+	// Producer ID, epoch and base sequence, no idempotence
+	body.int64(-1)
+	body.int16(-1)
+	body.int32(-1)
+	body.int32(int32(len(msgs)))
+	body.Write(records.Bytes())
+
+	var batch encoder
+	// Base offset is assigned by the broker
+	batch.int64(0)
+	// Length of the batch after the length, i.e. leader epoch, magic byte, checksum and body
+	batch.int32(int32(4 + 1 + 4 + body.Len()))
+	// Partition leader epoch
+	batch.int32(-1)
+	// Magic byte
+	batch.int8(2)
+	batch.int32(int32(crc32.Checksum(body.Bytes(), crc32c)))
+	batch.Write(body.Bytes())
+	return batch.Bytes()
+}
+
+// encodeProduceRequest encodes a request producing the messages to the partitions of the topic.
+// Messages are encoded as a record batch created at the timestamp from version 3, and as a message set before.
+func encodeProduceRequest(version, acks int16, timeoutMs int32, topic string, partitions map[int32][]Message, timestamp time.Time) []byte {
+	var e encoder
+	if version >= 3 {
+		// Transactional ID, null
+		e.int16(-1)
+	}
+	e.int16(acks)
+	e.int32(timeoutMs)
+	// Messages of a single topic
+	e.int32(1)
+	e.string(topic)
+	e.int32(int32(len(partitions)))
+	for p, msgs := range partitions {
+		e.int32(p)
+		if version >= 3 {
+			e.bytes(encodeRecordBatch(msgs, timestamp))
+		} else {
+			e.bytes(encodeMessageSet(msgs))
+		}
+	}
+	return e.Bytes()
+}
+
+// decodeProduceResponse returns the errors of the partitions in the response that failed.
+func decodeProduceResponse(d *decoder, version int16) (map[int32]KafkaError, error) {
+	var failed map[int32]KafkaError
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		d.string()
+		for j, p := 0, d.arrayLen(); j < p; j++ {
+			partition := d.int32()
+			code := d.int16()
+			// Offset
+			d.int64()
+			if version >= 2 {
+				// Log append time
+				d.int64()
+			}
+			if d.err == nil && code != errNone {
+				if failed == nil {
+					failed = make(map[int32]KafkaError)
+				}
+				failed[partition] = KafkaError(code)
+			}
+		}
+	}
+	return failed, d.err
+}
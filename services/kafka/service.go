@@ -0,0 +1,206 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/pkg/errors"
+)
+
+type Service struct {
+	mu      sync.RWMutex
+	configs Configs
+	writers map[string]*writer
+
+	logger *log.Logger
+}
+
+func NewService(cs Configs, l *log.Logger) (*Service, error) {
+	writers, err := newWriters(cs)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		configs: cs,
+		writers: writers,
+		logger:  l,
+	}, nil
+}
+
+// newWriters creates writers for the enabled clusters.
+func newWriters(cs Configs) (map[string]*writer, error) {
+	writers := make(map[string]*writer, len(cs))
+	for _, c := range cs {
+		if !c.Enabled {
+			continue
+		}
+		w, err := newWriter(c)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid configuration of kafka cluster %q", c.ID)
+		}
+		writers[c.ID] = w
+	}
+	return writers, nil
+}
+
+func (s *Service) Open() error {
+	return nil
+}
+
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.writers {
+		w.Close()
+	}
+	return nil
+}
+
+func (s *Service) Update(newConfigs []interface{}) error {
+	cs := make(Configs, len(newConfigs))
+	for i, c := range newConfigs {
+		config, ok := c.(Config)
+		if !ok {
+			return fmt.Errorf("expected config object to be of type %T, got %T", config, c)
+		}
+		cs[i] = config
+	}
+	if err := cs.Validate(); err != nil {
+		return err
+	}
+	writers, err := newWriters(cs)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.writers {
+		w.Close()
+	}
+	s.configs = cs
+	s.writers = writers
+	return nil
+}
+
+// writer returns the writer of the cluster.
+// The cluster may be empty if exactly one cluster is configured.
+func (s *Service) writer(cluster string) (*writer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if cluster == "" {
+		if len(s.configs) == 0 {
+			return nil, errors.New("no kafka cluster configured")
+		}
+		if len(s.configs) != 1 {
+			return nil, errors.New("must specify a kafka cluster when more than one cluster is configured")
+		}
+		cluster = s.configs[0].ID
+	}
+	w, ok := s.writers[cluster]
+	if !ok {
+		for _, c := range s.configs {
+			if c.ID == cluster {
+				return nil, fmt.Errorf("kafka cluster %q is not enabled", cluster)
+			}
+		}
+		return nil, fmt.Errorf("unknown kafka cluster %q", cluster)
+	}
+	return w, nil
+}
+
+// WriteMessages produces the messages to the topic of the cluster.
+func (s *Service) WriteMessages(cluster, topic string, msgs ...Message) error {
+	if topic == "" {
+		return errors.New("missing kafka topic")
+	}
+	w, err := s.writer(cluster)
+	if err != nil {
+		return err
+	}
+	return w.WriteMessages(topic, msgs...)
+}
+
+// Clusters returns the IDs of the configured clusters.
+func (s *Service) Clusters() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, len(s.configs))
+	for i, c := range s.configs {
+		ids[i] = c.ID
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+type testOptions struct {
+	Cluster string `json:"cluster"`
+	Topic   string `json:"topic"`
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+func (s *Service) TestOptions() interface{} {
+	o := &testOptions{
+		Topic:   "kapacitor",
+		Message: "test kafka message",
+	}
+	if ids := s.Clusters(); len(ids) == 1 {
+		o.Cluster = ids[0]
+	}
+	return o
+}
+
+func (s *Service) Test(options interface{}) error {
+	o, ok := options.(*testOptions)
+	if !ok {
+		return fmt.Errorf("unexpected options type %T", options)
+	}
+	m := Message{Value: []byte(o.Message)}
+	if o.Key != "" {
+		m.Key = []byte(o.Key)
+	}
+	return s.WriteMessages(o.Cluster, o.Topic, m)
+}
+
+type HandlerConfig struct {
+	// ID of the Kafka cluster.
+	// May be empty if exactly one cluster is configured.
+	Cluster string `mapstructure:"cluster"`
+	// Kafka topic to which the events are written.
+	Topic string `mapstructure:"topic"`
+}
+
+type handler struct {
+	s      *Service
+	c      HandlerConfig
+	logger *log.Logger
+}
+
+func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
+	return &handler{
+		s:      s,
+		c:      c,
+		logger: l,
+	}
+}
+
+// Handle writes the alert data of the event as JSON, keyed by the ID of the event.
+func (h *handler) Handle(event alert.Event) error {
+	value, err := json.Marshal(event.AlertData())
+	if err != nil {
+		return alert.Permanent(fmt.Errorf("failed to marshal alert data json: %v", err))
+	}
+	m := Message{
+		Key:   []byte(event.State.ID),
+		Value: value,
+	}
+	if err := h.s.WriteMessages(h.c.Cluster, h.c.Topic, m); err != nil {
+		return fmt.Errorf("failed to write event to Kafka: %v", err)
+	}
+	return nil
+}
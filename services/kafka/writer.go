@@ -0,0 +1,363 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/kapacitor/tlsconfig"
+	"github.com/pkg/errors"
+)
+
+// Message is a message produced to a Kafka topic.
+type Message struct {
+	// Messages with the same key are produced to the same partition.
+	// Messages without a key are distributed over the partitions.
+	Key   []byte
+	Value []byte
+}
+
+// brokerConn is a connection to a broker with the versions of the requests negotiated with the broker.
+type brokerConn struct {
+	net.Conn
+	versions map[int16]int16
+}
+
+// writer produces messages to the topics of a Kafka cluster.
+// Requests are sent to the leaders of the partitions, which are discovered from the configured brokers.
+type writer struct {
+	c         Config
+	tlsConfig *tls.Config
+
+	mu            sync.Mutex
+	correlationID int32
+	// Addresses of the brokers by node ID
+	brokers map[int32]string
+	// Leaders of the partitions by topic, indexed by partition ID
+	leaders map[string][]int32
+	// Next partition of messages without a key by topic
+	next  map[string]int
+	conns map[string]*brokerConn
+}
+
+func newWriter(c Config) (*writer, error) {
+	w := &writer{
+		c:       c,
+		brokers: make(map[int32]string),
+		leaders: make(map[string][]int32),
+		next:    make(map[string]int),
+		conns:   make(map[string]*brokerConn),
+	}
+	if c.UseSSL {
+		tlsConfig, err := tlsconfig.Create(c.SSLCA, c.SSLCert, c.SSLKey, c.InsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		w.tlsConfig = tlsConfig
+	}
+	return w, nil
+}
+
+func (w *writer) timeout() time.Duration {
+	if w.c.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return time.Duration(w.c.Timeout)
+}
+
+// maxWriteAttempts is the number of times messages are produced to a partition before they fail.
+const maxWriteAttempts = 3
+
+// WriteError is returned when messages could not be produced to the topic.
+// The other messages were acknowledged, so only the failed messages need to be written again.
+type WriteError struct {
+	Topic string
+	// Failed are the messages that were not produced.
+	Failed []Message
+	Err    error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("failed to produce %d messages to topic %q: %v", len(e.Failed), e.Topic, e.Err)
+}
+
+// WriteMessages produces the messages to the topic.
+// The messages are acknowledged by the leaders of their partitions.
+// The messages of partitions that fail are produced again after the leaders are rediscovered,
+// the messages of the other partitions are not produced again.
+func (w *writer) WriteMessages(topic string, msgs ...Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	leaders, err := w.partitionLeaders(topic)
+	if err != nil {
+		return &WriteError{Topic: topic, Failed: msgs, Err: err}
+	}
+	partitions := make(map[int32][]Message)
+	for _, m := range msgs {
+		p := int32(w.partition(topic, m.Key, len(leaders)))
+		partitions[p] = append(partitions[p], m)
+	}
+
+	for attempt := 0; attempt < maxWriteAttempts && len(partitions) > 0; attempt++ {
+		partitions, err = w.produce(topic, partitions)
+	}
+	if len(partitions) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(partitions))
+	for p := range partitions {
+		ids = append(ids, int(p))
+	}
+	sort.Ints(ids)
+	var failed []Message
+	for _, p := range ids {
+		failed = append(failed, partitions[int32(p)]...)
+	}
+	return &WriteError{Topic: topic, Failed: failed, Err: err}
+}
+
+// produce sends the messages of the partitions to their leaders.
+// It returns the messages of the partitions that failed and the last error.
+// Caller must have lock.
+func (w *writer) produce(topic string, partitions map[int32][]Message) (map[int32][]Message, error) {
+	leaders, err := w.partitionLeaders(topic)
+	if err != nil {
+		return partitions, err
+	}
+
+	// Group messages by the leaders of their partitions
+	failed := make(map[int32][]Message)
+	requests := make(map[int32]map[int32][]Message)
+	for p, msgs := range partitions {
+		if int(p) >= len(leaders) {
+			failed[p] = msgs
+			err = fmt.Errorf("partition %d of topic %q does not exist", p, topic)
+			continue
+		}
+		leader := leaders[p]
+		if requests[leader] == nil {
+			requests[leader] = make(map[int32][]Message)
+		}
+		requests[leader][p] = msgs
+	}
+
+	timeout := w.timeout()
+	for leader, partitions := range requests {
+		addr, ok := w.brokers[leader]
+		if !ok {
+			for p, msgs := range partitions {
+				failed[p] = msgs
+			}
+			err = fmt.Errorf("unknown leader %d of topic %q", leader, topic)
+			continue
+		}
+		now := time.Now()
+		d, version, rerr := w.request(addr, produceKey, func(version int16) []byte {
+			return encodeProduceRequest(version, 1, int32(timeout/time.Millisecond), topic, partitions, now)
+		})
+		if rerr != nil {
+			for p, msgs := range partitions {
+				failed[p] = msgs
+			}
+			err = rerr
+			continue
+		}
+		errs, rerr := decodeProduceResponse(d, version)
+		if rerr != nil {
+			// The response cannot be trusted, none of its partitions are known to be acknowledged.
+			w.closeConn(addr)
+			for p, msgs := range partitions {
+				failed[p] = msgs
+			}
+			err = rerr
+			continue
+		}
+		for p, code := range errs {
+			if msgs, ok := partitions[p]; ok {
+				failed[p] = msgs
+				err = errors.Wrapf(code, "failed to produce messages to partition %d", p)
+			}
+		}
+	}
+	if len(failed) > 0 {
+		// Leaders may have changed, rediscover them for the next write.
+		delete(w.leaders, topic)
+	}
+	return failed, err
+}
+
+// partition selects the partition of a message.
+func (w *writer) partition(topic string, key []byte, n int) int {
+	if key == nil {
+		p := w.next[topic] % n
+		w.next[topic] = p + 1
+		return p
+	}
+	h := fnv.New32a()
+	h.Write(key)
+	return int(h.Sum32() % uint32(n))
+}
+
+// partitionLeaders returns the node IDs of the leaders of the partitions of the topic.
+// Caller must have lock.
+func (w *writer) partitionLeaders(topic string) ([]int32, error) {
+	if leaders, ok := w.leaders[topic]; ok {
+		return leaders, nil
+	}
+	var lastErr error
+	for _, addr := range w.c.Brokers {
+		d, version, err := w.request(addr, metadataKey, func(version int16) []byte {
+			return encodeMetadataRequest(version, []string{topic})
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		m, err := decodeMetadataResponse(d, version)
+		if err != nil {
+			lastErr = err
+			w.closeConn(addr)
+			continue
+		}
+		for _, b := range m.Brokers {
+			w.brokers[b.NodeID] = net.JoinHostPort(b.Host, strconv.Itoa(int(b.Port)))
+		}
+		for _, t := range m.Topics {
+			if t.Name != topic {
+				continue
+			}
+			if t.Error != errNone {
+				return nil, errors.Wrapf(KafkaError(t.Error), "failed to get metadata of topic %q", topic)
+			}
+			if len(t.Partitions) == 0 {
+				return nil, fmt.Errorf("topic %q has no partitions", topic)
+			}
+			leaders := make([]int32, len(t.Partitions))
+			for _, p := range t.Partitions {
+				if p.Error != errNone && p.Error != errLeaderNotAvailable {
+					return nil, errors.Wrapf(KafkaError(p.Error), "failed to get metadata of partition %d of topic %q", p.ID, topic)
+				}
+				if p.ID < 0 || int(p.ID) >= len(leaders) || p.Leader < 0 {
+					return nil, fmt.Errorf("partition %d of topic %q has no leader", p.ID, topic)
+				}
+				leaders[p.ID] = p.Leader
+			}
+			w.leaders[topic] = leaders
+			return leaders, nil
+		}
+		return nil, fmt.Errorf("no metadata for topic %q", topic)
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no brokers configured")
+	}
+	return nil, errors.Wrap(lastErr, "failed to get metadata from brokers")
+}
+
+// request sends a request to the broker and reads its response.
+// The body of the request is encoded with the version negotiated with the broker, which is returned with the response.
+// The connection to the broker is closed if the request fails.
+// Caller must have lock.
+func (w *writer) request(addr string, apiKey int16, encode func(version int16) []byte) (*decoder, int16, error) {
+	conn, err := w.conn(addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	version := conn.versions[apiKey]
+	d, err := w.roundTrip(conn, apiKey, version, encode(version))
+	if err != nil {
+		w.closeConn(addr)
+		return nil, 0, err
+	}
+	return d, version, nil
+}
+
+// roundTrip writes a request to the connection and reads its response.
+// Caller must have lock.
+func (w *writer) roundTrip(conn net.Conn, apiKey, version int16, body []byte) (*decoder, error) {
+	w.correlationID++
+	conn.SetDeadline(time.Now().Add(w.timeout()))
+	if err := writeRequest(conn, apiKey, version, w.correlationID, w.c.ClientID, body); err != nil {
+		return nil, err
+	}
+	return readResponse(conn, w.correlationID)
+}
+
+// conn returns the connection to the broker, connecting and negotiating the versions of the requests if necessary.
+// Brokers that close the connection on an ApiVersions request predate it and are sent the first versions of the requests.
+// Caller must have lock.
+func (w *writer) conn(addr string) (*brokerConn, error) {
+	if conn, ok := w.conns[addr]; ok {
+		return conn, nil
+	}
+	conn, err := w.dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	versions := legacyVersions
+	d, err := w.roundTrip(conn, apiVersionsKey, 0, nil)
+	if err == nil {
+		var broker map[int16]versionRange
+		broker, err = decodeAPIVersionsResponse(d)
+		if err == nil {
+			versions, err = selectVersions(broker)
+		}
+		if err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "failed to negotiate API versions with broker %s", addr)
+		}
+	} else {
+		conn.Close()
+		if conn, err = w.dial(addr); err != nil {
+			return nil, err
+		}
+	}
+	bc := &brokerConn{
+		Conn:     conn,
+		versions: versions,
+	}
+	w.conns[addr] = bc
+	return bc, nil
+}
+
+// dial connects to the broker.
+func (w *writer) dial(addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: w.timeout()}
+	var conn net.Conn
+	var err error
+	if w.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, w.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to broker %s", addr)
+	}
+	return conn, nil
+}
+
+// Caller must have lock.
+func (w *writer) closeConn(addr string) {
+	if conn, ok := w.conns[addr]; ok {
+		conn.Close()
+		delete(w.conns, addr)
+	}
+}
+
+// Close closes the connections to all brokers.
+func (w *writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for addr := range w.conns {
+		w.closeConn(addr)
+	}
+	return nil
+}
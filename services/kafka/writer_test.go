@@ -0,0 +1,161 @@
+package kafka_test
+
+import (
+	"log"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/kapacitor/services/kafka"
+	"github.com/influxdata/kapacitor/services/kafka/kafkatest"
+)
+
+func TestService_WriteMessages(t *testing.T) {
+	testCases := []struct {
+		name   string
+		legacy bool
+	}{
+		{
+			name: "negotiated versions",
+		},
+		{
+			name:   "broker without ApiVersions",
+			legacy: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts, err := kafkatest.NewServer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ts.Close()
+			ts.Partitions = 2
+			ts.Legacy = tc.legacy
+
+			c := kafka.NewConfig()
+			c.Enabled = true
+			c.ID = "default"
+			c.Brokers = []string{ts.Addr}
+			s, err := kafka.NewService(kafka.Configs{c}, log.New(os.Stderr, "[kafka] ", log.LstdFlags))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Open(); err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+
+			for _, msgs := range [][]kafka.Message{
+				{
+					{Key: []byte("a"), Value: []byte("1")},
+					{Key: []byte("a"), Value: []byte("2")},
+				},
+				{
+					{Value: []byte("3")},
+				},
+			} {
+				if err := s.WriteMessages("default", "topic", msgs...); err != nil {
+					t.Fatal(err)
+				}
+			}
+			ts.Close()
+
+			got, err := ts.Messages()
+			if err != nil {
+				t.Fatal(err)
+			}
+			values := make(map[string][]string)
+			for _, m := range got {
+				values[m.Key] = append(values[m.Key], m.Value)
+			}
+			if exp := map[string][]string{"a": {"1", "2"}, "": {"3"}}; !reflect.DeepEqual(values, exp) {
+				t.Errorf("unexpected messages:\ngot %v\nexp %v", values, exp)
+			}
+		})
+	}
+}
+
+func TestService_WriteMessages_FailedPartitions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		failures int
+		// Values of the messages that failed.
+		failed []string
+	}{
+		{
+			name:     "retried",
+			failures: 1,
+		},
+		{
+			name:     "failed",
+			failures: 10,
+			failed:   []string{"2", "4"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts, err := kafkatest.NewServer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ts.Close()
+			ts.Partitions = 2
+			ts.Failures = map[int32]int{1: tc.failures}
+
+			c := kafka.NewConfig()
+			c.Enabled = true
+			c.ID = "default"
+			c.Brokers = []string{ts.Addr}
+			s, err := kafka.NewService(kafka.Configs{c}, log.New(os.Stderr, "[kafka] ", log.LstdFlags))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Open(); err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+
+			// Messages without a key alternate between the partitions.
+			msgs := []kafka.Message{
+				{Value: []byte("1")},
+				{Value: []byte("2")},
+				{Value: []byte("3")},
+				{Value: []byte("4")},
+			}
+			err = s.WriteMessages("default", "topic", msgs...)
+			var failed []string
+			if err != nil {
+				werr, ok := err.(*kafka.WriteError)
+				if !ok {
+					t.Fatalf("unexpected error type %T: %v", err, err)
+				}
+				for _, m := range werr.Failed {
+					failed = append(failed, string(m.Value))
+				}
+			}
+			if !reflect.DeepEqual(failed, tc.failed) {
+				t.Errorf("unexpected failed messages got %v exp %v", failed, tc.failed)
+			}
+			ts.Close()
+
+			got, err := ts.Messages()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The messages of the partition that did not fail are produced once.
+			count := make(map[string]int)
+			for _, m := range got {
+				count[m.Value]++
+			}
+			exp := map[string]int{"1": 1, "3": 1}
+			if tc.failed == nil {
+				exp["2"] = 1
+				exp["4"] = 1
+			}
+			if !reflect.DeepEqual(count, exp) {
+				t.Errorf("unexpected produced messages got %v exp %v", count, exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package localauth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// Default duration successful authentications are cached.
+	DefaultCacheExpiration = toml.Duration(10 * time.Minute)
+)
+
+type Config struct {
+	// Cost of the bcrypt password hashes.
+	BcryptCost int `toml:"bcrypt-cost"`
+	// How long a successful authentication is cached,
+	// avoiding the cost of comparing the password hash for each request.
+	// Zero disables the cache.
+	CacheExpiration toml.Duration `toml:"cache-expiration"`
+
+	// Admin user that is created when no users exist.
+	BootstrapUsername string `toml:"bootstrap-username"`
+	BootstrapPassword string `toml:"bootstrap-password"`
+}
+
+func NewConfig() Config {
+	return Config{
+		BcryptCost:      bcrypt.DefaultCost,
+		CacheExpiration: DefaultCacheExpiration,
+	}
+}
+
+func (c Config) Validate() error {
+	if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+		return fmt.Errorf("bcrypt-cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	if c.CacheExpiration < 0 {
+		return fmt.Errorf("cache-expiration cannot be negative")
+	}
+	if (c.BootstrapUsername == "") != (c.BootstrapPassword == "") {
+		return fmt.Errorf("must specify both bootstrap-username and bootstrap-password")
+	}
+	return nil
+}
@@ -0,0 +1,379 @@
+package localauth
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/influxdata/kapacitor/services/storage"
+)
+
+var (
+	ErrUserExists           = errors.New("user already exists")
+	ErrNoUserExists         = errors.New("no user exists")
+	ErrNoSubscriptionExists = errors.New("no subscription token exists")
+	ErrNoTokenExists        = errors.New("no token exists")
+)
+
+// Data access object for User data.
+type UserDAO interface {
+	// Retrieve a user
+	Get(name string) (User, error)
+
+	// Create a user.
+	// ErrUserExists is returned if a user already exists with the same name.
+	Create(u User) error
+
+	// Replace an existing user.
+	// ErrNoUserExists is returned if the user does not exist.
+	Replace(u User) error
+
+	// Delete a user.
+	// It is not an error to delete an non-existent user.
+	Delete(name string) error
+
+	// List users matching a pattern.
+	// The pattern is shell/glob matching see https://golang.org/pkg/path/#Match
+	// Offset and limit are pagination bounds. Offset is inclusive starting at index 0.
+	// More results may exist while the number of returned items is equal to limit.
+	List(pattern string, offset, limit int) ([]User, error)
+
+	// Rebuild fixes all indexes of the data.
+	Rebuild() error
+}
+
+// Data access object for Subscription token data.
+type SubscriptionDAO interface {
+	// Retrieve a subscription token
+	Get(token string) (Subscription, error)
+
+	// Put a subscription, creating it or replacing it if it already exists.
+	Put(s Subscription) error
+
+	// Delete a subscription token.
+	// It is not an error to delete an non-existent token.
+	Delete(token string) error
+
+	// List all subscription tokens.
+	List() ([]Subscription, error)
+
+	// Rebuild fixes all indexes of the data.
+	Rebuild() error
+}
+
+// Data access object for Token data.
+type TokenDAO interface {
+	// Retrieve a token
+	Get(id string) (Token, error)
+
+	// Create a token.
+	Create(t Token) error
+
+	// Delete a token.
+	// It is not an error to delete an non-existent token.
+	Delete(id string) error
+
+	// List tokens matching a pattern.
+	// The pattern is shell/glob matching see https://golang.org/pkg/path/#Match
+	// Offset and limit are pagination bounds. Offset is inclusive starting at index 0.
+	// More results may exist while the number of returned items is equal to limit.
+	List(pattern string, offset, limit int) ([]Token, error)
+
+	// Rebuild fixes all indexes of the data.
+	Rebuild() error
+}
+
+//--------------------------------------------------------------------
+// The following structures are stored in a database via JSON encoding.
+// Changes to the structures could break existing data.
+//
+// Many of these structures are exact copies of structures found elsewhere,
+// this is intentional so that all structures stored in the database are
+// defined here and nowhere else. So as to not accidentally change
+// the JSON serialization format in incompatible ways.
+
+// version is the current version of the User, Subscription and Token structures.
+const version = 1
+
+type User struct {
+	Name string `json:"name"`
+	// bcrypt hash of the user's password
+	Hash  []byte `json:"hash"`
+	Admin bool   `json:"admin"`
+	// Map of resource to the names of the privileges the user has for the resource.
+	Privileges map[string][]string `json:"privileges"`
+}
+
+func (u User) ObjectID() string {
+	return u.Name
+}
+
+func (u User) MarshalBinary() ([]byte, error) {
+	return storage.VersionJSONEncode(version, u)
+}
+
+func (u *User) UnmarshalBinary(data []byte) error {
+	return storage.VersionJSONDecode(data, func(version int, dec *json.Decoder) error {
+		return dec.Decode(u)
+	})
+}
+
+// Subscription is a token used by InfluxDB subscriptions to write data.
+type Subscription struct {
+	Token string `json:"token"`
+	// The database and retention policy pairs the token may write to.
+	Grants []SubscriptionGrant `json:"grants"`
+}
+
+type SubscriptionGrant struct {
+	Database        string `json:"db"`
+	RetentionPolicy string `json:"rp"`
+}
+
+func (s Subscription) ObjectID() string {
+	return s.Token
+}
+
+func (s Subscription) MarshalBinary() ([]byte, error) {
+	return storage.VersionJSONEncode(version, s)
+}
+
+func (s *Subscription) UnmarshalBinary(data []byte) error {
+	return storage.VersionJSONDecode(data, func(version int, dec *json.Decoder) error {
+		return dec.Decode(s)
+	})
+}
+
+const (
+	// Opaque long-lived token
+	APITokenType = "api"
+	// Short-lived JWT signed with the shared secret
+	JWTTokenType = "jwt"
+)
+
+// Token is a token issued by Kapacitor.
+// The secret of API tokens is not stored, only its hash.
+// JWTs are stored so that they can be listed and revoked.
+type Token struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// SHA-256 hash of the secret of an API token.
+	Hash        []byte    `json:"hash,omitempty"`
+	Username    string    `json:"username"`
+	Description string    `json:"description"`
+	Created     time.Time `json:"created"`
+	// Expiration of the token, the zero value means the token does not expire.
+	Expires time.Time `json:"expires"`
+	// The privileges the token is restricted to, nil means all the privileges of the user.
+	Privileges map[string][]string `json:"privileges"`
+}
+
+func (t Token) Expired(now time.Time) bool {
+	return !t.Expires.IsZero() && now.After(t.Expires)
+}
+
+func (t Token) ObjectID() string {
+	return t.ID
+}
+
+func (t Token) MarshalBinary() ([]byte, error) {
+	return storage.VersionJSONEncode(version, t)
+}
+
+func (t *Token) UnmarshalBinary(data []byte) error {
+	return storage.VersionJSONDecode(data, func(version int, dec *json.Decoder) error {
+		return dec.Decode(t)
+	})
+}
+
+// Key/Value store based implementation of the UserDAO
+type userKV struct {
+	store *storage.IndexedStore
+}
+
+func newUserKV(store storage.Interface) (*userKV, error) {
+	c := storage.DefaultIndexedStoreConfig("users", func() storage.BinaryObject {
+		return new(User)
+	})
+	istore, err := storage.NewIndexedStore(store, c)
+	if err != nil {
+		return nil, err
+	}
+	return &userKV{
+		store: istore,
+	}, nil
+}
+
+func (kv *userKV) error(err error) error {
+	switch err {
+	case storage.ErrNoObjectExists:
+		return ErrNoUserExists
+	case storage.ErrObjectExists:
+		return ErrUserExists
+	}
+	return err
+}
+
+func (kv *userKV) Get(name string) (User, error) {
+	obj, err := kv.store.Get(name)
+	if err != nil {
+		return User{}, kv.error(err)
+	}
+	u, ok := obj.(*User)
+	if !ok {
+		return User{}, storage.ImpossibleTypeErr(u, obj)
+	}
+	return *u, nil
+}
+
+func (kv *userKV) Create(u User) error {
+	return kv.error(kv.store.Create(&u))
+}
+
+func (kv *userKV) Replace(u User) error {
+	return kv.error(kv.store.Replace(&u))
+}
+
+func (kv *userKV) Delete(name string) error {
+	return kv.error(kv.store.Delete(name))
+}
+
+func (kv *userKV) List(pattern string, offset, limit int) ([]User, error) {
+	objects, err := kv.store.List(storage.DefaultIDIndex, pattern, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]User, len(objects))
+	for i, object := range objects {
+		u, ok := object.(*User)
+		if !ok {
+			return nil, storage.ImpossibleTypeErr(u, object)
+		}
+		users[i] = *u
+	}
+	return users, nil
+}
+
+func (kv *userKV) Rebuild() error {
+	return kv.store.Rebuild()
+}
+
+// Key/Value store based implementation of the SubscriptionDAO
+type subscriptionKV struct {
+	store *storage.IndexedStore
+}
+
+func newSubscriptionKV(store storage.Interface) (*subscriptionKV, error) {
+	c := storage.DefaultIndexedStoreConfig("subscriptions", func() storage.BinaryObject {
+		return new(Subscription)
+	})
+	istore, err := storage.NewIndexedStore(store, c)
+	if err != nil {
+		return nil, err
+	}
+	return &subscriptionKV{
+		store: istore,
+	}, nil
+}
+
+func (kv *subscriptionKV) Get(token string) (Subscription, error) {
+	obj, err := kv.store.Get(token)
+	if err == storage.ErrNoObjectExists {
+		return Subscription{}, ErrNoSubscriptionExists
+	} else if err != nil {
+		return Subscription{}, err
+	}
+	s, ok := obj.(*Subscription)
+	if !ok {
+		return Subscription{}, storage.ImpossibleTypeErr(s, obj)
+	}
+	return *s, nil
+}
+
+func (kv *subscriptionKV) Put(s Subscription) error {
+	return kv.store.Put(&s)
+}
+
+func (kv *subscriptionKV) Delete(token string) error {
+	return kv.store.Delete(token)
+}
+
+func (kv *subscriptionKV) List() ([]Subscription, error) {
+	objects, err := kv.store.List(storage.DefaultIDIndex, "", 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions := make([]Subscription, len(objects))
+	for i, object := range objects {
+		s, ok := object.(*Subscription)
+		if !ok {
+			return nil, storage.ImpossibleTypeErr(s, object)
+		}
+		subscriptions[i] = *s
+	}
+	return subscriptions, nil
+}
+
+func (kv *subscriptionKV) Rebuild() error {
+	return kv.store.Rebuild()
+}
+
+// Key/Value store based implementation of the TokenDAO
+type tokenKV struct {
+	store *storage.IndexedStore
+}
+
+func newTokenKV(store storage.Interface) (*tokenKV, error) {
+	c := storage.DefaultIndexedStoreConfig("tokens", func() storage.BinaryObject {
+		return new(Token)
+	})
+	istore, err := storage.NewIndexedStore(store, c)
+	if err != nil {
+		return nil, err
+	}
+	return &tokenKV{
+		store: istore,
+	}, nil
+}
+
+func (kv *tokenKV) Get(id string) (Token, error) {
+	obj, err := kv.store.Get(id)
+	if err == storage.ErrNoObjectExists {
+		return Token{}, ErrNoTokenExists
+	} else if err != nil {
+		return Token{}, err
+	}
+	t, ok := obj.(*Token)
+	if !ok {
+		return Token{}, storage.ImpossibleTypeErr(t, obj)
+	}
+	return *t, nil
+}
+
+func (kv *tokenKV) Create(t Token) error {
+	return kv.store.Create(&t)
+}
+
+func (kv *tokenKV) Delete(id string) error {
+	return kv.store.Delete(id)
+}
+
+func (kv *tokenKV) List(pattern string, offset, limit int) ([]Token, error) {
+	objects, err := kv.store.List(storage.DefaultIDIndex, pattern, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]Token, len(objects))
+	for i, object := range objects {
+		t, ok := object.(*Token)
+		if !ok {
+			return nil, storage.ImpossibleTypeErr(t, object)
+		}
+		tokens[i] = *t
+	}
+	return tokens, nil
+}
+
+func (kv *tokenKV) Rebuild() error {
+	return kv.store.Rebuild()
+}
@@ -0,0 +1,8 @@
+/*
+The localauth package provides authentication of users stored in the Kapacitor database.
+
+Passwords are stored as bcrypt hashes and each user has a set of privileges for API and database resources.
+Users are managed via the /kapacitor/v1/users HTTP endpoints.
+The service is used in place of the noauth service when authentication is enabled in the [http] section.
+*/
+package localauth
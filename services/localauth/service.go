@@ -0,0 +1,1076 @@
+package localauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/influxdata/kapacitor/auth"
+	client "github.com/influxdata/kapacitor/client/v1"
+	"github.com/influxdata/kapacitor/services/httpd"
+	"github.com/influxdata/kapacitor/services/storage"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	usersPath             = "/users"
+	usersPathAnchored     = "/users/"
+	usersBasePath         = httpd.BasePath + usersPath
+	usersBasePathAnchored = httpd.BasePath + usersPathAnchored
+
+	tokensPath             = "/tokens"
+	tokensPathAnchored     = "/tokens/"
+	tokensBasePath         = httpd.BasePath + tokensPath
+	tokensBasePathAnchored = httpd.BasePath + tokensPathAnchored
+
+	// Default expiration of JWTs
+	defaultJWTExpiration = time.Hour
+	// Maximum expiration of JWTs, since JWTs cannot be revoked by third parties that trust them.
+	maxJWTExpiration = 24 * time.Hour
+)
+
+const (
+	// Public name of the users store
+	usersAPIName = "users"
+	// Public name of the subscription tokens store
+	subscriptionsAPIName = "subscription-tokens"
+	// Public name of the tokens store
+	tokensAPIName = "tokens"
+	// The storage namespace for all auth data.
+	authNamespace = "auth"
+)
+
+var (
+	ErrAuthenticationFailed = errors.New("authentication failed")
+	ErrTokenExpired         = errors.New("token has expired")
+
+	validUsername = regexp.MustCompile(`^[-\._\p{L}0-9@]+$`)
+)
+
+// Service authenticates users against a local store of users with bcrypt password hashes.
+type Service struct {
+	bcryptCost        int
+	cacheExpiration   time.Duration
+	bootstrapUsername string
+	bootstrapPassword string
+	// Secret used to sign JWTs
+	sharedSecret string
+	// Hash compared against when authenticating unknown users,
+	// so that the response time does not reveal whether a user exists.
+	dummyHash []byte
+
+	users         UserDAO
+	subscriptions SubscriptionDAO
+	tokens        TokenDAO
+
+	// Cache of successful authentications by username.
+	cacheMu sync.Mutex
+	cache   map[string]cachedAuthentication
+
+	// Serialize updates to users and subscriptions.
+	mu sync.Mutex
+
+	routes []httpd.Route
+
+	StorageService interface {
+		Store(namespace string) storage.Interface
+		Register(name string, store storage.StoreActioner)
+	}
+	HTTPDService interface {
+		AddRoutes([]httpd.Route) error
+		DelRoutes([]httpd.Route)
+	}
+
+	logger *log.Logger
+}
+
+type cachedAuthentication struct {
+	// SHA-256 sum of the password that was authenticated.
+	sum     [sha256.Size]byte
+	user    auth.User
+	expires time.Time
+}
+
+func NewService(c Config, sharedSecret string, l *log.Logger) *Service {
+	return &Service{
+		bcryptCost:        c.BcryptCost,
+		cacheExpiration:   time.Duration(c.CacheExpiration),
+		bootstrapUsername: c.BootstrapUsername,
+		bootstrapPassword: c.BootstrapPassword,
+		sharedSecret:      sharedSecret,
+		cache:             make(map[string]cachedAuthentication),
+		logger:            l,
+	}
+}
+
+func (s *Service) Open() error {
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("dummy password"), s.bcryptCost)
+	if err != nil {
+		return err
+	}
+	s.dummyHash = dummyHash
+
+	store := s.StorageService.Store(authNamespace)
+	users, err := newUserKV(store)
+	if err != nil {
+		return err
+	}
+	s.users = users
+	s.StorageService.Register(usersAPIName, s.users)
+
+	subscriptions, err := newSubscriptionKV(store)
+	if err != nil {
+		return err
+	}
+	s.subscriptions = subscriptions
+	s.StorageService.Register(subscriptionsAPIName, s.subscriptions)
+
+	tokens, err := newTokenKV(store)
+	if err != nil {
+		return err
+	}
+	s.tokens = tokens
+	s.StorageService.Register(tokensAPIName, s.tokens)
+
+	if err := s.bootstrap(); err != nil {
+		return err
+	}
+
+	s.routes = []httpd.Route{
+		{
+			Method:      "GET",
+			Pattern:     usersPath,
+			HandlerFunc: s.handleListUsers,
+		},
+		{
+			Method:      "POST",
+			Pattern:     usersPath,
+			HandlerFunc: s.handleCreateUser,
+		},
+		{
+			Method:      "GET",
+			Pattern:     usersPathAnchored,
+			HandlerFunc: s.handleUser,
+		},
+		{
+			Method:      "PATCH",
+			Pattern:     usersPathAnchored,
+			HandlerFunc: s.handleUpdateUser,
+		},
+		{
+			Method:      "DELETE",
+			Pattern:     usersPathAnchored,
+			HandlerFunc: s.handleDeleteUser,
+		},
+		{
+			Method:      "OPTIONS",
+			Pattern:     usersPathAnchored,
+			HandlerFunc: httpd.ServeOptions,
+		},
+		{
+			Method:      "GET",
+			Pattern:     tokensPath,
+			HandlerFunc: s.handleListTokens,
+		},
+		{
+			Method:      "POST",
+			Pattern:     tokensPath,
+			HandlerFunc: s.handleCreateToken,
+		},
+		{
+			Method:      "GET",
+			Pattern:     tokensPathAnchored,
+			HandlerFunc: s.handleToken,
+		},
+		{
+			Method:      "DELETE",
+			Pattern:     tokensPathAnchored,
+			HandlerFunc: s.handleRevokeToken,
+		},
+		{
+			Method:      "OPTIONS",
+			Pattern:     tokensPathAnchored,
+			HandlerFunc: httpd.ServeOptions,
+		},
+	}
+
+	return s.HTTPDService.AddRoutes(s.routes)
+}
+
+func (s *Service) Close() error {
+	if s.HTTPDService != nil {
+		s.HTTPDService.DelRoutes(s.routes)
+	}
+	return nil
+}
+
+// bootstrap creates the configured admin user if no users exist.
+func (s *Service) bootstrap() error {
+	users, err := s.users.List("", 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return nil
+	}
+	if s.bootstrapUsername == "" {
+		s.logger.Println("W! no users exist and no bootstrap user is configured, all requests will fail to authenticate")
+		return nil
+	}
+	if _, err := s.CreateUser(s.bootstrapUsername, s.bootstrapPassword, true, nil); err != nil {
+		return fmt.Errorf("failed to create bootstrap user: %v", err)
+	}
+	s.logger.Printf("I! created bootstrap admin user %q", s.bootstrapUsername)
+	return nil
+}
+
+// Authenticate checks the password of the user and returns the user.
+func (s *Service) Authenticate(username, password string) (auth.User, error) {
+	sum := sha256.Sum256([]byte(password))
+	if user, ok := s.cached(username, sum); ok {
+		return user, nil
+	}
+	u, err := s.users.Get(username)
+	if err == ErrNoUserExists {
+		bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return auth.User{}, ErrAuthenticationFailed
+	} else if err != nil {
+		return auth.User{}, err
+	}
+	if err := bcrypt.CompareHashAndPassword(u.Hash, []byte(password)); err != nil {
+		return auth.User{}, ErrAuthenticationFailed
+	}
+	user, err := convertUser(u)
+	if err != nil {
+		return auth.User{}, err
+	}
+	s.cacheAuthentication(username, sum, user)
+	return user, nil
+}
+
+func (s *Service) cached(username string, sum [sha256.Size]byte) (auth.User, bool) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	c, ok := s.cache[username]
+	if !ok {
+		return auth.User{}, false
+	}
+	if time.Now().After(c.expires) {
+		delete(s.cache, username)
+		return auth.User{}, false
+	}
+	if subtle.ConstantTimeCompare(c.sum[:], sum[:]) != 1 {
+		return auth.User{}, false
+	}
+	return c.user, true
+}
+
+func (s *Service) cacheAuthentication(username string, sum [sha256.Size]byte, user auth.User) {
+	if s.cacheExpiration == 0 {
+		return
+	}
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	s.cache[username] = cachedAuthentication{
+		sum:     sum,
+		user:    user,
+		expires: time.Now().Add(s.cacheExpiration),
+	}
+}
+
+func (s *Service) invalidate(username string) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	delete(s.cache, username)
+}
+
+// User returns the user without checking any credentials.
+func (s *Service) User(username string) (auth.User, error) {
+	u, err := s.users.Get(username)
+	if err != nil {
+		return auth.User{}, err
+	}
+	return convertUser(u)
+}
+
+// SubscriptionUser returns a user that may write to the databases the token was granted access to.
+func (s *Service) SubscriptionUser(token string) (auth.User, error) {
+	sub, err := s.subscriptions.Get(token)
+	if err == ErrNoSubscriptionExists {
+		return auth.User{}, ErrAuthenticationFailed
+	} else if err != nil {
+		return auth.User{}, err
+	}
+	privileges := make(map[string][]auth.Privilege, len(sub.Grants))
+	for _, g := range sub.Grants {
+		privileges[auth.DatabaseResource(g.Database)] = []auth.Privilege{auth.WritePrivilege}
+	}
+	return auth.NewUser(httpd.SubscriptionUser, nil, false, privileges), nil
+}
+
+func (s *Service) GrantSubscriptionAccess(token, db, rp string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, err := s.subscriptions.Get(token)
+	if err == ErrNoSubscriptionExists {
+		sub = Subscription{Token: token}
+	} else if err != nil {
+		return err
+	}
+	grant := SubscriptionGrant{Database: db, RetentionPolicy: rp}
+	for _, g := range sub.Grants {
+		if g == grant {
+			return nil
+		}
+	}
+	sub.Grants = append(sub.Grants, grant)
+	return s.subscriptions.Put(sub)
+}
+
+func (s *Service) ListSubscriptionTokens() ([]string, error) {
+	subs, err := s.subscriptions.List()
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]string, len(subs))
+	for i, sub := range subs {
+		tokens[i] = sub.Token
+	}
+	return tokens, nil
+}
+
+func (s *Service) RevokeSubscriptionAccess(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.subscriptions.Delete(token)
+}
+
+// CreateUser creates a new user with the given password and privileges.
+func (s *Service) CreateUser(username, password string, admin bool, privileges map[string][]string) (User, error) {
+	if !validUsername.MatchString(username) || username == httpd.SubscriptionUser {
+		return User{}, fmt.Errorf("username must contain only letters, numbers, '-', '.', '@' and '_'. %q", username)
+	}
+	if password == "" {
+		return User{}, errors.New("must provide a password")
+	}
+	privileges, err := cleanPrivileges(privileges)
+	if err != nil {
+		return User{}, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.bcryptCost)
+	if err != nil {
+		return User{}, err
+	}
+	u := User{
+		Name:       username,
+		Hash:       hash,
+		Admin:      admin,
+		Privileges: privileges,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.users.Create(u); err != nil {
+		return User{}, err
+	}
+	return u, nil
+}
+
+// UpdateUser changes the password, admin status or privileges of an existing user.
+// Empty values leave the existing value unchanged.
+func (s *Service) UpdateUser(username, password string, admin *bool, privileges map[string][]string) (User, error) {
+	var hash []byte
+	if password != "" {
+		h, err := bcrypt.GenerateFromPassword([]byte(password), s.bcryptCost)
+		if err != nil {
+			return User{}, err
+		}
+		hash = h
+	}
+	if privileges != nil {
+		p, err := cleanPrivileges(privileges)
+		if err != nil {
+			return User{}, err
+		}
+		privileges = p
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, err := s.users.Get(username)
+	if err != nil {
+		return User{}, err
+	}
+	if hash != nil {
+		u.Hash = hash
+	}
+	if admin != nil {
+		u.Admin = *admin
+	}
+	if privileges != nil {
+		u.Privileges = privileges
+	}
+	if err := s.users.Replace(u); err != nil {
+		return User{}, err
+	}
+	s.invalidate(username)
+	return u, nil
+}
+
+// DeleteUser removes a user.
+func (s *Service) DeleteUser(username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.users.Delete(username); err != nil {
+		return err
+	}
+	s.invalidate(username)
+	// Revoke all tokens of the user
+	tokens, err := s.tokens.List("", 0, -1)
+	if err != nil {
+		return err
+	}
+	for _, t := range tokens {
+		if t.Username == username {
+			if err := s.tokens.Delete(t.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ListUsers returns the users whose names match the pattern.
+func (s *Service) ListUsers(pattern string, offset, limit int) ([]User, error) {
+	return s.users.List(pattern, offset, limit)
+}
+
+// APITokenUser returns the user for an API token.
+// If the token is restricted the user only has the privileges of the token that the owner of the token still has.
+func (s *Service) APITokenUser(token string) (auth.User, error) {
+	i := strings.IndexRune(token, '.')
+	if i < 0 {
+		return auth.User{}, ErrAuthenticationFailed
+	}
+	id, secret := token[:i], token[i+1:]
+	t, err := s.tokens.Get(id)
+	if err == ErrNoTokenExists {
+		return auth.User{}, ErrAuthenticationFailed
+	} else if err != nil {
+		return auth.User{}, err
+	}
+	sum := sha256.Sum256([]byte(secret))
+	if t.Type != APITokenType || subtle.ConstantTimeCompare(t.Hash, sum[:]) != 1 {
+		return auth.User{}, ErrAuthenticationFailed
+	}
+	if t.Expired(time.Now()) {
+		return auth.User{}, ErrTokenExpired
+	}
+	owner, err := s.User(t.Username)
+	if err != nil {
+		return auth.User{}, ErrAuthenticationFailed
+	}
+	if t.Privileges == nil {
+		return owner, nil
+	}
+	privileges, err := restrictPrivileges(owner, t.Privileges)
+	if err != nil {
+		return auth.User{}, err
+	}
+	return auth.NewUser(owner.Name(), nil, false, privileges), nil
+}
+
+// TokenRevoked reports whether a JWT issued by the service has been revoked.
+func (s *Service) TokenRevoked(id string) (bool, error) {
+	if id == "" {
+		return true, nil
+	}
+	_, err := s.tokens.Get(id)
+	if err == ErrNoTokenExists {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// CreateToken issues a token on behalf of the issuer, returning the token and its secret value.
+// Tokens may only be created for other users by admin users,
+// and a token can never have more privileges than the issuer.
+func (s *Service) CreateToken(issuer auth.User, opt client.CreateTokenOptions) (Token, string, error) {
+	username := opt.Username
+	if username == "" {
+		username = issuer.Name()
+	}
+	if username != issuer.Name() && !issuer.IsAdmin() {
+		return Token{}, "", fmt.Errorf("user %s cannot create tokens for other users", issuer.Name())
+	}
+	tokenType := opt.Type
+	if tokenType == "" {
+		tokenType = APITokenType
+	}
+	if tokenType != APITokenType && tokenType != JWTTokenType {
+		return Token{}, "", fmt.Errorf("unknown token type %q, must be one of %q or %q", tokenType, APITokenType, JWTTokenType)
+	}
+	if opt.ExpiresIn < 0 {
+		return Token{}, "", errors.New("expires-in cannot be negative")
+	}
+	if tokenType == JWTTokenType {
+		if s.sharedSecret == "" {
+			return Token{}, "", errors.New("cannot create JWTs without a shared-secret configured in the [http] section")
+		}
+		if time.Duration(opt.ExpiresIn) > maxJWTExpiration {
+			return Token{}, "", fmt.Errorf("JWTs cannot expire later than %v", maxJWTExpiration)
+		}
+	}
+
+	owner, err := s.User(username)
+	if err != nil {
+		return Token{}, "", err
+	}
+	// Tokens created by the owner cannot exceed the privileges the owner authenticated with.
+	base := owner
+	if issuer.Name() == owner.Name() {
+		base = issuer
+	}
+
+	privileges := opt.Privileges
+	if privileges != nil {
+		p, err := cleanPrivileges(privileges)
+		if err != nil {
+			return Token{}, "", err
+		}
+		for resource, ps := range p {
+			for _, name := range ps {
+				privilege, _ := auth.ParsePrivilege(name)
+				if err := base.AuthorizeAction(auth.Action{Resource: resource, Privilege: privilege}); err != nil {
+					return Token{}, "", fmt.Errorf("cannot grant %q privilege for resource %q to token: %v", name, resource, err)
+				}
+			}
+		}
+		privileges = p
+	} else if base.IsAdmin() != owner.IsAdmin() || !reflect.DeepEqual(base.Privileges(), owner.Privileges()) {
+		// The issuer is itself restricted, so restrict the token to the same privileges.
+		privileges = privilegeNames(base.Privileges())
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return Token{}, "", err
+	}
+	now := time.Now().UTC()
+	t := Token{
+		ID:          id,
+		Type:        tokenType,
+		Username:    username,
+		Description: opt.Description,
+		Created:     now,
+		Privileges:  privileges,
+	}
+	if opt.ExpiresIn > 0 {
+		t.Expires = now.Add(time.Duration(opt.ExpiresIn))
+	}
+
+	var value string
+	switch tokenType {
+	case APITokenType:
+		secret, err := randomHex(32)
+		if err != nil {
+			return Token{}, "", err
+		}
+		sum := sha256.Sum256([]byte(secret))
+		t.Hash = sum[:]
+		value = id + "." + secret
+	case JWTTokenType:
+		if t.Expires.IsZero() {
+			t.Expires = now.Add(defaultJWTExpiration)
+		}
+		claims := jwt.MapClaims{
+			"username": username,
+			"exp":      t.Expires.Unix(),
+			"iat":      now.Unix(),
+			"jti":      id,
+			"iss":      httpd.TokenIssuer,
+		}
+		if privileges == nil {
+			claims[httpd.AdminClaim] = base.IsAdmin()
+			claims[httpd.PrivilegesClaim] = privilegeNames(base.Privileges())
+		} else {
+			claims[httpd.AdminClaim] = false
+			claims[httpd.PrivilegesClaim] = privileges
+		}
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString([]byte(s.sharedSecret))
+		if err != nil {
+			return Token{}, "", err
+		}
+		value = signed
+	}
+
+	if err := s.tokens.Create(t); err != nil {
+		return Token{}, "", err
+	}
+	return t, value, nil
+}
+
+// Token returns the token with the given ID.
+func (s *Service) Token(id string) (Token, error) {
+	return s.tokens.Get(id)
+}
+
+// RevokeToken deletes a token so that it can no longer be used.
+func (s *Service) RevokeToken(id string) error {
+	return s.tokens.Delete(id)
+}
+
+// ListTokens returns the tokens whose IDs match the pattern, expired tokens are removed.
+func (s *Service) ListTokens(pattern string, offset, limit int) ([]Token, error) {
+	tokens, err := s.tokens.List(pattern, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	filtered := tokens[0:0]
+	for _, t := range tokens {
+		if t.Expired(now) {
+			if err := s.tokens.Delete(t.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	return filtered, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// restrictPrivileges returns the privileges that the user has out of the named privileges.
+func restrictPrivileges(user auth.User, privileges map[string][]string) (map[string][]auth.Privilege, error) {
+	restricted := make(map[string][]auth.Privilege, len(privileges))
+	for resource, ps := range privileges {
+		for _, name := range ps {
+			p, err := auth.ParsePrivilege(name)
+			if err != nil {
+				return nil, err
+			}
+			if user.AuthorizeAction(auth.Action{Resource: resource, Privilege: p}) == nil {
+				restricted[resource] = append(restricted[resource], p)
+			}
+		}
+	}
+	return restricted, nil
+}
+
+func privilegeNames(privileges map[string][]auth.Privilege) map[string][]string {
+	names := make(map[string][]string, len(privileges))
+	for resource, ps := range privileges {
+		for _, p := range ps {
+			names[resource] = append(names[resource], p.String())
+		}
+	}
+	return names
+}
+
+// cleanPrivileges validates the resources and privilege names,
+// returning privileges with clean resource paths.
+func cleanPrivileges(privileges map[string][]string) (map[string][]string, error) {
+	clean := make(map[string][]string, len(privileges))
+	for resource, ps := range privileges {
+		if !path.IsAbs(resource) {
+			return nil, fmt.Errorf("invalid resource %q, must be an absolute path", resource)
+		}
+		if auth.IsPattern(resource) {
+			if _, err := path.Match(resource, ""); err != nil {
+				return nil, fmt.Errorf("invalid resource pattern %q: %v", resource, err)
+			}
+		}
+		for _, p := range ps {
+			if _, err := auth.ParsePrivilege(p); err != nil {
+				return nil, err
+			}
+		}
+		r := path.Clean(resource)
+		clean[r] = append(clean[r], ps...)
+	}
+	return clean, nil
+}
+
+// convertUser converts a stored user into an auth.User.
+func convertUser(u User) (auth.User, error) {
+	privileges := make(map[string][]auth.Privilege, len(u.Privileges))
+	for resource, ps := range u.Privileges {
+		for _, name := range ps {
+			p, err := auth.ParsePrivilege(name)
+			if err != nil {
+				return auth.User{}, err
+			}
+			privileges[resource] = append(privileges[resource], p)
+		}
+	}
+	return auth.NewUser(u.Name, u.Hash, u.Admin, privileges), nil
+}
+
+func userLink(name string) client.Link {
+	return client.Link{Relation: client.Self, Href: path.Join(usersBasePath, name)}
+}
+
+func convertClientUser(u User) client.User {
+	privileges := make(map[string][]string, len(u.Privileges))
+	for resource, ps := range u.Privileges {
+		privileges[resource] = append([]string(nil), ps...)
+	}
+	return client.User{
+		Link:       userLink(u.Name),
+		Username:   u.Name,
+		Admin:      u.Admin,
+		Privileges: privileges,
+	}
+}
+
+func (s *Service) usernameFromPath(p string) (string, error) {
+	name := strings.TrimPrefix(p, usersBasePathAnchored)
+	if name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("invalid user path %q", p)
+	}
+	return name, nil
+}
+
+func (s *Service) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	pattern := r.URL.Query().Get("pattern")
+	offset := 0
+	if o := r.URL.Query().Get("offset"); o != "" {
+		i, err := strconv.ParseInt(o, 10, 64)
+		if err != nil {
+			httpd.HttpError(w, fmt.Sprintf("invalid offset parameter %q must be an integer: %s", o, err), true, http.StatusBadRequest)
+			return
+		}
+		offset = int(i)
+	}
+	limit := 100
+	if l := r.URL.Query().Get("limit"); l != "" {
+		i, err := strconv.ParseInt(l, 10, 64)
+		if err != nil {
+			httpd.HttpError(w, fmt.Sprintf("invalid limit parameter %q must be an integer: %s", l, err), true, http.StatusBadRequest)
+			return
+		}
+		limit = int(i)
+	}
+	users, err := s.ListUsers(pattern, offset, limit)
+	if err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to list users: ", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	type response struct {
+		Link  client.Link   `json:"link"`
+		Users []client.User `json:"users"`
+	}
+	resp := response{
+		Link:  client.Link{Relation: client.Self, Href: r.URL.String()},
+		Users: make([]client.User, len(users)),
+	}
+	for i, u := range users {
+		resp.Users[i] = convertClientUser(u)
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(resp, true))
+}
+
+func (s *Service) handleCreateUser(w http.ResponseWriter, r *http.Request, user auth.User) {
+	opt := client.CreateUserOptions{}
+	if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid JSON: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	if !authorizeGrant(w, user, opt.Admin, opt.Privileges) {
+		return
+	}
+	u, err := s.CreateUser(opt.Username, opt.Password, opt.Admin, opt.Privileges)
+	if err == ErrUserExists {
+		httpd.HttpError(w, fmt.Sprintf("user %q already exists", opt.Username), true, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to create user: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(convertClientUser(u), true))
+}
+
+func (s *Service) handleUser(w http.ResponseWriter, r *http.Request) {
+	name, err := s.usernameFromPath(r.URL.Path)
+	if err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusBadRequest)
+		return
+	}
+	u, err := s.users.Get(name)
+	if err == ErrNoUserExists {
+		httpd.HttpError(w, fmt.Sprintf("no user exists with name %q", name), true, http.StatusNotFound)
+		return
+	} else if err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to get user %q: %v", name, err), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(convertClientUser(u), true))
+}
+
+func (s *Service) handleUpdateUser(w http.ResponseWriter, r *http.Request, user auth.User) {
+	name, err := s.usernameFromPath(r.URL.Path)
+	if err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusBadRequest)
+		return
+	}
+	opt := client.UpdateUserOptions{}
+	if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid JSON: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	if !user.IsAdmin() {
+		if opt.Admin != nil {
+			httpd.HttpError(w, fmt.Sprintf("user %s cannot change the admin status of users", user.Name()), true, http.StatusForbidden)
+			return
+		}
+		if !s.authorizeUserChange(w, user, name) {
+			return
+		}
+	}
+	if !authorizeGrant(w, user, false, opt.Privileges) {
+		return
+	}
+	u, err := s.UpdateUser(name, opt.Password, opt.Admin, opt.Privileges)
+	if err == ErrNoUserExists {
+		httpd.HttpError(w, fmt.Sprintf("no user exists with name %q", name), true, http.StatusNotFound)
+		return
+	} else if err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to update user %q: %v", name, err), true, http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(convertClientUser(u), true))
+}
+
+func (s *Service) handleDeleteUser(w http.ResponseWriter, r *http.Request, user auth.User) {
+	name, err := s.usernameFromPath(r.URL.Path)
+	if err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusBadRequest)
+		return
+	}
+	if !user.IsAdmin() && !s.authorizeUserChange(w, user, name) {
+		return
+	}
+	if err := s.DeleteUser(name); err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to delete user %q: %v", name, err), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeGrant checks that the user may grant the admin status and privileges to a user.
+// Only admin users may grant admin status and users can never grant privileges they do not have.
+func authorizeGrant(w http.ResponseWriter, user auth.User, admin bool, privileges map[string][]string) bool {
+	if user.IsAdmin() {
+		return true
+	}
+	if admin {
+		httpd.HttpError(w, fmt.Sprintf("user %s cannot grant admin status", user.Name()), true, http.StatusForbidden)
+		return false
+	}
+	privileges, err := cleanPrivileges(privileges)
+	if err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusBadRequest)
+		return false
+	}
+	if err := authorizePrivileges(user, privileges); err != nil {
+		httpd.HttpError(w, fmt.Sprint("cannot grant ", err), true, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// authorizePrivileges checks that the user has all of the privileges.
+func authorizePrivileges(user auth.User, privileges map[string][]string) error {
+	for resource, ps := range privileges {
+		for _, name := range ps {
+			privilege, _ := auth.ParsePrivilege(name)
+			if err := user.AuthorizeAction(auth.Action{Resource: resource, Privilege: privilege}); err != nil {
+				return fmt.Errorf("%q privilege for resource %q: %v", name, resource, err)
+			}
+		}
+	}
+	return nil
+}
+
+// authorizeUserChange checks that the non-admin user may change the named user.
+// Users may change themselves, otherwise they may only change users whose privileges they also have,
+// so that they cannot take over a user with more privileges.
+func (s *Service) authorizeUserChange(w http.ResponseWriter, user auth.User, name string) bool {
+	if name == user.Name() {
+		return true
+	}
+	u, err := s.users.Get(name)
+	if err == ErrNoUserExists {
+		// Let the change report that the user does not exist.
+		return true
+	} else if err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to get user %q: %v", name, err), true, http.StatusInternalServerError)
+		return false
+	}
+	if u.Admin {
+		httpd.HttpError(w, fmt.Sprintf("user %s cannot change admin user %s", user.Name(), name), true, http.StatusForbidden)
+		return false
+	}
+	if err := authorizePrivileges(user, u.Privileges); err != nil {
+		httpd.HttpError(w, fmt.Sprintf("user %s cannot change user %s with %v", user.Name(), name, err), true, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func tokenLink(id string) client.Link {
+	return client.Link{Relation: client.Self, Href: path.Join(tokensBasePath, id)}
+}
+
+func convertClientToken(t Token, value string) client.Token {
+	ct := client.Token{
+		Link:        tokenLink(t.ID),
+		ID:          t.ID,
+		Type:        t.Type,
+		Username:    t.Username,
+		Description: t.Description,
+		Created:     t.Created,
+		Privileges:  t.Privileges,
+		Token:       value,
+	}
+	if !t.Expires.IsZero() {
+		expires := t.Expires
+		ct.Expires = &expires
+	}
+	return ct
+}
+
+func (s *Service) tokenIDFromPath(p string) (string, error) {
+	id := strings.TrimPrefix(p, tokensBasePathAnchored)
+	if id == "" || strings.Contains(id, "/") {
+		return "", fmt.Errorf("invalid token path %q", p)
+	}
+	return id, nil
+}
+
+// authorizedToken returns the token if the user owns it or is an admin.
+func (s *Service) authorizedToken(w http.ResponseWriter, r *http.Request, user auth.User) (Token, bool) {
+	id, err := s.tokenIDFromPath(r.URL.Path)
+	if err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusBadRequest)
+		return Token{}, false
+	}
+	t, err := s.Token(id)
+	if err == nil && t.Username != user.Name() && !user.IsAdmin() {
+		// Do not reveal the existence of tokens of other users.
+		err = ErrNoTokenExists
+	}
+	if err == ErrNoTokenExists {
+		httpd.HttpError(w, fmt.Sprintf("no token exists with ID %q", id), true, http.StatusNotFound)
+		return Token{}, false
+	} else if err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to get token %q: %v", id, err), true, http.StatusInternalServerError)
+		return Token{}, false
+	}
+	return t, true
+}
+
+func (s *Service) handleListTokens(w http.ResponseWriter, r *http.Request, user auth.User) {
+	pattern := r.URL.Query().Get("pattern")
+	offset := 0
+	if o := r.URL.Query().Get("offset"); o != "" {
+		i, err := strconv.ParseInt(o, 10, 64)
+		if err != nil {
+			httpd.HttpError(w, fmt.Sprintf("invalid offset parameter %q must be an integer: %s", o, err), true, http.StatusBadRequest)
+			return
+		}
+		offset = int(i)
+	}
+	limit := 100
+	if l := r.URL.Query().Get("limit"); l != "" {
+		i, err := strconv.ParseInt(l, 10, 64)
+		if err != nil {
+			httpd.HttpError(w, fmt.Sprintf("invalid limit parameter %q must be an integer: %s", l, err), true, http.StatusBadRequest)
+			return
+		}
+		limit = int(i)
+	}
+	tokens, err := s.ListTokens(pattern, offset, limit)
+	if err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to list tokens: ", err.Error()), true, http.StatusInternalServerError)
+		return
+	}
+	type response struct {
+		Link   client.Link    `json:"link"`
+		Tokens []client.Token `json:"tokens"`
+	}
+	resp := response{
+		Link:   client.Link{Relation: client.Self, Href: r.URL.String()},
+		Tokens: make([]client.Token, 0, len(tokens)),
+	}
+	for _, t := range tokens {
+		if t.Username == user.Name() || user.IsAdmin() {
+			resp.Tokens = append(resp.Tokens, convertClientToken(t, ""))
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(resp, true))
+}
+
+func (s *Service) handleCreateToken(w http.ResponseWriter, r *http.Request, user auth.User) {
+	opt := client.CreateTokenOptions{}
+	if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+		httpd.HttpError(w, fmt.Sprint("invalid JSON: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	t, value, err := s.CreateToken(user, opt)
+	if err != nil {
+		httpd.HttpError(w, fmt.Sprint("failed to create token: ", err.Error()), true, http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(convertClientToken(t, value), true))
+}
+
+func (s *Service) handleToken(w http.ResponseWriter, r *http.Request, user auth.User) {
+	t, ok := s.authorizedToken(w, r, user)
+	if !ok {
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(httpd.MarshalJSON(convertClientToken(t, ""), true))
+}
+
+func (s *Service) handleRevokeToken(w http.ResponseWriter, r *http.Request, user auth.User) {
+	t, ok := s.authorizedToken(w, r, user)
+	if !ok {
+		return
+	}
+	if err := s.RevokeToken(t.ID); err != nil {
+		httpd.HttpError(w, fmt.Sprintf("failed to revoke token %q: %v", t.ID, err), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,327 @@
+package localauth_test
+
+import (
+	"log"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/influxdata/kapacitor/auth"
+	client "github.com/influxdata/kapacitor/client/v1"
+	"github.com/influxdata/kapacitor/services/httpd/httpdtest"
+	"github.com/influxdata/kapacitor/services/localauth"
+	"github.com/influxdata/kapacitor/services/storage/storagetest"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func OpenNewService(c localauth.Config) (*localauth.Service, *httpdtest.Server) {
+	service := localauth.NewService(c, "secret", log.New(os.Stderr, "[auth] ", log.LstdFlags))
+	service.StorageService = storagetest.New()
+	server := httpdtest.NewServer(testing.Verbose())
+	service.HTTPDService = server
+	if err := service.Open(); err != nil {
+		panic(err)
+	}
+	return service, server
+}
+
+func newConfig() localauth.Config {
+	c := localauth.NewConfig()
+	c.BcryptCost = bcrypt.MinCost
+	return c
+}
+
+func TestService_Bootstrap(t *testing.T) {
+	c := newConfig()
+	c.BootstrapUsername = "admin"
+	c.BootstrapPassword = "secret"
+	service, server := OpenNewService(c)
+	defer server.Close()
+	defer service.Close()
+
+	user, err := service.Authenticate("admin", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.IsAdmin() {
+		t.Error("expected bootstrap user to be an admin")
+	}
+	if _, err := service.Authenticate("admin", "wrong"); err != localauth.ErrAuthenticationFailed {
+		t.Errorf("unexpected error authenticating with wrong password: got %v", err)
+	}
+}
+
+func TestService_Users(t *testing.T) {
+	service, server := OpenNewService(newConfig())
+	defer server.Close()
+	defer service.Close()
+
+	cli, err := client.New(client.Config{URL: server.Server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := cli.CreateUser(client.CreateUserOptions{
+		Username: "bob",
+		Password: "secret",
+		Privileges: map[string][]string{
+			"/api/tasks": {"read", "write"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := client.User{
+		Link:     cli.UserLink("bob"),
+		Username: "bob",
+		Privileges: map[string][]string{
+			"/api/tasks": {"read", "write"},
+		},
+	}
+	if !reflect.DeepEqual(u, exp) {
+		t.Errorf("unexpected user:\ngot\n%v\nexp\n%v", u, exp)
+	}
+	if _, err := cli.CreateUser(client.CreateUserOptions{Username: "bob", Password: "other"}); err == nil {
+		t.Error("expected error creating existing user")
+	}
+	if _, err := cli.CreateUser(client.CreateUserOptions{
+		Username:   "eve",
+		Password:   "secret",
+		Privileges: map[string][]string{"/api/tasks": {"own"}},
+	}); err == nil {
+		t.Error("expected error creating user with unknown privilege")
+	}
+
+	user, err := service.Authenticate("bob", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := user.AuthorizeAction(auth.Action{Resource: "/api/tasks/cpu", Privilege: auth.WritePrivilege}); err != nil {
+		t.Error(err)
+	}
+	if err := user.AuthorizeAction(auth.Action{Resource: "/api/tasks/cpu", Privilege: auth.DeletePrivilege}); err == nil {
+		t.Error("expected user to not have delete privilege")
+	}
+
+	// Changing the password invalidates cached authentications.
+	if _, err := cli.UpdateUser(cli.UserLink("bob"), client.UpdateUserOptions{Password: "new-secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Authenticate("bob", "secret"); err != localauth.ErrAuthenticationFailed {
+		t.Errorf("unexpected error authenticating with old password: got %v", err)
+	}
+	admin := true
+	u, err = cli.UpdateUser(cli.UserLink("bob"), client.UpdateUserOptions{Admin: &admin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !u.Admin || len(u.Privileges) != 1 {
+		t.Errorf("unexpected updated user %v", u)
+	}
+	if user, err := service.Authenticate("bob", "new-secret"); err != nil {
+		t.Fatal(err)
+	} else if !user.IsAdmin() {
+		t.Error("expected updated user to be an admin")
+	}
+
+	users, err := cli.ListUsers(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Username != "bob" {
+		t.Errorf("unexpected users %v", users)
+	}
+
+	if err := cli.DeleteUser(cli.UserLink("bob")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.User(cli.UserLink("bob")); err == nil {
+		t.Error("expected error getting deleted user")
+	}
+	if _, err := service.Authenticate("bob", "new-secret"); err != localauth.ErrAuthenticationFailed {
+		t.Errorf("unexpected error authenticating deleted user: got %v", err)
+	}
+}
+
+func TestService_SubscriptionTokens(t *testing.T) {
+	service, server := OpenNewService(newConfig())
+	defer server.Close()
+	defer service.Close()
+
+	if err := service.GrantSubscriptionAccess("token", "telegraf", "autogen"); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := service.ListSubscriptionTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"token"}; !reflect.DeepEqual(tokens, exp) {
+		t.Errorf("unexpected tokens: got %v exp %v", tokens, exp)
+	}
+
+	user, err := service.SubscriptionUser("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := user.AuthorizeAction(auth.Action{Resource: auth.DatabaseResource("telegraf"), Privilege: auth.WritePrivilege}); err != nil {
+		t.Error(err)
+	}
+	if err := user.AuthorizeAction(auth.Action{Resource: auth.DatabaseResource("other"), Privilege: auth.WritePrivilege}); err == nil {
+		t.Error("expected subscription user to not have write privilege for other database")
+	}
+
+	if err := service.RevokeSubscriptionAccess("token"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.SubscriptionUser("token"); err != localauth.ErrAuthenticationFailed {
+		t.Errorf("unexpected error for revoked token: got %v", err)
+	}
+}
+
+func TestService_APITokens(t *testing.T) {
+	service, server := OpenNewService(newConfig())
+	defer server.Close()
+	defer service.Close()
+
+	if _, err := service.CreateUser("bob", "secret", false, map[string][]string{
+		"/api/tasks":  {"read", "write"},
+		"/api/topics": {"read"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	bob, err := service.User("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := service.CreateToken(bob, client.CreateTokenOptions{Username: "alice"}); err == nil {
+		t.Error("expected error creating token for another user")
+	}
+	if _, _, err := service.CreateToken(bob, client.CreateTokenOptions{
+		Privileges: map[string][]string{"/api/tasks": {"delete"}},
+	}); err == nil {
+		t.Error("expected error creating token with privileges the user does not have")
+	}
+
+	token, value, err := service.CreateToken(bob, client.CreateTokenOptions{
+		Description: "ci",
+		Privileges:  map[string][]string{"/api/tasks": {"write"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Type != localauth.APITokenType || token.Username != "bob" || !token.Expires.IsZero() {
+		t.Errorf("unexpected token %v", token)
+	}
+
+	user, err := service.APITokenUser(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Name() != "bob" || user.IsAdmin() {
+		t.Errorf("unexpected token user %v", user)
+	}
+	if err := user.AuthorizeAction(auth.Action{Resource: "/api/tasks/cpu", Privilege: auth.WritePrivilege}); err != nil {
+		t.Error(err)
+	}
+	if err := user.AuthorizeAction(auth.Action{Resource: "/api/topics", Privilege: auth.ReadPrivilege}); err == nil {
+		t.Error("expected token user to not have privileges outside of the token")
+	}
+	if _, err := service.APITokenUser(token.ID + ".wrong"); err != localauth.ErrAuthenticationFailed {
+		t.Errorf("unexpected error authenticating with wrong secret: got %v", err)
+	}
+
+	// Removing the privilege from the user removes it from the token.
+	if _, err := service.UpdateUser("bob", "", nil, map[string][]string{"/api/tasks": {"read"}}); err != nil {
+		t.Fatal(err)
+	}
+	user, err = service.APITokenUser(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := user.AuthorizeAction(auth.Action{Resource: "/api/tasks/cpu", Privilege: auth.WritePrivilege}); err == nil {
+		t.Error("expected token user to lose privileges removed from the user")
+	}
+
+	if err := service.RevokeToken(token.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.APITokenUser(value); err != localauth.ErrAuthenticationFailed {
+		t.Errorf("unexpected error authenticating with revoked token: got %v", err)
+	}
+}
+
+func TestService_JWTTokens(t *testing.T) {
+	service, server := OpenNewService(newConfig())
+	defer server.Close()
+	defer service.Close()
+
+	cli, err := client.New(client.Config{URL: server.Server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.CreateUser(client.CreateUserOptions{Username: "bob", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cli.CreateToken(client.CreateTokenOptions{
+		Type:       "jwt",
+		Username:   "bob",
+		Privileges: map[string][]string{"/api/tasks": {"read"}},
+	}); err == nil {
+		t.Fatal("expected error creating JWT with privileges the user does not have")
+	}
+	if _, err := cli.CreateToken(client.CreateTokenOptions{
+		Type:      "jwt",
+		Username:  "bob",
+		ExpiresIn: client.Duration(25 * time.Hour),
+	}); err == nil {
+		t.Fatal("expected error creating JWT with an expiration past the maximum")
+	}
+	token, err := cli.CreateToken(client.CreateTokenOptions{
+		Type:      "jwt",
+		Username:  "bob",
+		ExpiresIn: client.Duration(time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Token == "" || token.Expires == nil || token.Expires.Sub(token.Created) != time.Minute {
+		t.Errorf("unexpected token %v", token)
+	}
+
+	parsed, err := jwt.Parse(token.Token, func(*jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := parsed.Claims.(jwt.MapClaims)
+	if claims["username"] != "bob" || claims["jti"] != token.ID || claims["iss"] != "kapacitor" {
+		t.Errorf("unexpected claims %v", claims)
+	}
+
+	tokens, err := cli.ListTokens(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].ID != token.ID || tokens[0].Token != "" {
+		t.Errorf("unexpected tokens %v", tokens)
+	}
+
+	if revoked, err := service.TokenRevoked(token.ID); err != nil {
+		t.Fatal(err)
+	} else if revoked {
+		t.Error("expected token to not be revoked")
+	}
+	if err := cli.RevokeToken(token.Link); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, err := service.TokenRevoked(token.ID); err != nil {
+		t.Fatal(err)
+	} else if !revoked {
+		t.Error("expected token to be revoked")
+	}
+}
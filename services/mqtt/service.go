@@ -222,11 +222,12 @@ type handler struct {
 	logger *log.Logger
 }
 
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
 	h.logger.Println("D! HANDLE")
 	if err := h.s.Alert(h.c.BrokerName, h.c.Topic, h.c.QoS, h.c.Retained, event.State.Message); err != nil {
-		h.logger.Println("E! failed to post message to MQTT broker", err)
+		return fmt.Errorf("failed to post message to MQTT broker: %v", err)
 	}
+	return nil
 }
 
 type testOptions struct {
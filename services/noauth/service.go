@@ -55,3 +55,13 @@ func (s *Service) ListSubscriptionTokens() ([]string, error) {
 func (s *Service) RevokeSubscriptionAccess(token string) error {
 	return nil
 }
+
+// Return a user will all privileges.
+func (s *Service) APITokenUser(token string) (auth.User, error) {
+	s.logger.Println("W! using noauth auth backend. Faked authentication for API token")
+	return auth.NewUser("token-user", nil, true, nil), nil
+}
+
+func (s *Service) TokenRevoked(id string) (bool, error) {
+	return false, nil
+}
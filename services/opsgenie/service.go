@@ -205,7 +205,7 @@ func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
 	}
 }
 
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
 	var messageType string
 	switch event.State.Level {
 	case alert.OK:
@@ -222,6 +222,7 @@ func (h *handler) Handle(event alert.Event) {
 		event.State.Time,
 		event.Data.Result,
 	); err != nil {
-		h.logger.Println("E! failed to send event to OpsGenie", err)
+		return fmt.Errorf("failed to send event to OpsGenie: %v", err)
 	}
+	return nil
 }
@@ -184,7 +184,7 @@ func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
 	}
 }
 
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
 	if err := h.s.Alert(
 		h.c.ServiceKey,
 		event.State.ID,
@@ -192,6 +192,7 @@ func (h *handler) Handle(event alert.Event) {
 		event.State.Level,
 		event.Data.Result,
 	); err != nil {
-		h.logger.Println("E! failed to send event to PagerDuty", err)
+		return fmt.Errorf("failed to send event to PagerDuty: %v", err)
 	}
+	return nil
 }
@@ -248,7 +248,7 @@ func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
 	}
 }
 
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
 	if err := h.s.Alert(
 		event.State.Message,
 		h.c.Device,
@@ -258,6 +258,7 @@ func (h *handler) Handle(event alert.Event) {
 		h.c.Sound,
 		event.State.Level,
 	); err != nil {
-		h.logger.Println("E! failed to send event to Pushover", err)
+		return fmt.Errorf("failed to send event to Pushover: %v", err)
 	}
+	return nil
 }
@@ -191,13 +191,12 @@ func (s *Service) Handler(c HandlerConfig, l *log.Logger) (alert.Handler, error)
 	}, nil
 }
 
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
 	td := event.TemplateData()
 	var buf bytes.Buffer
 	err := h.sourceTmpl.Execute(&buf, td)
 	if err != nil {
-		h.logger.Printf("E! failed to evaluate Sensu source template %s: %v", h.c.Source, err)
-		return
+		return alert.Permanent(fmt.Errorf("failed to evaluate Sensu source template %s: %v", h.c.Source, err))
 	}
 	sourceStr := buf.String()
 
@@ -208,6 +207,7 @@ func (h *handler) Handle(event alert.Event) {
 		h.c.Handlers,
 		event.State.Level,
 	); err != nil {
-		h.logger.Println("E! failed to send event to Sensu", err)
+		return fmt.Errorf("failed to send event to Sensu: %v", err)
 	}
+	return nil
 }
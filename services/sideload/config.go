@@ -0,0 +1,24 @@
+package sideload
+
+import (
+	"fmt"
+)
+
+type Config struct {
+	// Directory that contains the sources of sideload nodes.
+	// Sources are relative to the directory and cannot refer to files outside of it.
+	Dir string `toml:"dir"`
+}
+
+func (c Config) Validate() error {
+	if c.Dir == "" {
+		return fmt.Errorf("must specify sideload dir")
+	}
+	return nil
+}
+
+func NewConfig() Config {
+	return Config{
+		Dir: "./sideload",
+	}
+}
@@ -0,0 +1,12 @@
+/*
+The sideload package provides access to static data stored in files on disk.
+
+A source is a directory containing YAML or JSON files.
+Sources are located within the configured sideload directory, sources outside of it are rejected.
+Each file contains a mapping of keys to values, nested mappings are flattened into keys joined by a '.'.
+Values are looked up by key from an ordered list of files relative to the source directory,
+the first file that contains the key provides the value.
+
+Sources are read when they are first opened and are cached until they are reloaded.
+*/
+package sideload
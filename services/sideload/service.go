@@ -0,0 +1,263 @@
+package sideload
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/kapacitor/services/httpd"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	sideloadPath  = "/sideload"
+	reloadPath    = sideloadPath + "/reload"
+	keySeparator  = "."
+	fileURLScheme = "file"
+)
+
+// Source provides values loaded from the files of a directory.
+type Source interface {
+	// Lookup returns the value of key from the first file in order that defines the key.
+	// Nil is returned if no file defines the key.
+	Lookup(order []string, key string) interface{}
+	// Close releases the source.
+	Close()
+}
+
+// Service manages the sources read by sideload nodes.
+type Service struct {
+	// dir contains all sources.
+	dir string
+
+	mu      sync.Mutex
+	sources map[string]*source
+
+	routes []httpd.Route
+
+	HTTPDService interface {
+		AddRoutes([]httpd.Route) error
+		DelRoutes([]httpd.Route)
+	}
+
+	logger *log.Logger
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	return &Service{
+		dir:     c.Dir,
+		sources: make(map[string]*source),
+		logger:  l,
+	}
+}
+
+func (s *Service) Open() error {
+	s.routes = []httpd.Route{
+		{
+			Method:      "POST",
+			Pattern:     reloadPath,
+			HandlerFunc: s.handleReload,
+		},
+	}
+	return s.HTTPDService.AddRoutes(s.routes)
+}
+
+func (s *Service) Close() error {
+	if s.HTTPDService != nil {
+		s.HTTPDService.DelRoutes(s.routes)
+	}
+	return nil
+}
+
+func (s *Service) handleReload(w http.ResponseWriter, r *http.Request) {
+	if err := s.Reload(); err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Reload rereads the files of all open sources.
+func (s *Service) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []string
+	for _, src := range s.sources {
+		if err := src.updateCache(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to reload sideload sources: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Source opens the source for the given directory.
+// The directory may be a path or a file:// URL, relative to the sideload directory.
+// Absolute paths must be within the sideload directory.
+// Opening a source always rereads its files so that restarting a task picks up any changes.
+func (s *Service) Source(dir string) (Source, error) {
+	dir, err := sourceDir(s.dir, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src, ok := s.sources[dir]
+	if !ok {
+		src = &source{
+			s:   s,
+			dir: dir,
+		}
+	}
+	if err := src.updateCache(); err != nil {
+		return nil, err
+	}
+	src.refCount++
+	s.sources[dir] = src
+	return src, nil
+}
+
+func (s *Service) closeSource(src *source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src.refCount--
+	if src.refCount <= 0 {
+		delete(s.sources, src.dir)
+	}
+}
+
+// sourceDir returns the cleaned directory path for a source, which must be within the root directory.
+func sourceDir(root, dir string) (string, error) {
+	src := dir
+	if strings.Contains(dir, "://") {
+		u, err := url.Parse(dir)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid sideload source %q", dir)
+		}
+		if u.Scheme != fileURLScheme {
+			return "", fmt.Errorf("unsupported sideload source scheme %q, only %q is supported", u.Scheme, fileURLScheme)
+		}
+		// The host of a relative URL such as file://dir/sub is the first element of the path.
+		src = u.Host + u.Path
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid sideload dir")
+	}
+	if !filepath.IsAbs(src) {
+		src = filepath.Join(root, src)
+	}
+	src = filepath.Clean(src)
+	rel, err := filepath.Rel(root, src)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("sideload source %q must be within the sideload dir %q", dir, root)
+	}
+	return src, nil
+}
+
+type source struct {
+	s        *Service
+	dir      string
+	refCount int
+
+	mu    sync.RWMutex
+	cache map[string]map[string]interface{}
+}
+
+func (s *source) Close() {
+	s.s.closeSource(s)
+}
+
+func (s *source) Lookup(order []string, key string) interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, o := range order {
+		values, ok := s.cache[filepath.ToSlash(filepath.Clean(o))]
+		if !ok {
+			continue
+		}
+		if v, ok := values[key]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// updateCache reads all files of the source directory.
+// The existing cache is preserved if any file cannot be read.
+func (s *source) updateCache() error {
+	cache := make(map[string]map[string]interface{})
+	err := filepath.Walk(s.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		var unmarshal func([]byte, interface{}) error
+		switch filepath.Ext(path) {
+		case ".yml", ".yaml":
+			unmarshal = yaml.Unmarshal
+		case ".json":
+			unmarshal = json.Unmarshal
+		default:
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var raw map[string]interface{}
+		if err := unmarshal(data, &raw); err != nil {
+			return errors.Wrapf(err, "failed to decode sideload file %q", path)
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		values := make(map[string]interface{})
+		flatten("", raw, values)
+		cache[filepath.ToSlash(rel)] = values
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to load sideload source %q", s.dir)
+	}
+	s.mu.Lock()
+	s.cache = cache
+	s.mu.Unlock()
+	return nil
+}
+
+// flatten copies the values of m into values,
+// the keys of nested maps are joined to the keys of their parents.
+func flatten(prefix string, m map[string]interface{}, values map[string]interface{}) {
+	for k, v := range m {
+		key := prefix + k
+		switch value := v.(type) {
+		case map[string]interface{}:
+			flatten(key+keySeparator, value, values)
+		case map[interface{}]interface{}:
+			nested := make(map[string]interface{}, len(value))
+			for nk, nv := range value {
+				nested[fmt.Sprint(nk)] = nv
+			}
+			flatten(key+keySeparator, nested, values)
+		case int:
+			values[key] = int64(value)
+		default:
+			values[key] = value
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package sideload
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceDir(t *testing.T) {
+	root := filepath.FromSlash("/var/lib/kapacitor/sideload")
+	testCases := []struct {
+		dir string
+		exp string
+		err bool
+	}{
+		{dir: "thresholds", exp: "/var/lib/kapacitor/sideload/thresholds"},
+		{dir: "file://thresholds/prod", exp: "/var/lib/kapacitor/sideload/thresholds/prod"},
+		{dir: "file:///var/lib/kapacitor/sideload/thresholds", exp: "/var/lib/kapacitor/sideload/thresholds"},
+		{dir: "/var/lib/kapacitor/sideload", exp: "/var/lib/kapacitor/sideload"},
+		{dir: "thresholds/../prod", exp: "/var/lib/kapacitor/sideload/prod"},
+		{dir: "/etc", err: true},
+		{dir: "file:///etc", err: true},
+		{dir: "../replay", err: true},
+		{dir: "file://../replay", err: true},
+		{dir: "/var/lib/kapacitor/sideload-other", err: true},
+		{dir: "http://example.com/thresholds", err: true},
+	}
+	for _, tc := range testCases {
+		got, err := sourceDir(root, tc.dir)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: expected error, got %q", tc.dir, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.dir, err)
+			continue
+		}
+		if exp := filepath.FromSlash(tc.exp); got != exp {
+			t.Errorf("%s: unexpected source dir got %q exp %q", tc.dir, got, exp)
+		}
+	}
+}
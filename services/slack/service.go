@@ -213,6 +213,10 @@ type HandlerConfig struct {
 	// IconEmoji is an emoji name surrounded in ':' characters.
 	// The emoji image will replace the normal user icon for the slack bot.
 	IconEmoji string `mapstructure:"icon-emoji"`
+
+	// NotifyAcknowledged sends acknowledged events to Slack,
+	// by default acknowledged events are skipped.
+	NotifyAcknowledged bool `mapstructure:"notify-acknowledged"`
 }
 
 type handler struct {
@@ -229,7 +233,10 @@ func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
 	}
 }
 
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
+	if event.State.Acknowledged() && !h.c.NotifyAcknowledged {
+		return nil
+	}
 	if err := h.s.Alert(
 		h.c.Channel,
 		event.State.Message,
@@ -237,6 +244,7 @@ func (h *handler) Handle(event alert.Event) {
 		h.c.IconEmoji,
 		event.State.Level,
 	); err != nil {
-		h.logger.Println("E! failed to send event to Slack", err)
+		return fmt.Errorf("failed to send event to Slack: %v", err)
 	}
+	return nil
 }
@@ -229,6 +229,10 @@ func (s *Service) Test(options interface{}) error {
 type HandlerConfig struct {
 	// List of email recipients.
 	To []string `mapstructure:"to"`
+
+	// NotifyAcknowledged sends emails for acknowledged events,
+	// by default acknowledged events are skipped.
+	NotifyAcknowledged bool `mapstructure:"notify-acknowledged"`
 }
 
 type handler struct {
@@ -245,12 +249,16 @@ func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
 	}
 }
 
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
+	if event.State.Acknowledged() && !h.c.NotifyAcknowledged {
+		return nil
+	}
 	if err := h.s.SendMail(
 		h.c.To,
 		event.State.Message,
 		event.State.Details,
 	); err != nil {
-		h.logger.Println("E! failed to send email", err)
+		return fmt.Errorf("failed to send email: %v", err)
 	}
+	return nil
 }
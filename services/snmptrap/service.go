@@ -227,20 +227,20 @@ func (s *Service) Handler(c HandlerConfig, l *log.Logger) (alert.Handler, error)
 }
 
 // Handle takes an event triggers an SNMP trap.
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
 	// Execute templates
 	td := event.TemplateData()
 	var buf bytes.Buffer
 	for i, d := range h.c.DataList {
 		err := d.tmpl.Execute(&buf, td)
 		if err != nil {
-			h.logger.Println("E! failed to handle event", err)
-			return
+			return alert.Permanent(fmt.Errorf("failed to evaluate SNMP trap data template: %v", err))
 		}
 		h.c.DataList[i].Value = buf.String()
 		buf.Reset()
 	}
 	if err := h.s.Trap(h.c.TrapOid, h.c.DataList); err != nil {
-		h.logger.Println("E! failed to handle event", err)
+		return fmt.Errorf("failed to send SNMP trap: %v", err)
 	}
+	return nil
 }
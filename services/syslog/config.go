@@ -0,0 +1,96 @@
+package syslog
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultNetwork  = "udp"
+	DefaultAddr     = "localhost:514"
+	DefaultFacility = "user"
+	DefaultAppName  = "kapacitor"
+	// DefaultSDID uses the private enterprise number reserved for documentation (RFC 5612),
+	// replace it with the enterprise number of your organization.
+	DefaultSDID    = "kapacitor@32473"
+	DefaultTimeout = 10 * time.Second
+)
+
+type Config struct {
+	// Whether syslog integration is enabled.
+	Enabled bool `toml:"enabled" override:"enabled"`
+	// Network of the syslog server, one of udp, tcp or tls.
+	Network string `toml:"network" override:"network"`
+	// The host:port address of the syslog server.
+	Addr string `toml:"addr" override:"addr"`
+	// Default facility of the messages, i.e. kern, user, daemon or local0 to local7.
+	Facility string `toml:"facility" override:"facility"`
+	// Default APP-NAME of the messages.
+	AppName string `toml:"app-name" override:"app-name"`
+	// HOSTNAME of the messages.
+	// If empty the hostname of the machine is used.
+	Hostname string `toml:"hostname" override:"hostname"`
+	// SD-ID of the structured data element containing the tags of the alert.
+	SDID string `toml:"sd-id" override:"sd-id"`
+	// Timeout for connecting and writing to the syslog server.
+	Timeout toml.Duration `toml:"timeout" override:"timeout"`
+
+	// Path to CA file
+	SSLCA string `toml:"ssl-ca" override:"ssl-ca"`
+	// Path to host cert file
+	SSLCert string `toml:"ssl-cert" override:"ssl-cert"`
+	// Path to cert key file
+	SSLKey string `toml:"ssl-key" override:"ssl-key"`
+	// Use SSL but skip chain & host verification
+	InsecureSkipVerify bool `toml:"insecure-skip-verify" override:"insecure-skip-verify"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Network:  DefaultNetwork,
+		Addr:     DefaultAddr,
+		Facility: DefaultFacility,
+		AppName:  DefaultAppName,
+		SDID:     DefaultSDID,
+		Timeout:  toml.Duration(DefaultTimeout),
+	}
+}
+
+func (c Config) Validate() error {
+	if c.Enabled && c.Addr == "" {
+		return errors.New("must specify addr")
+	}
+	if c.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+			return errors.Wrapf(err, "invalid addr %q", c.Addr)
+		}
+	}
+	if err := validateNetwork(c.Network); err != nil {
+		return err
+	}
+	if c.Facility != "" {
+		if _, err := ParseFacility(c.Facility); err != nil {
+			return err
+		}
+	}
+	if c.SDID != "" && !validName(c.SDID) {
+		return fmt.Errorf("invalid sd-id %q", c.SDID)
+	}
+	if c.Timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	return nil
+}
+
+func validateNetwork(network string) error {
+	switch network {
+	case "", "udp", "tcp", "tls":
+		return nil
+	default:
+		return fmt.Errorf("invalid network %q, must be one of udp, tcp or tls", network)
+	}
+}
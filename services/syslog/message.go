@@ -0,0 +1,219 @@
+package syslog
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// Facility is a syslog facility as defined in RFC 5424.
+type Facility int
+
+var facilities = []string{
+	"kern",
+	"user",
+	"mail",
+	"daemon",
+	"auth",
+	"syslog",
+	"lpr",
+	"news",
+	"uucp",
+	"cron",
+	"authpriv",
+	"ftp",
+	"ntp",
+	"audit",
+	"alert",
+	"clock",
+	"local0",
+	"local1",
+	"local2",
+	"local3",
+	"local4",
+	"local5",
+	"local6",
+	"local7",
+}
+
+// ParseFacility returns the facility with the given name.
+func ParseFacility(name string) (Facility, error) {
+	for i, f := range facilities {
+		if f == strings.ToLower(name) {
+			return Facility(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown syslog facility %q", name)
+}
+
+func (f Facility) String() string {
+	if f < 0 || int(f) >= len(facilities) {
+		return "Facility(" + strconv.Itoa(int(f)) + ")"
+	}
+	return facilities[f]
+}
+
+// Severity is a syslog severity as defined in RFC 5424.
+type Severity int
+
+const (
+	Emergency Severity = iota
+	Alert
+	Critical
+	Error
+	Warning
+	Notice
+	Informational
+	Debug
+)
+
+// LevelSeverity returns the syslog severity of the alert level.
+func LevelSeverity(level alert.Level) Severity {
+	switch level {
+	case alert.Info:
+		return Notice
+	case alert.Warning:
+		return Warning
+	case alert.Critical:
+		return Critical
+	default:
+		return Informational
+	}
+}
+
+// Maximum lengths of the header fields
+const (
+	maxHostnameLen = 255
+	maxAppNameLen  = 48
+	maxProcIDLen   = 128
+	maxMsgIDLen    = 32
+	maxNameLen     = 32
+)
+
+// Message is a syslog message in the format of RFC 5424.
+type Message struct {
+	Facility  Facility
+	Severity  Severity
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+	// SDID is the ID of the structured data element, no structured data is written if empty.
+	SDID string
+	// Params of the structured data element.
+	Params map[string]string
+	Text   string
+}
+
+// Bytes returns the message formatted as defined in RFC 5424.
+func (m Message) Bytes() []byte {
+	var b bytes.Buffer
+	// Header
+	b.WriteString("<")
+	b.WriteString(strconv.Itoa(int(m.Facility)*8 + int(m.Severity)))
+	b.WriteString(">1 ")
+	if m.Timestamp.IsZero() {
+		b.WriteString("-")
+	} else {
+		b.WriteString(m.Timestamp.UTC().Format("2006-01-02T15:04:05.999999Z07:00"))
+	}
+	b.WriteString(" ")
+	b.WriteString(headerField(m.Hostname, maxHostnameLen))
+	b.WriteString(" ")
+	b.WriteString(headerField(m.AppName, maxAppNameLen))
+	b.WriteString(" ")
+	b.WriteString(headerField(m.ProcID, maxProcIDLen))
+	b.WriteString(" ")
+	b.WriteString(headerField(m.MsgID, maxMsgIDLen))
+	b.WriteString(" ")
+
+	// Structured data
+	if m.SDID == "" {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[")
+		b.WriteString(m.SDID)
+		names := make([]string, 0, len(m.Params))
+		for name := range m.Params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			b.WriteString(" ")
+			b.WriteString(paramName(name))
+			b.WriteString(`="`)
+			b.WriteString(paramValue(m.Params[name]))
+			b.WriteString(`"`)
+		}
+		b.WriteString("]")
+	}
+
+	if m.Text != "" {
+		b.WriteString(" ")
+		b.WriteString(m.Text)
+	}
+	return b.Bytes()
+}
+
+// headerField returns the value of a header field,
+// replacing the characters which are not printable US-ASCII and truncating it to max characters.
+func headerField(s string, max int) string {
+	if s == "" {
+		return "-"
+	}
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	return s
+}
+
+// validName reports whether s is a valid SD-NAME.
+func validName(s string) bool {
+	if len(s) == 0 || len(s) > maxNameLen {
+		return false
+	}
+	for _, r := range s {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return false
+		}
+	}
+	// The @ is only allowed once to separate the enterprise number of an SD-ID.
+	return strings.Count(s, "@") <= 1
+}
+
+// paramName returns a valid SD-NAME for the name of a parameter,
+// replacing the characters which are not allowed.
+func paramName(s string) string {
+	if s == "" {
+		return "_"
+	}
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' || r == '@' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > maxNameLen {
+		s = s[:maxNameLen]
+	}
+	return s
+}
+
+var paramValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// paramValue escapes the value of a parameter.
+func paramValue(s string) string {
+	return paramValueReplacer.Replace(s)
+}
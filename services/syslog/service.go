@@ -0,0 +1,276 @@
+package syslog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/tlsconfig"
+	"github.com/pkg/errors"
+)
+
+type Service struct {
+	configValue atomic.Value
+	hostname    string
+	procID      string
+	logger      *log.Logger
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	hostname, _ := os.Hostname()
+	s := &Service{
+		hostname: hostname,
+		procID:   strconv.Itoa(os.Getpid()),
+		logger:   l,
+	}
+	s.configValue.Store(c)
+	return s
+}
+
+func (s *Service) Open() error {
+	return nil
+}
+
+func (s *Service) Close() error {
+	return nil
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if l := len(newConfig); l != 1 {
+		return fmt.Errorf("expected only one new config object, got %d", l)
+	}
+	if c, ok := newConfig[0].(Config); !ok {
+		return fmt.Errorf("expected config object to be of type %T, got %T", c, newConfig[0])
+	} else {
+		s.configValue.Store(c)
+	}
+	return nil
+}
+
+type testOptions struct {
+	Facility string            `json:"facility"`
+	AppName  string            `json:"app-name"`
+	MsgID    string            `json:"msg-id"`
+	Message  string            `json:"message"`
+	Level    alert.Level       `json:"level"`
+	Tags     map[string]string `json:"tags"`
+}
+
+func (s *Service) TestOptions() interface{} {
+	c := s.config()
+	return &testOptions{
+		Facility: c.Facility,
+		AppName:  c.AppName,
+		Message:  "test syslog message",
+		Level:    alert.Critical,
+		Tags:     map[string]string{},
+	}
+}
+
+func (s *Service) Test(options interface{}) error {
+	o, ok := options.(*testOptions)
+	if !ok {
+		return fmt.Errorf("unexpected options type %T", options)
+	}
+	h, err := s.newHandler(HandlerConfig{
+		Facility: o.Facility,
+		AppName:  o.AppName,
+		MsgID:    o.MsgID,
+	}, s.logger)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+	return h.send(time.Now(), o.Message, o.Level, o.Tags)
+}
+
+type HandlerConfig struct {
+	// Facility of the messages.
+	// If empty uses the facility from the configuration.
+	Facility string `mapstructure:"facility"`
+	// APP-NAME of the messages.
+	// If empty uses the app-name from the configuration.
+	AppName string `mapstructure:"app-name"`
+	// MSGID of the messages.
+	MsgID string `mapstructure:"msg-id"`
+}
+
+// handler sends the events to the syslog server over its own connection.
+// The connection is reused for all events and reestablished when writing fails
+// or the network or address of the server are updated.
+type handler struct {
+	s      *Service
+	c      HandlerConfig
+	logger *log.Logger
+
+	mu      sync.Mutex
+	conn    net.Conn
+	network string
+	addr    string
+}
+
+func (s *Service) Handler(c HandlerConfig, l *log.Logger) (alert.Handler, error) {
+	return s.newHandler(c, l)
+}
+
+func (s *Service) newHandler(c HandlerConfig, l *log.Logger) (*handler, error) {
+	if c.Facility != "" {
+		if _, err := ParseFacility(c.Facility); err != nil {
+			return nil, err
+		}
+	}
+	return &handler{
+		s:      s,
+		c:      c,
+		logger: l,
+	}, nil
+}
+
+func (h *handler) Handle(event alert.Event) error {
+	if err := h.send(event.State.Time, event.State.Message, event.State.Level, event.Data.Tags); err != nil {
+		return fmt.Errorf("failed to send event to syslog: %v", err)
+	}
+	return nil
+}
+
+func (h *handler) send(t time.Time, text string, level alert.Level, tags map[string]string) error {
+	c := h.s.config()
+	if !c.Enabled {
+		return errors.New("service is not enabled")
+	}
+
+	facilityName := h.c.Facility
+	if facilityName == "" {
+		facilityName = c.Facility
+	}
+	if facilityName == "" {
+		facilityName = DefaultFacility
+	}
+	facility, err := ParseFacility(facilityName)
+	if err != nil {
+		return err
+	}
+	appName := h.c.AppName
+	if appName == "" {
+		appName = c.AppName
+	}
+	hostname := c.Hostname
+	if hostname == "" {
+		hostname = h.s.hostname
+	}
+	m := Message{
+		Facility:  facility,
+		Severity:  LevelSeverity(level),
+		Timestamp: t,
+		Hostname:  hostname,
+		AppName:   appName,
+		ProcID:    h.s.procID,
+		MsgID:     h.c.MsgID,
+		Text:      text,
+	}
+	if len(tags) > 0 {
+		m.SDID = c.SDID
+		m.Params = tags
+	}
+	return h.write(c, m.Bytes())
+}
+
+// write writes the message to the connection,
+// reconnecting and retrying once if writing to an existing connection fails.
+func (h *handler) write(c Config, msg []byte) error {
+	network := c.Network
+	if network == "" {
+		network = DefaultNetwork
+	}
+	timeout := time.Duration(c.Timeout)
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	// Stream transports use octet counting to frame the messages, see RFC 6587 and RFC 5425.
+	if network != "udp" {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conn != nil && (h.network != network || h.addr != c.Addr) {
+		h.closeConn()
+	}
+	reused := h.conn != nil
+	if err := h.writeConn(c, network, timeout, msg); err != nil {
+		if !reused {
+			return err
+		}
+		h.logger.Printf("D! reconnecting to syslog server %s after failed write: %v", c.Addr, err)
+		return h.writeConn(c, network, timeout, msg)
+	}
+	return nil
+}
+
+// Caller must have lock.
+func (h *handler) writeConn(c Config, network string, timeout time.Duration, msg []byte) error {
+	if h.conn == nil {
+		conn, err := dial(c, network, timeout)
+		if err != nil {
+			return err
+		}
+		h.conn = conn
+		h.network = network
+		h.addr = c.Addr
+	}
+	h.conn.SetWriteDeadline(time.Now().Add(timeout))
+	if _, err := h.conn.Write(msg); err != nil {
+		h.closeConn()
+		return errors.Wrapf(err, "failed to write to syslog server %s", c.Addr)
+	}
+	return nil
+}
+
+func dial(c Config, network string, timeout time.Duration) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	var err error
+	switch network {
+	case "tls":
+		var tlsConfig *tls.Config
+		tlsConfig, err = tlsconfig.Create(c.SSLCA, c.SSLCert, c.SSLKey, c.InsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.Addr, tlsConfig)
+	case "tcp", "udp":
+		conn, err = dialer.Dial(network, c.Addr)
+	default:
+		return nil, validateNetwork(network)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to syslog server %s", c.Addr)
+	}
+	return conn, nil
+}
+
+// Caller must have lock.
+func (h *handler) closeConn() {
+	if h.conn != nil {
+		h.conn.Close()
+		h.conn = nil
+	}
+}
+
+// Close closes the connection of the handler.
+func (h *handler) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closeConn()
+}
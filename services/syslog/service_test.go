@@ -0,0 +1,118 @@
+package syslog_test
+
+import (
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/syslog"
+	"github.com/influxdata/kapacitor/services/syslog/syslogtest"
+)
+
+func TestHandler_Reconnect(t *testing.T) {
+	ts, err := syslogtest.NewServer("tcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+
+	c := syslog.NewConfig()
+	c.Enabled = true
+	c.Network = "tcp"
+	c.Addr = ts.Addr
+	c.Hostname = "host"
+	s := syslog.NewService(c, log.New(os.Stderr, "[syslog] ", log.LstdFlags))
+	h, err := s.Handler(syslog.HandlerConfig{Facility: "local0"}, log.New(os.Stderr, "[syslog] ", log.LstdFlags))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := alert.Event{
+		State: alert.EventState{
+			ID:      "id",
+			Message: "first",
+			Level:   alert.Warning,
+			Time:    time.Date(2017, 1, 1, 0, 0, 0, 500, time.UTC),
+		},
+		Data: alert.EventData{
+			Tags: map[string]string{
+				"host":      "serverA",
+				"odd name=": `a "quoted" value]`,
+			},
+		},
+	}
+	if err := h.Handle(event); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for the first message so that closing the connection does not drop it.
+	waitMessages(t, ts, 1)
+	ts.CloseConns()
+
+	// Writes to a connection closed by the server may only fail after the close is noticed,
+	// keep handling events until one is received over a new connection.
+	event.State.Message = "second"
+	event.Data.Tags = nil
+	for i := 0; i < 3; i++ {
+		if err := h.Handle(event); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	h.(interface {
+		Close()
+	}).Close()
+	ts.Close()
+
+	msgs, err := ts.Messages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) < 2 {
+		t.Fatalf("expected at least 2 messages, got %d", len(msgs))
+	}
+	procID := strconv.Itoa(os.Getpid())
+	exp := []syslogtest.Message{
+		{
+			Priority:       132,
+			Timestamp:      "2017-01-01T00:00:00Z",
+			Hostname:       "host",
+			AppName:        "kapacitor",
+			ProcID:         procID,
+			MsgID:          "-",
+			StructuredData: `[kapacitor@32473 host="serverA" odd_name_="a \"quoted\" value\]"]`,
+			Text:           "first",
+		},
+		{
+			Priority:       132,
+			Timestamp:      "2017-01-01T00:00:00Z",
+			Hostname:       "host",
+			AppName:        "kapacitor",
+			ProcID:         procID,
+			MsgID:          "-",
+			StructuredData: "-",
+			Text:           "second",
+		},
+	}
+	if got := msgs[:2]; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected messages:\ngot\n%+v\nexp\n%+v", got, exp)
+	}
+}
+
+func waitMessages(t *testing.T, ts *syslogtest.Server, n int) {
+	for i := 0; i < 100; i++ {
+		msgs, err := ts.Messages()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) >= n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d messages", n)
+}
@@ -0,0 +1,255 @@
+// Package syslogtest provides a syslog server for tests.
+// The server listens on UDP or TCP and records the received RFC 5424 messages.
+package syslogtest
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// drainTimeout is how long the server reads the messages in flight when closed.
+const drainTimeout = 100 * time.Millisecond
+
+type Server struct {
+	// Addr of the server
+	Addr string
+	// Network of the server, udp or tcp
+	Network string
+
+	l      net.Listener
+	pc     net.PacketConn
+	wg     sync.WaitGroup
+	closed bool
+
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	messages []Message
+	err      error
+}
+
+// Message is a message received by the server.
+type Message struct {
+	Priority       int
+	Timestamp      string
+	Hostname       string
+	AppName        string
+	ProcID         string
+	MsgID          string
+	StructuredData string
+	Text           string
+}
+
+// NewServer starts a server on the network, either udp or tcp.
+func NewServer(network string) (*Server, error) {
+	s := &Server{
+		Network: network,
+		conns:   make(map[net.Conn]bool),
+	}
+	switch network {
+	case "udp":
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		s.pc = pc
+		s.Addr = pc.LocalAddr().String()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.runUDP()
+		}()
+	case "tcp":
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		s.l = l
+		s.Addr = l.Addr().String()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.runTCP()
+		}()
+	default:
+		return nil, errors.New("unsupported network " + network)
+	}
+	return s, nil
+}
+
+// Messages returns the received messages and the first error reading messages.
+func (s *Server) Messages() ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messages, s.err
+}
+
+// CloseConns closes the open connections of clients to the server.
+func (s *Server) CloseConns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+		delete(s.conns, conn)
+	}
+}
+
+// Close stops the server once the messages already sent to it have been read.
+func (s *Server) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	// Read the messages in flight until the drain timeout before stopping.
+	deadline := time.Now().Add(drainTimeout)
+	for conn := range s.conns {
+		conn.SetReadDeadline(deadline)
+	}
+	s.mu.Unlock()
+	if s.l != nil {
+		s.l.Close()
+	}
+	if s.pc != nil {
+		s.pc.SetReadDeadline(deadline)
+	}
+	s.wg.Wait()
+	if s.pc != nil {
+		s.pc.Close()
+	}
+}
+
+func (s *Server) runUDP() {
+	b := make([]byte, 64*1024)
+	for {
+		n, _, err := s.pc.ReadFrom(b)
+		if err != nil {
+			return
+		}
+		s.record(string(b[:n]))
+	}
+}
+
+func (s *Server) runTCP() {
+	for {
+		conn, err := s.l.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = true
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.serve(conn)
+		}()
+	}
+}
+
+// serve reads messages framed using octet counting from the connection.
+func (s *Server) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	for {
+		l, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(l, " "))
+		if err != nil {
+			s.setErr(errors.New("invalid message length " + l))
+			return
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return
+		}
+		s.record(string(b))
+	}
+}
+
+func (s *Server) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *Server) record(msg string) {
+	m, err := parse(msg)
+	if err != nil {
+		s.setErr(err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, m)
+}
+
+// parse parses an RFC 5424 message.
+func parse(msg string) (Message, error) {
+	var m Message
+	if !strings.HasPrefix(msg, "<") {
+		return m, errors.New("missing priority: " + msg)
+	}
+	end := strings.Index(msg, ">")
+	if end < 0 {
+		return m, errors.New("missing priority: " + msg)
+	}
+	pri, err := strconv.Atoi(msg[1:end])
+	if err != nil {
+		return m, errors.New("invalid priority: " + msg)
+	}
+	m.Priority = pri
+
+	fields := strings.SplitN(msg[end+1:], " ", 7)
+	if len(fields) < 7 || fields[0] != "1" {
+		return m, errors.New("invalid header: " + msg)
+	}
+	m.Timestamp = fields[1]
+	m.Hostname = fields[2]
+	m.AppName = fields[3]
+	m.ProcID = fields[4]
+	m.MsgID = fields[5]
+
+	rest := fields[6]
+	if strings.HasPrefix(rest, "-") {
+		m.StructuredData = "-"
+		rest = rest[1:]
+	} else {
+		// Find the end of the structured data, skipping escaped characters
+		i := 0
+		escaped := false
+	Loop:
+		for ; i < len(rest); i++ {
+			switch {
+			case escaped:
+				escaped = false
+			case rest[i] == '\\':
+				escaped = true
+			case rest[i] == ']' && (i+1 == len(rest) || rest[i+1] == ' '):
+				break Loop
+			}
+		}
+		if i == len(rest) {
+			return m, errors.New("invalid structured data: " + msg)
+		}
+		m.StructuredData = rest[:i+1]
+		rest = rest[i+1:]
+	}
+	m.Text = strings.TrimPrefix(rest, " ")
+	return m, nil
+}
@@ -131,11 +131,12 @@ func (s *Service) Handler(l *log.Logger) alert.Handler {
 	}
 }
 
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
 	if err := h.s.Alert(
 		event.State.ID,
 		event.State.Message,
 	); err != nil {
-		h.logger.Println("E! failed to send event to Talk", err)
+		return fmt.Errorf("failed to send event to Talk: %v", err)
 	}
+	return nil
 }
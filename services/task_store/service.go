@@ -15,6 +15,7 @@ import (
 
 	"github.com/boltdb/bolt"
 	"github.com/influxdata/kapacitor"
+	"github.com/influxdata/kapacitor/auth"
 	"github.com/influxdata/kapacitor/client/v1"
 	"github.com/influxdata/kapacitor/server/vars"
 	"github.com/influxdata/kapacitor/services/httpd"
@@ -120,14 +121,16 @@ func (ts *Service) Open() error {
 			HandlerFunc: ts.handleUpdateTask,
 		},
 		{
-			Method:      "GET",
-			Pattern:     tasksPath,
-			HandlerFunc: ts.handleListTasks,
+			Method:              "GET",
+			Pattern:             tasksPath,
+			HandlerFunc:         ts.handleListTasks,
+			ScopedAuthorization: true,
 		},
 		{
-			Method:      "POST",
-			Pattern:     tasksPath,
-			HandlerFunc: ts.handleCreateTask,
+			Method:              "POST",
+			Pattern:             tasksPath,
+			HandlerFunc:         ts.handleCreateTask,
+			ScopedAuthorization: true,
 		},
 		{
 			Method:      "GET",
@@ -151,14 +154,16 @@ func (ts *Service) Open() error {
 			HandlerFunc: ts.handleUpdateTemplate,
 		},
 		{
-			Method:      "GET",
-			Pattern:     templatesPath,
-			HandlerFunc: ts.handleListTemplates,
+			Method:              "GET",
+			Pattern:             templatesPath,
+			HandlerFunc:         ts.handleListTemplates,
+			ScopedAuthorization: true,
 		},
 		{
-			Method:      "POST",
-			Pattern:     templatesPath,
-			HandlerFunc: ts.handleCreateTemplate,
+			Method:              "POST",
+			Pattern:             templatesPath,
+			HandlerFunc:         ts.handleCreateTemplate,
+			ScopedAuthorization: true,
 		},
 	}
 
@@ -499,7 +504,7 @@ func (ts *Service) taskLink(id string) client.Link {
 	return client.Link{Relation: client.Self, Href: path.Join(httpd.BasePath, tasksPath, id)}
 }
 
-func (ts *Service) handleListTasks(w http.ResponseWriter, r *http.Request) {
+func (ts *Service) handleListTasks(w http.ResponseWriter, r *http.Request, user auth.User) {
 
 	pattern := r.URL.Query().Get("pattern")
 	fields := r.URL.Query()["fields"]
@@ -553,7 +558,7 @@ func (ts *Service) handleListTasks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	rawTasks, err := ts.tasks.List(pattern, int(offset), int(limit))
+	rawTasks, err := ts.listTasks(user, pattern, int(offset), int(limit))
 	if err != nil {
 		httpd.HttpError(w, fmt.Sprintf("failed to list tasks with pattern %q: %s", pattern, err), true, http.StatusBadRequest)
 		return
@@ -659,9 +664,34 @@ func (ts *Service) handleListTasks(w http.ResponseWriter, r *http.Request) {
 	w.Write(httpd.MarshalJSON(response{tasks}, true))
 }
 
+// listTasks lists the tasks the user is authorized to read.
+func (ts *Service) listTasks(user auth.User, pattern string, offset, limit int) ([]Task, error) {
+	if authorizeResource(user, tasksResource, auth.ReadPrivilege) == nil {
+		return ts.tasks.List(pattern, offset, limit)
+	}
+	// Page through the matching tasks, skipping those the user is not authorized to read.
+	var authorized []Task
+	for o := 0; limit < 0 || len(authorized) < offset+limit; o += listPageSize {
+		tasks, err := ts.tasks.List(pattern, o, listPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			if authorizeResource(user, taskResource(task.ID), auth.ReadPrivilege) == nil {
+				authorized = append(authorized, task)
+			}
+		}
+		if len(tasks) != listPageSize {
+			break
+		}
+	}
+	i, j := bounds(len(authorized), offset, limit)
+	return authorized[i:j], nil
+}
+
 var validTaskID = regexp.MustCompile(`^[-\._\p{L}0-9]+$`)
 
-func (ts *Service) handleCreateTask(w http.ResponseWriter, r *http.Request) {
+func (ts *Service) handleCreateTask(w http.ResponseWriter, r *http.Request, user auth.User) {
 	task := client.CreateTaskOptions{}
 	dec := json.NewDecoder(r.Body)
 	err := dec.Decode(&task)
@@ -676,6 +706,10 @@ func (ts *Service) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 		httpd.HttpError(w, fmt.Sprintf("task ID must contain only letters, numbers, '-', '.' and '_'. %q", task.ID), true, http.StatusBadRequest)
 		return
 	}
+	if err := authorizeResource(user, taskResource(task.ID), auth.WritePrivilege); err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusForbidden)
+		return
+	}
 
 	newTask := Task{
 		ID: task.ID,
@@ -690,6 +724,10 @@ func (ts *Service) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 
 	// Check for template ID
 	if task.TemplateID != "" {
+		if err := authorizeResource(user, templateResource(task.TemplateID), auth.ReadPrivilege); err != nil {
+			httpd.HttpError(w, err.Error(), true, http.StatusForbidden)
+			return
+		}
 		template, err := ts.templates.Get(task.TemplateID)
 		if err != nil {
 			httpd.HttpError(w, fmt.Sprintf("unknown template %s: err: %s", task.TemplateID, err), true, http.StatusBadRequest)
@@ -803,7 +841,7 @@ func (ts *Service) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 	w.Write(httpd.MarshalJSON(t, true))
 }
 
-func (ts *Service) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
+func (ts *Service) handleUpdateTask(w http.ResponseWriter, r *http.Request, user auth.User) {
 	id, err := ts.taskIDFromPath(r.URL.Path)
 	if err != nil {
 		httpd.HttpError(w, err.Error(), true, http.StatusBadRequest)
@@ -827,8 +865,18 @@ func (ts *Service) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 
 	// Set ID if changing
 	if task.ID != "" {
+		if err := authorizeResource(user, taskResource(task.ID), auth.WritePrivilege); err != nil {
+			httpd.HttpError(w, err.Error(), true, http.StatusForbidden)
+			return
+		}
 		updated.ID = task.ID
 	}
+	if task.TemplateID != "" {
+		if err := authorizeResource(user, templateResource(task.TemplateID), auth.ReadPrivilege); err != nil {
+			httpd.HttpError(w, err.Error(), true, http.StatusForbidden)
+			return
+		}
+	}
 
 	if task.TemplateID != "" || updated.TemplateID != "" {
 		templateID := task.TemplateID
@@ -1440,7 +1488,7 @@ func (ts *Service) templateLink(id string) client.Link {
 	return client.Link{Relation: client.Self, Href: path.Join(httpd.BasePath, templatesPath, id)}
 }
 
-func (ts *Service) handleListTemplates(w http.ResponseWriter, r *http.Request) {
+func (ts *Service) handleListTemplates(w http.ResponseWriter, r *http.Request, user auth.User) {
 
 	pattern := r.URL.Query().Get("pattern")
 	fields := r.URL.Query()["fields"]
@@ -1483,7 +1531,7 @@ func (ts *Service) handleListTemplates(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	rawTemplates, err := ts.templates.List(pattern, int(offset), int(limit))
+	rawTemplates, err := ts.listTemplates(user, pattern, int(offset), int(limit))
 	if err != nil {
 		httpd.HttpError(w, fmt.Sprintf("failed to list templates with pattern %q: %s", pattern, err), true, http.StatusBadRequest)
 		return
@@ -1550,9 +1598,34 @@ func (ts *Service) handleListTemplates(w http.ResponseWriter, r *http.Request) {
 	w.Write(httpd.MarshalJSON(response{templates}, true))
 }
 
+// listTemplates lists the templates the user is authorized to read.
+func (ts *Service) listTemplates(user auth.User, pattern string, offset, limit int) ([]Template, error) {
+	if authorizeResource(user, templatesResource, auth.ReadPrivilege) == nil {
+		return ts.templates.List(pattern, offset, limit)
+	}
+	// Page through the matching templates, skipping those the user is not authorized to read.
+	var authorized []Template
+	for o := 0; limit < 0 || len(authorized) < offset+limit; o += listPageSize {
+		templates, err := ts.templates.List(pattern, o, listPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, template := range templates {
+			if authorizeResource(user, templateResource(template.ID), auth.ReadPrivilege) == nil {
+				authorized = append(authorized, template)
+			}
+		}
+		if len(templates) != listPageSize {
+			break
+		}
+	}
+	i, j := bounds(len(authorized), offset, limit)
+	return authorized[i:j], nil
+}
+
 var validTemplateID = regexp.MustCompile(`^[-\._\p{L}0-9]+$`)
 
-func (ts *Service) handleCreateTemplate(w http.ResponseWriter, r *http.Request) {
+func (ts *Service) handleCreateTemplate(w http.ResponseWriter, r *http.Request, user auth.User) {
 	template := client.CreateTemplateOptions{}
 	dec := json.NewDecoder(r.Body)
 	err := dec.Decode(&template)
@@ -1567,6 +1640,10 @@ func (ts *Service) handleCreateTemplate(w http.ResponseWriter, r *http.Request)
 		httpd.HttpError(w, fmt.Sprintf("template ID must contain only letters, numbers, '-', '.' and '_'. %q", template.ID), true, http.StatusBadRequest)
 		return
 	}
+	if err := authorizeResource(user, templateResource(template.ID), auth.WritePrivilege); err != nil {
+		httpd.HttpError(w, err.Error(), true, http.StatusForbidden)
+		return
+	}
 
 	newTemplate := Template{
 		ID: template.ID,
@@ -1625,7 +1702,7 @@ func (ts *Service) handleCreateTemplate(w http.ResponseWriter, r *http.Request)
 	w.Write(httpd.MarshalJSON(t, true))
 }
 
-func (ts *Service) handleUpdateTemplate(w http.ResponseWriter, r *http.Request) {
+func (ts *Service) handleUpdateTemplate(w http.ResponseWriter, r *http.Request, user auth.User) {
 	id, err := ts.templateIDFromPath(r.URL.Path)
 	if err != nil {
 		httpd.HttpError(w, err.Error(), true, http.StatusBadRequest)
@@ -1649,6 +1726,10 @@ func (ts *Service) handleUpdateTemplate(w http.ResponseWriter, r *http.Request)
 
 	// Set ID
 	if template.ID != "" {
+		if err := authorizeResource(user, templateResource(template.ID), auth.WritePrivilege); err != nil {
+			httpd.HttpError(w, err.Error(), true, http.StatusForbidden)
+			return
+		}
 		updated.ID = template.ID
 	}
 
@@ -1678,6 +1759,13 @@ func (ts *Service) handleUpdateTemplate(w http.ResponseWriter, r *http.Request)
 		httpd.HttpError(w, fmt.Sprintf("error getting associated tasks for template %s: %s", original.ID, err.Error()), true, http.StatusInternalServerError)
 		return
 	}
+	// Updating the template updates the associated tasks, so the user must be able to write them.
+	for _, taskID := range taskIds {
+		if err := authorizeResource(user, taskResource(taskID), auth.WritePrivilege); err != nil {
+			httpd.HttpError(w, fmt.Sprintf("cannot update template with associated task %s: %v", taskID, err), true, http.StatusForbidden)
+			return
+		}
+	}
 
 	// Save updated template
 	now := time.Now()
@@ -1903,3 +1991,38 @@ func (ts *Service) saveLastError(id string, errStr string) error {
 	task.Error = errStr
 	return ts.tasks.Replace(task)
 }
+
+// Number of items read at a time when filtering lists
+const listPageSize = 100
+
+var (
+	tasksResource     = httpd.APIResource(path.Join(httpd.BasePath, tasksPath))
+	templatesResource = httpd.APIResource(path.Join(httpd.BasePath, templatesPath))
+)
+
+// taskResource returns the resource that authorizes access to the task.
+func taskResource(id string) string {
+	return path.Join(tasksResource, id)
+}
+
+// templateResource returns the resource that authorizes access to the template.
+func templateResource(id string) string {
+	return path.Join(templatesResource, id)
+}
+
+func authorizeResource(user auth.User, resource string, p auth.Privilege) error {
+	return user.AuthorizeAction(auth.Action{Resource: resource, Privilege: p})
+}
+
+// bounds returns the indexes of a list of length n within the offset and limit pagination bounds.
+// A negative limit means no limit.
+func bounds(n, offset, limit int) (int, int) {
+	if offset > n {
+		offset = n
+	}
+	end := n
+	if limit >= 0 && offset+limit < n {
+		end = offset + limit
+	}
+	return offset, end
+}
@@ -0,0 +1,203 @@
+package teams
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/pkg/errors"
+)
+
+type Service struct {
+	configValue atomic.Value
+	logger      *log.Logger
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	s := &Service{
+		logger: l,
+	}
+	s.configValue.Store(c)
+	return s
+}
+
+func (s *Service) Open() error {
+	return nil
+}
+
+func (s *Service) Close() error {
+	return nil
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if l := len(newConfig); l != 1 {
+		return fmt.Errorf("expected only one new config object, got %d", l)
+	}
+	if c, ok := newConfig[0].(Config); !ok {
+		return fmt.Errorf("expected config object to be of type %T, got %T", c, newConfig[0])
+	} else {
+		s.configValue.Store(c)
+	}
+	return nil
+}
+
+func (s *Service) Global() bool {
+	c := s.config()
+	return c.Global
+}
+
+func (s *Service) StateChangesOnly() bool {
+	c := s.config()
+	return c.StateChangesOnly
+}
+
+// card is a MessageCard of the Office 365 connectors for Teams channels.
+// See https://docs.microsoft.com/en-us/outlook/actionable-messages/message-card-reference
+type card struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	ThemeColor string `json:"themeColor"`
+	Summary    string `json:"summary"`
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+}
+
+// Theme colors of the cards by level
+const (
+	okColor       = "2EB886"
+	infoColor     = "0078D7"
+	warningColor  = "EB9B1D"
+	criticalColor = "CC4A31"
+)
+
+func levelColor(level alert.Level) string {
+	switch level {
+	case alert.Info:
+		return infoColor
+	case alert.Warning:
+		return warningColor
+	case alert.Critical:
+		return criticalColor
+	default:
+		return okColor
+	}
+}
+
+type testOptions struct {
+	ChannelURL string      `json:"channel-url"`
+	AlertID    string      `json:"alert-id"`
+	Message    string      `json:"message"`
+	Level      alert.Level `json:"level"`
+}
+
+func (s *Service) TestOptions() interface{} {
+	c := s.config()
+	return &testOptions{
+		ChannelURL: c.ChannelURL,
+		AlertID:    "testAlertID",
+		Message:    "test teams message",
+		Level:      alert.Critical,
+	}
+}
+
+func (s *Service) Test(options interface{}) error {
+	o, ok := options.(*testOptions)
+	if !ok {
+		return fmt.Errorf("unexpected options type %T", options)
+	}
+	return s.Alert(o.ChannelURL, o.AlertID, o.Message, o.Level)
+}
+
+func (s *Service) Alert(channelURL, alertID, message string, level alert.Level) error {
+	url, post, err := s.preparePost(channelURL, alertID, message, level)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(url, "application/json", post)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to understand Teams response. code: %d content: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (s *Service) preparePost(channelURL, alertID, message string, level alert.Level) (string, io.Reader, error) {
+	c := s.config()
+
+	if !c.Enabled {
+		return "", nil, errors.New("service is not enabled")
+	}
+	if channelURL == "" {
+		channelURL = c.ChannelURL
+	}
+	if channelURL == "" {
+		return "", nil, errors.New("no channel-url specified")
+	}
+
+	mc := card{
+		Type:       "MessageCard",
+		Context:    "http://schema.org/extensions",
+		ThemeColor: levelColor(level),
+		Summary:    message,
+		Title:      fmt.Sprintf("%s: %s", level, alertID),
+		Text:       message,
+	}
+
+	var post bytes.Buffer
+	enc := json.NewEncoder(&post)
+	err := enc.Encode(mc)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return channelURL, &post, nil
+}
+
+type HandlerConfig struct {
+	// Incoming webhook URL of the Teams channel in which to post messages.
+	// If empty uses the channel URL from the configuration.
+	ChannelURL string `mapstructure:"channel-url"`
+}
+
+type handler struct {
+	s      *Service
+	c      HandlerConfig
+	logger *log.Logger
+}
+
+func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
+	return &handler{
+		s:      s,
+		c:      c,
+		logger: l,
+	}
+}
+
+func (h *handler) Handle(event alert.Event) error {
+	if err := h.s.Alert(
+		h.c.ChannelURL,
+		event.State.ID,
+		event.State.Message,
+		event.State.Level,
+	); err != nil {
+		return fmt.Errorf("failed to send event to Teams: %v", err)
+	}
+	return nil
+}
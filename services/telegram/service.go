@@ -211,7 +211,7 @@ func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
 	}
 }
 
-func (h *handler) Handle(event alert.Event) {
+func (h *handler) Handle(event alert.Event) error {
 	if err := h.s.Alert(
 		h.c.ChatId,
 		h.c.ParseMode,
@@ -219,6 +219,7 @@ func (h *handler) Handle(event alert.Event) {
 		h.c.DisableWebPagePreview,
 		h.c.DisableNotification,
 	); err != nil {
-		h.logger.Println("E! failed to send event to Telegram", err)
+		return fmt.Errorf("failed to send event to Telegram: %v", err)
 	}
+	return nil
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/influxdata/kapacitor"
 	"github.com/influxdata/kapacitor/command"
+	"github.com/influxdata/kapacitor/services/blobstore"
 	"github.com/influxdata/kapacitor/udf"
 )
 
@@ -17,6 +18,13 @@ type Service struct {
 	infos   map[string]udf.Info
 	logger  *log.Logger
 	mu      sync.RWMutex
+
+	BlobStoreService interface {
+		CreateBlob(data []byte) (blobstore.Blob, error)
+		Blob(id string) (blobstore.Blob, error)
+		BlobByTag(name string) (blobstore.Blob, error)
+		TagBlob(name, id string) (blobstore.Tag, error)
+	}
 }
 
 func NewService(c Config, l *log.Logger) *Service {
@@ -72,6 +80,7 @@ func (s *Service) Create(
 		return kapacitor.NewUDFSocket(
 			taskID, nodeID,
 			kapacitor.NewSocketConn(conf.Socket),
+			s.blobStore(),
 			l,
 			time.Duration(conf.Timeout),
 			abortCallback,
@@ -91,6 +100,7 @@ func (s *Service) Create(
 			taskID, nodeID,
 			command.ExecCommander,
 			cmdSpec,
+			s.blobStore(),
 			l,
 			time.Duration(conf.Timeout),
 			abortCallback,
@@ -129,3 +139,43 @@ func (s *Service) loadUDFInfo(name string) (udf.Info, error) {
 	}
 	return info, nil
 }
+
+// blobStore returns the udf.BlobStore for UDFs to use, or nil if no blob store is available.
+func (s *Service) blobStore() udf.BlobStore {
+	if s.BlobStoreService == nil {
+		return nil
+	}
+	return blobStore{s: s}
+}
+
+// blobStore implements udf.BlobStore on top of the BlobStoreService.
+type blobStore struct {
+	s *Service
+}
+
+func (b blobStore) GetBlob(id, tag string) (string, []byte, error) {
+	var blob blobstore.Blob
+	var err error
+	if id != "" {
+		blob, err = b.s.BlobStoreService.Blob(id)
+	} else {
+		blob, err = b.s.BlobStoreService.BlobByTag(tag)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return blob.ID, blob.Data, nil
+}
+
+func (b blobStore) SaveBlob(data []byte, tag string) (string, error) {
+	blob, err := b.s.BlobStoreService.CreateBlob(data)
+	if err != nil {
+		return "", err
+	}
+	if tag != "" {
+		if _, err := b.s.BlobStoreService.TagBlob(tag, blob.ID); err != nil {
+			return "", err
+		}
+	}
+	return blob.ID, nil
+}
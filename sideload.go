@@ -0,0 +1,192 @@
+package kapacitor
+
+import (
+	"fmt"
+	"log"
+	text "text/template"
+
+	"github.com/influxdata/kapacitor/bufpool"
+	"github.com/influxdata/kapacitor/edge"
+	"github.com/influxdata/kapacitor/models"
+	"github.com/influxdata/kapacitor/pipeline"
+	"github.com/influxdata/kapacitor/services/sideload"
+	"github.com/pkg/errors"
+)
+
+type SideloadNode struct {
+	node
+	s          *pipeline.SideloadNode
+	source     sideload.Source
+	orderTmpls []*text.Template
+
+	order []string
+
+	bufferPool *bufpool.Pool
+}
+
+// Create a new SideloadNode which loads fields and tags from external sources.
+func newSideloadNode(et *ExecutingTask, n *pipeline.SideloadNode, l *log.Logger) (*SideloadNode, error) {
+	if et.tm.SideloadService == nil {
+		return nil, errors.New("no sideload service available")
+	}
+	sn := &SideloadNode{
+		node:       node{Node: n, et: et, logger: l},
+		s:          n,
+		bufferPool: bufpool.New(),
+		order:      make([]string, len(n.OrderList)),
+		orderTmpls: make([]*text.Template, len(n.OrderList)),
+	}
+	for i, o := range n.OrderList {
+		tmpl, err := text.New("order").Option("missingkey=error").Parse(o)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid order template %q", o)
+		}
+		sn.orderTmpls[i] = tmpl
+	}
+	sn.node.runF = sn.runSideload
+	return sn, nil
+}
+
+func (n *SideloadNode) runSideload([]byte) error {
+	// Open the source only once the node runs,
+	// so that a task which fails to start does not hold a reference to it.
+	src, err := n.et.tm.SideloadService.Source(n.s.Source)
+	if err != nil {
+		return err
+	}
+	n.source = src
+	defer n.source.Close()
+
+	consumer := edge.NewConsumerWithReceiver(
+		n.ins[0],
+		edge.NewReceiverFromForwardReceiverWithStats(
+			n.outs,
+			edge.NewTimedForwardReceiver(n.timer, n),
+		),
+	)
+	return consumer.Consume()
+}
+
+func (n *SideloadNode) BeginBatch(begin edge.BeginBatchMessage) (edge.Message, error) {
+	return begin, nil
+}
+
+func (n *SideloadNode) BatchPoint(bp edge.BatchPointMessage) (edge.Message, error) {
+	bp = bp.ShallowCopy()
+	fields, tags := n.doSideload(bp.Fields(), bp.Tags())
+	bp.SetFields(fields)
+	bp.SetTags(tags)
+	return bp, nil
+}
+
+func (n *SideloadNode) EndBatch(end edge.EndBatchMessage) (edge.Message, error) {
+	return end, nil
+}
+
+func (n *SideloadNode) Point(p edge.PointMessage) (edge.Message, error) {
+	p = p.ShallowCopy()
+	fields, tags := n.doSideload(p.Fields(), p.Tags())
+	p.SetFields(fields)
+	p.SetTags(tags)
+	return p, nil
+}
+
+func (n *SideloadNode) Barrier(b edge.BarrierMessage) (edge.Message, error) {
+	return b, nil
+}
+func (n *SideloadNode) DeleteGroup(d edge.DeleteGroupMessage) (edge.Message, error) {
+	return d, nil
+}
+
+// doSideload returns copies of the fields and tags with the sideloaded values set.
+func (n *SideloadNode) doSideload(fields models.Fields, tags models.Tags) (models.Fields, models.Tags) {
+	order := n.renderOrder(tags)
+	hasErr := false
+
+	if len(n.s.Fields) > 0 {
+		fields = fields.Copy()
+		for key, dflt := range n.s.Fields {
+			value := dflt
+			if v := n.source.Lookup(order, key); v != nil {
+				converted, err := convertSideloadField(v, dflt)
+				if err != nil {
+					n.logger.Printf("E! failed to load field %q: %v", key, err)
+					hasErr = true
+				} else {
+					value = converted
+				}
+			}
+			fields[key] = value
+		}
+	}
+	if len(n.s.Tags) > 0 {
+		tags = tags.Copy()
+		for key, dflt := range n.s.Tags {
+			value := dflt
+			if v := n.source.Lookup(order, key); v != nil {
+				switch v := v.(type) {
+				case string:
+					value = v
+				case int64, float64, bool:
+					value = fmt.Sprint(v)
+				default:
+					n.logger.Printf("E! failed to load tag %q: unsupported type %T", key, v)
+					hasErr = true
+				}
+			}
+			tags[key] = value
+		}
+	}
+	if hasErr {
+		n.incrementErrorCount()
+	}
+	return fields, tags
+}
+
+// renderOrder renders the order templates using the tags of a point.
+// Paths that reference missing tags are left out of the order.
+// The returned slice is only valid until the next call.
+func (n *SideloadNode) renderOrder(tags models.Tags) []string {
+	n.order = n.order[:0]
+	buf := n.bufferPool.Get()
+	defer n.bufferPool.Put(buf)
+	for _, tmpl := range n.orderTmpls {
+		buf.Reset()
+		if err := tmpl.Execute(buf, tags); err != nil {
+			continue
+		}
+		n.order = append(n.order, buf.String())
+	}
+	return n.order
+}
+
+// convertSideloadField converts a sideloaded value to the type of the field default.
+func convertSideloadField(v, dflt interface{}) (interface{}, error) {
+	switch dflt.(type) {
+	case float64:
+		switch v := v.(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return float64(v), nil
+		}
+	case int64:
+		switch v := v.(type) {
+		case int64:
+			return v, nil
+		case float64:
+			if i := int64(v); float64(i) == v {
+				return i, nil
+			}
+		}
+	case bool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case string:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot use value of type %T as %T", v, dflt)
+}
@@ -0,0 +1,151 @@
+package kapacitor
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/kapacitor/edge"
+	"github.com/influxdata/kapacitor/models"
+)
+
+// The following types and functions are used by nodes to snapshot and restore their running state.
+// Snapshots are gob encoded, as such changes to the snapshot structures
+// could break restoring existing snapshots.
+
+// encodeSnapshot encodes the snapshot state of a node.
+func encodeSnapshot(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeSnapshot decodes the snapshot state of a node.
+func decodeSnapshot(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// pointSnapshot is the serialized form of a point or a batch point.
+type pointSnapshot struct {
+	Name            string
+	Database        string
+	RetentionPolicy string
+	Dimensions      models.Dimensions
+	Tags            models.Tags
+	Fields          models.Fields
+	Time            time.Time
+}
+
+func newPointSnapshot(p edge.PointMessage) pointSnapshot {
+	return pointSnapshot{
+		Name:            p.Name(),
+		Database:        p.Database(),
+		RetentionPolicy: p.RetentionPolicy(),
+		Dimensions:      p.Dimensions(),
+		Tags:            p.Tags(),
+		Fields:          p.Fields(),
+		Time:            p.Time(),
+	}
+}
+
+func newBatchPointSnapshot(p edge.FieldsTagsTimeGetter) pointSnapshot {
+	return pointSnapshot{
+		Tags:   p.Tags(),
+		Fields: p.Fields(),
+		Time:   p.Time(),
+	}
+}
+
+func (p pointSnapshot) point() edge.PointMessage {
+	return edge.NewPointMessage(
+		p.Name,
+		p.Database,
+		p.RetentionPolicy,
+		p.Dimensions,
+		p.Fields,
+		p.Tags,
+		p.Time,
+	)
+}
+
+func (p pointSnapshot) batchPoint() edge.BatchPointMessage {
+	return edge.NewBatchPointMessage(
+		p.Fields,
+		p.Tags,
+		p.Time,
+	)
+}
+
+// batchSnapshot is the serialized form of a buffered batch.
+type batchSnapshot struct {
+	Name   string
+	Tags   models.Tags
+	ByName bool
+	TMax   time.Time
+	Points []pointSnapshot
+}
+
+func newBatchSnapshot(b edge.BufferedBatchMessage) batchSnapshot {
+	points := make([]pointSnapshot, len(b.Points()))
+	for i, bp := range b.Points() {
+		points[i] = newBatchPointSnapshot(bp)
+	}
+	return batchSnapshot{
+		Name:   b.Name(),
+		Tags:   b.Tags(),
+		ByName: b.Dimensions().ByName,
+		TMax:   b.Time(),
+		Points: points,
+	}
+}
+
+func (b batchSnapshot) batch() edge.BufferedBatchMessage {
+	points := make([]edge.BatchPointMessage, len(b.Points))
+	for i, p := range b.Points {
+		points[i] = p.batchPoint()
+	}
+	return edge.NewBufferedBatchMessage(
+		edge.NewBeginBatchMessage(
+			b.Name,
+			b.Tags,
+			b.ByName,
+			b.TMax,
+			len(points),
+		),
+		points,
+		edge.NewEndBatchMessage(),
+	)
+}
+
+// messageSnapshot is the serialized form of either a point or a buffered batch.
+type messageSnapshot struct {
+	Point *pointSnapshot
+	Batch *batchSnapshot
+}
+
+func newMessageSnapshot(m edge.Message) (messageSnapshot, error) {
+	switch msg := m.(type) {
+	case edge.PointMessage:
+		p := newPointSnapshot(msg)
+		return messageSnapshot{Point: &p}, nil
+	case edge.BufferedBatchMessage:
+		b := newBatchSnapshot(msg)
+		return messageSnapshot{Batch: &b}, nil
+	default:
+		return messageSnapshot{}, fmt.Errorf("cannot snapshot message of type %T", m)
+	}
+}
+
+func (m messageSnapshot) message() (edge.Message, error) {
+	switch {
+	case m.Point != nil:
+		return m.Point.point(), nil
+	case m.Batch != nil:
+		return m.Batch.batch(), nil
+	default:
+		return nil, fmt.Errorf("empty message snapshot")
+	}
+}
@@ -0,0 +1,369 @@
+package kapacitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/edge"
+	"github.com/influxdata/kapacitor/expvar"
+	"github.com/influxdata/kapacitor/models"
+	"github.com/influxdata/kapacitor/pipeline"
+	"github.com/influxdata/kapacitor/tick/stateful"
+	"github.com/influxdata/kapacitor/timer"
+	"github.com/stretchr/testify/assert"
+)
+
+type noDeadman struct{}
+
+func (noDeadman) Interval() time.Duration { return 0 }
+func (noDeadman) Threshold() float64      { return 0 }
+func (noDeadman) Id() string              { return "" }
+func (noDeadman) Message() string         { return "" }
+func (noDeadman) Global() bool            { return false }
+
+// lastPipelineNode returns the last node of the pipeline created from the script.
+func lastPipelineNode(t *testing.T, script string) pipeline.Node {
+	p, err := pipeline.CreatePipeline(script, pipeline.StreamEdge, stateful.NewScope(), noDeadman{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last pipeline.Node
+	p.Walk(func(n pipeline.Node) error {
+		last = n
+		return nil
+	})
+	return last
+}
+
+// initTestNode prepares a node to run outside of a task, returning its output edge.
+func initTestNode(n *node, parents int) edge.StatsEdge {
+	out := edge.NewStatsEdge(edge.NewChannelEdge(pipeline.StreamEdge, 10))
+	n.ins = make([]edge.StatsEdge, parents)
+	n.outs = []edge.StatsEdge{out}
+	n.timer = timer.NewNoOp()
+	n.nodeErrors = new(expvar.Int)
+	return out
+}
+
+// emitted closes the edge and returns all messages that were sent on it.
+func emitted(out edge.StatsEdge) []edge.Message {
+	out.Close()
+	var msgs []edge.Message
+	for m, ok := out.Emit(); ok; m, ok = out.Emit() {
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+func TestDerivativeNode_SnapshotRestore(t *testing.T) {
+	group := edge.GroupInfo{ID: "group"}
+	newNode := func() (*DerivativeNode, edge.StatsEdge) {
+		dn := lastPipelineNode(t, `stream|from().measurement('cpu')|derivative('value')`).(*pipeline.DerivativeNode)
+		n, err := newDerivativeNode(nil, dn, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n, initTestNode(&n.node, 1)
+	}
+	point := func(i int) edge.PointMessage {
+		return edge.NewPointMessage(
+			"cpu", "db", "rp",
+			models.Dimensions{},
+			models.Fields{"value": float64(i * i)},
+			nil,
+			time.Unix(int64(i), 0).UTC(),
+		)
+	}
+
+	n, out := newNode()
+	r, err := n.NewGroup(group, point(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		if err := r.Point(point(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := n.snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, restoredOut := newNode()
+	if err := restored.restore(data); err != nil {
+		t.Fatal(err)
+	}
+	restoredR, err := restored.NewGroup(group, point(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Point(point(3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := restoredR.Point(point(3)); err != nil {
+		t.Fatal(err)
+	}
+	exp := emitted(out)
+	got := emitted(restoredOut)
+	if len(exp) != 2 {
+		t.Fatalf("expected 2 derivatives got %d", len(exp))
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 derivative from restored node got %d", len(got))
+	}
+	assert.Equal(t, exp[1], got[0])
+	assert.Equal(t, 5.0, got[0].(edge.PointMessage).Fields()["value"])
+}
+
+func TestStateTrackingNode_SnapshotRestore(t *testing.T) {
+	testCases := []struct {
+		name   string
+		script string
+		newF   func(pipeline.Node) (*StateTrackingNode, error)
+		field  string
+		exp    interface{}
+	}{
+		{
+			name:   "stateDuration",
+			script: `stream|from().measurement('cpu')|stateDuration(lambda: "value" > 50)`,
+			newF: func(n pipeline.Node) (*StateTrackingNode, error) {
+				return newStateDurationNode(nil, n.(*pipeline.StateDurationNode), logger)
+			},
+			field: "state_duration",
+			exp:   2.0,
+		},
+		{
+			name:   "stateCount",
+			script: `stream|from().measurement('cpu')|stateCount(lambda: "value" > 50)`,
+			newF: func(n pipeline.Node) (*StateTrackingNode, error) {
+				return newStateCountNode(nil, n.(*pipeline.StateCountNode), logger)
+			},
+			field: "state_count",
+			exp:   int64(3),
+		},
+	}
+	group := edge.GroupInfo{ID: "group"}
+	point := func(i int) edge.PointMessage {
+		return edge.NewPointMessage(
+			"cpu", "db", "rp",
+			models.Dimensions{},
+			models.Fields{"value": float64(50 + i)},
+			nil,
+			time.Unix(int64(i), 0).UTC(),
+		)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			newNode := func() (*StateTrackingNode, edge.StatsEdge) {
+				n, err := tc.newF(lastPipelineNode(t, tc.script))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return n, initTestNode(&n.node, 1)
+			}
+
+			n, out := newNode()
+			r, err := n.NewGroup(group, point(1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 1; i <= 2; i++ {
+				if err := r.Point(point(i)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			data, err := n.snapshot()
+			if err != nil {
+				t.Fatal(err)
+			}
+			restored, restoredOut := newNode()
+			if err := restored.restore(data); err != nil {
+				t.Fatal(err)
+			}
+			restoredR, err := restored.NewGroup(group, point(3))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := r.Point(point(3)); err != nil {
+				t.Fatal(err)
+			}
+			if err := restoredR.Point(point(3)); err != nil {
+				t.Fatal(err)
+			}
+			exp := emitted(out)
+			got := emitted(restoredOut)
+			if len(exp) != 3 || len(got) != 1 {
+				t.Fatalf("unexpected number of points, got %d and %d from restored node", len(exp), len(got))
+			}
+			assert.Equal(t, exp[2], got[0])
+			assert.Equal(t, tc.exp, got[0].(edge.PointMessage).Fields()[tc.field])
+		})
+	}
+}
+
+func TestJoinNode_SnapshotRestore(t *testing.T) {
+	testCases := []struct {
+		name   string
+		script string
+		// Points sent before the snapshot
+		before []srcPoint
+		// Points sent after the snapshot
+		after []srcPoint
+	}{
+		{
+			name: "sets",
+			script: `
+var a = stream|from().measurement('a')
+var b = stream|from().measurement('b')
+a|join(b).as('a', 'b')
+`,
+			before: []srcPoint{
+				{Src: 0, Msg: edge.NewPointMessage("a", "db", "rp", models.Dimensions{}, models.Fields{"value": 1.0}, nil, time.Unix(0, 0).UTC())},
+				{Src: 0, Msg: edge.NewPointMessage("a", "db", "rp", models.Dimensions{}, models.Fields{"value": 2.0}, nil, time.Unix(10, 0).UTC())},
+			},
+			after: []srcPoint{
+				{Src: 1, Msg: edge.NewPointMessage("b", "db", "rp", models.Dimensions{}, models.Fields{"value": 3.0}, nil, time.Unix(0, 0).UTC())},
+			},
+		},
+		{
+			name: "buffers",
+			script: `
+var a = stream|from().measurement('a').groupBy('host', 'cpu')
+var b = stream|from().measurement('b').groupBy('host')
+a|join(b).as('a', 'b').on('host')
+`,
+			before: []srcPoint{
+				{Src: 0, Msg: edge.NewPointMessage(
+					"a", "db", "rp",
+					models.Dimensions{TagNames: []string{"cpu", "host"}},
+					models.Fields{"value": 1.0},
+					models.Tags{"host": "serverA", "cpu": "cpu0"},
+					time.Unix(0, 0).UTC(),
+				)},
+			},
+			after: []srcPoint{
+				{Src: 1, Msg: edge.NewPointMessage(
+					"b", "db", "rp",
+					models.Dimensions{TagNames: []string{"host"}},
+					models.Fields{"value": 2.0},
+					models.Tags{"host": "serverA"},
+					time.Unix(0, 0).UTC(),
+				)},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			newNode := func() (*JoinNode, edge.StatsEdge) {
+				n, err := newJoinNode(nil, lastPipelineNode(t, tc.script).(*pipeline.JoinNode), logger)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return n, initTestNode(&n.node, 2)
+			}
+			send := func(n *JoinNode, points []srcPoint) {
+				for _, p := range points {
+					if err := n.Point(p.Src, p.Msg.(edge.PointMessage)); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			n, out := newNode()
+			send(n, tc.before)
+
+			data, err := n.snapshot()
+			if err != nil {
+				t.Fatal(err)
+			}
+			restored, restoredOut := newNode()
+			if err := restored.restore(data); err != nil {
+				t.Fatal(err)
+			}
+
+			send(n, tc.after)
+			send(restored, tc.after)
+			exp := emitted(out)
+			got := emitted(restoredOut)
+			if len(exp) != 1 {
+				t.Fatalf("expected 1 joined point got %d", len(exp))
+			}
+			assert.Equal(t, exp, got)
+		})
+	}
+}
+
+func TestAlertNode_SnapshotRestore(t *testing.T) {
+	newNode := func(history int64) *AlertNode {
+		return &AlertNode{
+			a:        &pipeline.AlertNode{History: history},
+			states:   make(map[models.GroupID]*alertState),
+			restored: make(map[models.GroupID]alertStateSnapshot),
+		}
+	}
+	levels := []alert.Level{alert.OK, alert.Warning, alert.Critical, alert.Critical}
+
+	n := newNode(3)
+	state := n.newAlertState()
+	for i, l := range levels {
+		state.addEvent(time.Unix(int64(i), 0).UTC(), l)
+		state.triggered(time.Unix(int64(i), 0).UTC())
+	}
+	n.states["group"] = state
+
+	data, err := n.snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name        string
+		history     int64
+		expHistory  []alert.Level
+		expDuration time.Duration
+	}{
+		{
+			name:        "same history",
+			history:     3,
+			expHistory:  []alert.Level{alert.Warning, alert.Critical, alert.Critical},
+			expDuration: 2 * time.Second,
+		},
+		{
+			name:        "smaller history",
+			history:     2,
+			expHistory:  []alert.Level{alert.Critical, alert.Critical},
+			expDuration: 2 * time.Second,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			restored := newNode(tc.history)
+			if err := restored.restore(data); err != nil {
+				t.Fatal(err)
+			}
+			s, ok := restored.restored["group"]
+			if !ok {
+				t.Fatal("expected restored state for group")
+			}
+			rs := restored.newAlertState()
+			rs.restore(s)
+
+			assert.Equal(t, state.currentLevel(), rs.currentLevel())
+			assert.Equal(t, tc.expDuration, rs.duration())
+			assert.Equal(t, state.flapping, rs.flapping)
+			assert.Equal(t, state.changed, rs.changed)
+
+			// History is ordered oldest to newest, ending at the current index.
+			var history []alert.Level
+			for i := 1; i <= len(rs.history); i++ {
+				history = append(history, rs.history[(rs.idx+i)%len(rs.history)])
+			}
+			assert.Equal(t, tc.expHistory, history)
+		})
+	}
+}
@@ -3,21 +3,40 @@ package kapacitor
 import (
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/influxdata/kapacitor/edge"
+	"github.com/influxdata/kapacitor/models"
 	"github.com/influxdata/kapacitor/pipeline"
 	"github.com/influxdata/kapacitor/tick/ast"
 	"github.com/influxdata/kapacitor/tick/stateful"
+	"github.com/pkg/errors"
 )
 
 type stateTracker interface {
 	track(t time.Time, inState bool) interface{}
 	reset()
+	state() stateTrackerState
+	restore(stateTrackerState)
+}
+
+// stateTrackerState is the snapshot state of a stateTracker.
+type stateTrackerState struct {
+	// Used by the state duration tracker.
+	StartTime time.Time
+	// Used by the state count tracker.
+	Count int64
+}
+
+// stateTrackingSnapshot is the snapshot state of a StateTrackingNode.
+type stateTrackingSnapshot struct {
+	Trackers map[models.GroupID]stateTrackerState
 }
 
 type stateTrackingGroup struct {
-	n *StateTrackingNode
+	n  *StateTrackingNode
+	id models.GroupID
 	stateful.Expression
 	tracker stateTracker
 }
@@ -30,9 +49,20 @@ type StateTrackingNode struct {
 	scopePool stateful.ScopePool
 
 	newTracker func() stateTracker
+
+	// mu protects the groups so that they can be snapshotted while the node is running.
+	mu     sync.Mutex
+	groups map[models.GroupID]*stateTrackingGroup
+	// Restored tracker states for groups that have not yet been seen.
+	restored map[models.GroupID]stateTrackerState
 }
 
-func (n *StateTrackingNode) runStateTracking(_ []byte) error {
+func (n *StateTrackingNode) runStateTracking(snapshot []byte) error {
+	if snapshot != nil {
+		if err := n.restore(snapshot); err != nil {
+			return errors.Wrap(err, "failed to restore state tracking snapshot")
+		}
+	}
 	consumer := edge.NewGroupedConsumer(
 		n.ins[0],
 		n,
@@ -41,10 +71,47 @@ func (n *StateTrackingNode) runStateTracking(_ []byte) error {
 	return consumer.Consume()
 }
 
+func (n *StateTrackingNode) snapshot() ([]byte, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	s := stateTrackingSnapshot{
+		Trackers: make(map[models.GroupID]stateTrackerState, len(n.groups)+len(n.restored)),
+	}
+	for id, state := range n.restored {
+		s.Trackers[id] = state
+	}
+	for id, g := range n.groups {
+		s.Trackers[id] = g.tracker.state()
+	}
+	return encodeSnapshot(s)
+}
+
+func (n *StateTrackingNode) restore(data []byte) error {
+	var s stateTrackingSnapshot
+	if err := decodeSnapshot(data, &s); err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for id, state := range s.Trackers {
+		n.restored[id] = state
+	}
+	return nil
+}
+
 func (n *StateTrackingNode) NewGroup(group edge.GroupInfo, first edge.PointMeta) (edge.Receiver, error) {
+	g := n.newGroup()
+	g.id = group.ID
+	n.mu.Lock()
+	if state, ok := n.restored[group.ID]; ok {
+		g.tracker.restore(state)
+		delete(n.restored, group.ID)
+	}
+	n.groups[group.ID] = g
+	n.mu.Unlock()
 	return edge.NewReceiverFromForwardReceiverWithStats(
 		n.outs,
-		edge.NewTimedForwardReceiver(n.timer, n.newGroup()),
+		edge.NewTimedForwardReceiver(n.timer, edge.NewLockedForwardReceiver(&n.mu, g)),
 	), nil
 }
 
@@ -103,10 +170,16 @@ func (g *stateTrackingGroup) track(p edge.FieldsTagsTimeSetter) error {
 	return nil
 }
 
+// Barrier resets the tracked state if the group is idle,
+// so that the state of a group that stopped receiving data does not continue when it receives data again.
 func (g *stateTrackingGroup) Barrier(b edge.BarrierMessage) (edge.Message, error) {
+	if b.Idle() {
+		g.tracker.reset()
+	}
 	return b, nil
 }
 func (g *stateTrackingGroup) DeleteGroup(d edge.DeleteGroupMessage) (edge.Message, error) {
+	delete(g.n.groups, g.id)
 	return d, nil
 }
 
@@ -120,6 +193,14 @@ func (sdt *stateDurationTracker) reset() {
 	sdt.startTime = time.Time{}
 }
 
+func (sdt *stateDurationTracker) state() stateTrackerState {
+	return stateTrackerState{StartTime: sdt.startTime}
+}
+
+func (sdt *stateDurationTracker) restore(s stateTrackerState) {
+	sdt.startTime = s.StartTime
+}
+
 func (sdt *stateDurationTracker) track(t time.Time, inState bool) interface{} {
 	if !inState {
 		sdt.startTime = time.Time{}
@@ -147,6 +228,8 @@ func newStateDurationNode(et *ExecutingTask, sd *pipeline.StateDurationNode, l *
 		newTracker: func() stateTracker { return &stateDurationTracker{sd: sd} },
 		expr:       expr,
 		scopePool:  stateful.NewScopePool(ast.FindReferenceVariables(sd.Lambda.Expression)),
+		groups:     make(map[models.GroupID]*stateTrackingGroup),
+		restored:   make(map[models.GroupID]stateTrackerState),
 	}
 	n.node.runF = n.runStateTracking
 	return n, nil
@@ -160,6 +243,14 @@ func (sct *stateCountTracker) reset() {
 	sct.count = 0
 }
 
+func (sct *stateCountTracker) state() stateTrackerState {
+	return stateTrackerState{Count: sct.count}
+}
+
+func (sct *stateCountTracker) restore(s stateTrackerState) {
+	sct.count = s.Count
+}
+
 func (sct *stateCountTracker) track(t time.Time, inState bool) interface{} {
 	if !inState {
 		sct.count = 0
@@ -185,6 +276,8 @@ func newStateCountNode(et *ExecutingTask, sc *pipeline.StateCountNode, l *log.Lo
 		newTracker: func() stateTracker { return &stateCountTracker{} },
 		expr:       expr,
 		scopePool:  stateful.NewScopePool(ast.FindReferenceVariables(sc.Lambda.Expression)),
+		groups:     make(map[models.GroupID]*stateTrackingGroup),
+		restored:   make(map[models.GroupID]stateTrackerState),
 	}
 	n.node.runF = n.runStateTracking
 	return n, nil
@@ -457,6 +457,8 @@ func (et *ExecutingTask) createNode(p pipeline.Node, l *log.Logger) (n Node, err
 		n, err = newHTTPOutNode(et, t, l)
 	case *pipeline.HTTPPostNode:
 		n, err = newHTTPPostNode(et, t, l)
+	case *pipeline.KafkaOutNode:
+		n, err = newKafkaOutNode(et, t, l)
 	case *pipeline.InfluxDBOutNode:
 		n, err = newInfluxDBOutNode(et, t, l)
 	case *pipeline.KapacitorLoopbackNode:
@@ -479,6 +481,8 @@ func (et *ExecutingTask) createNode(p pipeline.Node, l *log.Logger) (n Node, err
 		n, err = newSampleNode(et, t, l)
 	case *pipeline.DerivativeNode:
 		n, err = newDerivativeNode(et, t, l)
+	case *pipeline.ChangeDetectNode:
+		n, err = newChangeDetectNode(et, t, l)
 	case *pipeline.UDFNode:
 		n, err = newUDFNode(et, t, l)
 	case *pipeline.StatsNode:
@@ -503,6 +507,10 @@ func (et *ExecutingTask) createNode(p pipeline.Node, l *log.Logger) (n Node, err
 		n, err = newStateDurationNode(et, t, l)
 	case *pipeline.StateCountNode:
 		n, err = newStateCountNode(et, t, l)
+	case *pipeline.SideloadNode:
+		n, err = newSideloadNode(et, t, l)
+	case *pipeline.BarrierNode:
+		n, err = newBarrierNode(et, t, l)
 	default:
 		return nil, fmt.Errorf("unknown pipeline node type %T", p)
 	}
@@ -18,18 +18,24 @@ import (
 	"github.com/influxdata/kapacitor/server/vars"
 	alertservice "github.com/influxdata/kapacitor/services/alert"
 	"github.com/influxdata/kapacitor/services/alerta"
+	"github.com/influxdata/kapacitor/services/alertmanager"
 	"github.com/influxdata/kapacitor/services/hipchat"
 	"github.com/influxdata/kapacitor/services/httpd"
 	"github.com/influxdata/kapacitor/services/httppost"
+	"github.com/influxdata/kapacitor/services/jira"
 	k8s "github.com/influxdata/kapacitor/services/k8s/client"
+	"github.com/influxdata/kapacitor/services/kafka"
 	"github.com/influxdata/kapacitor/services/mqtt"
 	"github.com/influxdata/kapacitor/services/opsgenie"
 	"github.com/influxdata/kapacitor/services/pagerduty"
 	"github.com/influxdata/kapacitor/services/pushover"
 	"github.com/influxdata/kapacitor/services/sensu"
+	"github.com/influxdata/kapacitor/services/sideload"
 	"github.com/influxdata/kapacitor/services/slack"
 	"github.com/influxdata/kapacitor/services/smtp"
 	"github.com/influxdata/kapacitor/services/snmptrap"
+	"github.com/influxdata/kapacitor/services/syslog"
+	"github.com/influxdata/kapacitor/services/teams"
 	"github.com/influxdata/kapacitor/services/telegram"
 	"github.com/influxdata/kapacitor/services/victorops"
 	"github.com/influxdata/kapacitor/tick"
@@ -95,6 +101,10 @@ type TaskMaster struct {
 	MQTTService interface {
 		Handler(mqtt.HandlerConfig, *log.Logger) alert.Handler
 	}
+	KafkaService interface {
+		Handler(kafka.HandlerConfig, *log.Logger) alert.Handler
+		WriteMessages(cluster, topic string, msgs ...kafka.Message) error
+	}
 
 	OpsGenieService interface {
 		Global() bool
@@ -112,7 +122,7 @@ type TaskMaster struct {
 		Handler(pushover.HandlerConfig, *log.Logger) alert.Handler
 	}
 	HTTPPostService interface {
-		Handler(httppost.HandlerConfig, *log.Logger) alert.Handler
+		Handler(httppost.HandlerConfig, *log.Logger) (alert.Handler, error)
 		Endpoint(string) (*httppost.Endpoint, bool)
 	}
 	SlackService interface {
@@ -137,18 +147,35 @@ type TaskMaster struct {
 		DefaultHandlerConfig() alerta.HandlerConfig
 		Handler(alerta.HandlerConfig, *log.Logger) (alert.Handler, error)
 	}
+	AlertmanagerService interface {
+		Handler(alertmanager.HandlerConfig, string, alertmanager.EventStater, *log.Logger) alert.Handler
+	}
+	JiraService interface {
+		Handler(jira.HandlerConfig, jira.EventAnnotator, *log.Logger) (alert.Handler, error)
+	}
 	SensuService interface {
 		Handler(sensu.HandlerConfig, *log.Logger) (alert.Handler, error)
 	}
+	SyslogService interface {
+		Handler(syslog.HandlerConfig, *log.Logger) (alert.Handler, error)
+	}
 	TalkService interface {
 		Handler(*log.Logger) alert.Handler
 	}
+	TeamsService interface {
+		Global() bool
+		StateChangesOnly() bool
+		Handler(teams.HandlerConfig, *log.Logger) alert.Handler
+	}
 	TimingService interface {
 		NewTimer(timer.Setter) timer.Timer
 	}
 	K8sService interface {
 		Client(string) (k8s.Client, error)
 	}
+	SideloadService interface {
+		Source(dir string) (sideload.Source, error)
+	}
 	LogService LogService
 
 	Commander command.Commander
@@ -226,6 +253,7 @@ func (tm *TaskMaster) New(id string) *TaskMaster {
 	n.InfluxDBService = tm.InfluxDBService
 	n.SMTPService = tm.SMTPService
 	n.MQTTService = tm.MQTTService
+	n.KafkaService = tm.KafkaService
 	n.OpsGenieService = tm.OpsGenieService
 	n.VictorOpsService = tm.VictorOpsService
 	n.PagerDutyService = tm.PagerDutyService
@@ -235,10 +263,15 @@ func (tm *TaskMaster) New(id string) *TaskMaster {
 	n.SNMPTrapService = tm.SNMPTrapService
 	n.HipChatService = tm.HipChatService
 	n.AlertaService = tm.AlertaService
+	n.AlertmanagerService = tm.AlertmanagerService
+	n.JiraService = tm.JiraService
 	n.SensuService = tm.SensuService
+	n.SyslogService = tm.SyslogService
 	n.TalkService = tm.TalkService
+	n.TeamsService = tm.TeamsService
 	n.TimingService = tm.TimingService
 	n.K8sService = tm.K8sService
+	n.SideloadService = tm.SideloadService
 	n.Commander = tm.Commander
 	return n
 }
@@ -152,6 +152,7 @@ type UDFProcess struct {
 	commander command.Commander
 	cmdSpec   command.Spec
 	cmd       command.Command
+	blobs     udf.BlobStore
 
 	stderr io.Reader
 
@@ -170,6 +171,7 @@ func NewUDFProcess(
 	taskName, nodeName string,
 	commander command.Commander,
 	cmdSpec command.Spec,
+	blobs udf.BlobStore,
 	l *log.Logger,
 	timeout time.Duration,
 	abortCallback func(),
@@ -179,6 +181,7 @@ func NewUDFProcess(
 		nodeName:      nodeName,
 		commander:     commander,
 		cmdSpec:       cmdSpec,
+		blobs:         blobs,
 		logger:        l,
 		timeout:       timeout,
 		abortCallback: abortCallback,
@@ -220,6 +223,7 @@ func (p *UDFProcess) Open() error {
 		p.nodeName,
 		outBuf,
 		stdin,
+		p.blobs,
 		p.logger,
 		p.timeout,
 		p.abortCallback,
@@ -284,6 +288,7 @@ type UDFSocket struct {
 
 	server *udf.Server
 	socket Socket
+	blobs  udf.BlobStore
 
 	logger        *log.Logger
 	timeout       time.Duration
@@ -300,6 +305,7 @@ type Socket interface {
 func NewUDFSocket(
 	taskName, nodeName string,
 	socket Socket,
+	blobs udf.BlobStore,
 	l *log.Logger,
 	timeout time.Duration,
 	abortCallback func(),
@@ -308,6 +314,7 @@ func NewUDFSocket(
 		taskName:      taskName,
 		nodeName:      nodeName,
 		socket:        socket,
+		blobs:         blobs,
 		logger:        l,
 		timeout:       timeout,
 		abortCallback: abortCallback,
@@ -328,6 +335,7 @@ func (s *UDFSocket) Open() error {
 		s.nodeName,
 		outBuf,
 		in,
+		s.blobs,
 		s.logger,
 		s.timeout,
 		s.abortCallback,
@@ -5,9 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"sync"
 )
 
+// ErrAgentStopped is returned when making a blob request after the Agent has stopped.
+var ErrAgentStopped = errors.New("agent stopped")
+
 // The Agent calls the appropriate methods on the Handler as it receives requests over a socket.
 //
 // Returning an error from any method will cause the Agent to stop and an ErrorResponse to be sent.
@@ -17,6 +21,8 @@ import (
 // The Handler is called from a single goroutine, meaning methods will not be called concurrently.
 //
 // To write Points/Batches back to the Agent/Kapacitor use the Agent.Responses channel.
+//
+// Blobs may be loaded and saved from within any method using Agent.GetBlob, Agent.GetBlobByTag and Agent.SaveBlob.
 type Handler interface {
 	// Return the InfoResponse. Describing the properties of this Handler
 	Info() (*InfoResponse, error)
@@ -58,6 +64,19 @@ type Agent struct {
 	writeErrC chan error
 	readErrC  chan error
 
+	// Requests read from Kapacitor waiting to be handled.
+	requests chan *Request
+	// Closed once no more requests will be read.
+	reading chan struct{}
+	// Closed once no more requests will be handled.
+	handling chan struct{}
+
+	blobMu       sync.Mutex
+	blobRequests map[string]chan *Request
+	nextBlobID   uint64
+	// Notifies the readLoop that the Handler waits on a blob response.
+	blobWaits chan struct{}
+
 	// The handler for requests.
 	Handler Handler
 }
@@ -70,6 +89,11 @@ func New(in io.ReadCloser, out io.WriteCloser) *Agent {
 		out:          out,
 		outResponses: make(chan *Response),
 		responses:    make(chan *Response),
+		requests:     make(chan *Request),
+		reading:      make(chan struct{}),
+		handling:     make(chan struct{}),
+		blobRequests: make(map[string]chan *Request),
+		blobWaits:    make(chan struct{}, 1),
 	}
 	s.Responses = s.responses
 	return s
@@ -83,10 +107,22 @@ func (a *Agent) Start() error {
 
 	a.readErrC = make(chan error, 1)
 	a.writeErrC = make(chan error, 1)
+	readErrC := make(chan error, 1)
+	go func() {
+		readErrC <- a.readLoop()
+	}()
 	a.outGroup.Add(1)
 	go func() {
 		defer a.outGroup.Done()
-		err := a.readLoop()
+		err := a.handleLoop()
+		close(a.handling)
+		if err != nil {
+			// Unblock the readLoop, the error from the Handler takes precedence.
+			a.in.Close()
+			<-readErrC
+		} else {
+			err = <-readErrC
+		}
 		if err != nil {
 			a.outResponses <- &Response{
 				Message: &Response_Error{
@@ -133,21 +169,68 @@ func (a *Agent) Wait() error {
 	return nil
 }
 
+// Read requests from Kapacitor.
+// Blob responses are passed directly to the waiting blob request,
+// so that the Handler may wait on them.
+// All other requests are passed to the handleLoop.
 func (a *Agent) readLoop() error {
-	defer a.Handler.Stop()
+	defer close(a.requests)
 	defer a.in.Close()
+	held, err := a.readRequests()
+	// No more blob responses will be read.
+	close(a.reading)
+	for _, request := range held {
+		select {
+		case a.requests <- request:
+		case <-a.handling:
+			return err
+		}
+	}
+	return err
+}
+
+// readRequests reads requests until the input is closed or the handleLoop stops.
+// Reading waits for the Handler to accept each request, unless the Handler waits on a blob response.
+// Then requests are held back, so that the blob response is read.
+// Returns the requests that are still held back.
+func (a *Agent) readRequests() ([]*Request, error) {
 	in := bufio.NewReader(a.in)
 	var buf []byte
-	request := &Request{}
+	var held []*Request
 	for {
+		for len(held) > 0 && !a.waitingOnBlob() {
+			select {
+			case a.requests <- held[0]:
+				held[0] = nil
+				held = held[1:]
+			case <-a.blobWaits:
+			case <-a.handling:
+				return nil, nil
+			}
+		}
+		request := &Request{}
 		err := ReadMessage(&buf, in, request)
 		if err == io.EOF {
-			break
+			return held, nil
 		}
 		if err != nil {
-			return err
+			return held, err
 		}
+		switch msg := request.Message.(type) {
+		case *Request_GetBlob:
+			a.doBlobResponse(msg.GetBlob.RequestID, request)
+		case *Request_SaveBlob:
+			a.doBlobResponse(msg.SaveBlob.RequestID, request)
+		default:
+			held = append(held, request)
+		}
+	}
+}
 
+// Call the appropriate methods on the Handler for each request.
+func (a *Agent) handleLoop() error {
+	defer a.Handler.Stop()
+	for request := range a.requests {
 		// Hand message to handler
 		var res *Response
 		switch msg := request.Message.(type) {
@@ -218,6 +301,109 @@ func (a *Agent) readLoop() error {
 	return nil
 }
 
+func (a *Agent) doBlobResponse(requestID string, req *Request) {
+	a.blobMu.Lock()
+	c, ok := a.blobRequests[requestID]
+	delete(a.blobRequests, requestID)
+	a.blobMu.Unlock()
+	if ok {
+		c <- req
+	}
+}
+
+// Send a blob request to Kapacitor and wait for the response.
+func (a *Agent) doBlobRequest(requestID string, res *Response) (*Request, error) {
+	c := make(chan *Request, 1)
+	a.blobMu.Lock()
+	a.blobRequests[requestID] = c
+	a.blobMu.Unlock()
+	select {
+	case a.blobWaits <- struct{}{}:
+	default:
+	}
+
+	a.responses <- res
+
+	select {
+	case req := <-c:
+		return req, nil
+	case <-a.reading:
+		a.blobMu.Lock()
+		delete(a.blobRequests, requestID)
+		a.blobMu.Unlock()
+		return nil, ErrAgentStopped
+	}
+}
+
+// waitingOnBlob reports whether any blob request waits on its response.
+func (a *Agent) waitingOnBlob() bool {
+	a.blobMu.Lock()
+	defer a.blobMu.Unlock()
+	return len(a.blobRequests) > 0
+}
+
+func (a *Agent) newBlobRequestID() string {
+	a.blobMu.Lock()
+	defer a.blobMu.Unlock()
+	a.nextBlobID++
+	return strconv.FormatUint(a.nextBlobID, 10)
+}
+
+// GetBlob retrieves the data of the blob with the given ID from Kapacitor.
+func (a *Agent) GetBlob(id string) ([]byte, error) {
+	_, data, err := a.getBlob(id, "")
+	return data, err
+}
+
+// GetBlobByTag retrieves the ID and data of the blob the tag currently points to from Kapacitor.
+func (a *Agent) GetBlobByTag(tag string) (string, []byte, error) {
+	return a.getBlob("", tag)
+}
+
+func (a *Agent) getBlob(id, tag string) (string, []byte, error) {
+	requestID := a.newBlobRequestID()
+	req, err := a.doBlobRequest(requestID, &Response{
+		Message: &Response_GetBlob{
+			GetBlob: &GetBlobRequest{
+				RequestID: requestID,
+				BlobID:    id,
+				Tag:       tag,
+			},
+		},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	res := req.Message.(*Request_GetBlob).GetBlob
+	if res.Error != "" {
+		return "", nil, errors.New(res.Error)
+	}
+	return res.BlobID, res.Data, nil
+}
+
+// SaveBlob saves data as a blob in Kapacitor and returns the ID of the blob.
+// If tag is not empty the tag is updated to point to the new blob.
+func (a *Agent) SaveBlob(data []byte, tag string) (string, error) {
+	requestID := a.newBlobRequestID()
+	req, err := a.doBlobRequest(requestID, &Response{
+		Message: &Response_SaveBlob{
+			SaveBlob: &SaveBlobRequest{
+				RequestID: requestID,
+				Data:      data,
+				Tag:       tag,
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	res := req.Message.(*Request_SaveBlob).SaveBlob
+	if res.Error != "" {
+		return "", errors.New(res.Error)
+	}
+	return res.BlobID, nil
+}
+
 func (a *Agent) writeLoop() error {
 	defer a.out.Close()
 	for response := range a.outResponses {
@@ -0,0 +1,285 @@
+package agent_test
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/influxdata/kapacitor/udf/agent"
+)
+
+// blobHandler loads a model from Kapacitor during Init.
+type blobHandler struct {
+	agent *agent.Agent
+	model []byte
+}
+
+func (h *blobHandler) Info() (*agent.InfoResponse, error) {
+	return &agent.InfoResponse{}, nil
+}
+
+func (h *blobHandler) Init(*agent.InitRequest) (*agent.InitResponse, error) {
+	_, model, err := h.agent.GetBlobByTag("model")
+	if err != nil {
+		return &agent.InitResponse{Success: false, Error: err.Error()}, nil
+	}
+	h.model = model
+	return &agent.InitResponse{Success: true}, nil
+}
+
+func (h *blobHandler) Snapshot() (*agent.SnapshotResponse, error) {
+	return &agent.SnapshotResponse{}, nil
+}
+func (h *blobHandler) Restore(*agent.RestoreRequest) (*agent.RestoreResponse, error) {
+	return &agent.RestoreResponse{Success: true}, nil
+}
+func (h *blobHandler) BeginBatch(*agent.BeginBatch) error { return nil }
+func (h *blobHandler) Point(*agent.Point) error           { return nil }
+func (h *blobHandler) EndBatch(*agent.EndBatch) error     { return nil }
+func (h *blobHandler) Stop() {
+	close(h.agent.Responses)
+}
+
+func TestAgent_GetBlobByTag(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	a := agent.New(inR, outW)
+	h := &blobHandler{agent: a}
+	a.Handler = h
+	if err := a.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := agent.WriteMessage(&agent.Request{
+		Message: &agent.Request_Init{Init: &agent.InitRequest{}},
+	}, inW)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := bufio.NewReader(outR)
+	var buf []byte
+	res := new(agent.Response)
+	if err := agent.ReadMessage(&buf, out, res); err != nil {
+		t.Fatal(err)
+	}
+	get, ok := res.Message.(*agent.Response_GetBlob)
+	if !ok {
+		t.Fatalf("expected get blob message got %T", res.Message)
+	}
+	if exp, got := "model", get.GetBlob.Tag; got != exp {
+		t.Errorf("unexpected tag got %q exp %q", got, exp)
+	}
+	err = agent.WriteMessage(&agent.Request{
+		Message: &agent.Request_GetBlob{
+			GetBlob: &agent.GetBlobResponse{
+				RequestID: get.GetBlob.RequestID,
+				BlobID:    "id",
+				Data:      []byte("weights"),
+			},
+		},
+	}, inW)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res = new(agent.Response)
+	if err := agent.ReadMessage(&buf, out, res); err != nil {
+		t.Fatal(err)
+	}
+	init, ok := res.Message.(*agent.Response_Init)
+	if !ok {
+		t.Fatalf("expected init message got %T", res.Message)
+	}
+	if !init.Init.Success {
+		t.Fatalf("unexpected init error: %s", init.Init.Error)
+	}
+	if exp, got := "weights", string(h.model); got != exp {
+		t.Errorf("unexpected model got %q exp %q", got, exp)
+	}
+
+	inW.Close()
+	go io.Copy(ioutil.Discard, outR)
+	if err := a.Wait(); err != nil {
+		t.Error(err)
+	}
+}
+
+// pointBlobHandler loads a model from Kapacitor when the first point arrives.
+type pointBlobHandler struct {
+	blobHandler
+	points int
+}
+
+func (h *pointBlobHandler) Point(*agent.Point) error {
+	h.points++
+	if h.model == nil {
+		model, err := h.agent.GetBlob("id")
+		if err != nil {
+			return err
+		}
+		h.model = model
+	}
+	return nil
+}
+
+func TestAgent_GetBlob_RequestsWhileWaiting(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	a := agent.New(inR, outW)
+	h := &pointBlobHandler{blobHandler: blobHandler{agent: a}}
+	a.Handler = h
+	if err := a.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	point := &agent.Request{
+		Message: &agent.Request_Point{Point: &agent.Point{Name: "cpu"}},
+	}
+	if err := agent.WriteMessage(point, inW); err != nil {
+		t.Fatal(err)
+	}
+
+	out := bufio.NewReader(outR)
+	var buf []byte
+	res := new(agent.Response)
+	if err := agent.ReadMessage(&buf, out, res); err != nil {
+		t.Fatal(err)
+	}
+	get, ok := res.Message.(*agent.Response_GetBlob)
+	if !ok {
+		t.Fatalf("expected get blob message got %T", res.Message)
+	}
+
+	// Send another point and a keepalive before answering the blob request.
+	writeErrC := make(chan error, 1)
+	go func() {
+		requests := []*agent.Request{
+			point,
+			{Message: &agent.Request_Keepalive{Keepalive: &agent.KeepaliveRequest{Time: 42}}},
+			{Message: &agent.Request_GetBlob{
+				GetBlob: &agent.GetBlobResponse{
+					RequestID: get.GetBlob.RequestID,
+					BlobID:    "id",
+					Data:      []byte("weights"),
+				},
+			}},
+		}
+		for _, r := range requests {
+			if err := agent.WriteMessage(r, inW); err != nil {
+				writeErrC <- err
+				return
+			}
+		}
+		writeErrC <- nil
+	}()
+
+	readErrC := make(chan error, 1)
+	go func() {
+		res = new(agent.Response)
+		readErrC <- agent.ReadMessage(&buf, out, res)
+	}()
+	select {
+	case err := <-readErrC:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for keepalive response, agent deadlocked")
+	}
+	keepalive, ok := res.Message.(*agent.Response_Keepalive)
+	if !ok {
+		t.Fatalf("expected keepalive message got %T", res.Message)
+	}
+	if exp, got := int64(42), keepalive.Keepalive.Time; got != exp {
+		t.Errorf("unexpected keepalive time got %d exp %d", got, exp)
+	}
+	if err := <-writeErrC; err != nil {
+		t.Fatal(err)
+	}
+
+	inW.Close()
+	go io.Copy(ioutil.Discard, outR)
+	if err := a.Wait(); err != nil {
+		t.Error(err)
+	}
+	if exp, got := 2, h.points; got != exp {
+		t.Errorf("unexpected points got %d exp %d", got, exp)
+	}
+	if exp, got := "weights", string(h.model); got != exp {
+		t.Errorf("unexpected model got %q exp %q", got, exp)
+	}
+}
+
+// blockingHandler blocks handling points until released.
+type blockingHandler struct {
+	blobHandler
+	release chan struct{}
+	points  int
+}
+
+func (h *blockingHandler) Point(*agent.Point) error {
+	<-h.release
+	h.points++
+	return nil
+}
+
+func TestAgent_Backpressure(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	go io.Copy(ioutil.Discard, outR)
+
+	a := agent.New(inR, outW)
+	h := &blockingHandler{
+		blobHandler: blobHandler{agent: a},
+		release:     make(chan struct{}),
+	}
+	a.Handler = h
+	if err := a.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write more points than fit in the read buffer of the agent.
+	const count = 1000
+	writeErrC := make(chan error, 1)
+	go func() {
+		point := &agent.Request{
+			Message: &agent.Request_Point{Point: &agent.Point{Name: "cpu", Database: "mydb", RetentionPolicy: "myrp"}},
+		}
+		for i := 0; i < count; i++ {
+			if err := agent.WriteMessage(point, inW); err != nil {
+				writeErrC <- err
+				return
+			}
+		}
+		writeErrC <- nil
+	}()
+
+	// Reading stops while the Handler is blocked.
+	select {
+	case err := <-writeErrC:
+		t.Fatalf("expected writes to block while the handler is blocked, err: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(h.release)
+	select {
+	case err := <-writeErrC:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out writing points")
+	}
+	inW.Close()
+	if err := a.Wait(); err != nil {
+		t.Error(err)
+	}
+	if exp, got := count, h.points; got != exp {
+		t.Errorf("unexpected points got %d exp %d", got, exp)
+	}
+}
@@ -5,8 +5,8 @@
 
 import sys
 import udf_pb2
-from threading import Lock, Thread
-from Queue import Queue
+from threading import Lock, Thread, Event, Condition
+from collections import deque
 import io
 import traceback
 import socket
@@ -15,6 +15,10 @@ import os
 import logging
 logger = logging.getLogger()
 
+# Number of requests read ahead of the handler.
+# More requests are only read while the handler waits on a blob response.
+max_queued_requests = 1
+
 
 # The Agent calls the appropriate methods on the Handler as requests are read off STDIN.
 #
@@ -25,6 +29,9 @@ logger = logging.getLogger()
 # The Handler is called from a single thread, meaning methods will not be called concurrently.
 #
 # To write Points/Batches back to the Agent/Kapacitor use the Agent.write_response method, which is thread safe.
+#
+# Blobs may be loaded and saved from within any method using the Agent.get_blob,
+# Agent.get_blob_by_tag and Agent.save_blob methods.
 class Handler(object):
     def info(self):
         pass
@@ -52,13 +59,24 @@ class Agent(object):
         self._in = _in
         self._out = out
         self._thread = None
+        self._read_thread = None
         self.handler = handler
         self._write_lock = Lock()
+        self._requests = deque()
+        self._blob_lock = Lock()
+        # Signals changes to the queued requests and the blob requests.
+        self._cond = Condition(self._blob_lock)
+        self._blob_requests = {}
+        self._next_blob_id = 0
+        self._reading = True
 
     # Start the agent.
     # This method returns immediately
     def start(self):
-        self._thread = Thread(target=self._read_loop)
+        self._read_thread = Thread(target=self._read_loop)
+        self._read_thread.daemon = True
+        self._read_thread.start()
+        self._thread = Thread(target=self._handle_loop)
         self._thread.start()
 
     # Wait for the Agent to terminate.
@@ -68,6 +86,82 @@ class Agent(object):
         self._in.close()
         self._out.close()
 
+    # Retrieve the data of the blob with the given ID from Kapacitor.
+    # This method is thread safe and may be called from within the Handler.
+    def get_blob(self, blob_id):
+        response = udf_pb2.Response()
+        response.getBlob.blobID = blob_id
+        return self._get_blob(response)[1]
+
+    # Retrieve the ID and data of the blob the tag currently points to from Kapacitor.
+    # This method is thread safe and may be called from within the Handler.
+    def get_blob_by_tag(self, tag):
+        response = udf_pb2.Response()
+        response.getBlob.tag = tag
+        return self._get_blob(response)
+
+    # Save data as a blob in Kapacitor and return the ID of the blob.
+    # If tag is not empty the tag is updated to point to the new blob.
+    # This method is thread safe and may be called from within the Handler.
+    def save_blob(self, data, tag=''):
+        response = udf_pb2.Response()
+        response.saveBlob.data = data
+        response.saveBlob.tag = tag
+        result = self._blob_request(response, response.saveBlob).saveBlob
+        if result.error:
+            raise Exception(result.error)
+        return result.blobID
+
+    def _get_blob(self, response):
+        result = self._blob_request(response, response.getBlob).getBlob
+        if result.error:
+            raise Exception(result.error)
+        return result.blobID, result.data
+
+    # Send a blob request to Kapacitor and wait for the response.
+    def _blob_request(self, response, blob_request):
+        done = Event()
+        result = []
+        self._blob_lock.acquire()
+        try:
+            if not self._reading:
+                raise Exception("agent stopped")
+            self._next_blob_id += 1
+            blob_request.requestID = str(self._next_blob_id)
+            self._blob_requests[blob_request.requestID] = (done, result)
+            self._cond.notify_all()
+        finally:
+            self._blob_lock.release()
+        self.write_response(response, flush=True)
+        done.wait()
+        if len(result) == 0:
+            raise Exception("agent stopped")
+        return result[0]
+
+    def _blob_response(self, request_id, request):
+        self._blob_lock.acquire()
+        try:
+            waiter = self._blob_requests.pop(request_id, None)
+        finally:
+            self._blob_lock.release()
+        if waiter is not None:
+            done, result = waiter
+            result.append(request)
+            done.set()
+
+    # Release all blob requests still waiting on a response.
+    def _stop_reading(self):
+        self._blob_lock.acquire()
+        try:
+            self._reading = False
+            waiters = self._blob_requests.values()
+            self._blob_requests = {}
+            self._cond.notify_all()
+        finally:
+            self._blob_lock.release()
+        for done, _ in waiters:
+            done.set()
+
     # Write a response to STDOUT.
     # This method is thread safe.
     def write_response(self, response, flush=False):
@@ -87,17 +181,69 @@ class Agent(object):
         finally:
             self._write_lock.release()
 
-    # Read requests off stdin
+    # Queue a request for the handle loop.
+    # Waits for the handle loop to take the queued requests,
+    # unless the handler waits on a blob response, which must be read first.
+    def _put_request(self, request, wait=True):
+        self._cond.acquire()
+        try:
+            while wait and len(self._requests) >= max_queued_requests and not self._blob_requests:
+                self._cond.wait()
+            self._requests.append(request)
+            self._cond.notify_all()
+        finally:
+            self._cond.release()
+
+    # Wait for the next queued request.
+    def _get_request(self):
+        self._cond.acquire()
+        try:
+            while not self._requests:
+                self._cond.wait()
+            request = self._requests.popleft()
+            self._cond.notify_all()
+            return request
+        finally:
+            self._cond.release()
+
+    # Read requests off stdin.
+    # Blob responses are passed directly to the waiting blob request,
+    # so that the Handler may wait on them.
+    # All other requests are queued for the handle loop.
     def _read_loop(self):
-        request = udf_pb2.Request()
-        while True:
-            msg = 'unknown'
-            try:
+        try:
+            while True:
                 size = decodeUvarint32(self._in)
                 data = self._in.read(size)
 
+                request = udf_pb2.Request()
                 request.ParseFromString(data)
 
+                msg = request.WhichOneof("message")
+                if msg == "getBlob":
+                    self._blob_response(request.getBlob.requestID, request)
+                elif msg == "saveBlob":
+                    self._blob_response(request.saveBlob.requestID, request)
+                else:
+                    self._put_request(request)
+        except EOF:
+            self._put_request(None, wait=False)
+        except Exception as e:
+            self._put_request(e, wait=False)
+        finally:
+            self._stop_reading()
+
+    # Call the appropriate methods on the handler for each request.
+    def _handle_loop(self):
+        while True:
+            msg = 'unknown'
+            try:
+                request = self._get_request()
+                if request is None:
+                    raise EOF
+                if isinstance(request, Exception):
+                    raise request
+
                 # use parsed message
                 msg = request.WhichOneof("message")
                 if msg == "info":
@@ -6,9 +6,11 @@
 Package agent is a generated protocol buffer package.
 
 It is generated from these files:
+
 	udf.proto
 
 It has these top-level messages:
+
 	InfoRequest
 	InfoResponse
 	OptionInfo
@@ -23,6 +25,10 @@ It has these top-level messages:
 	KeepaliveRequest
 	KeepaliveResponse
 	ErrorResponse
+	GetBlobRequest
+	GetBlobResponse
+	SaveBlobRequest
+	SaveBlobResponse
 	BeginBatch
 	Point
 	EndBatch
@@ -117,6 +123,20 @@ func (m *InfoResponse) String() string            { return proto.CompactTextStri
 func (*InfoResponse) ProtoMessage()               {}
 func (*InfoResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
 
+func (m *InfoResponse) GetWants() EdgeType {
+	if m != nil {
+		return m.Wants
+	}
+	return EdgeType_STREAM
+}
+
+func (m *InfoResponse) GetProvides() EdgeType {
+	if m != nil {
+		return m.Provides
+	}
+	return EdgeType_STREAM
+}
+
 func (m *InfoResponse) GetOptions() map[string]*OptionInfo {
 	if m != nil {
 		return m.Options
@@ -125,7 +145,7 @@ func (m *InfoResponse) GetOptions() map[string]*OptionInfo {
 }
 
 type OptionInfo struct {
-	ValueTypes []ValueType `protobuf:"varint,1,rep,name=valueTypes,enum=agent.ValueType" json:"valueTypes,omitempty"`
+	ValueTypes []ValueType `protobuf:"varint,1,rep,packed,name=valueTypes,enum=agent.ValueType" json:"valueTypes,omitempty"`
 }
 
 func (m *OptionInfo) Reset()                    { *m = OptionInfo{} }
@@ -133,6 +153,13 @@ func (m *OptionInfo) String() string            { return proto.CompactTextString
 func (*OptionInfo) ProtoMessage()               {}
 func (*OptionInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
+func (m *OptionInfo) GetValueTypes() []ValueType {
+	if m != nil {
+		return m.ValueTypes
+	}
+	return nil
+}
+
 // Request that the process initialize itself with the provided options.
 type InitRequest struct {
 	Options []*Option `protobuf:"bytes,1,rep,name=options" json:"options,omitempty"`
@@ -152,6 +179,20 @@ func (m *InitRequest) GetOptions() []*Option {
 	return nil
 }
 
+func (m *InitRequest) GetTaskID() string {
+	if m != nil {
+		return m.TaskID
+	}
+	return ""
+}
+
+func (m *InitRequest) GetNodeID() string {
+	if m != nil {
+		return m.NodeID
+	}
+	return ""
+}
+
 type Option struct {
 	Name   string         `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	Values []*OptionValue `protobuf:"bytes,2,rep,name=values" json:"values,omitempty"`
@@ -162,6 +203,13 @@ func (m *Option) String() string            { return proto.CompactTextString(m)
 func (*Option) ProtoMessage()               {}
 func (*Option) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
 
+func (m *Option) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
 func (m *Option) GetValues() []*OptionValue {
 	if m != nil {
 		return m.Values
@@ -185,9 +233,7 @@ func (m *OptionValue) String() string            { return proto.CompactTextStrin
 func (*OptionValue) ProtoMessage()               {}
 func (*OptionValue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
-type isOptionValue_Value interface {
-	isOptionValue_Value()
-}
+type isOptionValue_Value interface{ isOptionValue_Value() }
 
 type OptionValue_BoolValue struct {
 	BoolValue bool `protobuf:"varint,2,opt,name=boolValue,oneof"`
@@ -218,6 +264,13 @@ func (m *OptionValue) GetValue() isOptionValue_Value {
 	return nil
 }
 
+func (m *OptionValue) GetType() ValueType {
+	if m != nil {
+		return m.Type
+	}
+	return ValueType_BOOL
+}
+
 func (m *OptionValue) GetBoolValue() bool {
 	if x, ok := m.GetValue().(*OptionValue_BoolValue); ok {
 		return x.BoolValue
@@ -375,6 +428,20 @@ func (m *InitResponse) String() string            { return proto.CompactTextStri
 func (*InitResponse) ProtoMessage()               {}
 func (*InitResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
+func (m *InitResponse) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *InitResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
 // Request that the process provide a snapshot of its state.
 type SnapshotRequest struct {
 }
@@ -394,6 +461,13 @@ func (m *SnapshotResponse) String() string            { return proto.CompactText
 func (*SnapshotResponse) ProtoMessage()               {}
 func (*SnapshotResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
+func (m *SnapshotResponse) GetSnapshot() []byte {
+	if m != nil {
+		return m.Snapshot
+	}
+	return nil
+}
+
 // Request that the process restore its state from a snapshot.
 type RestoreRequest struct {
 	Snapshot []byte `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
@@ -404,6 +478,13 @@ func (m *RestoreRequest) String() string            { return proto.CompactTextSt
 func (*RestoreRequest) ProtoMessage()               {}
 func (*RestoreRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
+func (m *RestoreRequest) GetSnapshot() []byte {
+	if m != nil {
+		return m.Snapshot
+	}
+	return nil
+}
+
 // Respond with success or failure to a RestoreRequest
 type RestoreResponse struct {
 	Success bool   `protobuf:"varint,1,opt,name=success" json:"success,omitempty"`
@@ -415,6 +496,20 @@ func (m *RestoreResponse) String() string            { return proto.CompactTextS
 func (*RestoreResponse) ProtoMessage()               {}
 func (*RestoreResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
+func (m *RestoreResponse) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *RestoreResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
 // Request that the process respond with a Keepalive to verify it is responding.
 type KeepaliveRequest struct {
 	// The number of nanoseconds since the epoch.
@@ -427,6 +522,13 @@ func (m *KeepaliveRequest) String() string            { return proto.CompactText
 func (*KeepaliveRequest) ProtoMessage()               {}
 func (*KeepaliveRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
+func (m *KeepaliveRequest) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
 // Respond to KeepaliveRequest
 type KeepaliveResponse struct {
 	// The number of nanoseconds since the epoch.
@@ -439,6 +541,13 @@ func (m *KeepaliveResponse) String() string            { return proto.CompactTex
 func (*KeepaliveResponse) ProtoMessage()               {}
 func (*KeepaliveResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
 
+func (m *KeepaliveResponse) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
 // Sent from the process to Kapacitor indicating an error has occurred.
 // If an ErrorResponse is received, Kapacitor will terminate the process.
 type ErrorResponse struct {
@@ -450,6 +559,157 @@ func (m *ErrorResponse) String() string            { return proto.CompactTextStr
 func (*ErrorResponse) ProtoMessage()               {}
 func (*ErrorResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
+func (m *ErrorResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+// Request the data of a blob, either by its ID or by the name of a tag.
+// If both are set the ID takes precedence.
+type GetBlobRequest struct {
+	RequestID string `protobuf:"bytes,1,opt,name=requestID" json:"requestID,omitempty"`
+	BlobID    string `protobuf:"bytes,2,opt,name=blobID" json:"blobID,omitempty"`
+	Tag       string `protobuf:"bytes,3,opt,name=tag" json:"tag,omitempty"`
+}
+
+func (m *GetBlobRequest) Reset()                    { *m = GetBlobRequest{} }
+func (m *GetBlobRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetBlobRequest) ProtoMessage()               {}
+func (*GetBlobRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *GetBlobRequest) GetRequestID() string {
+	if m != nil {
+		return m.RequestID
+	}
+	return ""
+}
+
+func (m *GetBlobRequest) GetBlobID() string {
+	if m != nil {
+		return m.BlobID
+	}
+	return ""
+}
+
+func (m *GetBlobRequest) GetTag() string {
+	if m != nil {
+		return m.Tag
+	}
+	return ""
+}
+
+// Respond to the process with the requested blob.
+// If error is not empty the blob could not be retrieved.
+type GetBlobResponse struct {
+	RequestID string `protobuf:"bytes,1,opt,name=requestID" json:"requestID,omitempty"`
+	BlobID    string `protobuf:"bytes,2,opt,name=blobID" json:"blobID,omitempty"`
+	Data      []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Error     string `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *GetBlobResponse) Reset()                    { *m = GetBlobResponse{} }
+func (m *GetBlobResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetBlobResponse) ProtoMessage()               {}
+func (*GetBlobResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *GetBlobResponse) GetRequestID() string {
+	if m != nil {
+		return m.RequestID
+	}
+	return ""
+}
+
+func (m *GetBlobResponse) GetBlobID() string {
+	if m != nil {
+		return m.BlobID
+	}
+	return ""
+}
+
+func (m *GetBlobResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *GetBlobResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+// Request that data be saved as a new blob.
+// If tag is not empty the tag is updated to point to the new blob.
+type SaveBlobRequest struct {
+	RequestID string `protobuf:"bytes,1,opt,name=requestID" json:"requestID,omitempty"`
+	Data      []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Tag       string `protobuf:"bytes,3,opt,name=tag" json:"tag,omitempty"`
+}
+
+func (m *SaveBlobRequest) Reset()                    { *m = SaveBlobRequest{} }
+func (m *SaveBlobRequest) String() string            { return proto.CompactTextString(m) }
+func (*SaveBlobRequest) ProtoMessage()               {}
+func (*SaveBlobRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *SaveBlobRequest) GetRequestID() string {
+	if m != nil {
+		return m.RequestID
+	}
+	return ""
+}
+
+func (m *SaveBlobRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *SaveBlobRequest) GetTag() string {
+	if m != nil {
+		return m.Tag
+	}
+	return ""
+}
+
+// Respond to the process with the ID of the saved blob.
+// If error is not empty the blob could not be saved.
+type SaveBlobResponse struct {
+	RequestID string `protobuf:"bytes,1,opt,name=requestID" json:"requestID,omitempty"`
+	BlobID    string `protobuf:"bytes,2,opt,name=blobID" json:"blobID,omitempty"`
+	Error     string `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *SaveBlobResponse) Reset()                    { *m = SaveBlobResponse{} }
+func (m *SaveBlobResponse) String() string            { return proto.CompactTextString(m) }
+func (*SaveBlobResponse) ProtoMessage()               {}
+func (*SaveBlobResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *SaveBlobResponse) GetRequestID() string {
+	if m != nil {
+		return m.RequestID
+	}
+	return ""
+}
+
+func (m *SaveBlobResponse) GetBlobID() string {
+	if m != nil {
+		return m.BlobID
+	}
+	return ""
+}
+
+func (m *SaveBlobResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
 // Indicates the beginning of a batch.
 // All subsequent points should be considered
 // part of the batch until EndBatch arrives.
@@ -473,7 +733,21 @@ type BeginBatch struct {
 func (m *BeginBatch) Reset()                    { *m = BeginBatch{} }
 func (m *BeginBatch) String() string            { return proto.CompactTextString(m) }
 func (*BeginBatch) ProtoMessage()               {}
-func (*BeginBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+func (*BeginBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *BeginBatch) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *BeginBatch) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
 
 func (m *BeginBatch) GetTags() map[string]string {
 	if m != nil {
@@ -482,6 +756,20 @@ func (m *BeginBatch) GetTags() map[string]string {
 	return nil
 }
 
+func (m *BeginBatch) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *BeginBatch) GetByName() bool {
+	if m != nil {
+		return m.ByName
+	}
+	return false
+}
+
 // Message containing information about a single data point.
 // Can be sent on it's own or bookended by BeginBatch and EndBatch messages.
 type Point struct {
@@ -501,7 +789,49 @@ type Point struct {
 func (m *Point) Reset()                    { *m = Point{} }
 func (m *Point) String() string            { return proto.CompactTextString(m) }
 func (*Point) ProtoMessage()               {}
-func (*Point) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+func (*Point) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *Point) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Point) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Point) GetDatabase() string {
+	if m != nil {
+		return m.Database
+	}
+	return ""
+}
+
+func (m *Point) GetRetentionPolicy() string {
+	if m != nil {
+		return m.RetentionPolicy
+	}
+	return ""
+}
+
+func (m *Point) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *Point) GetDimensions() []string {
+	if m != nil {
+		return m.Dimensions
+	}
+	return nil
+}
 
 func (m *Point) GetTags() map[string]string {
 	if m != nil {
@@ -531,6 +861,13 @@ func (m *Point) GetFieldsString() map[string]string {
 	return nil
 }
 
+func (m *Point) GetByName() bool {
+	if m != nil {
+		return m.ByName
+	}
+	return false
+}
+
 // Indicates the end of a batch and contains
 // all meta data associated with the batch.
 // The same meta information is provided for
@@ -547,7 +884,28 @@ type EndBatch struct {
 func (m *EndBatch) Reset()                    { *m = EndBatch{} }
 func (m *EndBatch) String() string            { return proto.CompactTextString(m) }
 func (*EndBatch) ProtoMessage()               {}
-func (*EndBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+func (*EndBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *EndBatch) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *EndBatch) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *EndBatch) GetTmax() int64 {
+	if m != nil {
+		return m.Tmax
+	}
+	return 0
+}
 
 func (m *EndBatch) GetTags() map[string]string {
 	if m != nil {
@@ -556,6 +914,13 @@ func (m *EndBatch) GetTags() map[string]string {
 	return nil
 }
 
+func (m *EndBatch) GetByName() bool {
+	if m != nil {
+		return m.ByName
+	}
+	return false
+}
+
 // Request message wrapper -- sent from Kapacitor to process
 type Request struct {
 	// Types that are valid to be assigned to Message:
@@ -564,6 +929,8 @@ type Request struct {
 	//	*Request_Keepalive
 	//	*Request_Snapshot
 	//	*Request_Restore
+	//	*Request_GetBlob
+	//	*Request_SaveBlob
 	//	*Request_Begin
 	//	*Request_Point
 	//	*Request_End
@@ -573,11 +940,9 @@ type Request struct {
 func (m *Request) Reset()                    { *m = Request{} }
 func (m *Request) String() string            { return proto.CompactTextString(m) }
 func (*Request) ProtoMessage()               {}
-func (*Request) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+func (*Request) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
-type isRequest_Message interface {
-	isRequest_Message()
-}
+type isRequest_Message interface{ isRequest_Message() }
 
 type Request_Info struct {
 	Info *InfoRequest `protobuf:"bytes,1,opt,name=info,oneof"`
@@ -594,6 +959,12 @@ type Request_Snapshot struct {
 type Request_Restore struct {
 	Restore *RestoreRequest `protobuf:"bytes,5,opt,name=restore,oneof"`
 }
+type Request_GetBlob struct {
+	GetBlob *GetBlobResponse `protobuf:"bytes,6,opt,name=getBlob,oneof"`
+}
+type Request_SaveBlob struct {
+	SaveBlob *SaveBlobResponse `protobuf:"bytes,7,opt,name=saveBlob,oneof"`
+}
 type Request_Begin struct {
 	Begin *BeginBatch `protobuf:"bytes,16,opt,name=begin,oneof"`
 }
@@ -609,6 +980,8 @@ func (*Request_Init) isRequest_Message()      {}
 func (*Request_Keepalive) isRequest_Message() {}
 func (*Request_Snapshot) isRequest_Message()  {}
 func (*Request_Restore) isRequest_Message()   {}
+func (*Request_GetBlob) isRequest_Message()   {}
+func (*Request_SaveBlob) isRequest_Message()  {}
 func (*Request_Begin) isRequest_Message()     {}
 func (*Request_Point) isRequest_Message()     {}
 func (*Request_End) isRequest_Message()       {}
@@ -655,6 +1028,20 @@ func (m *Request) GetRestore() *RestoreRequest {
 	return nil
 }
 
+func (m *Request) GetGetBlob() *GetBlobResponse {
+	if x, ok := m.GetMessage().(*Request_GetBlob); ok {
+		return x.GetBlob
+	}
+	return nil
+}
+
+func (m *Request) GetSaveBlob() *SaveBlobResponse {
+	if x, ok := m.GetMessage().(*Request_SaveBlob); ok {
+		return x.SaveBlob
+	}
+	return nil
+}
+
 func (m *Request) GetBegin() *BeginBatch {
 	if x, ok := m.GetMessage().(*Request_Begin); ok {
 		return x.Begin
@@ -684,6 +1071,8 @@ func (*Request) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error
 		(*Request_Keepalive)(nil),
 		(*Request_Snapshot)(nil),
 		(*Request_Restore)(nil),
+		(*Request_GetBlob)(nil),
+		(*Request_SaveBlob)(nil),
 		(*Request_Begin)(nil),
 		(*Request_Point)(nil),
 		(*Request_End)(nil),
@@ -719,6 +1108,16 @@ func _Request_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Restore); err != nil {
 			return err
 		}
+	case *Request_GetBlob:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.GetBlob); err != nil {
+			return err
+		}
+	case *Request_SaveBlob:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.SaveBlob); err != nil {
+			return err
+		}
 	case *Request_Begin:
 		b.EncodeVarint(16<<3 | proto.WireBytes)
 		if err := b.EncodeMessage(x.Begin); err != nil {
@@ -784,6 +1183,22 @@ func _Request_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer
 		err := b.DecodeMessage(msg)
 		m.Message = &Request_Restore{msg}
 		return true, err
+	case 6: // message.getBlob
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(GetBlobResponse)
+		err := b.DecodeMessage(msg)
+		m.Message = &Request_GetBlob{msg}
+		return true, err
+	case 7: // message.saveBlob
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SaveBlobResponse)
+		err := b.DecodeMessage(msg)
+		m.Message = &Request_SaveBlob{msg}
+		return true, err
 	case 16: // message.begin
 		if wire != proto.WireBytes {
 			return true, proto.ErrInternalBadWireType
@@ -842,6 +1257,16 @@ func _Request_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(5<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Request_GetBlob:
+		s := proto.Size(x.GetBlob)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Request_SaveBlob:
+		s := proto.Size(x.SaveBlob)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case *Request_Begin:
 		s := proto.Size(x.Begin)
 		n += proto.SizeVarint(16<<3 | proto.WireBytes)
@@ -873,6 +1298,8 @@ type Response struct {
 	//	*Response_Snapshot
 	//	*Response_Restore
 	//	*Response_Error
+	//	*Response_GetBlob
+	//	*Response_SaveBlob
 	//	*Response_Begin
 	//	*Response_Point
 	//	*Response_End
@@ -882,11 +1309,9 @@ type Response struct {
 func (m *Response) Reset()                    { *m = Response{} }
 func (m *Response) String() string            { return proto.CompactTextString(m) }
 func (*Response) ProtoMessage()               {}
-func (*Response) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+func (*Response) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
 
-type isResponse_Message interface {
-	isResponse_Message()
-}
+type isResponse_Message interface{ isResponse_Message() }
 
 type Response_Info struct {
 	Info *InfoResponse `protobuf:"bytes,1,opt,name=info,oneof"`
@@ -906,6 +1331,12 @@ type Response_Restore struct {
 type Response_Error struct {
 	Error *ErrorResponse `protobuf:"bytes,6,opt,name=error,oneof"`
 }
+type Response_GetBlob struct {
+	GetBlob *GetBlobRequest `protobuf:"bytes,7,opt,name=getBlob,oneof"`
+}
+type Response_SaveBlob struct {
+	SaveBlob *SaveBlobRequest `protobuf:"bytes,8,opt,name=saveBlob,oneof"`
+}
 type Response_Begin struct {
 	Begin *BeginBatch `protobuf:"bytes,16,opt,name=begin,oneof"`
 }
@@ -922,6 +1353,8 @@ func (*Response_Keepalive) isResponse_Message() {}
 func (*Response_Snapshot) isResponse_Message()  {}
 func (*Response_Restore) isResponse_Message()   {}
 func (*Response_Error) isResponse_Message()     {}
+func (*Response_GetBlob) isResponse_Message()   {}
+func (*Response_SaveBlob) isResponse_Message()  {}
 func (*Response_Begin) isResponse_Message()     {}
 func (*Response_Point) isResponse_Message()     {}
 func (*Response_End) isResponse_Message()       {}
@@ -975,6 +1408,20 @@ func (m *Response) GetError() *ErrorResponse {
 	return nil
 }
 
+func (m *Response) GetGetBlob() *GetBlobRequest {
+	if x, ok := m.GetMessage().(*Response_GetBlob); ok {
+		return x.GetBlob
+	}
+	return nil
+}
+
+func (m *Response) GetSaveBlob() *SaveBlobRequest {
+	if x, ok := m.GetMessage().(*Response_SaveBlob); ok {
+		return x.SaveBlob
+	}
+	return nil
+}
+
 func (m *Response) GetBegin() *BeginBatch {
 	if x, ok := m.GetMessage().(*Response_Begin); ok {
 		return x.Begin
@@ -1005,6 +1452,8 @@ func (*Response) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) erro
 		(*Response_Snapshot)(nil),
 		(*Response_Restore)(nil),
 		(*Response_Error)(nil),
+		(*Response_GetBlob)(nil),
+		(*Response_SaveBlob)(nil),
 		(*Response_Begin)(nil),
 		(*Response_Point)(nil),
 		(*Response_End)(nil),
@@ -1045,6 +1494,16 @@ func _Response_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Error); err != nil {
 			return err
 		}
+	case *Response_GetBlob:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.GetBlob); err != nil {
+			return err
+		}
+	case *Response_SaveBlob:
+		b.EncodeVarint(8<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.SaveBlob); err != nil {
+			return err
+		}
 	case *Response_Begin:
 		b.EncodeVarint(16<<3 | proto.WireBytes)
 		if err := b.EncodeMessage(x.Begin); err != nil {
@@ -1118,6 +1577,22 @@ func _Response_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffe
 		err := b.DecodeMessage(msg)
 		m.Message = &Response_Error{msg}
 		return true, err
+	case 7: // message.getBlob
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(GetBlobRequest)
+		err := b.DecodeMessage(msg)
+		m.Message = &Response_GetBlob{msg}
+		return true, err
+	case 8: // message.saveBlob
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SaveBlobRequest)
+		err := b.DecodeMessage(msg)
+		m.Message = &Response_SaveBlob{msg}
+		return true, err
 	case 16: // message.begin
 		if wire != proto.WireBytes {
 			return true, proto.ErrInternalBadWireType
@@ -1181,6 +1656,16 @@ func _Response_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(6<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Response_GetBlob:
+		s := proto.Size(x.GetBlob)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Response_SaveBlob:
+		s := proto.Size(x.SaveBlob)
+		n += proto.SizeVarint(8<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case *Response_Begin:
 		s := proto.Size(x.Begin)
 		n += proto.SizeVarint(16<<3 | proto.WireBytes)
@@ -1218,6 +1703,10 @@ func init() {
 	proto.RegisterType((*KeepaliveRequest)(nil), "agent.KeepaliveRequest")
 	proto.RegisterType((*KeepaliveResponse)(nil), "agent.KeepaliveResponse")
 	proto.RegisterType((*ErrorResponse)(nil), "agent.ErrorResponse")
+	proto.RegisterType((*GetBlobRequest)(nil), "agent.GetBlobRequest")
+	proto.RegisterType((*GetBlobResponse)(nil), "agent.GetBlobResponse")
+	proto.RegisterType((*SaveBlobRequest)(nil), "agent.SaveBlobRequest")
+	proto.RegisterType((*SaveBlobResponse)(nil), "agent.SaveBlobResponse")
 	proto.RegisterType((*BeginBatch)(nil), "agent.BeginBatch")
 	proto.RegisterType((*Point)(nil), "agent.Point")
 	proto.RegisterType((*EndBatch)(nil), "agent.EndBatch")
@@ -1230,75 +1719,76 @@ func init() {
 func init() { proto.RegisterFile("udf.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1118 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xc4, 0x57, 0xdd, 0x72, 0xdb, 0x44,
-	0x14, 0xae, 0x22, 0xcb, 0x96, 0x8e, 0x9d, 0x44, 0xd9, 0x86, 0x54, 0x04, 0x26, 0x13, 0x04, 0x6d,
-	0x93, 0x50, 0x0c, 0x18, 0x18, 0x4a, 0x87, 0x81, 0x89, 0x89, 0x21, 0x1e, 0xda, 0xb8, 0xa3, 0xb8,
-	0xbd, 0x97, 0xa3, 0x8d, 0xab, 0x89, 0x23, 0x19, 0x69, 0x1d, 0x30, 0x57, 0xbc, 0x0f, 0xb7, 0x3c,
-	0x04, 0x17, 0x3c, 0x09, 0x33, 0xbc, 0x03, 0xfb, 0xa7, 0xd5, 0xca, 0x36, 0x74, 0xca, 0x74, 0x86,
-	0x3b, 0xef, 0x39, 0xdf, 0xf9, 0xfd, 0xce, 0x9e, 0x95, 0xc1, 0x99, 0x45, 0x97, 0xed, 0x69, 0x96,
-	0x92, 0x14, 0x59, 0xe1, 0x18, 0x27, 0xc4, 0x5f, 0x87, 0x66, 0x3f, 0xb9, 0x4c, 0x03, 0xfc, 0xc3,
-	0x0c, 0xe7, 0xc4, 0xff, 0xcb, 0x80, 0x96, 0x38, 0xe7, 0xd3, 0x34, 0xc9, 0x31, 0xba, 0x0b, 0xd6,
-	0x8f, 0x61, 0x42, 0x72, 0xcf, 0xd8, 0x37, 0x0e, 0x36, 0x3a, 0x9b, 0x6d, 0x6e, 0xd6, 0xee, 0x45,
-	0x63, 0x3c, 0x9c, 0x4f, 0x71, 0x20, 0xb4, 0xe8, 0x7d, 0xb0, 0xa9, 0xdb, 0x9b, 0x38, 0xc2, 0xb9,
-	0xb7, 0xb6, 0x1a, 0xa9, 0x00, 0xe8, 0x11, 0x34, 0xd2, 0x29, 0x89, 0xa9, 0x7f, 0xcf, 0xdc, 0x37,
-	0x0f, 0x9a, 0x9d, 0x7d, 0x89, 0xd5, 0x23, 0xb7, 0x07, 0x02, 0xd2, 0x4b, 0x48, 0x36, 0x0f, 0x0a,
-	0x83, 0xdd, 0x27, 0xd0, 0xd2, 0x15, 0xc8, 0x05, 0xf3, 0x0a, 0xcf, 0x79, 0x76, 0x4e, 0xc0, 0x7e,
-	0xa2, 0xfb, 0x60, 0xdd, 0x84, 0x93, 0x19, 0xe6, 0x79, 0x34, 0x3b, 0x5b, 0xd2, 0xb7, 0xb0, 0xe2,
-	0x11, 0x84, 0xfe, 0xd1, 0xda, 0x43, 0xc3, 0xff, 0x0a, 0xa0, 0x54, 0xa0, 0x8f, 0x00, 0xb8, 0x8a,
-	0xe5, 0xcb, 0x2a, 0x36, 0x69, 0x1d, 0xae, 0xb4, 0x7f, 0x5e, 0x28, 0x02, 0x0d, 0xe3, 0x5f, 0xb2,
-	0xf6, 0xc5, 0x44, 0xb6, 0x8f, 0xc6, 0x56, 0x95, 0x19, 0xbc, 0xb2, 0xf5, 0x4a, 0x74, 0x55, 0x06,
-	0xda, 0x81, 0x3a, 0x09, 0xf3, 0xab, 0xfe, 0x09, 0xcf, 0xd2, 0x09, 0xe4, 0x89, 0xc9, 0x93, 0x34,
-	0xc2, 0x54, 0x6e, 0x0a, 0xb9, 0x38, 0xf9, 0xa7, 0x50, 0x17, 0x2e, 0x10, 0x82, 0x5a, 0x12, 0x5e,
-	0x63, 0x59, 0x31, 0xff, 0x8d, 0x8e, 0xa0, 0xce, 0x73, 0x62, 0xbd, 0x67, 0x51, 0x51, 0x25, 0x2a,
-	0xcf, 0x3c, 0x90, 0x08, 0xff, 0x4f, 0x03, 0x9a, 0x9a, 0x1c, 0xbd, 0x07, 0x35, 0x42, 0x4b, 0x91,
-	0xfc, 0x2e, 0x57, 0xcb, 0xb5, 0x68, 0x0f, 0x9c, 0x51, 0x9a, 0x4e, 0x9e, 0xab, 0xc6, 0xda, 0xa7,
-	0xb7, 0x82, 0x52, 0x84, 0xde, 0x06, 0x3b, 0x4e, 0x88, 0x50, 0xb3, 0xcc, 0x4d, 0xaa, 0x56, 0x12,
-	0xe4, 0x43, 0x33, 0x4a, 0x67, 0xa3, 0x09, 0x16, 0x80, 0x1a, 0x05, 0x18, 0x14, 0xa0, 0x0b, 0x19,
-	0x26, 0x27, 0x59, 0x9c, 0x8c, 0x05, 0xc6, 0x62, 0xe5, 0x31, 0x8c, 0x26, 0x44, 0xf7, 0x60, 0x3d,
-	0x9a, 0x65, 0xa1, 0x4a, 0xde, 0xab, 0xcb, 0x50, 0x55, 0x71, 0xb7, 0x21, 0x47, 0x80, 0xd2, 0xdb,
-	0x12, 0xf4, 0xc8, 0x69, 0xf6, 0xa0, 0x91, 0xcf, 0x2e, 0x2e, 0x70, 0x2e, 0xe6, 0xd9, 0x0e, 0x8a,
-	0x23, 0xda, 0x06, 0x0b, 0x67, 0x59, 0x9a, 0x49, 0x3e, 0xc4, 0xc1, 0xdf, 0x82, 0xcd, 0xf3, 0x24,
-	0x9c, 0xe6, 0x2f, 0xd2, 0x82, 0x62, 0xbf, 0x0d, 0x6e, 0x29, 0x92, 0x6e, 0x77, 0xc1, 0xce, 0xa5,
-	0x8c, 0xfb, 0x6d, 0x05, 0xea, 0xec, 0x3f, 0x80, 0x0d, 0x8a, 0x23, 0x69, 0x86, 0x8b, 0x21, 0xf9,
-	0x37, 0xf4, 0x31, 0x6c, 0x2a, 0xf4, 0x7f, 0xcc, 0xf9, 0x1e, 0xb8, 0xdf, 0x63, 0x3c, 0x0d, 0x27,
-	0xf1, 0x8d, 0x0a, 0x49, 0x87, 0x86, 0xc4, 0x72, 0x68, 0xcc, 0x80, 0xff, 0xf6, 0xef, 0xc3, 0x96,
-	0x86, 0x93, 0xc1, 0x56, 0x01, 0xef, 0xc2, 0x7a, 0x8f, 0x79, 0x56, 0x20, 0x15, 0xd7, 0xd0, 0xe3,
-	0xfe, 0x61, 0x00, 0x74, 0xf1, 0x38, 0x4e, 0xba, 0x21, 0xb9, 0x78, 0xb1, 0x72, 0x4e, 0xa9, 0xe1,
-	0x38, 0x4b, 0x67, 0xd3, 0x22, 0x61, 0x7e, 0x40, 0x1f, 0xd2, 0x98, 0xe1, 0xb8, 0xd8, 0x05, 0x6f,
-	0xc9, 0x09, 0x2c, 0x5d, 0xb5, 0x87, 0x54, 0x2b, 0xd6, 0x00, 0x07, 0x32, 0xd7, 0x79, 0xfc, 0xb3,
-	0x98, 0x23, 0x9a, 0x24, 0xfb, 0xcd, 0x2e, 0xce, 0x68, 0x7e, 0xc6, 0x02, 0x5a, 0xbc, 0x49, 0xf2,
-	0xb4, 0xfb, 0x39, 0x38, 0xca, 0x7c, 0xc5, 0xb2, 0xd8, 0xd6, 0x97, 0x85, 0xa3, 0x6f, 0x86, 0x5f,
-	0x2d, 0xb0, 0x9e, 0xa6, 0x74, 0x84, 0x57, 0xf5, 0x44, 0x55, 0xb7, 0xa6, 0x55, 0x47, 0x79, 0x8d,
-	0x42, 0x12, 0x8e, 0xc2, 0x1c, 0xcb, 0xdb, 0xab, 0xce, 0xe8, 0x00, 0x36, 0x33, 0x4c, 0x68, 0x5d,
-	0x74, 0x46, 0x9f, 0xa6, 0x93, 0xf8, 0x62, 0xce, 0xb3, 0x77, 0x82, 0x45, 0x71, 0xd9, 0x23, 0x4b,
-	0xef, 0xd1, 0x1e, 0x40, 0x44, 0xe3, 0x26, 0x39, 0xdf, 0x2d, 0x75, 0xda, 0x29, 0x27, 0xd0, 0x24,
-	0x74, 0x03, 0x88, 0x1e, 0x36, 0x78, 0x0f, 0x77, 0x64, 0x0f, 0x79, 0xfe, 0x4b, 0xed, 0xeb, 0x42,
-	0xeb, 0x32, 0xc6, 0x93, 0x28, 0x3f, 0xe1, 0xd7, 0xcf, 0xb3, 0xb9, 0xcd, 0x5e, 0xc5, 0xe6, 0x5b,
-	0x0d, 0x20, 0x6c, 0x2b, 0x36, 0xe8, 0x0b, 0x70, 0xc4, 0xb9, 0x9f, 0x10, 0xcf, 0xa9, 0x10, 0xa7,
-	0x3b, 0xa0, 0x5a, 0x61, 0x5d, 0xa2, 0xcb, 0xf0, 0xe7, 0xfc, 0x66, 0x7b, 0xf0, 0x8f, 0xe1, 0x05,
-	0xa0, 0x12, 0x5e, 0x88, 0x34, 0xb6, 0x9b, 0xaf, 0x85, 0xed, 0xdd, 0xaf, 0x61, 0x6b, 0xa9, 0xe4,
-	0x97, 0x39, 0x30, 0x74, 0x07, 0x5f, 0xc2, 0x46, 0xb5, 0xe4, 0x97, 0x59, 0x9b, 0x2b, 0xc3, 0x6b,
-	0x25, 0xbf, 0xd2, 0xb4, 0xfe, 0x6e, 0x80, 0xdd, 0x4b, 0xa2, 0x57, 0xbd, 0x7a, 0x6c, 0xb4, 0xaf,
-	0xc3, 0x9f, 0xc4, 0xca, 0x0e, 0xf8, 0x6f, 0xf4, 0x81, 0x1c, 0xa5, 0x1a, 0xe7, 0xe5, 0xcd, 0xe2,
-	0x19, 0x97, 0xce, 0x97, 0xa6, 0xe9, 0xb5, 0x5f, 0xbc, 0x5f, 0x4c, 0x68, 0x14, 0x7b, 0xeb, 0x00,
-	0x6a, 0x31, 0x7d, 0x98, 0xb9, 0x61, 0xf9, 0xac, 0x69, 0x1f, 0x2c, 0x74, 0xf7, 0x73, 0x84, 0x40,
-	0xc6, 0x44, 0x3e, 0xfa, 0x25, 0x52, 0xbd, 0xcd, 0x02, 0x19, 0x13, 0x44, 0x13, 0xbb, 0x2a, 0xf6,
-	0x1e, 0x2f, 0xbc, 0xd9, 0xb9, 0x23, 0xe1, 0x8b, 0x7b, 0x93, 0xbd, 0x71, 0x0a, 0x8b, 0x3e, 0xd5,
-	0xf6, 0x76, 0x8d, 0xdb, 0x15, 0xf7, 0x6c, 0xe1, 0x8d, 0x60, 0x6f, 0x5f, 0x81, 0x44, 0x1f, 0x43,
-	0x23, 0x13, 0x1b, 0x9d, 0x37, 0xa8, 0xd9, 0x79, 0x43, 0x1a, 0x55, 0x5f, 0x05, 0x6a, 0x53, 0xe0,
-	0xd0, 0x21, 0x58, 0x23, 0xb6, 0xfd, 0x3c, 0xb7, 0xf2, 0x05, 0x53, 0x6e, 0x44, 0x0a, 0x16, 0x08,
-	0xfa, 0x7a, 0x5b, 0x53, 0x76, 0x63, 0xbc, 0x2d, 0x0e, 0x6d, 0xe9, 0xb7, 0x88, 0xa1, 0xb8, 0x12,
-	0xbd, 0x0b, 0x26, 0x4e, 0x22, 0x0f, 0x71, 0xcc, 0xe6, 0x02, 0xa3, 0x14, 0xc6, 0xb4, 0x5d, 0x07,
-	0x1a, 0xd7, 0xf4, 0x55, 0xa1, 0x4a, 0xff, 0x37, 0x13, 0x6c, 0xb5, 0xed, 0x0f, 0x2b, 0x1c, 0xdc,
-	0x5e, 0xf1, 0xa9, 0xa6, 0x48, 0x38, 0xac, 0x90, 0x70, 0xbb, 0x42, 0x82, 0x0e, 0xa5, 0x2c, 0x3c,
-	0x5c, 0x66, 0xc1, 0x5b, 0x66, 0x41, 0x19, 0x69, 0x34, 0x7c, 0xb6, 0x44, 0xc3, 0x9d, 0x25, 0x1a,
-	0x94, 0x5d, 0xc9, 0x43, 0x67, 0x91, 0x87, 0x9d, 0x45, 0x1e, 0x94, 0x91, 0x22, 0xe2, 0x41, 0xf1,
-	0xd0, 0xd5, 0xb9, 0xc5, 0x76, 0xd1, 0x39, 0xfd, 0x35, 0x64, 0x5d, 0xe6, 0xa0, 0xff, 0x9d, 0xb6,
-	0xa3, 0x77, 0xe8, 0x0e, 0x90, 0x5f, 0xdb, 0x08, 0xa0, 0x7e, 0x3e, 0x0c, 0x7a, 0xc7, 0x4f, 0xdc,
-	0x5b, 0xc8, 0x01, 0xab, 0x7b, 0x3c, 0xfc, 0xe6, 0xd4, 0x35, 0x8e, 0x4e, 0xc0, 0x51, 0x9f, 0x76,
-	0xc8, 0x86, 0x5a, 0x77, 0x30, 0x78, 0x4c, 0x11, 0x0d, 0x30, 0xfb, 0x67, 0x43, 0xd7, 0x60, 0x66,
-	0x27, 0x83, 0x67, 0xdd, 0xc7, 0x3d, 0x77, 0x4d, 0xba, 0xe8, 0x9f, 0x7d, 0xe7, 0x9a, 0xa8, 0x05,
-	0xf6, 0xc9, 0xb3, 0xe0, 0x78, 0xd8, 0x1f, 0x9c, 0xb9, 0xb5, 0x51, 0x9d, 0xff, 0x85, 0xf8, 0xe4,
-	0xef, 0x00, 0x00, 0x00, 0xff, 0xff, 0xe7, 0x9e, 0x6c, 0x9b, 0x4f, 0x0c, 0x00, 0x00,
+	// 1128 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0xdd, 0x72, 0xdb, 0x44,
+	0x14, 0x96, 0x22, 0xd9, 0x92, 0x8e, 0x65, 0x5b, 0xde, 0xd0, 0x46, 0x84, 0xc1, 0xe3, 0xa8, 0x61,
+	0x70, 0x52, 0xf0, 0x50, 0x97, 0x0b, 0xa6, 0x37, 0x10, 0x63, 0x53, 0x79, 0x68, 0x93, 0x92, 0xb8,
+	0xbd, 0x97, 0xa3, 0x8d, 0xab, 0x89, 0x23, 0x19, 0x69, 0x1d, 0x30, 0xcf, 0xc0, 0x1b, 0xf4, 0x82,
+	0x3b, 0x1e, 0x05, 0x5e, 0x8b, 0xd1, 0xee, 0xea, 0x67, 0xe5, 0x66, 0x1a, 0x86, 0x19, 0xee, 0xac,
+	0xf5, 0x77, 0xce, 0x7e, 0xe7, 0x7c, 0x67, 0xbf, 0x5d, 0x30, 0xd6, 0xfe, 0xd5, 0x60, 0x15, 0x47,
+	0x24, 0x42, 0x35, 0x6f, 0x81, 0x43, 0xe2, 0x34, 0xa1, 0x31, 0x0d, 0xaf, 0xa2, 0x73, 0xfc, 0xf3,
+	0x1a, 0x27, 0xc4, 0xf9, 0x5b, 0x06, 0x93, 0x7d, 0x27, 0xab, 0x28, 0x4c, 0x30, 0xea, 0x42, 0xed,
+	0x17, 0x2f, 0x24, 0x89, 0x2d, 0xf7, 0xe4, 0x7e, 0x6b, 0xd8, 0x1e, 0xd0, 0xb0, 0xc1, 0xc4, 0x5f,
+	0xe0, 0xd9, 0x66, 0x85, 0xd1, 0x01, 0xe8, 0xab, 0x38, 0xba, 0x0d, 0x7c, 0x9c, 0xd8, 0x3b, 0xef,
+	0x87, 0x3c, 0x01, 0x2d, 0x5a, 0x91, 0x20, 0x0a, 0x13, 0x5b, 0xe9, 0x29, 0xfd, 0xc6, 0xb0, 0xc7,
+	0x11, 0xe5, 0x8d, 0x06, 0x67, 0x0c, 0x32, 0x09, 0x49, 0xbc, 0xd9, 0x3f, 0x01, 0xb3, 0xfc, 0x8d,
+	0x1a, 0xa0, 0x5c, 0xe3, 0x0d, 0xe5, 0x60, 0xa0, 0x1e, 0xd4, 0x6e, 0xbd, 0xe5, 0x1a, 0xd3, 0xfd,
+	0x1a, 0xc3, 0x0e, 0xcf, 0xc6, 0x02, 0xd2, 0x9c, 0xcf, 0x76, 0xbe, 0x91, 0x9d, 0x21, 0x40, 0xb1,
+	0x82, 0x0e, 0x01, 0x68, 0x4c, 0x4a, 0x28, 0xad, 0x45, 0xe9, 0xb7, 0x86, 0x16, 0x0f, 0x7c, 0x93,
+	0xfd, 0xe1, 0xbc, 0x4c, 0x9b, 0x11, 0x10, 0xde, 0x0c, 0xd4, 0x2d, 0x88, 0xcb, 0x94, 0x78, 0x53,
+	0xd8, 0x0a, 0xb5, 0xa0, 0x4e, 0xbc, 0xe4, 0x7a, 0x3a, 0xa6, 0x4c, 0x8c, 0xf4, 0x3b, 0x8c, 0x7c,
+	0x3c, 0x1d, 0xdb, 0x4a, 0xfa, 0xed, 0x3c, 0x83, 0x3a, 0x47, 0x9a, 0xa0, 0x86, 0xde, 0x0d, 0xe6,
+	0x05, 0x38, 0x50, 0xa7, 0x64, 0xd2, 0x8e, 0xa5, 0x69, 0x91, 0x90, 0x96, 0xd2, 0x71, 0xfe, 0x94,
+	0xa1, 0x51, 0xfa, 0x46, 0x5d, 0x50, 0xc9, 0x66, 0x85, 0xb9, 0x0c, 0x5b, 0xd4, 0xd1, 0x2e, 0x18,
+	0xf3, 0x28, 0x5a, 0xbe, 0xc9, 0x1b, 0xa3, 0xbb, 0x12, 0x42, 0xa0, 0x07, 0x21, 0x61, 0x6b, 0x29,
+	0x25, 0xc5, 0x95, 0xd0, 0x03, 0x68, 0xf8, 0xd1, 0x7a, 0xbe, 0xc4, 0x6c, 0x59, 0xed, 0xc9, 0x7d,
+	0x99, 0x2d, 0x27, 0x24, 0x0e, 0xc2, 0x05, 0x5b, 0xae, 0xa5, 0x44, 0x5d, 0x09, 0xed, 0x41, 0xd3,
+	0x5f, 0xc7, 0x5e, 0xce, 0xc3, 0xae, 0xb3, 0x34, 0x23, 0x8d, 0x8b, 0xe0, 0x0c, 0xc0, 0x64, 0x3d,
+	0xe3, 0x03, 0xd3, 0x06, 0x2d, 0x59, 0x5f, 0x5e, 0xe2, 0x84, 0x8d, 0x8c, 0x8e, 0x9a, 0x50, 0xc3,
+	0x71, 0x1c, 0xc5, 0xac, 0x49, 0x4e, 0x07, 0xda, 0x17, 0xa1, 0xb7, 0x4a, 0xde, 0x46, 0x59, 0x9f,
+	0x9d, 0x43, 0xb0, 0x8a, 0x25, 0x9e, 0xc6, 0x02, 0x3d, 0xe1, 0x6b, 0x34, 0x8f, 0xe9, 0x38, 0xd0,
+	0x3a, 0xc7, 0x09, 0x89, 0x62, 0x9c, 0xe9, 0xb3, 0x8d, 0x79, 0x02, 0xed, 0x1c, 0x73, 0x4f, 0x3e,
+	0x3d, 0xb0, 0x7e, 0xc4, 0x78, 0xe5, 0x2d, 0x83, 0xdb, 0x3c, 0xb1, 0x09, 0x2a, 0x09, 0xb8, 0x5c,
+	0x8a, 0x73, 0x00, 0x9d, 0x12, 0x82, 0xa7, 0x15, 0x21, 0x5d, 0x68, 0x4e, 0xd2, 0x9c, 0xf9, 0xdf,
+	0xf9, 0x26, 0x54, 0x71, 0xe7, 0x3b, 0x68, 0x3d, 0xc7, 0x64, 0xb4, 0x8c, 0xe6, 0xd9, 0x16, 0x1d,
+	0x30, 0x62, 0xf6, 0x73, 0x3a, 0xe6, 0x63, 0xd1, 0x82, 0xfa, 0x7c, 0x19, 0xcd, 0xf3, 0x71, 0x6a,
+	0x80, 0x42, 0xbc, 0x05, 0x9f, 0xa5, 0x9f, 0xa0, 0x9d, 0x67, 0xe0, 0x7b, 0xdc, 0x23, 0x85, 0x09,
+	0xaa, 0xef, 0x11, 0x8f, 0xe6, 0x30, 0x0b, 0x52, 0x2a, 0x4d, 0xf9, 0x2d, 0xb4, 0x2f, 0xbc, 0x5b,
+	0xfc, 0x01, 0x56, 0x59, 0x8a, 0x1d, 0x9a, 0x42, 0xe0, 0x34, 0x06, 0xab, 0x48, 0x70, 0x7f, 0x52,
+	0x39, 0x0d, 0x96, 0xe5, 0x0f, 0x19, 0x60, 0x84, 0x17, 0x41, 0x38, 0xf2, 0xc8, 0xe5, 0xdb, 0xca,
+	0x51, 0x69, 0x42, 0x6d, 0x11, 0x47, 0xeb, 0x15, 0x0f, 0x3d, 0x02, 0x95, 0x78, 0x8b, 0xcc, 0x47,
+	0x3e, 0xe1, 0xa7, 0xa0, 0x88, 0x1e, 0xcc, 0xbc, 0x05, 0xb7, 0x0c, 0x13, 0xd4, 0x24, 0xf8, 0x8d,
+	0x0d, 0xb8, 0x42, 0x39, 0x6c, 0x4e, 0xbd, 0x1b, 0x36, 0xd9, 0xfa, 0xfe, 0x63, 0x30, 0x0a, 0xa8,
+	0xe0, 0x2e, 0xcd, 0xb2, 0xbb, 0x18, 0xd4, 0x4a, 0x7e, 0x57, 0xa1, 0xf6, 0x2a, 0x0a, 0xc2, 0xca,
+	0x60, 0xe4, 0x54, 0x19, 0x37, 0x0b, 0xf4, 0xb4, 0x51, 0x73, 0x2f, 0x61, 0x87, 0xcd, 0x40, 0x7b,
+	0xd0, 0x8e, 0x31, 0xc1, 0x61, 0x7a, 0x7a, 0x5e, 0x45, 0xcb, 0xe0, 0x72, 0x63, 0xab, 0xd9, 0x1e,
+	0xac, 0x2a, 0x7a, 0xcc, 0x10, 0x02, 0xf0, 0x83, 0x1b, 0x1c, 0x26, 0xd4, 0x6a, 0xea, 0x3d, 0xa5,
+	0x6f, 0xa0, 0x43, 0x5e, 0xa9, 0x46, 0x2b, 0x7d, 0xc8, 0x2b, 0xa5, 0x2c, 0x4a, 0x45, 0x7e, 0x0d,
+	0xe6, 0x55, 0x80, 0x97, 0x7e, 0x32, 0xa6, 0x47, 0xda, 0xd6, 0x29, 0xba, 0x2b, 0xa0, 0x7f, 0x28,
+	0x01, 0x58, 0xd4, 0x00, 0x0c, 0x16, 0x35, 0x0d, 0x89, 0x6d, 0x08, 0xad, 0x2c, 0x87, 0x4c, 0x43,
+	0x52, 0xd9, 0xe5, 0x82, 0x3a, 0x84, 0x0d, 0x77, 0xee, 0xc2, 0x00, 0x2c, 0xaa, 0x68, 0x79, 0xe3,
+	0x5f, 0xb7, 0x7c, 0xff, 0x29, 0x74, 0xb6, 0x79, 0xdf, 0x1d, 0x24, 0xd3, 0xa0, 0xaf, 0xa0, 0x55,
+	0x61, 0x7e, 0x77, 0x84, 0x22, 0x6e, 0x53, 0x26, 0xfe, 0xa1, 0x71, 0x78, 0x27, 0x83, 0x3e, 0x09,
+	0xfd, 0x7b, 0x8c, 0x6b, 0x3a, 0x2e, 0x37, 0xde, 0xaf, 0xcc, 0x7b, 0xd1, 0xe7, 0x5c, 0x52, 0x95,
+	0xb6, 0xef, 0xe3, 0xec, 0x9a, 0xe4, 0x99, 0x4a, 0xaa, 0xfe, 0xa7, 0x61, 0x7d, 0xa7, 0x80, 0x96,
+	0x1d, 0x67, 0x07, 0xd4, 0x20, 0xbc, 0x8a, 0x28, 0xb8, 0xb8, 0x66, 0x4a, 0xf7, 0xbd, 0x2b, 0x31,
+	0x4c, 0x40, 0xec, 0x9d, 0x0a, 0x26, 0xbf, 0x06, 0x5d, 0x09, 0x7d, 0x01, 0xc6, 0x75, 0xe6, 0x80,
+	0xb4, 0x98, 0xc6, 0x70, 0x8f, 0x03, 0xab, 0xde, 0xe9, 0x4a, 0xe8, 0xb8, 0x64, 0xcb, 0x6a, 0x4f,
+	0x2e, 0x8d, 0x6f, 0xc5, 0xf8, 0x5d, 0x09, 0xf5, 0x41, 0x8b, 0x99, 0x61, 0xd3, 0x5a, 0x1b, 0xc3,
+	0x07, 0x1c, 0x2a, 0x5a, 0xbd, 0x2b, 0xa1, 0x23, 0xd0, 0x16, 0xcc, 0x00, 0xed, 0xba, 0x90, 0xb4,
+	0x62, 0x8b, 0xae, 0x84, 0x1e, 0x83, 0x9e, 0x70, 0x5f, 0xb2, 0x35, 0x81, 0x6d, 0xd5, 0xae, 0x68,
+	0xfd, 0xb5, 0x79, 0xea, 0x1f, 0xb6, 0x25, 0xbc, 0x26, 0x0a, 0x4f, 0x71, 0x25, 0xf4, 0x29, 0xd4,
+	0x56, 0xe9, 0x94, 0xdb, 0x1d, 0x8a, 0x31, 0xcb, 0x93, 0xef, 0x4a, 0xa8, 0x0b, 0x0a, 0x0e, 0x7d,
+	0x1b, 0xd1, 0x3f, 0xdb, 0x15, 0x5d, 0x5d, 0x69, 0x64, 0x80, 0x76, 0x83, 0x93, 0xc4, 0x5b, 0x60,
+	0xe7, 0x2f, 0x05, 0xf4, 0xdc, 0x2b, 0x1f, 0x09, 0xf2, 0xec, 0xbe, 0xe7, 0x55, 0xe4, 0x4a, 0xe8,
+	0x91, 0xa0, 0xcf, 0xae, 0xa0, 0x4f, 0x0e, 0xfa, 0x72, 0x5b, 0x20, 0x7b, 0x5b, 0x20, 0xa1, 0x41,
+	0xa2, 0x42, 0x7b, 0x5b, 0x0a, 0xe5, 0xe0, 0xa3, 0xaa, 0x44, 0x0f, 0xab, 0x12, 0xe5, 0xd0, 0xcf,
+	0x32, 0x67, 0x67, 0x0a, 0x7d, 0x94, 0xb5, 0xa2, 0x7c, 0x35, 0x32, 0xd1, 0x33, 0x29, 0x35, 0x41,
+	0x74, 0xf1, 0x8e, 0xe4, 0xa3, 0x94, 0x29, 0xa9, 0x8b, 0xa3, 0x24, 0xde, 0x5c, 0xff, 0xb7, 0x90,
+	0xc7, 0x07, 0xa0, 0xe7, 0x0f, 0x5c, 0x80, 0xfa, 0xc5, 0xec, 0x7c, 0x72, 0xf2, 0xd2, 0x92, 0x90,
+	0x01, 0xb5, 0xd1, 0xc9, 0xec, 0x7b, 0xd7, 0x92, 0x8f, 0xc7, 0x60, 0x14, 0xef, 0x33, 0x1d, 0xd4,
+	0xd1, 0xd9, 0xd9, 0x0b, 0x4b, 0x42, 0x1a, 0x28, 0xd3, 0xd3, 0x99, 0x25, 0xa7, 0x61, 0xe3, 0xb3,
+	0xd7, 0xa3, 0x17, 0x13, 0x6b, 0x87, 0xa7, 0x98, 0x9e, 0x3e, 0xb7, 0xd2, 0x6b, 0x45, 0x1f, 0xbf,
+	0x3e, 0x3f, 0x99, 0x4d, 0xcf, 0x4e, 0x2d, 0x75, 0x5e, 0xa7, 0xcf, 0xf5, 0xa7, 0xff, 0x0c, 0x00,
+	0x86, 0xda, 0x29, 0x27, 0xbb, 0x0b, 0x00, 0x00,
 }
@@ -15,6 +15,16 @@ import (
 
 var ErrServerStopped = errors.New("server already stopped")
 
+// BlobStore provides the Server access to Kapacitor's blob store,
+// so that UDFs can load and save blobs.
+type BlobStore interface {
+	// GetBlob returns the ID and data of the blob identified by id, or if id is empty the blob the tag points to.
+	GetBlob(id, tag string) (string, []byte, error)
+	// SaveBlob saves data as a blob and returns its ID.
+	// If tag is not empty the tag is updated to point to the new blob.
+	SaveBlob(data []byte, tag string) (string, error)
+}
+
 // Server provides an implementation for the core communication with UDFs.
 // The Server provides only a partial implementation of udf.Interface as
 // it is expected that setup and teardown will be necessary to create a Server.
@@ -63,6 +73,13 @@ type Server struct {
 	requests      chan *agent.Request
 	requestsGroup sync.WaitGroup
 
+	// Responses to requests made by the UDF, i.e. blob requests.
+	udfResponses chan *agent.Request
+	// Closed once writeData has returned.
+	writing chan struct{}
+
+	blobs BlobStore
+
 	keepalive        chan int64
 	keepaliveTimeout time.Duration
 
@@ -94,6 +111,7 @@ func NewServer(
 	taskID, nodeID string,
 	in agent.ByteReadReader,
 	out io.WriteCloser,
+	blobs BlobStore,
 	l *log.Logger,
 	timeout time.Duration,
 	abortCallback func(),
@@ -104,8 +122,10 @@ func NewServer(
 		nodeID:           nodeID,
 		in:               in,
 		out:              out,
+		blobs:            blobs,
 		logger:           l,
 		requests:         make(chan *agent.Request),
+		udfResponses:     make(chan *agent.Request),
 		keepalive:        make(chan int64, 1),
 		keepaliveTimeout: timeout,
 		abortCallback:    abortCallback,
@@ -144,10 +164,12 @@ func (s *Server) Start() error {
 	s.stopping = make(chan struct{})
 	s.aborted = false
 	s.aborting = make(chan struct{})
+	s.writing = make(chan struct{})
 
 	s.ioGroup.Add(1)
 	go func() {
 		err := s.writeData()
+		close(s.writing)
 		if err != nil {
 			s.setError(err)
 			defer s.abort()
@@ -480,6 +502,11 @@ func (s *Server) writeData() error {
 			} else {
 				s.requests = nil
 			}
+		case req := <-s.udfResponses:
+			err := s.writeRequest(req)
+			if err != nil {
+				return err
+			}
 		case <-s.aborting:
 			return s.err
 		}
@@ -678,6 +705,18 @@ func (s *Server) handleResponse(response *agent.Response) error {
 	case *agent.Response_Error:
 		s.logger.Println("E!", msg.Error.Error)
 		return errors.New(msg.Error.Error)
+	case *agent.Response_GetBlob:
+		return s.doUDFResponse(&agent.Request{
+			Message: &agent.Request_GetBlob{
+				GetBlob: s.getBlob(msg.GetBlob),
+			},
+		})
+	case *agent.Response_SaveBlob:
+		return s.doUDFResponse(&agent.Request{
+			Message: &agent.Request_SaveBlob{
+				SaveBlob: s.saveBlob(msg.SaveBlob),
+			},
+		})
 	case *agent.Response_Begin:
 		s.begin = msg.Begin
 		s.points = make([]edge.BatchPointMessage, 0, msg.Begin.Size)
@@ -738,3 +777,55 @@ func (s *Server) handleResponse(response *agent.Response) error {
 	}
 	return nil
 }
+
+func (s *Server) getBlob(req *agent.GetBlobRequest) *agent.GetBlobResponse {
+	res := &agent.GetBlobResponse{
+		RequestID: req.RequestID,
+	}
+	if s.blobs == nil {
+		res.Error = "no blob store available"
+		return res
+	}
+	if req.BlobID == "" && req.Tag == "" {
+		res.Error = "must provide either a blob ID or a tag"
+		return res
+	}
+	id, data, err := s.blobs.GetBlob(req.BlobID, req.Tag)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.BlobID = id
+	res.Data = data
+	return res
+}
+
+func (s *Server) saveBlob(req *agent.SaveBlobRequest) *agent.SaveBlobResponse {
+	res := &agent.SaveBlobResponse{
+		RequestID: req.RequestID,
+	}
+	if s.blobs == nil {
+		res.Error = "no blob store available"
+		return res
+	}
+	id, err := s.blobs.SaveBlob(req.Data, req.Tag)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.BlobID = id
+	return res
+}
+
+// doUDFResponse sends the response to a request made by the UDF.
+func (s *Server) doUDFResponse(req *agent.Request) error {
+	select {
+	case s.udfResponses <- req:
+	case <-s.writing:
+		// No more data will be written to the UDF.
+		s.logger.Printf("E! dropping %T, server is stopping", req.Message)
+	case <-s.aborting:
+		return s.err
+	}
+	return nil
+}
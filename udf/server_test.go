@@ -18,7 +18,7 @@ import (
 func TestUDF_StartStop(t *testing.T) {
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_StartStop] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, 0, nil, nil)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, 0, nil, nil)
 
 	s.Start()
 
@@ -35,7 +35,7 @@ func TestUDF_StartStop(t *testing.T) {
 func TestUDF_StartInitStop(t *testing.T) {
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_StartStop] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, 0, nil, nil)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, 0, nil, nil)
 	go func() {
 		req := <-u.Requests
 		_, ok := req.Message.(*agent.Request_Init)
@@ -71,7 +71,7 @@ func TestUDF_StartInitStop(t *testing.T) {
 func TestUDF_StartInitAbort(t *testing.T) {
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_StartInfoAbort] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, 0, nil, nil)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, 0, nil, nil)
 	s.Start()
 	expErr := errors.New("explicit abort")
 	go func() {
@@ -92,7 +92,7 @@ func TestUDF_StartInitAbort(t *testing.T) {
 func TestUDF_StartInfoStop(t *testing.T) {
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_StartInfoStop] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, 0, nil, nil)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, 0, nil, nil)
 	go func() {
 		req := <-u.Requests
 		_, ok := req.Message.(*agent.Request_Info)
@@ -134,7 +134,7 @@ func TestUDF_StartInfoStop(t *testing.T) {
 func TestUDF_StartInfoAbort(t *testing.T) {
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_StartInfoAbort] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, 0, nil, nil)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, 0, nil, nil)
 	s.Start()
 	expErr := errors.New("explicit abort")
 	go func() {
@@ -156,7 +156,7 @@ func TestUDF_Keepalive(t *testing.T) {
 	t.Parallel()
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_Keepalive] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, time.Millisecond*100, nil, nil)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, time.Millisecond*100, nil, nil)
 	s.Start()
 	s.Init(nil)
 	req := <-u.Requests
@@ -196,7 +196,7 @@ func TestUDF_MissedKeepalive(t *testing.T) {
 
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_MissedKeepalive] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, time.Millisecond*100, aborted, nil)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, time.Millisecond*100, aborted, nil)
 	s.Start()
 
 	// Since the keepalive is missed, the process should abort on its own.
@@ -230,7 +230,7 @@ func TestUDF_KillCallBack(t *testing.T) {
 
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_MissedKeepalive] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, timeout, aborted, kill)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, timeout, aborted, kill)
 	s.Start()
 
 	// Since the keepalive is missed, the process should abort on its own.
@@ -259,7 +259,7 @@ func TestUDF_MissedKeepaliveInit(t *testing.T) {
 
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_MissedKeepaliveInit] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, time.Millisecond*100, aborted, nil)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, time.Millisecond*100, aborted, nil)
 	s.Start()
 	s.Init(nil)
 
@@ -287,7 +287,7 @@ func TestUDF_MissedKeepaliveInfo(t *testing.T) {
 
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_MissedKeepaliveInfo] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, time.Millisecond*100, aborted, nil)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, time.Millisecond*100, aborted, nil)
 	s.Start()
 	s.Info()
 
@@ -309,7 +309,7 @@ func TestUDF_MissedKeepaliveInfo(t *testing.T) {
 func TestUDF_SnapshotRestore(t *testing.T) {
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_SnapshotRestore] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, 0, nil, nil)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, 0, nil, nil)
 	go func() {
 		// Init
 		req := <-u.Requests
@@ -378,7 +378,7 @@ func TestUDF_SnapshotRestore(t *testing.T) {
 func TestUDF_StartInitPointStop(t *testing.T) {
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_StartPointStop] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, 0, nil, nil)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, 0, nil, nil)
 	go func() {
 		req := <-u.Requests
 		_, ok := req.Message.(*agent.Request_Init)
@@ -440,7 +440,7 @@ func TestUDF_StartInitPointStop(t *testing.T) {
 func TestUDF_StartInitBatchStop(t *testing.T) {
 	u := udf_test.NewIO()
 	l := log.New(os.Stderr, "[TestUDF_StartPointStop] ", log.LstdFlags)
-	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), l, 0, nil, nil)
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), nil, l, 0, nil, nil)
 	go func() {
 		req := <-u.Requests
 		_, ok := req.Message.(*agent.Request_Init)
@@ -534,3 +534,100 @@ func TestUDF_StartInitBatchStop(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+type testBlobStore struct {
+	blobs map[string][]byte
+	tags  map[string]string
+}
+
+func (b *testBlobStore) GetBlob(id, tag string) (string, []byte, error) {
+	if id == "" {
+		id = b.tags[tag]
+	}
+	data, ok := b.blobs[id]
+	if !ok {
+		return "", nil, errors.New("no blob exists")
+	}
+	return id, data, nil
+}
+
+func (b *testBlobStore) SaveBlob(data []byte, tag string) (string, error) {
+	id := "blob" + string(data)
+	b.blobs[id] = data
+	if tag != "" {
+		b.tags[tag] = id
+	}
+	return id, nil
+}
+
+func TestUDF_Blobs(t *testing.T) {
+	u := udf_test.NewIO()
+	l := log.New(os.Stderr, "[TestUDF_Blobs] ", log.LstdFlags)
+	blobs := &testBlobStore{
+		blobs: make(map[string][]byte),
+		tags:  make(map[string]string),
+	}
+	s := udf.NewServer("testTask", "testNode", u.Out(), u.In(), blobs, l, 0, nil, nil)
+	s.Start()
+
+	u.Responses <- &agent.Response{
+		Message: &agent.Response_SaveBlob{
+			SaveBlob: &agent.SaveBlobRequest{
+				RequestID: "1",
+				Data:      []byte("model"),
+				Tag:       "latest",
+			},
+		},
+	}
+	req := <-u.Requests
+	save, ok := req.Message.(*agent.Request_SaveBlob)
+	if !ok {
+		t.Fatalf("expected save blob message got %T", req.Message)
+	}
+	if exp, got := (&agent.SaveBlobResponse{RequestID: "1", BlobID: "blobmodel"}), save.SaveBlob; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected save blob response got %v exp %v", got, exp)
+	}
+
+	u.Responses <- &agent.Response{
+		Message: &agent.Response_GetBlob{
+			GetBlob: &agent.GetBlobRequest{
+				RequestID: "2",
+				Tag:       "latest",
+			},
+		},
+	}
+	req = <-u.Requests
+	get, ok := req.Message.(*agent.Request_GetBlob)
+	if !ok {
+		t.Fatalf("expected get blob message got %T", req.Message)
+	}
+	if exp, got := (&agent.GetBlobResponse{RequestID: "2", BlobID: "blobmodel", Data: []byte("model")}), get.GetBlob; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected get blob response got %v exp %v", got, exp)
+	}
+
+	u.Responses <- &agent.Response{
+		Message: &agent.Response_GetBlob{
+			GetBlob: &agent.GetBlobRequest{
+				RequestID: "3",
+				BlobID:    "missing",
+			},
+		},
+	}
+	req = <-u.Requests
+	get, ok = req.Message.(*agent.Request_GetBlob)
+	if !ok {
+		t.Fatalf("expected get blob message got %T", req.Message)
+	}
+	if exp, got := (&agent.GetBlobResponse{RequestID: "3", Error: "no blob exists"}), get.GetBlob; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected get blob response got %v exp %v", got, exp)
+	}
+
+	close(u.Responses)
+	s.Stop()
+	// read all requests and wait till the chan is closed
+	for range u.Requests {
+	}
+	if err := <-u.ErrC; err != nil {
+		t.Error(err)
+	}
+}
@@ -138,7 +138,7 @@ func New(taskID, nodeID string, uio *IO, l *log.Logger) *UDF {
 }
 
 func (u *UDF) Open() error {
-	u.Server = udf.NewServer(u.taskID, u.nodeID, u.uio.Out(), u.uio.In(), u.logger, 0, nil, nil)
+	u.Server = udf.NewServer(u.taskID, u.nodeID, u.uio.Out(), u.uio.In(), nil, u.logger, 0, nil, nil)
 	return u.Server.Start()
 }
 
@@ -90,6 +90,10 @@ func (n *UnionNode) Barrier(src int, b edge.BarrierMessage) error {
 	return n.emitReady(false)
 }
 
+func (n *UnionNode) DeleteGroup(src int, d edge.DeleteGroupMessage) error {
+	return edge.Forward(n.outs, d)
+}
+
 func (n *UnionNode) Finish() error {
 	// We are done, emit all buffered
 	return n.emitReady(true)
@@ -1,19 +1,48 @@
 package kapacitor
 
 import (
-	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/influxdata/kapacitor/edge"
 	"github.com/influxdata/kapacitor/models"
 	"github.com/influxdata/kapacitor/pipeline"
+	"github.com/pkg/errors"
 )
 
 type WindowNode struct {
 	node
 	w *pipeline.WindowNode
+
+	// mu protects the windows so that they can be snapshotted while the node is running.
+	mu      sync.Mutex
+	windows map[models.GroupID]window
+	// Restored window states for groups that have not yet been seen.
+	restored map[models.GroupID]windowState
+}
+
+type window interface {
+	edge.ForwardReceiver
+	// barrier returns a window to emit, if any, now that no data older than t will arrive.
+	barrier(t time.Time) edge.Message
+	state() windowState
+	restore(windowState)
+}
+
+// windowSnapshot is the snapshot state of a WindowNode.
+type windowSnapshot struct {
+	Windows map[models.GroupID]windowState
+}
+
+type windowState struct {
+	Points []pointSnapshot
+	// Used by windows by time.
+	NextEmit time.Time
+	// Used by windows by count.
+	Count         int
+	NextEmitCount int
 }
 
 // Create a new  WindowNode, which windows data for a period of time and emits the window.
@@ -22,35 +51,97 @@ func newWindowNode(et *ExecutingTask, n *pipeline.WindowNode, l *log.Logger) (*W
 		return nil, errors.New("window node must have either a non zero period or non zero period count")
 	}
 	wn := &WindowNode{
-		w:    n,
-		node: node{Node: n, et: et, logger: l},
+		w:        n,
+		node:     node{Node: n, et: et, logger: l},
+		windows:  make(map[models.GroupID]window),
+		restored: make(map[models.GroupID]windowState),
 	}
 	wn.node.runF = wn.runWindow
 	return wn, nil
 }
 
-func (n *WindowNode) runWindow([]byte) error {
+func (n *WindowNode) runWindow(snapshot []byte) error {
+	if snapshot != nil {
+		if err := n.restore(snapshot); err != nil {
+			return errors.Wrap(err, "failed to restore window snapshot")
+		}
+	}
 	consumer := edge.NewGroupedConsumer(n.ins[0], n)
 	n.statMap.Set(statCardinalityGauge, consumer.CardinalityVar())
 	return consumer.Consume()
 }
 
+func (n *WindowNode) snapshot() ([]byte, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	s := windowSnapshot{
+		Windows: make(map[models.GroupID]windowState, len(n.windows)+len(n.restored)),
+	}
+	for id, state := range n.restored {
+		s.Windows[id] = state
+	}
+	for id, w := range n.windows {
+		s.Windows[id] = w.state()
+	}
+	return encodeSnapshot(s)
+}
+
+func (n *WindowNode) restore(data []byte) error {
+	var s windowSnapshot
+	if err := decodeSnapshot(data, &s); err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for id, state := range s.Windows {
+		n.restored[id] = state
+	}
+	return nil
+}
+
 func (n *WindowNode) NewGroup(group edge.GroupInfo, first edge.PointMeta) (edge.Receiver, error) {
-	r, err := n.newWindow(group, first)
+	w, err := n.newWindow(group, first)
 	if err != nil {
 		return nil, err
 	}
+	n.mu.Lock()
+	if state, ok := n.restored[group.ID]; ok {
+		w.restore(state)
+		delete(n.restored, group.ID)
+	}
+	n.windows[group.ID] = w
+	n.mu.Unlock()
 	return edge.NewReceiverFromForwardReceiverWithStats(
 		n.outs,
-		edge.NewTimedForwardReceiver(n.timer, r),
+		edge.NewTimedForwardReceiver(n.timer, edge.NewLockedForwardReceiver(&n.mu, windowGroup{window: w, n: n})),
 	), nil
 }
 
+// windowGroup flushes the window on barriers and removes the window from the node once its group is deleted.
+type windowGroup struct {
+	window
+	n *WindowNode
+}
+
+func (g windowGroup) Barrier(b edge.BarrierMessage) (edge.Message, error) {
+	if msg := g.window.barrier(b.Time()); msg != nil {
+		if err := edge.Forward(g.n.outs, msg); err != nil {
+			return nil, err
+		}
+	}
+	return g.window.Barrier(b)
+}
+
+func (g windowGroup) DeleteGroup(d edge.DeleteGroupMessage) (edge.Message, error) {
+	delete(g.n.windows, d.GroupID())
+	return g.window.DeleteGroup(d)
+}
+
 func (n *WindowNode) DeleteGroup(group models.GroupID) {
 	// Nothing to do
 }
 
-func (n *WindowNode) newWindow(group edge.GroupInfo, first edge.PointMeta) (edge.ForwardReceiver, error) {
+func (n *WindowNode) newWindow(group edge.GroupInfo, first edge.PointMeta) (window, error) {
 	switch {
 	case n.w.Period != 0:
 		return newWindowByTime(
@@ -147,7 +238,6 @@ func (w *windowByTime) EndBatch(edge.EndBatchMessage) (edge.Message, error) {
 	return nil, errors.New("window does not support batch data")
 }
 func (w *windowByTime) Barrier(b edge.BarrierMessage) (edge.Message, error) {
-	//TODO(nathanielc): Implement barrier messages to flush window
 	return b, nil
 }
 func (w *windowByTime) DeleteGroup(d edge.DeleteGroupMessage) (edge.Message, error) {
@@ -193,6 +283,46 @@ func (w *windowByTime) Point(p edge.PointMessage) (msg edge.Message, err error)
 	return
 }
 
+func (w *windowByTime) barrier(t time.Time) edge.Message {
+	if w.every == 0 {
+		// Windows are emitted for every point, only purge points that have left the window.
+		w.buf.purge(t.Add(-1*w.period), false)
+		return nil
+	}
+	if t.Before(w.nextEmit) {
+		return nil
+	}
+	// purge old points
+	oldest := w.nextEmit.Add(-1 * w.period)
+	w.buf.purge(oldest, true)
+
+	// Do not emit empty windows for groups that have stopped receiving data.
+	var msg edge.Message
+	if w.buf.size > 0 {
+		msg = w.batch(w.nextEmit)
+	}
+
+	w.nextEmit = t.Add(w.every)
+	if w.align {
+		w.nextEmit = w.nextEmit.Truncate(w.every)
+	}
+	return msg
+}
+
+func (w *windowByTime) state() windowState {
+	return windowState{
+		Points:   w.buf.snapshot(),
+		NextEmit: w.nextEmit,
+	}
+}
+
+func (w *windowByTime) restore(s windowState) {
+	w.nextEmit = s.NextEmit
+	for _, p := range s.Points {
+		w.buf.insert(p.point())
+	}
+}
+
 // batch returns the current window buffer as a batch message.
 // TODO(nathanielc): A possible optimization could be to not buffer the data at all if we know that we do not have overlapping windows.
 func (w *windowByTime) batch(tmax time.Time) edge.BufferedBatchMessage {
@@ -326,6 +456,27 @@ func (b *windowTimeBuffer) points() []edge.BatchPointMessage {
 	return points
 }
 
+// Returns a snapshot of the points in the current buffer.
+func (b *windowTimeBuffer) snapshot() []pointSnapshot {
+	if b.size == 0 {
+		return nil
+	}
+	points := make([]pointSnapshot, 0, b.size)
+	if b.stop > b.start {
+		for _, p := range b.window[b.start:b.stop] {
+			points = append(points, newPointSnapshot(p))
+		}
+	} else {
+		for _, p := range b.window[b.start:] {
+			points = append(points, newPointSnapshot(p))
+		}
+		for _, p := range b.window[:b.stop] {
+			points = append(points, newPointSnapshot(p))
+		}
+	}
+	return points
+}
+
 type windowByCount struct {
 	name  string
 	group edge.GroupInfo
@@ -374,7 +525,6 @@ func (w *windowByCount) EndBatch(edge.EndBatchMessage) (edge.Message, error) {
 	return nil, errors.New("window does not support batch data")
 }
 func (w *windowByCount) Barrier(b edge.BarrierMessage) (edge.Message, error) {
-	//TODO(nathanielc): Implement barrier messages to flush window
 	return b, nil
 }
 func (w *windowByCount) DeleteGroup(d edge.DeleteGroupMessage) (edge.Message, error) {
@@ -398,6 +548,39 @@ func (w *windowByCount) Point(p edge.PointMessage) (msg edge.Message, err error)
 	return
 }
 
+// barrier does nothing since windows by count do not depend on time.
+func (w *windowByCount) barrier(time.Time) edge.Message {
+	return nil
+}
+
+func (w *windowByCount) state() windowState {
+	points := w.points()
+	s := windowState{
+		Points:        make([]pointSnapshot, len(points)),
+		Count:         w.count,
+		NextEmitCount: w.nextEmit,
+	}
+	for i, p := range points {
+		s.Points[i] = newBatchPointSnapshot(p)
+	}
+	return s
+}
+
+func (w *windowByCount) restore(s windowState) {
+	points := s.Points
+	if len(points) > w.period {
+		points = points[len(points)-w.period:]
+	}
+	for i, p := range points {
+		w.buf[i] = p.batchPoint()
+	}
+	w.start = 0
+	w.size = len(points)
+	w.stop = w.size % w.period
+	w.count = s.Count
+	w.nextEmit = s.NextEmitCount
+}
+
 func (w *windowByCount) batch() edge.BufferedBatchMessage {
 	points := w.points()
 	return edge.NewBufferedBatchMessage(
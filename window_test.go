@@ -199,3 +199,136 @@ func TestWindowBufferByCount(t *testing.T) {
 		}
 	}
 }
+
+func TestWindowByTime_SnapshotRestore(t *testing.T) {
+	group := edge.GroupInfo{ID: "group"}
+	newWindow := func() *windowByTime {
+		return newWindowByTime("name", time.Unix(0, 0), group, 10*time.Second, 5*time.Second, false, false, logger)
+	}
+	point := func(i int) edge.PointMessage {
+		return edge.NewPointMessage(
+			"name", "db", "rp",
+			models.Dimensions{},
+			models.Fields{"value": float64(i)},
+			nil,
+			time.Unix(int64(i), 0).UTC(),
+		)
+	}
+
+	w := newWindow()
+	for i := 0; i < 7; i++ {
+		if _, err := w.Point(point(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := encodeSnapshot(windowSnapshot{Windows: map[models.GroupID]windowState{group.ID: w.state()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s windowSnapshot
+	if err := decodeSnapshot(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	restored := newWindow()
+	restored.restore(s.Windows[group.ID])
+
+	exp, err := w.Point(point(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := restored.Point(point(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp == nil {
+		t.Fatal("expected window to emit a batch")
+	}
+	assert.Equal(t, exp, got)
+}
+
+func TestWindowByCount_SnapshotRestore(t *testing.T) {
+	group := edge.GroupInfo{ID: "group"}
+	newWindow := func() *windowByCount {
+		return newWindowByCount("name", group, 3, 2, false, logger)
+	}
+	point := func(i int) edge.PointMessage {
+		return edge.NewPointMessage(
+			"name", "db", "rp",
+			models.Dimensions{},
+			models.Fields{"value": int64(i)},
+			nil,
+			time.Unix(int64(i), 0).UTC(),
+		)
+	}
+
+	w := newWindow()
+	for i := 0; i < 5; i++ {
+		if _, err := w.Point(point(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := encodeSnapshot(windowSnapshot{Windows: map[models.GroupID]windowState{group.ID: w.state()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s windowSnapshot
+	if err := decodeSnapshot(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	restored := newWindow()
+	restored.restore(s.Windows[group.ID])
+
+	exp, err := w.Point(point(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := restored.Point(point(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp == nil {
+		t.Fatal("expected window to emit a batch")
+	}
+	assert.Equal(t, exp, got)
+}
+
+func TestWindowByTime_Barrier(t *testing.T) {
+	group := edge.GroupInfo{ID: "group"}
+	w := newWindowByTime("name", time.Unix(0, 0).UTC(), group, 10*time.Second, 5*time.Second, false, false, logger)
+	for i := 0; i < 4; i++ {
+		p := edge.NewPointMessage(
+			"name", "db", "rp",
+			models.Dimensions{},
+			models.Fields{"value": float64(i)},
+			nil,
+			time.Unix(int64(i), 0).UTC(),
+		)
+		msg, err := w.Point(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Nil(t, msg)
+	}
+
+	// Barrier before the window closes does not emit
+	assert.Nil(t, w.barrier(time.Unix(4, 0).UTC()))
+
+	// Barrier after the window closes emits the window
+	msg := w.barrier(time.Unix(6, 0).UTC())
+	if b, ok := msg.(edge.BufferedBatchMessage); assert.True(t, ok, "unexpected message %T", msg) {
+		assert.Equal(t, time.Unix(5, 0).UTC(), b.Time())
+		assert.Equal(t, 4, len(b.Points()))
+	}
+
+	// The next window still contains the overlapping points
+	msg = w.barrier(time.Unix(12, 0).UTC())
+	if b, ok := msg.(edge.BufferedBatchMessage); assert.True(t, ok, "unexpected message %T", msg) {
+		assert.Equal(t, time.Unix(11, 0).UTC(), b.Time())
+		assert.Equal(t, 3, len(b.Points()))
+	}
+
+	// Empty windows are not emitted
+	assert.Nil(t, w.barrier(time.Unix(20, 0).UTC()))
+}